@@ -5,21 +5,50 @@ import (
 	"crypto/rand"
 	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"flag"
 	"fmt"
+	"io"
 	"os"
 	"os/signal"
+	"path/filepath"
 	"slices"
 	"strconv"
 	"strings"
 	"syscall"
 	"time"
+	"unicode"
 
 	"github.com/AlhasanIQ/consult-human/config"
 	"github.com/AlhasanIQ/consult-human/contract"
+	"github.com/AlhasanIQ/consult-human/gitrepo"
 	"github.com/AlhasanIQ/consult-human/provider"
+	"golang.org/x/term"
 )
 
+const envAskQuiet = "CONSULT_HUMAN_QUIET"
+
+const (
+	askWithdrawTimeout = 5 * time.Second
+	askConfirmTimeout  = 5 * time.Second
+)
+
+const (
+	askMaxTags     = 10
+	askMaxTagBytes = 256
+)
+
+var askProviderFn = provider.New
+
+var askSignalContextFn = signal.NotifyContext
+
+var askHostnameFn = os.Hostname
+
+// askNowFn is the clock quiet hours are checked against.
+var askNowFn = time.Now
+
+var askInputIsTerminalFn = askInputIsTerminal
+
 type stringSliceFlag []string
 
 func (s *stringSliceFlag) String() string {
@@ -36,18 +65,102 @@ func runAsk(args []string, io IO) error {
 	fs.SetOutput(io.ErrOut)
 
 	var choicesRaw stringSliceFlag
+	var tagsRaw stringSliceFlag
 	var allowOther bool
 	var providerOverride string
 	var timeoutOverride string
+	var quiet bool
+	var priorityRaw string
+	var remindAfter string
+	var strictReply bool
+	var followUpTo string
+	var session string
+	var dryRun bool
+	var batchPath string
+	var noDedupe bool
+	var format string
+	var rawFormat bool
+	var chat string
+	var watchEdits bool
+	var poll bool
+	var pollWaitRaw string
+	var silent bool
+	var urgent bool
+	var showDeadline bool
+	var broadcastRaw string
 
-	fs.Var(&choicesRaw, "choice", "Choice in the form id:text or plain text. Repeatable.")
+	fs.Var(&choicesRaw, "choice", "Choice in the form id:text[::description] or plain text. Repeatable.")
+	fs.Var(&tagsRaw, "tag", "Tag in the form key=value. Repeatable.")
 	fs.BoolVar(&allowOther, "allow-other", false, "Allow a free-text answer outside predefined choices")
-	fs.StringVar(&providerOverride, "provider", "", "Override configured provider (telegram)")
+	fs.StringVar(&providerOverride, "provider", "", "Override configured provider (telegram|slack|signal|email|ntfy|webhook|desktop|zulip|pushover|imessage|console)")
+	fs.StringVar(&broadcastRaw, "broadcast", "", "Send to these providers at once (comma-separated); the first answer wins (default broadcast_providers)")
 	fs.StringVar(&timeoutOverride, "timeout", "", "Override configured timeout (e.g. 5m, 30s)")
+	fs.StringVar(&priorityRaw, "priority", string(contract.PriorityNormal), "Question priority (low|normal|high)")
+	fs.StringVar(&remindAfter, "remind-after", "", "Send one reminder if unanswered after this long (e.g. 10m)")
+	fs.StringVar(&followUpTo, "follow-up", "", "Thread this question under an earlier request ID")
+	fs.StringVar(&session, "session", "", "Thread this question under the previous one asked with the same session name")
+	fs.StringVar(&chat, "chat", "", "Send to this Telegram chat (alias from telegram.chats, or a chat ID)")
+	fs.BoolVar(&strictReply, "strict-reply", false, "Only accept explicit replies to the question (or messages naming the request ID)")
+	fs.StringVar(&batchPath, "batch", "", "Ask every question in a YAML/JSON file and stream results as NDJSON")
+	fs.StringVar(&format, "format", "", "Message formatting (plain|markdown|html); overrides telegram.parse_mode")
+	fs.BoolVar(&rawFormat, "raw", false, "The question is already formatted for --format; send it without escaping")
+	fs.BoolVar(&watchEdits, "watch-edits", false, "After a reply arrives, wait briefly in case the human edits it, and return the edited text")
+	fs.BoolVar(&poll, "poll", false, "Ask a choice question as a native Telegram poll")
+	fs.StringVar(&pollWaitRaw, "poll-wait", "", "With --poll, answer with the most voted option once this long has passed (e.g. 10m)")
+	fs.BoolVar(&silent, "silent", false, "Deliver without a notification sound (default telegram.silent; --priority low implies it)")
+	fs.BoolVar(&urgent, "urgent", false, "Send now even during quiet hours (--priority high does too)")
+	fs.BoolVar(&showDeadline, "show-deadline", false, "Keep the time left to answer on the question message")
+	fs.BoolVar(&noDedupe, "no-dedupe", false, "Always send, even if an identical question is still pending")
+	fs.BoolVar(&dryRun, "dry-run", false, "Validate and print the rendered message without sending it")
+	fs.BoolVar(&quiet, "quiet", false, "Suppress progress output on stderr (env: "+envAskQuiet+"=1)")
 
 	if err := fs.Parse(args); err != nil {
 		return err
 	}
+	var silentOverride *bool
+	fs.Visit(func(f *flag.Flag) {
+		if f.Name == "silent" {
+			silentOverride = &silent
+		}
+	})
+
+	format = strings.ToLower(strings.TrimSpace(format))
+	if format != "" && format != config.TelegramParseModePlain && format != config.TelegramParseModeMarkdown && format != config.TelegramParseModeHTML {
+		return fmt.Errorf("--format must be plain, markdown, or html")
+	}
+
+	if batchPath != "" {
+		// Priority and tags are set per question in the batch file.
+		if fs.NArg() > 0 || len(choicesRaw) > 0 || len(tagsRaw) > 0 || priorityRaw != string(contract.PriorityNormal) ||
+			remindAfter != "" || followUpTo != "" || urgent || showDeadline ||
+			dryRun || rawFormat || session != "" || poll || broadcastRaw != "" {
+			return fmt.Errorf("--batch cannot be combined with a question, --choice, --tag, --priority, --remind-after, --follow-up, --raw, --session, --poll, --broadcast, --urgent, --show-deadline, or --dry-run")
+		}
+		cfg, err := config.Load()
+		if err != nil {
+			return err
+		}
+		if format != "" {
+			cfg.Telegram.ParseMode = format
+		}
+		if strictReply {
+			cfg.Telegram.StrictReply = true
+		}
+		if noDedupe {
+			cfg.Telegram.DedupeWindow = "0"
+		}
+		if err := applyAskChat(&cfg, chat); err != nil {
+			return err
+		}
+		return runAskBatch(askBatchOptions{
+			path:             batchPath,
+			providerOverride: providerOverride,
+			timeoutOverride:  timeoutOverride,
+			quiet:            quiet,
+			watchEdits:       watchEdits,
+			silent:           silentOverride,
+		}, cfg, io)
+	}
 
 	question := strings.TrimSpace(strings.Join(fs.Args(), " "))
 	if question == "" {
@@ -61,24 +174,70 @@ func runAsk(args []string, io IO) error {
 	if len(choices) == 0 && allowOther {
 		return fmt.Errorf("--allow-other requires at least one --choice")
 	}
+	if poll && (len(choices) < 2 || allowOther) {
+		return fmt.Errorf("--poll requires at least two --choice values and cannot be combined with --allow-other")
+	}
+	var pollWait time.Duration
+	if strings.TrimSpace(pollWaitRaw) != "" {
+		if !poll {
+			return fmt.Errorf("--poll-wait requires --poll")
+		}
+		d, err := time.ParseDuration(strings.TrimSpace(pollWaitRaw))
+		if err != nil {
+			return fmt.Errorf("invalid --poll-wait: %w", err)
+		}
+		if d < 0 {
+			return fmt.Errorf("--poll-wait must be >= 0")
+		}
+		pollWait = d
+	}
+	priority, err := parsePriority(priorityRaw)
+	if err != nil {
+		return err
+	}
+	tags, err := parseTags(tagsRaw)
+	if err != nil {
+		return err
+	}
 
 	cfg, err := config.Load()
 	if err != nil {
 		return err
 	}
 
-	timeout, err := config.EffectiveTimeout(cfg)
+	timeout, err := resolveAskTimeout(cfg, timeoutOverride)
 	if err != nil {
 		return err
 	}
-	if strings.TrimSpace(timeoutOverride) != "" {
-		timeout, err = time.ParseDuration(strings.TrimSpace(timeoutOverride))
+	escalateAfter, err := askEscalationDelay(cfg, priority)
+	if err != nil {
+		return err
+	}
+
+	if strings.TrimSpace(remindAfter) != "" {
+		d, err := time.ParseDuration(strings.TrimSpace(remindAfter))
 		if err != nil {
-			return fmt.Errorf("invalid --timeout: %w", err)
+			return fmt.Errorf("invalid --remind-after: %w", err)
 		}
-		if timeout <= 0 {
-			return fmt.Errorf("--timeout must be > 0")
+		if d < 0 {
+			return fmt.Errorf("--remind-after must be >= 0")
 		}
+		cfg.Telegram.RemindAfter = strings.TrimSpace(remindAfter)
+	}
+	if strictReply {
+		cfg.Telegram.StrictReply = true
+	}
+	if noDedupe {
+		cfg.Telegram.DedupeWindow = "0"
+	}
+	if format != "" {
+		cfg.Telegram.ParseMode = format
+	}
+	if err := applyAskChat(&cfg, chat); err != nil {
+		return err
+	}
+	if rawFormat && (cfg.Telegram.ParseMode == "" || cfg.Telegram.ParseMode == config.TelegramParseModePlain) {
+		return fmt.Errorf("--raw requires --format markdown or html (or telegram.parse_mode)")
 	}
 
 	reqID, err := newRequestID()
@@ -92,43 +251,135 @@ func runAsk(args []string, io IO) error {
 	}
 
 	req := contract.AskRequest{
-		RequestID:  reqID,
-		Question:   question,
-		Type:       qType,
-		Choices:    choices,
-		AllowOther: allowOther,
-		SentAt:     time.Now().UTC(),
+		RequestID:    reqID,
+		Question:     question,
+		Type:         qType,
+		Choices:      choices,
+		AllowOther:   allowOther,
+		Priority:     priority,
+		FollowUpTo:   strings.TrimSpace(followUpTo),
+		Session:      strings.TrimSpace(session),
+		Tags:         tags,
+		PreFormatted: rawFormat,
+		SentAt:       time.Now().UTC(),
+		Poll:         poll,
+		PollWait:     pollWait,
+		Origin:       resolveSenderLabel(cfg.Telegram.SenderLabel),
+		Silent:       resolveAskSilent(cfg, priority, silentOverride),
+		ShowDeadline: showDeadline,
 	}
 
-	p, err := provider.New(cfg, providerOverride)
+	broadcast, err := askBroadcastList(cfg, broadcastRaw, providerOverride)
 	if err != nil {
 		return err
 	}
-	defer p.Close()
-
-	fmt.Fprintf(io.ErrOut, "Sending request %s via %s...\n", req.RequestID, p.Name())
+	status := askStatusWriter(io, quiet)
+	chain := askProviderChain(cfg, providerOverride)
+	if len(broadcast) > 0 {
+		chain = broadcast
+	} else if strings.TrimSpace(providerOverride) == "" && !askProviderConfigured(cfg) && askInputIsTerminalFn(io.In) {
+		if !dryRun {
+			fmt.Fprintln(status, "note: no provider is configured; asking in this terminal instead. Run `consult-human setup` to configure Telegram.")
+		}
+		chain = []string{"console"}
+	}
+	if dryRun {
+		printAskDryRun(io.Out, req, chain, len(broadcast) > 0, timeout, cfg.Telegram.ParseMode)
+		return nil
+	}
 
-	baseCtx, stopSignals := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	baseCtx, stopSignals := askSignalContextFn(context.Background(), os.Interrupt, syscall.SIGTERM)
 	defer stopSignals()
 
 	ctx, cancel := context.WithTimeout(baseCtx, timeout)
 	defer cancel()
 
-	if _, err := p.Send(ctx, req); err != nil {
+	started := time.Now()
+	req, err = applyQuietHours(ctx, cfg, chain[0], req, urgent, status, io.ErrOut)
+	if err != nil {
+		recordAskHistory(io.ErrOut, req, chain[0], started, nil, err)
 		return err
 	}
+	var p provider.Provider
+	if len(broadcast) > 0 {
+		p, err = sendBroadcast(ctx, cfg, broadcast, req, status, io.ErrOut)
+	} else {
+		p, err = sendWithFallback(ctx, cfg, chain, req, status, io.ErrOut)
+	}
+	if err != nil {
+		recordAskHistory(io.ErrOut, req, chain[0], started, nil, err)
+		return err
+	}
+	if len(broadcast) == 0 {
+		p = withEscalation(cfg, p, req, escalateAfter, urgent, status, io.ErrOut)
+	}
+	defer p.Close()
 
-	fmt.Fprintln(io.ErrOut, "Waiting for human reply...")
+	fmt.Fprintln(status, "Waiting for human reply...")
 	reply, err := p.Receive(ctx, req.RequestID)
+	if errors.Is(err, provider.ErrCancelledByHuman) {
+		result := buildCancelledAskResult(req, p.Name())
+		recordAskHistory(io.ErrOut, req, p.Name(), started, &result, err)
+		enc := json.NewEncoder(io.Out)
+		enc.SetEscapeHTML(false)
+		if encErr := enc.Encode(result); encErr != nil {
+			return encErr
+		}
+		return &exitError{code: ExitCodeCancelled, err: err}
+	}
 	if err != nil {
+		if baseCtx.Err() != nil {
+			withdrawAsk(io.ErrOut, p, req.RequestID)
+			err = &exitError{code: ExitCodeInterrupted, err: fmt.Errorf("interrupted: %w", err)}
+		} else if errors.Is(err, context.DeadlineExceeded) {
+			notifyAskTimeout(io.ErrOut, p, req.RequestID, timeout)
+		}
+		recordAskHistory(io.ErrOut, req, p.Name(), started, nil, err)
 		return err
 	}
+	if watchEdits {
+		fmt.Fprintln(status, "Reply received; watching briefly for edits...")
+		reply = awaitAskCorrection(baseCtx, io.ErrOut, p, reply)
+	}
 
+	result := buildAskResult(req, reply, p.Name())
+	confirmAskReply(io.ErrOut, cfg, p, reply, result)
+	recordAskHistory(io.ErrOut, req, p.Name(), started, &result, nil)
+
+	enc := json.NewEncoder(io.Out)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(result); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func resolveAskTimeout(cfg config.Config, override string) (time.Duration, error) {
+	if strings.TrimSpace(override) == "" {
+		return config.EffectiveTimeout(cfg)
+	}
+	timeout, err := time.ParseDuration(strings.TrimSpace(override))
+	if err != nil {
+		return 0, fmt.Errorf("invalid --timeout: %w", err)
+	}
+	if timeout <= 0 {
+		return 0, fmt.Errorf("--timeout must be > 0")
+	}
+	return timeout, nil
+}
+
+func buildAskResult(req contract.AskRequest, reply contract.Reply, providerName string) contract.AskResult {
 	result := contract.AskResult{
 		RequestID:    req.RequestID,
-		Provider:     p.Name(),
+		Provider:     providerName,
 		QuestionType: req.Type,
+		Question:     req.Question,
+		Choices:      req.Choices,
+		SentAt:       req.SentAt,
 		RawReply:     reply.Raw,
+		Attachments:  reply.Attachments,
+		Edited:       reply.Edited,
 		ReceivedAt:   reply.ReceivedAt,
 	}
 
@@ -140,16 +391,274 @@ func runAsk(args []string, io IO) error {
 		result.OtherText = other
 		result.Text = strings.TrimSpace(reply.Text)
 	}
+	return result
+}
 
-	enc := json.NewEncoder(io.Out)
-	enc.SetEscapeHTML(false)
-	if err := enc.Encode(result); err != nil {
-		return err
+// buildCancelledAskResult describes a question the human dismissed instead
+// of answering.
+func buildCancelledAskResult(req contract.AskRequest, providerName string) contract.AskResult {
+	return contract.AskResult{
+		RequestID:    req.RequestID,
+		Provider:     providerName,
+		QuestionType: req.Type,
+		Question:     req.Question,
+		Choices:      req.Choices,
+		SentAt:       req.SentAt,
+		Cancelled:    true,
+		ReceivedAt:   time.Now().UTC(),
 	}
+}
 
+// awaitAskCorrection returns the human's edit of reply if they make one
+// within the provider's correction window, or reply unchanged.
+func awaitAskCorrection(ctx context.Context, errOut io.Writer, p provider.Provider, reply contract.Reply) contract.Reply {
+	c, ok := p.(provider.Corrector)
+	if !ok {
+		return reply
+	}
+	corrected, edited, err := c.AwaitCorrection(ctx, reply)
+	if err != nil {
+		fmt.Fprintf(errOut, "warning: could not check %s for an edited reply: %v\n", reply.RequestID, err)
+		return reply
+	}
+	if !edited {
+		return reply
+	}
+	return corrected
+}
+
+// askProviderChain lists the providers to try in order: the --provider
+// override alone, or the active provider followed by fallback_providers.
+func askProviderChain(cfg config.Config, override string) []string {
+	if name := strings.ToLower(strings.TrimSpace(override)); name != "" {
+		return []string{name}
+	}
+	config.ApplyDefaults(&cfg)
+	chain := []string{cfg.ActiveProvider}
+	for _, name := range cfg.FallbackProviders {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name != "" && !slices.Contains(chain, name) {
+			chain = append(chain, name)
+		}
+	}
+	return chain
+}
+
+// sendWithFallback delivers req through the first provider in chain that
+// accepts it. Only send failures move on to the next provider; an expired
+// or canceled context stops the chain.
+func sendWithFallback(ctx context.Context, cfg config.Config, chain []string, req contract.AskRequest, status, errOut io.Writer) (provider.Provider, error) {
+	var lastErr error
+	for i, name := range chain {
+		hasNext := i < len(chain)-1
+
+		p, err := askProviderFn(cfg, name)
+		if err != nil {
+			if errors.Is(err, provider.ErrProviderDisabled) && len(chain) > 1 {
+				fmt.Fprintf(errOut, "note: skipping %s: %v\n", name, err)
+				continue
+			}
+			lastErr = err
+			if hasNext {
+				fmt.Fprintf(errOut, "warning: provider %s unavailable: %v; trying %s\n", name, err, chain[i+1])
+			}
+			continue
+		}
+
+		setProviderStatus(p, status)
+		fmt.Fprintf(status, "Sending request %s via %s...\n", req.RequestID, p.Name())
+		if _, err := p.Send(ctx, req); err != nil {
+			_ = p.Close()
+			lastErr = err
+			if ctx.Err() != nil {
+				return nil, err
+			}
+			if hasNext {
+				fmt.Fprintf(errOut, "warning: send via %s failed: %v; trying %s\n", name, err, chain[i+1])
+			}
+			continue
+		}
+		return p, nil
+	}
+	if lastErr == nil {
+		return nil, fmt.Errorf("no enabled provider available (tried %s)", strings.Join(chain, ", "))
+	}
+	return nil, lastErr
+}
+
+func askProviderConfigured(cfg config.Config) bool {
+	return strings.TrimSpace(cfg.Telegram.BotToken) != "" || strings.TrimSpace(cfg.Slack.BotToken) != "" || strings.TrimSpace(cfg.Signal.Number) != "" || strings.TrimSpace(cfg.Email.SMTPHost) != "" || strings.TrimSpace(cfg.Ntfy.Topic) != "" || strings.TrimSpace(cfg.Webhook.URL) != "" || cfg.ActiveProvider == "desktop" || strings.TrimSpace(cfg.Zulip.APIKey) != "" || strings.TrimSpace(cfg.Pushover.AppToken) != "" || strings.TrimSpace(cfg.IMessage.Handle) != ""
+}
+
+func askInputIsTerminal(r io.Reader) bool {
+	f, ok := r.(*os.File)
+	return ok && term.IsTerminal(int(f.Fd()))
+}
+
+// applyAskChat points the Telegram provider at the --chat destination. The
+// pending record keeps the chat, so receiving needs nothing further.
+func applyAskChat(cfg *config.Config, chat string) error {
+	if strings.TrimSpace(chat) == "" {
+		return nil
+	}
+	chatID, err := config.ResolveTelegramChat(*cfg, chat)
+	if err != nil {
+		return fmt.Errorf("invalid --chat: %w", err)
+	}
+	cfg.Telegram.ChatID = chatID
 	return nil
 }
 
+func printAskDryRun(w io.Writer, req contract.AskRequest, chain []string, broadcast bool, timeout time.Duration, parseMode string) {
+	sep := " -> "
+	if broadcast {
+		sep = " + "
+	}
+	fmt.Fprintf(w, "provider: %s\n", strings.Join(chain, sep))
+	fmt.Fprintf(w, "timeout: %s\n", timeout)
+	fmt.Fprintf(w, "request_id: %s\n", req.RequestID)
+	fmt.Fprintln(w, "---")
+	switch chain[0] {
+	case "telegram":
+		fmt.Fprintln(w, provider.RenderTelegramPromptFor(req, parseMode))
+	case "slack":
+		fmt.Fprintln(w, provider.RenderSlackPrompt(req))
+	default:
+		fmt.Fprintln(w, strings.TrimRight(provider.RenderPrompt(req), "\n"))
+	}
+}
+
+// withdrawAsk gives the provider a brief, independent window to tell the
+// human the question no longer needs an answer.
+func withdrawAsk(errOut io.Writer, p provider.Provider, requestID string) {
+	canceler, ok := p.(provider.Canceler)
+	if !ok {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), askWithdrawTimeout)
+	defer cancel()
+	if err := canceler.Cancel(ctx, requestID); err != nil {
+		fmt.Fprintf(errOut, "warning: could not withdraw question %s: %v\n", requestID, err)
+	}
+}
+
+// notifyAskTimeout gives the provider a brief, independent window to tell
+// the human the question timed out. Failures only warn.
+func notifyAskTimeout(errOut io.Writer, p provider.Provider, requestID string, waited time.Duration) {
+	notifier, ok := p.(provider.TimeoutNotifier)
+	if !ok {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), askWithdrawTimeout)
+	defer cancel()
+	if err := notifier.NotifyTimeout(ctx, requestID, waited); err != nil {
+		fmt.Fprintf(errOut, "warning: could not mark question %s as timed out: %v\n", requestID, err)
+	}
+}
+
+// confirmAskReply tells the human how their reply was understood, when the
+// provider supports it and telegram.confirm_replies allows it. Failures only
+// warn: the answer has already been received.
+func confirmAskReply(errOut io.Writer, cfg config.Config, p provider.Provider, reply contract.Reply, result contract.AskResult) {
+	ack, ok := p.(provider.Acknowledger)
+	if !ok {
+		return
+	}
+	text := askConfirmationText(cfg.Telegram.ConfirmReplies, result)
+	if text == "" {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), askConfirmTimeout)
+	defer cancel()
+	if err := ack.Acknowledge(ctx, reply, text); err != nil {
+		fmt.Fprintf(errOut, "warning: could not confirm reply to %s: %v\n", result.RequestID, err)
+	}
+}
+
+// askConfirmationText renders the acknowledgment for result from the chosen
+// choice labels, or returns "" when none should be sent.
+func askConfirmationText(mode string, result contract.AskResult) string {
+	mode = strings.ToLower(strings.TrimSpace(mode))
+	if mode == config.TelegramConfirmRepliesOff {
+		return ""
+	}
+	if result.QuestionType != contract.QuestionTypeChoice {
+		if mode != config.TelegramConfirmRepliesAll {
+			return ""
+		}
+		return "Got it."
+	}
+
+	parts := make([]string, 0, len(result.SelectedIDs)+1)
+	for _, id := range result.SelectedIDs {
+		for _, choice := range result.Choices {
+			if choice.ID == id {
+				parts = append(parts, fmt.Sprintf("%s) %s", choice.ID, choice.Text))
+				break
+			}
+		}
+	}
+	if result.OtherText != "" {
+		parts = append(parts, fmt.Sprintf("other: %q", result.OtherText))
+	}
+	if len(parts) == 0 {
+		return ""
+	}
+	return "Got it: " + strings.Join(parts, ", ")
+}
+
+// askStatusWriter returns where progress chatter goes. Errors are still
+// returned to the caller and printed to stderr by main.
+func askStatusWriter(runtimeIO IO, quiet bool) io.Writer {
+	if quiet || isTruthyEnv(os.Getenv(envAskQuiet)) {
+		return io.Discard
+	}
+	return runtimeIO.ErrOut
+}
+
+func isTruthyEnv(raw string) bool {
+	v, err := strconv.ParseBool(strings.TrimSpace(raw))
+	return err == nil && v
+}
+
+func parsePriority(raw string) (contract.Priority, error) {
+	switch p := contract.Priority(strings.ToLower(strings.TrimSpace(raw))); p {
+	case "":
+		return contract.PriorityNormal, nil
+	case contract.PriorityLow, contract.PriorityNormal, contract.PriorityHigh:
+		return p, nil
+	default:
+		return "", fmt.Errorf("--priority must be low, normal, or high")
+	}
+}
+
+func parseTags(raw []string) (map[string]string, error) {
+	if len(raw) == 0 {
+		return nil, nil
+	}
+	if len(raw) > askMaxTags {
+		return nil, fmt.Errorf("at most %d --tag values are allowed", askMaxTags)
+	}
+
+	tags := make(map[string]string, len(raw))
+	for _, item := range raw {
+		key, value, ok := strings.Cut(item, "=")
+		key = strings.TrimSpace(key)
+		value = strings.TrimSpace(value)
+		if !ok || key == "" {
+			return nil, fmt.Errorf("invalid tag %q (want key=value)", item)
+		}
+		if len(key) > askMaxTagBytes || len(value) > askMaxTagBytes {
+			return nil, fmt.Errorf("tag %q exceeds %d bytes", key, askMaxTagBytes)
+		}
+		if _, dup := tags[key]; dup {
+			return nil, fmt.Errorf("duplicate tag %q", key)
+		}
+		tags[key] = value
+	}
+	return tags, nil
+}
+
 func parseChoices(raw []string) ([]contract.Choice, error) {
 	choices := make([]contract.Choice, 0, len(raw))
 	seen := map[string]struct{}{}
@@ -160,15 +669,18 @@ func parseChoices(raw []string) ([]contract.Choice, error) {
 			continue
 		}
 
+		head, description, _ := strings.Cut(rawItem, "::")
+		description = strings.TrimSpace(description)
+
 		id := ""
 		text := ""
-		parts := strings.SplitN(rawItem, ":", 2)
+		parts := strings.SplitN(head, ":", 2)
 		if len(parts) == 2 {
 			id = normalizeChoiceID(parts[0])
 			text = strings.TrimSpace(parts[1])
 		} else {
 			id = autoChoiceID(i)
-			text = rawItem
+			text = strings.TrimSpace(head)
 		}
 
 		if id == "" || text == "" {
@@ -178,7 +690,7 @@ func parseChoices(raw []string) ([]contract.Choice, error) {
 			return nil, fmt.Errorf("duplicate choice id %q", id)
 		}
 		seen[id] = struct{}{}
-		choices = append(choices, contract.Choice{ID: id, Text: text})
+		choices = append(choices, contract.Choice{ID: id, Text: text, Description: description})
 	}
 
 	return choices, nil
@@ -215,10 +727,17 @@ func classifyChoiceReply(req contract.AskRequest, raw string) ([]string, string)
 		byText[strings.ToLower(strings.TrimSpace(c.Text))] = id
 	}
 
+	// Matching runs on an emoji-normalized copy; "other" text keeps the
+	// human's original wording.
+	matchText := normalizeReplyEmoji(text)
+
 	// If the reply is a sentence (space-separated, no explicit delimiters),
 	// avoid falsely matching incidental tokens like "a" to choice "A".
-	if !strings.ContainsAny(text, ",;\n") && strings.Contains(text, " ") {
-		if id, ok := byText[strings.ToLower(strings.TrimSpace(text))]; ok {
+	if !strings.ContainsAny(matchText, ",;\n") && strings.Contains(matchText, " ") {
+		if id, ok := byText[strings.ToLower(strings.TrimSpace(matchText))]; ok {
+			return []string{id}, ""
+		}
+		if id, ok := fuzzyMatchChoice(req.Choices, matchText); ok {
 			return []string{id}, ""
 		}
 		if req.AllowOther {
@@ -231,9 +750,11 @@ func classifyChoiceReply(req contract.AskRequest, raw string) ([]string, string)
 		return nil, ""
 	}
 
-	tokens := splitReplyTokens(text)
+	tokens := splitReplyTokens(matchText)
 	selected := make([]string, 0, len(tokens))
 	selectedSet := map[string]struct{}{}
+	var byWord []string
+	unresolved := false
 
 	for _, token := range tokens {
 		n := normalizeChoiceID(token)
@@ -245,6 +766,16 @@ func classifyChoiceReply(req contract.AskRequest, raw string) ([]string, string)
 			continue
 		}
 
+		if ids, ok := expandChoiceRange(req.Choices, n); ok {
+			for _, id := range ids {
+				if _, seen := selectedSet[id]; !seen {
+					selectedSet[id] = struct{}{}
+					selected = append(selected, id)
+				}
+			}
+			continue
+		}
+
 		if idx, err := strconv.Atoi(n); err == nil {
 			if idx >= 1 && idx <= len(req.Choices) {
 				id := normalizeChoiceID(req.Choices[idx-1].ID)
@@ -257,6 +788,16 @@ func classifyChoiceReply(req contract.AskRequest, raw string) ([]string, string)
 		}
 
 		if id, ok := byText[strings.ToLower(strings.TrimSpace(token))]; ok {
+			byWord = append(byWord, id)
+			continue
+		}
+		unresolved = true
+	}
+
+	// A choice named by one word of a longer sentence, as in "no idea, what
+	// does this do?", is not an answer.
+	if !unresolved {
+		for _, id := range byWord {
 			if _, seen := selectedSet[id]; !seen {
 				selectedSet[id] = struct{}{}
 				selected = append(selected, id)
@@ -264,6 +805,12 @@ func classifyChoiceReply(req contract.AskRequest, raw string) ([]string, string)
 		}
 	}
 
+	if len(selected) == 0 {
+		if id, ok := fuzzyMatchChoice(req.Choices, matchText); ok {
+			selected = append(selected, id)
+		}
+	}
+
 	slices.Sort(selected)
 	if len(selected) > 0 {
 		if strings.HasPrefix(strings.ToLower(text), "other:") {
@@ -287,6 +834,147 @@ func classifyChoiceReply(req contract.AskRequest, raw string) ([]string, string)
 	return nil, ""
 }
 
+// normalizeReplyEmoji rewrites keycap and circled digits (1️⃣, 🔟, ②, ❸) as
+// comma-separated plain numbers, drops variation selectors and zero-width
+// joiners, and strips a leading ✅ or 👍 so "✅ 2" reads as "2".
+func normalizeReplyEmoji(s string) string {
+	var b strings.Builder
+	runes := []rune(s)
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		switch {
+		case r == '\uFE0F' || r == '\uFE0E' || r == '\u200D' || r == '\u20E3':
+			continue
+		case r >= '0' && r <= '9' && keycapFollows(runes[i+1:]):
+			b.WriteString("," + string(r) + ",")
+		case r == '\U0001F51F':
+			b.WriteString(",10,")
+		case r >= '\u2460' && r <= '\u2473':
+			b.WriteString("," + strconv.Itoa(int(r-'\u2460')+1) + ",")
+		case r >= '\u2776' && r <= '\u277F':
+			b.WriteString("," + strconv.Itoa(int(r-'\u2776')+1) + ",")
+		default:
+			b.WriteRune(r)
+		}
+	}
+
+	out := strings.TrimSpace(b.String())
+	for _, prefix := range []string{"\u2705", "\U0001F44D"} {
+		if rest, ok := strings.CutPrefix(out, prefix); ok {
+			rest = strings.TrimLeftFunc(rest, func(r rune) bool {
+				return r >= '\U0001F3FB' && r <= '\U0001F3FF'
+			})
+			out = strings.TrimSpace(rest)
+			break
+		}
+	}
+	return strings.Trim(out, ", ")
+}
+
+func keycapFollows(rest []rune) bool {
+	for _, r := range rest {
+		switch r {
+		case '\uFE0F', '\uFE0E':
+			continue
+		case '\u20E3':
+			return true
+		default:
+			return false
+		}
+	}
+	return false
+}
+
+// fuzzyMatchChoice maps a free-text reply to the single choice whose text
+// contains the reply as a run of words. A reply that only mentions a choice,
+// as in "don't wait" or "yes but later", is not an answer; ambiguous or
+// too-short replies match nothing either.
+func fuzzyMatchChoice(choices []contract.Choice, reply string) (string, bool) {
+	if strings.HasPrefix(strings.ToLower(strings.TrimSpace(reply)), "other:") {
+		return "", false
+	}
+	replyWords := normalizedWords(reply)
+	if len(strings.Join(replyWords, "")) < 3 {
+		return "", false
+	}
+
+	match := ""
+	for _, c := range choices {
+		choiceWords := normalizedWords(c.Text)
+		if len(choiceWords) == 0 {
+			continue
+		}
+		if !containsWordRun(choiceWords, replyWords) {
+			continue
+		}
+		if match != "" {
+			return "", false
+		}
+		match = normalizeChoiceID(c.ID)
+	}
+	return match, match != ""
+}
+
+func normalizedWords(s string) []string {
+	return strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+func containsWordRun(haystack, needle []string) bool {
+	if len(needle) == 0 || len(needle) > len(haystack) {
+		return false
+	}
+	for i := 0; i+len(needle) <= len(haystack); i++ {
+		if slices.Equal(haystack[i:i+len(needle)], needle) {
+			return true
+		}
+	}
+	return false
+}
+
+// expandChoiceRange expands "1-3" or "B-D" into the choice IDs it spans.
+// Both endpoints must resolve to existing choices, so dates and other
+// dashed free text are never treated as ranges.
+func expandChoiceRange(choices []contract.Choice, token string) ([]string, bool) {
+	parts := strings.Split(token, "-")
+	if len(parts) != 2 {
+		return nil, false
+	}
+	from, ok := choicePosition(choices, parts[0])
+	if !ok {
+		return nil, false
+	}
+	to, ok := choicePosition(choices, parts[1])
+	if !ok {
+		return nil, false
+	}
+	if from > to {
+		from, to = to, from
+	}
+	ids := make([]string, 0, to-from+1)
+	for i := from; i <= to; i++ {
+		ids = append(ids, normalizeChoiceID(choices[i].ID))
+	}
+	return ids, true
+}
+
+func choicePosition(choices []contract.Choice, endpoint string) (int, bool) {
+	n := normalizeChoiceID(endpoint)
+	if n == "" {
+		return 0, false
+	}
+	for i, c := range choices {
+		if normalizeChoiceID(c.ID) == n {
+			return i, true
+		}
+	}
+	if idx, err := strconv.Atoi(n); err == nil && idx >= 1 && idx <= len(choices) {
+		return idx - 1, true
+	}
+	return 0, false
+}
+
 func splitReplyTokens(s string) []string {
 	fields := strings.FieldsFunc(s, func(r rune) bool {
 		switch r {
@@ -314,3 +1002,99 @@ func newRequestID() (string, error) {
 	}
 	return hex.EncodeToString(b), nil
 }
+
+// applyQuietHours makes req silent during quiet hours or, with behavior
+// defer, holds it until they end. A deferral that would outlast the request
+// timeout sends silently instead. Urgent and high-priority questions, and
+// questions asked in the terminal, are sent as usual.
+func applyQuietHours(ctx context.Context, cfg config.Config, providerName string, req contract.AskRequest, urgent bool, status, errOut io.Writer) (contract.AskRequest, error) {
+	if urgent || req.Priority == contract.PriorityHigh || providerName != "telegram" {
+		return req, nil
+	}
+	now := askNowFn()
+	active, ends, err := cfg.QuietHours.Active(now)
+	if err != nil || !active {
+		return req, err
+	}
+	wait := ends.Sub(now)
+	if cfg.QuietHours.EffectiveBehavior() == config.QuietHoursDefer {
+		if dl, ok := ctx.Deadline(); !ok || wait < time.Until(dl) {
+			fmt.Fprintf(status, "note: quiet hours until %s; holding request %s until then (--urgent sends now)\n", ends.Format("15:04 MST"), req.RequestID)
+			deferAsk(ctx, cfg, providerName, req, ends, errOut)
+			timer := time.NewTimer(wait)
+			defer timer.Stop()
+			select {
+			case <-ctx.Done():
+				return req, ctx.Err()
+			case <-timer.C:
+			}
+			return req, nil
+		}
+		fmt.Fprintf(status, "note: quiet hours last until %s, past the request timeout; sending silently\n", ends.Format("15:04 MST"))
+	}
+	req.Silent = true
+	return req, nil
+}
+
+// deferAsk lets the provider list req as deferred while quiet hours hold it.
+// It is best effort: the question is still sent when they end.
+func deferAsk(ctx context.Context, cfg config.Config, providerName string, req contract.AskRequest, until time.Time, errOut io.Writer) {
+	p, err := askProviderFn(cfg, providerName)
+	if err != nil {
+		return
+	}
+	defer p.Close()
+	deferrer, ok := p.(provider.Deferrer)
+	if !ok {
+		return
+	}
+	if err := deferrer.Defer(ctx, req, until); err != nil {
+		fmt.Fprintf(errOut, "warning: could not record deferred question %s: %v\n", req.RequestID, err)
+	}
+}
+
+// setProviderStatus routes p's progress notes to status, so --quiet
+// silences them along with the rest.
+func setProviderStatus(p provider.Provider, status io.Writer) {
+	if r, ok := p.(provider.StatusReporter); ok {
+		r.SetStatus(status)
+	}
+}
+
+// resolveAskSilent decides whether a question is delivered silently: an
+// explicit --silent or --silent=false wins, then low priority and
+// telegram.silent make it silent.
+func resolveAskSilent(cfg config.Config, priority contract.Priority, override *bool) bool {
+	if override != nil {
+		return *override
+	}
+	return priority == contract.PriorityLow || cfg.Telegram.Silent
+}
+
+// resolveSenderLabel fills the {host} and {repo} placeholders of
+// telegram.sender_label: the short hostname, and the name of the git repo
+// the command runs in. A placeholder that cannot be resolved is left empty.
+func resolveSenderLabel(label string) string {
+	label = strings.TrimSpace(label)
+	if label == "" {
+		return ""
+	}
+	if strings.Contains(label, "{host}") {
+		host, err := askHostnameFn()
+		if err != nil {
+			host = ""
+		}
+		host, _, _ = strings.Cut(host, ".")
+		label = strings.ReplaceAll(label, "{host}", host)
+	}
+	if strings.Contains(label, "{repo}") {
+		repo := ""
+		if cwd, err := setupCurrentDirFn(); err == nil {
+			if root, found, err := gitrepo.FindRoot(cwd); err == nil && found {
+				repo = filepath.Base(root)
+			}
+		}
+		label = strings.ReplaceAll(label, "{repo}", repo)
+	}
+	return strings.Trim(label, " ·")
+}
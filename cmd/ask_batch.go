@@ -0,0 +1,265 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/AlhasanIQ/consult-human/config"
+	"github.com/AlhasanIQ/consult-human/contract"
+	"github.com/AlhasanIQ/consult-human/provider"
+)
+
+// askBatchItem is one entry of an --batch file. Choices use the same
+// "id:text" syntax as --choice; Timeout bounds this question only.
+type askBatchItem struct {
+	Question   string            `json:"question" yaml:"question"`
+	Choices    []string          `json:"choices,omitempty" yaml:"choices,omitempty"`
+	AllowOther bool              `json:"allow_other,omitempty" yaml:"allow_other,omitempty"`
+	Priority   string            `json:"priority,omitempty" yaml:"priority,omitempty"`
+	Tags       map[string]string `json:"tags,omitempty" yaml:"tags,omitempty"`
+	Timeout    string            `json:"timeout,omitempty" yaml:"timeout,omitempty"`
+}
+
+type askBatchOptions struct {
+	path             string
+	providerOverride string
+	timeoutOverride  string
+	quiet            bool
+	watchEdits       bool
+	silent           *bool
+}
+
+type askBatchEntry struct {
+	req     contract.AskRequest
+	timeout time.Duration
+}
+
+func runAskBatch(opts askBatchOptions, cfg config.Config, io IO) error {
+	entries, err := loadAskBatch(opts.path)
+	if err != nil {
+		return err
+	}
+	timeout, err := resolveAskTimeout(cfg, opts.timeoutOverride)
+	if err != nil {
+		return err
+	}
+	origin := resolveSenderLabel(cfg.Telegram.SenderLabel)
+	for i := range entries {
+		entries[i].req.Origin = origin
+		entries[i].req.Silent = resolveAskSilent(cfg, entries[i].req.Priority, opts.silent)
+	}
+
+	baseCtx, stopSignals := askSignalContextFn(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stopSignals()
+
+	ctx, cancel := context.WithTimeout(baseCtx, timeout)
+	defer cancel()
+
+	status := askStatusWriter(io, opts.quiet)
+	chain := askProviderChain(cfg, opts.providerOverride)
+	started := time.Now()
+
+	for i := range entries {
+		entries[i].req, err = applyQuietHours(ctx, cfg, chain[0], entries[i].req, false, status, io.ErrOut)
+		if err != nil {
+			recordAskHistory(io.ErrOut, entries[i].req, chain[0], started, nil, err)
+			return err
+		}
+	}
+
+	// The first question picks the provider; the rest go through the same one
+	// so every request shares its pending store and poller.
+	p, err := sendWithFallback(ctx, cfg, chain, entries[0].req, status, io.ErrOut)
+	if err != nil {
+		recordAskHistory(io.ErrOut, entries[0].req, chain[0], started, nil, err)
+		return err
+	}
+	defer p.Close()
+
+	sent := entries[:1]
+	for _, entry := range entries[1:] {
+		fmt.Fprintf(status, "Sending request %s via %s...\n", entry.req.RequestID, p.Name())
+		if _, err := p.Send(ctx, entry.req); err != nil {
+			recordAskHistory(io.ErrOut, entry.req, p.Name(), started, nil, err)
+			fmt.Fprintf(io.ErrOut, "warning: could not send %s: %v\n", entry.req.RequestID, err)
+			continue
+		}
+		sent = append(sent, entry)
+	}
+
+	fmt.Fprintf(status, "Waiting for %d human replies...\n", len(sent))
+
+	var (
+		mu        sync.Mutex
+		wg        sync.WaitGroup
+		failed    []string
+		encodeErr error
+	)
+	enc := json.NewEncoder(io.Out)
+	enc.SetEscapeHTML(false)
+
+	for _, entry := range sent {
+		wg.Add(1)
+		go func(entry askBatchEntry) {
+			defer wg.Done()
+
+			itemCtx := ctx
+			if entry.timeout > 0 {
+				var itemCancel context.CancelFunc
+				itemCtx, itemCancel = context.WithTimeout(ctx, entry.timeout)
+				defer itemCancel()
+			}
+
+			reply, err := p.Receive(itemCtx, entry.req.RequestID)
+			if errors.Is(err, provider.ErrCancelledByHuman) {
+				result := buildCancelledAskResult(entry.req, p.Name())
+				recordAskHistory(io.ErrOut, entry.req, p.Name(), started, &result, err)
+				mu.Lock()
+				defer mu.Unlock()
+				if err := enc.Encode(result); err != nil && encodeErr == nil {
+					encodeErr = err
+				}
+				return
+			}
+			if err != nil {
+				recordAskHistory(io.ErrOut, entry.req, p.Name(), started, nil, err)
+				if errors.Is(err, context.DeadlineExceeded) && baseCtx.Err() == nil {
+					waited := timeout
+					if entry.timeout > 0 && entry.timeout < timeout {
+						waited = entry.timeout
+					}
+					notifyAskTimeout(io.ErrOut, p, entry.req.RequestID, waited)
+				}
+				mu.Lock()
+				defer mu.Unlock()
+				failed = append(failed, entry.req.RequestID)
+				if errors.Is(err, context.DeadlineExceeded) {
+					fmt.Fprintf(io.ErrOut, "timed out: %s (%s)\n", entry.req.RequestID, historyExcerpt(entry.req.Question))
+				} else {
+					fmt.Fprintf(io.ErrOut, "failed: %s (%s): %v\n", entry.req.RequestID, historyExcerpt(entry.req.Question), err)
+				}
+				return
+			}
+
+			if opts.watchEdits {
+				reply = awaitAskCorrection(baseCtx, io.ErrOut, p, reply)
+			}
+			result := buildAskResult(entry.req, reply, p.Name())
+			confirmAskReply(io.ErrOut, cfg, p, reply, result)
+			recordAskHistory(io.ErrOut, entry.req, p.Name(), started, &result, nil)
+			mu.Lock()
+			defer mu.Unlock()
+			if err := enc.Encode(result); err != nil && encodeErr == nil {
+				encodeErr = err
+			}
+		}(entry)
+	}
+	wg.Wait()
+
+	if encodeErr != nil {
+		return encodeErr
+	}
+	if unanswered := len(entries) - (len(sent) - len(failed)); unanswered > 0 {
+		if baseCtx.Err() != nil {
+			for _, id := range failed {
+				withdrawAsk(io.ErrOut, p, id)
+			}
+			return &exitError{code: ExitCodeInterrupted, err: fmt.Errorf("interrupted: %d of %d questions unanswered", unanswered, len(entries))}
+		}
+		return fmt.Errorf("%d of %d questions were not answered", unanswered, len(entries))
+	}
+	return nil
+}
+
+func loadAskBatch(path string) ([]askBatchEntry, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	// JSON is valid YAML, so one decoder covers both file types.
+	var items []askBatchItem
+	if err := yaml.Unmarshal(b, &items); err != nil {
+		return nil, fmt.Errorf("parse batch file %s: %w", path, err)
+	}
+	if len(items) == 0 {
+		return nil, fmt.Errorf("batch file %s has no questions", path)
+	}
+
+	entries := make([]askBatchEntry, 0, len(items))
+	for i, item := range items {
+		entry, err := buildAskBatchEntry(item)
+		if err != nil {
+			return nil, fmt.Errorf("batch question %d: %w", i+1, err)
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+func buildAskBatchEntry(item askBatchItem) (askBatchEntry, error) {
+	question := strings.TrimSpace(item.Question)
+	if question == "" {
+		return askBatchEntry{}, fmt.Errorf("missing question")
+	}
+	choices, err := parseChoices(item.Choices)
+	if err != nil {
+		return askBatchEntry{}, err
+	}
+	if len(choices) == 0 && item.AllowOther {
+		return askBatchEntry{}, fmt.Errorf("allow_other requires at least one choice")
+	}
+	priority, err := parsePriority(item.Priority)
+	if err != nil {
+		return askBatchEntry{}, err
+	}
+	if len(item.Tags) > askMaxTags {
+		return askBatchEntry{}, fmt.Errorf("at most %d tags are allowed", askMaxTags)
+	}
+	for k, v := range item.Tags {
+		if len(k) > askMaxTagBytes || len(v) > askMaxTagBytes {
+			return askBatchEntry{}, fmt.Errorf("tag %q exceeds %d bytes", k, askMaxTagBytes)
+		}
+	}
+
+	var timeout time.Duration
+	if raw := strings.TrimSpace(item.Timeout); raw != "" {
+		timeout, err = time.ParseDuration(raw)
+		if err != nil {
+			return askBatchEntry{}, fmt.Errorf("invalid timeout: %w", err)
+		}
+		if timeout <= 0 {
+			return askBatchEntry{}, fmt.Errorf("timeout must be > 0")
+		}
+	}
+
+	reqID, err := newRequestID()
+	if err != nil {
+		return askBatchEntry{}, err
+	}
+	qType := contract.QuestionTypeOpen
+	if len(choices) > 0 {
+		qType = contract.QuestionTypeChoice
+	}
+	return askBatchEntry{
+		req: contract.AskRequest{
+			RequestID:  reqID,
+			Question:   question,
+			Type:       qType,
+			Choices:    choices,
+			AllowOther: item.AllowOther,
+			Priority:   priority,
+			Tags:       item.Tags,
+			SentAt:     time.Now().UTC(),
+		},
+		timeout: timeout,
+	}, nil
+}
@@ -0,0 +1,196 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/AlhasanIQ/consult-human/config"
+	"github.com/AlhasanIQ/consult-human/contract"
+	"github.com/AlhasanIQ/consult-human/provider"
+)
+
+// fakeBatchProvider answers questions found in answers after delay and leaves
+// the rest unanswered until the context ends.
+type fakeBatchProvider struct {
+	answers map[string]string
+	delay   time.Duration
+
+	mu   sync.Mutex
+	sent map[string]contract.AskRequest
+}
+
+func (f *fakeBatchProvider) Name() string { return "fake" }
+
+func (f *fakeBatchProvider) Close() error { return nil }
+
+func (f *fakeBatchProvider) Send(_ context.Context, req contract.AskRequest) (string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.sent[req.RequestID] = req
+	return req.RequestID, nil
+}
+
+func (f *fakeBatchProvider) Receive(ctx context.Context, requestID string) (contract.Reply, error) {
+	f.mu.Lock()
+	req, ok := f.sent[requestID]
+	f.mu.Unlock()
+	if !ok {
+		return contract.Reply{}, fmt.Errorf("unknown request id %q", requestID)
+	}
+	answer, ok := f.answers[req.Question]
+	if !ok {
+		<-ctx.Done()
+		return contract.Reply{}, ctx.Err()
+	}
+	select {
+	case <-ctx.Done():
+		return contract.Reply{}, ctx.Err()
+	case <-time.After(f.delay):
+	}
+	return contract.Reply{RequestID: requestID, Text: answer, Raw: answer, ReceivedAt: time.Now().UTC()}, nil
+}
+
+func stubBatchProvider(t *testing.T, fake *fakeBatchProvider) {
+	t.Helper()
+	t.Setenv(config.EnvConfigPath, filepath.Join(t.TempDir(), "config.yaml"))
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+	t.Setenv(envAskQuiet, "1")
+	fake.sent = make(map[string]contract.AskRequest)
+	orig := askProviderFn
+	askProviderFn = func(config.Config, string) (provider.Provider, error) { return fake, nil }
+	t.Cleanup(func() { askProviderFn = orig })
+}
+
+func writeBatchFile(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("write batch file: %v", err)
+	}
+	return path
+}
+
+func TestRunAskBatchStreamsResults(t *testing.T) {
+	fake := &fakeBatchProvider{
+		answers: map[string]string{"Which DB?": "postgres", "Which approach?": "B"},
+		delay:   10 * time.Millisecond,
+	}
+	stubBatchProvider(t, fake)
+
+	path := writeBatchFile(t, "batch.yaml", `
+- question: Which DB?
+- question: Which approach?
+  choices: ["A:Shared", "B:Inline"]
+`)
+
+	var out bytes.Buffer
+	if err := runAsk([]string{"--batch", path}, IO{In: strings.NewReader(""), Out: &out, ErrOut: &bytes.Buffer{}}); err != nil {
+		t.Fatalf("runAsk returned error: %v", err)
+	}
+
+	got := map[string]contract.AskResult{}
+	dec := json.NewDecoder(&out)
+	for dec.More() {
+		var result contract.AskResult
+		if err := dec.Decode(&result); err != nil {
+			t.Fatalf("decode NDJSON: %v", err)
+		}
+		got[result.Question] = result
+	}
+	if len(got) != 2 {
+		t.Fatalf("expected two results, got %#v", got)
+	}
+	if got["Which DB?"].Text != "postgres" {
+		t.Fatalf("unexpected open result: %#v", got["Which DB?"])
+	}
+	if ids := got["Which approach?"].SelectedIDs; len(ids) != 1 || ids[0] != "B" {
+		t.Fatalf("unexpected choice result: %#v", got["Which approach?"])
+	}
+}
+
+func TestRunAskBatchReportsPerQuestionTimeout(t *testing.T) {
+	fake := &fakeBatchProvider{answers: map[string]string{"Ship it?": "yes"}}
+	stubBatchProvider(t, fake)
+
+	path := writeBatchFile(t, "batch.json", `[
+		{"question": "Ship it?"},
+		{"question": "Nobody will answer this", "timeout": "30ms"}
+	]`)
+
+	var out, errOut bytes.Buffer
+	err := runAsk([]string{"--batch", path, "--timeout", "5s"}, IO{In: strings.NewReader(""), Out: &out, ErrOut: &errOut})
+	if err == nil || !strings.Contains(err.Error(), "1 of 2") {
+		t.Fatalf("expected partial completion error, got %v", err)
+	}
+	if !strings.Contains(errOut.String(), "timed out:") || !strings.Contains(errOut.String(), "Nobody will answer this") {
+		t.Fatalf("expected timed-out question on stderr, got %q", errOut.String())
+	}
+	if strings.Count(out.String(), "\n") != 1 || !strings.Contains(out.String(), `"text":"yes"`) {
+		t.Fatalf("expected the answered question on stdout, got %q", out.String())
+	}
+}
+
+func TestRunAskBatchValidatesFile(t *testing.T) {
+	stubBatchProvider(t, &fakeBatchProvider{})
+	cases := map[string]string{
+		"empty.yaml":       "[]",
+		"no-question.yaml": "- choices: [A:One]",
+		"bad-timeout.yaml": "- question: Ship it?\n  timeout: soon",
+		"bad-other.yaml":   "- question: Ship it?\n  allow_other: true",
+	}
+	for name, content := range cases {
+		path := writeBatchFile(t, name, content)
+		if err := runAsk([]string{"--batch", path}, IO{In: strings.NewReader(""), Out: &bytes.Buffer{}, ErrOut: &bytes.Buffer{}}); err == nil {
+			t.Fatalf("%s: expected validation error", name)
+		}
+	}
+	for _, args := range [][]string{
+		{"Ship it?"},
+		{"--tag", "repo=api"},
+		{"--priority", "high"},
+		{"--remind-after", "10m"},
+		{"--follow-up", "req-1"},
+		{"--urgent"},
+		{"--show-deadline"},
+	} {
+		args = append([]string{"--batch", "x.yaml"}, args...)
+		if err := runAsk(args, IO{In: strings.NewReader(""), Out: &bytes.Buffer{}, ErrOut: &bytes.Buffer{}}); err == nil || !strings.Contains(err.Error(), "cannot be combined") {
+			t.Fatalf("%v: expected a combination error, got %v", args, err)
+		}
+	}
+}
+
+func TestRunAskBatchSendsSilentlyDuringQuietHours(t *testing.T) {
+	fake := &fakeBatchProvider{answers: map[string]string{"Ship it?": "yes", "Ship it now?": "yes"}}
+	stubBatchProvider(t, fake)
+	cfg := config.Default()
+	cfg.QuietHours = config.QuietHours{Start: "23:00", End: "07:00", Timezone: "UTC"}
+	if err := config.Save(cfg); err != nil {
+		t.Fatalf("save config: %v", err)
+	}
+	stubAskClock(t, time.Date(2026, 3, 10, 2, 0, 0, 0, time.UTC))
+
+	path := writeBatchFile(t, "batch.yaml", `
+- question: Ship it?
+- question: Ship it now?
+  priority: high
+`)
+	if err := runAsk([]string{"--batch", path}, IO{In: strings.NewReader(""), Out: &bytes.Buffer{}, ErrOut: &bytes.Buffer{}}); err != nil {
+		t.Fatalf("runAsk returned error: %v", err)
+	}
+	silent := map[string]bool{}
+	for _, req := range fake.sent {
+		silent[req.Question] = req.Silent
+	}
+	if !silent["Ship it?"] || silent["Ship it now?"] {
+		t.Fatalf("expected only the normal-priority question to be silent, got %v", silent)
+	}
+}
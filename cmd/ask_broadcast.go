@@ -0,0 +1,260 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/AlhasanIQ/consult-human/config"
+	"github.com/AlhasanIQ/consult-human/contract"
+	"github.com/AlhasanIQ/consult-human/provider"
+)
+
+// askBroadcastList returns the providers a question is broadcast to: the
+// --broadcast list, or broadcast_providers unless --provider picks a single
+// provider. Fewer than two providers means no broadcast.
+func askBroadcastList(cfg config.Config, flagValue, providerOverride string) ([]string, error) {
+	list := cfg.BroadcastProviders
+	if strings.TrimSpace(flagValue) != "" {
+		if strings.TrimSpace(providerOverride) != "" {
+			return nil, fmt.Errorf("--broadcast cannot be combined with --provider")
+		}
+		if err := config.Set(&cfg, "broadcast_providers", flagValue); err != nil {
+			return nil, fmt.Errorf("invalid --broadcast: %w", err)
+		}
+		list = cfg.BroadcastProviders
+	} else if strings.TrimSpace(providerOverride) != "" {
+		return nil, nil
+	}
+
+	names := make([]string, 0, len(list))
+	for _, name := range list {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name != "" && !slices.Contains(names, name) {
+			names = append(names, name)
+		}
+	}
+	if len(names) < 2 {
+		if strings.TrimSpace(flagValue) != "" {
+			return nil, fmt.Errorf("--broadcast needs at least two providers")
+		}
+		return nil, nil
+	}
+	return names, nil
+}
+
+// broadcastProvider sends one question through several providers at once.
+// The first of them to return an answer wins: the others stop waiting and
+// are told the question was answered elsewhere. Until then it stands for all
+// of them, so withdrawing or timing out the question reaches every one.
+type broadcastProvider struct {
+	errOut  io.Writer
+	members []provider.Provider
+	winner  provider.Provider
+}
+
+// broadcastResult is one member's outcome.
+type broadcastResult struct {
+	p     provider.Provider
+	reply contract.Reply
+	err   error
+}
+
+// sendBroadcast delivers req through every provider in names concurrently.
+// It fails only when no provider accepted the question.
+func sendBroadcast(ctx context.Context, cfg config.Config, names []string, req contract.AskRequest, status, errOut io.Writer) (provider.Provider, error) {
+	b := &broadcastProvider{errOut: errOut}
+	var candidates []provider.Provider
+	var lastErr error
+	for _, name := range names {
+		p, err := askProviderFn(cfg, name)
+		if err != nil {
+			if errors.Is(err, provider.ErrProviderDisabled) {
+				fmt.Fprintf(status, "note: skipping %s: %v\n", name, err)
+				continue
+			}
+			fmt.Fprintf(errOut, "warning: provider %s unavailable: %v\n", name, err)
+			lastErr = err
+			continue
+		}
+		setProviderStatus(p, status)
+		fmt.Fprintf(status, "Sending request %s via %s...\n", req.RequestID, p.Name())
+		candidates = append(candidates, p)
+	}
+
+	errs := make([]error, len(candidates))
+	var wg sync.WaitGroup
+	for i, p := range candidates {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, errs[i] = p.Send(ctx, req)
+		}()
+	}
+	wg.Wait()
+
+	for i, p := range candidates {
+		if errs[i] != nil {
+			_ = p.Close()
+			lastErr = errs[i]
+			fmt.Fprintf(errOut, "warning: send via %s failed: %v\n", p.Name(), errs[i])
+			continue
+		}
+		b.members = append(b.members, p)
+	}
+	if len(b.members) == 0 {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		if lastErr == nil {
+			return nil, fmt.Errorf("no enabled provider available (tried %s)", strings.Join(names, ", "))
+		}
+		return nil, lastErr
+	}
+	return b, nil
+}
+
+// Name is the winning provider once there is one, and otherwise every member
+// joined with "+".
+func (b *broadcastProvider) Name() string {
+	if b.winner != nil {
+		return b.winner.Name()
+	}
+	names := make([]string, 0, len(b.members))
+	for _, p := range b.members {
+		names = append(names, p.Name())
+	}
+	return strings.Join(names, "+")
+}
+
+func (b *broadcastProvider) Send(context.Context, contract.AskRequest) (string, error) {
+	return "", fmt.Errorf("broadcast questions are sent by sendBroadcast")
+}
+
+// Receive waits on every member with a shared context and returns the first
+// answer, or the human's dismissal. Each losing member is told where the
+// question was answered and closed.
+func (b *broadcastProvider) Receive(ctx context.Context, requestID string) (contract.Reply, error) {
+	recvCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	results := make(chan broadcastResult, len(b.members))
+	for _, p := range b.members {
+		go func() {
+			reply, err := p.Receive(recvCtx, requestID)
+			results <- broadcastResult{p: p, reply: reply, err: err}
+		}()
+	}
+
+	var won *broadcastResult
+	var lastErr error
+	for range b.members {
+		res := <-results
+		switch {
+		case won != nil:
+		case res.err == nil || errors.Is(res.err, provider.ErrCancelledByHuman):
+			won = &res
+			cancel()
+		default:
+			if ctx.Err() == nil {
+				fmt.Fprintf(b.errOut, "warning: waiting on %s failed: %v\n", res.p.Name(), res.err)
+			}
+			lastErr = res.err
+		}
+	}
+	if won == nil {
+		if ctx.Err() != nil {
+			return contract.Reply{}, ctx.Err()
+		}
+		return contract.Reply{}, lastErr
+	}
+	return b.settle(*won, requestID)
+}
+
+// settle makes won the winner: every other member is told where the
+// question was answered, or that it was dismissed, and closed.
+func (b *broadcastProvider) settle(won broadcastResult, requestID string) (contract.Reply, error) {
+	b.winner = won.p
+	for _, p := range b.members {
+		if p == won.p {
+			continue
+		}
+		if errors.Is(won.err, provider.ErrCancelledByHuman) {
+			withdrawAsk(b.errOut, p, requestID)
+		} else {
+			notifyAnsweredElsewhere(b.errOut, p, requestID, won.p.Name())
+		}
+		_ = p.Close()
+	}
+	b.members = []provider.Provider{won.p}
+	return won.reply, won.err
+}
+
+func (b *broadcastProvider) Close() error {
+	var errs []error
+	for _, p := range b.members {
+		errs = append(errs, p.Close())
+	}
+	return errors.Join(errs...)
+}
+
+// Cancel withdraws the question from every member still waiting on it.
+func (b *broadcastProvider) Cancel(ctx context.Context, requestID string) error {
+	var errs []error
+	for _, p := range b.members {
+		if c, ok := p.(provider.Canceler); ok {
+			errs = append(errs, c.Cancel(ctx, requestID))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// NotifyTimeout tells every member still waiting that the agent gave up.
+func (b *broadcastProvider) NotifyTimeout(ctx context.Context, requestID string, waited time.Duration) error {
+	var errs []error
+	for _, p := range b.members {
+		if n, ok := p.(provider.TimeoutNotifier); ok {
+			errs = append(errs, n.NotifyTimeout(ctx, requestID, waited))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// AwaitCorrection watches the winning provider for an edited reply.
+func (b *broadcastProvider) AwaitCorrection(ctx context.Context, reply contract.Reply) (contract.Reply, bool, error) {
+	c, ok := b.winner.(provider.Corrector)
+	if !ok {
+		return reply, false, nil
+	}
+	return c.AwaitCorrection(ctx, reply)
+}
+
+// Acknowledge confirms the reply on the winning provider.
+func (b *broadcastProvider) Acknowledge(ctx context.Context, reply contract.Reply, text string) error {
+	ack, ok := b.winner.(provider.Acknowledger)
+	if !ok {
+		return nil
+	}
+	return ack.Acknowledge(ctx, reply, text)
+}
+
+// notifyAnsweredElsewhere gives a losing provider a brief, independent window
+// to mark the question answered through winner. Providers that cannot say
+// that withdraw it instead, which also drops any pending record.
+func notifyAnsweredElsewhere(errOut io.Writer, p provider.Provider, requestID, winner string) {
+	n, ok := p.(provider.ElsewhereNotifier)
+	if !ok {
+		withdrawAsk(errOut, p, requestID)
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), askWithdrawTimeout)
+	defer cancel()
+	if err := n.NotifyAnsweredElsewhere(ctx, requestID, winner); err != nil {
+		fmt.Fprintf(errOut, "warning: could not mark question %s as answered on %s: %v\n", requestID, p.Name(), err)
+	}
+}
@@ -0,0 +1,155 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/AlhasanIQ/consult-human/config"
+	"github.com/AlhasanIQ/consult-human/contract"
+	"github.com/AlhasanIQ/consult-human/provider"
+)
+
+// fakeBroadcastMember is a fakeAskProvider that can instead wait for its
+// context to end, and records answered-elsewhere notes and escalation links.
+type fakeBroadcastMember struct {
+	fakeAskProvider
+	wait bool
+
+	elsewhere []string
+	linked    []string
+	closed    bool
+}
+
+func (f *fakeBroadcastMember) Receive(ctx context.Context, requestID string) (contract.Reply, error) {
+	if f.wait {
+		<-ctx.Done()
+		return contract.Reply{}, ctx.Err()
+	}
+	return f.fakeAskProvider.Receive(ctx, requestID)
+}
+
+func (f *fakeBroadcastMember) NotifyAnsweredElsewhere(_ context.Context, requestID, winner string) error {
+	f.elsewhere = append(f.elsewhere, requestID+"@"+winner)
+	return nil
+}
+
+func (f *fakeBroadcastMember) Link(_ context.Context, requestID, other string) error {
+	f.linked = append(f.linked, requestID+"@"+other)
+	return nil
+}
+
+func (f *fakeBroadcastMember) Close() error {
+	f.closed = true
+	return nil
+}
+
+func stubBroadcastProviders(t *testing.T, members ...*fakeBroadcastMember) {
+	t.Helper()
+	t.Setenv(config.EnvConfigPath, filepath.Join(t.TempDir(), "config.yaml"))
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+	t.Setenv(envAskQuiet, "1")
+	orig := askProviderFn
+	askProviderFn = func(_ config.Config, name string) (provider.Provider, error) {
+		for _, m := range members {
+			if m.name == name {
+				return m, nil
+			}
+		}
+		return nil, fmt.Errorf("unknown provider %q", name)
+	}
+	t.Cleanup(func() { askProviderFn = orig })
+}
+
+func TestAskBroadcastList(t *testing.T) {
+	cfg := config.Default()
+	if got, err := askBroadcastList(cfg, "Telegram, email, telegram", ""); err != nil || !reflect.DeepEqual(got, []string{"telegram", "email"}) {
+		t.Fatalf("unexpected broadcast list %#v err=%v", got, err)
+	}
+	if _, err := askBroadcastList(cfg, "telegram,email", "slack"); err == nil || !strings.Contains(err.Error(), "--provider") {
+		t.Fatalf("expected --broadcast with --provider to fail, got %v", err)
+	}
+	if _, err := askBroadcastList(cfg, "telegram", ""); err == nil || !strings.Contains(err.Error(), "at least two") {
+		t.Fatalf("expected a single provider to fail, got %v", err)
+	}
+	if _, err := askBroadcastList(cfg, "telegram,pigeon", ""); err == nil || !strings.Contains(err.Error(), "invalid --broadcast") {
+		t.Fatalf("expected an unknown provider to fail, got %v", err)
+	}
+
+	cfg.BroadcastProviders = []string{"slack", "zulip"}
+	if got, err := askBroadcastList(cfg, "", ""); err != nil || !reflect.DeepEqual(got, []string{"slack", "zulip"}) {
+		t.Fatalf("expected broadcast_providers to apply, got %#v err=%v", got, err)
+	}
+	if got, err := askBroadcastList(cfg, "", "slack"); err != nil || got != nil {
+		t.Fatalf("expected --provider to turn broadcast off, got %#v err=%v", got, err)
+	}
+}
+
+func TestRunAskBroadcastFirstAnswerWins(t *testing.T) {
+	telegram := &fakeBroadcastMember{fakeAskProvider: fakeAskProvider{name: "telegram"}, wait: true}
+	email := &fakeBroadcastMember{fakeAskProvider: fakeAskProvider{name: "email", reply: contract.Reply{Text: "ship it", Raw: "ship it"}}}
+	slack := &fakeBroadcastMember{fakeAskProvider: fakeAskProvider{name: "slack", sendErr: errors.New("channel_not_found")}}
+	stubBroadcastProviders(t, telegram, email, slack)
+
+	var out, errOut bytes.Buffer
+	if err := runAsk([]string{"--broadcast", "telegram,email,slack", "Ship it?"}, IO{Out: &out, ErrOut: &errOut}); err != nil {
+		t.Fatalf("runAsk returned error: %v", err)
+	}
+	if !strings.Contains(out.String(), `"provider":"email"`) || !strings.Contains(out.String(), `"text":"ship it"`) {
+		t.Fatalf("expected the email answer to win, got %s", out.String())
+	}
+	if len(telegram.sent) != 1 || len(email.sent) != 1 {
+		t.Fatalf("expected the question on every provider, got telegram=%d email=%d", len(telegram.sent), len(email.sent))
+	}
+	id := email.sent[0].RequestID
+	if !reflect.DeepEqual(telegram.elsewhere, []string{id + "@email"}) || !telegram.closed {
+		t.Fatalf("expected telegram to be told and closed, got %#v closed=%v", telegram.elsewhere, telegram.closed)
+	}
+	if len(email.elsewhere) != 0 || len(email.canceled) != 0 {
+		t.Fatalf("the winner must not be withdrawn, got %#v %#v", email.elsewhere, email.canceled)
+	}
+	if !strings.Contains(errOut.String(), "send via slack failed: channel_not_found") {
+		t.Fatalf("expected the failed send on stderr, got %q", errOut.String())
+	}
+}
+
+func TestRunAskBroadcastDismissalWithdrawsOthers(t *testing.T) {
+	telegram := &fakeBroadcastMember{fakeAskProvider: fakeAskProvider{name: "telegram", recvErr: provider.ErrCancelledByHuman}}
+	email := &fakeBroadcastMember{fakeAskProvider: fakeAskProvider{name: "email"}, wait: true}
+	stubBroadcastProviders(t, telegram, email)
+
+	var out bytes.Buffer
+	err := runAsk([]string{"--broadcast", "telegram,email", "Ship it?"}, IO{Out: &out, ErrOut: &bytes.Buffer{}})
+	var exitErr *exitError
+	if !errors.As(err, &exitErr) || exitErr.code != ExitCodeCancelled {
+		t.Fatalf("expected the cancelled exit code, got %v", err)
+	}
+	if !strings.Contains(out.String(), `"provider":"telegram"`) {
+		t.Fatalf("expected the dismissal from telegram, got %s", out.String())
+	}
+	if len(email.canceled) != 1 || len(email.elsewhere) != 0 {
+		t.Fatalf("expected email to be withdrawn, got canceled=%#v elsewhere=%#v", email.canceled, email.elsewhere)
+	}
+}
+
+func TestRunAskBroadcastTimeoutNotifiesEveryProvider(t *testing.T) {
+	telegram := &fakeBroadcastMember{fakeAskProvider: fakeAskProvider{name: "telegram"}, wait: true}
+	email := &fakeBroadcastMember{fakeAskProvider: fakeAskProvider{name: "email"}, wait: true}
+	stubBroadcastProviders(t, telegram, email)
+
+	err := runAsk([]string{"--broadcast", "telegram,email", "--timeout", "20ms", "Ship it?"}, IO{Out: &bytes.Buffer{}, ErrOut: &bytes.Buffer{}})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected a timeout, got %v", err)
+	}
+	if len(telegram.timedOut) != 1 || len(email.timedOut) != 1 {
+		t.Fatalf("expected both providers to be told, got telegram=%#v email=%#v", telegram.timedOut, email.timedOut)
+	}
+	if !telegram.closed || !email.closed {
+		t.Fatalf("expected both providers to be closed")
+	}
+}
@@ -0,0 +1,187 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/AlhasanIQ/consult-human/config"
+	"github.com/AlhasanIQ/consult-human/contract"
+	"github.com/AlhasanIQ/consult-human/provider"
+)
+
+// askEscalationDelay returns how long a question waits before it escalates,
+// or 0 when it does not: escalation is not configured, or the question is
+// low priority.
+func askEscalationDelay(cfg config.Config, priority contract.Priority) (time.Duration, error) {
+	after, err := config.EffectiveEscalationAfter(cfg)
+	if err != nil || after == 0 || priority == contract.PriorityLow {
+		return 0, err
+	}
+	if _, err := config.EscalationTarget(cfg); err != nil {
+		return 0, err
+	}
+	return after, nil
+}
+
+// escalationProvider waits on the provider a question was sent through and,
+// when no answer comes within after, sends it again through
+// escalation.provider. From then on it behaves like a broadcast of the two:
+// the first answer wins and the other provider is told and closed.
+type escalationProvider struct {
+	*broadcastProvider
+	cfg    config.Config
+	req    contract.AskRequest
+	after  time.Duration
+	urgent bool
+	status io.Writer
+}
+
+// withEscalation wraps p so its question escalates after the given delay. p
+// is returned as is when escalation would only send through p's own
+// provider again.
+func withEscalation(cfg config.Config, p provider.Provider, req contract.AskRequest, after time.Duration, urgent bool, status, errOut io.Writer) provider.Provider {
+	target := strings.ToLower(strings.TrimSpace(cfg.Escalation.Provider))
+	if after <= 0 || target == "" {
+		return p
+	}
+	if target == p.Name() {
+		if cfg.Escalation.Recipient != "" {
+			fmt.Fprintf(errOut, "warning: escalation.recipient is not supported when escalation.provider is %s, which already has the question; not escalating\n", target)
+		} else {
+			fmt.Fprintf(status, "note: escalation.provider is %s, which already has the question; not escalating\n", target)
+		}
+		return p
+	}
+	return &escalationProvider{
+		broadcastProvider: &broadcastProvider{errOut: errOut, members: []provider.Provider{p}},
+		cfg:               cfg,
+		req:               req,
+		after:             after,
+		urgent:            urgent,
+		status:            status,
+	}
+}
+
+// Receive waits on the first provider, escalates once the delay passes
+// without an answer, and returns the first answer or dismissal from either.
+// Escalation waits for quiet hours that defer questions to end, and never
+// outlives ctx.
+func (e *escalationProvider) Receive(ctx context.Context, requestID string) (contract.Reply, error) {
+	recvCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	results := make(chan broadcastResult, 2)
+	receive := func(p provider.Provider) {
+		go func() {
+			reply, err := p.Receive(recvCtx, requestID)
+			results <- broadcastResult{p: p, reply: reply, err: err}
+		}()
+	}
+	receive(e.members[0])
+	waiting := 1
+
+	timer := time.NewTimer(e.after)
+	defer timer.Stop()
+	escalate := timer.C
+
+	var won *broadcastResult
+	var lastErr error
+	for waiting > 0 {
+		select {
+		case res := <-results:
+			waiting--
+			switch {
+			case won != nil:
+			case res.err == nil || errors.Is(res.err, provider.ErrCancelledByHuman):
+				won = &res
+				cancel()
+			default:
+				if ctx.Err() == nil && waiting > 0 {
+					fmt.Fprintf(e.errOut, "warning: waiting on %s failed: %v\n", res.p.Name(), res.err)
+				}
+				lastErr = res.err
+			}
+		case <-escalate:
+			escalate = nil
+			if hold := e.quietHoursLeft(); hold > 0 {
+				timer.Reset(hold)
+				escalate = timer.C
+				continue
+			}
+			if p := e.escalate(recvCtx); p != nil {
+				e.members = append(e.members, p)
+				receive(p)
+				waiting++
+			}
+		}
+	}
+	if won == nil {
+		if ctx.Err() != nil {
+			return contract.Reply{}, ctx.Err()
+		}
+		return contract.Reply{}, lastErr
+	}
+	return e.settle(*won, requestID)
+}
+
+// quietHoursLeft is how long quiet hours that defer questions still hold
+// this one back, or 0. Urgent and high-priority questions are not held.
+func (e *escalationProvider) quietHoursLeft() time.Duration {
+	if e.urgent || e.req.Priority == contract.PriorityHigh || e.cfg.QuietHours.EffectiveBehavior() != config.QuietHoursDefer {
+		return 0
+	}
+	now := askNowFn()
+	active, ends, err := e.cfg.QuietHours.Active(now)
+	if err != nil || !active {
+		return 0
+	}
+	return ends.Sub(now)
+}
+
+// escalate sends the question through escalation.provider and links it to
+// the first provider's record. A failure is reported and leaves the
+// question waiting where it was.
+func (e *escalationProvider) escalate(ctx context.Context) provider.Provider {
+	first := e.members[0]
+	name := strings.ToLower(strings.TrimSpace(e.cfg.Escalation.Provider))
+	fmt.Fprintf(e.status, "note: no reply via %s after %s; escalating request %s to %s\n", first.Name(), e.after, e.req.RequestID, name)
+
+	target, err := config.EscalationTarget(e.cfg)
+	if err != nil {
+		fmt.Fprintf(e.errOut, "warning: could not escalate to %s: %v\n", name, err)
+		return nil
+	}
+	p, err := askProviderFn(target, name)
+	if err != nil {
+		fmt.Fprintf(e.errOut, "warning: could not escalate to %s: %v\n", name, err)
+		return nil
+	}
+	setProviderStatus(p, e.status)
+	fmt.Fprintf(e.status, "Sending request %s via %s...\n", e.req.RequestID, p.Name())
+	if _, err := p.Send(ctx, e.req); err != nil {
+		_ = p.Close()
+		if ctx.Err() == nil {
+			fmt.Fprintf(e.errOut, "warning: send via %s failed: %v\n", name, err)
+		}
+		return nil
+	}
+	linkEscalation(ctx, e.errOut, first, e.req.RequestID, p.Name())
+	linkEscalation(ctx, e.errOut, p, e.req.RequestID, first.Name())
+	return p
+}
+
+// linkEscalation notes on p's record of the question that it also went out
+// through other, where p keeps such records.
+func linkEscalation(ctx context.Context, errOut io.Writer, p provider.Provider, requestID, other string) {
+	l, ok := p.(provider.Linker)
+	if !ok {
+		return
+	}
+	if err := l.Link(ctx, requestID, other); err != nil {
+		fmt.Fprintf(errOut, "warning: could not link question %s on %s to %s: %v\n", requestID, p.Name(), other, err)
+	}
+}
@@ -0,0 +1,111 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/AlhasanIQ/consult-human/config"
+	"github.com/AlhasanIQ/consult-human/contract"
+)
+
+func saveEscalationConfig(t *testing.T, after, providerName string) {
+	t.Helper()
+	cfg := config.Default()
+	cfg.Escalation = config.Escalation{After: after, Provider: providerName}
+	if err := config.Save(cfg); err != nil {
+		t.Fatalf("save config: %v", err)
+	}
+}
+
+func TestRunAskEscalatesUnansweredQuestion(t *testing.T) {
+	telegram := &fakeBroadcastMember{fakeAskProvider: fakeAskProvider{name: "telegram"}, wait: true}
+	pushover := &fakeBroadcastMember{fakeAskProvider: fakeAskProvider{name: "pushover", reply: contract.Reply{Text: "yes", Raw: "yes"}}}
+	stubBroadcastProviders(t, telegram, pushover)
+	t.Setenv(envAskQuiet, "")
+	saveEscalationConfig(t, "20ms", "pushover")
+
+	var out, errOut bytes.Buffer
+	if err := runAsk([]string{"Deploy?"}, IO{Out: &out, ErrOut: &errOut}); err != nil {
+		t.Fatalf("runAsk returned error: %v", err)
+	}
+	if !strings.Contains(out.String(), `"provider":"pushover"`) || !strings.Contains(out.String(), `"text":"yes"`) {
+		t.Fatalf("expected the escalated answer, got %s", out.String())
+	}
+	if len(pushover.sent) != 1 {
+		t.Fatalf("expected the question to escalate once, got %d sends", len(pushover.sent))
+	}
+	id := pushover.sent[0].RequestID
+	if !strings.Contains(errOut.String(), "no reply via telegram after 20ms; escalating request "+id+" to pushover") {
+		t.Fatalf("expected an escalation notice on stderr, got %q", errOut.String())
+	}
+	if !reflect.DeepEqual(telegram.linked, []string{id + "@pushover"}) || !reflect.DeepEqual(pushover.linked, []string{id + "@telegram"}) {
+		t.Fatalf("expected both records to be linked, got %#v %#v", telegram.linked, pushover.linked)
+	}
+	if !reflect.DeepEqual(telegram.elsewhere, []string{id + "@pushover"}) || !telegram.closed {
+		t.Fatalf("expected telegram to be told and closed, got %#v closed=%v", telegram.elsewhere, telegram.closed)
+	}
+}
+
+func TestRunAskDoesNotEscalateLowPriority(t *testing.T) {
+	telegram := &fakeBroadcastMember{fakeAskProvider: fakeAskProvider{name: "telegram"}, wait: true}
+	pushover := &fakeBroadcastMember{fakeAskProvider: fakeAskProvider{name: "pushover", reply: contract.Reply{Text: "yes"}}}
+	stubBroadcastProviders(t, telegram, pushover)
+	saveEscalationConfig(t, "10ms", "pushover")
+
+	err := runAsk([]string{"--priority", "low", "--timeout", "100ms", "Deploy?"}, IO{Out: &bytes.Buffer{}, ErrOut: &bytes.Buffer{}})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected the question to time out, got %v", err)
+	}
+	if len(pushover.sent) != 0 {
+		t.Fatalf("expected no escalation for a low-priority question, got %d sends", len(pushover.sent))
+	}
+}
+
+func TestRunAskWarnsOnEscalationRecipientForTheSameProvider(t *testing.T) {
+	telegram := &fakeBroadcastMember{fakeAskProvider: fakeAskProvider{name: "telegram", reply: contract.Reply{Text: "yes", Raw: "yes"}}}
+	stubBroadcastProviders(t, telegram)
+	cfg := config.Default()
+	cfg.Escalation = config.Escalation{After: "10ms", Provider: "telegram", Recipient: "-100123"}
+	if err := config.Save(cfg); err != nil {
+		t.Fatalf("save config: %v", err)
+	}
+
+	var errOut bytes.Buffer
+	if err := runAsk([]string{"Deploy?"}, IO{Out: &bytes.Buffer{}, ErrOut: &errOut}); err != nil {
+		t.Fatalf("runAsk returned error: %v", err)
+	}
+	if len(telegram.sent) != 1 {
+		t.Fatalf("expected one send and no escalation, got %d sends", len(telegram.sent))
+	}
+	if !strings.Contains(errOut.String(), "warning: escalation.recipient is not supported when escalation.provider is telegram") {
+		t.Fatalf("expected a warning even in quiet mode, got %q", errOut.String())
+	}
+}
+
+func TestEscalationWaitsOutDeferringQuietHours(t *testing.T) {
+	now := time.Date(2026, 1, 1, 23, 30, 0, 0, time.UTC)
+	orig := askNowFn
+	askNowFn = func() time.Time { return now }
+	t.Cleanup(func() { askNowFn = orig })
+
+	cfg := config.Default()
+	cfg.QuietHours = config.QuietHours{Start: "23:00", End: "07:00", Behavior: config.QuietHoursDefer}
+	e := &escalationProvider{cfg: cfg, req: contract.AskRequest{Priority: contract.PriorityNormal}}
+	if got := e.quietHoursLeft(); got != 7*time.Hour+30*time.Minute {
+		t.Fatalf("expected escalation to wait for quiet hours to end, got %s", got)
+	}
+	e.urgent = true
+	if got := e.quietHoursLeft(); got != 0 {
+		t.Fatalf("expected urgent questions to escalate anyway, got %s", got)
+	}
+	e.urgent = false
+	e.cfg.QuietHours.Behavior = config.QuietHoursSilent
+	if got := e.quietHoursLeft(); got != 0 {
+		t.Fatalf("expected silent quiet hours not to hold escalation, got %s", got)
+	}
+}
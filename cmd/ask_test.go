@@ -1,10 +1,23 @@
 package cmd
 
 import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
 	"reflect"
+	"strings"
 	"testing"
+	"time"
+	"unicode/utf8"
 
+	"github.com/AlhasanIQ/consult-human/config"
 	"github.com/AlhasanIQ/consult-human/contract"
+	"github.com/AlhasanIQ/consult-human/provider"
 )
 
 func TestParseChoices(t *testing.T) {
@@ -20,6 +33,39 @@ func TestParseChoices(t *testing.T) {
 	}
 }
 
+func TestParseChoicesWithDescription(t *testing.T) {
+	choices, err := parseChoices([]string{"A:Ship now::Deploys the current build to production", "Wait:: Holds until review"})
+	if err != nil {
+		t.Fatalf("parseChoices failed: %v", err)
+	}
+	want := []contract.Choice{
+		{ID: "A", Text: "Ship now", Description: "Deploys the current build to production"},
+		{ID: "B", Text: "Wait", Description: "Holds until review"},
+	}
+	if !reflect.DeepEqual(choices, want) {
+		t.Fatalf("unexpected choices: %#v", choices)
+	}
+	if _, err := parseChoices([]string{"::description only"}); err == nil {
+		t.Fatalf("expected error for a choice without a label")
+	}
+}
+
+func TestClassifyChoiceReplyIgnoresDescription(t *testing.T) {
+	req := contract.AskRequest{
+		Type:       contract.QuestionTypeChoice,
+		AllowOther: true,
+		Choices: []contract.Choice{
+			{ID: "A", Text: "Ship now", Description: "Deploys the current build to production"},
+			{ID: "B", Text: "Wait", Description: "Holds until review"},
+		},
+	}
+
+	selected, other := classifyChoiceReply(req, "production build")
+	if len(selected) != 0 || other != "production build" {
+		t.Fatalf("description text must not select a choice, got selected=%#v other=%q", selected, other)
+	}
+}
+
 func TestClassifyChoiceReplyByID(t *testing.T) {
 	req := contract.AskRequest{
 		Type: contract.QuestionTypeChoice,
@@ -56,3 +102,928 @@ func TestClassifyChoiceReplyOther(t *testing.T) {
 		t.Fatalf("expected other text")
 	}
 }
+
+func TestClassifyChoiceReplyRanges(t *testing.T) {
+	req := contract.AskRequest{
+		Type:       contract.QuestionTypeChoice,
+		AllowOther: true,
+		Choices: []contract.Choice{
+			{ID: "A", Text: "Alpha"},
+			{ID: "B", Text: "Bravo"},
+			{ID: "C", Text: "Charlie"},
+			{ID: "D", Text: "Delta"},
+		},
+	}
+
+	tests := []struct {
+		name         string
+		reply        string
+		wantSelected []string
+		wantOther    string
+	}{
+		{name: "numeric range", reply: "1-3", wantSelected: []string{"A", "B", "C"}},
+		{name: "letter range", reply: "B-D", wantSelected: []string{"B", "C", "D"}},
+		{name: "lowercase letter range", reply: "b-c", wantSelected: []string{"B", "C"}},
+		{name: "reversed range", reply: "3-1", wantSelected: []string{"A", "B", "C"}},
+		{name: "range mixed with ids", reply: "A, C-D", wantSelected: []string{"A", "C", "D"}},
+		{name: "range exceeds choices", reply: "2-9", wantOther: "2-9"},
+		{name: "date is free text", reply: "2024-01-01", wantOther: "2024-01-01"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			selected, other := classifyChoiceReply(req, tt.reply)
+			if len(selected) != 0 || len(tt.wantSelected) != 0 {
+				if !reflect.DeepEqual(selected, tt.wantSelected) {
+					t.Fatalf("selected = %#v, want %#v", selected, tt.wantSelected)
+				}
+			}
+			if other != tt.wantOther {
+				t.Fatalf("other = %q, want %q", other, tt.wantOther)
+			}
+		})
+	}
+}
+
+func TestClassifyChoiceReplyFuzzyText(t *testing.T) {
+	req := contract.AskRequest{
+		Type:       contract.QuestionTypeChoice,
+		AllowOther: true,
+		Choices: []contract.Choice{
+			{ID: "A", Text: "Use the shared package"},
+			{ID: "B", Text: "Inline the helper"},
+		},
+	}
+
+	tests := []struct {
+		name         string
+		reply        string
+		wantSelected []string
+		wantOther    string
+	}{
+		{name: "partial phrase", reply: "shared package", wantSelected: []string{"A"}},
+		{name: "single word with punctuation", reply: "Helper!", wantSelected: []string{"B"}},
+		{name: "reply wraps full choice", reply: "ok, inline the helper please", wantOther: "ok, inline the helper please"},
+		{name: "tie on shared word", reply: "the", wantOther: "the"},
+		{name: "incidental word in sentence", reply: "not sure the helper is even needed", wantOther: "not sure the helper is even needed"},
+		{name: "other prefix stays other", reply: "other: shared package", wantOther: "shared package"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			selected, other := classifyChoiceReply(req, tt.reply)
+			if len(selected) != 0 || len(tt.wantSelected) != 0 {
+				if !reflect.DeepEqual(selected, tt.wantSelected) {
+					t.Fatalf("selected = %#v, want %#v", selected, tt.wantSelected)
+				}
+			}
+			if other != tt.wantOther {
+				t.Fatalf("other = %q, want %q", other, tt.wantOther)
+			}
+		})
+	}
+}
+
+func TestClassifyChoiceReplyFuzzyTextIgnoresNegatedAndQualifiedWords(t *testing.T) {
+	yesNo := []contract.Choice{{ID: "A", Text: "Yes"}, {ID: "B", Text: "No"}}
+	waitOrGo := []contract.Choice{{ID: "A", Text: "Wait"}, {ID: "B", Text: "Use the shared package"}}
+
+	tests := []struct {
+		name         string
+		choices      []contract.Choice
+		reply        string
+		wantSelected []string
+	}{
+		{name: "exact single word", choices: waitOrGo, reply: "wait.", wantSelected: []string{"A"}},
+		{name: "negated", choices: waitOrGo, reply: "don't wait"},
+		{name: "negated in a sentence", choices: waitOrGo, reply: "I can't wait, do the shared package thing"},
+		{name: "word inside free text", choices: yesNo, reply: "no idea, what does this do?"},
+		{name: "qualified", choices: yesNo, reply: "yes but only after the tests pass"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := contract.AskRequest{Type: contract.QuestionTypeChoice, AllowOther: true, Choices: tt.choices}
+			selected, other := classifyChoiceReply(req, tt.reply)
+			if len(selected) != 0 || len(tt.wantSelected) != 0 {
+				if !reflect.DeepEqual(selected, tt.wantSelected) {
+					t.Fatalf("selected = %#v, want %#v", selected, tt.wantSelected)
+				}
+			}
+			if tt.wantSelected == nil && other != tt.reply {
+				t.Fatalf("other = %q, want the reply kept as free text", other)
+			}
+		})
+	}
+}
+
+func TestClassifyChoiceReplyEmojiDigits(t *testing.T) {
+	choices := make([]contract.Choice, 0, 10)
+	for i := 0; i < 10; i++ {
+		choices = append(choices, contract.Choice{ID: autoChoiceID(i), Text: fmt.Sprintf("Option %d", i+1)})
+	}
+	req := contract.AskRequest{
+		Type:       contract.QuestionTypeChoice,
+		AllowOther: true,
+		Choices:    choices,
+	}
+
+	tests := []struct {
+		name         string
+		reply        string
+		wantSelected []string
+		wantOther    string
+	}{
+		{name: "keycap", reply: "1️⃣", wantSelected: []string{"A"}},
+		{name: "keycap without variation selector", reply: "3\u20E3", wantSelected: []string{"C"}},
+		{name: "keycap ten", reply: "🔟", wantSelected: []string{"J"}},
+		{name: "adjacent keycaps", reply: "2️⃣4️⃣", wantSelected: []string{"B", "D"}},
+		{name: "circled number", reply: "②", wantSelected: []string{"B"}},
+		{name: "negative circled number", reply: "❸", wantSelected: []string{"C"}},
+		{name: "check mark before index", reply: "✅ 2", wantSelected: []string{"B"}},
+		{name: "thumbs up with skin tone before id", reply: "👍🏽 c", wantSelected: []string{"C"}},
+		{name: "zero width joiner is ignored", reply: "5\u200D", wantSelected: []string{"E"}},
+		{name: "emoji-only reply stays other", reply: "👍", wantOther: "👍"},
+		{name: "other keeps original emoji", reply: "🚀 ship it now", wantOther: "🚀 ship it now"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			selected, other := classifyChoiceReply(req, tt.reply)
+			if len(selected) != 0 || len(tt.wantSelected) != 0 {
+				if !reflect.DeepEqual(selected, tt.wantSelected) {
+					t.Fatalf("selected = %#v, want %#v", selected, tt.wantSelected)
+				}
+			}
+			if other != tt.wantOther {
+				t.Fatalf("other = %q, want %q", other, tt.wantOther)
+			}
+			if !utf8.ValidString(other) {
+				t.Fatalf("other is not valid UTF-8: %q", other)
+			}
+		})
+	}
+}
+
+type fakeAskProvider struct {
+	name    string
+	reply   contract.Reply
+	sent    []contract.AskRequest
+	sendErr error
+	recvErr error
+
+	canceled []string
+	timedOut []string
+	acked    []string
+	ackErr   error
+
+	correction *contract.Reply
+	deferred   []time.Time
+
+	note   string
+	status io.Writer
+}
+
+func (f *fakeAskProvider) SetStatus(w io.Writer) { f.status = w }
+
+func (f *fakeAskProvider) Defer(_ context.Context, _ contract.AskRequest, until time.Time) error {
+	f.deferred = append(f.deferred, until)
+	return nil
+}
+
+func (f *fakeAskProvider) AwaitCorrection(_ context.Context, reply contract.Reply) (contract.Reply, bool, error) {
+	if f.correction == nil {
+		return reply, false, nil
+	}
+	return *f.correction, true, nil
+}
+
+func (f *fakeAskProvider) Acknowledge(_ context.Context, _ contract.Reply, text string) error {
+	f.acked = append(f.acked, text)
+	return f.ackErr
+}
+
+func (f *fakeAskProvider) Cancel(_ context.Context, requestID string) error {
+	f.canceled = append(f.canceled, requestID)
+	return nil
+}
+
+func (f *fakeAskProvider) NotifyTimeout(_ context.Context, requestID string, _ time.Duration) error {
+	f.timedOut = append(f.timedOut, requestID)
+	return nil
+}
+
+func (f *fakeAskProvider) Name() string {
+	if f.name != "" {
+		return f.name
+	}
+	return "fake"
+}
+
+func (f *fakeAskProvider) Send(_ context.Context, req contract.AskRequest) (string, error) {
+	if f.sendErr != nil {
+		return "", f.sendErr
+	}
+	f.sent = append(f.sent, req)
+	return req.RequestID, nil
+}
+
+func (f *fakeAskProvider) Receive(_ context.Context, requestID string) (contract.Reply, error) {
+	if f.note != "" && f.status != nil {
+		fmt.Fprintln(f.status, f.note)
+	}
+	if f.recvErr != nil {
+		return contract.Reply{}, f.recvErr
+	}
+	reply := f.reply
+	reply.RequestID = requestID
+	return reply, nil
+}
+
+func (f *fakeAskProvider) Close() error { return nil }
+
+func stubAskProvider(t *testing.T, fake *fakeAskProvider) {
+	t.Helper()
+	t.Setenv(config.EnvConfigPath, filepath.Join(t.TempDir(), "config.yaml"))
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+	orig := askProviderFn
+	askProviderFn = func(config.Config, string) (provider.Provider, error) { return fake, nil }
+	t.Cleanup(func() { askProviderFn = orig })
+}
+
+func TestRunAskPrintsProgressByDefault(t *testing.T) {
+	t.Setenv(envAskQuiet, "")
+	stubAskProvider(t, &fakeAskProvider{reply: contract.Reply{Text: "yes", Raw: "yes"}})
+
+	var out, errOut bytes.Buffer
+	if err := runAsk([]string{"Ship it?"}, IO{In: strings.NewReader(""), Out: &out, ErrOut: &errOut}); err != nil {
+		t.Fatalf("runAsk returned error: %v", err)
+	}
+	if !strings.Contains(errOut.String(), "Waiting for human reply") {
+		t.Fatalf("expected progress output on stderr, got %q", errOut.String())
+	}
+}
+
+func TestRunAskQuietSuppressesProgress(t *testing.T) {
+	cases := []struct {
+		name string
+		args []string
+		env  string
+	}{
+		{name: "flag", args: []string{"--quiet", "Ship it?"}},
+		{name: "env", args: []string{"Ship it?"}, env: "1"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Setenv(envAskQuiet, tc.env)
+			stubAskProvider(t, &fakeAskProvider{reply: contract.Reply{Text: "yes", Raw: "yes"}})
+
+			var out, errOut bytes.Buffer
+			if err := runAsk(tc.args, IO{In: strings.NewReader(""), Out: &out, ErrOut: &errOut}); err != nil {
+				t.Fatalf("runAsk returned error: %v", err)
+			}
+			if errOut.Len() != 0 {
+				t.Fatalf("expected no stderr output in quiet mode, got %q", errOut.String())
+			}
+			var result contract.AskResult
+			if err := json.Unmarshal(out.Bytes(), &result); err != nil {
+				t.Fatalf("decode stdout: %v (%q)", err, out.String())
+			}
+			if result.Text != "yes" {
+				t.Fatalf("unexpected result text: %q", result.Text)
+			}
+		})
+	}
+}
+
+func TestRunAskQuietSilencesNotes(t *testing.T) {
+	t.Run("console fallback", func(t *testing.T) {
+		t.Setenv(envAskQuiet, "")
+		stubAskProvider(t, &fakeAskProvider{reply: contract.Reply{Text: "yes", Raw: "yes"}})
+		orig := askInputIsTerminalFn
+		askInputIsTerminalFn = func(io.Reader) bool { return true }
+		t.Cleanup(func() { askInputIsTerminalFn = orig })
+
+		var errOut bytes.Buffer
+		if err := runAsk([]string{"--quiet", "Ship it?"}, IO{In: strings.NewReader(""), Out: &bytes.Buffer{}, ErrOut: &errOut}); err != nil {
+			t.Fatalf("runAsk returned error: %v", err)
+		}
+		if errOut.Len() != 0 {
+			t.Fatalf("expected no stderr output in quiet mode, got %q", errOut.String())
+		}
+	})
+
+	t.Run("provider notes", func(t *testing.T) {
+		t.Setenv(envAskQuiet, "")
+		stubAskProvider(t, &fakeAskProvider{reply: contract.Reply{Text: "yes", Raw: "yes"}, note: "note: identical question is already pending"})
+
+		var errOut bytes.Buffer
+		if err := runAsk([]string{"--quiet", "Ship it?"}, IO{In: strings.NewReader(""), Out: &bytes.Buffer{}, ErrOut: &errOut}); err != nil {
+			t.Fatalf("runAsk returned error: %v", err)
+		}
+		if errOut.Len() != 0 {
+			t.Fatalf("expected no stderr output in quiet mode, got %q", errOut.String())
+		}
+	})
+
+	t.Run("deferred quiet hours", func(t *testing.T) {
+		t.Setenv(envAskQuiet, "1")
+		fake := &fakeAskProvider{reply: contract.Reply{Text: "yes", Raw: "yes"}}
+		stubAskProvider(t, fake)
+		cfg := config.Default()
+		cfg.Telegram.BotToken = "test-token"
+		cfg.QuietHours = config.QuietHours{Start: "23:00", End: "07:00", Timezone: "UTC", Behavior: config.QuietHoursDefer}
+		if err := config.Save(cfg); err != nil {
+			t.Fatalf("save config: %v", err)
+		}
+		stubAskClock(t, time.Date(2026, 3, 10, 7, 0, 0, 0, time.UTC).Add(-20*time.Millisecond))
+
+		var errOut bytes.Buffer
+		if err := runAsk([]string{"Ship it?"}, IO{In: strings.NewReader(""), Out: &bytes.Buffer{}, ErrOut: &errOut}); err != nil {
+			t.Fatalf("runAsk returned error: %v", err)
+		}
+		if len(fake.deferred) != 1 {
+			t.Fatalf("expected the question to be deferred, got %v", fake.deferred)
+		}
+		if errOut.Len() != 0 {
+			t.Fatalf("expected no stderr output in quiet mode, got %q", errOut.String())
+		}
+	})
+}
+
+func TestRunAskChatRoutesToAlias(t *testing.T) {
+	t.Setenv(envAskQuiet, "1")
+	stubAskProvider(t, &fakeAskProvider{reply: contract.Reply{Text: "yes", Raw: "yes"}})
+	cfg := config.Default()
+	cfg.Telegram.ChatID = 1
+	cfg.Telegram.Chats = map[string]int64{"work": -100123}
+	if err := config.Save(cfg); err != nil {
+		t.Fatalf("config.Save returned error: %v", err)
+	}
+	var gotChatID int64
+	orig := askProviderFn
+	askProviderFn = func(cfg config.Config, name string) (provider.Provider, error) {
+		gotChatID = cfg.Telegram.ChatID
+		return orig(cfg, name)
+	}
+
+	var out, errOut bytes.Buffer
+	if err := runAsk([]string{"--chat", "work", "Ship it?"}, IO{In: strings.NewReader(""), Out: &out, ErrOut: &errOut}); err != nil {
+		t.Fatalf("runAsk returned error: %v", err)
+	}
+	if gotChatID != -100123 {
+		t.Fatalf("expected question routed to chat -100123, got %d", gotChatID)
+	}
+
+	err := runAsk([]string{"--chat", "home", "Ship it?"}, IO{In: strings.NewReader(""), Out: &out, ErrOut: &errOut})
+	if err == nil || !strings.Contains(err.Error(), "--chat") {
+		t.Fatalf("expected unknown chat error, got %v", err)
+	}
+}
+
+func TestParsePriority(t *testing.T) {
+	cases := []struct {
+		input   string
+		want    contract.Priority
+		wantErr bool
+	}{
+		{input: "", want: contract.PriorityNormal},
+		{input: "LOW", want: contract.PriorityLow},
+		{input: "high", want: contract.PriorityHigh},
+		{input: "urgent", wantErr: true},
+	}
+
+	for _, tc := range cases {
+		got, err := parsePriority(tc.input)
+		if tc.wantErr {
+			if err == nil {
+				t.Fatalf("input %q: expected error", tc.input)
+			}
+			continue
+		}
+		if err != nil || got != tc.want {
+			t.Fatalf("input %q: want %q got %q (err %v)", tc.input, tc.want, got, err)
+		}
+	}
+}
+
+func TestParseTags(t *testing.T) {
+	got, err := parseTags([]string{"repo=consult-human", " agent = claude ", "empty="})
+	if err != nil {
+		t.Fatalf("parseTags failed: %v", err)
+	}
+	want := map[string]string{"repo": "consult-human", "agent": "claude", "empty": ""}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("unexpected tags: %#v", got)
+	}
+
+	tooMany := make([]string, askMaxTags+1)
+	for i := range tooMany {
+		tooMany[i] = fmt.Sprintf("k%d=v", i)
+	}
+	invalid := [][]string{
+		{"novalue"},
+		{"=value"},
+		{"repo=a", "repo=b"},
+		{"repo=" + strings.Repeat("x", askMaxTagBytes+1)},
+		tooMany,
+	}
+	for _, raw := range invalid {
+		if _, err := parseTags(raw); err == nil {
+			t.Fatalf("expected error for %q", raw)
+		}
+	}
+}
+
+func TestRunAskPassesTagsToProvider(t *testing.T) {
+	t.Setenv(envAskQuiet, "1")
+	fake := &fakeAskProvider{reply: contract.Reply{Text: "yes", Raw: "yes"}}
+	stubAskProvider(t, fake)
+
+	var out bytes.Buffer
+	if err := runAsk([]string{"--tag", "repo=api", "--tag", "agent=codex", "Ship it?"}, IO{In: strings.NewReader(""), Out: &out, ErrOut: &bytes.Buffer{}}); err != nil {
+		t.Fatalf("runAsk returned error: %v", err)
+	}
+	if len(fake.sent) != 1 || !reflect.DeepEqual(fake.sent[0].Tags, map[string]string{"repo": "api", "agent": "codex"}) {
+		t.Fatalf("unexpected sent requests: %#v", fake.sent)
+	}
+}
+
+func TestRunAskPassesSessionToProvider(t *testing.T) {
+	t.Setenv(envAskQuiet, "1")
+	fake := &fakeAskProvider{reply: contract.Reply{Text: "yes", Raw: "yes"}}
+	stubAskProvider(t, fake)
+
+	if err := runAsk([]string{"--session", " deploy ", "Ship it?"}, IO{In: strings.NewReader(""), Out: &bytes.Buffer{}, ErrOut: &bytes.Buffer{}}); err != nil {
+		t.Fatalf("runAsk returned error: %v", err)
+	}
+	if len(fake.sent) != 1 || fake.sent[0].Session != "deploy" {
+		t.Fatalf("unexpected sent requests: %#v", fake.sent)
+	}
+	if err := runAsk([]string{"--session", "deploy", "--batch", "questions.yaml"}, IO{In: strings.NewReader(""), Out: &bytes.Buffer{}, ErrOut: &bytes.Buffer{}}); err == nil {
+		t.Fatalf("expected --session to be rejected with --batch")
+	}
+}
+
+func TestRunAskPassesPollToProvider(t *testing.T) {
+	t.Setenv(envAskQuiet, "1")
+	fake := &fakeAskProvider{reply: contract.Reply{Text: "B", Raw: "B"}}
+	stubAskProvider(t, fake)
+
+	args := []string{"--poll", "--poll-wait", "10m", "--choice", "A:eu", "--choice", "B:us", "Which region?"}
+	if err := runAsk(args, IO{In: strings.NewReader(""), Out: &bytes.Buffer{}, ErrOut: &bytes.Buffer{}}); err != nil {
+		t.Fatalf("runAsk returned error: %v", err)
+	}
+	if len(fake.sent) != 1 || !fake.sent[0].Poll || fake.sent[0].PollWait != 10*time.Minute {
+		t.Fatalf("unexpected sent requests: %#v", fake.sent)
+	}
+
+	invalid := [][]string{
+		{"--poll", "Ship it?"},
+		{"--poll", "--choice", "A:yes", "--choice", "B:no", "--allow-other", "Ship it?"},
+		{"--poll-wait", "5m", "--choice", "A:yes", "--choice", "B:no", "Ship it?"},
+		{"--poll", "--poll-wait", "soon", "--choice", "A:yes", "--choice", "B:no", "Ship it?"},
+	}
+	for _, args := range invalid {
+		if err := runAsk(args, IO{In: strings.NewReader(""), Out: &bytes.Buffer{}, ErrOut: &bytes.Buffer{}}); err == nil {
+			t.Fatalf("expected %q to be rejected", args)
+		}
+	}
+}
+
+func TestRunAskWatchEditsReturnsCorrectedReply(t *testing.T) {
+	t.Setenv(envAskQuiet, "1")
+	stubAskProvider(t, &fakeAskProvider{
+		reply:      contract.Reply{Text: "shp it", Raw: "shp it"},
+		correction: &contract.Reply{Text: "ship it", Raw: "ship it", Edited: true},
+	})
+
+	for _, tc := range []struct {
+		args       []string
+		wantText   string
+		wantEdited bool
+	}{
+		{args: []string{"Ship it?"}, wantText: "shp it"},
+		{args: []string{"--watch-edits", "Ship it?"}, wantText: "ship it", wantEdited: true},
+	} {
+		var out bytes.Buffer
+		if err := runAsk(tc.args, IO{In: strings.NewReader(""), Out: &out, ErrOut: &bytes.Buffer{}}); err != nil {
+			t.Fatalf("runAsk returned error: %v", err)
+		}
+		var result contract.AskResult
+		if err := json.Unmarshal(out.Bytes(), &result); err != nil {
+			t.Fatalf("decode stdout: %v (%q)", err, out.String())
+		}
+		if result.Text != tc.wantText || result.Edited != tc.wantEdited {
+			t.Fatalf("%v: unexpected result text %q edited %v", tc.args, result.Text, result.Edited)
+		}
+	}
+}
+
+func TestRunAskResultEchoesRequest(t *testing.T) {
+	t.Setenv(envAskQuiet, "1")
+	fake := &fakeAskProvider{reply: contract.Reply{Text: "B", Raw: "B"}}
+	stubAskProvider(t, fake)
+
+	var out bytes.Buffer
+	args := []string{"--choice", "A:Shared", "--choice", "B:Inline", "Which approach?"}
+	if err := runAsk(args, IO{In: strings.NewReader(""), Out: &out, ErrOut: &bytes.Buffer{}}); err != nil {
+		t.Fatalf("runAsk returned error: %v", err)
+	}
+
+	var result contract.AskResult
+	if err := json.Unmarshal(out.Bytes(), &result); err != nil {
+		t.Fatalf("decode stdout: %v (%q)", err, out.String())
+	}
+	if result.Question != "Which approach?" {
+		t.Fatalf("unexpected question: %q", result.Question)
+	}
+	wantChoices := []contract.Choice{{ID: "A", Text: "Shared"}, {ID: "B", Text: "Inline"}}
+	if !reflect.DeepEqual(result.Choices, wantChoices) {
+		t.Fatalf("unexpected choices: %#v", result.Choices)
+	}
+	if !result.SentAt.Equal(fake.sent[0].SentAt) {
+		t.Fatalf("sent_at = %v, want %v", result.SentAt, fake.sent[0].SentAt)
+	}
+}
+
+func TestAskConfirmationText(t *testing.T) {
+	choices := []contract.Choice{{ID: "A", Text: "Ship now"}, {ID: "B", Text: "Wait"}}
+	choice := func(selected []string, other string) contract.AskResult {
+		return contract.AskResult{QuestionType: contract.QuestionTypeChoice, Choices: choices, SelectedIDs: selected, OtherText: other}
+	}
+	open := contract.AskResult{QuestionType: contract.QuestionTypeOpen, Text: "sure"}
+
+	cases := []struct {
+		name   string
+		mode   string
+		result contract.AskResult
+		want   string
+	}{
+		{"single choice", "", choice([]string{"B"}, ""), "Got it: B) Wait"},
+		{"multiple choices", "choice", choice([]string{"A", "B"}, ""), "Got it: A) Ship now, B) Wait"},
+		{"other text", "", choice(nil, "tomorrow"), `Got it: other: "tomorrow"`},
+		{"unmatched choice", "", choice(nil, ""), ""},
+		{"off", "off", choice([]string{"B"}, ""), ""},
+		{"open question by default", "", open, ""},
+		{"open question when enabled", "all", open, "Got it."},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := askConfirmationText(tc.mode, tc.result); got != tc.want {
+				t.Fatalf("askConfirmationText = %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestRunAskConfirmsChoiceReplyBestEffort(t *testing.T) {
+	t.Setenv(envAskQuiet, "1")
+	fake := &fakeAskProvider{reply: contract.Reply{Text: "2", Raw: "2"}, ackErr: errors.New("network down")}
+	stubAskProvider(t, fake)
+
+	var out, errOut bytes.Buffer
+	args := []string{"--choice", "A:Ship now", "--choice", "B:Wait", "Release?"}
+	if err := runAsk(args, IO{In: strings.NewReader(""), Out: &out, ErrOut: &errOut}); err != nil {
+		t.Fatalf("runAsk returned error: %v", err)
+	}
+	if len(fake.acked) != 1 || fake.acked[0] != "Got it: B) Wait" {
+		t.Fatalf("unexpected confirmations: %#v", fake.acked)
+	}
+	if !strings.Contains(errOut.String(), "warning: could not confirm reply") {
+		t.Fatalf("expected a warning for the failed confirmation, got %q", errOut.String())
+	}
+	if !strings.Contains(out.String(), `"selected_ids":["B"]`) {
+		t.Fatalf("expected the result despite the failed confirmation, got %q", out.String())
+	}
+}
+
+func TestAskResultOmitsEmptyEchoFields(t *testing.T) {
+	b, err := json.Marshal(contract.AskResult{RequestID: "req-1", Provider: "telegram"})
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	for _, key := range []string{`"question"`, `"choices"`, `"sent_at"`} {
+		if strings.Contains(string(b), key) {
+			t.Fatalf("expected %s to be omitted, got %s", key, b)
+		}
+	}
+}
+
+func TestSendWithFallbackUsesNextProviderOnSendFailure(t *testing.T) {
+	primary := &fakeAskProvider{name: "telegram", sendErr: errors.New("bot token revoked")}
+	backup := &fakeAskProvider{name: "backup"}
+	orig := askProviderFn
+	askProviderFn = func(_ config.Config, name string) (provider.Provider, error) {
+		switch name {
+		case "telegram":
+			return primary, nil
+		case "whatsapp":
+			return nil, fmt.Errorf("whatsapp %w", provider.ErrProviderDisabled)
+		default:
+			return backup, nil
+		}
+	}
+	t.Cleanup(func() { askProviderFn = orig })
+
+	var errOut bytes.Buffer
+	req := contract.AskRequest{RequestID: "req-1", Question: "Ship it?"}
+	p, err := sendWithFallback(context.Background(), config.Default(), []string{"telegram", "whatsapp", "backup"}, req, io.Discard, &errOut)
+	if err != nil {
+		t.Fatalf("sendWithFallback returned error: %v", err)
+	}
+	if p.Name() != "backup" || len(backup.sent) != 1 {
+		t.Fatalf("expected delivery via backup, got %q (sent %d)", p.Name(), len(backup.sent))
+	}
+	got := errOut.String()
+	if !strings.Contains(got, "send via telegram failed: bot token revoked") {
+		t.Fatalf("expected failed provider on stderr, got %q", got)
+	}
+	if !strings.Contains(got, "skipping whatsapp") {
+		t.Fatalf("expected disabled provider note on stderr, got %q", got)
+	}
+}
+
+func TestSendWithFallbackStopsWhenContextExpires(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	primary := &fakeAskProvider{name: "telegram", sendErr: context.Canceled}
+	backup := &fakeAskProvider{name: "backup"}
+	orig := askProviderFn
+	askProviderFn = func(_ config.Config, name string) (provider.Provider, error) {
+		if name == "telegram" {
+			return primary, nil
+		}
+		return backup, nil
+	}
+	t.Cleanup(func() { askProviderFn = orig })
+
+	req := contract.AskRequest{RequestID: "req-1", Question: "Ship it?"}
+	if _, err := sendWithFallback(ctx, config.Default(), []string{"telegram", "backup"}, req, io.Discard, io.Discard); err == nil {
+		t.Fatalf("expected context error")
+	}
+	if len(backup.sent) != 0 {
+		t.Fatalf("fallback must not run after the context ends")
+	}
+}
+
+func TestAskProviderChain(t *testing.T) {
+	cfg := config.Default()
+	cfg.FallbackProviders = []string{"whatsapp", "Telegram", ""}
+	if got := askProviderChain(cfg, ""); !reflect.DeepEqual(got, []string{"telegram", "whatsapp"}) {
+		t.Fatalf("unexpected chain: %#v", got)
+	}
+	if got := askProviderChain(cfg, "whatsapp"); !reflect.DeepEqual(got, []string{"whatsapp"}) {
+		t.Fatalf("override should disable fallbacks, got %#v", got)
+	}
+}
+
+func TestRunAskWithdrawsQuestionOnInterrupt(t *testing.T) {
+	t.Setenv(envAskQuiet, "1")
+	fake := &fakeAskProvider{recvErr: context.Canceled}
+	stubAskProvider(t, fake)
+
+	orig := askSignalContextFn
+	askSignalContextFn = func(parent context.Context, _ ...os.Signal) (context.Context, context.CancelFunc) {
+		ctx, cancel := context.WithCancel(parent)
+		cancel()
+		return ctx, func() {}
+	}
+	t.Cleanup(func() { askSignalContextFn = orig })
+
+	err := runAsk([]string{"Ship it?"}, IO{In: strings.NewReader(""), Out: &bytes.Buffer{}, ErrOut: &bytes.Buffer{}})
+	if err == nil {
+		t.Fatalf("expected interrupt error")
+	}
+	if got := ExitCode(err); got != ExitCodeInterrupted {
+		t.Fatalf("exit code = %d, want %d", got, ExitCodeInterrupted)
+	}
+	if len(fake.sent) != 1 || !reflect.DeepEqual(fake.canceled, []string{fake.sent[0].RequestID}) {
+		t.Fatalf("expected the sent question to be withdrawn, got %#v", fake.canceled)
+	}
+}
+
+func TestRunAskReportsQuestionCancelledByHuman(t *testing.T) {
+	t.Setenv(envAskQuiet, "1")
+	fake := &fakeAskProvider{recvErr: provider.ErrCancelledByHuman}
+	stubAskProvider(t, fake)
+
+	var out bytes.Buffer
+	err := runAsk([]string{"Ship it?"}, IO{In: strings.NewReader(""), Out: &out, ErrOut: &bytes.Buffer{}})
+	if got := ExitCode(err); got != ExitCodeCancelled {
+		t.Fatalf("exit code = %d, want %d (err %v)", got, ExitCodeCancelled, err)
+	}
+	var result contract.AskResult
+	if err := json.Unmarshal(out.Bytes(), &result); err != nil {
+		t.Fatalf("decode result: %v (%q)", err, out.String())
+	}
+	if !result.Cancelled || result.Question != "Ship it?" || result.Text != "" {
+		t.Fatalf("unexpected result: %#v", result)
+	}
+	if len(fake.canceled) != 0 {
+		t.Fatalf("a question the human cancelled must not be withdrawn again")
+	}
+}
+
+func TestRunAskTimeoutKeepsDefaultExitCode(t *testing.T) {
+	t.Setenv(envAskQuiet, "1")
+	fake := &fakeAskProvider{recvErr: context.DeadlineExceeded}
+	stubAskProvider(t, fake)
+
+	err := runAsk([]string{"Ship it?"}, IO{In: strings.NewReader(""), Out: &bytes.Buffer{}, ErrOut: &bytes.Buffer{}})
+	if got := ExitCode(err); got != 1 {
+		t.Fatalf("exit code = %d, want 1", got)
+	}
+	if len(fake.canceled) != 0 {
+		t.Fatalf("timeout must not withdraw the question")
+	}
+	if len(fake.sent) != 1 || !reflect.DeepEqual(fake.timedOut, []string{fake.sent[0].RequestID}) {
+		t.Fatalf("expected the timed out question to be marked, got %#v", fake.timedOut)
+	}
+}
+
+func TestRunAskDryRunPrintsPromptWithoutProvider(t *testing.T) {
+	t.Setenv(config.EnvConfigPath, filepath.Join(t.TempDir(), "config.yaml"))
+	orig := askProviderFn
+	askProviderFn = func(config.Config, string) (provider.Provider, error) {
+		t.Fatalf("dry run must not construct a provider")
+		return nil, nil
+	}
+	t.Cleanup(func() { askProviderFn = orig })
+
+	var out bytes.Buffer
+	args := []string{"--dry-run", "--timeout", "30s", "--choice", "A:Shared", "--choice", "B:Inline", "Which approach?"}
+	if err := runAsk(args, IO{In: strings.NewReader(""), Out: &out, ErrOut: &bytes.Buffer{}}); err != nil {
+		t.Fatalf("runAsk returned error: %v", err)
+	}
+
+	got := out.String()
+	for _, want := range []string{"provider: telegram\n", "timeout: 30s\n", "request_id: ", "A) Shared\nB) Inline\n"} {
+		if !strings.Contains(got, want) {
+			t.Fatalf("expected %q in dry-run output, got %q", want, got)
+		}
+	}
+}
+
+func TestRunAskDryRunShowsFormattedPrompt(t *testing.T) {
+	t.Setenv(config.EnvConfigPath, filepath.Join(t.TempDir(), "config.yaml"))
+
+	var out bytes.Buffer
+	args := []string{"--dry-run", "--format", "markdown", "Rename user_id?"}
+	if err := runAsk(args, IO{In: strings.NewReader(""), Out: &out, ErrOut: &bytes.Buffer{}}); err != nil {
+		t.Fatalf("runAsk returned error: %v", err)
+	}
+	if !strings.HasSuffix(out.String(), "---\nRename user\\_id?\n") {
+		t.Fatalf("expected escaped prompt, got %q", out.String())
+	}
+
+	out.Reset()
+	args = []string{"--dry-run", "--format", "markdown", "--raw", "Rename *user_id*?"}
+	if err := runAsk(args, IO{In: strings.NewReader(""), Out: &out, ErrOut: &bytes.Buffer{}}); err != nil {
+		t.Fatalf("runAsk returned error: %v", err)
+	}
+	if !strings.HasSuffix(out.String(), "---\nRename *user_id*?\n") {
+		t.Fatalf("expected raw prompt, got %q", out.String())
+	}
+}
+
+func TestRunAskDryRunStillValidates(t *testing.T) {
+	t.Setenv(config.EnvConfigPath, filepath.Join(t.TempDir(), "config.yaml"))
+	cases := [][]string{
+		{"--dry-run", "--timeout", "soon", "Ship it?"},
+		{"--dry-run", "--allow-other", "Ship it?"},
+		{"--dry-run", "--choice", "A:One", "--choice", "A:Two", "Ship it?"},
+		{"--dry-run", "--format", "rtf", "Ship it?"},
+		{"--dry-run", "--raw", "Ship *it*?"},
+	}
+	for _, args := range cases {
+		if err := runAsk(args, IO{In: strings.NewReader(""), Out: &bytes.Buffer{}, ErrOut: &bytes.Buffer{}}); err == nil {
+			t.Fatalf("expected validation error for %q", args)
+		}
+	}
+}
+
+func TestResolveSenderLabelFillsHostAndRepo(t *testing.T) {
+	repo := filepath.Join(t.TempDir(), "consult-human")
+	if err := os.MkdirAll(filepath.Join(repo, ".git"), 0o755); err != nil {
+		t.Fatalf("mkdir .git: %v", err)
+	}
+	sub := filepath.Join(repo, "cmd")
+	if err := os.MkdirAll(sub, 0o755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+
+	origCurrentDirFn, origHostnameFn := setupCurrentDirFn, askHostnameFn
+	setupCurrentDirFn = func() (string, error) { return sub, nil }
+	askHostnameFn = func() (string, error) { return "macbook.local", nil }
+	defer func() { setupCurrentDirFn, askHostnameFn = origCurrentDirFn, origHostnameFn }()
+
+	if got := resolveSenderLabel(""); got != "" {
+		t.Fatalf("expected no label when unset, got %q", got)
+	}
+	if got := resolveSenderLabel("{host} · {repo}"); got != "macbook · consult-human" {
+		t.Fatalf("unexpected label %q", got)
+	}
+
+	setupCurrentDirFn = func() (string, error) { return t.TempDir(), nil }
+	if got := resolveSenderLabel("{host} · {repo}"); got != "macbook" {
+		t.Fatalf("expected the repo to drop outside a git repo, got %q", got)
+	}
+}
+
+func TestResolveAskSilent(t *testing.T) {
+	quiet := config.Default()
+	quiet.Telegram.Silent = true
+	off, on := false, true
+
+	cases := []struct {
+		cfg      config.Config
+		priority contract.Priority
+		override *bool
+		want     bool
+	}{
+		{cfg: config.Default(), priority: contract.PriorityNormal, want: false},
+		{cfg: config.Default(), priority: contract.PriorityLow, want: true},
+		{cfg: config.Default(), priority: contract.PriorityLow, override: &off, want: false},
+		{cfg: config.Default(), priority: contract.PriorityHigh, override: &on, want: true},
+		{cfg: quiet, priority: contract.PriorityNormal, want: true},
+		{cfg: quiet, priority: contract.PriorityNormal, override: &off, want: false},
+	}
+	for _, tc := range cases {
+		if got := resolveAskSilent(tc.cfg, tc.priority, tc.override); got != tc.want {
+			t.Fatalf("resolveAskSilent(silent=%t, %s, %v) = %t, want %t", tc.cfg.Telegram.Silent, tc.priority, tc.override, got, tc.want)
+		}
+	}
+}
+
+func stubAskClock(t *testing.T, now time.Time) {
+	t.Helper()
+	orig := askNowFn
+	askNowFn = func() time.Time { return now }
+	t.Cleanup(func() { askNowFn = orig })
+}
+
+func TestRunAskSendsSilentlyDuringQuietHours(t *testing.T) {
+	fake := &fakeAskProvider{reply: contract.Reply{Text: "yes", Raw: "yes"}}
+	stubAskProvider(t, fake)
+	cfg := config.Default()
+	cfg.QuietHours = config.QuietHours{Start: "23:00", End: "07:00", Timezone: "Asia/Tokyo"}
+	if err := config.Save(cfg); err != nil {
+		t.Fatalf("save config: %v", err)
+	}
+	// 15:30 UTC is 00:30 in Tokyo.
+	stubAskClock(t, time.Date(2026, 3, 10, 15, 30, 0, 0, time.UTC))
+
+	io := IO{In: strings.NewReader(""), Out: &bytes.Buffer{}, ErrOut: &bytes.Buffer{}}
+	if err := runAsk([]string{"--quiet", "Ship it?"}, io); err != nil {
+		t.Fatalf("runAsk returned error: %v", err)
+	}
+	if err := runAsk([]string{"--quiet", "--urgent", "Ship it now?"}, io); err != nil {
+		t.Fatalf("runAsk returned error: %v", err)
+	}
+	if len(fake.sent) != 2 || !fake.sent[0].Silent || fake.sent[1].Silent {
+		t.Fatalf("expected only the non-urgent question to be silent, got %#v", fake.sent)
+	}
+}
+
+func TestApplyQuietHoursDefersUntilTheWindowEnds(t *testing.T) {
+	fake := &fakeAskProvider{}
+	stubAskProvider(t, fake)
+	cfg := config.Default()
+	cfg.QuietHours = config.QuietHours{Start: "23:00", End: "07:00", Timezone: "Europe/Berlin", Behavior: config.QuietHoursDefer}
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Skipf("time zone data unavailable: %v", err)
+	}
+	ends := time.Date(2026, 3, 10, 7, 0, 0, 0, berlin)
+	stubAskClock(t, ends.Add(-20*time.Millisecond))
+
+	req := contract.AskRequest{RequestID: "req-1", Question: "Ship it?", Priority: contract.PriorityNormal}
+	var errOut bytes.Buffer
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	got, err := applyQuietHours(ctx, cfg, "telegram", req, false, &errOut, &errOut)
+	if err != nil {
+		t.Fatalf("applyQuietHours returned error: %v", err)
+	}
+	if got.Silent || len(fake.deferred) != 1 || !fake.deferred[0].Equal(ends) {
+		t.Fatalf("expected the question to be deferred until %s, got %#v (deferred %v)", ends, got, fake.deferred)
+	}
+	if !strings.Contains(errOut.String(), "holding request req-1 until then") {
+		t.Fatalf("expected a deferral note, got %q", errOut.String())
+	}
+
+	// A window that outlasts the timeout sends silently instead of waiting.
+	stubAskClock(t, ends.Add(-time.Hour))
+	errOut.Reset()
+	got, err = applyQuietHours(ctx, cfg, "telegram", req, false, &errOut, &errOut)
+	if err != nil || !got.Silent || len(fake.deferred) != 1 {
+		t.Fatalf("expected a silent send past the timeout, got %#v (%v)", got, err)
+	}
+
+	req.Priority = contract.PriorityHigh
+	if got, _ := applyQuietHours(ctx, cfg, "telegram", req, false, &errOut, &errOut); got.Silent {
+		t.Fatalf("expected high priority to bypass quiet hours")
+	}
+}
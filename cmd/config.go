@@ -6,6 +6,9 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
 	"strings"
 
 	"github.com/AlhasanIQ/consult-human/config"
@@ -18,7 +21,10 @@ func runConfig(args []string, io IO) error {
 	}
 
 	sub := strings.ToLower(strings.TrimSpace(args[0]))
-	subArgs := args[1:]
+	subArgs, err := applyProfileFlag(args[1:])
+	if err != nil {
+		return err
+	}
 
 	switch sub {
 	case "path":
@@ -29,6 +35,12 @@ func runConfig(args []string, io IO) error {
 		fmt.Fprintln(io.Out, path)
 		return nil
 	case "show":
+		if len(subArgs) == 1 && subArgs[0] == "--origin" {
+			return runConfigShowOrigin(io)
+		}
+		if len(subArgs) != 0 {
+			return fmt.Errorf("usage: consult-human config show [--origin]")
+		}
 		cfg, err := config.Load()
 		if err != nil {
 			return err
@@ -37,49 +49,29 @@ func runConfig(args []string, io IO) error {
 		if err != nil {
 			return err
 		}
+		// The profile goes to stderr so the output stays valid JSON and can
+		// be piped into config import.
+		if profile, _ := config.ActiveProfile(); profile != "" {
+			fmt.Fprintf(io.ErrOut, "# profile: %s\n", profile)
+		}
 		_, err = io.Out.Write(b)
 		return err
 	case "init":
-		path, err := config.ConfigPath()
-		if err != nil {
-			return err
-		}
-		_, statErr := os.Stat(path)
-		if statErr == nil {
-			fmt.Fprintf(io.ErrOut, "Config already exists at %s\n", path)
-			return nil
-		}
-		if !errors.Is(statErr, os.ErrNotExist) {
-			return statErr
-		}
-		cfg := config.Default()
-		if err := config.Save(cfg); err != nil {
-			return err
-		}
-		fmt.Fprintf(io.ErrOut, "Initialized config at %s\n", path)
-		return nil
+		return runConfigInit(subArgs, io)
 	case "set":
-		if len(subArgs) < 2 {
-			printConfigUsage(io.ErrOut)
-			return fmt.Errorf("usage: consult-human config set <key> <value>")
-		}
-		key := subArgs[0]
-		value := strings.Join(subArgs[1:], " ")
-
-		cfg, err := config.Load()
-		if err != nil {
-			return err
-		}
-		if err := config.Set(&cfg, key, value); err != nil {
-			return err
-		}
-		if err := config.Save(cfg); err != nil {
-			return err
-		}
-		fmt.Fprintf(io.ErrOut, "Updated %s\n", key)
-		return nil
+		return runConfigSet(subArgs, io)
+	case "edit":
+		return runConfigEdit(subArgs, io)
 	case "reset":
 		return runConfigReset(subArgs, io)
+	case "doctor":
+		return runConfigDoctor(subArgs, io)
+	case "validate":
+		return runConfigValidate(subArgs, io)
+	case "export":
+		return runConfigExport(subArgs, io)
+	case "import":
+		return runConfigImport(subArgs, io)
 	case "help", "--help", "-h":
 		printConfigUsage(io.Out)
 		return nil
@@ -92,37 +84,381 @@ func runConfig(args []string, io IO) error {
 func printConfigUsage(w io.Writer) {
 	fmt.Fprintln(w, "Usage:")
 	fmt.Fprintln(w, "  consult-human config path")
-	fmt.Fprintln(w, "  consult-human config show")
-	fmt.Fprintln(w, "  consult-human config init")
-	fmt.Fprintln(w, "  consult-human config set <key> <value>")
-	fmt.Fprintln(w, "  consult-human config reset [--provider telegram|whatsapp] [--keep-storage]")
+	fmt.Fprintln(w, "  consult-human config show [--origin]")
+	fmt.Fprintln(w, "  consult-human config init [--repo | --format yaml|json|toml]")
+	fmt.Fprintln(w, "  consult-human config edit")
+	fmt.Fprintln(w, "  consult-human config set [--skip-verify] [--profile NAME] <key> <value>")
+	fmt.Fprintln(w, "  consult-human config set [--skip-verify] [--profile NAME] <key>=<value>...")
+	fmt.Fprintln(w, "  consult-human config reset [--provider telegram|slack|signal|email|ntfy|webhook|desktop|zulip|pushover|imessage|whatsapp] [--keep-storage]")
+	fmt.Fprintln(w, "  consult-human config validate [--strict]")
+	fmt.Fprintln(w, "  consult-human config doctor [--offline]")
+	fmt.Fprintln(w, "  consult-human config export [--include-secrets]")
+	fmt.Fprintln(w, "  consult-human config import <file>")
 	fmt.Fprintln(w, "")
 	fmt.Fprintln(w, "Supported keys:")
 	fmt.Fprintln(w, "  default-provider | provider | active_provider")
+	fmt.Fprintln(w, "  fallback_providers (comma-separated, tried in order when sending fails)")
+	fmt.Fprintln(w, "  broadcast_providers (comma-separated, asked at once; the first answer wins)")
 	fmt.Fprintln(w, "  request_timeout")
+	fmt.Fprintln(w, "  quiet_hours.start, quiet_hours.end (e.g. 23:00 and 07:00; both set enables quiet hours)")
+	fmt.Fprintln(w, "  quiet_hours.timezone (IANA name such as Europe/Berlin; default UTC)")
+	fmt.Fprintln(w, "  quiet_hours.behavior (silent|defer; default silent, defer holds questions until quiet hours end)")
+	fmt.Fprintln(w, "  escalation.after, escalation.provider (re-send unanswered questions through another provider after this long)")
+	fmt.Fprintln(w, "  escalation.recipient (optional chat, channel, number or address for the escalation provider)")
 	fmt.Fprintln(w, "  telegram.bot_token")
-	fmt.Fprintln(w, "  telegram.chat_id")
+	fmt.Fprintln(w, "  telegram.chat_id (numeric ID, or @username of a channel or group the bot is in)")
 	fmt.Fprintln(w, "  telegram.poll_interval_seconds")
+	fmt.Fprintln(w, "  telegram.send_retries")
+	fmt.Fprintln(w, "  telegram.max_requests_per_second (default 5; shared by every process using the bot token, 0 disables)")
+	fmt.Fprintln(w, "  telegram.priority_ping_after (0 disables the high-priority follow-up ping)")
+	fmt.Fprintln(w, "  telegram.remind_after (empty or 0 disables the unanswered-question reminder)")
+	fmt.Fprintln(w, "  telegram.parse_mode (plain|markdown|html; default plain)")
+	fmt.Fprintln(w, "  telegram.mark_answered (default true; edits answered questions to show the reply)")
+	fmt.Fprintln(w, "  telegram.auto_persist_chat_id (default true; saves the chat a /start links during ask as telegram.chat_id)")
+	fmt.Fprintln(w, "  telegram.notify_timeout (default true; marks questions the agent stopped waiting on)")
+	fmt.Fprintln(w, "  telegram.group_mode (default false; in group chats only replies to the bot and @mentions count as answers)")
+	fmt.Fprintln(w, "  telegram.reminder.text_template (Go template with {{.PendingCount}}; empty uses the built-in wording)")
+	fmt.Fprintln(w, "  telegram.reminder.cooldown (default 20s; 0 disables the reply-directly reminder)")
+	fmt.Fprintln(w, "  telegram.reminder.max_per_request (default 0, no limit)")
+	fmt.Fprintln(w, "  telegram.sender_label (shown above each question; {host} and {repo} are filled in, e.g. \"{host} · {repo}\"; empty by default)")
+	fmt.Fprintln(w, "  telegram.code_block_max_lines (default 40; longer fenced code blocks are sent as attached files)")
+	fmt.Fprintln(w, "  telegram.inline_code_blocks (default false; true keeps long code blocks in the message)")
+	fmt.Fprintln(w, "  telegram.silent (default false; deliver questions without a notification sound, as --priority low does)")
+	fmt.Fprintln(w, "  telegram.reactions (emoji=answer pairs, default \"👍=yes,✅=yes,👎=no,❌=no\"; none disables reaction answers)")
+	fmt.Fprintln(w, "  telegram.typing_indicator (default true; shows \"typing…\" while a question is being sent)")
+	fmt.Fprintln(w, "  telegram.confirm_replies (choice|all|off; default choice confirms choice answers only)")
+	fmt.Fprintln(w, "  telegram.dedupe_window (default 2m; 0 always sends repeated identical questions)")
+	fmt.Fprintln(w, "  telegram.unreachable_after (default 5m; how long ask retries a failing getUpdates, 0 fails at once)")
+	fmt.Fprintln(w, "  telegram.receive_mode (polling|webhook; webhook needs `consult-human serve telegram-webhook`)")
+	fmt.Fprintln(w, "  telegram.webhook_secret (secret token Telegram sends with webhook updates)")
+	fmt.Fprintln(w, "  telegram.api_base_url (default https://api.telegram.org; for a self-hosted Bot API server)")
+	fmt.Fprintln(w, "  telegram.auto_delete_webhook (default false; remove a webhook that blocks polling)")
+	fmt.Fprintln(w, "  telegram.allowed_user_ids (comma-separated; only these users' replies count)")
+	fmt.Fprintln(w, "  telegram.allowed_usernames (comma-separated; only these users' replies count)")
+	fmt.Fprintln(w, "  telegram.chats.<alias> (chat ID for ask --chat <alias>; empty removes)")
+	fmt.Fprintln(w, "  telegram.transcribe_command (run with a voice reply's audio path; stdout becomes the reply text)")
+	fmt.Fprintln(w, "  telegram.strict_reply (true requires replying to the question message)")
+	fmt.Fprintln(w, "  telegram.long_message_mode (split|document for questions over 4096 characters)")
 	fmt.Fprintln(w, "  telegram.pending_store_path (alias: telegram.store_path)")
+	fmt.Fprintln(w, "  slack.bot_token (xoxb- bot token with chat:write and channels:history)")
+	fmt.Fprintln(w, "  slack.channel (channel ID the bot was invited to; replies are read from each question's thread)")
+	fmt.Fprintln(w, "  slack.poll_interval_seconds (default 2)")
+	fmt.Fprintln(w, "  signal.api_url (signal-cli-rest-api URL, default http://127.0.0.1:8080)")
+	fmt.Fprintln(w, "  signal.number (number registered with signal-cli-rest-api, e.g. +15551234567)")
+	fmt.Fprintln(w, "  signal.recipient (phone number questions are sent to)")
+	fmt.Fprintln(w, "  signal.poll_interval_seconds (default 2)")
+	fmt.Fprintln(w, "  email.smtp_host, email.smtp_port (default 587, STARTTLS; 465 for implicit TLS)")
+	fmt.Fprintln(w, "  email.smtp_username, email.smtp_password")
+	fmt.Fprintln(w, "  email.from, email.to (questions go from -> to; replies are read from the IMAP mailbox)")
+	fmt.Fprintln(w, "  email.imap_host, email.imap_port (default 993, TLS)")
+	fmt.Fprintln(w, "  email.imap_username, email.imap_password (default to the SMTP login)")
+	fmt.Fprintln(w, "  email.mailbox (default INBOX)")
+	fmt.Fprintln(w, "  email.poll_interval_seconds (default 15)")
+	fmt.Fprintln(w, "  ntfy.server (default https://ntfy.sh; set for a self-hosted server)")
+	fmt.Fprintln(w, "  ntfy.topic (questions are published here; answers go to <topic>-replies)")
+	fmt.Fprintln(w, "  ntfy.token (tk_ access token for servers with access control)")
+	fmt.Fprintln(w, "  webhook.url (questions are POSTed here as ask request JSON)")
+	fmt.Fprintln(w, "  webhook.secret (signs both directions with X-Consult-Human-Signature: sha256=<hex HMAC-SHA256 of the body>)")
+	fmt.Fprintln(w, "  webhook.callback_listen (host:port answers are POSTed to, e.g. 127.0.0.1:8787)")
+	fmt.Fprintln(w, "  webhook.poll_url (instead of callback_listen: GET <poll_url>?request_id=<id>; 200 with a reply, 204 or 404 until answered)")
+	fmt.Fprintln(w, "  webhook.tls_cert, webhook.tls_key (serve callback_listen over HTTPS)")
+	fmt.Fprintln(w, "  desktop.port (port of the local reply form on 127.0.0.1; default 0 picks a free one)")
+	fmt.Fprintln(w, "  zulip.site, zulip.email, zulip.api_key (organization URL and the bot's login from its zuliprc)")
+	fmt.Fprintln(w, "  zulip.stream (questions get their own topic here) or zulip.recipient (email for direct messages)")
+	fmt.Fprintln(w, "  zulip.poll_interval_seconds (default 2)")
+	fmt.Fprintln(w, "  pushover.app_token, pushover.user_key (application API token and your user key from pushover.net)")
+	fmt.Fprintln(w, "  pushover.reply_url (public URL of the reply form, e.g. a tunnel; empty means confirm-only: acknowledging is the answer)")
+	fmt.Fprintln(w, "  pushover.listen (where the reply form listens; default 127.0.0.1:8788)")
+	fmt.Fprintln(w, "  imessage.handle (macOS only: your phone number in international format or Apple ID email)")
+	fmt.Fprintln(w, "  imessage.db_path (default ~/Library/Messages/chat.db), imessage.poll_interval_seconds (default 2)")
 	fmt.Fprintln(w, "  whatsapp.recipient")
 	fmt.Fprintln(w, "  whatsapp.store_path")
 	fmt.Fprintln(w, "")
+	fmt.Fprintln(w, "Webhook JSON contract:")
+	fmt.Fprintln(w, `  question (POST to webhook.url): {"request_id":"...","question":"...","type":"open|choice","choices":[{"id":"a","text":"..."}],"priority":"low|normal|high","sent_at":"<RFC 3339>",...}`)
+	fmt.Fprintln(w, `  answer (POST to callback_listen or 200 from poll_url): {"request_id":"...","text":"...","from":"..."}`)
+	fmt.Fprintln(w, "")
 	fmt.Fprintln(w, "Note: whatsapp provider is temporarily disabled.")
 }
 
+// With CONSULT_HUMAN_CONFIG set, its extension must match --format.
+func runConfigInit(args []string, io IO) error {
+	fs := flag.NewFlagSet("config init", flag.ContinueOnError)
+	fs.SetOutput(io.ErrOut)
+
+	var repo bool
+	var format string
+	fs.BoolVar(&repo, "repo", false, "Write a .consult-human.yaml for the current git repository instead")
+	fs.StringVar(&format, "format", "", "Config file format (yaml|json|toml; default yaml)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 0 || (repo && format != "") {
+		return fmt.Errorf("usage: consult-human config init [--repo | --format yaml|json|toml]")
+	}
+	if repo {
+		return runConfigInitRepo(io)
+	}
+
+	path, err := config.ConfigPath()
+	if err != nil {
+		return err
+	}
+	_, statErr := os.Stat(path)
+	if statErr == nil {
+		fmt.Fprintf(io.ErrOut, "Config already exists at %s\n", path)
+		return nil
+	}
+	if !errors.Is(statErr, os.ErrNotExist) {
+		return statErr
+	}
+	if format = strings.ToLower(strings.TrimSpace(format)); format != "" {
+		if format != config.FormatYAML && format != config.FormatJSON && format != config.FormatTOML {
+			return fmt.Errorf("--format must be yaml, json or toml")
+		}
+		current, err := config.FormatOf(path)
+		if err != nil {
+			return err
+		}
+		if current != format {
+			if strings.TrimSpace(os.Getenv(config.EnvConfigPath)) != "" {
+				return fmt.Errorf("--format %s does not match %s=%s; point it at a .%s file instead", format, config.EnvConfigPath, path, format)
+			}
+			path = strings.TrimSuffix(path, filepath.Ext(path)) + "." + format
+		}
+	}
+	if err := config.SaveAs(path, config.Default()); err != nil {
+		return err
+	}
+	fmt.Fprintf(io.ErrOut, "Initialized config at %s\n", path)
+	return nil
+}
+
+// Every pair is checked before the file is written.
+func runConfigSet(args []string, io IO) error {
+	skipVerify := false
+	if len(args) > 0 && args[0] == "--skip-verify" {
+		skipVerify = true
+		args = args[1:]
+	}
+
+	type pair struct{ key, value string }
+	var pairs []pair
+	if len(args) > 0 && strings.Contains(args[0], "=") {
+		for _, arg := range args {
+			key, value, ok := strings.Cut(arg, "=")
+			if !ok || strings.TrimSpace(key) == "" {
+				return fmt.Errorf("expected key=value, got %q; use `config set <key> <value>` to set a single key", arg)
+			}
+			pairs = append(pairs, pair{key: strings.TrimSpace(key), value: value})
+		}
+	} else if len(args) >= 2 {
+		pairs = []pair{{key: args[0], value: strings.Join(args[1:], " ")}}
+	} else {
+		printConfigUsage(io.ErrOut)
+		return fmt.Errorf("usage: consult-human config set <key> <value> | <key>=<value>...")
+	}
+
+	cfg, err := config.LoadGlobal()
+	if err != nil {
+		return err
+	}
+	verifyToken := false
+	keys := make([]string, 0, len(pairs))
+	var chatIDPairs []pair
+	for _, p := range pairs {
+		keys = append(keys, p.key)
+		if strings.EqualFold(strings.TrimSpace(p.key), "telegram.chat_id") {
+			// Looked up below, with the token and API URL set alongside it.
+			chatIDPairs = append(chatIDPairs, p)
+			continue
+		}
+		if err := config.Set(&cfg, p.key, p.value); err != nil {
+			return fmt.Errorf("%s: %w", p.key, err)
+		}
+		if strings.EqualFold(strings.TrimSpace(p.key), "telegram.bot_token") {
+			verifyToken = true
+		}
+	}
+	for _, p := range chatIDPairs {
+		value, err := resolveTelegramChatID(cfg, p.value, skipVerify, io.ErrOut)
+		if err != nil {
+			return fmt.Errorf("%s: %w", p.key, err)
+		}
+		if err := config.Set(&cfg, p.key, value); err != nil {
+			return fmt.Errorf("%s: %w", p.key, err)
+		}
+	}
+	if verifyToken && cfg.Telegram.BotToken != "" && !skipVerify {
+		username, err := verifyTelegramSetupToken(cfg)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(io.ErrOut, "Token OK — bot is @%s, now send /start to it\n", username)
+	}
+	if err := config.Save(cfg); err != nil {
+		return err
+	}
+	if len(keys) == 1 {
+		fmt.Fprintf(io.ErrOut, "Updated %s\n", keys[0])
+	} else {
+		fmt.Fprintf(io.ErrOut, "Updated %d keys: %s\n", len(keys), strings.Join(keys, ", "))
+	}
+	return nil
+}
+
+func runConfigValidate(args []string, io IO) error {
+	fs := flag.NewFlagSet("config validate", flag.ContinueOnError)
+	fs.SetOutput(io.ErrOut)
+	var strict bool
+	fs.BoolVar(&strict, "strict", false, "Treat unknown keys as errors")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 0 {
+		return fmt.Errorf("usage: consult-human config validate [--strict]")
+	}
+	if strict {
+		if err := os.Setenv(config.EnvStrictConfig, "1"); err != nil {
+			return err
+		}
+	}
+
+	path, err := config.ConfigPath()
+	if err != nil {
+		return err
+	}
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+	if err := config.Validate(cfg); err != nil {
+		return err
+	}
+	fmt.Fprintf(io.Out, "%s is valid\n", path)
+	return nil
+}
+
+var telegramChatUsernamePattern = regexp.MustCompile(`^(?:https?://)?(?:t\.me/|@)?([A-Za-z][A-Za-z0-9_]{3,31})$`)
+
+// Anything that is neither a username nor a number is left for config.Set to reject.
+func resolveTelegramChatID(cfg config.Config, value string, skipVerify bool, errOut io.Writer) (string, error) {
+	value = strings.TrimSpace(value)
+	if id, err := strconv.ParseInt(value, 10, 64); err == nil {
+		checkTelegramChatID(cfg, id, skipVerify, errOut)
+		return value, nil
+	}
+	m := telegramChatUsernamePattern.FindStringSubmatch(value)
+	if m == nil {
+		return value, nil
+	}
+	username := "@" + m[1]
+	if strings.TrimSpace(cfg.Telegram.BotToken) == "" {
+		return "", fmt.Errorf("looking up %s needs the bot token; run `consult-human config set telegram.bot_token <TOKEN>` first, or give the numeric chat ID", username)
+	}
+	if skipVerify {
+		return "", fmt.Errorf("cannot look up %s with --skip-verify; give the numeric chat ID", username)
+	}
+	apiBaseURL, err := config.EffectiveTelegramAPIBaseURL(cfg)
+	if err != nil {
+		return "", err
+	}
+	chat, err := telegramSetupGetChatFn(apiBaseURL, cfg.Telegram.BotToken, username)
+	if errors.Is(err, errTelegramSetupChatNotFound) {
+		return "", fmt.Errorf("telegram cannot find %s: add the bot to that channel or group first. Private chats have no username to look up; send /start to the bot and run `consult-human setup` instead", username)
+	}
+	if err != nil {
+		return "", fmt.Errorf("could not look up %s: %w", username, err)
+	}
+	fmt.Fprintf(errOut, "Resolved %s to %s, chat ID %d\n", username, chat.label(), chat.ID)
+	return strconv.FormatInt(chat.ID, 10), nil
+}
+
+// Supergroup and channel IDs start with -100; t.me/c/ links show them without it.
+func checkTelegramChatID(cfg config.Config, id int64, skipVerify bool, errOut io.Writer) {
+	if id == 0 {
+		return
+	}
+	digits := strconv.FormatInt(id, 10)
+	digits = strings.TrimPrefix(digits, "-")
+	prefixed := "-100" + digits
+	hasPrefix := id < 0 && strings.HasPrefix(digits, "100")
+
+	if strings.TrimSpace(cfg.Telegram.BotToken) == "" || skipVerify {
+		if id < 0 && !hasPrefix {
+			fmt.Fprintf(errOut, "note: %d is a basic group ID; if the chat is a supergroup or channel, its ID is %s\n", id, prefixed)
+		}
+		return
+	}
+	apiBaseURL, err := config.EffectiveTelegramAPIBaseURL(cfg)
+	if err != nil {
+		return
+	}
+	if _, err := telegramSetupGetChatFn(apiBaseURL, cfg.Telegram.BotToken, strconv.FormatInt(id, 10)); !errors.Is(err, errTelegramSetupChatNotFound) {
+		return
+	}
+	if !hasPrefix {
+		if chat, err := telegramSetupGetChatFn(apiBaseURL, cfg.Telegram.BotToken, prefixed); err == nil {
+			fmt.Fprintf(errOut, "warning: telegram cannot find chat %d, but %s is %s. Supergroup and channel IDs start with -100: run `consult-human config set telegram.chat_id %s`\n", id, prefixed, chat.label(), prefixed)
+			return
+		}
+	}
+	fmt.Fprintf(errOut, "warning: telegram cannot find chat %d; questions will fail until the bot is in that chat, or the person has sent it /start\n", id)
+}
+
+func runConfigShowOrigin(io IO) error {
+	settings, err := config.Settings()
+	if err != nil {
+		return err
+	}
+	if path, err := config.ConfigPath(); err == nil {
+		fmt.Fprintf(io.Out, "# global: %s\n", path)
+	}
+	if path, found, err := config.RepoConfigPath(); err == nil && found {
+		if _, err := os.Stat(path); err == nil {
+			fmt.Fprintf(io.Out, "# repo:   %s\n", path)
+		}
+	}
+	for _, s := range settings {
+		fmt.Fprintf(io.Out, "%s: %s  (%s)\n", s.Key, s.Value, s.Origin)
+	}
+	return nil
+}
+
+func runConfigInitRepo(io IO) error {
+	path, found, err := config.RepoConfigPath()
+	if err != nil {
+		return err
+	}
+	if !found {
+		return fmt.Errorf("not inside a git repository; run `consult-human config init --repo` from the repository")
+	}
+	if _, err := os.Stat(path); err == nil {
+		fmt.Fprintf(io.ErrOut, "Repository config already exists at %s\n", path)
+		return nil
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	if err := os.WriteFile(path, []byte(config.RepoConfigTemplate), 0o644); err != nil {
+		return err
+	}
+	fmt.Fprintf(io.ErrOut, "Initialized repository config at %s\n", path)
+	return nil
+}
+
 func runConfigReset(args []string, io IO) error {
 	fs := flag.NewFlagSet("config reset", flag.ContinueOnError)
 	fs.SetOutput(io.ErrOut)
 
 	var providerName string
 	var keepStorage bool
-	fs.StringVar(&providerName, "provider", "", "Reset only one provider (telegram|whatsapp)")
+	fs.StringVar(&providerName, "provider", "", "Reset only one provider (telegram|slack|signal|email|ntfy|webhook|desktop|zulip|pushover|imessage|whatsapp)")
 	fs.BoolVar(&keepStorage, "keep-storage", false, "Do not clear local storage/cache files during reset")
 	if err := fs.Parse(args); err != nil {
 		return err
 	}
 	if fs.NArg() != 0 {
-		return fmt.Errorf("usage: consult-human config reset [--provider telegram|whatsapp] [--keep-storage]")
+		return fmt.Errorf("usage: consult-human config reset [--provider telegram|slack|signal|email|ntfy|webhook|desktop|zulip|pushover|imessage|whatsapp] [--keep-storage]")
 	}
 
 	path, err := config.ConfigPath()
@@ -154,8 +490,14 @@ func runConfigReset(args []string, io IO) error {
 		return nil
 	}
 
-	if providerName != "telegram" && providerName != "whatsapp" {
-		return fmt.Errorf("provider must be telegram or whatsapp")
+	if providerName != "telegram" && providerName != "slack" && providerName != "signal" && providerName != "email" && providerName != "ntfy" && providerName != "webhook" && providerName != "desktop" && providerName != "zulip" && providerName != "pushover" && providerName != "imessage" && providerName != "whatsapp" {
+		return fmt.Errorf("provider must be telegram, slack, signal, email, ntfy, webhook, desktop, zulip, pushover, imessage or whatsapp")
+	}
+	// Slack, email, webhook, desktop, zulip, pushover and imessage keep
+	// nothing on disk; their questions are tracked per process.
+	switch providerName {
+	case "slack", "email", "webhook", "desktop", "zulip", "pushover", "imessage":
+		keepStorage = true
 	}
 
 	if _, err := os.Stat(path); err != nil {
@@ -173,7 +515,7 @@ func runConfigReset(args []string, io IO) error {
 		return err
 	}
 
-	cfg, err := config.Load()
+	cfg, err := config.LoadGlobal()
 	if err != nil {
 		return err
 	}
@@ -189,6 +531,24 @@ func runConfigReset(args []string, io IO) error {
 	switch providerName {
 	case "telegram":
 		cfg.Telegram = config.TelegramConfig{}
+	case "slack":
+		cfg.Slack = config.SlackConfig{}
+	case "signal":
+		cfg.Signal = config.SignalConfig{}
+	case "email":
+		cfg.Email = config.EmailConfig{}
+	case "ntfy":
+		cfg.Ntfy = config.NtfyConfig{}
+	case "webhook":
+		cfg.Webhook = config.WebhookConfig{}
+	case "desktop":
+		cfg.Desktop = config.DesktopConfig{}
+	case "zulip":
+		cfg.Zulip = config.ZulipConfig{}
+	case "pushover":
+		cfg.Pushover = config.PushoverConfig{}
+	case "imessage":
+		cfg.IMessage = config.IMessageConfig{}
 	case "whatsapp":
 		cfg.WhatsApp = config.WhatsAppConfig{}
 	}
@@ -199,7 +559,7 @@ func runConfigReset(args []string, io IO) error {
 			cfg.ActiveProvider = "telegram"
 		}
 	}
-	if cfg.ActiveProvider == "whatsapp" {
+	if cfg.ActiveProvider == "whatsapp" || (cfg.ActiveProvider == providerName && providerName != "telegram") {
 		cfg.ActiveProvider = "telegram"
 	}
 
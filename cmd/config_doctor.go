@@ -0,0 +1,234 @@
+package cmd
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/AlhasanIQ/consult-human/config"
+)
+
+// doctor prints one ✓ or ✗ line per check and counts the failures. Each
+// failure comes with the command or setting that fixes it.
+type doctor struct {
+	s        *sty
+	failures int
+}
+
+func (d *doctor) pass(text string) {
+	d.s.success(text)
+}
+
+func (d *doctor) fail(text, fix string) {
+	d.failures++
+	d.s.errMsg(text)
+	if fix != "" {
+		d.s.info(d.s.dim("→ " + fix))
+	}
+}
+
+// note reports something worth knowing that does not break anything.
+func (d *doctor) note(text string) {
+	d.s.info(text)
+}
+
+// runConfigDoctor checks the local installation the way a support request
+// would: the binary on PATH, the config, the active provider, the Telegram
+// bot and chat, and the installed skill files. --offline skips the checks
+// that call Telegram.
+func runConfigDoctor(args []string, io IO) error {
+	fs := flag.NewFlagSet("config doctor", flag.ContinueOnError)
+	fs.SetOutput(io.ErrOut)
+	var offline bool
+	fs.BoolVar(&offline, "offline", false, "Skip the checks that call Telegram (getMe, getWebhookInfo)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 0 {
+		return fmt.Errorf("usage: consult-human config doctor [--offline]")
+	}
+
+	d := &doctor{s: newSty(io.Out)}
+	d.s.section("Installation")
+	doctorCheckPath(d)
+
+	d.s.section("Config")
+	cfg, ok := doctorCheckConfig(d)
+	if ok {
+		doctorCheckProvider(d, cfg)
+	}
+
+	if ok && doctorUsesTelegram(cfg) {
+		d.s.section("Telegram")
+		doctorCheckTelegram(d, cfg, offline)
+	}
+
+	d.s.section("Skill")
+	doctorCheckSkill(d)
+
+	fmt.Fprintln(io.Out)
+	if d.failures > 0 {
+		return fmt.Errorf("config doctor found %d problem(s)", d.failures)
+	}
+	d.s.success("No problems found")
+	return nil
+}
+
+// doctorCheckPath makes sure a shell can find consult-human, adding it to
+// the shell profile the way setup does when it is missing.
+func doctorCheckPath(d *doctor) {
+	if path, err := setupLookPathFn("consult-human"); err == nil {
+		d.pass("consult-human is on PATH: " + path)
+		return
+	}
+	status, err := setupEnsureShellPathFn()
+	switch {
+	case err != nil:
+		d.fail("consult-human is not on PATH and could not be added: "+err.Error(), "add the directory of the consult-human binary to PATH in your shell profile")
+	case strings.TrimSpace(status.SkippedReason) != "":
+		d.fail("consult-human is not on PATH: "+status.SkippedReason, "add the directory of the consult-human binary to PATH in your shell profile")
+	case status.Changed:
+		d.pass(fmt.Sprintf("Added %s to PATH via %s", status.BinaryDir, status.ProfilePath))
+		d.note("Open a new shell for it to take effect.")
+	default:
+		d.fail(fmt.Sprintf("consult-human is not on PATH in this shell, though %s adds %s", status.ProfilePath, status.BinaryDir), "open a new shell, or run `source "+status.ProfilePath+"`")
+	}
+}
+
+// doctorCheckConfig loads and validates the effective config. ok is false
+// when it cannot be used for the checks that follow.
+func doctorCheckConfig(d *doctor) (config.Config, bool) {
+	path, err := config.ConfigPath()
+	if err != nil {
+		d.fail("could not locate the config file: "+err.Error(), "check "+config.EnvConfigPath+" and "+config.EnvProfile)
+		return config.Config{}, false
+	}
+	if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) {
+		d.fail("no config file at "+path, "run `consult-human setup`")
+	} else if err != nil {
+		d.fail("cannot read "+path+": "+err.Error(), "check the file's permissions")
+		return config.Config{}, false
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		d.fail("config does not load: "+err.Error(), "run `consult-human config edit` to fix it")
+		return config.Config{}, false
+	}
+	if err := config.Validate(cfg); err != nil {
+		d.fail("config is invalid: "+err.Error(), "run `consult-human config edit`, or set the value again with `consult-human config set`")
+		return cfg, true
+	}
+	d.pass("Config parses and validates: " + path)
+	return cfg, true
+}
+
+// doctorCheckProvider checks that the active provider has what it needs.
+func doctorCheckProvider(d *doctor, cfg config.Config) {
+	name := cfg.ActiveProvider
+	if !isProviderSetupComplete(cfg, name) {
+		d.fail(fmt.Sprintf("active provider %s is not set up", name), fmt.Sprintf("run `consult-human setup --provider %s`", name))
+		return
+	}
+	d.pass(fmt.Sprintf("Active provider %s is set up", name))
+}
+
+// doctorUsesTelegram reports whether any question could go through Telegram.
+func doctorUsesTelegram(cfg config.Config) bool {
+	for _, name := range append(append([]string{cfg.ActiveProvider, cfg.Escalation.Provider}, cfg.FallbackProviders...), cfg.BroadcastProviders...) {
+		if name == setupProviderTelegram {
+			return true
+		}
+	}
+	return false
+}
+
+// doctorCheckTelegram checks the bot token, the chat link and, online, that
+// Telegram accepts the token and no webhook stands in the way of polling.
+func doctorCheckTelegram(d *doctor, cfg config.Config, offline bool) {
+	token := strings.TrimSpace(cfg.Telegram.BotToken)
+	if token == "" {
+		d.fail("telegram.bot_token is not set", "run `consult-human setup --provider telegram`")
+		return
+	}
+	if cfg.Telegram.ChatID == 0 {
+		d.fail("no Telegram chat is linked", "run `consult-human setup --provider telegram --link-chat`")
+	} else {
+		d.pass(fmt.Sprintf("Chat linked: %d", cfg.Telegram.ChatID))
+	}
+
+	if offline {
+		d.note(d.s.dim("Skipped the bot token and webhook checks (--offline)"))
+		return
+	}
+	apiBaseURL, err := config.EffectiveTelegramAPIBaseURL(cfg)
+	if err != nil {
+		d.fail(err.Error(), "run `consult-human config set telegram.api_base_url <URL>`")
+		return
+	}
+	username, err := telegramSetupGetMeFn(apiBaseURL, token)
+	switch {
+	case errors.Is(err, errTelegramSetupTokenRejected):
+		d.fail("Telegram rejects the bot token", "copy the token from @BotFather again: `consult-human config set telegram.bot_token <TOKEN>`")
+		return
+	case err != nil:
+		d.fail("could not reach Telegram: "+err.Error(), "check the network, or run with --offline")
+		return
+	}
+	d.pass("Bot token is valid: @" + username)
+
+	webhookURL, err := telegramSetupWebhookURLFn(apiBaseURL, token)
+	if err != nil {
+		d.fail("could not check the bot's webhook: "+err.Error(), "check the network, or run with --offline")
+		return
+	}
+	webhookMode := cfg.Telegram.ReceiveMode == config.TelegramReceiveModeWebhook
+	switch {
+	case webhookMode && webhookURL == "":
+		d.fail("telegram.receive_mode is webhook, but the bot has no webhook", "run `consult-human serve telegram-webhook --url <URL>`")
+	case webhookMode:
+		d.pass("Webhook set for webhook mode: " + webhookURL)
+	case webhookURL != "" && !cfg.Telegram.AutoDeleteWebhook:
+		d.fail("a webhook ("+webhookURL+") blocks polling for replies", "run `consult-human config set telegram.auto_delete_webhook true`, or remove it with `consult-human setup --provider telegram`")
+	case webhookURL != "":
+		d.pass("A webhook is set and will be removed when polling starts (telegram.auto_delete_webhook)")
+	default:
+		d.pass("No webhook blocks polling")
+	}
+}
+
+// doctorCheckSkill looks for the skill at every user-global destination.
+// A broken symlink is a failure; so is having no skill installed at all.
+func doctorCheckSkill(d *doctor) {
+	destinations, _, err := resolveSkillDestinations(skillTargetBoth, "")
+	if err != nil {
+		d.fail("could not resolve skill directories: "+err.Error(), "")
+		return
+	}
+	installed := 0
+	for _, path := range destinations {
+		info, err := os.Lstat(path)
+		if errors.Is(err, os.ErrNotExist) {
+			d.note(d.s.dim("Not installed: " + path))
+			continue
+		}
+		if err != nil {
+			d.fail("cannot read "+path+": "+err.Error(), "run `consult-human skill install`")
+			continue
+		}
+		if info.Mode()&os.ModeSymlink != 0 {
+			if _, err := os.Stat(path); err != nil {
+				target, _ := os.Readlink(path)
+				d.fail(fmt.Sprintf("%s is a broken link to %s", path, target), "run `consult-human skill install`")
+				continue
+			}
+		}
+		installed++
+		d.pass("Skill installed: " + path)
+	}
+	if installed == 0 {
+		d.fail("the consult-human skill is not installed for any agent", "run `consult-human skill install`")
+	}
+}
@@ -0,0 +1,152 @@
+package cmd
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/AlhasanIQ/consult-human/config"
+)
+
+// configEditIsTerminalFn and configEditRunEditorFn are replaced by tests.
+var (
+	configEditIsTerminalFn = askInputIsTerminal
+	configEditRunEditorFn  = runConfigEditor
+)
+
+// runConfigEdit opens the config file in the user's editor and checks it
+// once the editor exits. A file that does not parse or validate can be
+// opened again or put back as it was before the edit.
+func runConfigEdit(args []string, io IO) error {
+	if len(args) != 0 {
+		return fmt.Errorf("usage: consult-human config edit")
+	}
+	if !configEditIsTerminalFn(io.In) {
+		return fmt.Errorf("config edit needs an interactive terminal; use `consult-human config set <key> <value>` instead")
+	}
+
+	path, err := config.ConfigPath()
+	if err != nil {
+		return err
+	}
+	if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) {
+		if err := config.Save(config.Default()); err != nil {
+			return err
+		}
+		fmt.Fprintf(io.ErrOut, "Initialized config at %s\n", path)
+	} else if err != nil {
+		return err
+	}
+
+	format, err := config.FormatOf(path)
+	if err != nil {
+		return err
+	}
+	original, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	backup, err := os.CreateTemp("", "consult-human-config-*"+filepath.Ext(path))
+	if err != nil {
+		return err
+	}
+	keepBackup := false
+	defer func() {
+		if !keepBackup {
+			_ = os.Remove(backup.Name())
+		}
+	}()
+	if _, err := backup.Write(original); err != nil {
+		_ = backup.Close()
+		return err
+	}
+	if err := backup.Close(); err != nil {
+		return err
+	}
+
+	reader := bufio.NewReader(io.In)
+	for {
+		if err := configEditRunEditorFn(configEditor(), path); err != nil {
+			return fmt.Errorf("editor failed: %w", err)
+		}
+		edited, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		cfg, err := config.ParseAs(format, edited)
+		if err == nil {
+			err = config.Validate(cfg)
+		}
+		if err == nil {
+			err = reportUnknownConfigKeys(io, path, format, edited)
+		}
+		if err == nil {
+			if string(edited) == string(original) {
+				fmt.Fprintf(io.ErrOut, "No changes to %s\n", path)
+			} else {
+				fmt.Fprintf(io.ErrOut, "Updated %s\n", path)
+			}
+			return nil
+		}
+
+		fmt.Fprintf(io.ErrOut, "Invalid config: %v\n", err)
+		fmt.Fprint(io.ErrOut, "Re-open the editor, or revert to the previous content? [R/v]: ")
+		// Without an answer to read, revert rather than loop.
+		answer, readErr := reader.ReadString('\n')
+		if readErr == nil && !strings.EqualFold(strings.TrimSpace(answer), "v") {
+			continue
+		}
+		if err := os.WriteFile(path, original, 0o600); err != nil {
+			keepBackup = true
+			return fmt.Errorf("could not revert %s (previous content is in %s): %w", path, backup.Name(), err)
+		}
+		fmt.Fprintf(io.ErrOut, "Reverted %s to its previous content\n", path)
+		return nil
+	}
+}
+
+// reportUnknownConfigKeys warns about every key in an edited config file
+// that no setting reads, or fails on them in strict mode.
+func reportUnknownConfigKeys(io IO, path, format string, b []byte) error {
+	keys, err := config.UnknownKeys(format, b)
+	if err != nil || len(keys) == 0 {
+		return err
+	}
+	if config.StrictConfig() {
+		return &config.UnknownKeysError{Path: path, Keys: keys}
+	}
+	for _, k := range keys {
+		fmt.Fprintf(io.ErrOut, "warning: unknown key %s; it is ignored\n", k)
+	}
+	return nil
+}
+
+// configEditor is the editor command line from $VISUAL or $EDITOR, falling
+// back to vi, or notepad on Windows.
+func configEditor() string {
+	for _, env := range []string{"VISUAL", "EDITOR"} {
+		if v := strings.TrimSpace(os.Getenv(env)); v != "" {
+			return v
+		}
+	}
+	if runtime.GOOS == "windows" {
+		return "notepad"
+	}
+	return "vi"
+}
+
+// runConfigEditor runs editor, which may carry its own arguments such as
+// "code --wait", on path and waits for it to exit.
+func runConfigEditor(editor, path string) error {
+	fields := strings.Fields(editor)
+	cmd := exec.Command(fields[0], append(fields[1:], path)...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	return cmd.Run()
+}
@@ -0,0 +1,101 @@
+package cmd
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/AlhasanIQ/consult-human/config"
+)
+
+var configImportIsTerminalFn = askInputIsTerminal
+
+// Secrets are redacted unless --include-secrets is given.
+func runConfigExport(args []string, io IO) error {
+	fs := flag.NewFlagSet("config export", flag.ContinueOnError)
+	fs.SetOutput(io.ErrOut)
+	var includeSecrets bool
+	fs.BoolVar(&includeSecrets, "include-secrets", false, "Keep bot tokens, passwords and other secrets instead of redacting them")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 0 {
+		return fmt.Errorf("usage: consult-human config export [--include-secrets]")
+	}
+
+	cfg, err := config.LoadGlobal()
+	if err != nil {
+		return err
+	}
+	b, err := config.Export(cfg, includeSecrets)
+	if err != nil {
+		return err
+	}
+	if includeSecrets {
+		fmt.Fprintln(io.ErrOut, "note: the export includes secrets; share it privately")
+	}
+	_, err = io.Out.Write(b)
+	return err
+}
+
+// Nothing is saved unless every value passes the `config set` checks.
+func runConfigImport(args []string, io IO) error {
+	if len(args) != 1 {
+		return fmt.Errorf("usage: consult-human config import <file>")
+	}
+	file := args[0]
+	b, err := os.ReadFile(file)
+	if err != nil {
+		return err
+	}
+	settings, err := config.ParseExport(b)
+	if err != nil {
+		return fmt.Errorf("%s: %w", file, err)
+	}
+	if len(settings) == 0 {
+		return fmt.Errorf("%s sets no values", file)
+	}
+
+	var redacted []string
+	for _, setting := range settings {
+		if setting.Redacted {
+			redacted = append(redacted, setting.Key)
+		}
+	}
+	interactive := configImportIsTerminalFn(io.In)
+	if len(redacted) > 0 && !interactive {
+		return fmt.Errorf("%s has redacted values for %s; run `consult-human config import` in a terminal to enter them, or fill them in the file", file, strings.Join(redacted, ", "))
+	}
+
+	cfg, err := config.LoadGlobal()
+	if err != nil {
+		return err
+	}
+	s := newSty(io.ErrOut)
+	reader := bufio.NewReader(io.In)
+	for _, setting := range settings {
+		value := setting.Value
+		if setting.Redacted {
+			if value, err = promptRequiredLine(reader, s, s.promptLabel(fmt.Sprintf("Value for %s: ", setting.Key))); err != nil {
+				return err
+			}
+		}
+		if err := config.Set(&cfg, setting.Key, value); err != nil {
+			return fmt.Errorf("%s: %w", setting.Key, err)
+		}
+	}
+	if err := config.Validate(cfg); err != nil {
+		return fmt.Errorf("%s: %w", file, err)
+	}
+	if err := config.Save(cfg); err != nil {
+		return err
+	}
+	path, err := config.ConfigPath()
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(io.ErrOut, "Imported %d keys from %s into %s\n", len(settings), file, path)
+	return nil
+}
@@ -2,6 +2,8 @@ package cmd
 
 import (
 	"bytes"
+	"errors"
+	"io"
 	"os"
 	"path/filepath"
 	"strings"
@@ -134,6 +136,40 @@ func TestRunConfigResetSpecificProviderTelegram(t *testing.T) {
 	}
 }
 
+func TestRunConfigResetSpecificProviderSlack(t *testing.T) {
+	setTestStateHome(t)
+
+	cfgPath := filepath.Join(t.TempDir(), "config.yaml")
+	t.Setenv(config.EnvConfigPath, cfgPath)
+
+	cfg := config.Default()
+	cfg.ActiveProvider = "slack"
+	cfg.Telegram.BotToken = "tg-token"
+	cfg.Slack = config.SlackConfig{BotToken: "xoxb-token", Channel: "C123"}
+	if err := config.Save(cfg); err != nil {
+		t.Fatalf("config.Save: %v", err)
+	}
+
+	var errOut bytes.Buffer
+	if err := runConfig([]string{"reset", "--provider", "slack"}, IO{In: strings.NewReader(""), Out: &bytes.Buffer{}, ErrOut: &errOut}); err != nil {
+		t.Fatalf("runConfig reset --provider slack returned error: %v", err)
+	}
+
+	got, err := config.Load()
+	if err != nil {
+		t.Fatalf("config.Load: %v", err)
+	}
+	if got.Slack != (config.SlackConfig{}) || got.Telegram.BotToken != "tg-token" {
+		t.Fatalf("expected only slack config to be cleared, got slack=%#v telegram token=%q", got.Slack, got.Telegram.BotToken)
+	}
+	if got.ActiveProvider != "telegram" {
+		t.Fatalf("expected active provider to fall back to telegram, got %q", got.ActiveProvider)
+	}
+	if strings.Contains(errOut.String(), "Deleted") {
+		t.Fatalf("expected no storage to be cleared for slack, got: %q", errOut.String())
+	}
+}
+
 func TestRunConfigResetSpecificProviderInvalid(t *testing.T) {
 	setTestStateHome(t)
 
@@ -150,7 +186,7 @@ func TestRunConfigResetSpecificProviderInvalid(t *testing.T) {
 	if err == nil {
 		t.Fatalf("expected error for invalid provider")
 	}
-	if !strings.Contains(err.Error(), "provider must be telegram or whatsapp") {
+	if !strings.Contains(err.Error(), "provider must be telegram, slack, signal, email, ntfy, webhook, desktop, zulip, pushover, imessage or whatsapp") {
 		t.Fatalf("unexpected error: %v", err)
 	}
 }
@@ -259,3 +295,546 @@ func setTestStateHome(t *testing.T) {
 	t.Helper()
 	t.Setenv("XDG_STATE_HOME", t.TempDir())
 }
+
+func TestRunConfigSetBotTokenVerifiesWithTelegram(t *testing.T) {
+	t.Setenv(config.EnvConfigPath, filepath.Join(t.TempDir(), "config.yaml"))
+	stubTelegramSetupGetMe(t, func(apiBaseURL, token string) (string, error) {
+		if token != "good-token" {
+			return "", errTelegramSetupTokenRejected
+		}
+		return "my_helper_bot", nil
+	})
+
+	err := runConfig([]string{"set", "telegram.bot_token", "typo-token"}, IO{In: strings.NewReader(""), Out: &bytes.Buffer{}, ErrOut: &bytes.Buffer{}})
+	if !errors.Is(err, errTelegramSetupTokenRejected) {
+		t.Fatalf("expected token rejection, got %v", err)
+	}
+	if cfg, err := config.Load(); err != nil || cfg.Telegram.BotToken != "" {
+		t.Fatalf("rejected token must not be saved, got %q (err %v)", cfg.Telegram.BotToken, err)
+	}
+
+	var errOut bytes.Buffer
+	if err := runConfig([]string{"set", "telegram.bot_token", "good-token"}, IO{In: strings.NewReader(""), Out: &bytes.Buffer{}, ErrOut: &errOut}); err != nil {
+		t.Fatalf("runConfig set returned error: %v", err)
+	}
+	if !strings.Contains(errOut.String(), "bot is @my_helper_bot") {
+		t.Fatalf("expected bot username, got %q", errOut.String())
+	}
+
+	if err := runConfig([]string{"set", "--skip-verify", "telegram.bot_token", "offline-token"}, IO{In: strings.NewReader(""), Out: &bytes.Buffer{}, ErrOut: &bytes.Buffer{}}); err != nil {
+		t.Fatalf("runConfig set --skip-verify returned error: %v", err)
+	}
+	if cfg, err := config.Load(); err != nil || cfg.Telegram.BotToken != "offline-token" {
+		t.Fatalf("expected unverified token saved, got %q (err %v)", cfg.Telegram.BotToken, err)
+	}
+}
+
+func TestRunConfigEditValidatesAndReverts(t *testing.T) {
+	cfgPath := filepath.Join(t.TempDir(), "config.yaml")
+	t.Setenv(config.EnvConfigPath, cfgPath)
+
+	origTerminalFn, origEditorFn := configEditIsTerminalFn, configEditRunEditorFn
+	configEditIsTerminalFn = func(r io.Reader) bool { return true }
+	var edits []string
+	configEditRunEditorFn = func(editor, path string) error {
+		content := "active_provider: carrier-pigeon\n"
+		if len(edits) == 1 {
+			content = "request_timeout: [\n"
+		}
+		edits = append(edits, content)
+		return os.WriteFile(path, []byte(content), 0o600)
+	}
+	defer func() { configEditIsTerminalFn, configEditRunEditorFn = origTerminalFn, origEditorFn }()
+
+	var errOut bytes.Buffer
+	if err := runConfig([]string{"edit"}, IO{In: strings.NewReader("\nv\n"), Out: &bytes.Buffer{}, ErrOut: &errOut}); err != nil {
+		t.Fatalf("config edit returned error: %v", err)
+	}
+	if len(edits) != 2 {
+		t.Fatalf("expected the editor to be re-opened once, got %d runs", len(edits))
+	}
+	for _, want := range []string{"Initialized config", "provider must be", "parse config", "Reverted"} {
+		if !strings.Contains(errOut.String(), want) {
+			t.Fatalf("expected %q in output, got: %q", want, errOut.String())
+		}
+	}
+	cfg, err := config.Load()
+	if err != nil {
+		t.Fatalf("config.Load after revert: %v", err)
+	}
+	if cfg.ActiveProvider != "telegram" {
+		t.Fatalf("expected the default config back, got active provider %q", cfg.ActiveProvider)
+	}
+}
+
+func TestRunConfigEditRequiresTerminal(t *testing.T) {
+	t.Setenv(config.EnvConfigPath, filepath.Join(t.TempDir(), "config.yaml"))
+	err := runConfig([]string{"edit"}, IO{In: strings.NewReader(""), Out: &bytes.Buffer{}, ErrOut: &bytes.Buffer{}})
+	if err == nil || !strings.Contains(err.Error(), "config set") {
+		t.Fatalf("expected guidance to use config set, got %v", err)
+	}
+}
+
+func TestRunConfigInitRepoAndShowOrigin(t *testing.T) {
+	t.Setenv(config.EnvConfigPath, filepath.Join(t.TempDir(), "config.yaml"))
+	repo := t.TempDir()
+	if err := os.Mkdir(filepath.Join(repo, ".git"), 0o755); err != nil {
+		t.Fatalf("mkdir .git: %v", err)
+	}
+	t.Chdir(repo)
+
+	if err := runConfig([]string{"init", "--repo"}, IO{In: strings.NewReader(""), Out: &bytes.Buffer{}, ErrOut: &bytes.Buffer{}}); err != nil {
+		t.Fatalf("config init --repo: %v", err)
+	}
+	repoPath := filepath.Join(repo, config.RepoConfigFileName)
+	b, err := os.ReadFile(repoPath)
+	if err != nil || string(b) != config.RepoConfigTemplate {
+		t.Fatalf("expected the repo config template at %s, got %q: %v", repoPath, b, err)
+	}
+	if err := os.WriteFile(repoPath, []byte("request_timeout: 45m\n"), 0o644); err != nil {
+		t.Fatalf("write repo config: %v", err)
+	}
+
+	var out bytes.Buffer
+	if err := runConfig([]string{"show", "--origin"}, IO{In: strings.NewReader(""), Out: &out, ErrOut: &bytes.Buffer{}}); err != nil {
+		t.Fatalf("config show --origin: %v", err)
+	}
+	for _, want := range []string{"# repo:", "request_timeout: 45m  (repo)", "active_provider: telegram  (default)"} {
+		if !strings.Contains(out.String(), want) {
+			t.Fatalf("expected %q in output, got: %q", want, out.String())
+		}
+	}
+}
+
+func TestRunConfigSetWithProfile(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv(config.EnvConfigPath, filepath.Join(dir, "config.yaml"))
+	t.Setenv(config.EnvProfile, "")
+
+	if err := Execute([]string{"config", "set", "--profile", "work", "request_timeout", "42m"}, IO{In: strings.NewReader(""), Out: &bytes.Buffer{}, ErrOut: &bytes.Buffer{}}); err != nil {
+		t.Fatalf("config set --profile: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "config.yaml")); !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("expected the default config to be left alone, got %v", err)
+	}
+	b, err := os.ReadFile(filepath.Join(dir, "config.work.yaml"))
+	if err != nil || !strings.Contains(string(b), "request_timeout: 42m") {
+		t.Fatalf("expected the work profile file to hold the value, got %q: %v", b, err)
+	}
+
+	var out, errOut bytes.Buffer
+	if err := Execute([]string{"--profile", "work", "config", "show"}, IO{In: strings.NewReader(""), Out: &out, ErrOut: &errOut}); err != nil {
+		t.Fatalf("config show: %v", err)
+	}
+	if strings.Contains(out.String(), "# profile") || !strings.Contains(out.String(), "request_timeout: 42m") {
+		t.Fatalf("unexpected config show output: %q", out.String())
+	}
+	if errOut.String() != "# profile: work\n" {
+		t.Fatalf("expected the profile on stderr, got %q", errOut.String())
+	}
+}
+
+func TestRunConfigSetMultipleKeyValues(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	t.Setenv(config.EnvConfigPath, path)
+
+	var errOut bytes.Buffer
+	args := []string{"set", "--skip-verify", "telegram.bot_token=123:abc", "telegram.chat_id=123", "request_timeout=30m"}
+	if err := runConfig(args, IO{In: strings.NewReader(""), Out: &bytes.Buffer{}, ErrOut: &errOut}); err != nil {
+		t.Fatalf("config set: %v", err)
+	}
+	if want := "Updated 3 keys: telegram.bot_token, telegram.chat_id, request_timeout"; !strings.Contains(errOut.String(), want) {
+		t.Fatalf("expected %q, got %q", want, errOut.String())
+	}
+	cfg, err := config.LoadGlobal()
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if cfg.Telegram.BotToken != "123:abc" || cfg.Telegram.ChatID != 123 || cfg.RequestTimeout != "30m" {
+		t.Fatalf("unexpected config: %#v", cfg)
+	}
+}
+
+func TestRunConfigSetMultipleKeyValuesWritesNothingOnError(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	t.Setenv(config.EnvConfigPath, path)
+
+	err := runConfig([]string{"set", "request_timeout=30m", "no_such.key=1"}, IO{In: strings.NewReader(""), Out: &bytes.Buffer{}, ErrOut: &bytes.Buffer{}})
+	if err == nil || !strings.Contains(err.Error(), "no_such.key") {
+		t.Fatalf("expected an error naming the bad key, got %v", err)
+	}
+	if _, err := os.Stat(path); !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("expected no config to be written, got %v", err)
+	}
+	if err := runConfig([]string{"set", "request_timeout=30m", "oops"}, IO{In: strings.NewReader(""), Out: &bytes.Buffer{}, ErrOut: &bytes.Buffer{}}); err == nil {
+		t.Fatal("expected an argument without = to be rejected")
+	}
+}
+
+func TestRunConfigInitFormat(t *testing.T) {
+	xdg := t.TempDir()
+	t.Setenv(config.EnvConfigPath, "")
+	t.Setenv(config.EnvProfile, "")
+	t.Setenv("XDG_CONFIG_HOME", xdg)
+
+	var errOut bytes.Buffer
+	if err := runConfig([]string{"init", "--format", "json"}, IO{In: strings.NewReader(""), Out: &bytes.Buffer{}, ErrOut: &errOut}); err != nil {
+		t.Fatalf("config init --format json: %v", err)
+	}
+	path := filepath.Join(xdg, "consult-human", "config.json")
+	b, err := os.ReadFile(path)
+	if err != nil || !strings.Contains(string(b), `"active_provider": "telegram"`) {
+		t.Fatalf("expected a JSON config at %s, got %q: %v", path, b, err)
+	}
+	if err := runConfig([]string{"set", "request_timeout", "40m"}, IO{In: strings.NewReader(""), Out: &bytes.Buffer{}, ErrOut: &bytes.Buffer{}}); err != nil {
+		t.Fatalf("config set: %v", err)
+	}
+	if b, _ := os.ReadFile(path); !strings.Contains(string(b), `"request_timeout": "40m"`) {
+		t.Fatalf("expected config set to update the JSON file, got %q", b)
+	}
+
+	t.Setenv(config.EnvConfigPath, filepath.Join(t.TempDir(), "config.yaml"))
+	if err := runConfig([]string{"init", "--format", "toml"}, IO{In: strings.NewReader(""), Out: &bytes.Buffer{}, ErrOut: &bytes.Buffer{}}); err == nil || !strings.Contains(err.Error(), "does not match") {
+		t.Fatalf("expected a format that contradicts CONSULT_HUMAN_CONFIG to fail, got %v", err)
+	}
+	if err := runConfig([]string{"init", "--format", "ini"}, IO{In: strings.NewReader(""), Out: &bytes.Buffer{}, ErrOut: &bytes.Buffer{}}); err == nil {
+		t.Fatal("expected an unknown format to fail")
+	}
+}
+
+func stubTelegramGetChat(t *testing.T, chats map[string]telegramSetupChat) *[]string {
+	t.Helper()
+	var lookups []string
+	orig := telegramSetupGetChatFn
+	telegramSetupGetChatFn = func(_, _, chat string) (telegramSetupChat, error) {
+		lookups = append(lookups, chat)
+		if c, ok := chats[chat]; ok {
+			return c, nil
+		}
+		return telegramSetupChat{}, errTelegramSetupChatNotFound
+	}
+	t.Cleanup(func() { telegramSetupGetChatFn = orig })
+	return &lookups
+}
+
+func TestRunConfigSetTelegramChatIDResolvesUsername(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	t.Setenv(config.EnvConfigPath, path)
+	stubTelegramGetChat(t, map[string]telegramSetupChat{
+		"@mychannel": {ID: -1001234567890, Type: "channel", Title: "Deploys"},
+	})
+
+	ioNoInput := func(errOut *bytes.Buffer) IO {
+		return IO{In: strings.NewReader(""), Out: &bytes.Buffer{}, ErrOut: errOut}
+	}
+	if err := runConfig([]string{"set", "telegram.chat_id", "@mychannel"}, ioNoInput(&bytes.Buffer{})); err == nil || !strings.Contains(err.Error(), "needs the bot token") {
+		t.Fatalf("expected a lookup without a token to fail helpfully, got %v", err)
+	}
+
+	cfg := config.Default()
+	cfg.Telegram.BotToken = "123:abc"
+	if err := config.Save(cfg); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+	var errOut bytes.Buffer
+	if err := runConfig([]string{"set", "telegram.chat_id", "https://t.me/mychannel"}, ioNoInput(&errOut)); err != nil {
+		t.Fatalf("config set: %v", err)
+	}
+	if !strings.Contains(errOut.String(), `Resolved @mychannel to channel "Deploys", chat ID -1001234567890`) {
+		t.Fatalf("expected the resolution on stderr, got %q", errOut.String())
+	}
+	if got, _ := config.LoadGlobal(); got.Telegram.ChatID != -1001234567890 {
+		t.Fatalf("expected the numeric ID to be stored, got %d", got.Telegram.ChatID)
+	}
+
+	if err := runConfig([]string{"set", "telegram.chat_id", "@unknownchat"}, ioNoInput(&bytes.Buffer{})); err == nil || !strings.Contains(err.Error(), "add the bot to that channel or group") {
+		t.Fatalf("expected an unknown chat to fail helpfully, got %v", err)
+	}
+}
+
+func TestRunConfigSetTelegramChatIDUsesTokenSetAlongside(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	t.Setenv(config.EnvConfigPath, path)
+	cfg := config.Default()
+	cfg.Telegram.BotToken = "OLD"
+	if err := config.Save(cfg); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+	var tokens []string
+	origGetChat, origGetMe := telegramSetupGetChatFn, telegramSetupGetMeFn
+	telegramSetupGetChatFn = func(_, token, chat string) (telegramSetupChat, error) {
+		tokens = append(tokens, token)
+		return telegramSetupChat{ID: -1009876543210, Type: "supergroup", Title: "Team"}, nil
+	}
+	telegramSetupGetMeFn = func(_, _ string) (string, error) { return "consult_bot", nil }
+	t.Cleanup(func() { telegramSetupGetChatFn, telegramSetupGetMeFn = origGetChat, origGetMe })
+
+	err := runConfig([]string{"set", "telegram.chat_id=@team", "telegram.bot_token=NEW"}, IO{In: strings.NewReader(""), Out: &bytes.Buffer{}, ErrOut: &bytes.Buffer{}})
+	if err != nil {
+		t.Fatalf("config set: %v", err)
+	}
+	if len(tokens) != 1 || tokens[0] != "NEW" {
+		t.Fatalf("expected @team to be looked up with the new token, got %v", tokens)
+	}
+	got, _ := config.LoadGlobal()
+	if got.Telegram.ChatID != -1009876543210 || got.Telegram.BotToken != "NEW" {
+		t.Fatalf("expected both keys saved, got chat %d token %q", got.Telegram.ChatID, got.Telegram.BotToken)
+	}
+}
+
+func TestRunConfigSetTelegramChatIDWarnsAboutMissingPrefix(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	t.Setenv(config.EnvConfigPath, path)
+	lookups := stubTelegramGetChat(t, map[string]telegramSetupChat{
+		"-1001234567890": {ID: -1001234567890, Type: "supergroup", Title: "Team"},
+	})
+
+	var errOut bytes.Buffer
+	if err := runConfig([]string{"set", "telegram.chat_id", "-4567"}, IO{In: strings.NewReader(""), Out: &bytes.Buffer{}, ErrOut: &errOut}); err != nil {
+		t.Fatalf("config set: %v", err)
+	}
+	if !strings.Contains(errOut.String(), "note: -4567 is a basic group ID") || len(*lookups) != 0 {
+		t.Fatalf("expected a note without a lookup, got %q and %v", errOut.String(), *lookups)
+	}
+
+	cfg := config.Default()
+	cfg.Telegram.BotToken = "123:abc"
+	if err := config.Save(cfg); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+	errOut.Reset()
+	if err := runConfig([]string{"set", "telegram.chat_id", "1234567890"}, IO{In: strings.NewReader(""), Out: &bytes.Buffer{}, ErrOut: &errOut}); err != nil {
+		t.Fatalf("config set: %v", err)
+	}
+	if !strings.Contains(errOut.String(), `but -1001234567890 is supergroup "Team"`) {
+		t.Fatalf("expected the -100 suggestion, got %q", errOut.String())
+	}
+	if got, _ := config.LoadGlobal(); got.Telegram.ChatID != 1234567890 {
+		t.Fatalf("expected the value to be saved as given, got %d", got.Telegram.ChatID)
+	}
+}
+
+// setupDoctorTest gives config doctor a linked Telegram config, a binary on
+// PATH and one installed skill, and stubs the Telegram calls so the webhook
+// they report can be chosen per test.
+func setupDoctorTest(t *testing.T, webhookURL string) (home string, getMeCalls *int) {
+	t.Helper()
+	home = t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv(config.EnvConfigPath, filepath.Join(t.TempDir(), "config.yaml"))
+	cfg := config.Default()
+	cfg.Telegram.BotToken = "123456:ABC"
+	cfg.Telegram.ChatID = 42
+	if err := config.Save(cfg); err != nil {
+		t.Fatalf("config.Save: %v", err)
+	}
+
+	skillDir := filepath.Join(home, ".claude", "skills", "consult-human")
+	if err := os.MkdirAll(skillDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(skillDir, skillFileName), []byte("skill"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	origLookPath, origWebhook := setupLookPathFn, telegramSetupWebhookURLFn
+	setupLookPathFn = func(string) (string, error) { return "/usr/local/bin/consult-human", nil }
+	telegramSetupWebhookURLFn = func(string, string) (string, error) { return webhookURL, nil }
+	t.Cleanup(func() { setupLookPathFn, telegramSetupWebhookURLFn = origLookPath, origWebhook })
+	stubSetupEnsureShellPath(t)
+
+	getMeCalls = new(int)
+	stubTelegramSetupGetMe(t, func(string, string) (string, error) {
+		*getMeCalls++
+		return "my_bot", nil
+	})
+	return home, getMeCalls
+}
+
+func TestRunConfigDoctorReportsProblems(t *testing.T) {
+	home, _ := setupDoctorTest(t, "https://example.com/hook")
+	broken := filepath.Join(home, ".agents", "skills", "consult-human")
+	if err := os.MkdirAll(broken, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(filepath.Join(home, "gone", skillFileName), filepath.Join(broken, skillFileName)); err != nil {
+		t.Fatal(err)
+	}
+
+	var out bytes.Buffer
+	err := Execute([]string{"doctor"}, IO{In: strings.NewReader(""), Out: &out, ErrOut: &bytes.Buffer{}})
+	if err == nil || !strings.Contains(err.Error(), "found 2 problem(s)") {
+		t.Fatalf("expected doctor to report 2 problems, got %v\n%s", err, out.String())
+	}
+	for _, want := range []string{
+		"✓ Bot token is valid: @my_bot",
+		"✗ a webhook (https://example.com/hook) blocks polling for replies",
+		"telegram.auto_delete_webhook true",
+		"is a broken link to",
+		"✓ Skill installed: " + filepath.Join(home, ".claude", "skills", "consult-human", skillFileName),
+	} {
+		if !strings.Contains(out.String(), want) {
+			t.Fatalf("expected doctor output to contain %q, got:\n%s", want, out.String())
+		}
+	}
+}
+
+func TestRunConfigDoctorOffline(t *testing.T) {
+	_, getMeCalls := setupDoctorTest(t, "https://example.com/hook")
+
+	var out bytes.Buffer
+	if err := runConfig([]string{"doctor", "--offline"}, IO{In: strings.NewReader(""), Out: &out, ErrOut: &bytes.Buffer{}}); err != nil {
+		t.Fatalf("expected an offline doctor run to pass, got %v\n%s", err, out.String())
+	}
+	if *getMeCalls != 0 {
+		t.Fatalf("expected --offline to skip getMe, got %d calls", *getMeCalls)
+	}
+	if !strings.Contains(out.String(), "No problems found") {
+		t.Fatalf("expected a clean report, got:\n%s", out.String())
+	}
+}
+
+func TestRunConfigDoctorUnlinkedChat(t *testing.T) {
+	setupDoctorTest(t, "")
+	cfg, err := config.LoadGlobal()
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg.Telegram.ChatID = 0
+	if err := config.Save(cfg); err != nil {
+		t.Fatal(err)
+	}
+
+	var out bytes.Buffer
+	if err := runConfig([]string{"doctor"}, IO{In: strings.NewReader(""), Out: &out, ErrOut: &bytes.Buffer{}}); err == nil {
+		t.Fatalf("expected an unlinked chat to fail doctor, got:\n%s", out.String())
+	}
+	if !strings.Contains(out.String(), "consult-human setup --provider telegram --link-chat") {
+		t.Fatalf("expected a --link-chat suggestion, got:\n%s", out.String())
+	}
+}
+
+func stubConfigImportTerminal(t *testing.T, interactive bool) {
+	t.Helper()
+	orig := configImportIsTerminalFn
+	configImportIsTerminalFn = func(io.Reader) bool { return interactive }
+	t.Cleanup(func() { configImportIsTerminalFn = orig })
+}
+
+func TestRunConfigExportImportRedacted(t *testing.T) {
+	t.Setenv(config.EnvConfigPath, filepath.Join(t.TempDir(), "config.yaml"))
+	ioNoInput := IO{In: strings.NewReader(""), Out: &bytes.Buffer{}, ErrOut: &bytes.Buffer{}}
+	if err := runConfig([]string{"set", "--skip-verify", "telegram.bot_token=123456:ABC", "request_timeout=30m"}, ioNoInput); err != nil {
+		t.Fatalf("config set: %v", err)
+	}
+	var exported bytes.Buffer
+	if err := runConfig([]string{"export"}, IO{In: strings.NewReader(""), Out: &exported, ErrOut: &bytes.Buffer{}}); err != nil {
+		t.Fatalf("config export: %v", err)
+	}
+	if strings.Contains(exported.String(), "123456:ABC") {
+		t.Fatalf("expected the bot token to be redacted, got:\n%s", exported.String())
+	}
+	file := filepath.Join(t.TempDir(), "shared.yaml")
+	if err := os.WriteFile(file, exported.Bytes(), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	// A teammate's machine.
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	t.Setenv(config.EnvConfigPath, path)
+	stubConfigImportTerminal(t, false)
+	if err := runConfig([]string{"import", file}, ioNoInput); err == nil || !strings.Contains(err.Error(), "redacted values for telegram.bot_token") {
+		t.Fatalf("expected a non-interactive import with placeholders to fail, got %v", err)
+	}
+	if _, err := os.Stat(path); !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("expected a failed import to write nothing, stat err %v", err)
+	}
+
+	stubConfigImportTerminal(t, true)
+	var errOut bytes.Buffer
+	if err := runConfig([]string{"import", file}, IO{In: strings.NewReader("654321:XYZ\n"), Out: &bytes.Buffer{}, ErrOut: &errOut}); err != nil {
+		t.Fatalf("config import: %v\n%s", err, errOut.String())
+	}
+	if !strings.Contains(errOut.String(), "Value for telegram.bot_token") {
+		t.Fatalf("expected a prompt for the bot token, got %q", errOut.String())
+	}
+	cfg, err := config.LoadGlobal()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Telegram.BotToken != "654321:XYZ" || cfg.RequestTimeout != "30m" {
+		t.Fatalf("expected the import to apply, got token %q timeout %q", cfg.Telegram.BotToken, cfg.RequestTimeout)
+	}
+}
+
+func TestRunConfigExportImportWithSecrets(t *testing.T) {
+	t.Setenv(config.EnvConfigPath, filepath.Join(t.TempDir(), "config.yaml"))
+	ioNoInput := IO{In: strings.NewReader(""), Out: &bytes.Buffer{}, ErrOut: &bytes.Buffer{}}
+	if err := runConfig([]string{"set", "--skip-verify", "telegram.bot_token=123456:ABC", "quiet_hours.start=23:00", "quiet_hours.end=07:00"}, ioNoInput); err != nil {
+		t.Fatalf("config set: %v", err)
+	}
+	want, err := config.LoadGlobal()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var exported bytes.Buffer
+	if err := runConfig([]string{"export", "--include-secrets"}, IO{In: strings.NewReader(""), Out: &exported, ErrOut: &bytes.Buffer{}}); err != nil {
+		t.Fatalf("config export: %v", err)
+	}
+	file := filepath.Join(t.TempDir(), "shared.yaml")
+	if err := os.WriteFile(file, exported.Bytes(), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Setenv(config.EnvConfigPath, filepath.Join(t.TempDir(), "config.yaml"))
+	stubConfigImportTerminal(t, false)
+	if err := runConfig([]string{"import", file}, ioNoInput); err != nil {
+		t.Fatalf("config import: %v", err)
+	}
+	got, err := config.LoadGlobal()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Telegram.BotToken != want.Telegram.BotToken || got.QuietHours != want.QuietHours {
+		t.Fatalf("round trip changed the config\nwant %#v\ngot  %#v", want, got)
+	}
+}
+
+func TestRunConfigImportRejectsUnknownKeys(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	t.Setenv(config.EnvConfigPath, path)
+	stubConfigImportTerminal(t, false)
+	file := filepath.Join(t.TempDir(), "shared.yaml")
+	if err := os.WriteFile(file, []byte("request_timeout: 30m\ntelegram:\n  colour: blue\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	err := runConfig([]string{"import", file}, IO{In: strings.NewReader(""), Out: &bytes.Buffer{}, ErrOut: &bytes.Buffer{}})
+	if err == nil || !strings.Contains(err.Error(), "telegram.colour") {
+		t.Fatalf("expected the unknown key to be named, got %v", err)
+	}
+	if _, err := os.Stat(path); !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("expected a rejected import to write nothing, stat err %v", err)
+	}
+}
+
+func TestRunConfigValidateStrict(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	t.Setenv(config.EnvConfigPath, path)
+	t.Setenv(config.EnvStrictConfig, "")
+	if err := os.WriteFile(path, []byte("request_timeout: 30m\nslack:\n  chanel: C123\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	var out bytes.Buffer
+	if err := runConfig([]string{"validate"}, IO{In: strings.NewReader(""), Out: &out, ErrOut: &bytes.Buffer{}}); err != nil {
+		t.Fatalf("expected unknown keys to only warn, got %v", err)
+	}
+	if !strings.Contains(out.String(), "is valid") {
+		t.Fatalf("expected a valid report, got %q", out.String())
+	}
+
+	err := runConfig([]string{"validate", "--strict"}, IO{In: strings.NewReader(""), Out: &bytes.Buffer{}, ErrOut: &bytes.Buffer{}})
+	if err == nil || !strings.Contains(err.Error(), "slack.chanel (did you mean slack.channel?)") {
+		t.Fatalf("expected --strict to fail on the unknown key, got %v", err)
+	}
+}
@@ -0,0 +1,226 @@
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/AlhasanIQ/consult-human/config"
+	"github.com/AlhasanIQ/consult-human/contract"
+	"github.com/AlhasanIQ/consult-human/filelock"
+	"github.com/AlhasanIQ/consult-human/provider"
+)
+
+const (
+	historyOutcomeAnswered  = "answered"
+	historyOutcomeTimeout   = "timeout"
+	historyOutcomeCanceled  = "canceled"
+	historyOutcomeError     = "error"
+	historyOutcomeDismissed = "dismissed"
+)
+
+type historyRecord struct {
+	RecordedAt time.Time           `json:"recorded_at"`
+	Outcome    string              `json:"outcome"`
+	Provider   string              `json:"provider"`
+	DurationMS int64               `json:"duration_ms"`
+	Request    contract.AskRequest `json:"request"`
+	Result     *contract.AskResult `json:"result,omitempty"`
+	Error      string              `json:"error,omitempty"`
+}
+
+func runHistory(args []string, io IO) error {
+	fs := flag.NewFlagSet("history", flag.ContinueOnError)
+	fs.SetOutput(io.ErrOut)
+
+	var limit int
+	var sinceRaw string
+	var output string
+	fs.IntVar(&limit, "limit", 20, "Maximum number of records to show (0 shows all)")
+	fs.StringVar(&sinceRaw, "since", "", "Only show records newer than this duration (e.g. 24h)")
+	fs.StringVar(&output, "output", "text", "Output format (text|json)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 0 {
+		return fmt.Errorf("usage: consult-human history [--limit N] [--since 24h] [--output text|json]")
+	}
+	if limit < 0 {
+		return fmt.Errorf("--limit must be >= 0")
+	}
+	output = strings.ToLower(strings.TrimSpace(output))
+	if output != "text" && output != "json" {
+		return fmt.Errorf("--output must be text or json")
+	}
+
+	var since time.Time
+	if strings.TrimSpace(sinceRaw) != "" {
+		d, err := time.ParseDuration(strings.TrimSpace(sinceRaw))
+		if err != nil {
+			return fmt.Errorf("invalid --since: %w", err)
+		}
+		if d <= 0 {
+			return fmt.Errorf("--since must be > 0")
+		}
+		since = time.Now().Add(-d)
+	}
+
+	path, err := config.DefaultHistoryPath()
+	if err != nil {
+		return err
+	}
+	records, err := readHistory(path)
+	if err != nil {
+		return err
+	}
+
+	selected := make([]historyRecord, 0, len(records))
+	for i := len(records) - 1; i >= 0; i-- {
+		if !since.IsZero() && records[i].RecordedAt.Before(since) {
+			continue
+		}
+		selected = append(selected, records[i])
+		if limit > 0 && len(selected) == limit {
+			break
+		}
+	}
+
+	if output == "json" {
+		enc := json.NewEncoder(io.Out)
+		enc.SetEscapeHTML(false)
+		enc.SetIndent("", "  ")
+		return enc.Encode(selected)
+	}
+
+	if len(selected) == 0 {
+		fmt.Fprintln(io.ErrOut, "No history records.")
+		return nil
+	}
+	for _, rec := range selected {
+		fmt.Fprintln(io.Out, formatHistoryLine(rec))
+	}
+	return nil
+}
+
+func formatHistoryLine(rec historyRecord) string {
+	answer := rec.Error
+	if rec.Result != nil {
+		answer = rec.Result.Text
+	}
+	return fmt.Sprintf(
+		"%s  %-8s  %s  %s -> %s",
+		rec.RecordedAt.Local().Format("2006-01-02 15:04:05"),
+		rec.Outcome,
+		rec.Request.RequestID,
+		historyExcerpt(rec.Request.Question),
+		historyExcerpt(answer),
+	)
+}
+
+func historyExcerpt(s string) string {
+	s = strings.Join(strings.Fields(s), " ")
+	runes := []rune(s)
+	if len(runes) > 60 {
+		return string(runes[:59]) + "…"
+	}
+	return s
+}
+
+// recordAskHistory appends one ask outcome to the history log. Failures are
+// reported as warnings and never fail the ask itself.
+func recordAskHistory(errOut io.Writer, req contract.AskRequest, providerName string, started time.Time, result *contract.AskResult, askErr error) {
+	rec := historyRecord{
+		RecordedAt: time.Now().UTC(),
+		Outcome:    historyOutcome(askErr),
+		Provider:   providerName,
+		DurationMS: time.Since(started).Milliseconds(),
+		Request:    req,
+		Result:     result,
+	}
+	if askErr != nil {
+		rec.Error = askErr.Error()
+	}
+
+	path, err := config.DefaultHistoryPath()
+	if err == nil {
+		err = appendHistory(path, rec)
+	}
+	if err != nil {
+		fmt.Fprintf(errOut, "warning: could not write ask history: %v\n", err)
+	}
+}
+
+func historyOutcome(err error) string {
+	switch {
+	case err == nil:
+		return historyOutcomeAnswered
+	case errors.Is(err, context.DeadlineExceeded):
+		return historyOutcomeTimeout
+	case errors.Is(err, provider.ErrCancelledByHuman):
+		return historyOutcomeDismissed
+	case errors.Is(err, context.Canceled):
+		return historyOutcomeCanceled
+	default:
+		return historyOutcomeError
+	}
+}
+
+func appendHistory(path string, rec historyRecord) error {
+	line, err := json.Marshal(rec)
+	if err != nil {
+		return err
+	}
+	return withHistoryLock(path, func() error {
+		f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+		if err != nil {
+			return err
+		}
+		if _, err := f.Write(append(line, '\n')); err != nil {
+			_ = f.Close()
+			return err
+		}
+		return f.Close()
+	})
+}
+
+func readHistory(path string) ([]historyRecord, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	defer f.Close()
+
+	var records []historyRecord
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 4*1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		var rec historyRecord
+		if err := json.Unmarshal([]byte(line), &rec); err != nil {
+			// Skip a torn or hand-edited line rather than hiding the rest.
+			continue
+		}
+		records = append(records, rec)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return records, nil
+}
+
+func withHistoryLock(path string, fn func() error) error {
+	return filelock.Lock{Path: path + ".lock", Name: "history"}.With(fn)
+}
@@ -0,0 +1,103 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/AlhasanIQ/consult-human/config"
+	"github.com/AlhasanIQ/consult-human/contract"
+)
+
+func TestRunAskRecordsAnsweredHistory(t *testing.T) {
+	t.Setenv(envAskQuiet, "1")
+	stubAskProvider(t, &fakeAskProvider{reply: contract.Reply{Text: "yes", Raw: "yes"}})
+
+	if err := runAsk([]string{"Ship it?"}, IO{In: strings.NewReader(""), Out: &bytes.Buffer{}, ErrOut: &bytes.Buffer{}}); err != nil {
+		t.Fatalf("runAsk returned error: %v", err)
+	}
+
+	path, err := config.DefaultHistoryPath()
+	if err != nil {
+		t.Fatalf("DefaultHistoryPath: %v", err)
+	}
+	records, err := readHistory(path)
+	if err != nil {
+		t.Fatalf("readHistory: %v", err)
+	}
+	if len(records) != 1 {
+		t.Fatalf("expected one record, got %#v", records)
+	}
+	rec := records[0]
+	if rec.Outcome != historyOutcomeAnswered || rec.Provider != "fake" || rec.Result == nil || rec.Result.Text != "yes" {
+		t.Fatalf("unexpected answered record: %#v", rec)
+	}
+}
+
+func TestRunHistoryFiltersNewestFirst(t *testing.T) {
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+	path, err := config.DefaultHistoryPath()
+	if err != nil {
+		t.Fatalf("DefaultHistoryPath: %v", err)
+	}
+
+	now := time.Now().UTC()
+	for i, age := range []time.Duration{48 * time.Hour, 2 * time.Hour, time.Hour} {
+		rec := historyRecord{
+			RecordedAt: now.Add(-age),
+			Outcome:    historyOutcomeAnswered,
+			Request:    contract.AskRequest{RequestID: []string{"old", "mid", "new"}[i], Question: "q"},
+		}
+		if err := appendHistory(path, rec); err != nil {
+			t.Fatalf("appendHistory: %v", err)
+		}
+	}
+
+	var out bytes.Buffer
+	if err := runHistory([]string{"--since", "24h", "--output", "json"}, IO{In: strings.NewReader(""), Out: &out, ErrOut: &bytes.Buffer{}}); err != nil {
+		t.Fatalf("runHistory returned error: %v", err)
+	}
+	var got []historyRecord
+	if err := json.Unmarshal(out.Bytes(), &got); err != nil {
+		t.Fatalf("decode history output: %v (%q)", err, out.String())
+	}
+	if len(got) != 2 || got[0].Request.RequestID != "new" || got[1].Request.RequestID != "mid" {
+		t.Fatalf("unexpected filtered history: %#v", got)
+	}
+
+	out.Reset()
+	if err := runHistory([]string{"--limit", "1"}, IO{In: strings.NewReader(""), Out: &out, ErrOut: &bytes.Buffer{}}); err != nil {
+		t.Fatalf("runHistory returned error: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 1 || !strings.Contains(lines[0], "new") {
+		t.Fatalf("unexpected limited history: %q", out.String())
+	}
+}
+
+func TestRunAskRecordsTimeoutHistory(t *testing.T) {
+	t.Setenv(envAskQuiet, "1")
+	stubAskProvider(t, &fakeAskProvider{recvErr: context.DeadlineExceeded})
+
+	if err := runAsk([]string{"Roll back?"}, IO{In: strings.NewReader(""), Out: &bytes.Buffer{}, ErrOut: &bytes.Buffer{}}); err == nil {
+		t.Fatalf("expected runAsk to fail on receive timeout")
+	}
+
+	path, err := config.DefaultHistoryPath()
+	if err != nil {
+		t.Fatalf("DefaultHistoryPath: %v", err)
+	}
+	records, err := readHistory(path)
+	if err != nil {
+		t.Fatalf("readHistory: %v", err)
+	}
+	if len(records) != 1 {
+		t.Fatalf("expected one record, got %#v", records)
+	}
+	if records[0].Outcome != historyOutcomeTimeout || records[0].Request.Question != "Roll back?" || records[0].Result != nil {
+		t.Fatalf("unexpected timeout record: %#v", records[0])
+	}
+}
@@ -0,0 +1,70 @@
+package cmd
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"syscall"
+
+	"github.com/AlhasanIQ/consult-human/config"
+	"github.com/AlhasanIQ/consult-human/contract"
+	"github.com/AlhasanIQ/consult-human/provider"
+)
+
+func runNotify(args []string, io IO) error {
+	fs := flag.NewFlagSet("notify", flag.ContinueOnError)
+	fs.SetOutput(io.ErrOut)
+
+	var attachments stringSliceFlag
+	var silent bool
+	var providerOverride string
+	fs.Var(&attachments, "attach", "File to attach. Repeatable.")
+	fs.BoolVar(&silent, "silent", false, "Deliver without a notification sound")
+	fs.StringVar(&providerOverride, "provider", "", "Override configured provider (telegram|console)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	message := strings.TrimSpace(strings.Join(fs.Args(), " "))
+	if message == "" && len(attachments) == 0 {
+		return fmt.Errorf("missing message")
+	}
+	for _, path := range attachments {
+		st, err := os.Stat(path)
+		if err != nil {
+			return fmt.Errorf("invalid --attach: %w", err)
+		}
+		if st.IsDir() {
+			return fmt.Errorf("invalid --attach: %s is a directory", path)
+		}
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+	name := strings.TrimSpace(providerOverride)
+	if name == "" {
+		name = cfg.ActiveProvider
+	}
+	p, err := askProviderFn(cfg, name)
+	if err != nil {
+		return err
+	}
+	defer p.Close()
+
+	notifier, ok := p.(provider.Notifier)
+	if !ok {
+		return fmt.Errorf("provider %s does not support notifications", p.Name())
+	}
+
+	ctx, stop := askSignalContextFn(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	return notifier.Notify(ctx, contract.Notification{
+		Text:        message,
+		Attachments: attachments,
+		Silent:      silent,
+	})
+}
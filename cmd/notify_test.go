@@ -0,0 +1,88 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/AlhasanIQ/consult-human/config"
+	"github.com/AlhasanIQ/consult-human/contract"
+	"github.com/AlhasanIQ/consult-human/provider"
+)
+
+type fakeNotifyProvider struct {
+	fakeAskProvider
+	notified []contract.Notification
+}
+
+func (f *fakeNotifyProvider) Notify(_ context.Context, n contract.Notification) error {
+	f.notified = append(f.notified, n)
+	return nil
+}
+
+func stubNotifyProvider(t *testing.T, p provider.Provider) {
+	t.Helper()
+	t.Setenv(config.EnvConfigPath, filepath.Join(t.TempDir(), "config.yaml"))
+	orig := askProviderFn
+	askProviderFn = func(config.Config, string) (provider.Provider, error) { return p, nil }
+	t.Cleanup(func() { askProviderFn = orig })
+}
+
+func TestRunNotifySendsMessageAndAttachments(t *testing.T) {
+	fake := &fakeNotifyProvider{}
+	stubNotifyProvider(t, fake)
+
+	attachPath := filepath.Join(t.TempDir(), "report.txt")
+	if err := os.WriteFile(attachPath, []byte("ok"), 0o600); err != nil {
+		t.Fatalf("write attachment: %v", err)
+	}
+
+	var out, errOut bytes.Buffer
+	err := Execute([]string{"notify", "--silent", "--attach", attachPath, "Deploy", "finished"}, IO{In: strings.NewReader(""), Out: &out, ErrOut: &errOut})
+	if err != nil {
+		t.Fatalf("notify returned error: %v", err)
+	}
+	want := []contract.Notification{{Text: "Deploy finished", Attachments: []string{attachPath}, Silent: true}}
+	if !reflect.DeepEqual(fake.notified, want) {
+		t.Fatalf("unexpected notifications: %#v", fake.notified)
+	}
+	if out.Len() != 0 {
+		t.Fatalf("expected no stdout output, got %q", out.String())
+	}
+}
+
+func TestRunNotifyValidatesInput(t *testing.T) {
+	fake := &fakeNotifyProvider{}
+	stubNotifyProvider(t, fake)
+
+	cases := map[string][]string{
+		"missing message":  {"notify"},
+		"missing file":     {"notify", "--attach", filepath.Join(t.TempDir(), "nope.txt"), "hi"},
+		"directory attach": {"notify", "--attach", t.TempDir(), "hi"},
+	}
+	for name, args := range cases {
+		t.Run(name, func(t *testing.T) {
+			var out, errOut bytes.Buffer
+			if err := Execute(args, IO{In: strings.NewReader(""), Out: &out, ErrOut: &errOut}); err == nil {
+				t.Fatalf("expected error")
+			}
+		})
+	}
+	if len(fake.notified) != 0 {
+		t.Fatalf("expected nothing sent, got %#v", fake.notified)
+	}
+}
+
+func TestRunNotifyRejectsProviderWithoutNotifications(t *testing.T) {
+	stubNotifyProvider(t, &fakeAskProvider{name: "telegram"})
+
+	var out, errOut bytes.Buffer
+	err := Execute([]string{"notify", "hi"}, IO{In: strings.NewReader(""), Out: &out, ErrOut: &errOut})
+	if err == nil || !strings.Contains(err.Error(), "does not support notifications") {
+		t.Fatalf("expected unsupported provider error, got %v", err)
+	}
+}
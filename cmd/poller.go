@@ -0,0 +1,51 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"syscall"
+
+	"github.com/AlhasanIQ/consult-human/config"
+	"github.com/AlhasanIQ/consult-human/provider"
+)
+
+const pollerSubcommandRun = "run"
+
+// runPollerFn runs the poller daemon; tests replace it to avoid calling Telegram.
+var runPollerFn = provider.RunTelegramPoller
+
+func runPoller(args []string, io IO) error {
+	if len(args) == 0 || strings.TrimSpace(args[0]) != pollerSubcommandRun {
+		printPollerUsage(io.ErrOut)
+		if len(args) == 0 {
+			return fmt.Errorf("missing poller subcommand")
+		}
+		return fmt.Errorf("unknown poller subcommand %q", args[0])
+	}
+	if len(args) > 1 {
+		printPollerUsage(io.ErrOut)
+		return fmt.Errorf("poller run takes no arguments")
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+
+	ctx, stopSignals := askSignalContextFn(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stopSignals()
+
+	if err := runPollerFn(ctx, cfg, io.ErrOut); err != nil {
+		return err
+	}
+	fmt.Fprintln(io.ErrOut, "telegram poller stopped")
+	return nil
+}
+
+func printPollerUsage(w io.Writer) {
+	fmt.Fprintln(w, "Usage:")
+	fmt.Fprintln(w, "  consult-human poller run")
+}
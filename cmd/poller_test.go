@@ -0,0 +1,54 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/AlhasanIQ/consult-human/config"
+	"github.com/AlhasanIQ/consult-human/provider"
+)
+
+func TestRunPollerRunStartsDaemon(t *testing.T) {
+	t.Setenv(config.EnvConfigPath, filepath.Join(t.TempDir(), "config.yaml"))
+	called := false
+	orig := runPollerFn
+	runPollerFn = func(context.Context, config.Config, io.Writer) error {
+		called = true
+		return nil
+	}
+	t.Cleanup(func() { runPollerFn = orig })
+
+	var out, errOut bytes.Buffer
+	if err := Execute([]string{"poller", "run"}, IO{In: strings.NewReader(""), Out: &out, ErrOut: &errOut}); err != nil {
+		t.Fatalf("poller run returned error: %v", err)
+	}
+	if !called {
+		t.Fatalf("expected the poller to run")
+	}
+	if !strings.Contains(errOut.String(), "telegram poller stopped") {
+		t.Fatalf("unexpected output: %q", errOut.String())
+	}
+}
+
+func TestRunPollerReportsRunningDaemon(t *testing.T) {
+	t.Setenv(config.EnvConfigPath, filepath.Join(t.TempDir(), "config.yaml"))
+	orig := runPollerFn
+	runPollerFn = func(context.Context, config.Config, io.Writer) error {
+		return fmt.Errorf("%w (lock /tmp/telegram-poller.lock)", provider.ErrTelegramPollerRunning)
+	}
+	t.Cleanup(func() { runPollerFn = orig })
+
+	var out, errOut bytes.Buffer
+	err := Execute([]string{"poller", "run"}, IO{In: strings.NewReader(""), Out: &out, ErrOut: &errOut})
+	if err == nil || !strings.Contains(err.Error(), "already running") {
+		t.Fatalf("expected already running error, got %v", err)
+	}
+	if err := Execute([]string{"poller", "start"}, IO{In: strings.NewReader(""), Out: &out, ErrOut: &errOut}); err == nil {
+		t.Fatalf("expected error for unknown subcommand")
+	}
+}
@@ -0,0 +1,53 @@
+package cmd
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"strings"
+
+	"github.com/AlhasanIQ/consult-human/config"
+	"github.com/AlhasanIQ/consult-human/provider"
+)
+
+func runReply(args []string, io IO) error {
+	fs := flag.NewFlagSet("reply", flag.ContinueOnError)
+	fs.SetOutput(io.ErrOut)
+
+	var notify bool
+	fs.BoolVar(&notify, "notify", true, "Post a silent \"answered locally\" note under the question in the chat")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() < 2 {
+		return fmt.Errorf("usage: consult-human reply [--notify=false] <request-id> <answer>")
+	}
+	requestID := strings.TrimSpace(fs.Arg(0))
+	answer := strings.TrimSpace(strings.Join(fs.Args()[1:], " "))
+	if requestID == "" {
+		return fmt.Errorf("missing request id")
+	}
+	if answer == "" {
+		return fmt.Errorf("missing answer")
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+	p, err := askProviderFn(cfg, "telegram")
+	if err != nil {
+		return err
+	}
+	defer p.Close()
+
+	replier, ok := p.(provider.LocalReplier)
+	if !ok {
+		return fmt.Errorf("provider %s does not support local replies", p.Name())
+	}
+	if err := replier.ReplyLocally(context.Background(), requestID, answer, notify); err != nil {
+		return err
+	}
+	fmt.Fprintf(io.Out, "answered %s locally\n", requestID)
+	return nil
+}
@@ -0,0 +1,53 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/AlhasanIQ/consult-human/config"
+	"github.com/AlhasanIQ/consult-human/provider"
+)
+
+type fakeLocalReplyProvider struct {
+	fakeAskProvider
+	requestID string
+	text      string
+	notify    bool
+}
+
+func (f *fakeLocalReplyProvider) ReplyLocally(_ context.Context, requestID, text string, notify bool) error {
+	f.requestID, f.text, f.notify = requestID, text, notify
+	return nil
+}
+
+func TestRunReplyPassesAnswerToProvider(t *testing.T) {
+	fake := &fakeLocalReplyProvider{}
+	stubAskProvider(t, &fake.fakeAskProvider)
+	orig := askProviderFn
+	askProviderFn = func(config.Config, string) (provider.Provider, error) { return fake, nil }
+	t.Cleanup(func() { askProviderFn = orig })
+
+	var out, errOut bytes.Buffer
+	err := Execute([]string{"reply", "--notify=false", "req-1", "yes,", "ship", "it"}, IO{In: strings.NewReader(""), Out: &out, ErrOut: &errOut})
+	if err != nil {
+		t.Fatalf("reply returned error: %v", err)
+	}
+	if fake.requestID != "req-1" || fake.text != "yes, ship it" || fake.notify {
+		t.Fatalf("unexpected local reply: %#v", fake)
+	}
+	if !strings.Contains(out.String(), "answered req-1 locally") {
+		t.Fatalf("unexpected output: %q", out.String())
+	}
+}
+
+func TestRunReplyRejectsProviderWithoutLocalReplies(t *testing.T) {
+	stubAskProvider(t, &fakeAskProvider{name: "telegram"})
+
+	var out, errOut bytes.Buffer
+	err := Execute([]string{"reply", "req-1", "yes"}, IO{In: strings.NewReader(""), Out: &out, ErrOut: &errOut})
+	if err == nil || !strings.Contains(err.Error(), "does not support local replies") {
+		t.Fatalf("expected unsupported provider error, got %v", err)
+	}
+}
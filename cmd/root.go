@@ -1,11 +1,40 @@
 package cmd
 
 import (
+	"errors"
 	"fmt"
 	"io"
+	"os"
 	"strings"
+
+	"github.com/AlhasanIQ/consult-human/config"
 )
 
+// ExitCodeInterrupted is the process exit code when ask is stopped by a signal.
+const ExitCodeInterrupted = 130
+
+// ExitCodeCancelled is the process exit code when the human dismisses the
+// question with /cancel instead of answering it.
+const ExitCodeCancelled = 3
+
+type exitError struct {
+	code int
+	err  error
+}
+
+func (e *exitError) Error() string { return e.err.Error() }
+
+func (e *exitError) Unwrap() error { return e.err }
+
+// ExitCode maps an Execute error to the process exit code.
+func ExitCode(err error) int {
+	var exitErr *exitError
+	if errors.As(err, &exitErr) {
+		return exitErr.code
+	}
+	return 1
+}
+
 type IO struct {
 	In     io.Reader
 	Out    io.Writer
@@ -17,6 +46,10 @@ func Execute(args []string, io IO) error {
 		return fmt.Errorf("invalid IO")
 	}
 
+	args, err := applyProfileFlag(args)
+	if err != nil {
+		return err
+	}
 	if len(args) == 0 {
 		printRootUsage(io.ErrOut)
 		return fmt.Errorf("missing command")
@@ -27,6 +60,16 @@ func Execute(args []string, io IO) error {
 		return runAsk(args[1:], io)
 	case "config":
 		return runConfig(args[1:], io)
+	case "history":
+		return runHistory(args[1:], io)
+	case "notify":
+		return runNotify(args[1:], io)
+	case "reply":
+		return runReply(args[1:], io)
+	case "poller":
+		return runPoller(args[1:], io)
+	case "serve":
+		return runServe(args[1:], io)
 	case "storage", "cache":
 		return runStorage(args[1:], io)
 	case "skill":
@@ -35,6 +78,8 @@ func Execute(args []string, io IO) error {
 		return runSkill(append([]string{skillSubcommandInstall}, args[1:]...), io)
 	case "setup":
 		return runSetup(args[1:], io)
+	case "doctor":
+		return runConfig(append([]string{"doctor"}, args[1:]...), io)
 	case "help", "--help", "-h":
 		printRootUsage(io.Out)
 		return nil
@@ -48,9 +93,52 @@ func printRootUsage(w io.Writer) {
 	fmt.Fprintln(w, "consult-human: relay AI-agent questions to a human via messaging apps")
 	fmt.Fprintln(w, "")
 	fmt.Fprintln(w, "Usage:")
+	fmt.Fprintln(w, "  consult-human [--profile NAME] <command> ...")
 	fmt.Fprintln(w, "  consult-human ask [flags] <question>")
-	fmt.Fprintln(w, "  consult-human config <path|show|init|set|reset>")
+	fmt.Fprintln(w, "  consult-human config <path|show|init|edit|set|reset|validate|doctor|export|import>")
+	fmt.Fprintln(w, "  consult-human doctor [--offline]")
+	fmt.Fprintln(w, "  consult-human history [--limit N] [--since 24h] [--output text|json]")
+	fmt.Fprintln(w, "  consult-human notify [--attach FILE] [--silent] <message>")
+	fmt.Fprintln(w, "  consult-human reply [--notify=false] <request-id> <answer>")
+	fmt.Fprintln(w, "  consult-human poller run")
+	fmt.Fprintln(w, "  consult-human serve telegram-webhook --url URL [--listen :8443]")
 	fmt.Fprintln(w, "  consult-human storage <path|clear>")
-	fmt.Fprintln(w, "  consult-human skill <install>")
+	fmt.Fprintln(w, "  consult-human skill <install|status|update>")
 	fmt.Fprintln(w, "  consult-human setup [flags]")
 }
+
+// applyProfileFlag takes --profile NAME out of the leading flags of args and
+// selects that profile for the rest of the process through
+// CONSULT_HUMAN_PROFILE. Flags after the first other argument are left alone.
+func applyProfileFlag(args []string) ([]string, error) {
+	out := make([]string, 0, len(args))
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if !strings.HasPrefix(arg, "-") {
+			out = append(out, args[i:]...)
+			break
+		}
+		var name string
+		switch {
+		case arg == "--profile" || arg == "-profile":
+			if i+1 >= len(args) {
+				return nil, fmt.Errorf("--profile needs a profile name")
+			}
+			i++
+			name = args[i]
+		case strings.HasPrefix(arg, "--profile="):
+			name = strings.TrimPrefix(arg, "--profile=")
+		default:
+			out = append(out, arg)
+			continue
+		}
+		name = strings.TrimSpace(name)
+		if !config.IsValidProfileName(name) {
+			return nil, fmt.Errorf("profile name must be letters, digits, '-' or '_', got %q", name)
+		}
+		if err := os.Setenv(config.EnvProfile, name); err != nil {
+			return nil, err
+		}
+	}
+	return out, nil
+}
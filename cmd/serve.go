@@ -0,0 +1,80 @@
+package cmd
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"syscall"
+
+	"github.com/AlhasanIQ/consult-human/config"
+	"github.com/AlhasanIQ/consult-human/provider"
+)
+
+const serveTargetTelegramWebhook = "telegram-webhook"
+
+// serveWebhookFn runs the webhook server; tests replace it to avoid
+// listening and calling Telegram.
+var serveWebhookFn = func(ctx context.Context, cfg config.Config, opts provider.TelegramWebhookOptions) error {
+	srv, err := provider.NewTelegramWebhookServer(cfg, opts)
+	if err != nil {
+		return err
+	}
+	return srv.Serve(ctx)
+}
+
+func runServe(args []string, io IO) error {
+	if len(args) == 0 || strings.TrimSpace(args[0]) != serveTargetTelegramWebhook {
+		printServeUsage(io.ErrOut)
+		if len(args) == 0 {
+			return fmt.Errorf("missing serve target")
+		}
+		return fmt.Errorf("unknown serve target %q", args[0])
+	}
+
+	fs := flag.NewFlagSet("serve "+serveTargetTelegramWebhook, flag.ContinueOnError)
+	fs.SetOutput(io.ErrOut)
+
+	var listen, hookURL, secret string
+	fs.StringVar(&listen, "listen", ":8443", "Local address to listen on")
+	fs.StringVar(&hookURL, "url", "", "Public https URL Telegram posts updates to (required)")
+	fs.StringVar(&secret, "secret", "", "Webhook secret token (default telegram.webhook_secret, or random)")
+	if err := fs.Parse(args[1:]); err != nil {
+		return err
+	}
+	if strings.TrimSpace(hookURL) == "" {
+		return fmt.Errorf("--url is required")
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+	if secret == "" {
+		secret = cfg.Telegram.WebhookSecret
+	}
+	if cfg.Telegram.ReceiveMode != config.TelegramReceiveModeWebhook {
+		fmt.Fprintln(io.ErrOut, "warning: telegram.receive_mode is not webhook; ask will refuse to poll while the webhook is set. Run: consult-human config set telegram.receive_mode webhook")
+	}
+
+	ctx, stopSignals := askSignalContextFn(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stopSignals()
+
+	fmt.Fprintf(io.ErrOut, "serving telegram webhook on %s for %s (health: %s)\n", listen, hookURL, provider.TelegramWebhookHealthPath)
+	if err := serveWebhookFn(ctx, cfg, provider.TelegramWebhookOptions{
+		Listen: listen,
+		URL:    hookURL,
+		Secret: secret,
+	}); err != nil {
+		return err
+	}
+	fmt.Fprintln(io.ErrOut, "telegram webhook server stopped")
+	return nil
+}
+
+func printServeUsage(w io.Writer) {
+	fmt.Fprintln(w, "Usage:")
+	fmt.Fprintln(w, "  consult-human serve telegram-webhook --url https://example.com/hook [--listen :8443] [--secret TOKEN]")
+}
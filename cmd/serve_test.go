@@ -0,0 +1,72 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/AlhasanIQ/consult-human/config"
+	"github.com/AlhasanIQ/consult-human/provider"
+)
+
+func stubServeWebhook(t *testing.T) *provider.TelegramWebhookOptions {
+	t.Helper()
+	t.Setenv(config.EnvConfigPath, filepath.Join(t.TempDir(), "config.yaml"))
+	got := &provider.TelegramWebhookOptions{}
+	orig := serveWebhookFn
+	serveWebhookFn = func(_ context.Context, _ config.Config, opts provider.TelegramWebhookOptions) error {
+		*got = opts
+		return nil
+	}
+	t.Cleanup(func() { serveWebhookFn = orig })
+	return got
+}
+
+func TestRunServeTelegramWebhookPassesOptions(t *testing.T) {
+	got := stubServeWebhook(t)
+	cfg := config.Default()
+	cfg.Telegram.ReceiveMode = config.TelegramReceiveModeWebhook
+	cfg.Telegram.WebhookSecret = "from-config"
+	if err := config.Save(cfg); err != nil {
+		t.Fatalf("save config: %v", err)
+	}
+
+	var out, errOut bytes.Buffer
+	err := Execute([]string{"serve", "telegram-webhook", "--listen", ":9000", "--url", "https://example.com/hook"}, IO{In: strings.NewReader(""), Out: &out, ErrOut: &errOut})
+	if err != nil {
+		t.Fatalf("serve returned error: %v", err)
+	}
+	if got.Listen != ":9000" || got.URL != "https://example.com/hook" || got.Secret != "from-config" {
+		t.Fatalf("unexpected webhook options: %#v", *got)
+	}
+	if strings.Contains(errOut.String(), "receive_mode is not webhook") {
+		t.Fatalf("unexpected receive_mode warning: %q", errOut.String())
+	}
+}
+
+func TestRunServeWarnsWhenNotInWebhookMode(t *testing.T) {
+	stubServeWebhook(t)
+
+	var out, errOut bytes.Buffer
+	err := Execute([]string{"serve", "telegram-webhook", "--url", "https://example.com/hook"}, IO{In: strings.NewReader(""), Out: &out, ErrOut: &errOut})
+	if err != nil {
+		t.Fatalf("serve returned error: %v", err)
+	}
+	if !strings.Contains(errOut.String(), "telegram.receive_mode webhook") {
+		t.Fatalf("expected receive_mode warning, got %q", errOut.String())
+	}
+}
+
+func TestRunServeRequiresTargetAndURL(t *testing.T) {
+	stubServeWebhook(t)
+
+	var out, errOut bytes.Buffer
+	if err := Execute([]string{"serve", "slack"}, IO{In: strings.NewReader(""), Out: &out, ErrOut: &errOut}); err == nil || !strings.Contains(err.Error(), "unknown serve target") {
+		t.Fatalf("expected unknown target error, got %v", err)
+	}
+	if err := Execute([]string{"serve", "telegram-webhook"}, IO{In: strings.NewReader(""), Out: &out, ErrOut: &errOut}); err == nil || !strings.Contains(err.Error(), "--url is required") {
+		t.Fatalf("expected missing url error, got %v", err)
+	}
+}
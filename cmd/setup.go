@@ -2,21 +2,35 @@ package cmd
 
 import (
 	"bufio"
+	"context"
 	"errors"
 	"flag"
 	"fmt"
 	"io"
 	"os"
 	"path/filepath"
+	"slices"
 	"strconv"
 	"strings"
+	"syscall"
+	"time"
 
 	"github.com/AlhasanIQ/consult-human/config"
+	"github.com/AlhasanIQ/consult-human/gitrepo"
 )
 
 const (
 	setupProviderTelegram = "telegram"
 	setupProviderWhatsApp = "whatsapp"
+	setupProviderSlack    = "slack"
+	setupProviderSignal   = "signal"
+	setupProviderEmail    = "email"
+	setupProviderNtfy     = "ntfy"
+	setupProviderWebhook  = "webhook"
+	setupProviderDesktop  = "desktop"
+	setupProviderZulip    = "zulip"
+	setupProviderPushover = "pushover"
+	setupProviderIMessage = "imessage"
 )
 
 var setupSkillInstallFn = runSkillInstall
@@ -34,10 +48,18 @@ func runSetup(args []string, io IO) error {
 
 	var nonInteractive bool
 	var linkChat bool
+	var roundTrip bool
+	var skipVerify bool
+	var chatName string
+	var expectUser int64
 	var providersRaw stringSliceFlag
 	fs.BoolVar(&nonInteractive, "non-interactive", false, "Print setup checklist instead of prompting")
 	fs.BoolVar(&linkChat, "link-chat", false, "Link Telegram chat by waiting for /start without prompts")
-	fs.Var(&providersRaw, "provider", "Provider to include (telegram). Repeatable.")
+	fs.BoolVar(&skipVerify, "skip-verify", false, "Don't check the bot token with Telegram (offline config edits)")
+	fs.BoolVar(&roundTrip, "test", false, "Send a test message to the linked Telegram chat and wait for a reply")
+	fs.StringVar(&chatName, "name", "", "With --link-chat, save the chat under this alias in telegram.chats")
+	fs.Int64Var(&expectUser, "expect-user", 0, "With --link-chat, the Telegram user ID that must send /start")
+	fs.Var(&providersRaw, "provider", "Provider to include (telegram|slack|signal|email|ntfy|webhook|desktop|zulip|pushover|imessage). Repeatable.")
 
 	if err := fs.Parse(args); err != nil {
 		return err
@@ -59,19 +81,39 @@ func runSetup(args []string, io IO) error {
 		return err
 	}
 
-	cfg, err := config.Load()
+	cfg, err := config.LoadGlobal()
 	if err != nil {
 		return err
 	}
 
+	chatName = strings.ToLower(strings.TrimSpace(chatName))
+	if chatName != "" && !linkChat {
+		return fmt.Errorf("--name requires --link-chat")
+	}
+	if expectUser != 0 && !linkChat {
+		return fmt.Errorf("--expect-user requires --link-chat")
+	}
+	if linkChat && expectUser == 0 {
+		return fmt.Errorf("--link-chat requires --expect-user <telegram-user-id> so only your /start can link the chat (or run `consult-human setup` to confirm with a code)")
+	}
+	if chatName != "" && !config.IsValidTelegramChatAlias(chatName) {
+		return fmt.Errorf("--name %q must be letters, digits, _ or -, and not only digits", chatName)
+	}
+
+	if roundTrip && (linkChat || nonInteractive) {
+		return fmt.Errorf("--test cannot be combined with --link-chat or --non-interactive")
+	}
+	if roundTrip {
+		return runSetupTest(io, cfg, selected, selectedExplicit)
+	}
 	if linkChat {
-		return runSetupLinkChat(io, cfg, selected, selectedExplicit)
+		return runSetupLinkChat(io, cfg, selected, selectedExplicit, chatName, expectUser)
 	}
 
 	if nonInteractive {
 		return runSetupNonInteractive(io.Out, cfg, selected, selectedExplicit)
 	}
-	return runSetupInteractive(io, cfg, selected)
+	return runSetupInteractive(io, cfg, selected, skipVerify)
 }
 
 func isSetupHelpRequest(args []string) bool {
@@ -87,10 +129,10 @@ func isSetupHelpRequest(args []string) bool {
 	return false
 }
 
-func runSetupInteractive(io IO, cfg config.Config, selected []string) error {
+func runSetupInteractive(io IO, cfg config.Config, selected []string, skipVerify bool) error {
 	s := newSty(io.ErrOut)
 	s.header("consult-human · interactive setup")
-	s.info(s.dim("WhatsApp is temporarily disabled. Configuring Telegram only."))
+	s.info(s.dim("WhatsApp is temporarily disabled."))
 	runSetupShellPathInteractiveStep(s)
 
 	if err := validateSetupSelection(cfg, selected); err != nil {
@@ -101,13 +143,49 @@ func runSetupInteractive(io IO, cfg config.Config, selected []string) error {
 	for _, providerName := range selected {
 		switch providerName {
 		case setupProviderTelegram:
-			if err := runTelegramSetup(reader, s, &cfg); err != nil {
+			if err := runTelegramSetup(reader, s, &cfg, skipVerify); err != nil {
+				return err
+			}
+		case setupProviderSlack:
+			if err := runSlackSetup(reader, s, &cfg, skipVerify); err != nil {
+				return err
+			}
+		case setupProviderSignal:
+			if err := runSignalSetup(reader, s, &cfg, skipVerify); err != nil {
+				return err
+			}
+		case setupProviderEmail:
+			if err := runEmailSetup(reader, s, &cfg); err != nil {
+				return err
+			}
+		case setupProviderNtfy:
+			if err := runNtfySetup(reader, s, &cfg, skipVerify); err != nil {
+				return err
+			}
+		case setupProviderWebhook:
+			if err := runWebhookSetup(reader, s, &cfg); err != nil {
+				return err
+			}
+		case setupProviderDesktop:
+			if err := runDesktopSetup(reader, s, &cfg, skipVerify); err != nil {
+				return err
+			}
+		case setupProviderZulip:
+			if err := runZulipSetup(reader, s, &cfg, skipVerify); err != nil {
+				return err
+			}
+		case setupProviderPushover:
+			if err := runPushoverSetup(reader, s, &cfg, skipVerify); err != nil {
+				return err
+			}
+		case setupProviderIMessage:
+			if err := runIMessageSetup(reader, s, &cfg, skipVerify); err != nil {
 				return err
 			}
 		}
 	}
 
-	cfg.ActiveProvider = setupProviderTelegram
+	cfg.ActiveProvider = selected[0]
 	if err := config.Save(cfg); err != nil {
 		return err
 	}
@@ -149,6 +227,24 @@ func runSetupNonInteractive(w io.Writer, cfg config.Config, selected []string, s
 		switch providerName {
 		case setupProviderTelegram:
 			writeTelegramChecklist(w, isProviderSetupComplete(cfg, setupProviderTelegram))
+		case setupProviderSlack:
+			writeSlackChecklist(w, isProviderSetupComplete(cfg, setupProviderSlack))
+		case setupProviderSignal:
+			writeSignalChecklist(w, isProviderSetupComplete(cfg, setupProviderSignal))
+		case setupProviderEmail:
+			writeEmailChecklist(w, isProviderSetupComplete(cfg, setupProviderEmail))
+		case setupProviderNtfy:
+			writeNtfyChecklist(w, isProviderSetupComplete(cfg, setupProviderNtfy))
+		case setupProviderWebhook:
+			writeWebhookChecklist(w, isProviderSetupComplete(cfg, setupProviderWebhook))
+		case setupProviderDesktop:
+			writeDesktopChecklist(w, isProviderSetupComplete(cfg, setupProviderDesktop))
+		case setupProviderZulip:
+			writeZulipChecklist(w, isProviderSetupComplete(cfg, setupProviderZulip))
+		case setupProviderPushover:
+			writePushoverChecklist(w, isProviderSetupComplete(cfg, setupProviderPushover))
+		case setupProviderIMessage:
+			writeIMessageChecklist(w, isProviderSetupComplete(cfg, setupProviderIMessage))
 		}
 	}
 
@@ -156,7 +252,7 @@ func runSetupNonInteractive(w io.Writer, cfg config.Config, selected []string, s
 		writeWhatsAppDeferredNotice(w, cfg)
 	}
 
-	fmt.Fprintln(w, "Next: set active provider with `consult-human config set default-provider telegram`.")
+	fmt.Fprintf(w, "Next: set active provider with `consult-human config set default-provider %s`.\n", selected[0])
 	fmt.Fprintln(w)
 	fmt.Fprintln(w, "Verification: run `consult-human config show`.")
 	fmt.Fprintln(w)
@@ -165,15 +261,20 @@ func runSetupNonInteractive(w io.Writer, cfg config.Config, selected []string, s
 	fmt.Fprintln(w, "  Global install (all repos on this machine):")
 	fmt.Fprintln(w, "    consult-human skill install --target claude")
 	fmt.Fprintln(w, "    consult-human skill install --target codex")
+	fmt.Fprintln(w, "    consult-human skill install --target gemini")
+	fmt.Fprintln(w, "    consult-human skill install --target opencode")
 	fmt.Fprintln(w, "    consult-human skill install --target both")
 	fmt.Fprintln(w, "  Local install (repo-only):")
 	fmt.Fprintln(w, "    consult-human skill install --target claude --repo /path/to/repo")
 	fmt.Fprintln(w, "    consult-human skill install --target codex --repo /path/to/repo")
+	fmt.Fprintln(w, "    consult-human skill install --target gemini --repo /path/to/repo")
+	fmt.Fprintln(w, "    consult-human skill install --target opencode --repo /path/to/repo")
 	fmt.Fprintln(w, "    consult-human skill install --target both --repo /path/to/repo")
+	fmt.Fprintln(w, "  `both` also covers Cursor, Windsurf, Gemini CLI and opencode when they are installed; add --force-all to install for them regardless. Gemini CLI gets its reminder in GEMINI.md.")
 	return nil
 }
 
-func runSetupLinkChat(io IO, cfg config.Config, selected []string, selectedExplicit bool) error {
+func runSetupLinkChat(io IO, cfg config.Config, selected []string, selectedExplicit bool, chatName string, expectUser int64) error {
 	if selectedExplicit {
 		if len(selected) != 1 || selected[0] != setupProviderTelegram {
 			return fmt.Errorf("--link-chat currently supports only --provider telegram")
@@ -189,16 +290,30 @@ func runSetupLinkChat(io IO, cfg config.Config, selected []string, selectedExpli
 		return fmt.Errorf("telegram.bot_token is required; run `consult-human config set telegram.bot_token \"<BOT_TOKEN>\"` first")
 	}
 
-	fmt.Fprintln(s.w)
-	fmt.Fprintf(s.w, "  Now send %s to your bot from the chat you want to use.\n\n", s.bold("/start"))
-	sp := s.startSpinner("Waiting for /start message...")
-	chatID, err := telegramSetupLinkFn(token, setupTelegramLinkTimeout, s.w)
-	sp.stop()
+	cfg.Telegram.BotToken = token
+	link, err := linkTelegramChatForSetup(s, cfg, "", func(webhookURL string) (bool, error) {
+		if !cfg.Telegram.AutoDeleteWebhook {
+			s.info(fmt.Sprintf("A webhook is registered at %s. Run `consult-human config set telegram.auto_delete_webhook true` to let consult-human remove it.", webhookURL))
+		}
+		return cfg.Telegram.AutoDeleteWebhook, nil
+	})
 	if err != nil {
 		return fmt.Errorf("could not link Telegram chat: %w", err)
 	}
+	if err := checkTelegramSetupExpectedUser(link, expectUser); err != nil {
+		return fmt.Errorf("could not link Telegram chat: %w", err)
+	}
 
-	cfg.Telegram.ChatID = chatID
+	// A named chat is added alongside the default, which it only fills in
+	// when no chat is linked yet.
+	if chatName != "" {
+		if err := config.SetTelegramChat(&cfg, chatName, strconv.FormatInt(link.ChatID, 10)); err != nil {
+			return err
+		}
+	}
+	if chatName == "" || cfg.Telegram.ChatID == 0 {
+		cfg.Telegram.ChatID = link.ChatID
+	}
 	if err := config.Save(cfg); err != nil {
 		return err
 	}
@@ -208,8 +323,39 @@ func runSetupLinkChat(io IO, cfg config.Config, selected []string, selectedExpli
 		return err
 	}
 
-	s.success(fmt.Sprintf("Linked to chat %d", chatID))
+	if chatName != "" {
+		s.success(fmt.Sprintf("Linked chat %d as %q (use: consult-human ask --chat %s ...)", link.ChatID, chatName, chatName))
+	} else {
+		s.success(fmt.Sprintf("Linked to chat %d", link.ChatID))
+	}
 	s.info(s.dim(fmt.Sprintf("Config saved to %s", configPath)))
+	confirmTelegramSetupLink(s, cfg, link)
+	if link.isGroup() {
+		explainTelegramGroupMode(s, cfg)
+		s.info(fmt.Sprintf("To accept answers only from %s, run:", link.senderLabel()))
+		s.info(fmt.Sprintf("  consult-human config set telegram.allowed_user_ids %d", link.UserID))
+	}
+	return nil
+}
+
+func runSetupTest(io IO, cfg config.Config, selected []string, selectedExplicit bool) error {
+	if selectedExplicit {
+		if len(selected) != 1 || selected[0] != setupProviderTelegram {
+			return fmt.Errorf("--test currently supports only --provider telegram")
+		}
+	}
+
+	s := newSty(io.ErrOut)
+	s.header("consult-human · telegram test")
+	fmt.Fprintf(s.w, "  Sending a test message to chat %d; %s in Telegram.\n\n", cfg.Telegram.ChatID, s.bold("reply with anything"))
+
+	ctx, stopSignals := askSignalContextFn(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stopSignals()
+	latency, err := checkTelegramRoundTrip(ctx, cfg)
+	if err != nil {
+		return fmt.Errorf("telegram test failed: %w", err)
+	}
+	s.success(fmt.Sprintf("Round trip OK: reply received %s after sending", latency.Round(time.Millisecond)))
 	return nil
 }
 
@@ -220,7 +366,11 @@ func runSetupSkillInstallInteractive(reader *bufio.Reader, s *sty, runtimeIO IO)
 	fmt.Fprintln(s.w)
 	s.choice(1, "claude", "(Cloud/Claude Code)")
 	s.choice(2, "codex", "(Codex CLI)")
-	s.choice(3, "both", "(install to both targets)")
+	s.choice(3, "both", "(claude and codex, plus the other runtimes when detected)")
+	s.choice(4, "cursor", "(Cursor rules)")
+	s.choice(5, "windsurf", "(Windsurf rules)")
+	s.choice(6, "gemini", "(Gemini CLI)")
+	s.choice(7, "opencode", "(opencode)")
 	fmt.Fprintln(s.w)
 
 	for {
@@ -240,6 +390,14 @@ func runSetupSkillInstallInteractive(reader *bufio.Reader, s *sty, runtimeIO IO)
 		}
 
 		args := append([]string{"--target", target}, installArgs...)
+		proceed, err := previewSetupSkillInstall(reader, s, args, runtimeIO)
+		if err != nil {
+			return err
+		}
+		if !proceed {
+			s.info(fmt.Sprintf("Skipped skill install; run `consult-human skill install %s` when ready.", strings.Join(args, " ")))
+			return nil
+		}
 		if err := setupSkillInstallFn(args, runtimeIO); err != nil {
 			return fmt.Errorf("skill install failed: %w", err)
 		}
@@ -249,6 +407,30 @@ func runSetupSkillInstallInteractive(reader *bufio.Reader, s *sty, runtimeIO IO)
 	}
 }
 
+// previewSetupSkillInstall offers a dry run of the skill install with args
+// and, after showing one, asks whether to go ahead. It reports whether to.
+func previewSetupSkillInstall(reader *bufio.Reader, s *sty, args []string, runtimeIO IO) (bool, error) {
+	fmt.Fprintln(s.w)
+	answer, err := promptLine(reader, s.w, s.promptLabel("Preview the files this will write first? [y/N]: "))
+	if err != nil {
+		return false, err
+	}
+	if a := strings.ToLower(answer); a != "y" && a != "yes" {
+		return true, nil
+	}
+	dryRunArgs := append(append([]string(nil), args...), "--dry-run")
+	if err := setupSkillInstallFn(dryRunArgs, runtimeIO); err != nil {
+		return false, fmt.Errorf("skill install preview failed: %w", err)
+	}
+	fmt.Fprintln(s.w)
+	answer, err = promptLine(reader, s.w, s.promptLabel("Install now? [Y/n]: "))
+	if err != nil {
+		return false, err
+	}
+	a := strings.ToLower(answer)
+	return a == "" || a == "y" || a == "yes", nil
+}
+
 type setupSkillScopeOption struct {
 	Token        string
 	Label        string
@@ -389,21 +571,26 @@ func buildSetupSkillScopeOptions(target string) ([]setupSkillScopeOption, string
 }
 
 func skillDestinationTemplates(target string) []string {
-	switch target {
-	case skillTargetClaude:
-		return []string{
-			"<custom-repo>/.claude/skills/consult-human/SKILL.md",
-		}
-	case skillTargetCodex:
-		return []string{
-			"<custom-repo>/.codex/skills/consult-human/SKILL.md",
-		}
-	default:
-		return []string{
-			"<custom-repo>/.claude/skills/consult-human/SKILL.md",
-			"<custom-repo>/.codex/skills/consult-human/SKILL.md",
-		}
+	var templates []string
+	if skillTargetSelects(target, skillTargetClaude) {
+		templates = append(templates, "<custom-repo>/.claude/skills/consult-human/SKILL.md")
 	}
+	if skillTargetSelects(target, skillTargetCodex) {
+		templates = append(templates, "<custom-repo>/.codex/skills/consult-human/SKILL.md")
+	}
+	if skillTargetSelects(target, skillTargetCursor) {
+		templates = append(templates, "<custom-repo>/.cursor/rules/"+cursorRuleFileName)
+	}
+	if skillTargetSelects(target, skillTargetWindsurf) {
+		templates = append(templates, "<custom-repo>/.windsurf/rules/"+windsurfRuleFileName)
+	}
+	if skillTargetSelects(target, skillTargetGemini) {
+		templates = append(templates, "<custom-repo>/.gemini/skills/consult-human/SKILL.md")
+	}
+	if skillTargetSelects(target, skillTargetOpencode) {
+		templates = append(templates, "<custom-repo>/.opencode/skill/consult-human/SKILL.md")
+	}
+	return templates
 }
 
 func detectCurrentRepoForSkillSetup() (string, bool, bool, error) {
@@ -411,7 +598,7 @@ func detectCurrentRepoForSkillSetup() (string, bool, bool, error) {
 	if err != nil {
 		return "", false, false, err
 	}
-	repoRoot, found, err := findGitRepoRoot(cwd)
+	repoRoot, found, err := gitrepo.FindRoot(cwd)
 	if err != nil {
 		return "", false, false, err
 	}
@@ -419,36 +606,16 @@ func detectCurrentRepoForSkillSetup() (string, bool, bool, error) {
 		return "", false, false, nil
 	}
 
-	hasClaudeDir, err := dirExists(filepath.Join(repoRoot, ".claude"))
-	if err != nil {
-		return "", false, false, err
-	}
-	hasAgentsDir, err := dirExists(filepath.Join(repoRoot, ".agents"))
-	if err != nil {
-		return "", false, false, err
-	}
-	return repoRoot, true, hasClaudeDir || hasAgentsDir, nil
-}
-
-func findGitRepoRoot(startPath string) (string, bool, error) {
-	if strings.TrimSpace(startPath) == "" {
-		return "", false, fmt.Errorf("empty start path")
-	}
-	current := filepath.Clean(startPath)
-	for {
-		gitPath := filepath.Join(current, ".git")
-		if _, err := os.Stat(gitPath); err == nil {
-			return current, true, nil
-		} else if err != nil && !errors.Is(err, os.ErrNotExist) {
-			return "", false, err
+	for _, dir := range []string{".claude", ".agents", ".cursor", ".windsurf"} {
+		found, err := dirExists(filepath.Join(repoRoot, dir))
+		if err != nil {
+			return "", false, false, err
 		}
-
-		parent := filepath.Dir(current)
-		if parent == current {
-			return "", false, nil
+		if found {
+			return repoRoot, true, true, nil
 		}
-		current = parent
 	}
+	return repoRoot, true, false, nil
 }
 
 func dirExists(path string) (bool, error) {
@@ -476,7 +643,8 @@ func writeTelegramChecklist(w io.Writer, alreadySetup bool) {
 	step++
 	fmt.Fprintf(w, "  Step %d: Run `consult-human config set telegram.bot_token \"<BOT_TOKEN>\"`.\n", step)
 	step++
-	fmt.Fprintf(w, "  Step %d: Run `consult-human setup --provider telegram --link-chat` to link chat via /start (non-interactive).\n", step)
+	fmt.Fprintf(w, "  Step %d: Run `consult-human setup --provider telegram --link-chat --expect-user <USER_ID>` to link chat via /start (non-interactive; USER_ID is your numeric Telegram user ID).\n", step)
+	fmt.Fprintln(w, "  Self-hosted Bot API server: run `consult-human config set telegram.api_base_url \"<URL>\"` before linking.")
 	fmt.Fprintln(w)
 }
 
@@ -495,12 +663,17 @@ func writeWhatsAppDeferredNotice(w io.Writer, cfg config.Config) {
 
 func printSetupUsage(w io.Writer) {
 	fmt.Fprintln(w, "Usage:")
-	fmt.Fprintln(w, "  consult-human setup [--provider telegram] [--link-chat]")
-	fmt.Fprintln(w, "  consult-human setup --non-interactive [--provider telegram]")
+	fmt.Fprintln(w, "  consult-human setup [--provider telegram|slack|signal|email|ntfy|webhook|desktop|zulip|pushover|imessage] [--skip-verify]")
+	fmt.Fprintln(w, "  consult-human setup --link-chat --expect-user USER_ID [--provider telegram] [--name ALIAS]")
+	fmt.Fprintln(w, "  consult-human setup --non-interactive [--provider telegram|slack|signal|email|ntfy|webhook|desktop|zulip|pushover|imessage]")
+	fmt.Fprintln(w, "  consult-human setup --test [--provider telegram]")
 	fmt.Fprintln(w, "")
 	fmt.Fprintln(w, "Interactive first-time setup, or checklist-only mode.")
 	fmt.Fprintln(w, "Both setup modes ensure consult-human binary PATH in your shell login profile.")
-	fmt.Fprintln(w, "`--link-chat` waits for Telegram /start and saves telegram.chat_id without prompts.")
+	fmt.Fprintln(w, "Interactive setup links the chat that sends /start once you type the code the bot sends there.")
+	fmt.Fprintln(w, "`--link-chat` waits for Telegram /start from the --expect-user ID and saves telegram.chat_id without prompts.")
+	fmt.Fprintln(w, "`--skip-verify` saves the bot token without checking it with Telegram's getMe.")
+	fmt.Fprintln(w, "`--test` sends a test message to the linked chat and waits up to 2m for any reply.")
 	fmt.Fprintln(w, "WhatsApp is temporarily disabled.")
 }
 
@@ -539,6 +712,8 @@ func parseSetupProviderToken(token string) (string, error) {
 	switch token {
 	case "1", setupProviderTelegram:
 		return setupProviderTelegram, nil
+	case setupProviderSlack, setupProviderSignal, setupProviderEmail, setupProviderNtfy, setupProviderWebhook, setupProviderDesktop, setupProviderZulip, setupProviderPushover, setupProviderIMessage:
+		return token, nil
 	case "2", setupProviderWhatsApp:
 		return "", fmt.Errorf("whatsapp is temporarily disabled")
 	default:
@@ -550,9 +725,7 @@ func parseSetupProviderToken(token string) (string, error) {
 }
 
 func parseSetupSkillTargetSelection(raw string) (string, error) {
-	claudeSelected := false
-	codexSelected := false
-
+	selected := map[string]bool{}
 	for _, part := range strings.Split(raw, ",") {
 		token := strings.TrimSpace(strings.ToLower(part))
 		if token == "" {
@@ -562,27 +735,19 @@ func parseSetupSkillTargetSelection(raw string) (string, error) {
 		if err != nil {
 			return "", err
 		}
-		switch target {
-		case skillTargetBoth:
-			claudeSelected = true
-			codexSelected = true
-		case skillTargetClaude:
-			claudeSelected = true
-		case skillTargetCodex:
-			codexSelected = true
+		if target == skillTargetBoth {
+			return skillTargetBoth, nil
 		}
+		selected[target] = true
 	}
 
-	if !claudeSelected && !codexSelected {
-		return "", fmt.Errorf("select at least one target: claude, codex, or both")
+	if len(selected) == 0 {
+		return "", fmt.Errorf("select at least one target: claude, codex, both, cursor, windsurf, gemini, or opencode")
 	}
-	if claudeSelected && codexSelected {
+	if len(selected) == 2 && selected[skillTargetClaude] && selected[skillTargetCodex] {
 		return skillTargetBoth, nil
 	}
-	if claudeSelected {
-		return skillTargetClaude, nil
-	}
-	return skillTargetCodex, nil
+	return joinSkillTargets(selected), nil
 }
 
 func parseSetupSkillTargetToken(token string) (string, error) {
@@ -593,6 +758,14 @@ func parseSetupSkillTargetToken(token string) (string, error) {
 		return skillTargetCodex, nil
 	case "3", "both", "all":
 		return skillTargetBoth, nil
+	case "4", "cursor":
+		return skillTargetCursor, nil
+	case "5", "windsurf":
+		return skillTargetWindsurf, nil
+	case "6", "gemini", "gemini-cli":
+		return skillTargetGemini, nil
+	case "7", "opencode":
+		return skillTargetOpencode, nil
 	default:
 		return "", fmt.Errorf("invalid skill target %q", token)
 	}
@@ -614,6 +787,25 @@ func isProviderSetupComplete(cfg config.Config, providerName string) bool {
 		return strings.TrimSpace(cfg.Telegram.BotToken) != "" && cfg.Telegram.ChatID != 0
 	case setupProviderWhatsApp:
 		return strings.TrimSpace(cfg.WhatsApp.Recipient) != ""
+	case setupProviderSlack:
+		return strings.TrimSpace(cfg.Slack.BotToken) != "" && strings.TrimSpace(cfg.Slack.Channel) != ""
+	case setupProviderSignal:
+		return strings.TrimSpace(cfg.Signal.Number) != "" && strings.TrimSpace(cfg.Signal.Recipient) != ""
+	case setupProviderEmail:
+		return strings.TrimSpace(cfg.Email.SMTPHost) != "" && strings.TrimSpace(cfg.Email.IMAPHost) != "" && strings.TrimSpace(cfg.Email.To) != ""
+	case setupProviderNtfy:
+		return strings.TrimSpace(cfg.Ntfy.Topic) != ""
+	case setupProviderWebhook:
+		return strings.TrimSpace(cfg.Webhook.URL) != "" && (strings.TrimSpace(cfg.Webhook.CallbackListen) != "" || strings.TrimSpace(cfg.Webhook.PollURL) != "")
+	case setupProviderDesktop:
+		// Nothing to configure; it counts once something would use it.
+		return cfg.ActiveProvider == setupProviderDesktop || slices.Contains(cfg.FallbackProviders, setupProviderDesktop)
+	case setupProviderZulip:
+		return strings.TrimSpace(cfg.Zulip.APIKey) != "" && (strings.TrimSpace(cfg.Zulip.Stream) != "" || strings.TrimSpace(cfg.Zulip.Recipient) != "")
+	case setupProviderPushover:
+		return strings.TrimSpace(cfg.Pushover.AppToken) != "" && strings.TrimSpace(cfg.Pushover.UserKey) != ""
+	case setupProviderIMessage:
+		return strings.TrimSpace(cfg.IMessage.Handle) != ""
 	default:
 		return false
 	}
@@ -631,7 +823,7 @@ func validateSetupProvidersEnabled(selected []string) error {
 
 func isSetupProviderEnabled(providerName string) bool {
 	switch strings.ToLower(strings.TrimSpace(providerName)) {
-	case setupProviderTelegram:
+	case setupProviderTelegram, setupProviderSlack, setupProviderSignal, setupProviderEmail, setupProviderNtfy, setupProviderWebhook, setupProviderDesktop, setupProviderZulip, setupProviderPushover, setupProviderIMessage:
 		return true
 	case setupProviderWhatsApp:
 		return false
@@ -0,0 +1,69 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os/exec"
+	"runtime"
+
+	"github.com/AlhasanIQ/consult-human/config"
+)
+
+var desktopSetupLookPathFn = exec.LookPath
+
+func runDesktopSetup(reader *bufio.Reader, s *sty, cfg *config.Config, skipVerify bool) error {
+	s.section("Desktop")
+	fmt.Fprintf(s.w, "  Questions show up as desktop notifications; clicking one opens a reply form in your browser.\n")
+	fmt.Fprintf(s.w, "  The form is served on 127.0.0.1 only while a question is waiting.\n\n")
+
+	for {
+		line, err := promptLine(reader, s.w, s.promptLabel("Reply form port (Enter for any free port): "))
+		if err != nil {
+			return err
+		}
+		if line == "" {
+			break
+		}
+		if err := config.Set(cfg, "desktop.port", line); err != nil {
+			s.errMsg(err.Error())
+			continue
+		}
+		break
+	}
+	if skipVerify {
+		return nil
+	}
+
+	notifier := desktopNotifierCommand()
+	if _, err := desktopSetupLookPathFn(notifier); err != nil {
+		return fmt.Errorf("could not find %s for desktop notifications (use --skip-verify to continue anyway): %w", notifier, err)
+	}
+	s.success(fmt.Sprintf("Found %s", notifier))
+	return nil
+}
+
+func writeDesktopChecklist(w io.Writer, alreadySetup bool) {
+	if alreadySetup {
+		fmt.Fprintln(w, "Desktop (already set up):")
+		fmt.Fprintln(w, "  Status: already configured.")
+		fmt.Fprintln(w, "  Reconfigure first: `consult-human config reset --provider desktop`.")
+		fmt.Fprintln(w, "  Fresh setup steps (if reconfiguring):")
+	} else {
+		fmt.Fprintln(w, "Desktop:")
+	}
+	fmt.Fprintf(w, "  Step 1: Make sure `%s` is available; it shows the notifications.\n", desktopNotifierCommand())
+	fmt.Fprintln(w, "  Step 2: Optionally pin the reply form port with `consult-human config set desktop.port <PORT>`.")
+	fmt.Fprintln(w)
+}
+
+func desktopNotifierCommand() string {
+	switch runtime.GOOS {
+	case "darwin":
+		return "osascript"
+	case "windows":
+		return "powershell"
+	default:
+		return "notify-send"
+	}
+}
@@ -0,0 +1,73 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+
+	"github.com/AlhasanIQ/consult-human/config"
+)
+
+// runEmailSetup collects the SMTP and IMAP settings. Nothing is sent during
+// setup; the first `ask` shows whether the servers accept the login.
+func runEmailSetup(reader *bufio.Reader, s *sty, cfg *config.Config) error {
+	s.section("Email")
+	fmt.Fprintf(s.w, "  Questions are sent over SMTP (STARTTLS) and replies read over IMAP.\n")
+	fmt.Fprintf(s.w, "  Use an app password where your mail provider requires one.\n\n")
+
+	prompts := []struct {
+		key      string
+		label    string
+		optional bool
+	}{
+		{key: "email.smtp_host", label: "SMTP server (e.g. smtp.gmail.com): "},
+		{key: "email.smtp_port", label: fmt.Sprintf("SMTP port [%d]: ", config.DefaultEmailSMTPPort), optional: true},
+		{key: "email.smtp_username", label: "SMTP username: "},
+		{key: "email.smtp_password", label: "SMTP password: "},
+		{key: "email.from", label: "Send questions from: "},
+		{key: "email.to", label: "Send questions to (your address): "},
+		{key: "email.imap_host", label: "IMAP server (e.g. imap.gmail.com): "},
+		{key: "email.imap_port", label: fmt.Sprintf("IMAP port [%d]: ", config.DefaultEmailIMAPPort), optional: true},
+	}
+	for _, p := range prompts {
+		for {
+			var value string
+			var err error
+			if p.optional {
+				value, err = promptLine(reader, s.w, s.promptLabel(p.label))
+			} else {
+				value, err = promptRequiredLine(reader, s, s.promptLabel(p.label))
+			}
+			if err != nil {
+				return err
+			}
+			if value == "" {
+				break
+			}
+			if err := config.Set(cfg, p.key, value); err != nil {
+				s.errMsg(err.Error())
+				continue
+			}
+			break
+		}
+	}
+	s.info(s.dim("The IMAP login defaults to the SMTP one; set email.imap_username and email.imap_password if it differs."))
+	return nil
+}
+
+func writeEmailChecklist(w io.Writer, alreadySetup bool) {
+	if alreadySetup {
+		fmt.Fprintln(w, "Email (already set up):")
+		fmt.Fprintln(w, "  Status: already configured.")
+		fmt.Fprintln(w, "  Reconfigure first: `consult-human config reset --provider email`.")
+		fmt.Fprintln(w, "  Fresh setup steps (if reconfiguring):")
+	} else {
+		fmt.Fprintln(w, "Email:")
+	}
+	fmt.Fprintln(w, "  Step 1: Get the SMTP and IMAP servers of the sending mailbox and a login (an app password where required).")
+	fmt.Fprintln(w, "  Step 2: Run `consult-human config set email.smtp_host <HOST>`, `consult-human config set email.smtp_username <USER>` and `consult-human config set email.smtp_password <PASSWORD>`.")
+	fmt.Fprintln(w, "  Step 3: Run `consult-human config set email.from <ADDRESS>` and `consult-human config set email.to <YOUR_ADDRESS>`.")
+	fmt.Fprintln(w, "  Step 4: Run `consult-human config set email.imap_host <HOST>`; set email.imap_username and email.imap_password if the IMAP login differs.")
+	fmt.Fprintf(w, "  Ports default to %d (SMTP, STARTTLS) and %d (IMAP, TLS); replies are read from %s unless email.mailbox is set.\n", config.DefaultEmailSMTPPort, config.DefaultEmailIMAPPort, config.DefaultEmailMailbox)
+	fmt.Fprintln(w)
+}
@@ -0,0 +1,82 @@
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/AlhasanIQ/consult-human/config"
+	"github.com/AlhasanIQ/consult-human/provider"
+)
+
+var (
+	imessageSetupCheckFn = func(dbPath string) error {
+		ctx, cancel := context.WithTimeout(context.Background(), setupSlackRequestTimeout)
+		defer cancel()
+		return provider.CheckIMessageAccess(ctx, dbPath)
+	}
+	imessageSetupSendFn = func(handle, text string) error {
+		ctx, cancel := context.WithTimeout(context.Background(), setupSlackRequestTimeout)
+		defer cancel()
+		return provider.SendIMessage(ctx, handle, text)
+	}
+)
+
+func runIMessageSetup(reader *bufio.Reader, s *sty, cfg *config.Config, skipVerify bool) error {
+	s.section("iMessage")
+	fmt.Fprintf(s.w, "  consult-human sends through the Messages app on this Mac:\n\n")
+	s.step(1, "Sign in to iMessage in "+s.bold("Messages"))
+	s.step(2, "Give your terminal app "+s.bold("Full Disk Access")+" (System Settings > Privacy & Security) so replies can be read")
+	fmt.Fprintln(s.w)
+
+	if !skipVerify {
+		dbPath, err := config.EffectiveIMessageDBPath(*cfg)
+		if err != nil {
+			return err
+		}
+		if err := imessageSetupCheckFn(dbPath); err != nil {
+			return fmt.Errorf("%w (use --skip-verify to set up iMessage anyway)", err)
+		}
+	}
+	for {
+		handle, err := promptRequiredLine(reader, s, s.promptLabel("Your phone number or Apple ID email (questions go here): "))
+		if err != nil {
+			return err
+		}
+		if err := config.Set(cfg, "imessage.handle", handle); err != nil {
+			s.errMsg(err.Error())
+			continue
+		}
+		break
+	}
+	if skipVerify {
+		return nil
+	}
+
+	host, err := askHostnameFn()
+	if err != nil || host == "" {
+		host = "this machine"
+	}
+	text := fmt.Sprintf("consult-human on %s will send its questions here.", host)
+	if err := imessageSetupSendFn(cfg.IMessage.Handle, text); err != nil {
+		return fmt.Errorf("could not send an iMessage test message: %w", err)
+	}
+	s.success(fmt.Sprintf("Sent a test message to %s", cfg.IMessage.Handle))
+	return nil
+}
+
+func writeIMessageChecklist(w io.Writer, alreadySetup bool) {
+	if alreadySetup {
+		fmt.Fprintln(w, "iMessage (already set up):")
+		fmt.Fprintln(w, "  Status: already configured.")
+		fmt.Fprintln(w, "  Reconfigure first: `consult-human config reset --provider imessage`.")
+		fmt.Fprintln(w, "  Fresh setup steps (if reconfiguring):")
+	} else {
+		fmt.Fprintln(w, "iMessage (macOS only):")
+	}
+	fmt.Fprintln(w, "  Step 1: Sign in to iMessage in the Messages app on this Mac.")
+	fmt.Fprintln(w, "  Step 2: Give the terminal app running consult-human Full Disk Access (System Settings > Privacy & Security > Full Disk Access).")
+	fmt.Fprintln(w, "  Step 3: Run `consult-human config set imessage.handle \"<+YOUR_NUMBER or APPLE_ID_EMAIL>\"`.")
+	fmt.Fprintln(w)
+}
@@ -0,0 +1,131 @@
+package cmd
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/AlhasanIQ/consult-human/config"
+)
+
+var ntfySetupPublishFn = func(server, topic, token, text string) error {
+	body, err := json.Marshal(map[string]any{"topic": topic, "title": "consult-human", "message": text})
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), setupSlackRequestTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, server+"/", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		b, _ := io.ReadAll(io.LimitReader(resp.Body, 4<<10))
+		return fmt.Errorf("ntfy status %d: %s", resp.StatusCode, strings.TrimSpace(string(b)))
+	}
+	return nil
+}
+
+func runNtfySetup(reader *bufio.Reader, s *sty, cfg *config.Config, skipVerify bool) error {
+	s.section("ntfy")
+	fmt.Fprintf(s.w, "  No account needed: questions go to a topic you subscribe to in the ntfy app.\n\n")
+	s.step(1, "Install the ntfy app ("+s.bold("https://ntfy.sh")+") or open the web app")
+	s.step(2, "Subscribe to the topic below, and to the same name with "+s.bold(config.NtfyReplyTopicSuffix)+" added to answer")
+	fmt.Fprintln(s.w)
+
+	line, err := promptLine(reader, s.w, s.promptLabel(fmt.Sprintf("Server [%s]: ", config.EffectiveNtfyServer(*cfg))))
+	if err != nil {
+		return err
+	}
+	if line != "" {
+		if err := config.Set(cfg, "ntfy.server", line); err != nil {
+			return err
+		}
+	}
+
+	suggested, err := suggestNtfyTopic()
+	if err != nil {
+		return err
+	}
+	s.info(s.dim("Anyone who knows the topic name can read it on a public server, so keep it hard to guess."))
+	for {
+		topic, err := promptLine(reader, s.w, s.promptLabel(fmt.Sprintf("Topic [%s]: ", suggested)))
+		if err != nil {
+			return err
+		}
+		if topic == "" {
+			topic = suggested
+		}
+		if err := config.Set(cfg, "ntfy.topic", topic); err != nil {
+			s.errMsg(err.Error())
+			continue
+		}
+		break
+	}
+	for {
+		token, err := promptLine(reader, s.w, s.promptLabel("Access token (tk_..., Enter for none): "))
+		if err != nil {
+			return err
+		}
+		if err := config.Set(cfg, "ntfy.token", token); err != nil {
+			s.errMsg(err.Error())
+			continue
+		}
+		break
+	}
+	if skipVerify {
+		return nil
+	}
+
+	host, err := askHostnameFn()
+	if err != nil || host == "" {
+		host = "this machine"
+	}
+	text := fmt.Sprintf("consult-human on %s will publish its questions to this topic.", host)
+	if err := ntfySetupPublishFn(config.EffectiveNtfyServer(*cfg), cfg.Ntfy.Topic, cfg.Ntfy.Token, text); err != nil {
+		return fmt.Errorf("could not publish to ntfy (use --skip-verify when offline): %w", err)
+	}
+	s.success(fmt.Sprintf("Published a test message to %s", cfg.Ntfy.Topic))
+	return nil
+}
+
+func writeNtfyChecklist(w io.Writer, alreadySetup bool) {
+	if alreadySetup {
+		fmt.Fprintln(w, "ntfy (already set up):")
+		fmt.Fprintln(w, "  Status: already configured.")
+		fmt.Fprintln(w, "  Reconfigure first: `consult-human config reset --provider ntfy`.")
+		fmt.Fprintln(w, "  Fresh setup steps (if reconfiguring):")
+	} else {
+		fmt.Fprintln(w, "ntfy:")
+	}
+	fmt.Fprintln(w, "  Step 1: Pick a hard-to-guess topic name and run `consult-human config set ntfy.topic <TOPIC>`.")
+	fmt.Fprintf(w, "  Step 2: In the ntfy app, subscribe to <TOPIC> for questions and to <TOPIC>%s to publish answers.\n", config.NtfyReplyTopicSuffix)
+	fmt.Fprintf(w, "  For a self-hosted server, run `consult-human config set ntfy.server <URL>` (default %s).\n", config.DefaultNtfyServer)
+	fmt.Fprintln(w, "  With access control, run `consult-human config set ntfy.token <tk_TOKEN>`.")
+	fmt.Fprintln(w)
+}
+
+func suggestNtfyTopic() (string, error) {
+	b := make([]byte, 6)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return "consult-human-" + hex.EncodeToString(b), nil
+}
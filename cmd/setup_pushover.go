@@ -0,0 +1,150 @@
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/AlhasanIQ/consult-human/config"
+	"github.com/AlhasanIQ/consult-human/provider"
+)
+
+var errPushoverSetupRejected = errors.New("rejected by Pushover, double-check the application API token and your user key")
+
+var pushoverSetupValidateFn = func(appToken, userKey string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), setupSlackRequestTimeout)
+	defer cancel()
+	form := url.Values{"token": {appToken}, "user": {userKey}}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, provider.DefaultPushoverAPIBaseURL+"/users/validate.json", strings.NewReader(form.Encode()))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	var decoded struct {
+		Status int `json:"status"`
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(&decoded); err != nil {
+		return fmt.Errorf("pushover status %d: %w", resp.StatusCode, err)
+	}
+	if decoded.Status != 1 {
+		return errPushoverSetupRejected
+	}
+	return nil
+}
+
+func runPushoverSetup(reader *bufio.Reader, s *sty, cfg *config.Config, skipVerify bool) error {
+	s.section("Pushover")
+	fmt.Fprintf(s.w, "  Create an application for consult-human:\n\n")
+	s.step(1, "Open "+s.bold("https://pushover.net/apps/build")+" and create an application; copy its API token")
+	s.step(2, "Copy your user key from the top of the pushover.net dashboard")
+	fmt.Fprintln(s.w)
+
+	for {
+		for _, p := range []struct{ key, label string }{
+			{"pushover.app_token", "Application API token: "},
+			{"pushover.user_key", "Your user key: "},
+		} {
+			if err := promptPushoverKey(reader, s, cfg, p.key, p.label); err != nil {
+				return err
+			}
+		}
+		if skipVerify {
+			break
+		}
+		err := pushoverSetupValidateFn(cfg.Pushover.AppToken, cfg.Pushover.UserKey)
+		if errors.Is(err, errPushoverSetupRejected) {
+			s.errMsg(err.Error())
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("could not verify the pushover keys (use --skip-verify when offline): %w", err)
+		}
+		s.success("Keys OK")
+		break
+	}
+
+	fmt.Fprintln(s.w)
+	s.step(1, "Confirm only: acknowledging the alert is the answer (no choices or text)")
+	s.step(2, "Reply form: the notification opens a page served here, through a tunnel or your LAN")
+	for {
+		line, err := promptLine(reader, s.w, s.promptLabel("Answer by [1]: "))
+		if err != nil {
+			return err
+		}
+		switch line {
+		case "", "1":
+			cfg.Pushover.ReplyURL = ""
+			return nil
+		case "2":
+			for {
+				line, err := promptLine(reader, s.w, s.promptLabel(fmt.Sprintf("Listen address [%s]: ", config.DefaultPushoverListen)))
+				if err != nil {
+					return err
+				}
+				if line == "" {
+					line = config.DefaultPushoverListen
+				}
+				if err := config.Set(cfg, "pushover.listen", line); err != nil {
+					s.errMsg(err.Error())
+					continue
+				}
+				break
+			}
+			s.info(s.dim(fmt.Sprintf("Your phone must reach it, e.g. `cloudflared tunnel --url http://%s`.", cfg.Pushover.Listen)))
+			for {
+				value, err := promptRequiredLine(reader, s, s.promptLabel("Public URL of the form: "))
+				if err != nil {
+					return err
+				}
+				if err := config.Set(cfg, "pushover.reply_url", value); err != nil {
+					s.errMsg(err.Error())
+					continue
+				}
+				return nil
+			}
+		default:
+			s.errMsg("enter 1 or 2")
+		}
+	}
+}
+
+func promptPushoverKey(reader *bufio.Reader, s *sty, cfg *config.Config, key, label string) error {
+	for {
+		value, err := promptRequiredLine(reader, s, s.promptLabel(label))
+		if err != nil {
+			return err
+		}
+		if err := config.Set(cfg, key, value); err != nil {
+			s.errMsg(err.Error())
+			continue
+		}
+		return nil
+	}
+}
+
+func writePushoverChecklist(w io.Writer, alreadySetup bool) {
+	if alreadySetup {
+		fmt.Fprintln(w, "Pushover (already set up):")
+		fmt.Fprintln(w, "  Status: already configured.")
+		fmt.Fprintln(w, "  Reconfigure first: `consult-human config reset --provider pushover`.")
+		fmt.Fprintln(w, "  Fresh setup steps (if reconfiguring):")
+	} else {
+		fmt.Fprintln(w, "Pushover:")
+	}
+	fmt.Fprintln(w, "  Step 1: Create an application at https://pushover.net/apps/build and copy its API token and your user key.")
+	fmt.Fprintln(w, "  Step 2: Run `consult-human config set pushover.app_token <API_TOKEN>` and `consult-human config set pushover.user_key <USER_KEY>`.")
+	fmt.Fprintln(w, "  Step 3 (optional): To answer from a reply form instead of only acknowledging, expose the form with a tunnel (e.g. `cloudflared tunnel --url http://"+config.DefaultPushoverListen+"`) and run `consult-human config set pushover.reply_url <TUNNEL_URL>`.")
+	fmt.Fprintln(w)
+}
@@ -0,0 +1,147 @@
+package cmd
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/AlhasanIQ/consult-human/config"
+)
+
+var signalSetupAccountsFn = func(apiURL string) ([]string, error) {
+	var accounts []string
+	if err := callSignalSetup(http.MethodGet, apiURL+"/v1/accounts", nil, &accounts); err != nil {
+		return nil, err
+	}
+	return accounts, nil
+}
+
+var signalSetupSendFn = func(apiURL, number, recipient, text string) error {
+	body, err := json.Marshal(map[string]any{"message": text, "number": number, "recipients": []string{recipient}})
+	if err != nil {
+		return err
+	}
+	return callSignalSetup(http.MethodPost, apiURL+"/v2/send", body, nil)
+}
+
+func runSignalSetup(reader *bufio.Reader, s *sty, cfg *config.Config, skipVerify bool) error {
+	s.section("Signal")
+	fmt.Fprintf(s.w, "  consult-human talks to a local signal-cli-rest-api:\n\n")
+	s.step(1, "Run "+s.bold("docker run -d -p 8080:8080 -v $HOME/.local/share/signal-cli:/home/.local/share/signal-cli -e MODE=normal bbernhard/signal-cli-rest-api"))
+	s.step(2, "Register a number for consult-human there, or link it to your Signal account as a device")
+	fmt.Fprintln(s.w)
+
+	line, err := promptLine(reader, s.w, s.promptLabel(fmt.Sprintf("API URL [%s]: ", config.EffectiveSignalAPIURL(*cfg))))
+	if err != nil {
+		return err
+	}
+	if line != "" {
+		if err := config.Set(cfg, "signal.api_url", line); err != nil {
+			return err
+		}
+	}
+	apiURL := config.EffectiveSignalAPIURL(*cfg)
+
+	var accounts []string
+	if !skipVerify {
+		accounts, err = signalSetupAccountsFn(apiURL)
+		if err != nil {
+			return fmt.Errorf("could not reach signal-cli-rest-api at %s (use --skip-verify when it is not running yet): %w", apiURL, err)
+		}
+	}
+	for {
+		number, err := promptRequiredLine(reader, s, s.promptLabel("Number consult-human sends from (e.g. +15551234567): "))
+		if err != nil {
+			return err
+		}
+		if err := config.Set(cfg, "signal.number", number); err != nil {
+			s.errMsg(err.Error())
+			continue
+		}
+		if !skipVerify && !slices.Contains(accounts, number) {
+			s.errMsg(fmt.Sprintf("%s is not registered with signal-cli-rest-api (registered: %s)", number, strings.Join(accounts, ", ")))
+			continue
+		}
+		break
+	}
+	for {
+		recipient, err := promptRequiredLine(reader, s, s.promptLabel("Your Signal number (questions go here): "))
+		if err != nil {
+			return err
+		}
+		if err := config.Set(cfg, "signal.recipient", recipient); err != nil {
+			s.errMsg(err.Error())
+			continue
+		}
+		break
+	}
+	if skipVerify {
+		return nil
+	}
+
+	host, err := askHostnameFn()
+	if err != nil || host == "" {
+		host = "this machine"
+	}
+	text := fmt.Sprintf("consult-human on %s will send its questions here.", host)
+	if err := signalSetupSendFn(apiURL, cfg.Signal.Number, cfg.Signal.Recipient, text); err != nil {
+		return fmt.Errorf("could not send a Signal test message: %w", err)
+	}
+	s.success(fmt.Sprintf("Sent a test message to %s", cfg.Signal.Recipient))
+	return nil
+}
+
+func writeSignalChecklist(w io.Writer, alreadySetup bool) {
+	if alreadySetup {
+		fmt.Fprintln(w, "Signal (already set up):")
+		fmt.Fprintln(w, "  Status: already configured.")
+		fmt.Fprintln(w, "  Reconfigure first: `consult-human config reset --provider signal`.")
+		fmt.Fprintln(w, "  Fresh setup steps (if reconfiguring):")
+	} else {
+		fmt.Fprintln(w, "Signal:")
+	}
+	fmt.Fprintln(w, "  Step 1: Run `docker run -d -p 8080:8080 -v $HOME/.local/share/signal-cli:/home/.local/share/signal-cli -e MODE=normal bbernhard/signal-cli-rest-api`.")
+	fmt.Fprintln(w, "  Step 2: Register a number for consult-human there, or link it to your Signal account as a device.")
+	fmt.Fprintln(w, "  Step 3: Run `consult-human config set signal.number \"<+SENDER>\"` and `consult-human config set signal.recipient \"<+YOUR_NUMBER>\"`.")
+	fmt.Fprintf(w, "  If the API does not listen on %s, run `consult-human config set signal.api_url \"<URL>\"`.\n", config.DefaultSignalAPIURL)
+	fmt.Fprintln(w)
+}
+
+func callSignalSetup(method, url string, body []byte, out any) error {
+	ctx, cancel := context.WithTimeout(context.Background(), setupSlackRequestTimeout)
+	defer cancel()
+	var reader io.Reader
+	if body != nil {
+		reader = bytes.NewReader(body)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, url, reader)
+	if err != nil {
+		return err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	b, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("signal-cli-rest-api status %d: %s", resp.StatusCode, strings.TrimSpace(string(b)))
+	}
+	if out == nil {
+		return nil
+	}
+	return json.Unmarshal(b, out)
+}
@@ -0,0 +1,167 @@
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/AlhasanIQ/consult-human/config"
+)
+
+const setupSlackRequestTimeout = 15 * time.Second
+
+type slackSetupIdentity struct {
+	Team string
+	User string
+}
+
+var slackSetupAuthTestFn = func(token string) (slackSetupIdentity, error) {
+	var decoded struct {
+		Team string `json:"team"`
+		User string `json:"user"`
+	}
+	if err := postSlackSetup(token, "auth.test", url.Values{}, &decoded); err != nil {
+		return slackSetupIdentity{}, err
+	}
+	return slackSetupIdentity{Team: decoded.Team, User: decoded.User}, nil
+}
+
+var slackSetupPostFn = func(token, channel, text string) error {
+	return postSlackSetup(token, "chat.postMessage", url.Values{"channel": {channel}, "text": {text}}, nil)
+}
+
+var errSlackSetupTokenRejected = errors.New("token rejected by Slack, double-check the Bot User OAuth Token (xoxb-...)")
+
+func runSlackSetup(reader *bufio.Reader, s *sty, cfg *config.Config, skipVerify bool) error {
+	s.section("Slack")
+
+	token := strings.TrimSpace(cfg.Slack.BotToken)
+	if token != "" {
+		s.info(s.dim("Using saved Slack bot token from config."))
+	} else {
+		fmt.Fprintf(s.w, "  Create a Slack app and get its bot token:\n\n")
+		s.step(1, "Create an app at "+s.bold("https://api.slack.com/apps"))
+		s.step(2, "Add the bot scopes "+s.bold("chat:write")+" and "+s.bold("channels:history")+" (groups:history for private channels)")
+		s.step(3, "Install it to your workspace and copy the Bot User OAuth Token (xoxb-...)")
+		fmt.Fprintln(s.w)
+	}
+
+	for {
+		if token == "" {
+			line, err := promptRequiredLine(reader, s, s.promptLabel("Bot token: "))
+			if err != nil {
+				return err
+			}
+			token = line
+		}
+		if err := config.Set(cfg, "slack.bot_token", token); err != nil {
+			s.errMsg(err.Error())
+			token = ""
+			continue
+		}
+		if skipVerify {
+			break
+		}
+
+		identity, err := slackSetupAuthTestFn(token)
+		if errors.Is(err, errSlackSetupTokenRejected) {
+			s.errMsg(err.Error())
+			token = ""
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("could not verify slack bot token (use --skip-verify when offline): %w", err)
+		}
+		fmt.Fprintln(s.w)
+		s.success(fmt.Sprintf("Token OK — bot is @%s in %s", identity.User, identity.Team))
+		break
+	}
+
+	fmt.Fprintln(s.w)
+	s.info("Invite the bot to the channel questions should go to (" + s.bold("/invite @<bot>") + "), then copy")
+	s.info("the channel ID from the channel details; it starts with C (or G for private channels).")
+	for {
+		channel, err := promptRequiredLine(reader, s, s.promptLabel("Channel ID: "))
+		if err != nil {
+			return err
+		}
+		cfg.Slack.Channel = channel
+		if skipVerify {
+			return nil
+		}
+		host, err := askHostnameFn()
+		if err != nil || host == "" {
+			host = "this machine"
+		}
+		text := fmt.Sprintf("This channel is now linked to consult-human on %s.", host)
+		if err := slackSetupPostFn(cfg.Slack.BotToken, channel, text); err != nil {
+			s.errMsg(fmt.Sprintf("Could not post to %s: %v. Check the ID and that the bot was invited.", channel, err))
+			continue
+		}
+		s.success(fmt.Sprintf("Posted a test message to %s", channel))
+		return nil
+	}
+}
+
+func writeSlackChecklist(w io.Writer, alreadySetup bool) {
+	if alreadySetup {
+		fmt.Fprintln(w, "Slack (already set up):")
+		fmt.Fprintln(w, "  Status: already configured.")
+		fmt.Fprintln(w, "  Reconfigure first: `consult-human config reset --provider slack`.")
+		fmt.Fprintln(w, "  Fresh setup steps (if reconfiguring):")
+	} else {
+		fmt.Fprintln(w, "Slack:")
+	}
+	fmt.Fprintln(w, "  Step 1: Create an app at https://api.slack.com/apps with the chat:write and channels:history (groups:history for private channels) bot scopes.")
+	fmt.Fprintln(w, "  Step 2: Install it to the workspace and run `consult-human config set slack.bot_token \"<xoxb-TOKEN>\"`.")
+	fmt.Fprintln(w, "  Step 3: Invite the bot to a channel with /invite and run `consult-human config set slack.channel <CHANNEL_ID>`.")
+	fmt.Fprintln(w)
+}
+
+func postSlackSetup(token, method string, params url.Values, out any) error {
+	ctx, cancel := context.WithTimeout(context.Background(), setupSlackRequestTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, config.DefaultSlackAPIBaseURL+"/"+method, strings.NewReader(params.Encode()))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("slack %s status %d: %s", method, resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+	var status struct {
+		OK    bool   `json:"ok"`
+		Error string `json:"error"`
+	}
+	if err := json.Unmarshal(body, &status); err != nil {
+		return err
+	}
+	if !status.OK {
+		if status.Error == "invalid_auth" || status.Error == "not_authed" || status.Error == "account_inactive" {
+			return errSlackSetupTokenRejected
+		}
+		return fmt.Errorf("slack %s failed: %s", method, status.Error)
+	}
+	if out == nil {
+		return nil
+	}
+	return json.Unmarshal(body, out)
+}
@@ -4,71 +4,449 @@ import (
 	"bufio"
 	"bytes"
 	"context"
+	"crypto/rand"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"math/big"
 	"net/http"
 	"strings"
 	"time"
 
 	"github.com/AlhasanIQ/consult-human/config"
+	"github.com/AlhasanIQ/consult-human/contract"
 )
 
-const setupTelegramLinkTimeout = 2 * time.Minute
+const (
+	setupTelegramLinkTimeout    = 2 * time.Minute
+	setupTelegramWebhookTimeout = 15 * time.Second
+	setupTelegramTestTimeout    = 2 * time.Minute
+)
+
+const setupTelegramTestQuestion = "consult-human test: reply with anything to confirm"
+
+const setupTelegramVerifyAttempts = 3
 
 var telegramSetupLinkFn = waitForTelegramStartForSetup
 
-func runTelegramSetup(reader *bufio.Reader, s *sty, cfg *config.Config) error {
+var telegramSetupStartCodeFn = newTelegramStartCode
+
+var telegramSetupVerifyCodeFn = newTelegramVerifyCode
+
+var telegramSetupSendFn = func(apiBaseURL, token string, chatID int64, text string) error {
+	return sendTelegramSetupMessage(telegramSetupBaseURL(apiBaseURL, token), chatID, text)
+}
+
+var telegramSetupGetMeFn = func(apiBaseURL, token string) (string, error) {
+	return getTelegramSetupBotUsername(telegramSetupBaseURL(apiBaseURL, token))
+}
+
+var telegramSetupGetChatFn = func(apiBaseURL, token, chat string) (telegramSetupChat, error) {
+	return getTelegramSetupChat(telegramSetupBaseURL(apiBaseURL, token), chat)
+}
+
+var errTelegramSetupChatNotFound = errors.New("chat not found, or the bot is not a member of it")
+
+var errTelegramSetupTokenRejected = errors.New("token rejected by Telegram, double-check the value from @BotFather")
+
+var errTelegramSetupWebhookActive = errors.New("telegram webhook is configured; disable webhook mode before running setup")
+
+var (
+	telegramSetupWebhookURLFn = func(apiBaseURL, token string) (string, error) {
+		return getTelegramSetupWebhookURL(telegramSetupBaseURL(apiBaseURL, token))
+	}
+	telegramSetupDeleteWebhookFn = func(apiBaseURL, token string) error {
+		return deleteTelegramSetupWebhook(telegramSetupBaseURL(apiBaseURL, token))
+	}
+)
+
+type telegramSetupLink struct {
+	ChatID   int64
+	UserID   int64
+	Username string
+}
+
+// Telegram gives groups negative chat IDs.
+func (l telegramSetupLink) isGroup() bool {
+	return l.ChatID < 0 && l.UserID != 0
+}
+
+func (l telegramSetupLink) senderLabel() string {
+	if l.Username != "" {
+		return fmt.Sprintf("@%s (user %d)", l.Username, l.UserID)
+	}
+	return fmt.Sprintf("user %d", l.UserID)
+}
+
+func runTelegramSetup(reader *bufio.Reader, s *sty, cfg *config.Config, skipVerify bool) error {
 	s.section("Telegram")
 
-	defaultToken := strings.TrimSpace(cfg.Telegram.BotToken)
-	var token string
-	if defaultToken == "" {
+	token := strings.TrimSpace(cfg.Telegram.BotToken)
+	if token != "" {
+		s.info(s.dim("Using saved Telegram bot token from config."))
+	} else {
 		fmt.Fprintf(s.w, "  Create a bot and get your token:\n\n")
 		s.step(1, "Open Telegram and chat with "+s.bold("@BotFather"))
 		s.step(2, "Send "+s.bold("/newbot")+" and follow the prompts")
 		s.step(3, "Copy the bot token")
 		fmt.Fprintln(s.w)
+	}
+
+	var botUsername string
+	for {
+		if token == "" {
+			line, err := promptRequiredLine(reader, s, s.promptLabel("Bot token: "))
+			if err != nil {
+				return err
+			}
+			token = line
+		}
+		cfg.Telegram.BotToken = token
+		if skipVerify {
+			break
+		}
 
-		line, err := promptRequiredLine(reader, s, s.promptLabel("Bot token: "))
+		username, err := verifyTelegramSetupToken(*cfg)
+		if errors.Is(err, errTelegramSetupTokenRejected) {
+			s.errMsg(err.Error())
+			token = ""
+			continue
+		}
 		if err != nil {
 			return err
 		}
-		token = line
-	} else {
-		token = defaultToken
-		s.info(s.dim("Using saved Telegram bot token from config."))
+		fmt.Fprintln(s.w)
+		s.success(fmt.Sprintf("Token OK — bot is @%s", username))
+		botUsername = username
+		break
+	}
+
+	link, err := linkTelegramChatForSetup(s, *cfg, botUsername, func(webhookURL string) (bool, error) {
+		answer, err := promptLine(reader, s.w, s.promptLabel(fmt.Sprintf("A webhook is registered at %s; remove it so consult-human can poll? [y/N]: ", webhookURL)))
+		if err != nil {
+			return false, err
+		}
+		a := strings.ToLower(answer)
+		return a == "y" || a == "yes", nil
+	})
+	if err != nil {
+		return fmt.Errorf("could not link Telegram chat: %w", err)
+	}
+
+	if err := verifyTelegramSetupLink(reader, s, *cfg, link); err != nil {
+		return fmt.Errorf("could not link Telegram chat: %w", err)
+	}
+	cfg.Telegram.ChatID = link.ChatID
+	s.success(fmt.Sprintf("Linked to chat %d", link.ChatID))
+	confirmTelegramSetupLink(s, *cfg, link)
+
+	if link.isGroup() {
+		explainTelegramGroupMode(s, *cfg)
+		answer, err := promptLine(reader, s.w, s.promptLabel(fmt.Sprintf("Only accept answers from %s? [Y/n]: ", link.senderLabel())))
+		if err != nil {
+			return err
+		}
+		if a := strings.ToLower(answer); a == "" || a == "y" || a == "yes" {
+			cfg.Telegram.AllowedUserIDs = []int64{link.UserID}
+			s.success(fmt.Sprintf("Only %s can answer", link.senderLabel()))
+		}
+	}
+	return nil
+}
+
+func verifyTelegramSetupToken(cfg config.Config) (string, error) {
+	apiBaseURL, err := config.EffectiveTelegramAPIBaseURL(cfg)
+	if err != nil {
+		return "", err
+	}
+	username, err := telegramSetupGetMeFn(apiBaseURL, cfg.Telegram.BotToken)
+	if err != nil && !errors.Is(err, errTelegramSetupTokenRejected) {
+		return "", fmt.Errorf("could not verify telegram bot token (use --skip-verify when offline): %w", err)
+	}
+	return username, err
+}
+
+func explainTelegramGroupMode(s *sty, cfg config.Config) {
+	s.info("This is a group chat. With privacy mode on (the @BotFather default) the bot only sees")
+	s.info("replies to its messages, @mentions and commands; with it off it sees every message.")
+	if !cfg.Telegram.GroupMode {
+		s.info("To count only replies to a question or @mentions of the bot as answers, run:")
+		s.info("  consult-human config set telegram.group_mode true")
+	}
+}
+
+// The one-time code stops another setup run on the same bot from taking the link.
+func linkTelegramChatForSetup(s *sty, cfg config.Config, botUsername string, removeWebhook func(webhookURL string) (bool, error)) (telegramSetupLink, error) {
+	apiBaseURL, err := config.EffectiveTelegramAPIBaseURL(cfg)
+	if err != nil {
+		return telegramSetupLink{}, err
 	}
-	cfg.Telegram.BotToken = token
+	token := cfg.Telegram.BotToken
 
+	code, err := telegramSetupStartCodeFn()
+	if err != nil {
+		return telegramSetupLink{}, err
+	}
+	expires := time.Now().Add(setupTelegramLinkTimeout)
 	fmt.Fprintln(s.w)
-	fmt.Fprintf(s.w, "  Now send %s to your bot from the chat you want to use.\n\n", s.bold("/start"))
+	if botUsername != "" {
+		fmt.Fprintf(s.w, "  Open %s from the chat you want to use and press Start,\n", s.bold(telegramStartLink(botUsername, code)))
+		fmt.Fprintf(s.w, "  or send %s to your bot there.\n\n", s.bold("/start "+code))
+	} else {
+		fmt.Fprintf(s.w, "  Now send %s to your bot from the chat you want to use.\n\n", s.bold("/start "+code))
+	}
 
 	sp := s.startSpinner("Waiting for /start message...")
-	chatID, err := telegramSetupLinkFn(cfg.Telegram.BotToken, setupTelegramLinkTimeout, s.w)
+	link, err := telegramSetupLinkFn(apiBaseURL, token, code, setupTelegramLinkTimeout, s.w)
+	sp.stop()
+	if !errors.Is(err, errTelegramSetupWebhookActive) {
+		return link, err
+	}
+
+	webhookURL, lookupErr := telegramSetupWebhookURLFn(apiBaseURL, token)
+	if lookupErr != nil {
+		return telegramSetupLink{}, fmt.Errorf("%w (webhook lookup failed: %v)", err, lookupErr)
+	}
+	remove, promptErr := removeWebhook(webhookURL)
+	if promptErr != nil {
+		return telegramSetupLink{}, promptErr
+	}
+	if !remove {
+		return telegramSetupLink{}, err
+	}
+	if err := telegramSetupDeleteWebhookFn(apiBaseURL, token); err != nil {
+		return telegramSetupLink{}, err
+	}
+	s.success(fmt.Sprintf("Removed webhook %s", webhookURL))
+
+	sp = s.startSpinner("Waiting for /start message...")
+	link, err = telegramSetupLinkFn(apiBaseURL, token, code, time.Until(expires), s.w)
 	sp.stop()
+	return link, err
+}
+
+// The typed-back code proves the chat that sent /start is the operator's.
+func verifyTelegramSetupLink(reader *bufio.Reader, s *sty, cfg config.Config, link telegramSetupLink) error {
+	apiBaseURL, err := config.EffectiveTelegramAPIBaseURL(cfg)
 	if err != nil {
-		return fmt.Errorf("could not link Telegram chat: %w", err)
+		return err
+	}
+	code, err := telegramSetupVerifyCodeFn()
+	if err != nil {
+		return err
+	}
+	text := fmt.Sprintf("consult-human verification code: %s\nType it into the terminal running setup.", code)
+	if err := telegramSetupSendFn(apiBaseURL, cfg.Telegram.BotToken, link.ChatID, text); err != nil {
+		return fmt.Errorf("could not send the verification code: %w", err)
+	}
+
+	s.info(fmt.Sprintf("Got /start from chat %d. A verification code was sent there.", link.ChatID))
+	for attempt := 1; ; attempt++ {
+		answer, err := promptRequiredLine(reader, s, s.promptLabel("Verification code: "))
+		if err != nil {
+			return err
+		}
+		if answer == code {
+			return nil
+		}
+		if attempt == setupTelegramVerifyAttempts {
+			return fmt.Errorf("verification code did not match; chat %d was not linked", link.ChatID)
+		}
+		s.errMsg("That code does not match; check the latest message from the bot.")
 	}
+}
 
-	cfg.Telegram.ChatID = chatID
-	s.success(fmt.Sprintf("Linked to chat %d", chatID))
+func checkTelegramSetupExpectedUser(link telegramSetupLink, expectUser int64) error {
+	if link.UserID != expectUser {
+		return fmt.Errorf("/start came from %s, not the expected user %d; chat %d was not linked", link.senderLabel(), expectUser, link.ChatID)
+	}
 	return nil
 }
 
-func waitForTelegramStartForSetup(token string, timeout time.Duration, w io.Writer) (int64, error) {
+// Best-effort: the link is already saved.
+func confirmTelegramSetupLink(s *sty, cfg config.Config, link telegramSetupLink) {
+	apiBaseURL, err := config.EffectiveTelegramAPIBaseURL(cfg)
+	if err != nil {
+		return
+	}
+	host, err := askHostnameFn()
+	if err != nil || host == "" {
+		host = "this machine"
+	}
+	text := fmt.Sprintf("This chat is now linked to consult-human on %s.", host)
+	if err := telegramSetupSendFn(apiBaseURL, cfg.Telegram.BotToken, link.ChatID, text); err != nil {
+		s.info(s.dim(fmt.Sprintf("Could not send the confirmation message: %v", err)))
+	}
+}
+
+func newTelegramVerifyCode() (string, error) {
+	n, err := rand.Int(rand.Reader, big.NewInt(10000))
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%04d", n.Int64()), nil
+}
+
+// Telegram allows up to 64 characters from [A-Za-z0-9_-] in a /start payload.
+func newTelegramStartCode() (string, error) {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+func telegramStartLink(botUsername, code string) string {
+	return fmt.Sprintf("https://t.me/%s?start=%s", strings.TrimPrefix(botUsername, "@"), code)
+}
+
+func telegramSetupBaseURL(apiBaseURL, token string) string {
+	return fmt.Sprintf("%s/bot%s", apiBaseURL, strings.TrimSpace(token))
+}
+
+func waitForTelegramStartForSetup(apiBaseURL, token, code string, timeout time.Duration, w io.Writer) (telegramSetupLink, error) {
 	token = strings.TrimSpace(token)
 	if token == "" {
-		return 0, fmt.Errorf("missing telegram token")
+		return telegramSetupLink{}, fmt.Errorf("missing telegram token")
+	}
+	return waitForTelegramStartWithBaseURL(telegramSetupBaseURL(apiBaseURL, token), code, timeout, w)
+}
+
+func getTelegramSetupWebhookURL(baseURL string) (string, error) {
+	var decoded struct {
+		OK     bool `json:"ok"`
+		Result struct {
+			URL string `json:"url"`
+		} `json:"result"`
+	}
+	if err := postTelegramSetup(baseURL, "getWebhookInfo", "{}", &decoded); err != nil {
+		return "", err
+	}
+	if !decoded.OK {
+		return "", fmt.Errorf("telegram getWebhookInfo failed")
+	}
+	return strings.TrimSpace(decoded.Result.URL), nil
+}
+
+func getTelegramSetupBotUsername(baseURL string) (string, error) {
+	var decoded struct {
+		OK     bool `json:"ok"`
+		Result struct {
+			Username string `json:"username"`
+		} `json:"result"`
+	}
+	if err := postTelegramSetup(baseURL, "getMe", "{}", &decoded); err != nil {
+		return "", err
+	}
+	if !decoded.OK {
+		return "", fmt.Errorf("telegram getMe failed")
+	}
+	return decoded.Result.Username, nil
+}
+
+type telegramSetupChat struct {
+	ID       int64  `json:"id"`
+	Type     string `json:"type"`
+	Title    string `json:"title"`
+	Username string `json:"username"`
+}
+
+func (c telegramSetupChat) label() string {
+	name := c.Title
+	if name == "" && c.Username != "" {
+		name = "@" + c.Username
+	}
+	if name == "" {
+		return c.Type
+	}
+	return fmt.Sprintf("%s %q", c.Type, name)
+}
+
+func getTelegramSetupChat(baseURL, chat string) (telegramSetupChat, error) {
+	body, err := json.Marshal(map[string]string{"chat_id": chat})
+	if err != nil {
+		return telegramSetupChat{}, err
+	}
+	var decoded struct {
+		OK     bool              `json:"ok"`
+		Result telegramSetupChat `json:"result"`
 	}
-	baseURL := fmt.Sprintf("https://api.telegram.org/bot%s", token)
-	return waitForTelegramStartWithBaseURL(baseURL, timeout, w)
+	if err := postTelegramSetup(baseURL, "getChat", string(body), &decoded); err != nil {
+		// Telegram answers 400 "chat not found" for chats the bot cannot see.
+		if strings.Contains(err.Error(), "status 400") {
+			return telegramSetupChat{}, errTelegramSetupChatNotFound
+		}
+		return telegramSetupChat{}, err
+	}
+	if !decoded.OK {
+		return telegramSetupChat{}, fmt.Errorf("telegram getChat failed")
+	}
+	return decoded.Result, nil
 }
 
-func waitForTelegramStartWithBaseURL(baseURL string, timeout time.Duration, w io.Writer) (int64, error) {
+func sendTelegramSetupMessage(baseURL string, chatID int64, text string) error {
+	body, err := json.Marshal(map[string]any{"chat_id": chatID, "text": text})
+	if err != nil {
+		return err
+	}
+	var decoded struct {
+		OK bool `json:"ok"`
+	}
+	if err := postTelegramSetup(baseURL, "sendMessage", string(body), &decoded); err != nil {
+		return err
+	}
+	if !decoded.OK {
+		return fmt.Errorf("telegram sendMessage failed")
+	}
+	return nil
+}
+
+// Queued updates are kept so they reach polling instead.
+func deleteTelegramSetupWebhook(baseURL string) error {
+	var decoded struct {
+		OK bool `json:"ok"`
+	}
+	if err := postTelegramSetup(baseURL, "deleteWebhook", `{"drop_pending_updates":false}`, &decoded); err != nil {
+		return err
+	}
+	if !decoded.OK {
+		return fmt.Errorf("telegram deleteWebhook failed")
+	}
+	return nil
+}
+
+func postTelegramSetup(baseURL, method, body string, out any) error {
+	ctx, cancel := context.WithTimeout(context.Background(), setupTelegramWebhookTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, strings.TrimRight(baseURL, "/")+"/"+method, strings.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		b, _ := io.ReadAll(io.LimitReader(resp.Body, 2048))
+		// Telegram answers 401 for an unknown token and 404 for a malformed one.
+		if resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusNotFound {
+			return errTelegramSetupTokenRejected
+		}
+		return fmt.Errorf("telegram %s status %d: %s", method, resp.StatusCode, strings.TrimSpace(string(b)))
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+// A plain /start, or one with another code, is ignored.
+func waitForTelegramStartWithBaseURL(baseURL, code string, timeout time.Duration, w io.Writer) (telegramSetupLink, error) {
 	baseURL = strings.TrimSpace(baseURL)
 	if baseURL == "" {
-		return 0, fmt.Errorf("missing telegram api base URL")
+		return telegramSetupLink{}, fmt.Errorf("missing telegram api base URL")
 	}
 
 	ctx, cancel := context.WithTimeout(context.Background(), timeout)
@@ -80,16 +458,16 @@ func waitForTelegramStartWithBaseURL(baseURL string, timeout time.Duration, w io
 	for {
 		select {
 		case <-ctx.Done():
-			return 0, fmt.Errorf("timed out waiting for /start")
+			return telegramSetupLink{}, fmt.Errorf("timed out waiting for /start")
 		default:
 		}
 
 		updates, nextOffset, err := fetchTelegramSetupUpdates(ctx, client, baseURL, offset)
 		if err != nil {
 			if ctx.Err() != nil {
-				return 0, fmt.Errorf("timed out waiting for /start")
+				return telegramSetupLink{}, fmt.Errorf("timed out waiting for /start")
 			}
-			return 0, err
+			return telegramSetupLink{}, err
 		}
 		offset = nextOffset
 
@@ -97,13 +475,17 @@ func waitForTelegramStartWithBaseURL(baseURL string, timeout time.Duration, w io
 			if up.Message == nil {
 				continue
 			}
-			if !isSetupTelegramStartCommand(up.Message.Text) {
+			if payload, ok := setupTelegramStartPayload(up.Message.Text); !ok || payload != code {
 				continue
 			}
 			if up.Message.Chat.ID == 0 {
 				continue
 			}
-			return up.Message.Chat.ID, nil
+			link := telegramSetupLink{ChatID: up.Message.Chat.ID}
+			if from := up.Message.From; from != nil {
+				link.UserID, link.Username = from.ID, from.Username
+			}
+			return link, nil
 		}
 	}
 }
@@ -138,7 +520,7 @@ func fetchTelegramSetupUpdates(ctx context.Context, client *http.Client, baseURL
 		b, _ := io.ReadAll(io.LimitReader(resp.Body, 2048))
 		bodyText := strings.TrimSpace(string(b))
 		if strings.Contains(strings.ToLower(bodyText), "webhook") {
-			return nil, offset, fmt.Errorf("telegram webhook is configured; disable webhook mode before running setup")
+			return nil, offset, errTelegramSetupWebhookActive
 		}
 		return nil, offset, fmt.Errorf("telegram getUpdates status %d: %s", resp.StatusCode, bodyText)
 	}
@@ -160,16 +542,83 @@ func fetchTelegramSetupUpdates(ctx context.Context, client *http.Client, baseURL
 	return decoded.Result, nextOffset, nil
 }
 
-func isSetupTelegramStartCommand(text string) bool {
-	t := strings.ToLower(strings.TrimSpace(text))
-	if t == "" {
-		return false
+func checkTelegramRoundTrip(ctx context.Context, cfg config.Config) (time.Duration, error) {
+	if strings.TrimSpace(cfg.Telegram.BotToken) == "" {
+		return 0, fmt.Errorf("telegram.bot_token is required; run `consult-human config set telegram.bot_token \"<BOT_TOKEN>\"` first")
 	}
-	token := strings.Fields(t)[0]
-	if token == "/start" {
-		return true
+	if cfg.Telegram.ChatID == 0 {
+		return 0, fmt.Errorf("telegram chat is not linked yet; run `consult-human setup --link-chat` first")
 	}
-	return strings.HasPrefix(token, "/start@") && len(token) > len("/start@")
+
+	p, err := askProviderFn(cfg, setupProviderTelegram)
+	if err != nil {
+		return 0, err
+	}
+	defer p.Close()
+
+	reqID, err := newRequestID()
+	if err != nil {
+		return 0, err
+	}
+	req := contract.AskRequest{
+		RequestID: reqID,
+		Question:  setupTelegramTestQuestion,
+		Type:      contract.QuestionTypeOpen,
+		SentAt:    time.Now().UTC(),
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, setupTelegramTestTimeout)
+	defer cancel()
+
+	started := time.Now()
+	if _, err := p.Send(ctx, req); err != nil {
+		return 0, telegramRoundTripError(cfg, "send the test message", err)
+	}
+	if _, err := p.Receive(ctx, req.RequestID); err != nil {
+		if errors.Is(err, context.DeadlineExceeded) {
+			notifyAskTimeout(io.Discard, p, req.RequestID, setupTelegramTestTimeout)
+		} else {
+			withdrawAsk(io.Discard, p, req.RequestID)
+		}
+		return 0, telegramRoundTripError(cfg, "receive a reply", err)
+	}
+	return time.Since(started), nil
+}
+
+func telegramRoundTripError(cfg config.Config, step string, err error) error {
+	msg := strings.ToLower(err.Error())
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		hint := "reply to the test message in Telegram within " + setupTelegramTestTimeout.String()
+		if cfg.Telegram.ReceiveMode == config.TelegramReceiveModeWebhook {
+			hint += ", and make sure `consult-human serve telegram-webhook` is running"
+		}
+		return fmt.Errorf("no reply to the test message in chat %d; %s", cfg.Telegram.ChatID, hint)
+	case strings.Contains(msg, "webhook"):
+		return fmt.Errorf("could not %s because of a webhook conflict: %w", step, err)
+	case strings.Contains(msg, "chat not found"):
+		return fmt.Errorf("telegram chat %d was not found; re-link it with `consult-human setup --link-chat`: %w", cfg.Telegram.ChatID, err)
+	case strings.Contains(msg, "status 403"):
+		return fmt.Errorf("the bot cannot message chat %d; unblock it or add it back to the group, then re-run `consult-human setup --link-chat`: %w", cfg.Telegram.ChatID, err)
+	case strings.Contains(msg, "status 401") || strings.Contains(msg, "status 404"):
+		return fmt.Errorf("telegram rejected the bot token; check telegram.bot_token: %w", err)
+	}
+	return fmt.Errorf("could not %s: %w", step, err)
+}
+
+func setupTelegramStartPayload(text string) (string, bool) {
+	fields := strings.Fields(text)
+	if len(fields) == 0 {
+		return "", false
+	}
+	command := strings.ToLower(fields[0])
+	if command != "/start" && !(strings.HasPrefix(command, "/start@") && len(command) > len("/start@")) {
+		return "", false
+	}
+	if len(fields) < 2 {
+		return "", true
+	}
+	return fields[1], true
 }
 
 type setupTelegramGetUpdatesResponse struct {
@@ -183,8 +632,14 @@ type setupTelegramUpdate struct {
 }
 
 type setupTelegramMessage struct {
-	Text string            `json:"text"`
-	Chat setupTelegramChat `json:"chat"`
+	Text string             `json:"text"`
+	Chat setupTelegramChat  `json:"chat"`
+	From *setupTelegramUser `json:"from"`
+}
+
+type setupTelegramUser struct {
+	ID       int64  `json:"id"`
+	Username string `json:"username"`
 }
 
 type setupTelegramChat struct {
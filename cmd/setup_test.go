@@ -1,7 +1,11 @@
 package cmd
 
 import (
+	"bufio"
 	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
@@ -14,6 +18,7 @@ import (
 	"time"
 
 	"github.com/AlhasanIQ/consult-human/config"
+	"github.com/AlhasanIQ/consult-human/contract"
 )
 
 func stubSetupEnsureShellPath(t *testing.T) {
@@ -30,6 +35,28 @@ func stubSetupEnsureShellPath(t *testing.T) {
 	t.Cleanup(func() { setupEnsureShellPathFn = origEnsurePathFn })
 }
 
+func stubTelegramSetupGetMe(t *testing.T, fn func(apiBaseURL, token string) (string, error)) {
+	t.Helper()
+	orig := telegramSetupGetMeFn
+	telegramSetupGetMeFn = fn
+	t.Cleanup(func() { telegramSetupGetMeFn = orig })
+}
+
+// stubTelegramSetupSend records the messages setup sends to the linked chat
+// and fixes the verification code at "1234".
+func stubTelegramSetupSend(t *testing.T) *[]string {
+	t.Helper()
+	var sent []string
+	origSendFn, origCodeFn := telegramSetupSendFn, telegramSetupVerifyCodeFn
+	telegramSetupSendFn = func(apiBaseURL, token string, chatID int64, text string) error {
+		sent = append(sent, text)
+		return nil
+	}
+	telegramSetupVerifyCodeFn = func() (string, error) { return "1234", nil }
+	t.Cleanup(func() { telegramSetupSendFn, telegramSetupVerifyCodeFn = origSendFn, origCodeFn })
+	return &sent
+}
+
 func TestParseSetupProviderFlags(t *testing.T) {
 	got, err := parseSetupProviderFlags([]string{"telegram"})
 	if err != nil {
@@ -51,6 +78,27 @@ func TestParseSetupSkillTargetSelection(t *testing.T) {
 	}
 }
 
+func TestParseSetupSkillTargetSelectionEditors(t *testing.T) {
+	tests := map[string]string{
+		"4":     skillTargetCursor,
+		"5":     skillTargetWindsurf,
+		"1,5":   "claude,windsurf",
+		"4,2,1": "claude,codex,cursor",
+		"3,4":   skillTargetBoth,
+		"6":     skillTargetGemini,
+		"7,1":   "claude,opencode",
+	}
+	for in, want := range tests {
+		got, err := parseSetupSkillTargetSelection(in)
+		if err != nil {
+			t.Fatalf("parseSetupSkillTargetSelection(%q) returned error: %v", in, err)
+		}
+		if got != want {
+			t.Fatalf("parseSetupSkillTargetSelection(%q): want %q got %q", in, want, got)
+		}
+	}
+}
+
 func TestParseSetupSkillTargetSelectionInvalid(t *testing.T) {
 	if _, err := parseSetupSkillTargetSelection("x"); err == nil {
 		t.Fatalf("expected error for invalid selection")
@@ -83,29 +131,6 @@ func TestBuildSetupSkillScopeOptionsDefaultsToRepoWhenAgentDirExists(t *testing.
 	}
 }
 
-func TestFindGitRepoRoot(t *testing.T) {
-	root := t.TempDir()
-	repo := filepath.Join(root, "repo")
-	sub := filepath.Join(repo, "a", "b")
-	if err := os.MkdirAll(filepath.Join(repo, ".git"), 0o755); err != nil {
-		t.Fatalf("mkdir .git: %v", err)
-	}
-	if err := os.MkdirAll(sub, 0o755); err != nil {
-		t.Fatalf("mkdir sub: %v", err)
-	}
-
-	got, found, err := findGitRepoRoot(sub)
-	if err != nil {
-		t.Fatalf("findGitRepoRoot: %v", err)
-	}
-	if !found {
-		t.Fatalf("expected repo to be found")
-	}
-	if got != repo {
-		t.Fatalf("expected repo %q got %q", repo, got)
-	}
-}
-
 func TestParseSetupProviderFlagsRejectsInvalid(t *testing.T) {
 	if _, err := parseSetupProviderFlags([]string{"3"}); err == nil {
 		t.Fatalf("expected error for invalid option")
@@ -122,6 +147,7 @@ func TestRunSetupTelegramFlow(t *testing.T) {
 	cfgPath := filepath.Join(t.TempDir(), "config.yaml")
 	t.Setenv(config.EnvConfigPath, cfgPath)
 	stubSetupEnsureShellPath(t)
+	stubTelegramSetupGetMe(t, func(string, string) (string, error) { return "my_helper_bot", nil })
 
 	origSkillFn := setupSkillInstallFn
 	defer func() { setupSkillInstallFn = origSkillFn }()
@@ -135,11 +161,13 @@ func TestRunSetupTelegramFlow(t *testing.T) {
 	}
 
 	origLinkFn := telegramSetupLinkFn
-	telegramSetupLinkFn = func(token string, timeout time.Duration, w io.Writer) (int64, error) {
+	var linkCode string
+	telegramSetupLinkFn = func(apiBaseURL, token, code string, timeout time.Duration, w io.Writer) (telegramSetupLink, error) {
 		if token != "test-token" {
-			return 0, fmt.Errorf("unexpected token: %s", token)
+			return telegramSetupLink{}, fmt.Errorf("unexpected token: %s", token)
 		}
-		return 4242, nil
+		linkCode = code
+		return telegramSetupLink{ChatID: 4242}, nil
 	}
 	defer func() { telegramSetupLinkFn = origLinkFn }()
 
@@ -147,7 +175,8 @@ func TestRunSetupTelegramFlow(t *testing.T) {
 	setupCurrentDirFn = func() (string, error) { return t.TempDir(), nil }
 	defer func() { setupCurrentDirFn = origCurrentDirFn }()
 
-	input := strings.NewReader("test-token\n1\n1\n")
+	sent := stubTelegramSetupSend(t)
+	input := strings.NewReader("test-token\n1234\n1\n1\n")
 	var out bytes.Buffer
 	var errOut bytes.Buffer
 
@@ -174,6 +203,79 @@ func TestRunSetupTelegramFlow(t *testing.T) {
 	if strings.Contains(errOut.String(), "Next steps:") {
 		t.Fatalf("did not expect Next steps block in output, got: %q", errOut.String())
 	}
+	if linkCode == "" || !strings.Contains(errOut.String(), "https://t.me/my_helper_bot?start="+linkCode) {
+		t.Fatalf("expected a deep link carrying the start code %q, got: %q", linkCode, errOut.String())
+	}
+	if len(*sent) != 2 || !strings.Contains((*sent)[0], "1234") || !strings.HasPrefix((*sent)[1], "This chat is now linked to consult-human on ") {
+		t.Fatalf("expected a verification code and a confirmation in the chat, got %#v", *sent)
+	}
+}
+
+func TestRunSetupTelegramRepromptsForRejectedToken(t *testing.T) {
+	cfgPath := filepath.Join(t.TempDir(), "config.yaml")
+	t.Setenv(config.EnvConfigPath, cfgPath)
+	stubSetupEnsureShellPath(t)
+	stubTelegramSetupGetMe(t, func(apiBaseURL, token string) (string, error) {
+		if token != "good-token" {
+			return "", errTelegramSetupTokenRejected
+		}
+		return "my_helper_bot", nil
+	})
+
+	origSkillFn := setupSkillInstallFn
+	defer func() { setupSkillInstallFn = origSkillFn }()
+	setupSkillInstallFn = func(args []string, io IO) error { return nil }
+
+	origCurrentDirFn := setupCurrentDirFn
+	setupCurrentDirFn = func() (string, error) { return t.TempDir(), nil }
+	defer func() { setupCurrentDirFn = origCurrentDirFn }()
+
+	origLinkFn := telegramSetupLinkFn
+	telegramSetupLinkFn = func(apiBaseURL, token, code string, timeout time.Duration, w io.Writer) (telegramSetupLink, error) {
+		if token != "good-token" {
+			return telegramSetupLink{}, fmt.Errorf("unexpected token: %s", token)
+		}
+		return telegramSetupLink{ChatID: 4242}, nil
+	}
+	defer func() { telegramSetupLinkFn = origLinkFn }()
+
+	stubTelegramSetupSend(t)
+	var errOut bytes.Buffer
+	if err := runSetup(nil, IO{In: strings.NewReader("typo-token\ngood-token\n1234\n1\n1\n"), Out: &bytes.Buffer{}, ErrOut: &errOut}); err != nil {
+		t.Fatalf("runSetup returned error: %v", err)
+	}
+	if !strings.Contains(errOut.String(), "token rejected by Telegram, double-check the value from @BotFather") {
+		t.Fatalf("expected rejection message, got %q", errOut.String())
+	}
+	if !strings.Contains(errOut.String(), "Token OK — bot is @my_helper_bot") {
+		t.Fatalf("expected bot username, got %q", errOut.String())
+	}
+	cfg, err := config.Load()
+	if err != nil {
+		t.Fatalf("config.Load returned error: %v", err)
+	}
+	if cfg.Telegram.BotToken != "good-token" {
+		t.Fatalf("expected the verified token to be saved, got %q", cfg.Telegram.BotToken)
+	}
+}
+
+func TestGetTelegramSetupBotUsernameRejectsUnknownToken(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/botgood/getMe" {
+			_, _ = w.Write([]byte(`{"ok":true,"result":{"username":"my_helper_bot"}}`))
+			return
+		}
+		w.WriteHeader(http.StatusUnauthorized)
+		_, _ = w.Write([]byte(`{"ok":false,"error_code":401,"description":"Unauthorized"}`))
+	}))
+	defer srv.Close()
+
+	if got, err := getTelegramSetupBotUsername(telegramSetupBaseURL(srv.URL, "good")); err != nil || got != "my_helper_bot" {
+		t.Fatalf("expected my_helper_bot, got %q (err %v)", got, err)
+	}
+	if _, err := getTelegramSetupBotUsername(telegramSetupBaseURL(srv.URL, "typo")); !errors.Is(err, errTelegramSetupTokenRejected) {
+		t.Fatalf("expected token rejection, got %v", err)
+	}
 }
 
 func TestRunSetupRejectsAlreadyConfiguredProvider(t *testing.T) {
@@ -205,6 +307,7 @@ func TestRunSetupTelegramUsesSavedTokenWithoutPrompt(t *testing.T) {
 	cfgPath := filepath.Join(t.TempDir(), "config.yaml")
 	t.Setenv(config.EnvConfigPath, cfgPath)
 	stubSetupEnsureShellPath(t)
+	stubTelegramSetupGetMe(t, func(string, string) (string, error) { return "my_helper_bot", nil })
 
 	origCurrentDirFn := setupCurrentDirFn
 	setupCurrentDirFn = func() (string, error) { return t.TempDir(), nil }
@@ -226,21 +329,22 @@ func TestRunSetupTelegramUsesSavedTokenWithoutPrompt(t *testing.T) {
 	}
 
 	origLinkFn := telegramSetupLinkFn
-	telegramSetupLinkFn = func(token string, timeout time.Duration, w io.Writer) (int64, error) {
+	telegramSetupLinkFn = func(apiBaseURL, token, code string, timeout time.Duration, w io.Writer) (telegramSetupLink, error) {
 		if token != "saved-token" {
-			return 0, fmt.Errorf("unexpected token: %s", token)
+			return telegramSetupLink{}, fmt.Errorf("unexpected token: %s", token)
 		}
-		return 777, nil
+		return telegramSetupLink{ChatID: 777}, nil
 	}
 	defer func() { telegramSetupLinkFn = origLinkFn }()
 
+	stubTelegramSetupSend(t)
 	var out bytes.Buffer
 	var errOut bytes.Buffer
 
 	done := make(chan error, 1)
 	go func() {
 		done <- runSetup([]string{"--provider", "telegram"}, IO{
-			In:     strings.NewReader("1\n1\n"),
+			In:     strings.NewReader("1234\n1\n1\n"),
 			Out:    &out,
 			ErrOut: &errOut,
 		})
@@ -270,6 +374,54 @@ func TestRunSetupTelegramUsesSavedTokenWithoutPrompt(t *testing.T) {
 	}
 }
 
+func TestRunSetupTelegramGroupOffersAllowlist(t *testing.T) {
+	cfgPath := filepath.Join(t.TempDir(), "config.yaml")
+	t.Setenv(config.EnvConfigPath, cfgPath)
+	stubSetupEnsureShellPath(t)
+	stubTelegramSetupGetMe(t, func(string, string) (string, error) { return "my_helper_bot", nil })
+
+	origCurrentDirFn := setupCurrentDirFn
+	setupCurrentDirFn = func() (string, error) { return t.TempDir(), nil }
+	defer func() { setupCurrentDirFn = origCurrentDirFn }()
+
+	origSkillFn := setupSkillInstallFn
+	defer func() { setupSkillInstallFn = origSkillFn }()
+	setupSkillInstallFn = func(args []string, io IO) error { return nil }
+
+	cfg := config.Default()
+	cfg.Telegram.BotToken = "saved-token"
+	if err := config.Save(cfg); err != nil {
+		t.Fatalf("config.Save returned error: %v", err)
+	}
+
+	origLinkFn := telegramSetupLinkFn
+	telegramSetupLinkFn = func(apiBaseURL, token, code string, timeout time.Duration, w io.Writer) (telegramSetupLink, error) {
+		return telegramSetupLink{ChatID: -100123, UserID: 42, Username: "alice"}, nil
+	}
+	defer func() { telegramSetupLinkFn = origLinkFn }()
+
+	stubTelegramSetupSend(t)
+	var out, errOut bytes.Buffer
+	if err := runSetup([]string{"--provider", "telegram"}, IO{
+		In:     strings.NewReader("1234\n\n1\n1\n"),
+		Out:    &out,
+		ErrOut: &errOut,
+	}); err != nil {
+		t.Fatalf("runSetup returned error: %v", err)
+	}
+
+	updated, err := config.Load()
+	if err != nil {
+		t.Fatalf("config.Load returned error: %v", err)
+	}
+	if len(updated.Telegram.AllowedUserIDs) != 1 || updated.Telegram.AllowedUserIDs[0] != 42 {
+		t.Fatalf("expected the /start sender to be allowlisted, got %#v", updated.Telegram.AllowedUserIDs)
+	}
+	if !strings.Contains(errOut.String(), "Only accept answers from @alice (user 42)?") {
+		t.Fatalf("expected allowlist prompt, got: %q", errOut.String())
+	}
+}
+
 func TestRunSetupRejectsWhatsAppProvider(t *testing.T) {
 	cfgPath := filepath.Join(t.TempDir(), "config.yaml")
 	t.Setenv(config.EnvConfigPath, cfgPath)
@@ -287,181 +439,1096 @@ func TestRunSetupRejectsWhatsAppProvider(t *testing.T) {
 	}
 }
 
-func TestRunSetupNonInteractiveChecklistTelegram(t *testing.T) {
+func TestRunSetupSkillInstallInteractivePreview(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  [][]string
+	}{
+		{name: "no preview", input: "1\n1\n\n", want: [][]string{{"--target", "claude"}}},
+		{name: "preview then install", input: "1\n1\ny\n\n", want: [][]string{{"--target", "claude", "--dry-run"}, {"--target", "claude"}}},
+		{name: "preview then decline", input: "1\n1\ny\nn\n", want: [][]string{{"--target", "claude", "--dry-run"}}},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Setenv("HOME", t.TempDir())
+			origSkillFn, origCurrentDirFn := setupSkillInstallFn, setupCurrentDirFn
+			var calls [][]string
+			setupSkillInstallFn = func(args []string, io IO) error {
+				calls = append(calls, args)
+				return nil
+			}
+			setupCurrentDirFn = func() (string, error) { return t.TempDir(), nil }
+			defer func() { setupSkillInstallFn, setupCurrentDirFn = origSkillFn, origCurrentDirFn }()
+
+			var errOut bytes.Buffer
+			reader := bufio.NewReader(strings.NewReader(tc.input))
+			if err := runSetupSkillInstallInteractive(reader, newSty(&errOut), IO{In: strings.NewReader(""), Out: &bytes.Buffer{}, ErrOut: &errOut}); err != nil {
+				t.Fatalf("runSetupSkillInstallInteractive returned error: %v", err)
+			}
+			if !reflect.DeepEqual(calls, tc.want) {
+				t.Fatalf("want skill install calls %#v, got %#v", tc.want, calls)
+			}
+		})
+	}
+}
+
+func TestRunSetupInteractiveSlack(t *testing.T) {
 	cfgPath := filepath.Join(t.TempDir(), "config.yaml")
 	t.Setenv(config.EnvConfigPath, cfgPath)
 	stubSetupEnsureShellPath(t)
 
-	var out bytes.Buffer
-	var errOut bytes.Buffer
+	origSkillFn, origCurrentDirFn := setupSkillInstallFn, setupCurrentDirFn
+	setupSkillInstallFn = func(args []string, io IO) error { return nil }
+	setupCurrentDirFn = func() (string, error) { return t.TempDir(), nil }
+	origAuthFn, origPostFn := slackSetupAuthTestFn, slackSetupPostFn
+	slackSetupAuthTestFn = func(token string) (slackSetupIdentity, error) {
+		if token != "xoxb-good" {
+			return slackSetupIdentity{}, errSlackSetupTokenRejected
+		}
+		return slackSetupIdentity{Team: "Acme", User: "helper"}, nil
+	}
+	var posted []string
+	slackSetupPostFn = func(token, channel, text string) error {
+		if channel != "C123" {
+			return fmt.Errorf("slack chat.postMessage failed: channel_not_found")
+		}
+		posted = append(posted, text)
+		return nil
+	}
+	defer func() {
+		setupSkillInstallFn, setupCurrentDirFn = origSkillFn, origCurrentDirFn
+		slackSetupAuthTestFn, slackSetupPostFn = origAuthFn, origPostFn
+	}()
 
-	if err := runSetup([]string{"--non-interactive", "--provider", "telegram"}, IO{
-		In:     strings.NewReader(""),
-		Out:    &out,
-		ErrOut: &errOut,
-	}); err != nil {
+	var errOut bytes.Buffer
+	input := strings.NewReader("xoxp-user\nxoxb-stale\nxoxb-good\nCWRONG\nC123\n1\n1\n")
+	if err := runSetup([]string{"--provider", "slack"}, IO{In: input, Out: &bytes.Buffer{}, ErrOut: &errOut}); err != nil {
 		t.Fatalf("runSetup returned error: %v", err)
 	}
 
-	got := out.String()
-	if !strings.Contains(got, "Setup checklist (non-interactive)") {
-		t.Fatalf("expected checklist header, got: %q", got)
-	}
-	if !strings.Contains(got, "Shell PATH:") {
-		t.Fatalf("expected shell PATH status block, got: %q", got)
-	}
-	if !strings.Contains(got, "consult-human config set telegram.bot_token") {
-		t.Fatalf("expected telegram token command, got: %q", got)
-	}
-	if !strings.Contains(got, "consult-human setup --provider telegram --link-chat") {
-		t.Fatalf("expected telegram non-interactive link command, got: %q", got)
+	cfg, err := config.Load()
+	if err != nil {
+		t.Fatalf("config.Load returned error: %v", err)
 	}
-	if !strings.Contains(got, "consult-human config set default-provider telegram") {
-		t.Fatalf("expected default-provider command, got: %q", got)
+	if cfg.Slack.BotToken != "xoxb-good" || cfg.Slack.Channel != "C123" || cfg.ActiveProvider != setupProviderSlack {
+		t.Fatalf("unexpected config: slack=%#v active=%q", cfg.Slack, cfg.ActiveProvider)
 	}
-	if !strings.Contains(got, "consult-human skill install --target claude") {
-		t.Fatalf("expected skill install command, got: %q", got)
+	if len(posted) != 1 || !strings.HasPrefix(posted[0], "This channel is now linked to consult-human on ") {
+		t.Fatalf("expected one confirmation post, got %#v", posted)
 	}
-	if !strings.Contains(got, "Final step: install skill files for agent runtimes, and a reminder in CLAUDE.md/AGENTS.md (required):") {
-		t.Fatalf("expected required skill-install final step, got: %q", got)
+	for _, want := range []string{"starting with xoxb-", "token rejected by Slack", "Could not post to CWRONG", "bot is @helper in Acme"} {
+		if !strings.Contains(errOut.String(), want) {
+			t.Fatalf("expected %q in setup output, got: %q", want, errOut.String())
+		}
 	}
-	if !strings.Contains(got, "Ask your human whether to install globally or locally (repo-only).") {
-		t.Fatalf("expected ask-human scope guidance, got: %q", got)
+}
+
+func TestRunSetupNonInteractiveChecklistSlack(t *testing.T) {
+	cfgPath := filepath.Join(t.TempDir(), "config.yaml")
+	t.Setenv(config.EnvConfigPath, cfgPath)
+	stubSetupEnsureShellPath(t)
+
+	var out bytes.Buffer
+	if err := runSetup([]string{"--non-interactive", "--provider", "slack"}, IO{In: strings.NewReader(""), Out: &out, ErrOut: &bytes.Buffer{}}); err != nil {
+		t.Fatalf("runSetup returned error: %v", err)
 	}
-	if !strings.Contains(got, "Global install (all repos on this machine):") {
-		t.Fatalf("expected global install heading, got: %q", got)
+	got := out.String()
+	for _, want := range []string{"Slack:", "consult-human config set slack.bot_token", "consult-human config set slack.channel", "config set default-provider slack"} {
+		if !strings.Contains(got, want) {
+			t.Fatalf("expected %q in checklist, got: %q", want, got)
+		}
 	}
-	if !strings.Contains(got, "Local install (repo-only):") {
-		t.Fatalf("expected local install heading, got: %q", got)
+	if strings.Contains(got, "Telegram:") {
+		t.Fatalf("expected no telegram steps, got: %q", got)
 	}
 }
 
-func TestRunSetupLinkChatWithSavedToken(t *testing.T) {
+func TestRunSetupInteractiveSignal(t *testing.T) {
 	cfgPath := filepath.Join(t.TempDir(), "config.yaml")
 	t.Setenv(config.EnvConfigPath, cfgPath)
 	stubSetupEnsureShellPath(t)
 
-	cfg := config.Default()
-	cfg.Telegram.BotToken = "saved-token"
-	if err := config.Save(cfg); err != nil {
-		t.Fatalf("config.Save returned error: %v", err)
-	}
-
-	origLinkFn := telegramSetupLinkFn
-	telegramSetupLinkFn = func(token string, timeout time.Duration, w io.Writer) (int64, error) {
-		if token != "saved-token" {
-			return 0, fmt.Errorf("unexpected token: %s", token)
-		}
-		return 999, nil
+	origSkillFn, origCurrentDirFn := setupSkillInstallFn, setupCurrentDirFn
+	setupSkillInstallFn = func(args []string, io IO) error { return nil }
+	setupCurrentDirFn = func() (string, error) { return t.TempDir(), nil }
+	origAccountsFn, origSendFn := signalSetupAccountsFn, signalSetupSendFn
+	var accountsURL string
+	signalSetupAccountsFn = func(apiURL string) ([]string, error) {
+		accountsURL = apiURL
+		return []string{"+15550001111"}, nil
+	}
+	var sentTo []string
+	signalSetupSendFn = func(apiURL, number, recipient, text string) error {
+		sentTo = append(sentTo, number+"->"+recipient)
+		return nil
 	}
-	defer func() { telegramSetupLinkFn = origLinkFn }()
+	defer func() {
+		setupSkillInstallFn, setupCurrentDirFn = origSkillFn, origCurrentDirFn
+		signalSetupAccountsFn, signalSetupSendFn = origAccountsFn, origSendFn
+	}()
 
-	var out bytes.Buffer
 	var errOut bytes.Buffer
-	if err := runSetup([]string{"--provider", "telegram", "--link-chat"}, IO{
-		In:     strings.NewReader(""),
-		Out:    &out,
-		ErrOut: &errOut,
-	}); err != nil {
+	input := strings.NewReader("\n+15559990000\n+15550001111\n15552223333\n+15552223333\n1\n1\n")
+	if err := runSetup([]string{"--provider", "signal"}, IO{In: input, Out: &bytes.Buffer{}, ErrOut: &errOut}); err != nil {
 		t.Fatalf("runSetup returned error: %v", err)
 	}
 
-	updated, err := config.Load()
+	cfg, err := config.Load()
 	if err != nil {
 		t.Fatalf("config.Load returned error: %v", err)
 	}
-	if got, want := updated.Telegram.ChatID, int64(999); got != want {
-		t.Fatalf("want telegram chat id %d got %d", want, got)
+	if cfg.Signal.Number != "+15550001111" || cfg.Signal.Recipient != "+15552223333" || cfg.Signal.APIURL != "" || cfg.ActiveProvider != setupProviderSignal {
+		t.Fatalf("unexpected config: signal=%#v active=%q", cfg.Signal, cfg.ActiveProvider)
+	}
+	if accountsURL != config.DefaultSignalAPIURL {
+		t.Fatalf("expected the default API URL to be checked, got %q", accountsURL)
+	}
+	if len(sentTo) != 1 || sentTo[0] != "+15550001111->+15552223333" {
+		t.Fatalf("expected one test message, got %#v", sentTo)
+	}
+	for _, want := range []string{"+15559990000 is not registered", "international format"} {
+		if !strings.Contains(errOut.String(), want) {
+			t.Fatalf("expected %q in setup output, got: %q", want, errOut.String())
+		}
 	}
 }
 
-func TestRunSetupLinkChatRequiresToken(t *testing.T) {
+func TestRunSetupNonInteractiveChecklistSignal(t *testing.T) {
 	cfgPath := filepath.Join(t.TempDir(), "config.yaml")
 	t.Setenv(config.EnvConfigPath, cfgPath)
 	stubSetupEnsureShellPath(t)
 
-	if err := config.Save(config.Default()); err != nil {
-		t.Fatalf("config.Save returned error: %v", err)
-	}
-
 	var out bytes.Buffer
-	var errOut bytes.Buffer
-	err := runSetup([]string{"--provider", "telegram", "--link-chat"}, IO{
-		In:     strings.NewReader(""),
-		Out:    &out,
-		ErrOut: &errOut,
-	})
-	if err == nil {
-		t.Fatalf("expected error when token is missing")
+	if err := runSetup([]string{"--non-interactive", "--provider", "signal"}, IO{In: strings.NewReader(""), Out: &out, ErrOut: &bytes.Buffer{}}); err != nil {
+		t.Fatalf("runSetup returned error: %v", err)
 	}
-	if !strings.Contains(err.Error(), "telegram.bot_token is required") {
-		t.Fatalf("unexpected error: %v", err)
+	got := out.String()
+	for _, want := range []string{"Signal:", "bbernhard/signal-cli-rest-api", "config set signal.number", "config set signal.recipient", "config set default-provider signal"} {
+		if !strings.Contains(got, want) {
+			t.Fatalf("expected %q in checklist, got: %q", want, got)
+		}
 	}
 }
 
-func TestRunSetupNonInteractiveEnsuresShellPath(t *testing.T) {
+func TestRunSetupInteractiveEmail(t *testing.T) {
 	cfgPath := filepath.Join(t.TempDir(), "config.yaml")
 	t.Setenv(config.EnvConfigPath, cfgPath)
+	stubSetupEnsureShellPath(t)
 
-	origEnsurePathFn := setupEnsureShellPathFn
-	calls := 0
-	setupEnsureShellPathFn = func() (setupShellPathStatus, error) {
-		calls++
-		return setupShellPathStatus{
-			Shell:          "zsh",
-			ProfilePath:    "/tmp/.zshenv",
-			BinaryDir:      "/tmp/bin",
-			AlreadyPresent: true,
-		}, nil
-	}
-	defer func() { setupEnsureShellPathFn = origEnsurePathFn }()
+	origSkillFn, origCurrentDirFn := setupSkillInstallFn, setupCurrentDirFn
+	setupSkillInstallFn = func(args []string, io IO) error { return nil }
+	setupCurrentDirFn = func() (string, error) { return t.TempDir(), nil }
+	defer func() { setupSkillInstallFn, setupCurrentDirFn = origSkillFn, origCurrentDirFn }()
 
-	var out bytes.Buffer
 	var errOut bytes.Buffer
-
-	if err := runSetup([]string{"--non-interactive"}, IO{
-		In:     strings.NewReader(""),
-		Out:    &out,
-		ErrOut: &errOut,
-	}); err != nil {
+	input := strings.NewReader("smtp.example.com\n\nagent\nsecret\nagent@example.com\nnot-an-address\nhuman@example.org\nimap.example.com\n143\n1\n1\n")
+	if err := runSetup([]string{"--provider", "email"}, IO{In: input, Out: &bytes.Buffer{}, ErrOut: &errOut}); err != nil {
 		t.Fatalf("runSetup returned error: %v", err)
 	}
 
-	if calls != 1 {
-		t.Fatalf("expected shell-path ensure to be called once, got %d", calls)
+	cfg, err := config.Load()
+	if err != nil {
+		t.Fatalf("config.Load returned error: %v", err)
+	}
+	want := config.EmailConfig{
+		SMTPHost:     "smtp.example.com",
+		SMTPUsername: "agent",
+		SMTPPassword: "secret",
+		From:         "agent@example.com",
+		To:           "human@example.org",
+		IMAPHost:     "imap.example.com",
+		IMAPPort:     143,
+	}
+	if cfg.Email != want || cfg.ActiveProvider != setupProviderEmail {
+		t.Fatalf("unexpected config: email=%#v active=%q", cfg.Email, cfg.ActiveProvider)
+	}
+	if !strings.Contains(errOut.String(), "email.to must be an email address") {
+		t.Fatalf("expected the bad address to be rejected, got: %q", errOut.String())
 	}
 }
 
-func TestRunSetupNonInteractiveRejectsWhatsAppProvider(t *testing.T) {
+func TestRunSetupNonInteractiveChecklistEmail(t *testing.T) {
 	cfgPath := filepath.Join(t.TempDir(), "config.yaml")
 	t.Setenv(config.EnvConfigPath, cfgPath)
 	stubSetupEnsureShellPath(t)
 
 	var out bytes.Buffer
-	var errOut bytes.Buffer
-
-	err := runSetup([]string{"--non-interactive", "--provider", "whatsapp"}, IO{
-		In:     strings.NewReader(""),
-		Out:    &out,
-		ErrOut: &errOut,
-	})
-	if err == nil {
-		t.Fatalf("expected error for disabled whatsapp provider")
+	if err := runSetup([]string{"--non-interactive", "--provider", "email"}, IO{In: strings.NewReader(""), Out: &out, ErrOut: &bytes.Buffer{}}); err != nil {
+		t.Fatalf("runSetup returned error: %v", err)
 	}
-	if !strings.Contains(err.Error(), "temporarily disabled") {
-		t.Fatalf("unexpected error: %v", err)
+	got := out.String()
+	for _, want := range []string{"Email:", "config set email.smtp_host", "config set email.imap_host", "config set email.to", "config set default-provider email"} {
+		if !strings.Contains(got, want) {
+			t.Fatalf("expected %q in checklist, got: %q", want, got)
+		}
 	}
 }
 
-func TestRunSetupNonInteractiveShowsConfiguredProviderStatus(t *testing.T) {
+func TestRunSetupInteractiveNtfy(t *testing.T) {
 	cfgPath := filepath.Join(t.TempDir(), "config.yaml")
 	t.Setenv(config.EnvConfigPath, cfgPath)
 	stubSetupEnsureShellPath(t)
 
-	cfg := config.Default()
-	cfg.Telegram.BotToken = "existing-token"
-	cfg.Telegram.ChatID = 111
-	if err := config.Save(cfg); err != nil {
+	origSkillFn, origCurrentDirFn, origPublishFn := setupSkillInstallFn, setupCurrentDirFn, ntfySetupPublishFn
+	setupSkillInstallFn = func(args []string, io IO) error { return nil }
+	setupCurrentDirFn = func() (string, error) { return t.TempDir(), nil }
+	var published []string
+	ntfySetupPublishFn = func(server, topic, token, text string) error {
+		published = append(published, server+"/"+topic+" "+token)
+		return nil
+	}
+	defer func() {
+		setupSkillInstallFn, setupCurrentDirFn, ntfySetupPublishFn = origSkillFn, origCurrentDirFn, origPublishFn
+	}()
+
+	var errOut bytes.Buffer
+	input := strings.NewReader("https://ntfy.example.com\nbad topic\nagent-questions\nsecret\ntk_abc\n1\n1\n")
+	if err := runSetup([]string{"--provider", "ntfy"}, IO{In: input, Out: &bytes.Buffer{}, ErrOut: &errOut}); err != nil {
+		t.Fatalf("runSetup returned error: %v", err)
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		t.Fatalf("config.Load returned error: %v", err)
+	}
+	want := config.NtfyConfig{Server: "https://ntfy.example.com", Topic: "agent-questions", Token: "tk_abc"}
+	if cfg.Ntfy != want || cfg.ActiveProvider != setupProviderNtfy {
+		t.Fatalf("unexpected config: ntfy=%#v active=%q", cfg.Ntfy, cfg.ActiveProvider)
+	}
+	if len(published) != 1 || published[0] != "https://ntfy.example.com/agent-questions tk_abc" {
+		t.Fatalf("expected one test message, got %#v", published)
+	}
+	for _, want := range []string{"ntfy.topic must be", "ntfy.token must be"} {
+		if !strings.Contains(errOut.String(), want) {
+			t.Fatalf("expected %q in setup output, got: %q", want, errOut.String())
+		}
+	}
+}
+
+func TestRunSetupNonInteractiveChecklistNtfy(t *testing.T) {
+	cfgPath := filepath.Join(t.TempDir(), "config.yaml")
+	t.Setenv(config.EnvConfigPath, cfgPath)
+	stubSetupEnsureShellPath(t)
+
+	var out bytes.Buffer
+	if err := runSetup([]string{"--non-interactive", "--provider", "ntfy"}, IO{In: strings.NewReader(""), Out: &out, ErrOut: &bytes.Buffer{}}); err != nil {
+		t.Fatalf("runSetup returned error: %v", err)
+	}
+	got := out.String()
+	for _, want := range []string{"ntfy:", "config set ntfy.topic", "<TOPIC>-replies", "config set ntfy.server", "config set ntfy.token", "config set default-provider ntfy"} {
+		if !strings.Contains(got, want) {
+			t.Fatalf("expected %q in checklist, got: %q", want, got)
+		}
+	}
+}
+
+func TestRunSetupInteractiveWebhook(t *testing.T) {
+	cfgPath := filepath.Join(t.TempDir(), "config.yaml")
+	t.Setenv(config.EnvConfigPath, cfgPath)
+	stubSetupEnsureShellPath(t)
+
+	origSkillFn, origCurrentDirFn := setupSkillInstallFn, setupCurrentDirFn
+	setupSkillInstallFn = func(args []string, io IO) error { return nil }
+	setupCurrentDirFn = func() (string, error) { return t.TempDir(), nil }
+	defer func() {
+		setupSkillInstallFn, setupCurrentDirFn = origSkillFn, origCurrentDirFn
+	}()
+
+	var errOut bytes.Buffer
+	input := strings.NewReader("bridge.example\nhttps://bridge.example/questions\ns3cret\n3\n2\nhttps://bridge.example/answers\n1\n1\n")
+	if err := runSetup([]string{"--provider", "webhook"}, IO{In: input, Out: &bytes.Buffer{}, ErrOut: &errOut}); err != nil {
+		t.Fatalf("runSetup returned error: %v", err)
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		t.Fatalf("config.Load returned error: %v", err)
+	}
+	want := config.WebhookConfig{URL: "https://bridge.example/questions", Secret: "s3cret", PollURL: "https://bridge.example/answers"}
+	if cfg.Webhook != want || cfg.ActiveProvider != setupProviderWebhook {
+		t.Fatalf("unexpected config: webhook=%#v active=%q", cfg.Webhook, cfg.ActiveProvider)
+	}
+	for _, want := range []string{"webhook.url must be an http or https URL", "enter 1 or 2"} {
+		if !strings.Contains(errOut.String(), want) {
+			t.Fatalf("expected %q in setup output, got: %q", want, errOut.String())
+		}
+	}
+}
+
+func TestRunSetupNonInteractiveChecklistWebhook(t *testing.T) {
+	cfgPath := filepath.Join(t.TempDir(), "config.yaml")
+	t.Setenv(config.EnvConfigPath, cfgPath)
+	stubSetupEnsureShellPath(t)
+
+	var out bytes.Buffer
+	if err := runSetup([]string{"--non-interactive", "--provider", "webhook"}, IO{In: strings.NewReader(""), Out: &out, ErrOut: &bytes.Buffer{}}); err != nil {
+		t.Fatalf("runSetup returned error: %v", err)
+	}
+	got := out.String()
+	for _, want := range []string{"Webhook:", "config set webhook.url", "config set webhook.callback_listen", "config set webhook.poll_url", "config set default-provider webhook"} {
+		if !strings.Contains(got, want) {
+			t.Fatalf("expected %q in checklist, got: %q", want, got)
+		}
+	}
+}
+
+func TestRunSetupInteractiveDesktop(t *testing.T) {
+	cfgPath := filepath.Join(t.TempDir(), "config.yaml")
+	t.Setenv(config.EnvConfigPath, cfgPath)
+	stubSetupEnsureShellPath(t)
+
+	origSkillFn, origCurrentDirFn, origLookPathFn := setupSkillInstallFn, setupCurrentDirFn, desktopSetupLookPathFn
+	setupSkillInstallFn = func(args []string, io IO) error { return nil }
+	setupCurrentDirFn = func() (string, error) { return t.TempDir(), nil }
+	var looked []string
+	desktopSetupLookPathFn = func(file string) (string, error) {
+		looked = append(looked, file)
+		return "/usr/bin/" + file, nil
+	}
+	defer func() {
+		setupSkillInstallFn, setupCurrentDirFn, desktopSetupLookPathFn = origSkillFn, origCurrentDirFn, origLookPathFn
+	}()
+
+	var errOut bytes.Buffer
+	input := strings.NewReader("70000\n8790\n1\n1\n")
+	if err := runSetup([]string{"--provider", "desktop"}, IO{In: input, Out: &bytes.Buffer{}, ErrOut: &errOut}); err != nil {
+		t.Fatalf("runSetup returned error: %v", err)
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		t.Fatalf("config.Load returned error: %v", err)
+	}
+	if cfg.Desktop.Port != 8790 || cfg.ActiveProvider != setupProviderDesktop {
+		t.Fatalf("unexpected config: desktop=%#v active=%q", cfg.Desktop, cfg.ActiveProvider)
+	}
+	if len(looked) != 1 || looked[0] != desktopNotifierCommand() {
+		t.Fatalf("expected the notifier to be looked up, got %#v", looked)
+	}
+	if !strings.Contains(errOut.String(), "desktop.port must be a port number") {
+		t.Fatalf("expected the bad port to be rejected, got: %q", errOut.String())
+	}
+}
+
+func TestRunSetupNonInteractiveChecklistDesktop(t *testing.T) {
+	cfgPath := filepath.Join(t.TempDir(), "config.yaml")
+	t.Setenv(config.EnvConfigPath, cfgPath)
+	stubSetupEnsureShellPath(t)
+
+	var out bytes.Buffer
+	if err := runSetup([]string{"--non-interactive", "--provider", "desktop"}, IO{In: strings.NewReader(""), Out: &out, ErrOut: &bytes.Buffer{}}); err != nil {
+		t.Fatalf("runSetup returned error: %v", err)
+	}
+	got := out.String()
+	for _, want := range []string{"Desktop:", desktopNotifierCommand(), "config set desktop.port", "config set default-provider desktop"} {
+		if !strings.Contains(got, want) {
+			t.Fatalf("expected %q in checklist, got: %q", want, got)
+		}
+	}
+}
+
+func TestRunSetupInteractiveZulip(t *testing.T) {
+	cfgPath := filepath.Join(t.TempDir(), "config.yaml")
+	t.Setenv(config.EnvConfigPath, cfgPath)
+	stubSetupEnsureShellPath(t)
+
+	origSkillFn, origCurrentDirFn, origWhoAmIFn := setupSkillInstallFn, setupCurrentDirFn, zulipSetupWhoAmIFn
+	setupSkillInstallFn = func(args []string, io IO) error { return nil }
+	setupCurrentDirFn = func() (string, error) { return t.TempDir(), nil }
+	var logins []string
+	zulipSetupWhoAmIFn = func(site, email, apiKey string) (string, error) {
+		logins = append(logins, email+":"+apiKey)
+		if apiKey != "key123" {
+			return "", errZulipSetupLoginRejected
+		}
+		return "consult-human bot", nil
+	}
+	defer func() {
+		setupSkillInstallFn, setupCurrentDirFn, zulipSetupWhoAmIFn = origSkillFn, origCurrentDirFn, origWhoAmIFn
+	}()
+
+	var errOut bytes.Buffer
+	input := strings.NewReader(strings.Join([]string{
+		"https://example.zulipchat.com", "bot@example.zulipchat.com", "wrong",
+		"https://example.zulipchat.com/", "bot@example.zulipchat.com", "key123",
+		"2", "not-an-email", "human@example.com", "1", "1",
+	}, "\n") + "\n")
+	if err := runSetup([]string{"--provider", "zulip"}, IO{In: input, Out: &bytes.Buffer{}, ErrOut: &errOut}); err != nil {
+		t.Fatalf("runSetup returned error: %v", err)
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		t.Fatalf("config.Load returned error: %v", err)
+	}
+	want := config.ZulipConfig{Site: "https://example.zulipchat.com", Email: "bot@example.zulipchat.com", APIKey: "key123", Recipient: "human@example.com"}
+	if cfg.Zulip != want || cfg.ActiveProvider != setupProviderZulip {
+		t.Fatalf("unexpected config: zulip=%#v active=%q", cfg.Zulip, cfg.ActiveProvider)
+	}
+	if len(logins) != 2 {
+		t.Fatalf("expected the login to be checked twice, got %#v", logins)
+	}
+	for _, want := range []string{"login rejected by Zulip", "zulip.recipient must be an email address"} {
+		if !strings.Contains(errOut.String(), want) {
+			t.Fatalf("expected %q in setup output, got: %q", want, errOut.String())
+		}
+	}
+}
+
+func TestRunSetupNonInteractiveChecklistZulip(t *testing.T) {
+	cfgPath := filepath.Join(t.TempDir(), "config.yaml")
+	t.Setenv(config.EnvConfigPath, cfgPath)
+	stubSetupEnsureShellPath(t)
+
+	var out bytes.Buffer
+	if err := runSetup([]string{"--non-interactive", "--provider", "zulip"}, IO{In: strings.NewReader(""), Out: &out, ErrOut: &bytes.Buffer{}}); err != nil {
+		t.Fatalf("runSetup returned error: %v", err)
+	}
+	got := out.String()
+	for _, want := range []string{"Zulip:", "config set zulip.site", "config set zulip.api_key", "config set zulip.stream", "config set zulip.recipient", "config set default-provider zulip"} {
+		if !strings.Contains(got, want) {
+			t.Fatalf("expected %q in checklist, got: %q", want, got)
+		}
+	}
+}
+
+func TestRunSetupInteractivePushover(t *testing.T) {
+	cfgPath := filepath.Join(t.TempDir(), "config.yaml")
+	t.Setenv(config.EnvConfigPath, cfgPath)
+	stubSetupEnsureShellPath(t)
+
+	origSkillFn, origCurrentDirFn, origValidateFn := setupSkillInstallFn, setupCurrentDirFn, pushoverSetupValidateFn
+	setupSkillInstallFn = func(args []string, io IO) error { return nil }
+	setupCurrentDirFn = func() (string, error) { return t.TempDir(), nil }
+	var checks int
+	pushoverSetupValidateFn = func(appToken, userKey string) error {
+		checks++
+		if userKey != "uQiRzpo4DXghDmr9QzzfQu27cmVRsG" {
+			return errPushoverSetupRejected
+		}
+		return nil
+	}
+	defer func() {
+		setupSkillInstallFn, setupCurrentDirFn, pushoverSetupValidateFn = origSkillFn, origCurrentDirFn, origValidateFn
+	}()
+
+	var errOut bytes.Buffer
+	input := strings.NewReader(strings.Join([]string{
+		"azGDORePK8gMaC0QOYAMyEEuzJnyUi", "uQiRzpo4DXghDmr9QzzfQu27cmVRsX",
+		"azGDORePK8gMaC0QOYAMyEEuzJnyUi", "uQiRzpo4DXghDmr9QzzfQu27cmVRsG",
+		"2", "", "http://127.0.0.1:8788/reply", "https://reply.example.com", "1", "1",
+	}, "\n") + "\n")
+	if err := runSetup([]string{"--provider", "pushover"}, IO{In: input, Out: &bytes.Buffer{}, ErrOut: &errOut}); err != nil {
+		t.Fatalf("runSetup returned error: %v", err)
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		t.Fatalf("config.Load returned error: %v", err)
+	}
+	want := config.PushoverConfig{AppToken: "azGDORePK8gMaC0QOYAMyEEuzJnyUi", UserKey: "uQiRzpo4DXghDmr9QzzfQu27cmVRsG", ReplyURL: "https://reply.example.com", Listen: config.DefaultPushoverListen}
+	if cfg.Pushover != want || cfg.ActiveProvider != setupProviderPushover {
+		t.Fatalf("unexpected config: pushover=%#v active=%q", cfg.Pushover, cfg.ActiveProvider)
+	}
+	if checks != 2 {
+		t.Fatalf("expected the keys to be checked twice, got %d", checks)
+	}
+	for _, want := range []string{"rejected by Pushover", "pushover.reply_url must be an http or https URL without a path"} {
+		if !strings.Contains(errOut.String(), want) {
+			t.Fatalf("expected %q in setup output, got: %q", want, errOut.String())
+		}
+	}
+}
+
+func TestRunSetupNonInteractiveChecklistPushover(t *testing.T) {
+	cfgPath := filepath.Join(t.TempDir(), "config.yaml")
+	t.Setenv(config.EnvConfigPath, cfgPath)
+	stubSetupEnsureShellPath(t)
+
+	var out bytes.Buffer
+	if err := runSetup([]string{"--non-interactive", "--provider", "pushover"}, IO{In: strings.NewReader(""), Out: &out, ErrOut: &bytes.Buffer{}}); err != nil {
+		t.Fatalf("runSetup returned error: %v", err)
+	}
+	got := out.String()
+	for _, want := range []string{"Pushover:", "config set pushover.app_token", "config set pushover.user_key", "config set pushover.reply_url", "config set default-provider pushover"} {
+		if !strings.Contains(got, want) {
+			t.Fatalf("expected %q in checklist, got: %q", want, got)
+		}
+	}
+}
+
+func TestRunSetupInteractiveIMessage(t *testing.T) {
+	cfgPath := filepath.Join(t.TempDir(), "config.yaml")
+	t.Setenv(config.EnvConfigPath, cfgPath)
+	stubSetupEnsureShellPath(t)
+
+	origSkillFn, origCurrentDirFn := setupSkillInstallFn, setupCurrentDirFn
+	setupSkillInstallFn = func(args []string, io IO) error { return nil }
+	setupCurrentDirFn = func() (string, error) { return t.TempDir(), nil }
+	origCheckFn, origSendFn := imessageSetupCheckFn, imessageSetupSendFn
+	var checkedPath string
+	imessageSetupCheckFn = func(dbPath string) error {
+		checkedPath = dbPath
+		return nil
+	}
+	var sentTo []string
+	imessageSetupSendFn = func(handle, text string) error {
+		sentTo = append(sentTo, handle)
+		return nil
+	}
+	defer func() {
+		setupSkillInstallFn, setupCurrentDirFn = origSkillFn, origCurrentDirFn
+		imessageSetupCheckFn, imessageSetupSendFn = origCheckFn, origSendFn
+	}()
+
+	var errOut bytes.Buffer
+	input := strings.NewReader("5552223333\n+15552223333\n1\n1\n")
+	if err := runSetup([]string{"--provider", "imessage"}, IO{In: input, Out: &bytes.Buffer{}, ErrOut: &errOut}); err != nil {
+		t.Fatalf("runSetup returned error: %v", err)
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		t.Fatalf("config.Load returned error: %v", err)
+	}
+	if cfg.IMessage.Handle != "+15552223333" || cfg.ActiveProvider != setupProviderIMessage {
+		t.Fatalf("unexpected config: imessage=%#v active=%q", cfg.IMessage, cfg.ActiveProvider)
+	}
+	if !strings.HasSuffix(checkedPath, filepath.Join("Library", "Messages", "chat.db")) {
+		t.Fatalf("expected the default Messages database to be checked, got %q", checkedPath)
+	}
+	if len(sentTo) != 1 || sentTo[0] != "+15552223333" {
+		t.Fatalf("expected one test message, got %#v", sentTo)
+	}
+	if !strings.Contains(errOut.String(), "international format") {
+		t.Fatalf("expected the rejected handle in setup output, got: %q", errOut.String())
+	}
+}
+
+func TestRunSetupInteractiveIMessageWithoutDiskAccess(t *testing.T) {
+	cfgPath := filepath.Join(t.TempDir(), "config.yaml")
+	t.Setenv(config.EnvConfigPath, cfgPath)
+	stubSetupEnsureShellPath(t)
+
+	origCheckFn := imessageSetupCheckFn
+	imessageSetupCheckFn = func(dbPath string) error {
+		return errors.New("cannot read the Messages database; grant Full Disk Access")
+	}
+	defer func() { imessageSetupCheckFn = origCheckFn }()
+
+	err := runSetup([]string{"--provider", "imessage"}, IO{In: strings.NewReader("+15552223333\n"), Out: &bytes.Buffer{}, ErrOut: &bytes.Buffer{}})
+	if err == nil || !strings.Contains(err.Error(), "Full Disk Access") || !strings.Contains(err.Error(), "--skip-verify") {
+		t.Fatalf("expected Full Disk Access guidance, got %v", err)
+	}
+}
+
+func TestRunSetupNonInteractiveChecklistIMessage(t *testing.T) {
+	cfgPath := filepath.Join(t.TempDir(), "config.yaml")
+	t.Setenv(config.EnvConfigPath, cfgPath)
+	stubSetupEnsureShellPath(t)
+
+	var out bytes.Buffer
+	if err := runSetup([]string{"--non-interactive", "--provider", "imessage"}, IO{In: strings.NewReader(""), Out: &out, ErrOut: &bytes.Buffer{}}); err != nil {
+		t.Fatalf("runSetup returned error: %v", err)
+	}
+	got := out.String()
+	for _, want := range []string{"iMessage (macOS only):", "Full Disk Access", "config set imessage.handle"} {
+		if !strings.Contains(got, want) {
+			t.Fatalf("expected %q in checklist, got: %q", want, got)
+		}
+	}
+}
+
+func TestRunSetupNonInteractiveChecklistTelegram(t *testing.T) {
+	cfgPath := filepath.Join(t.TempDir(), "config.yaml")
+	t.Setenv(config.EnvConfigPath, cfgPath)
+	stubSetupEnsureShellPath(t)
+
+	var out bytes.Buffer
+	var errOut bytes.Buffer
+
+	if err := runSetup([]string{"--non-interactive", "--provider", "telegram"}, IO{
+		In:     strings.NewReader(""),
+		Out:    &out,
+		ErrOut: &errOut,
+	}); err != nil {
+		t.Fatalf("runSetup returned error: %v", err)
+	}
+
+	got := out.String()
+	if !strings.Contains(got, "Setup checklist (non-interactive)") {
+		t.Fatalf("expected checklist header, got: %q", got)
+	}
+	if !strings.Contains(got, "Shell PATH:") {
+		t.Fatalf("expected shell PATH status block, got: %q", got)
+	}
+	if !strings.Contains(got, "consult-human config set telegram.bot_token") {
+		t.Fatalf("expected telegram token command, got: %q", got)
+	}
+	if !strings.Contains(got, "consult-human setup --provider telegram --link-chat") {
+		t.Fatalf("expected telegram non-interactive link command, got: %q", got)
+	}
+	if !strings.Contains(got, "telegram.api_base_url") {
+		t.Fatalf("expected api_base_url override hint, got: %q", got)
+	}
+	if !strings.Contains(got, "consult-human config set default-provider telegram") {
+		t.Fatalf("expected default-provider command, got: %q", got)
+	}
+	if !strings.Contains(got, "consult-human skill install --target claude") {
+		t.Fatalf("expected skill install command, got: %q", got)
+	}
+	if !strings.Contains(got, "Final step: install skill files for agent runtimes, and a reminder in CLAUDE.md/AGENTS.md (required):") {
+		t.Fatalf("expected required skill-install final step, got: %q", got)
+	}
+	if !strings.Contains(got, "Ask your human whether to install globally or locally (repo-only).") {
+		t.Fatalf("expected ask-human scope guidance, got: %q", got)
+	}
+	if !strings.Contains(got, "Global install (all repos on this machine):") {
+		t.Fatalf("expected global install heading, got: %q", got)
+	}
+	if !strings.Contains(got, "Local install (repo-only):") {
+		t.Fatalf("expected local install heading, got: %q", got)
+	}
+}
+
+func TestRunSetupLinkChatWithSavedToken(t *testing.T) {
+	cfgPath := filepath.Join(t.TempDir(), "config.yaml")
+	t.Setenv(config.EnvConfigPath, cfgPath)
+	stubSetupEnsureShellPath(t)
+
+	cfg := config.Default()
+	cfg.Telegram.BotToken = "saved-token"
+	if err := config.Save(cfg); err != nil {
+		t.Fatalf("config.Save returned error: %v", err)
+	}
+
+	origLinkFn := telegramSetupLinkFn
+	telegramSetupLinkFn = func(apiBaseURL, token, code string, timeout time.Duration, w io.Writer) (telegramSetupLink, error) {
+		if token != "saved-token" {
+			return telegramSetupLink{}, fmt.Errorf("unexpected token: %s", token)
+		}
+		return telegramSetupLink{ChatID: 999, UserID: 42}, nil
+	}
+	defer func() { telegramSetupLinkFn = origLinkFn }()
+
+	sent := stubTelegramSetupSend(t)
+	var out bytes.Buffer
+	var errOut bytes.Buffer
+	if err := runSetup([]string{"--provider", "telegram", "--link-chat", "--expect-user", "42"}, IO{
+		In:     strings.NewReader(""),
+		Out:    &out,
+		ErrOut: &errOut,
+	}); err != nil {
+		t.Fatalf("runSetup returned error: %v", err)
+	}
+
+	updated, err := config.Load()
+	if err != nil {
+		t.Fatalf("config.Load returned error: %v", err)
+	}
+	if got, want := updated.Telegram.ChatID, int64(999); got != want {
+		t.Fatalf("want telegram chat id %d got %d", want, got)
+	}
+	if len(*sent) != 1 || !strings.HasPrefix((*sent)[0], "This chat is now linked to consult-human on ") {
+		t.Fatalf("expected one confirmation message, got %#v", *sent)
+	}
+}
+
+func TestRunSetupLinkChatRejectsUnexpectedUser(t *testing.T) {
+	cfgPath := filepath.Join(t.TempDir(), "config.yaml")
+	t.Setenv(config.EnvConfigPath, cfgPath)
+	stubSetupEnsureShellPath(t)
+
+	cfg := config.Default()
+	cfg.Telegram.BotToken = "saved-token"
+	if err := config.Save(cfg); err != nil {
+		t.Fatalf("config.Save returned error: %v", err)
+	}
+
+	origLinkFn := telegramSetupLinkFn
+	telegramSetupLinkFn = func(apiBaseURL, token, code string, timeout time.Duration, w io.Writer) (telegramSetupLink, error) {
+		return telegramSetupLink{ChatID: 999, UserID: 7, Username: "mallory"}, nil
+	}
+	defer func() { telegramSetupLinkFn = origLinkFn }()
+	sent := stubTelegramSetupSend(t)
+
+	io := IO{In: strings.NewReader(""), Out: &bytes.Buffer{}, ErrOut: &bytes.Buffer{}}
+	err := runSetup([]string{"--provider", "telegram", "--link-chat", "--expect-user", "42"}, io)
+	if err == nil || !strings.Contains(err.Error(), "not the expected user 42") {
+		t.Fatalf("expected an unexpected-user error, got %v", err)
+	}
+	if err := runSetup([]string{"--provider", "telegram", "--link-chat"}, io); err == nil || !strings.Contains(err.Error(), "--expect-user") {
+		t.Fatalf("expected --link-chat without --expect-user to fail, got %v", err)
+	}
+	updated, err := config.Load()
+	if err != nil {
+		t.Fatalf("config.Load returned error: %v", err)
+	}
+	if updated.Telegram.ChatID != 0 || len(*sent) != 0 {
+		t.Fatalf("expected nothing linked or sent, got chat %d and %#v", updated.Telegram.ChatID, *sent)
+	}
+}
+
+func TestRunSetupInteractiveRejectsWrongVerificationCode(t *testing.T) {
+	cfgPath := filepath.Join(t.TempDir(), "config.yaml")
+	t.Setenv(config.EnvConfigPath, cfgPath)
+	stubSetupEnsureShellPath(t)
+
+	cfg := config.Default()
+	cfg.Telegram.BotToken = "saved-token"
+	if err := config.Save(cfg); err != nil {
+		t.Fatalf("config.Save returned error: %v", err)
+	}
+
+	origLinkFn := telegramSetupLinkFn
+	telegramSetupLinkFn = func(apiBaseURL, token, code string, timeout time.Duration, w io.Writer) (telegramSetupLink, error) {
+		return telegramSetupLink{ChatID: 999, UserID: 7}, nil
+	}
+	defer func() { telegramSetupLinkFn = origLinkFn }()
+	sent := stubTelegramSetupSend(t)
+
+	var errOut bytes.Buffer
+	err := runSetup([]string{"--provider", "telegram", "--skip-verify"}, IO{In: strings.NewReader("0000\n1111\n2222\n"), Out: &bytes.Buffer{}, ErrOut: &errOut})
+	if err == nil || !strings.Contains(err.Error(), "verification code did not match") {
+		t.Fatalf("expected a verification error, got %v", err)
+	}
+	updated, err := config.Load()
+	if err != nil {
+		t.Fatalf("config.Load returned error: %v", err)
+	}
+	if updated.Telegram.ChatID != 0 || len(*sent) != 1 {
+		t.Fatalf("expected only the code to be sent and no chat linked, got chat %d and %#v", updated.Telegram.ChatID, *sent)
+	}
+}
+
+func TestRunSetupLinkChatWithNameAddsAlias(t *testing.T) {
+	cfgPath := filepath.Join(t.TempDir(), "config.yaml")
+	t.Setenv(config.EnvConfigPath, cfgPath)
+	stubSetupEnsureShellPath(t)
+
+	cfg := config.Default()
+	cfg.Telegram.BotToken = "saved-token"
+	cfg.Telegram.ChatID = 111
+	if err := config.Save(cfg); err != nil {
+		t.Fatalf("config.Save returned error: %v", err)
+	}
+
+	origLinkFn := telegramSetupLinkFn
+	telegramSetupLinkFn = func(apiBaseURL, token, code string, timeout time.Duration, w io.Writer) (telegramSetupLink, error) {
+		return telegramSetupLink{ChatID: -100222, UserID: 42}, nil
+	}
+	defer func() { telegramSetupLinkFn = origLinkFn }()
+	stubTelegramSetupSend(t)
+
+	var out bytes.Buffer
+	var errOut bytes.Buffer
+	if err := runSetup([]string{"--provider", "telegram", "--link-chat", "--expect-user", "42", "--name", "Work"}, IO{
+		In:     strings.NewReader(""),
+		Out:    &out,
+		ErrOut: &errOut,
+	}); err != nil {
+		t.Fatalf("runSetup returned error: %v", err)
+	}
+
+	updated, err := config.Load()
+	if err != nil {
+		t.Fatalf("config.Load returned error: %v", err)
+	}
+	if got := updated.Telegram.Chats["work"]; got != -100222 {
+		t.Fatalf("expected work alias for chat -100222, got %#v", updated.Telegram.Chats)
+	}
+	if got, want := updated.Telegram.ChatID, int64(111); got != want {
+		t.Fatalf("expected default chat %d to be kept, got %d", want, got)
+	}
+
+	if err := runSetup([]string{"--name", "work"}, IO{In: strings.NewReader(""), Out: &out, ErrOut: &errOut}); err == nil {
+		t.Fatalf("expected --name without --link-chat to fail")
+	}
+}
+
+func TestRunSetupLinkChatDeletesWebhookWhenConfigured(t *testing.T) {
+	cfgPath := filepath.Join(t.TempDir(), "config.yaml")
+	t.Setenv(config.EnvConfigPath, cfgPath)
+	stubSetupEnsureShellPath(t)
+
+	cfg := config.Default()
+	cfg.Telegram.BotToken = "saved-token"
+	cfg.Telegram.AutoDeleteWebhook = true
+	if err := config.Save(cfg); err != nil {
+		t.Fatalf("config.Save returned error: %v", err)
+	}
+
+	linkCalls := 0
+	deleted := false
+	origLinkFn := telegramSetupLinkFn
+	origURLFn := telegramSetupWebhookURLFn
+	origDeleteFn := telegramSetupDeleteWebhookFn
+	telegramSetupLinkFn = func(apiBaseURL, token, code string, timeout time.Duration, w io.Writer) (telegramSetupLink, error) {
+		linkCalls++
+		if !deleted {
+			return telegramSetupLink{}, errTelegramSetupWebhookActive
+		}
+		return telegramSetupLink{ChatID: 999, UserID: 42}, nil
+	}
+	telegramSetupWebhookURLFn = func(apiBaseURL, token string) (string, error) {
+		return "https://example.com/hook", nil
+	}
+	telegramSetupDeleteWebhookFn = func(apiBaseURL, token string) error {
+		deleted = true
+		return nil
+	}
+	defer func() {
+		telegramSetupLinkFn = origLinkFn
+		telegramSetupWebhookURLFn = origURLFn
+		telegramSetupDeleteWebhookFn = origDeleteFn
+	}()
+	stubTelegramSetupSend(t)
+
+	var out bytes.Buffer
+	var errOut bytes.Buffer
+	if err := runSetup([]string{"--provider", "telegram", "--link-chat", "--expect-user", "42"}, IO{
+		In:     strings.NewReader(""),
+		Out:    &out,
+		ErrOut: &errOut,
+	}); err != nil {
+		t.Fatalf("runSetup returned error: %v", err)
+	}
+	if !deleted || linkCalls != 2 {
+		t.Fatalf("expected webhook removal and one retry, deleted=%v calls=%d", deleted, linkCalls)
+	}
+
+	updated, err := config.Load()
+	if err != nil {
+		t.Fatalf("config.Load returned error: %v", err)
+	}
+	if got, want := updated.Telegram.ChatID, int64(999); got != want {
+		t.Fatalf("want telegram chat id %d got %d", want, got)
+	}
+
+	// Without the flag the webhook is left alone.
+	cfg = config.Default()
+	cfg.Telegram.BotToken = "saved-token"
+	if err := config.Save(cfg); err != nil {
+		t.Fatalf("config.Save returned error: %v", err)
+	}
+	deleted = false
+	telegramSetupDeleteWebhookFn = func(apiBaseURL, token string) error {
+		t.Fatalf("deleteWebhook should not be called without telegram.auto_delete_webhook")
+		return nil
+	}
+	errOut.Reset()
+	err = runSetup([]string{"--provider", "telegram", "--link-chat", "--expect-user", "42"}, IO{In: strings.NewReader(""), Out: &out, ErrOut: &errOut})
+	if !errors.Is(err, errTelegramSetupWebhookActive) {
+		t.Fatalf("expected webhook-active error, got %v", err)
+	}
+	if !strings.Contains(errOut.String(), "telegram.auto_delete_webhook") {
+		t.Fatalf("expected auto_delete_webhook hint, got %q", errOut.String())
+	}
+}
+
+func TestDeleteTelegramSetupWebhookKeepsPendingUpdates(t *testing.T) {
+	var body map[string]any
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/deleteWebhook" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		_, _ = io.WriteString(w, `{"ok":true,"result":true}`)
+	}))
+	defer srv.Close()
+
+	if err := deleteTelegramSetupWebhook(srv.URL); err != nil {
+		t.Fatalf("deleteTelegramSetupWebhook returned error: %v", err)
+	}
+	if got, ok := body["drop_pending_updates"]; !ok || got != false {
+		t.Fatalf("expected drop_pending_updates=false, got %#v", body)
+	}
+}
+
+func TestRunSetupLinkChatUsesConfiguredAPIBaseURL(t *testing.T) {
+	cfgPath := filepath.Join(t.TempDir(), "config.yaml")
+	t.Setenv(config.EnvConfigPath, cfgPath)
+	stubSetupEnsureShellPath(t)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Path != "/botsaved-token/getUpdates" {
+			_, _ = io.WriteString(w, `{"ok":true,"result":true}`)
+			return
+		}
+		_, _ = io.WriteString(w, `{"ok":true,"result":[{"update_id":1,"message":{"text":"/start c0de","chat":{"id":777},"from":{"id":42}}}]}`)
+	}))
+	defer srv.Close()
+	origCodeFn := telegramSetupStartCodeFn
+	telegramSetupStartCodeFn = func() (string, error) { return "c0de", nil }
+	defer func() { telegramSetupStartCodeFn = origCodeFn }()
+
+	cfg := config.Default()
+	cfg.Telegram.BotToken = "saved-token"
+	if err := config.Set(&cfg, "telegram.api_base_url", srv.URL+"/"); err != nil {
+		t.Fatalf("config.Set returned error: %v", err)
+	}
+	if err := config.Save(cfg); err != nil {
+		t.Fatalf("config.Save returned error: %v", err)
+	}
+
+	var out bytes.Buffer
+	var errOut bytes.Buffer
+	if err := runSetup([]string{"--provider", "telegram", "--link-chat", "--expect-user", "42"}, IO{
+		In:     strings.NewReader(""),
+		Out:    &out,
+		ErrOut: &errOut,
+	}); err != nil {
+		t.Fatalf("runSetup returned error: %v", err)
+	}
+
+	updated, err := config.Load()
+	if err != nil {
+		t.Fatalf("config.Load returned error: %v", err)
+	}
+	if got, want := updated.Telegram.ChatID, int64(777); got != want {
+		t.Fatalf("want telegram chat id %d got %d", want, got)
+	}
+}
+
+func TestRunSetupLinkChatRequiresToken(t *testing.T) {
+	cfgPath := filepath.Join(t.TempDir(), "config.yaml")
+	t.Setenv(config.EnvConfigPath, cfgPath)
+	stubSetupEnsureShellPath(t)
+
+	if err := config.Save(config.Default()); err != nil {
+		t.Fatalf("config.Save returned error: %v", err)
+	}
+
+	var out bytes.Buffer
+	var errOut bytes.Buffer
+	err := runSetup([]string{"--provider", "telegram", "--link-chat", "--expect-user", "42"}, IO{
+		In:     strings.NewReader(""),
+		Out:    &out,
+		ErrOut: &errOut,
+	})
+	if err == nil {
+		t.Fatalf("expected error when token is missing")
+	}
+	if !strings.Contains(err.Error(), "telegram.bot_token is required") {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestRunSetupTestReportsRoundTrip(t *testing.T) {
+	fake := &fakeAskProvider{reply: contract.Reply{Text: "ok", Raw: "ok"}}
+	stubAskProvider(t, fake)
+	cfg := config.Default()
+	cfg.Telegram.BotToken = "token"
+	cfg.Telegram.ChatID = 777
+	if err := config.Save(cfg); err != nil {
+		t.Fatalf("Save returned error: %v", err)
+	}
+
+	var errOut bytes.Buffer
+	if err := runSetup([]string{"--provider", "telegram", "--test"}, IO{In: strings.NewReader(""), Out: &bytes.Buffer{}, ErrOut: &errOut}); err != nil {
+		t.Fatalf("runSetup returned error: %v", err)
+	}
+	if len(fake.sent) != 1 || fake.sent[0].Question != setupTelegramTestQuestion {
+		t.Fatalf("expected one test question, got %#v", fake.sent)
+	}
+	if !strings.Contains(errOut.String(), "Round trip OK") {
+		t.Fatalf("expected success line, got %q", errOut.String())
+	}
+}
+
+func TestRunSetupTestExplainsFailures(t *testing.T) {
+	cases := []struct {
+		name string
+		fake *fakeAskProvider
+		want string
+	}{
+		{"no reply", &fakeAskProvider{recvErr: context.DeadlineExceeded}, "no reply to the test message in chat 777"},
+		{"bad chat", &fakeAskProvider{sendErr: errors.New("telegram sendMessage status 400: {\"ok\":false,\"description\":\"Bad Request: chat not found\"}")}, "re-link it with `consult-human setup --link-chat`"},
+		{"webhook", &fakeAskProvider{recvErr: errors.New("telegram webhook is configured at https://example.com/hook")}, "webhook conflict"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			stubAskProvider(t, tc.fake)
+			cfg := config.Default()
+			cfg.Telegram.BotToken = "token"
+			cfg.Telegram.ChatID = 777
+			if err := config.Save(cfg); err != nil {
+				t.Fatalf("Save returned error: %v", err)
+			}
+
+			err := runSetup([]string{"--test"}, IO{In: strings.NewReader(""), Out: &bytes.Buffer{}, ErrOut: &bytes.Buffer{}})
+			if err == nil || !strings.Contains(err.Error(), tc.want) {
+				t.Fatalf("expected error containing %q, got %v", tc.want, err)
+			}
+		})
+	}
+}
+
+func TestRunSetupNonInteractiveEnsuresShellPath(t *testing.T) {
+	cfgPath := filepath.Join(t.TempDir(), "config.yaml")
+	t.Setenv(config.EnvConfigPath, cfgPath)
+
+	origEnsurePathFn := setupEnsureShellPathFn
+	calls := 0
+	setupEnsureShellPathFn = func() (setupShellPathStatus, error) {
+		calls++
+		return setupShellPathStatus{
+			Shell:          "zsh",
+			ProfilePath:    "/tmp/.zshenv",
+			BinaryDir:      "/tmp/bin",
+			AlreadyPresent: true,
+		}, nil
+	}
+	defer func() { setupEnsureShellPathFn = origEnsurePathFn }()
+
+	var out bytes.Buffer
+	var errOut bytes.Buffer
+
+	if err := runSetup([]string{"--non-interactive"}, IO{
+		In:     strings.NewReader(""),
+		Out:    &out,
+		ErrOut: &errOut,
+	}); err != nil {
+		t.Fatalf("runSetup returned error: %v", err)
+	}
+
+	if calls != 1 {
+		t.Fatalf("expected shell-path ensure to be called once, got %d", calls)
+	}
+}
+
+func TestRunSetupNonInteractiveRejectsWhatsAppProvider(t *testing.T) {
+	cfgPath := filepath.Join(t.TempDir(), "config.yaml")
+	t.Setenv(config.EnvConfigPath, cfgPath)
+	stubSetupEnsureShellPath(t)
+
+	var out bytes.Buffer
+	var errOut bytes.Buffer
+
+	err := runSetup([]string{"--non-interactive", "--provider", "whatsapp"}, IO{
+		In:     strings.NewReader(""),
+		Out:    &out,
+		ErrOut: &errOut,
+	})
+	if err == nil {
+		t.Fatalf("expected error for disabled whatsapp provider")
+	}
+	if !strings.Contains(err.Error(), "temporarily disabled") {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestRunSetupNonInteractiveShowsConfiguredProviderStatus(t *testing.T) {
+	cfgPath := filepath.Join(t.TempDir(), "config.yaml")
+	t.Setenv(config.EnvConfigPath, cfgPath)
+	stubSetupEnsureShellPath(t)
+
+	cfg := config.Default()
+	cfg.Telegram.BotToken = "existing-token"
+	cfg.Telegram.ChatID = 111
+	if err := config.Save(cfg); err != nil {
 		t.Fatalf("config.Save returned error: %v", err)
 	}
 
@@ -525,21 +1592,25 @@ func TestWaitForTelegramStartWithBaseURL(t *testing.T) {
 		}
 		calls++
 		w.Header().Set("Content-Type", "application/json")
-		if calls == 1 {
+		switch calls {
+		case 1:
 			_, _ = io.WriteString(w, `{"ok":true,"result":[{"update_id":1,"message":{"text":"/help","chat":{"id":123}}}]}`)
-			return
+		case 2:
+			// Plain /start and another machine's code do not link.
+			_, _ = io.WriteString(w, `{"ok":true,"result":[{"update_id":2,"message":{"text":"/start","chat":{"id":124}}},{"update_id":3,"message":{"text":"/start other","chat":{"id":125}}}]}`)
+		default:
+			_, _ = io.WriteString(w, `{"ok":true,"result":[{"update_id":4,"message":{"text":"/start c0de","chat":{"id":456},"from":{"id":42,"username":"alice"}}}]}`)
 		}
-		_, _ = io.WriteString(w, `{"ok":true,"result":[{"update_id":2,"message":{"text":"/start","chat":{"id":456}}}]}`)
 	}))
 	defer srv.Close()
 
 	var out bytes.Buffer
-	chatID, err := waitForTelegramStartWithBaseURL(srv.URL, 2*time.Second, &out)
+	link, err := waitForTelegramStartWithBaseURL(srv.URL, "c0de", 2*time.Second, &out)
 	if err != nil {
 		t.Fatalf("waitForTelegramStartWithBaseURL returned error: %v", err)
 	}
-	if chatID != 456 {
-		t.Fatalf("expected chatID 456, got %d", chatID)
+	if link.ChatID != 456 || link.UserID != 42 || link.Username != "alice" {
+		t.Fatalf("unexpected link: %#v", link)
 	}
 }
 
@@ -551,7 +1622,7 @@ func TestWaitForTelegramStartWithBaseURLTimeout(t *testing.T) {
 	defer srv.Close()
 
 	var out bytes.Buffer
-	_, err := waitForTelegramStartWithBaseURL(srv.URL, 120*time.Millisecond, &out)
+	_, err := waitForTelegramStartWithBaseURL(srv.URL, "code", 120*time.Millisecond, &out)
 	if err == nil {
 		t.Fatalf("expected timeout error")
 	}
@@ -568,7 +1639,7 @@ func TestWaitForTelegramStartWithBaseURLWebhookActive(t *testing.T) {
 	defer srv.Close()
 
 	var out bytes.Buffer
-	_, err := waitForTelegramStartWithBaseURL(srv.URL, time.Second, &out)
+	_, err := waitForTelegramStartWithBaseURL(srv.URL, "code", time.Second, &out)
 	if err == nil {
 		t.Fatalf("expected webhook-active error")
 	}
@@ -0,0 +1,105 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+
+	"github.com/AlhasanIQ/consult-human/config"
+)
+
+const setupWebhookDefaultListen = "127.0.0.1:8787"
+
+// runWebhookSetup collects the bridge URLs. Nothing is sent during setup;
+// the bridge may not be running yet.
+func runWebhookSetup(reader *bufio.Reader, s *sty, cfg *config.Config) error {
+	s.section("Webhook")
+	fmt.Fprintf(s.w, "  Questions are POSTed as JSON to your bridge; answers come back as JSON.\n")
+	fmt.Fprintf(s.w, "  Run `consult-human config --help` for the JSON contract.\n\n")
+
+	if err := promptWebhookKey(reader, s, cfg, "webhook.url", "Question URL (POST): ", true); err != nil {
+		return err
+	}
+	if err := promptWebhookKey(reader, s, cfg, "webhook.secret", "Shared secret for signatures (Enter for none): ", false); err != nil {
+		return err
+	}
+
+	s.step(1, "Listen for answers POSTed back (the bridge must reach this machine)")
+	s.step(2, "Poll the bridge for answers")
+	var mode string
+	for {
+		line, err := promptLine(reader, s.w, s.promptLabel("Receive answers by [1]: "))
+		if err != nil {
+			return err
+		}
+		if line == "" || line == "1" || line == "2" {
+			mode = line
+			break
+		}
+		s.errMsg("enter 1 or 2")
+	}
+
+	if mode == "2" {
+		cfg.Webhook.CallbackListen = ""
+		cfg.Webhook.TLSCert, cfg.Webhook.TLSKey = "", ""
+		return promptWebhookKey(reader, s, cfg, "webhook.poll_url", "Answer URL (GET ?request_id=): ", true)
+	}
+	cfg.Webhook.PollURL = ""
+	for {
+		line, err := promptLine(reader, s.w, s.promptLabel(fmt.Sprintf("Listen address [%s]: ", setupWebhookDefaultListen)))
+		if err != nil {
+			return err
+		}
+		if line == "" {
+			line = setupWebhookDefaultListen
+		}
+		if err := config.Set(cfg, "webhook.callback_listen", line); err != nil {
+			s.errMsg(err.Error())
+			continue
+		}
+		break
+	}
+	if err := promptWebhookKey(reader, s, cfg, "webhook.tls_cert", "TLS certificate path (Enter for plain HTTP): ", false); err != nil {
+		return err
+	}
+	if cfg.Webhook.TLSCert == "" {
+		return nil
+	}
+	return promptWebhookKey(reader, s, cfg, "webhook.tls_key", "TLS key path: ", true)
+}
+
+func promptWebhookKey(reader *bufio.Reader, s *sty, cfg *config.Config, key, label string, required bool) error {
+	for {
+		var value string
+		var err error
+		if required {
+			value, err = promptRequiredLine(reader, s, s.promptLabel(label))
+		} else {
+			value, err = promptLine(reader, s.w, s.promptLabel(label))
+		}
+		if err != nil {
+			return err
+		}
+		if err := config.Set(cfg, key, value); err != nil {
+			s.errMsg(err.Error())
+			continue
+		}
+		return nil
+	}
+}
+
+func writeWebhookChecklist(w io.Writer, alreadySetup bool) {
+	if alreadySetup {
+		fmt.Fprintln(w, "Webhook (already set up):")
+		fmt.Fprintln(w, "  Status: already configured.")
+		fmt.Fprintln(w, "  Reconfigure first: `consult-human config reset --provider webhook`.")
+		fmt.Fprintln(w, "  Fresh setup steps (if reconfiguring):")
+	} else {
+		fmt.Fprintln(w, "Webhook:")
+	}
+	fmt.Fprintln(w, "  Step 1: Run a bridge that accepts questions as JSON; `consult-human config --help` lists the JSON contract.")
+	fmt.Fprintln(w, "  Step 2: Run `consult-human config set webhook.url <URL>` and, to sign requests, `consult-human config set webhook.secret <SECRET>`.")
+	fmt.Fprintf(w, "  Step 3: Run `consult-human config set webhook.callback_listen %s` for the bridge to POST answers to, or `consult-human config set webhook.poll_url <URL>` to poll it instead.\n", setupWebhookDefaultListen)
+	fmt.Fprintln(w, "  For HTTPS callbacks, run `consult-human config set webhook.tls_cert <PATH>` and `consult-human config set webhook.tls_key <PATH>`.")
+	fmt.Fprintln(w)
+}
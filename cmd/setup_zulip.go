@@ -0,0 +1,138 @@
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/AlhasanIQ/consult-human/config"
+)
+
+var errZulipSetupLoginRejected = errors.New("login rejected by Zulip, double-check the bot email and API key from its zuliprc")
+
+var zulipSetupWhoAmIFn = func(site, email, apiKey string) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), setupSlackRequestTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, strings.TrimRight(site, "/")+"/api/v1/users/me", nil)
+	if err != nil {
+		return "", err
+	}
+	req.SetBasicAuth(email, apiKey)
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusUnauthorized {
+		return "", errZulipSetupLoginRejected
+	}
+	var decoded struct {
+		Result   string `json:"result"`
+		Msg      string `json:"msg"`
+		FullName string `json:"full_name"`
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(&decoded); err != nil {
+		return "", fmt.Errorf("zulip status %d: %w", resp.StatusCode, err)
+	}
+	if decoded.Result != "success" {
+		return "", fmt.Errorf("zulip status %d: %s", resp.StatusCode, decoded.Msg)
+	}
+	return decoded.FullName, nil
+}
+
+func runZulipSetup(reader *bufio.Reader, s *sty, cfg *config.Config, skipVerify bool) error {
+	s.section("Zulip")
+	fmt.Fprintf(s.w, "  Create a bot for consult-human:\n\n")
+	s.step(1, "In Zulip, open "+s.bold("Personal settings > Bots")+" and add a Generic bot")
+	s.step(2, "Download its zuliprc; it holds the bot email and API key")
+	fmt.Fprintln(s.w)
+
+	for {
+		for _, p := range []struct{ key, label string }{
+			{"zulip.site", "Organization URL (e.g. https://example.zulipchat.com): "},
+			{"zulip.email", "Bot email: "},
+			{"zulip.api_key", "Bot API key: "},
+		} {
+			for {
+				value, err := promptRequiredLine(reader, s, s.promptLabel(p.label))
+				if err != nil {
+					return err
+				}
+				if err := config.Set(cfg, p.key, value); err != nil {
+					s.errMsg(err.Error())
+					continue
+				}
+				break
+			}
+		}
+		if skipVerify {
+			break
+		}
+		name, err := zulipSetupWhoAmIFn(cfg.Zulip.Site, cfg.Zulip.Email, cfg.Zulip.APIKey)
+		if errors.Is(err, errZulipSetupLoginRejected) {
+			s.errMsg(err.Error())
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("could not verify the zulip bot (use --skip-verify when offline): %w", err)
+		}
+		s.success(fmt.Sprintf("Login OK — bot is %s", name))
+		break
+	}
+
+	fmt.Fprintln(s.w)
+	s.step(1, "Post each question to a stream, in its own topic")
+	s.step(2, "Send questions to you as direct messages")
+	for {
+		line, err := promptLine(reader, s.w, s.promptLabel("Send questions by [1]: "))
+		if err != nil {
+			return err
+		}
+		switch line {
+		case "", "1":
+			cfg.Zulip.Recipient = ""
+			s.info(s.dim("Subscribe the bot to the stream so it can post there."))
+			stream, err := promptRequiredLine(reader, s, s.promptLabel("Stream name: "))
+			if err != nil {
+				return err
+			}
+			return config.Set(cfg, "zulip.stream", stream)
+		case "2":
+			cfg.Zulip.Stream = ""
+			for {
+				recipient, err := promptRequiredLine(reader, s, s.promptLabel("Your Zulip email: "))
+				if err != nil {
+					return err
+				}
+				if err := config.Set(cfg, "zulip.recipient", recipient); err != nil {
+					s.errMsg(err.Error())
+					continue
+				}
+				return nil
+			}
+		default:
+			s.errMsg("enter 1 or 2")
+		}
+	}
+}
+
+func writeZulipChecklist(w io.Writer, alreadySetup bool) {
+	if alreadySetup {
+		fmt.Fprintln(w, "Zulip (already set up):")
+		fmt.Fprintln(w, "  Status: already configured.")
+		fmt.Fprintln(w, "  Reconfigure first: `consult-human config reset --provider zulip`.")
+		fmt.Fprintln(w, "  Fresh setup steps (if reconfiguring):")
+	} else {
+		fmt.Fprintln(w, "Zulip:")
+	}
+	fmt.Fprintln(w, "  Step 1: In Zulip, add a Generic bot under Personal settings > Bots and download its zuliprc.")
+	fmt.Fprintln(w, "  Step 2: Run `consult-human config set zulip.site <ORGANIZATION_URL>`, `consult-human config set zulip.email <BOT_EMAIL>` and `consult-human config set zulip.api_key <API_KEY>`.")
+	fmt.Fprintln(w, "  Step 3: Subscribe the bot to a stream and run `consult-human config set zulip.stream <STREAM>`, or run `consult-human config set zulip.recipient <YOUR_EMAIL>` for direct messages.")
+	fmt.Fprintln(w)
+}
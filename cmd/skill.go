@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"os/exec"
 	"path/filepath"
 	"strings"
 
@@ -15,21 +16,38 @@ import (
 
 const (
 	skillSubcommandInstall = "install"
+	skillSubcommandStatus  = "status"
+	skillSubcommandUpdate  = "update"
 
-	skillTargetClaude = "claude"
-	skillTargetCodex  = "codex"
-	skillTargetBoth   = "both"
+	skillTargetClaude   = "claude"
+	skillTargetCodex    = "codex"
+	skillTargetCursor   = "cursor"
+	skillTargetWindsurf = "windsurf"
+	skillTargetGemini   = "gemini"
+	skillTargetOpencode = "opencode"
+	skillTargetBoth     = "both"
 
 	skillFileName = "SKILL.md"
 
-	claudeInstructionsFileName = "CLAUDE.md"
-	agentsInstructionsFileName = "AGENTS.md"
+	// Cursor and Windsurf read rule files rather than skill directories.
+	cursorRuleFileName   = "consult-human.mdc"
+	windsurfRuleFileName = "consult-human.md"
+
+	claudeInstructionsFileName   = "CLAUDE.md"
+	agentsInstructionsFileName   = "AGENTS.md"
+	cursorInstructionsFileName   = ".cursorrules"
+	windsurfInstructionsFileName = ".windsurfrules"
+	geminiInstructionsFileName   = "GEMINI.md"
 
 	consultHumanReminderStart = "<!-- consult-human reminder:start -->"
 	consultHumanReminderEnd   = "<!-- consult-human reminder:end -->"
 	consultHumanReminderBody  = "## IMPORTANT: Never Forget Human Consultation\nNever forget: before any high-leverage decision, risky or destructive opearation, side-effecting operation, tech-debr inducing design/desision or whenever you are unsure, consult the human with `/consult-human` skill before proceeding. Always check the `/consult-human` skill before consulting.\nWhenever you want to ask/discuss/talk to the human (user), use this skill. Whenever you are unsure about next steps, use this skill."
 )
 
+var skillTargetOrder = []string{skillTargetClaude, skillTargetCodex, skillTargetCursor, skillTargetWindsurf, skillTargetGemini, skillTargetOpencode}
+
+var skillLookPathFn = exec.LookPath
+
 var skillTemplateEmbedded []byte
 
 // SetEmbeddedSkillTemplate sets the runtime-managed skill template bytes.
@@ -53,6 +71,10 @@ func runSkill(args []string, io IO) error {
 	switch sub {
 	case skillSubcommandInstall:
 		return runSkillInstall(subArgs, io)
+	case skillSubcommandStatus:
+		return runSkillStatus(subArgs, io)
+	case skillSubcommandUpdate:
+		return runSkillUpdate(subArgs, io)
 	case "help", "--help", "-h":
 		printSkillUsage(io.Out)
 		return nil
@@ -64,9 +86,11 @@ func runSkill(args []string, io IO) error {
 
 func printSkillUsage(w io.Writer) {
 	fmt.Fprintln(w, "Usage:")
-	fmt.Fprintln(w, "  consult-human skill install [--target claude|codex|both] [--repo <path>] [--copy] [--source <SKILL.md path>]")
+	fmt.Fprintln(w, "  consult-human skill install [--target claude|codex|cursor|windsurf|gemini|opencode|both] [--force-all] [--repo <path>] [--copy] [--source <SKILL.md path>] [--dry-run]")
+	fmt.Fprintln(w, "  consult-human skill status [--repo <path>] [--output text|json]")
+	fmt.Fprintln(w, "  consult-human skill update [--repo <path>]... [--check]")
 	fmt.Fprintln(w, "")
-	fmt.Fprintln(w, "Install consult-human SKILL.md into local agent skill directories.")
+	fmt.Fprintln(w, "Install consult-human SKILL.md into local agent skill directories, check where it is installed, or refresh installs from this binary.")
 }
 
 func runSkillInstall(args []string, io IO) error {
@@ -79,17 +103,21 @@ func runSkillInstall(args []string, io IO) error {
 	var linkMode bool
 	var copyMode bool
 
-	fs.StringVar(&targetRaw, "target", "", "Install target (claude|codex|both). Defaults to both.")
+	var forceAll bool
+	var dryRun bool
+	fs.StringVar(&targetRaw, "target", "", "Install target (claude|codex|cursor|windsurf|gemini|opencode|both), or a comma-separated list. Defaults to both, which also covers the other runtimes that are installed.")
+	fs.BoolVar(&forceAll, "force-all", false, "Install for every target, whether its runtime is installed or not")
 	fs.StringVar(&sourceRaw, "source", "", "Local SKILL.md source path (optional)")
 	fs.StringVar(&repoRaw, "repo", "", "Install inside this repo path instead of user-global directories")
 	fs.BoolVar(&linkMode, "link", true, "Symlink SKILL.md instead of copying file contents (default true)")
 	fs.BoolVar(&copyMode, "copy", false, "Copy SKILL.md instead of symlinking")
+	fs.BoolVar(&dryRun, "dry-run", false, "Print what would be installed and changed without writing anything")
 
 	if err := fs.Parse(args); err != nil {
 		return err
 	}
 	if fs.NArg() != 0 {
-		return fmt.Errorf("usage: consult-human skill install [--target claude|codex|both] [--repo <path>] [--copy] [--source <SKILL.md path>]")
+		return fmt.Errorf("usage: consult-human skill install [--target claude|codex|cursor|windsurf|gemini|opencode|both] [--force-all] [--repo <path>] [--copy] [--source <SKILL.md path>] [--dry-run]")
 	}
 	if copyMode {
 		linkMode = false
@@ -102,8 +130,20 @@ func runSkillInstall(args []string, io IO) error {
 	if err != nil {
 		return err
 	}
+	if forceAll {
+		if target != skillTargetBoth {
+			return fmt.Errorf("--force-all only goes with --target both or all")
+		}
+		target = strings.Join(skillTargetOrder, ",")
+	}
 
-	sourceBytes, sourcePath, sourceLabel, err := loadSkillSource(strings.TrimSpace(sourceRaw))
+	var sourceBytes []byte
+	var sourcePath, sourceLabel string
+	if dryRun {
+		sourcePath, sourceLabel, err = previewSkillSource(strings.TrimSpace(sourceRaw))
+	} else {
+		sourceBytes, sourcePath, sourceLabel, err = loadSkillSource(strings.TrimSpace(sourceRaw))
+	}
 	if err != nil {
 		return err
 	}
@@ -128,13 +168,15 @@ func runSkillInstall(args []string, io IO) error {
 	if repoRoot != "" {
 		scope = "repo: " + repoRoot
 	}
+	if dryRun {
+		return printSkillInstallDryRun(io, mode, scope, sourceLabel, destinations, notes, target, repoRoot)
+	}
 	fmt.Fprintf(io.ErrOut, "Installing skill (%s mode, %s) from %s\n", mode, scope, sourceLabel)
 	for _, dst := range destinations {
-		targetFile, err := installSkillFile(dst, sourceBytes, sourcePath, linkMode)
-		if err != nil {
-			return fmt.Errorf("install to %s: %w", dst, err)
+		if err := installSkillFile(dst, sourceBytes, sourcePath, linkMode); err != nil {
+			return fmt.Errorf("install to %s: %w", filepath.Dir(dst), err)
 		}
-		fmt.Fprintf(io.ErrOut, "Installed %s\n", targetFile)
+		fmt.Fprintf(io.ErrOut, "Installed %s\n", dst)
 	}
 	for _, note := range notes {
 		fmt.Fprintf(io.ErrOut, "Note: %s\n", note)
@@ -159,18 +201,71 @@ func runSkillInstall(args []string, io IO) error {
 }
 
 func normalizeSkillTarget(raw string) (string, error) {
-	target := strings.ToLower(strings.TrimSpace(raw))
-	switch target {
-	case "cloud", "cloud-code", "claude-code":
-		return skillTargetClaude, nil
-	case skillTargetClaude, skillTargetCodex, skillTargetBoth, "all":
-		if target == "all" {
+	selected := map[string]bool{}
+	for _, part := range strings.Split(raw, ",") {
+		target := strings.ToLower(strings.TrimSpace(part))
+		switch target {
+		case "cloud", "cloud-code", "claude-code":
+			target = skillTargetClaude
+		case "all":
+			target = skillTargetBoth
+		case skillTargetClaude, skillTargetCodex, skillTargetCursor, skillTargetWindsurf, skillTargetGemini, skillTargetOpencode, skillTargetBoth:
+		default:
+			return "", fmt.Errorf("target must be claude, codex, cursor, windsurf, gemini, opencode, or both")
+		}
+		if target == skillTargetBoth {
 			return skillTargetBoth, nil
 		}
-		return target, nil
-	default:
-		return "", fmt.Errorf("target must be claude, codex, or both")
+		selected[target] = true
+	}
+	return joinSkillTargets(selected), nil
+}
+
+func joinSkillTargets(selected map[string]bool) string {
+	targets := make([]string, 0, len(selected))
+	for _, target := range skillTargetOrder {
+		if selected[target] {
+			targets = append(targets, target)
+		}
+	}
+	return strings.Join(targets, ",")
+}
+
+// skillTargetBoth selects every tool; callers check skillToolDetected for the optional ones.
+func skillTargetSelects(target, tool string) bool {
+	if target == skillTargetBoth {
+		return true
+	}
+	for _, t := range strings.Split(target, ",") {
+		if t == tool {
+			return true
+		}
+	}
+	return false
+}
+
+func skillToolDetected(target, dir, binary string) bool {
+	if target != skillTargetBoth {
+		return true
 	}
+	if info, err := os.Stat(dir); err == nil && info.IsDir() {
+		return true
+	}
+	if binary == "" {
+		return false
+	}
+	_, err := skillLookPathFn(binary)
+	return err == nil
+}
+
+func opencodeRoot(repoRoot, home string) string {
+	if repoRoot != "" {
+		return filepath.Join(repoRoot, ".opencode")
+	}
+	if xdg := strings.TrimSpace(os.Getenv("XDG_CONFIG_HOME")); xdg != "" {
+		return filepath.Join(xdg, "opencode")
+	}
+	return filepath.Join(home, ".config", "opencode")
 }
 
 func resolveRepoRoot(raw string) (string, error) {
@@ -215,17 +310,29 @@ func resolveSkillDestinations(target string, repoRoot string) ([]string, []strin
 	}
 
 	var notes []string
-	if target == skillTargetClaude || target == skillTargetBoth {
-		add(filepath.Join(baseRoot, ".claude", "skills", "consult-human"))
+	if skillTargetSelects(target, skillTargetClaude) {
+		add(filepath.Join(baseRoot, ".claude", "skills", "consult-human", skillFileName))
 
 		agentsRoot := filepath.Join(baseRoot, ".agents")
 		if info, statErr := os.Stat(agentsRoot); statErr == nil && info.IsDir() {
-			add(filepath.Join(agentsRoot, "skills", "consult-human"))
+			add(filepath.Join(agentsRoot, "skills", "consult-human", skillFileName))
 		}
 	}
 
-	if target == skillTargetCodex || target == skillTargetBoth {
-		add(filepath.Join(baseRoot, ".codex", "skills", "consult-human"))
+	if skillTargetSelects(target, skillTargetCodex) {
+		add(filepath.Join(baseRoot, ".codex", "skills", "consult-human", skillFileName))
+	}
+	if skillTargetSelects(target, skillTargetCursor) && skillToolDetected(target, filepath.Join(baseRoot, ".cursor"), "") {
+		add(filepath.Join(baseRoot, ".cursor", "rules", cursorRuleFileName))
+	}
+	if skillTargetSelects(target, skillTargetWindsurf) && skillToolDetected(target, filepath.Join(baseRoot, ".windsurf"), "") {
+		add(filepath.Join(baseRoot, ".windsurf", "rules", windsurfRuleFileName))
+	}
+	if skillTargetSelects(target, skillTargetGemini) && skillToolDetected(target, filepath.Join(baseRoot, ".gemini"), "gemini") {
+		add(filepath.Join(baseRoot, ".gemini", "skills", "consult-human", skillFileName))
+	}
+	if root := opencodeRoot(repoRoot, baseRoot); skillTargetSelects(target, skillTargetOpencode) && skillToolDetected(target, root, "opencode") {
+		add(filepath.Join(root, "skill", "consult-human", skillFileName))
 	}
 
 	return destinations, notes, nil
@@ -254,7 +361,7 @@ func resolveInstructionReminderTargets(target string, repoRoot string) ([]string
 		targets = append(targets, path)
 	}
 
-	if target == skillTargetClaude || target == skillTargetBoth {
+	if skillTargetSelects(target, skillTargetClaude) {
 		add(filepath.Join(baseRoot, ".claude", claudeInstructionsFileName))
 
 		agentsRoot := filepath.Join(baseRoot, ".agents")
@@ -262,9 +369,26 @@ func resolveInstructionReminderTargets(target string, repoRoot string) ([]string
 			add(filepath.Join(agentsRoot, agentsInstructionsFileName))
 		}
 	}
-	if target == skillTargetCodex || target == skillTargetBoth {
+	if skillTargetSelects(target, skillTargetCodex) {
 		add(filepath.Join(baseRoot, ".codex", agentsInstructionsFileName))
 	}
+	if skillTargetSelects(target, skillTargetCursor) && skillToolDetected(target, filepath.Join(baseRoot, ".cursor"), "") {
+		add(filepath.Join(baseRoot, cursorInstructionsFileName))
+	}
+	if skillTargetSelects(target, skillTargetWindsurf) && skillToolDetected(target, filepath.Join(baseRoot, ".windsurf"), "") {
+		add(filepath.Join(baseRoot, windsurfInstructionsFileName))
+	}
+	if skillTargetSelects(target, skillTargetGemini) && skillToolDetected(target, filepath.Join(baseRoot, ".gemini"), "gemini") {
+		add(filepath.Join(baseRoot, ".gemini", geminiInstructionsFileName))
+	}
+	if root := opencodeRoot(repoRoot, baseRoot); skillTargetSelects(target, skillTargetOpencode) && skillToolDetected(target, root, "opencode") {
+		// In a repo opencode reads AGENTS.md at the root, not in .opencode.
+		if repoRoot != "" {
+			add(filepath.Join(repoRoot, agentsInstructionsFileName))
+		} else {
+			add(filepath.Join(root, agentsInstructionsFileName))
+		}
+	}
 
 	return targets, nil
 }
@@ -331,35 +455,31 @@ func upsertConsultHumanReminderBlock(content string, desiredBlock string) (strin
 	return trimmed + "\n\n" + desiredBlock + "\n", true
 }
 
-func installSkillFile(destinationDir string, sourceBytes []byte, sourcePath string, linkMode bool) (string, error) {
-	if err := os.MkdirAll(destinationDir, 0o755); err != nil {
-		return "", err
+func installSkillFile(targetFile string, sourceBytes []byte, sourcePath string, linkMode bool) error {
+	if err := os.MkdirAll(filepath.Dir(targetFile), 0o755); err != nil {
+		return err
 	}
 
-	targetFile := filepath.Join(destinationDir, skillFileName)
 	if linkMode {
 		absSourcePath, err := filepath.Abs(sourcePath)
 		if err != nil {
-			return "", err
+			return err
 		}
 		if err := os.Remove(targetFile); err != nil && !errors.Is(err, os.ErrNotExist) {
-			return "", err
-		}
-		if err := os.Symlink(absSourcePath, targetFile); err != nil {
-			return "", err
+			return err
 		}
-		return targetFile, nil
+		return os.Symlink(absSourcePath, targetFile)
 	}
 
 	tmpFile := targetFile + ".tmp"
 	if err := os.WriteFile(tmpFile, sourceBytes, 0o644); err != nil {
-		return "", err
+		return err
 	}
 	if err := os.Rename(tmpFile, targetFile); err != nil {
 		_ = os.Remove(tmpFile)
-		return "", err
+		return err
 	}
-	return targetFile, nil
+	return nil
 }
 
 func loadSkillSource(sourcePathRaw string) ([]byte, string, string, error) {
@@ -412,6 +532,77 @@ func loadSkillSource(sourcePathRaw string) ([]byte, string, string, error) {
 	return skillTemplateEmbedded, managedPath, fmt.Sprintf("%s (%s)", managedPath, status), nil
 }
 
+// Unlike loadSkillSource it never seeds or refreshes the managed source.
+func previewSkillSource(sourcePathRaw string) (string, string, error) {
+	if strings.TrimSpace(sourcePathRaw) != "" {
+		path, err := config.ExpandPath(sourcePathRaw)
+		if err != nil {
+			return "", "", err
+		}
+		b, err := os.ReadFile(path)
+		if err != nil {
+			return "", "", err
+		}
+		if strings.TrimSpace(string(b)) == "" {
+			return "", "", fmt.Errorf("empty skill source: %s", path)
+		}
+		return path, path, nil
+	}
+
+	managedPath, err := defaultManagedSkillSourcePath()
+	if err != nil {
+		return "", "", err
+	}
+	embedded := bytes.TrimSpace(skillTemplateEmbedded)
+	if len(embedded) == 0 {
+		return "", "", fmt.Errorf("embedded skill template is empty")
+	}
+	existing, readErr := os.ReadFile(managedPath)
+	switch {
+	case readErr == nil && bytes.Equal(bytes.TrimSpace(existing), embedded):
+		return managedPath, managedPath, nil
+	case readErr == nil:
+		return managedPath, fmt.Sprintf("%s (would be updated from embedded template)", managedPath), nil
+	case errors.Is(readErr, os.ErrNotExist):
+		return managedPath, fmt.Sprintf("%s (would be seeded from embedded template)", managedPath), nil
+	default:
+		return "", "", readErr
+	}
+}
+
+func printSkillInstallDryRun(io IO, mode, scope, sourceLabel string, destinations, notes []string, target, repoRoot string) error {
+	fmt.Fprintf(io.ErrOut, "Dry run: installing skill (%s mode, %s) from %s\n", mode, scope, sourceLabel)
+	for _, dst := range destinations {
+		fmt.Fprintf(io.ErrOut, "would install %s (%s)\n", dst, mode)
+	}
+	for _, note := range notes {
+		fmt.Fprintf(io.ErrOut, "Note: %s\n", note)
+	}
+
+	reminderTargets, err := resolveInstructionReminderTargets(target, repoRoot)
+	if err != nil {
+		return err
+	}
+	for _, reminderFile := range reminderTargets {
+		entry, err := reminderFileStatus(reminderFile)
+		if err != nil {
+			return err
+		}
+		switch {
+		case entry.Status == skillStatusCurrent:
+			fmt.Fprintf(io.ErrOut, "reminder already present in %s\n", reminderFile)
+		case entry.Status == skillStatusOutdated:
+			fmt.Fprintf(io.ErrOut, "would update reminder in %s (replaces an outdated block)\n", reminderFile)
+		case entry.Exists:
+			fmt.Fprintf(io.ErrOut, "would update reminder in %s (appends the block)\n", reminderFile)
+		default:
+			fmt.Fprintf(io.ErrOut, "would update reminder in %s (creates the file)\n", reminderFile)
+		}
+	}
+	fmt.Fprintln(io.ErrOut, "Dry run: nothing was written")
+	return nil
+}
+
 func defaultManagedSkillSourcePath() (string, error) {
 	cfgPath, err := config.ConfigPath()
 	if err != nil {
@@ -0,0 +1,212 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+)
+
+// skill status values. Only a copied skill file that is skillStatusDrifted
+// fails `skill status`: a symlink follows its source, so an outdated one is
+// fixed by running `skill install` again.
+const (
+	skillStatusCurrent  = "current"
+	skillStatusMissing  = "missing"
+	skillStatusBroken   = "broken-link"
+	skillStatusOutdated = "outdated"
+	skillStatusDrifted  = "drifted"
+)
+
+// skillStatusEntry is one skill file or agent instructions file as `skill
+// status` reports it.
+type skillStatusEntry struct {
+	Kind       string `json:"kind"`
+	Path       string `json:"path"`
+	Exists     bool   `json:"exists"`
+	Mode       string `json:"mode,omitempty"`
+	LinkTarget string `json:"link_target,omitempty"`
+	Status     string `json:"status"`
+}
+
+func runSkillStatus(args []string, io IO) error {
+	fs := flag.NewFlagSet("skill status", flag.ContinueOnError)
+	fs.SetOutput(io.ErrOut)
+
+	var repoRaw string
+	var output string
+	fs.StringVar(&repoRaw, "repo", "", "Check the install inside this repo path instead of user-global directories")
+	fs.StringVar(&output, "output", "text", "Output format (text|json)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 0 {
+		return fmt.Errorf("usage: consult-human skill status [--repo <path>] [--output text|json]")
+	}
+	output = strings.ToLower(strings.TrimSpace(output))
+	if output != "text" && output != "json" {
+		return fmt.Errorf("--output must be text or json")
+	}
+	repoRoot, err := resolveRepoRoot(repoRaw)
+	if err != nil {
+		return err
+	}
+
+	entries, err := collectSkillStatus(repoRoot)
+	if err != nil {
+		return err
+	}
+
+	if output == "json" {
+		enc := json.NewEncoder(io.Out)
+		enc.SetEscapeHTML(false)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(entries); err != nil {
+			return err
+		}
+	} else {
+		tw := tabwriter.NewWriter(io.Out, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(tw, "KIND\tSTATUS\tMODE\tPATH")
+		for _, e := range entries {
+			mode := e.Mode
+			if mode == "" {
+				mode = "-"
+			}
+			path := e.Path
+			if e.LinkTarget != "" {
+				path += " -> " + e.LinkTarget
+			}
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", e.Kind, e.Status, mode, path)
+		}
+		if err := tw.Flush(); err != nil {
+			return err
+		}
+	}
+
+	drifted := 0
+	for _, e := range entries {
+		if e.Status == skillStatusDrifted {
+			drifted++
+		}
+	}
+	if drifted > 0 {
+		return fmt.Errorf("%d copied skill file(s) differ from the skill this binary ships; run `consult-human skill install --copy` to update them", drifted)
+	}
+	return nil
+}
+
+// collectSkillStatus checks every skill and reminder destination of both
+// targets under repoRoot, or the home directory when it is empty.
+func collectSkillStatus(repoRoot string) ([]skillStatusEntry, error) {
+	reference, err := skillReferenceContent()
+	if err != nil {
+		return nil, err
+	}
+	destinations, _, err := resolveSkillDestinations(skillTargetBoth, repoRoot)
+	if err != nil {
+		return nil, err
+	}
+	reminders, err := resolveInstructionReminderTargets(skillTargetBoth, repoRoot)
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]skillStatusEntry, 0, len(destinations)+len(reminders))
+	for _, path := range destinations {
+		entry, err := skillFileStatus(path, reference)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+	for _, path := range reminders {
+		entry, err := reminderFileStatus(path)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+// skillReferenceContent is the skill an install would write: the embedded
+// template, or the managed source when the binary carries none.
+func skillReferenceContent() ([]byte, error) {
+	if embedded := bytes.TrimSpace(skillTemplateEmbedded); len(embedded) > 0 {
+		return embedded, nil
+	}
+	managedPath, err := defaultManagedSkillSourcePath()
+	if err != nil {
+		return nil, err
+	}
+	b, err := os.ReadFile(managedPath)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+	return bytes.TrimSpace(b), nil
+}
+
+func skillFileStatus(path string, reference []byte) (skillStatusEntry, error) {
+	entry := skillStatusEntry{Kind: "skill", Path: path, Status: skillStatusMissing}
+	info, err := os.Lstat(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return entry, nil
+	}
+	if err != nil {
+		return entry, err
+	}
+	entry.Exists = true
+	entry.Mode = "copy"
+	if info.Mode()&os.ModeSymlink != 0 {
+		entry.Mode = "symlink"
+		if entry.LinkTarget, err = os.Readlink(path); err != nil {
+			return entry, err
+		}
+	}
+
+	content, err := os.ReadFile(path)
+	switch {
+	case err != nil && entry.Mode == "symlink" && errors.Is(err, os.ErrNotExist):
+		entry.Status = skillStatusBroken
+		return entry, nil
+	case err != nil:
+		return entry, err
+	}
+	switch {
+	case len(reference) == 0 || bytes.Equal(bytes.TrimSpace(content), reference):
+		entry.Status = skillStatusCurrent
+	case entry.Mode == "symlink":
+		entry.Status = skillStatusOutdated
+	default:
+		entry.Status = skillStatusDrifted
+	}
+	return entry, nil
+}
+
+func reminderFileStatus(path string) (skillStatusEntry, error) {
+	entry := skillStatusEntry{Kind: "reminder", Path: path, Status: skillStatusMissing}
+	b, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return entry, nil
+	}
+	if err != nil {
+		return entry, err
+	}
+	entry.Exists = true
+	current := string(b)
+	desiredBlock := strings.Join([]string{
+		consultHumanReminderStart,
+		consultHumanReminderBody,
+		consultHumanReminderEnd,
+	}, "\n")
+	if _, changed := upsertConsultHumanReminderBlock(current, desiredBlock); !changed {
+		entry.Status = skillStatusCurrent
+	} else if strings.Contains(current, consultHumanReminderStart) {
+		entry.Status = skillStatusOutdated
+	}
+	return entry, nil
+}
@@ -2,8 +2,10 @@ package cmd
 
 import (
 	"bytes"
+	"encoding/json"
 	"os"
 	"path/filepath"
+	"reflect"
 	"runtime"
 	"strings"
 	"testing"
@@ -121,6 +123,216 @@ func TestRunSkillInstallDefaultBothCreatesCodexWhenMissing(t *testing.T) {
 	}
 }
 
+func TestRunSkillInstallCursorAndWindsurfRules(t *testing.T) {
+	tests := []struct {
+		target       string
+		rulePath     string
+		reminderPath string
+	}{
+		{target: "cursor", rulePath: filepath.Join(".cursor", "rules", "consult-human.mdc"), reminderPath: ".cursorrules"},
+		{target: "windsurf", rulePath: filepath.Join(".windsurf", "rules", "consult-human.md"), reminderPath: ".windsurfrules"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.target, func(t *testing.T) {
+			home := t.TempDir()
+			t.Setenv("HOME", home)
+
+			sourceDir := t.TempDir()
+			sourcePath := filepath.Join(sourceDir, "SKILL.md")
+			sourceContent := tc.target + "-skill"
+			if err := os.WriteFile(sourcePath, []byte(sourceContent), 0o644); err != nil {
+				t.Fatalf("write source: %v", err)
+			}
+
+			var errOut bytes.Buffer
+			err := runSkill([]string{"install", "--target", tc.target, "--source", sourcePath, "--copy"}, IO{
+				In:     strings.NewReader(""),
+				Out:    &bytes.Buffer{},
+				ErrOut: &errOut,
+			})
+			if err != nil {
+				t.Fatalf("runSkill install returned error: %v", err)
+			}
+
+			b, readErr := os.ReadFile(filepath.Join(home, tc.rulePath))
+			if readErr != nil {
+				t.Fatalf("read installed rule: %v", readErr)
+			}
+			if string(b) != sourceContent {
+				t.Fatalf("unexpected installed content: %q", string(b))
+			}
+			reminder, readErr := os.ReadFile(filepath.Join(home, tc.reminderPath))
+			if readErr != nil {
+				t.Fatalf("read reminder file: %v", readErr)
+			}
+			if !strings.Contains(string(reminder), consultHumanReminderStart) {
+				t.Fatalf("expected reminder block in %s, got %q", tc.reminderPath, string(reminder))
+			}
+
+			claudePath := filepath.Join(home, ".claude", "skills", "consult-human", "SKILL.md")
+			if _, statErr := os.Stat(claudePath); !os.IsNotExist(statErr) {
+				t.Fatalf("did not expect claude install path to exist, stat err: %v", statErr)
+			}
+		})
+	}
+}
+
+func TestRunSkillInstallDefaultBothIncludesDetectedEditors(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	if err := os.MkdirAll(filepath.Join(home, ".cursor"), 0o755); err != nil {
+		t.Fatalf("mkdir .cursor: %v", err)
+	}
+
+	sourceDir := t.TempDir()
+	sourcePath := filepath.Join(sourceDir, "SKILL.md")
+	if err := os.WriteFile(sourcePath, []byte("sample"), 0o644); err != nil {
+		t.Fatalf("write source: %v", err)
+	}
+
+	err := runSkill([]string{"install", "--source", sourcePath, "--copy"}, IO{
+		In:     strings.NewReader(""),
+		Out:    &bytes.Buffer{},
+		ErrOut: &bytes.Buffer{},
+	})
+	if err != nil {
+		t.Fatalf("runSkill install returned error: %v", err)
+	}
+
+	if _, statErr := os.Stat(filepath.Join(home, ".cursor", "rules", "consult-human.mdc")); statErr != nil {
+		t.Fatalf("expected cursor rule for detected .cursor dir, stat err: %v", statErr)
+	}
+	for _, path := range []string{filepath.Join(home, ".windsurf"), filepath.Join(home, ".windsurfrules")} {
+		if _, statErr := os.Stat(path); !os.IsNotExist(statErr) {
+			t.Fatalf("did not expect %s without a .windsurf dir, stat err: %v", path, statErr)
+		}
+	}
+}
+
+func TestRunSkillInstallGeminiAndOpencode(t *testing.T) {
+	reminderBlock := strings.Join([]string{consultHumanReminderStart, consultHumanReminderBody, consultHumanReminderEnd}, "\n")
+	tests := []struct {
+		target       string
+		skillPath    string
+		reminderPath string
+	}{
+		{target: "gemini", skillPath: filepath.Join(".gemini", "skills", "consult-human", "SKILL.md"), reminderPath: filepath.Join(".gemini", "GEMINI.md")},
+		{target: "opencode", skillPath: filepath.Join(".config", "opencode", "skill", "consult-human", "SKILL.md"), reminderPath: filepath.Join(".config", "opencode", "AGENTS.md")},
+	}
+	for _, tc := range tests {
+		t.Run(tc.target, func(t *testing.T) {
+			home := t.TempDir()
+			t.Setenv("HOME", home)
+			t.Setenv("XDG_CONFIG_HOME", "")
+
+			sourceDir := t.TempDir()
+			sourcePath := filepath.Join(sourceDir, "SKILL.md")
+			sourceContent := tc.target + "-skill"
+			if err := os.WriteFile(sourcePath, []byte(sourceContent), 0o644); err != nil {
+				t.Fatalf("write source: %v", err)
+			}
+
+			err := runSkill([]string{"install", "--target", tc.target, "--source", sourcePath, "--copy"}, IO{
+				In:     strings.NewReader(""),
+				Out:    &bytes.Buffer{},
+				ErrOut: &bytes.Buffer{},
+			})
+			if err != nil {
+				t.Fatalf("runSkill install returned error: %v", err)
+			}
+
+			b, readErr := os.ReadFile(filepath.Join(home, tc.skillPath))
+			if readErr != nil {
+				t.Fatalf("read installed skill: %v", readErr)
+			}
+			if string(b) != sourceContent {
+				t.Fatalf("unexpected installed content: %q", string(b))
+			}
+			reminder, readErr := os.ReadFile(filepath.Join(home, tc.reminderPath))
+			if readErr != nil {
+				t.Fatalf("read reminder file: %v", readErr)
+			}
+			if !strings.Contains(string(reminder), reminderBlock) {
+				t.Fatalf("expected the shared reminder block in %s, got %q", tc.reminderPath, string(reminder))
+			}
+		})
+	}
+}
+
+func TestRunSkillInstallOpencodeRepoUsesRootAgentsFile(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	repo := t.TempDir()
+
+	sourceDir := t.TempDir()
+	sourcePath := filepath.Join(sourceDir, "SKILL.md")
+	if err := os.WriteFile(sourcePath, []byte("sample"), 0o644); err != nil {
+		t.Fatalf("write source: %v", err)
+	}
+
+	err := runSkill([]string{"install", "--target", "opencode", "--repo", repo, "--source", sourcePath, "--copy"}, IO{
+		In:     strings.NewReader(""),
+		Out:    &bytes.Buffer{},
+		ErrOut: &bytes.Buffer{},
+	})
+	if err != nil {
+		t.Fatalf("runSkill install returned error: %v", err)
+	}
+	if _, statErr := os.Stat(filepath.Join(repo, ".opencode", "skill", "consult-human", "SKILL.md")); statErr != nil {
+		t.Fatalf("expected repo opencode skill, stat err: %v", statErr)
+	}
+	if _, statErr := os.Stat(filepath.Join(repo, "AGENTS.md")); statErr != nil {
+		t.Fatalf("expected reminder in repo AGENTS.md, stat err: %v", statErr)
+	}
+}
+
+func TestRunSkillInstallAllSkipsMissingRuntimesUnlessForced(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CONFIG_HOME", "")
+
+	orig := skillLookPathFn
+	skillLookPathFn = func(name string) (string, error) {
+		if name == "gemini" {
+			return "/usr/local/bin/gemini", nil
+		}
+		return "", os.ErrNotExist
+	}
+	t.Cleanup(func() { skillLookPathFn = orig })
+
+	sourceDir := t.TempDir()
+	sourcePath := filepath.Join(sourceDir, "SKILL.md")
+	if err := os.WriteFile(sourcePath, []byte("sample"), 0o644); err != nil {
+		t.Fatalf("write source: %v", err)
+	}
+	io := IO{In: strings.NewReader(""), Out: &bytes.Buffer{}, ErrOut: &bytes.Buffer{}}
+
+	if err := runSkill([]string{"install", "--target", "all", "--source", sourcePath, "--copy"}, io); err != nil {
+		t.Fatalf("runSkill install returned error: %v", err)
+	}
+	geminiPath := filepath.Join(home, ".gemini", "skills", "consult-human", "SKILL.md")
+	if _, statErr := os.Stat(geminiPath); statErr != nil {
+		t.Fatalf("expected gemini install when gemini is on PATH, stat err: %v", statErr)
+	}
+	opencodePath := filepath.Join(home, ".config", "opencode", "skill", "consult-human", "SKILL.md")
+	if _, statErr := os.Stat(opencodePath); !os.IsNotExist(statErr) {
+		t.Fatalf("did not expect opencode install without opencode, stat err: %v", statErr)
+	}
+
+	if err := runSkill([]string{"install", "--target", "all", "--force-all", "--source", sourcePath, "--copy"}, io); err != nil {
+		t.Fatalf("runSkill install --force-all returned error: %v", err)
+	}
+	for _, path := range []string{opencodePath, filepath.Join(home, ".cursor", "rules", "consult-human.mdc"), filepath.Join(home, ".windsurf", "rules", "consult-human.md")} {
+		if _, statErr := os.Stat(path); statErr != nil {
+			t.Fatalf("expected %s with --force-all, stat err: %v", path, statErr)
+		}
+	}
+
+	if err := runSkill([]string{"install", "--target", "claude", "--force-all", "--source", sourcePath}, io); err == nil {
+		t.Fatalf("expected --force-all with a single target to fail")
+	}
+}
+
 func TestRunSkillInstallClaudeDoesNotEmitAgentsSkipNote(t *testing.T) {
 	home := t.TempDir()
 	t.Setenv("HOME", home)
@@ -280,6 +492,11 @@ func TestNormalizeSkillTargetAliases(t *testing.T) {
 		{in: "codex", want: skillTargetCodex},
 		{in: "both", want: skillTargetBoth},
 		{in: "all", want: skillTargetBoth},
+		{in: "cursor", want: skillTargetCursor},
+		{in: "windsurf", want: skillTargetWindsurf},
+		{in: "windsurf, claude", want: "claude,windsurf"},
+		{in: "cursor,all", want: skillTargetBoth},
+		{in: "opencode,gemini", want: "gemini,opencode"},
 	}
 	for _, tc := range tests {
 		got, err := normalizeSkillTarget(tc.in)
@@ -453,3 +670,244 @@ func TestEnsureConsultHumanReminderPreservesExistingMode(t *testing.T) {
 		t.Fatalf("expected mode %o, got %o", want, got)
 	}
 }
+
+func TestRunSkillStatusReportsInstalls(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	io := IO{In: strings.NewReader(""), Out: &bytes.Buffer{}, ErrOut: &bytes.Buffer{}}
+	if err := runSkill([]string{"install", "--target", "claude", "--copy"}, io); err != nil {
+		t.Fatalf("runSkill install returned error: %v", err)
+	}
+
+	var out bytes.Buffer
+	err := runSkill([]string{"status", "--output", "json"}, IO{In: strings.NewReader(""), Out: &out, ErrOut: &bytes.Buffer{}})
+	if err != nil {
+		t.Fatalf("runSkill status returned error: %v", err)
+	}
+	var entries []skillStatusEntry
+	if err := json.Unmarshal(out.Bytes(), &entries); err != nil {
+		t.Fatalf("decode status: %v\n%s", err, out.String())
+	}
+	got := map[string]skillStatusEntry{}
+	for _, e := range entries {
+		got[e.Path] = e
+	}
+
+	claudeSkill := got[filepath.Join(home, ".claude", "skills", "consult-human", "SKILL.md")]
+	if claudeSkill.Status != skillStatusCurrent || claudeSkill.Mode != "copy" || !claudeSkill.Exists {
+		t.Fatalf("unexpected claude skill status: %+v", claudeSkill)
+	}
+	codexSkill := got[filepath.Join(home, ".codex", "skills", "consult-human", "SKILL.md")]
+	if codexSkill.Status != skillStatusMissing || codexSkill.Exists {
+		t.Fatalf("unexpected codex skill status: %+v", codexSkill)
+	}
+	claudeReminder := got[filepath.Join(home, ".claude", "CLAUDE.md")]
+	if claudeReminder.Kind != "reminder" || claudeReminder.Status != skillStatusCurrent {
+		t.Fatalf("unexpected claude reminder status: %+v", claudeReminder)
+	}
+}
+
+func TestRunSkillStatusFailsOnDriftedCopy(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	io := IO{In: strings.NewReader(""), Out: &bytes.Buffer{}, ErrOut: &bytes.Buffer{}}
+	if err := runSkill([]string{"install", "--target", "claude", "--copy"}, io); err != nil {
+		t.Fatalf("runSkill install returned error: %v", err)
+	}
+	installedPath := filepath.Join(home, ".claude", "skills", "consult-human", "SKILL.md")
+	if err := os.WriteFile(installedPath, []byte("name: consult-human\n\nedited by hand"), 0o644); err != nil {
+		t.Fatalf("edit installed skill: %v", err)
+	}
+
+	var out bytes.Buffer
+	err := runSkill([]string{"status"}, IO{In: strings.NewReader(""), Out: &out, ErrOut: &bytes.Buffer{}})
+	if err == nil || !strings.Contains(err.Error(), "1 copied skill file(s)") {
+		t.Fatalf("expected drift error, got: %v", err)
+	}
+	if !strings.Contains(out.String(), "drifted") || !strings.Contains(out.String(), installedPath) {
+		t.Fatalf("expected drifted row in table, got:\n%s", out.String())
+	}
+}
+
+func TestRunSkillStatusSymlink(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("symlinks need extra privileges on windows")
+	}
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	io := IO{In: strings.NewReader(""), Out: &bytes.Buffer{}, ErrOut: &bytes.Buffer{}}
+	if err := runSkill([]string{"install", "--target", "claude"}, io); err != nil {
+		t.Fatalf("runSkill install returned error: %v", err)
+	}
+	managedPath, err := defaultManagedSkillSourcePath()
+	if err != nil {
+		t.Fatalf("managed source path: %v", err)
+	}
+
+	entries, err := collectSkillStatus("")
+	if err != nil {
+		t.Fatalf("collectSkillStatus returned error: %v", err)
+	}
+	skill := entries[0]
+	if skill.Mode != "symlink" || skill.LinkTarget != managedPath || skill.Status != skillStatusCurrent {
+		t.Fatalf("unexpected symlink status: %+v", skill)
+	}
+
+	if err := os.WriteFile(managedPath, []byte("older skill"), 0o644); err != nil {
+		t.Fatalf("rewrite managed source: %v", err)
+	}
+	if entries, err = collectSkillStatus(""); err != nil {
+		t.Fatalf("collectSkillStatus returned error: %v", err)
+	}
+	if entries[0].Status != skillStatusOutdated {
+		t.Fatalf("expected outdated symlink, got: %+v", entries[0])
+	}
+
+	if err := os.Remove(managedPath); err != nil {
+		t.Fatalf("remove managed source: %v", err)
+	}
+	if entries, err = collectSkillStatus(""); err != nil {
+		t.Fatalf("collectSkillStatus returned error: %v", err)
+	}
+	if entries[0].Status != skillStatusBroken {
+		t.Fatalf("expected broken link, got: %+v", entries[0])
+	}
+}
+
+func TestRunSkillUpdateRefreshesInstalls(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("symlinks need extra privileges on windows")
+	}
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	repo := t.TempDir()
+
+	io := IO{In: strings.NewReader(""), Out: &bytes.Buffer{}, ErrOut: &bytes.Buffer{}}
+	if err := runSkill([]string{"install", "--target", "claude", "--copy"}, io); err != nil {
+		t.Fatalf("runSkill install returned error: %v", err)
+	}
+	if err := runSkill([]string{"install", "--target", "codex", "--repo", repo}, io); err != nil {
+		t.Fatalf("runSkill install --repo returned error: %v", err)
+	}
+
+	original := skillTemplateEmbedded
+	t.Cleanup(func() { SetEmbeddedSkillTemplate(original) })
+	newTemplate := "name: consult-human\n\nnewer instructions\n"
+	SetEmbeddedSkillTemplate([]byte(newTemplate))
+
+	var checkOut bytes.Buffer
+	err := runSkill([]string{"update", "--check", "--repo", repo}, IO{In: strings.NewReader(""), Out: &bytes.Buffer{}, ErrOut: &checkOut})
+	if err == nil || !strings.Contains(err.Error(), "2 installed file(s)") {
+		t.Fatalf("expected check to report 2 outdated files, got: %v\n%s", err, checkOut.String())
+	}
+
+	copyPath := filepath.Join(home, ".claude", "skills", "consult-human", "SKILL.md")
+	linkPath := filepath.Join(repo, ".codex", "skills", "consult-human", "SKILL.md")
+	var out bytes.Buffer
+	if err := runSkill([]string{"update", "--repo", repo}, IO{In: strings.NewReader(""), Out: &bytes.Buffer{}, ErrOut: &out}); err != nil {
+		t.Fatalf("runSkill update returned error: %v", err)
+	}
+	for _, want := range []string{"updated " + copyPath, "updated " + linkPath, "already current " + filepath.Join(home, ".claude", "CLAUDE.md")} {
+		if !strings.Contains(out.String(), want) {
+			t.Fatalf("expected %q in output, got:\n%s", want, out.String())
+		}
+	}
+
+	for _, path := range []string{copyPath, linkPath} {
+		b, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatalf("read %s: %v", path, err)
+		}
+		if string(b) != newTemplate {
+			t.Fatalf("expected %s to be refreshed, got %q", path, string(b))
+		}
+	}
+	if info, err := os.Lstat(copyPath); err != nil || info.Mode()&os.ModeSymlink != 0 {
+		t.Fatalf("expected %s to stay a copy, err=%v", copyPath, err)
+	}
+	if info, err := os.Lstat(linkPath); err != nil || info.Mode()&os.ModeSymlink == 0 {
+		t.Fatalf("expected %s to stay a symlink, err=%v", linkPath, err)
+	}
+
+	if err := runSkill([]string{"update", "--check", "--repo", repo}, IO{In: strings.NewReader(""), Out: &bytes.Buffer{}, ErrOut: &bytes.Buffer{}}); err != nil {
+		t.Fatalf("expected check to pass after update, got: %v", err)
+	}
+}
+
+func TestRunSkillInstallDryRunWritesNothing(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	cfgDir := t.TempDir()
+	t.Setenv(config.EnvConfigPath, filepath.Join(cfgDir, "config.yaml"))
+
+	claudeMD := filepath.Join(home, ".claude", "CLAUDE.md")
+	if err := os.MkdirAll(filepath.Dir(claudeMD), 0o755); err != nil {
+		t.Fatalf("mkdir .claude: %v", err)
+	}
+	if err := os.WriteFile(claudeMD, []byte("# my notes\n"), 0o644); err != nil {
+		t.Fatalf("write CLAUDE.md: %v", err)
+	}
+
+	snapshot := func() map[string]string {
+		files := map[string]string{}
+		for _, root := range []string{home, cfgDir} {
+			err := filepath.WalkDir(root, func(path string, d os.DirEntry, err error) error {
+				if err != nil {
+					return err
+				}
+				if d.IsDir() {
+					files[path] = "dir"
+					return nil
+				}
+				b, err := os.ReadFile(path)
+				if err != nil {
+					return err
+				}
+				files[path] = string(b)
+				return nil
+			})
+			if err != nil {
+				t.Fatalf("walk %s: %v", root, err)
+			}
+		}
+		return files
+	}
+	before := snapshot()
+
+	var errOut bytes.Buffer
+	err := runSkill([]string{"install", "--dry-run"}, IO{In: strings.NewReader(""), Out: &bytes.Buffer{}, ErrOut: &errOut})
+	if err != nil {
+		t.Fatalf("runSkill install --dry-run returned error: %v", err)
+	}
+
+	if after := snapshot(); !reflect.DeepEqual(before, after) {
+		t.Fatalf("dry run changed files:\nbefore: %#v\nafter:  %#v", before, after)
+	}
+	got := errOut.String()
+	for _, want := range []string{
+		"would be seeded from embedded template",
+		"would install " + filepath.Join(home, ".claude", "skills", "consult-human", "SKILL.md") + " (symlink)",
+		"would install " + filepath.Join(home, ".codex", "skills", "consult-human", "SKILL.md") + " (symlink)",
+		"would update reminder in " + claudeMD + " (appends the block)",
+		"would update reminder in " + filepath.Join(home, ".codex", "AGENTS.md") + " (creates the file)",
+		"Dry run: nothing was written",
+	} {
+		if !strings.Contains(got, want) {
+			t.Fatalf("expected %q in dry-run output, got:\n%s", want, got)
+		}
+	}
+
+	if err := runSkill([]string{"install", "--target", "claude", "--copy"}, IO{In: strings.NewReader(""), Out: &bytes.Buffer{}, ErrOut: &bytes.Buffer{}}); err != nil {
+		t.Fatalf("runSkill install returned error: %v", err)
+	}
+	errOut.Reset()
+	if err := runSkill([]string{"install", "--target", "claude", "--copy", "--dry-run"}, IO{In: strings.NewReader(""), Out: &bytes.Buffer{}, ErrOut: &errOut}); err != nil {
+		t.Fatalf("runSkill install --dry-run returned error: %v", err)
+	}
+	if !strings.Contains(errOut.String(), "reminder already present in "+claudeMD) {
+		t.Fatalf("expected reminder to be reported as present, got:\n%s", errOut.String())
+	}
+}
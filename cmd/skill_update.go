@@ -0,0 +1,142 @@
+package cmd
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// runSkillUpdate refreshes the managed skill source from the embedded
+// template and then every skill already installed globally or under a --repo,
+// keeping each one a symlink or a copy as it was. With --check it writes
+// nothing and fails when anything is out of date.
+func runSkillUpdate(args []string, io IO) error {
+	fs := flag.NewFlagSet("skill update", flag.ContinueOnError)
+	fs.SetOutput(io.ErrOut)
+
+	var repoRaws []string
+	var check bool
+	fs.Func("repo", "Also update the install inside this repo path (repeatable)", func(v string) error {
+		repoRaws = append(repoRaws, v)
+		return nil
+	})
+	fs.BoolVar(&check, "check", false, "Only report out of date installs, and exit non-zero when there are any")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 0 {
+		return fmt.Errorf("usage: consult-human skill update [--repo <path>]... [--check]")
+	}
+
+	roots := []string{""}
+	for _, raw := range repoRaws {
+		root, err := resolveRepoRoot(raw)
+		if err != nil {
+			return err
+		}
+		roots = append(roots, root)
+	}
+
+	managedPath, err := defaultManagedSkillSourcePath()
+	if err != nil {
+		return err
+	}
+
+	var entries []skillStatusEntry
+	for _, root := range roots {
+		rootEntries, err := collectSkillStatus(root)
+		if err != nil {
+			return err
+		}
+		entries = append(entries, rootEntries...)
+	}
+
+	if check {
+		return checkSkillUpdate(entries, managedPath, io)
+	}
+
+	sourceBytes, sourcePath, sourceLabel, err := loadSkillSource("")
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(io.ErrOut, "Managed skill source: %s\n", sourceLabel)
+
+	found := false
+	for _, e := range entries {
+		if !e.Exists || (e.Kind == "reminder" && e.Status == skillStatusMissing) {
+			continue
+		}
+		found = true
+		switch e.Kind {
+		case "reminder":
+			// Only files that already carry the reminder block are refreshed;
+			// adding one is up to `skill install`.
+			changed, err := ensureConsultHumanReminder(e.Path)
+			if err != nil {
+				return fmt.Errorf("update reminder in %s: %w", e.Path, err)
+			}
+			printSkillUpdateResult(io, e.Path, changed)
+		case "skill":
+			if e.Mode == "symlink" && e.Status != skillStatusBroken && !linksToManagedSkill(e, managedPath) {
+				fmt.Fprintf(io.ErrOut, "skipped %s (links to %s, not the managed source)\n", e.Path, e.LinkTarget)
+				continue
+			}
+			// A symlink to the managed source is current again now that the
+			// source is refreshed; the others are rewritten in their own mode.
+			changed := e.Status == skillStatusBroken || (e.Mode == "copy" && e.Status != skillStatusCurrent)
+			if changed {
+				if err := installSkillFile(e.Path, sourceBytes, sourcePath, e.Mode == "symlink"); err != nil {
+					return fmt.Errorf("update %s: %w", e.Path, err)
+				}
+			}
+			printSkillUpdateResult(io, e.Path, changed || e.Status == skillStatusOutdated)
+		}
+	}
+	if !found {
+		fmt.Fprintln(io.ErrOut, "No installed skill found; run `consult-human skill install` first")
+	}
+	return nil
+}
+
+// checkSkillUpdate reports what `skill update` would change, without
+// changing it.
+func checkSkillUpdate(entries []skillStatusEntry, managedPath string, io IO) error {
+	outdated := 0
+	for _, e := range entries {
+		if !e.Exists || e.Status == skillStatusCurrent || (e.Kind == "reminder" && e.Status == skillStatusMissing) {
+			continue
+		}
+		if e.Kind == "skill" && e.Mode == "symlink" && e.Status != skillStatusBroken && !linksToManagedSkill(e, managedPath) {
+			continue
+		}
+		outdated++
+		fmt.Fprintf(io.ErrOut, "outdated %s (%s)\n", e.Path, e.Status)
+	}
+	if outdated > 0 {
+		return fmt.Errorf("%d installed file(s) are out of date; run `consult-human skill update`", outdated)
+	}
+	fmt.Fprintln(io.ErrOut, "All installed skill files are current")
+	return nil
+}
+
+func linksToManagedSkill(e skillStatusEntry, managedPath string) bool {
+	target := e.LinkTarget
+	if !filepath.IsAbs(target) {
+		target = filepath.Join(filepath.Dir(e.Path), target)
+	}
+	if filepath.Clean(target) == filepath.Clean(managedPath) {
+		return true
+	}
+	a, errA := os.Stat(target)
+	b, errB := os.Stat(managedPath)
+	return errA == nil && errB == nil && os.SameFile(a, b)
+}
+
+func printSkillUpdateResult(io IO, path string, changed bool) {
+	if changed {
+		fmt.Fprintf(io.ErrOut, "updated %s\n", path)
+	} else {
+		fmt.Fprintf(io.ErrOut, "already current %s\n", path)
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"io"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 
 	"github.com/AlhasanIQ/consult-human/config"
@@ -21,7 +22,11 @@ type storageClearReport struct {
 type telegramStoragePaths struct {
 	Pending    string
 	Inbox      string
+	Answered   string
+	Recent     string
+	RateLimit  string
 	PollerLock string
+	Media      string
 }
 
 func runStorage(args []string, io IO) error {
@@ -49,8 +54,8 @@ func runStorage(args []string, io IO) error {
 
 func printStorageUsage(w io.Writer) {
 	fmt.Fprintln(w, "Usage:")
-	fmt.Fprintln(w, "  consult-human storage path [--provider all|telegram|whatsapp]")
-	fmt.Fprintln(w, "  consult-human storage clear [--provider all|telegram|whatsapp]")
+	fmt.Fprintln(w, "  consult-human storage path [--provider all|telegram|signal|ntfy|whatsapp]")
+	fmt.Fprintln(w, "  consult-human storage clear [--provider all|telegram|signal|ntfy|whatsapp]")
 	fmt.Fprintln(w, "")
 	fmt.Fprintln(w, "Shows or clears local runtime storage/cache files.")
 }
@@ -60,12 +65,12 @@ func runStorageClear(args []string, io IO) error {
 	fs.SetOutput(io.ErrOut)
 
 	var providerName string
-	fs.StringVar(&providerName, "provider", storageProviderAll, "Provider scope to clear (all|telegram|whatsapp)")
+	fs.StringVar(&providerName, "provider", storageProviderAll, "Provider scope to clear (all|telegram|signal|ntfy|whatsapp)")
 	if err := fs.Parse(args); err != nil {
 		return err
 	}
 	if fs.NArg() != 0 {
-		return fmt.Errorf("usage: consult-human storage clear [--provider all|telegram|whatsapp]")
+		return fmt.Errorf("usage: consult-human storage clear [--provider all|telegram|signal|ntfy|whatsapp]")
 	}
 
 	providerName, err := normalizeStorageProvider(providerName)
@@ -91,12 +96,12 @@ func runStoragePath(args []string, io IO) error {
 	fs.SetOutput(io.ErrOut)
 
 	var providerName string
-	fs.StringVar(&providerName, "provider", storageProviderAll, "Provider scope (all|telegram|whatsapp)")
+	fs.StringVar(&providerName, "provider", storageProviderAll, "Provider scope (all|telegram|signal|ntfy|whatsapp)")
 	if err := fs.Parse(args); err != nil {
 		return err
 	}
 	if fs.NArg() != 0 {
-		return fmt.Errorf("usage: consult-human storage path [--provider all|telegram|whatsapp]")
+		return fmt.Errorf("usage: consult-human storage path [--provider all|telegram|signal|ntfy|whatsapp]")
 	}
 
 	providerName, err := normalizeStorageProvider(providerName)
@@ -117,14 +122,33 @@ func runStoragePath(args []string, io IO) error {
 	if err != nil {
 		return err
 	}
+	signalPath, err := config.DefaultSignalStorePath()
+	if err != nil {
+		return err
+	}
+	ntfyPath, err := config.DefaultNtfyStorePath()
+	if err != nil {
+		return err
+	}
 	skillManagedPath, err := defaultManagedSkillSourcePath()
 	if err != nil {
 		return err
 	}
+	if providerName == setupProviderSignal {
+		fmt.Fprintln(io.Out, signalPath)
+		return nil
+	}
+	if providerName == setupProviderNtfy {
+		fmt.Fprintln(io.Out, ntfyPath)
+		return nil
+	}
 	if providerName == setupProviderTelegram || providerName == setupProviderWhatsApp {
 		if providerName == setupProviderTelegram {
 			fmt.Fprintf(io.Out, "pending: %s\n", tgPaths.Pending)
 			fmt.Fprintf(io.Out, "inbox: %s\n", tgPaths.Inbox)
+			fmt.Fprintf(io.Out, "answered: %s\n", tgPaths.Answered)
+			fmt.Fprintf(io.Out, "recent: %s\n", tgPaths.Recent)
+			fmt.Fprintf(io.Out, "media: %s\n", tgPaths.Media)
 		} else {
 			fmt.Fprintln(io.Out, waPath)
 		}
@@ -133,6 +157,11 @@ func runStoragePath(args []string, io IO) error {
 
 	fmt.Fprintf(io.Out, "telegram.pending: %s\n", tgPaths.Pending)
 	fmt.Fprintf(io.Out, "telegram.inbox: %s\n", tgPaths.Inbox)
+	fmt.Fprintf(io.Out, "telegram.answered: %s\n", tgPaths.Answered)
+	fmt.Fprintf(io.Out, "telegram.recent: %s\n", tgPaths.Recent)
+	fmt.Fprintf(io.Out, "telegram.media: %s\n", tgPaths.Media)
+	fmt.Fprintf(io.Out, "signal: %s\n", signalPath)
+	fmt.Fprintf(io.Out, "ntfy: %s\n", ntfyPath)
 	fmt.Fprintf(io.Out, "whatsapp: %s\n", waPath)
 	fmt.Fprintf(io.Out, "skill.managed: %s\n", skillManagedPath)
 	return nil
@@ -144,10 +173,10 @@ func normalizeStorageProvider(raw string) (string, error) {
 		name = storageProviderAll
 	}
 	switch name {
-	case storageProviderAll, setupProviderTelegram, setupProviderWhatsApp:
+	case storageProviderAll, setupProviderTelegram, setupProviderSignal, setupProviderNtfy, setupProviderWhatsApp:
 		return name, nil
 	default:
-		return "", fmt.Errorf("provider must be all, telegram, or whatsapp")
+		return "", fmt.Errorf("provider must be all, telegram, signal, ntfy, or whatsapp")
 	}
 }
 
@@ -156,6 +185,15 @@ func clearStorageWithConfig(cfg config.Config, providerName string) (storageClea
 	if err != nil {
 		return storageClearReport{}, err
 	}
+	// Only these targets are directories; the rest are removed as files.
+	var dirs []string
+	if providerName == setupProviderTelegram || providerName == storageProviderAll {
+		tgPaths, err := effectiveTelegramStoragePaths(cfg)
+		if err != nil {
+			return storageClearReport{}, err
+		}
+		dirs = append(dirs, tgPaths.Media)
+	}
 
 	report := storageClearReport{
 		Removed: make([]string, 0, len(targets)),
@@ -165,11 +203,15 @@ func clearStorageWithConfig(cfg config.Config, providerName string) (storageClea
 		if strings.TrimSpace(path) == "" {
 			continue
 		}
-		if err := os.Remove(path); err != nil {
-			if os.IsNotExist(err) {
-				report.Missing = append(report.Missing, path)
-				continue
-			}
+		remove := os.Remove
+		if slices.Contains(dirs, path) {
+			remove = os.RemoveAll
+		}
+		if _, err := os.Lstat(path); os.IsNotExist(err) {
+			report.Missing = append(report.Missing, path)
+			continue
+		}
+		if err := remove(path); err != nil {
 			return report, fmt.Errorf("delete %s: %w", path, err)
 		}
 		report.Removed = append(report.Removed, path)
@@ -186,10 +228,22 @@ func storageTargetsForProvider(cfg config.Config, providerName string) ([]string
 	if err != nil {
 		return nil, err
 	}
+	signalPath, err := config.DefaultSignalStorePath()
+	if err != nil {
+		return nil, err
+	}
+	ntfyPath, err := config.DefaultNtfyStorePath()
+	if err != nil {
+		return nil, err
+	}
 
 	switch providerName {
 	case setupProviderTelegram:
 		return telegramStorageTargets(tgPaths), nil
+	case setupProviderSignal:
+		return lockedStoreTargets(signalPath), nil
+	case setupProviderNtfy:
+		return lockedStoreTargets(ntfyPath), nil
 	case setupProviderWhatsApp:
 		return whatsAppStorageTargets(waPath), nil
 	case storageProviderAll:
@@ -200,10 +254,12 @@ func storageTargetsForProvider(cfg config.Config, providerName string) ([]string
 			return nil, err
 		}
 		all := append(tg, wa...)
+		all = append(all, lockedStoreTargets(signalPath)...)
+		all = append(all, lockedStoreTargets(ntfyPath)...)
 		all = append(all, skillManagedPath)
 		return dedupeNonEmpty(all), nil
 	default:
-		return nil, fmt.Errorf("provider must be all, telegram, or whatsapp")
+		return nil, fmt.Errorf("provider must be all, telegram, signal, ntfy, or whatsapp")
 	}
 }
 
@@ -216,10 +272,30 @@ func effectiveTelegramStoragePaths(cfg config.Config) (telegramStoragePaths, err
 	if err != nil {
 		return telegramStoragePaths{}, err
 	}
+	answeredPath, err := config.EffectiveTelegramAnsweredStorePath(cfg)
+	if err != nil {
+		return telegramStoragePaths{}, err
+	}
+	recentPath, err := config.EffectiveTelegramRecentStorePath(cfg)
+	if err != nil {
+		return telegramStoragePaths{}, err
+	}
+	rateLimitPath, err := config.EffectiveTelegramRateLimitPath(cfg)
+	if err != nil {
+		return telegramStoragePaths{}, err
+	}
+	mediaDir, err := config.EffectiveTelegramMediaDir(cfg)
+	if err != nil {
+		return telegramStoragePaths{}, err
+	}
 	return telegramStoragePaths{
 		Pending:    pendingPath,
 		Inbox:      inboxPath,
+		Answered:   answeredPath,
+		Recent:     recentPath,
+		RateLimit:  rateLimitPath,
 		PollerLock: filepath.Join(filepath.Dir(inboxPath), "telegram-poller.lock"),
+		Media:      mediaDir,
 	}, nil
 }
 
@@ -231,7 +307,17 @@ func telegramStorageTargets(paths telegramStoragePaths) []string {
 		paths.Inbox,
 		paths.Inbox + ".lock",
 		paths.Inbox + ".tmp",
+		paths.Answered,
+		paths.Answered + ".lock",
+		paths.Answered + ".tmp",
+		paths.Recent,
+		paths.Recent + ".lock",
+		paths.Recent + ".tmp",
+		paths.RateLimit,
+		paths.RateLimit + ".lock",
+		paths.RateLimit + ".tmp",
 		paths.PollerLock,
+		paths.Media,
 	})
 }
 
@@ -260,6 +346,16 @@ func whatsAppStorageTargets(storePath string) []string {
 	})
 }
 
+// lockedStoreTargets is a JSON store shared by ask processes, such as the
+// Signal and ntfy pending stores, with its lock and temp files.
+func lockedStoreTargets(storePath string) []string {
+	return dedupeNonEmpty([]string{
+		storePath,
+		storePath + ".lock",
+		storePath + ".tmp",
+	})
+}
+
 func dedupeNonEmpty(paths []string) []string {
 	seen := map[string]struct{}{}
 	out := make([]string, 0, len(paths))
@@ -32,6 +32,13 @@ func TestRunStorageClearTelegram(t *testing.T) {
 	if err := os.WriteFile(pollerLock, []byte("1\n"), 0o600); err != nil {
 		t.Fatalf("write telegram poller lock: %v", err)
 	}
+	mediaDir := filepath.Join(filepath.Dir(tgPath), "media")
+	if err := os.MkdirAll(filepath.Join(mediaDir, "req-1"), 0o700); err != nil {
+		t.Fatalf("create media dir: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(mediaDir, "req-1", "photo-1.jpg"), []byte("jpg"), 0o600); err != nil {
+		t.Fatalf("write media file: %v", err)
+	}
 
 	var out bytes.Buffer
 	var errOut bytes.Buffer
@@ -55,6 +62,9 @@ func TestRunStorageClearTelegram(t *testing.T) {
 	if _, statErr := os.Stat(pollerLock); !os.IsNotExist(statErr) {
 		t.Fatalf("expected telegram poller lock removed, stat err: %v", statErr)
 	}
+	if _, statErr := os.Stat(mediaDir); !os.IsNotExist(statErr) {
+		t.Fatalf("expected telegram media dir removed, stat err: %v", statErr)
+	}
 	if !strings.Contains(errOut.String(), "Cleared storage for telegram") {
 		t.Fatalf("expected clear summary, got: %q", errOut.String())
 	}
@@ -98,6 +108,40 @@ func TestRunStorageClearWhatsApp(t *testing.T) {
 	}
 }
 
+func TestRunStorageSignal(t *testing.T) {
+	setTestStateHome(t)
+	t.Setenv(config.EnvConfigPath, filepath.Join(t.TempDir(), "config.yaml"))
+
+	signalPath, err := config.DefaultSignalStorePath()
+	if err != nil {
+		t.Fatalf("DefaultSignalStorePath: %v", err)
+	}
+	var out bytes.Buffer
+	if err := runStorage([]string{"path", "--provider", "signal"}, IO{In: strings.NewReader(""), Out: &out, ErrOut: &bytes.Buffer{}}); err != nil {
+		t.Fatalf("runStorage path signal: %v", err)
+	}
+	if got := strings.TrimSpace(out.String()); got != signalPath {
+		t.Fatalf("expected %q, got %q", signalPath, got)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(signalPath), 0o700); err != nil {
+		t.Fatalf("create state dir: %v", err)
+	}
+	if err := os.WriteFile(signalPath, []byte(`{}`), 0o600); err != nil {
+		t.Fatalf("write signal store: %v", err)
+	}
+	var errOut bytes.Buffer
+	if err := runStorage([]string{"clear", "--provider", "signal"}, IO{In: strings.NewReader(""), Out: &bytes.Buffer{}, ErrOut: &errOut}); err != nil {
+		t.Fatalf("runStorage clear signal: %v", err)
+	}
+	if _, statErr := os.Stat(signalPath); !os.IsNotExist(statErr) {
+		t.Fatalf("expected signal store removed, stat err: %v", statErr)
+	}
+	if !strings.Contains(errOut.String(), "Cleared storage for signal") {
+		t.Fatalf("unexpected clear report: %q", errOut.String())
+	}
+}
+
 func TestRunStorageClearInvalidProvider(t *testing.T) {
 	setTestStateHome(t)
 
@@ -114,7 +158,7 @@ func TestRunStorageClearInvalidProvider(t *testing.T) {
 	if err == nil {
 		t.Fatalf("expected invalid provider error")
 	}
-	if !strings.Contains(err.Error(), "provider must be all, telegram, or whatsapp") {
+	if !strings.Contains(err.Error(), "provider must be all, telegram, signal, ntfy, or whatsapp") {
 		t.Fatalf("unexpected error: %v", err)
 	}
 }
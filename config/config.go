@@ -1,12 +1,26 @@
 package config
 
 import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
 	"fmt"
+	"io"
+	"math"
+	"net"
+	"net/mail"
+	"net/url"
 	"os"
+	"os/user"
 	"path/filepath"
+	"reflect"
+	"regexp"
+	"runtime"
+	"sort"
 	"strconv"
 	"strings"
+	"text/template"
 	"time"
 
 	"gopkg.in/yaml.v3"
@@ -15,25 +29,239 @@ import (
 const (
 	EnvConfigPath               = "CONSULT_HUMAN_CONFIG"
 	EnvTelegramPendingStorePath = "CONSULT_HUMAN_TELEGRAM_PENDING_STORE"
+	EnvProfile                  = "CONSULT_HUMAN_PROFILE"
 )
 
 type Config struct {
-	ActiveProvider string         `yaml:"active_provider"`
-	RequestTimeout string         `yaml:"request_timeout"`
-	Telegram       TelegramConfig `yaml:"telegram"`
-	WhatsApp       WhatsAppConfig `yaml:"whatsapp"`
+	ActiveProvider     string         `yaml:"active_provider" json:"active_provider" toml:"active_provider"`
+	FallbackProviders  []string       `yaml:"fallback_providers,omitempty" json:"fallback_providers,omitempty" toml:"fallback_providers,omitempty"`
+	BroadcastProviders []string       `yaml:"broadcast_providers,omitempty" json:"broadcast_providers,omitempty" toml:"broadcast_providers,omitempty"`
+	RequestTimeout     string         `yaml:"request_timeout" json:"request_timeout" toml:"request_timeout"`
+	Telegram           TelegramConfig `yaml:"telegram" json:"telegram" toml:"telegram"`
+	WhatsApp           WhatsAppConfig `yaml:"whatsapp" json:"whatsapp" toml:"whatsapp"`
+	Slack              SlackConfig    `yaml:"slack,omitempty" json:"slack,omitzero" toml:"slack,omitempty"`
+	Signal             SignalConfig   `yaml:"signal,omitempty" json:"signal,omitzero" toml:"signal,omitempty"`
+	Email              EmailConfig    `yaml:"email,omitempty" json:"email,omitzero" toml:"email,omitempty"`
+	Ntfy               NtfyConfig     `yaml:"ntfy,omitempty" json:"ntfy,omitzero" toml:"ntfy,omitempty"`
+	Webhook            WebhookConfig  `yaml:"webhook,omitempty" json:"webhook,omitzero" toml:"webhook,omitempty"`
+	Desktop            DesktopConfig  `yaml:"desktop,omitempty" json:"desktop,omitzero" toml:"desktop,omitempty"`
+	Zulip              ZulipConfig    `yaml:"zulip,omitempty" json:"zulip,omitzero" toml:"zulip,omitempty"`
+	Pushover           PushoverConfig `yaml:"pushover,omitempty" json:"pushover,omitzero" toml:"pushover,omitempty"`
+	IMessage           IMessageConfig `yaml:"imessage,omitempty" json:"imessage,omitzero" toml:"imessage,omitempty"`
+	QuietHours         QuietHours     `yaml:"quiet_hours,omitempty" json:"quiet_hours,omitzero" toml:"quiet_hours,omitempty"`
+	Escalation         Escalation     `yaml:"escalation,omitempty" json:"escalation,omitzero" toml:"escalation,omitempty"`
 }
 
+// QuietHours is a daily window of "15:04" times in Timezone; it may cross midnight.
+type QuietHours struct {
+	Start    string `yaml:"start,omitempty" json:"start,omitempty" toml:"start,omitempty"`
+	End      string `yaml:"end,omitempty" json:"end,omitempty" toml:"end,omitempty"`
+	Timezone string `yaml:"timezone,omitempty" json:"timezone,omitempty" toml:"timezone,omitempty"`
+	Behavior string `yaml:"behavior,omitempty" json:"behavior,omitempty" toml:"behavior,omitempty"`
+}
+
+const (
+	QuietHoursSilent = "silent"
+	QuietHoursDefer  = "defer"
+)
+
 type TelegramConfig struct {
-	BotToken            string `yaml:"bot_token"`
-	ChatID              int64  `yaml:"chat_id"`
-	PollIntervalSeconds int    `yaml:"poll_interval_seconds"`
-	PendingStorePath    string `yaml:"pending_store_path"`
+	BotToken            string           `yaml:"bot_token" json:"bot_token" toml:"bot_token"`
+	ChatID              int64            `yaml:"chat_id" json:"chat_id" toml:"chat_id"`
+	PollIntervalSeconds int              `yaml:"poll_interval_seconds" json:"poll_interval_seconds" toml:"poll_interval_seconds"`
+	PendingStorePath    string           `yaml:"pending_store_path" json:"pending_store_path" toml:"pending_store_path"`
+	SendRetries         int              `yaml:"send_retries" json:"send_retries" toml:"send_retries"`
+	MaxRequestsPerSec   float64          `yaml:"max_requests_per_second" json:"max_requests_per_second" toml:"max_requests_per_second"`
+	PriorityPingAfter   string           `yaml:"priority_ping_after" json:"priority_ping_after" toml:"priority_ping_after"`
+	RemindAfter         string           `yaml:"remind_after,omitempty" json:"remind_after,omitempty" toml:"remind_after,omitempty"`
+	StrictReply         bool             `yaml:"strict_reply,omitempty" json:"strict_reply,omitempty" toml:"strict_reply,omitempty"`
+	LongMessageMode     string           `yaml:"long_message_mode,omitempty" json:"long_message_mode,omitempty" toml:"long_message_mode,omitempty"`
+	DedupeWindow        string           `yaml:"dedupe_window,omitempty" json:"dedupe_window,omitempty" toml:"dedupe_window,omitempty"`
+	ConfirmReplies      string           `yaml:"confirm_replies,omitempty" json:"confirm_replies,omitempty" toml:"confirm_replies,omitempty"`
+	ParseMode           string           `yaml:"parse_mode,omitempty" json:"parse_mode,omitempty" toml:"parse_mode,omitempty"`
+	MarkAnswered        *bool            `yaml:"mark_answered,omitempty" json:"mark_answered,omitempty" toml:"mark_answered,omitempty"`
+	AutoPersistChatID   *bool            `yaml:"auto_persist_chat_id,omitempty" json:"auto_persist_chat_id,omitempty" toml:"auto_persist_chat_id,omitempty"`
+	ReceiveMode         string           `yaml:"receive_mode,omitempty" json:"receive_mode,omitempty" toml:"receive_mode,omitempty"`
+	WebhookSecret       string           `yaml:"webhook_secret,omitempty" json:"webhook_secret,omitempty" toml:"webhook_secret,omitempty"`
+	AllowedUserIDs      []int64          `yaml:"allowed_user_ids,omitempty" json:"allowed_user_ids,omitempty" toml:"allowed_user_ids,omitempty"`
+	AllowedUsernames    []string         `yaml:"allowed_usernames,omitempty" json:"allowed_usernames,omitempty" toml:"allowed_usernames,omitempty"`
+	Chats               map[string]int64 `yaml:"chats,omitempty" json:"chats,omitempty" toml:"chats,omitempty"`
+	TranscribeCommand   string           `yaml:"transcribe_command,omitempty" json:"transcribe_command,omitempty" toml:"transcribe_command,omitempty"`
+	TypingIndicator     *bool            `yaml:"typing_indicator,omitempty" json:"typing_indicator,omitempty" toml:"typing_indicator,omitempty"`
+	AutoDeleteWebhook   bool             `yaml:"auto_delete_webhook,omitempty" json:"auto_delete_webhook,omitempty" toml:"auto_delete_webhook,omitempty"`
+	APIBaseURL          string           `yaml:"api_base_url,omitempty" json:"api_base_url,omitempty" toml:"api_base_url,omitempty"`
+	UnreachableAfter    string           `yaml:"unreachable_after,omitempty" json:"unreachable_after,omitempty" toml:"unreachable_after,omitempty"`
+	NotifyTimeout       *bool            `yaml:"notify_timeout,omitempty" json:"notify_timeout,omitempty" toml:"notify_timeout,omitempty"`
+	GroupMode           bool             `yaml:"group_mode,omitempty" json:"group_mode,omitempty" toml:"group_mode,omitempty"`
+	Reactions           string           `yaml:"reactions,omitempty" json:"reactions,omitempty" toml:"reactions,omitempty"`
+	Reminder            TelegramReminder `yaml:"reminder,omitempty" json:"reminder,omitzero" toml:"reminder,omitempty"`
+	SenderLabel         string           `yaml:"sender_label,omitempty" json:"sender_label,omitempty" toml:"sender_label,omitempty"`
+	Silent              bool             `yaml:"silent,omitempty" json:"silent,omitempty" toml:"silent,omitempty"`
+	InlineCodeBlocks    bool             `yaml:"inline_code_blocks,omitempty" json:"inline_code_blocks,omitempty" toml:"inline_code_blocks,omitempty"`
+	CodeBlockMaxLines   int              `yaml:"code_block_max_lines,omitempty" json:"code_block_max_lines,omitempty" toml:"code_block_max_lines,omitempty"`
+}
+
+// TelegramReminder turns off with a Cooldown of "0"; MaxPerRequest 0 means no limit.
+type TelegramReminder struct {
+	TextTemplate  string `yaml:"text_template,omitempty" json:"text_template,omitempty" toml:"text_template,omitempty"`
+	Cooldown      string `yaml:"cooldown,omitempty" json:"cooldown,omitempty" toml:"cooldown,omitempty"`
+	MaxPerRequest int    `yaml:"max_per_request,omitempty" json:"max_per_request,omitempty" toml:"max_per_request,omitempty"`
+}
+
+// TelegramReminderData is what telegram.reminder.text_template is executed with.
+type TelegramReminderData struct {
+	PendingCount int
+}
+
+// DefaultTelegramMaxRequestsPerSec is shared by every process using the bot token.
+const DefaultTelegramMaxRequestsPerSec = 5
+
+const DefaultTelegramAPIBaseURL = "https://api.telegram.org"
+
+const (
+	TelegramLongMessageSplit    = "split"
+	TelegramLongMessageDocument = "document"
+)
+
+const (
+	TelegramReceiveModePolling = "polling"
+	TelegramReceiveModeWebhook = "webhook"
+)
+
+const (
+	TelegramParseModePlain    = "plain"
+	TelegramParseModeMarkdown = "markdown"
+	TelegramParseModeHTML     = "html"
+)
+
+const (
+	TelegramConfirmRepliesChoice = "choice"
+	TelegramConfirmRepliesAll    = "all"
+	TelegramConfirmRepliesOff    = "off"
+)
+
+type SlackConfig struct {
+	BotToken            string `yaml:"bot_token,omitempty" json:"bot_token,omitempty" toml:"bot_token,omitempty"`
+	Channel             string `yaml:"channel,omitempty" json:"channel,omitempty" toml:"channel,omitempty"`
+	PollIntervalSeconds int    `yaml:"poll_interval_seconds,omitempty" json:"poll_interval_seconds,omitempty" toml:"poll_interval_seconds,omitempty"`
+}
+
+const DefaultSlackAPIBaseURL = "https://slack.com/api"
+
+type SignalConfig struct {
+	APIURL              string `yaml:"api_url,omitempty" json:"api_url,omitempty" toml:"api_url,omitempty"`
+	Number              string `yaml:"number,omitempty" json:"number,omitempty" toml:"number,omitempty"`
+	Recipient           string `yaml:"recipient,omitempty" json:"recipient,omitempty" toml:"recipient,omitempty"`
+	PollIntervalSeconds int    `yaml:"poll_interval_seconds,omitempty" json:"poll_interval_seconds,omitempty" toml:"poll_interval_seconds,omitempty"`
+}
+
+const DefaultSignalAPIURL = "http://127.0.0.1:8080"
+
+// EmailConfig falls back to the SMTP login for IMAP.
+type EmailConfig struct {
+	SMTPHost            string `yaml:"smtp_host,omitempty" json:"smtp_host,omitempty" toml:"smtp_host,omitempty"`
+	SMTPPort            int    `yaml:"smtp_port,omitempty" json:"smtp_port,omitempty" toml:"smtp_port,omitempty"`
+	SMTPUsername        string `yaml:"smtp_username,omitempty" json:"smtp_username,omitempty" toml:"smtp_username,omitempty"`
+	SMTPPassword        string `yaml:"smtp_password,omitempty" json:"smtp_password,omitempty" toml:"smtp_password,omitempty"`
+	From                string `yaml:"from,omitempty" json:"from,omitempty" toml:"from,omitempty"`
+	To                  string `yaml:"to,omitempty" json:"to,omitempty" toml:"to,omitempty"`
+	IMAPHost            string `yaml:"imap_host,omitempty" json:"imap_host,omitempty" toml:"imap_host,omitempty"`
+	IMAPPort            int    `yaml:"imap_port,omitempty" json:"imap_port,omitempty" toml:"imap_port,omitempty"`
+	IMAPUsername        string `yaml:"imap_username,omitempty" json:"imap_username,omitempty" toml:"imap_username,omitempty"`
+	IMAPPassword        string `yaml:"imap_password,omitempty" json:"imap_password,omitempty" toml:"imap_password,omitempty"`
+	Mailbox             string `yaml:"mailbox,omitempty" json:"mailbox,omitempty" toml:"mailbox,omitempty"`
+	PollIntervalSeconds int    `yaml:"poll_interval_seconds,omitempty" json:"poll_interval_seconds,omitempty" toml:"poll_interval_seconds,omitempty"`
+}
+
+const (
+	DefaultEmailSMTPPort = 587
+	DefaultEmailIMAPPort = 993
+	DefaultEmailMailbox  = "INBOX"
+)
+
+// NtfyConfig reads answers from the companion "<topic>-replies" topic.
+type NtfyConfig struct {
+	Server string `yaml:"server,omitempty" json:"server,omitempty" toml:"server,omitempty"`
+	Topic  string `yaml:"topic,omitempty" json:"topic,omitempty" toml:"topic,omitempty"`
+	Token  string `yaml:"token,omitempty" json:"token,omitempty" toml:"token,omitempty"`
+}
+
+const DefaultNtfyServer = "https://ntfy.sh"
+
+const NtfyReplyTopicSuffix = "-replies"
+
+// WebhookConfig signs both directions with Secret, using HMAC-SHA256.
+type WebhookConfig struct {
+	URL            string `yaml:"url,omitempty" json:"url,omitempty" toml:"url,omitempty"`
+	Secret         string `yaml:"secret,omitempty" json:"secret,omitempty" toml:"secret,omitempty"`
+	CallbackListen string `yaml:"callback_listen,omitempty" json:"callback_listen,omitempty" toml:"callback_listen,omitempty"`
+	PollURL        string `yaml:"poll_url,omitempty" json:"poll_url,omitempty" toml:"poll_url,omitempty"`
+	TLSCert        string `yaml:"tls_cert,omitempty" json:"tls_cert,omitempty" toml:"tls_cert,omitempty"`
+	TLSKey         string `yaml:"tls_key,omitempty" json:"tls_key,omitempty" toml:"tls_key,omitempty"`
+}
+
+// DesktopConfig picks a free port for each ask when Port is 0.
+type DesktopConfig struct {
+	Port int `yaml:"port,omitempty" json:"port,omitempty" toml:"port,omitempty"`
+}
+
+type ZulipConfig struct {
+	Site                string `yaml:"site,omitempty" json:"site,omitempty" toml:"site,omitempty"`
+	Email               string `yaml:"email,omitempty" json:"email,omitempty" toml:"email,omitempty"`
+	APIKey              string `yaml:"api_key,omitempty" json:"api_key,omitempty" toml:"api_key,omitempty"`
+	Stream              string `yaml:"stream,omitempty" json:"stream,omitempty" toml:"stream,omitempty"`
+	Recipient           string `yaml:"recipient,omitempty" json:"recipient,omitempty" toml:"recipient,omitempty"`
+	PollIntervalSeconds int    `yaml:"poll_interval_seconds,omitempty" json:"poll_interval_seconds,omitempty" toml:"poll_interval_seconds,omitempty"`
+}
+
+// PushoverConfig without a ReplyURL sends emergency notifications whose acknowledgment is the answer.
+type PushoverConfig struct {
+	AppToken string `yaml:"app_token,omitempty" json:"app_token,omitempty" toml:"app_token,omitempty"`
+	UserKey  string `yaml:"user_key,omitempty" json:"user_key,omitempty" toml:"user_key,omitempty"`
+	ReplyURL string `yaml:"reply_url,omitempty" json:"reply_url,omitempty" toml:"reply_url,omitempty"`
+	Listen   string `yaml:"listen,omitempty" json:"listen,omitempty" toml:"listen,omitempty"`
+}
+
+const DefaultPushoverListen = "127.0.0.1:8788"
+
+func IsValidPushoverKey(v string) bool {
+	if len(v) != 30 {
+		return false
+	}
+	for _, r := range v {
+		if (r < 'a' || r > 'z') && (r < 'A' || r > 'Z') && (r < '0' || r > '9') {
+			return false
+		}
+	}
+	return true
+}
+
+type IMessageConfig struct {
+	Handle              string `yaml:"handle,omitempty" json:"handle,omitempty" toml:"handle,omitempty"`
+	DBPath              string `yaml:"db_path,omitempty" json:"db_path,omitempty" toml:"db_path,omitempty"`
+	PollIntervalSeconds int    `yaml:"poll_interval_seconds,omitempty" json:"poll_interval_seconds,omitempty" toml:"poll_interval_seconds,omitempty"`
+}
+
+const DefaultIMessageDBPath = "~/Library/Messages/chat.db"
+
+func EffectiveIMessageDBPath(cfg Config) (string, error) {
+	if raw := strings.TrimSpace(cfg.IMessage.DBPath); raw != "" {
+		return ExpandPath(raw)
+	}
+	return ExpandPath(DefaultIMessageDBPath)
+}
+
+func IsValidIMessageHandle(v string) bool {
+	if strings.HasPrefix(v, "+") {
+		return IsValidSignalNumber(v)
+	}
+	addr, err := mail.ParseAddress(v)
+	return err == nil && addr.Address == v
 }
 
 type WhatsAppConfig struct {
-	Recipient string `yaml:"recipient"`
-	StorePath string `yaml:"store_path"`
+	Recipient string `yaml:"recipient" json:"recipient" toml:"recipient"`
+	StorePath string `yaml:"store_path" json:"store_path" toml:"store_path"`
 }
 
 func Default() Config {
@@ -42,25 +270,62 @@ func Default() Config {
 		RequestTimeout: "15m",
 		Telegram: TelegramConfig{
 			PollIntervalSeconds: 2,
+			SendRetries:         3,
+			MaxRequestsPerSec:   DefaultTelegramMaxRequestsPerSec,
+			PriorityPingAfter:   "5m",
 		},
 		WhatsApp: WhatsAppConfig{},
 	}
 }
 
+// ConfigPath turns "config.yaml" into "config.<profile>.yaml" while a profile is active.
 func ConfigPath() (string, error) {
+	profile, err := ActiveProfile()
+	if err != nil {
+		return "", err
+	}
+	path, err := baseConfigPath()
+	if err != nil || profile == "" {
+		return path, err
+	}
+	ext := filepath.Ext(path)
+	return strings.TrimSuffix(path, ext) + "." + profile + ext, nil
+}
+
+func baseConfigPath() (string, error) {
 	if p := strings.TrimSpace(os.Getenv(EnvConfigPath)); p != "" {
 		return ExpandPath(p)
 	}
 
 	if xdg := strings.TrimSpace(os.Getenv("XDG_CONFIG_HOME")); xdg != "" {
-		return filepath.Join(xdg, "consult-human", "config.yaml"), nil
+		return defaultConfigFile(filepath.Join(xdg, "consult-human")), nil
 	}
 
 	cfgDir, err := os.UserConfigDir()
 	if err != nil {
 		return "", err
 	}
-	return filepath.Join(cfgDir, "consult-human", "config.yaml"), nil
+	return defaultConfigFile(filepath.Join(cfgDir, "consult-human")), nil
+}
+
+func ActiveProfile() (string, error) {
+	profile := strings.TrimSpace(os.Getenv(EnvProfile))
+	if profile != "" && !IsValidProfileName(profile) {
+		return "", fmt.Errorf("profile name must be letters, digits, '-' or '_', got %q", profile)
+	}
+	return profile, nil
+}
+
+func IsValidProfileName(v string) bool {
+	if v == "" || len(v) > 64 {
+		return false
+	}
+	for _, r := range v {
+		if (r < 'a' || r > 'z') && (r < 'A' || r > 'Z') && (r < '0' || r > '9') && r != '-' && r != '_' {
+			return false
+		}
+	}
+	return true
 }
 
 func DefaultWhatsAppStorePath() (string, error) {
@@ -87,6 +352,70 @@ func DefaultTelegramInboxStorePath() (string, error) {
 	return filepath.Join(stateDir, "telegram-inbox.json"), nil
 }
 
+func DefaultSignalStorePath() (string, error) {
+	stateDir, err := DefaultStateDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(stateDir, "signal-pending.json"), nil
+}
+
+func DefaultNtfyStorePath() (string, error) {
+	stateDir, err := DefaultStateDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(stateDir, "ntfy-pending.json"), nil
+}
+
+func EffectiveNtfyServer(cfg Config) string {
+	if raw := strings.TrimRight(strings.TrimSpace(cfg.Ntfy.Server), "/"); raw != "" {
+		return raw
+	}
+	return DefaultNtfyServer
+}
+
+// IsValidNtfyTopic also requires room for NtfyReplyTopicSuffix.
+func IsValidNtfyTopic(v string) bool {
+	if v == "" || len(v)+len(NtfyReplyTopicSuffix) > 64 {
+		return false
+	}
+	for _, r := range v {
+		if (r < 'a' || r > 'z') && (r < 'A' || r > 'Z') && (r < '0' || r > '9') && r != '-' && r != '_' {
+			return false
+		}
+	}
+	return true
+}
+
+func EffectiveSignalAPIURL(cfg Config) string {
+	if raw := strings.TrimRight(strings.TrimSpace(cfg.Signal.APIURL), "/"); raw != "" {
+		return raw
+	}
+	return DefaultSignalAPIURL
+}
+
+func IsValidSignalNumber(v string) bool {
+	digits, ok := strings.CutPrefix(v, "+")
+	if !ok || len(digits) < 6 || len(digits) > 15 {
+		return false
+	}
+	for _, r := range digits {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+func DefaultHistoryPath() (string, error) {
+	stateDir, err := DefaultStateDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(stateDir, "history.jsonl"), nil
+}
+
 func TelegramPendingStorePath() (string, error) {
 	raw := strings.TrimSpace(os.Getenv(EnvTelegramPendingStorePath))
 	if raw == "" {
@@ -113,24 +442,144 @@ func EffectiveTelegramInboxStorePath(cfg Config) (string, error) {
 	return filepath.Join(filepath.Dir(pendingPath), "telegram-inbox.json"), nil
 }
 
-func DefaultStateDir() (string, error) {
-	if xdg := strings.TrimSpace(os.Getenv("XDG_STATE_HOME")); xdg != "" {
-		return filepath.Join(xdg, "consult-human"), nil
+func EffectiveTelegramAnsweredStorePath(cfg Config) (string, error) {
+	pendingPath, err := EffectiveTelegramPendingStorePath(cfg)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(filepath.Dir(pendingPath), "telegram-answered.json"), nil
+}
+
+func EffectiveTelegramRecentStorePath(cfg Config) (string, error) {
+	pendingPath, err := EffectiveTelegramPendingStorePath(cfg)
+	if err != nil {
+		return "", err
 	}
+	return filepath.Join(filepath.Dir(pendingPath), "telegram-recent.json"), nil
+}
 
-	home, err := os.UserHomeDir()
+func EffectiveTelegramRateLimitPath(cfg Config) (string, error) {
+	pendingPath, err := EffectiveTelegramPendingStorePath(cfg)
 	if err != nil {
 		return "", err
 	}
-	return filepath.Join(home, ".local", "state", "consult-human"), nil
+	sum := sha256.Sum256([]byte(strings.TrimSpace(cfg.Telegram.BotToken)))
+	return filepath.Join(filepath.Dir(pendingPath), "telegram-ratelimit-"+hex.EncodeToString(sum[:6])+".json"), nil
+}
+
+func EffectiveTelegramAPIBaseURL(cfg Config) (string, error) {
+	raw := strings.TrimSpace(cfg.Telegram.APIBaseURL)
+	if raw == "" {
+		return DefaultTelegramAPIBaseURL, nil
+	}
+	return NormalizeTelegramAPIBaseURL(raw)
+}
+
+func NormalizeTelegramAPIBaseURL(raw string) (string, error) {
+	u, err := url.Parse(strings.TrimSpace(raw))
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return "", fmt.Errorf("telegram.api_base_url must be an http or https URL, got %q", raw)
+	}
+	return strings.TrimRight(u.String(), "/"), nil
+}
+
+const DefaultTelegramReactions = "👍=yes,✅=yes,👎=no,❌=no"
+
+func EffectiveTelegramReactions(cfg Config) (map[string]string, error) {
+	raw := strings.TrimSpace(cfg.Telegram.Reactions)
+	if raw == "" {
+		raw = DefaultTelegramReactions
+	}
+	return ParseTelegramReactions(raw)
 }
 
+// ParseTelegramReactions returns an empty map for "none", turning reactions off.
+func ParseTelegramReactions(raw string) (map[string]string, error) {
+	out := map[string]string{}
+	if strings.EqualFold(strings.TrimSpace(raw), "none") {
+		return out, nil
+	}
+	for _, pair := range strings.Split(raw, ",") {
+		emoji, answer, ok := strings.Cut(pair, "=")
+		emoji, answer = strings.TrimSpace(emoji), strings.TrimSpace(answer)
+		if !ok || emoji == "" || answer == "" {
+			return nil, fmt.Errorf("telegram.reactions must be emoji=answer pairs separated by commas (or none), got %q", pair)
+		}
+		out[emoji] = answer
+	}
+	return out, nil
+}
+
+// ParseTelegramReminderTemplate also executes the template once with sample data.
+func ParseTelegramReminderTemplate(raw string) (*template.Template, error) {
+	tmpl, err := template.New("reminder").Option("missingkey=error").Parse(raw)
+	if err != nil {
+		return nil, fmt.Errorf("invalid telegram.reminder.text_template: %w", err)
+	}
+	if err := tmpl.Execute(io.Discard, TelegramReminderData{PendingCount: 2}); err != nil {
+		return nil, fmt.Errorf("invalid telegram.reminder.text_template: %w", err)
+	}
+	return tmpl, nil
+}
+
+func EffectiveTelegramMediaDir(cfg Config) (string, error) {
+	pendingPath, err := EffectiveTelegramPendingStorePath(cfg)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(filepath.Dir(pendingPath), "media"), nil
+}
+
+// DefaultStateDir gives each profile its own directory under "profiles".
+func DefaultStateDir() (string, error) {
+	profile, err := ActiveProfile()
+	if err != nil {
+		return "", err
+	}
+	dir := ""
+	if xdg := strings.TrimSpace(os.Getenv("XDG_STATE_HOME")); xdg != "" {
+		dir = filepath.Join(xdg, "consult-human")
+	} else {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", err
+		}
+		dir = filepath.Join(home, ".local", "state", "consult-human")
+	}
+	if profile != "" {
+		dir = filepath.Join(dir, "profiles", profile)
+	}
+	return dir, nil
+}
+
+// Load merges the repository config over the global file; commands that save use LoadGlobal.
 func Load() (Config, error) {
+	cfg, err := LoadGlobal()
+	if err != nil {
+		return Config{}, err
+	}
+	repo, _, found, err := loadRepoLayer()
+	if err != nil {
+		return Config{}, err
+	}
+	if found {
+		mergeNonZero(reflect.ValueOf(&cfg).Elem(), reflect.ValueOf(repo))
+		ApplyDefaults(&cfg)
+	}
+	return cfg, nil
+}
+
+func LoadGlobal() (Config, error) {
 	path, err := ConfigPath()
 	if err != nil {
 		return Config{}, err
 	}
 
+	format, err := FormatOf(path)
+	if err != nil {
+		return Config{}, err
+	}
+
 	b, err := os.ReadFile(path)
 	if err != nil {
 		if errors.Is(err, os.ErrNotExist) {
@@ -141,31 +590,162 @@ func Load() (Config, error) {
 		return Config{}, err
 	}
 
-	cfg := Default()
-	if err := yaml.Unmarshal(b, &cfg); err != nil {
-		return Config{}, fmt.Errorf("parse config: %w", err)
+	cfg, err := ParseAs(format, b)
+	if err != nil {
+		return Config{}, err
+	}
+	if err := checkUnknownKeys(path, format, b, StrictConfig()); err != nil {
+		return Config{}, err
 	}
-	ApplyDefaults(&cfg)
 	return cfg, nil
 }
 
-func Save(cfg Config) error {
+func Parse(b []byte) (Config, error) {
+	return ParseAs(FormatYAML, b)
+}
+
+func Validate(cfg Config) error {
 	ApplyDefaults(&cfg)
+	if err := Set(&Config{}, "active_provider", cfg.ActiveProvider); err != nil {
+		return fmt.Errorf("active_provider: %w", err)
+	}
+	if _, err := parseProviderList("fallback_providers", strings.Join(cfg.FallbackProviders, ",")); err != nil {
+		return err
+	}
+	if _, err := parseProviderList("broadcast_providers", strings.Join(cfg.BroadcastProviders, ",")); err != nil {
+		return err
+	}
+	if _, err := EffectiveTimeout(cfg); err != nil {
+		return err
+	}
+	if _, _, err := cfg.QuietHours.Active(time.Now()); err != nil {
+		return err
+	}
+	if b := cfg.QuietHours.Behavior; b != "" && b != QuietHoursSilent && b != QuietHoursDefer {
+		return fmt.Errorf("quiet_hours.behavior must be %s or %s", QuietHoursSilent, QuietHoursDefer)
+	}
+	if _, err := EffectiveEscalationAfter(cfg); err != nil {
+		return err
+	}
+	if v := cfg.Escalation.Provider; v != "" {
+		if _, err := parseProviderList("escalation.provider", v); err != nil {
+			return err
+		}
+		// The escalated question would share its request ID, and so its
+		// pending records, with the one it escalates.
+		if cfg.Escalation.Recipient != "" && strings.EqualFold(strings.TrimSpace(v), cfg.ActiveProvider) {
+			return fmt.Errorf("escalation.recipient needs escalation.provider to differ from active_provider (%s)", cfg.ActiveProvider)
+		}
+	}
+	if _, err := EffectiveTelegramAPIBaseURL(cfg); err != nil {
+		return err
+	}
+	if _, err := EffectiveTelegramReactions(cfg); err != nil {
+		return err
+	}
+	if raw := strings.TrimSpace(cfg.Telegram.Reminder.TextTemplate); raw != "" {
+		if _, err := ParseTelegramReminderTemplate(raw); err != nil {
+			return err
+		}
+	}
+	return nil
+}
 
+func Save(cfg Config) error {
 	path, err := ConfigPath()
 	if err != nil {
 		return err
 	}
+	return SaveAs(path, cfg)
+}
+
+func SaveAs(path string, cfg Config) error {
+	ApplyDefaults(&cfg)
+
+	format, err := FormatOf(path)
+	if err != nil {
+		return err
+	}
 	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
 		return err
 	}
-
-	b, err := yaml.Marshal(cfg)
+	b, err := marshalAs(format, cfg)
 	if err != nil {
 		return err
 	}
+	return writeConfigFile(path, b)
+}
+
+func writeConfigFile(path string, b []byte) error {
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, b, 0o600); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		_ = os.Remove(tmp)
+		return err
+	}
+	return nil
+}
+
+// SaveTelegramChatID re-reads the file and edits YAML in place; "" means it already held chatID.
+func SaveTelegramChatID(chatID int64) (string, error) {
+	path, err := ConfigPath()
+	if err != nil {
+		return "", err
+	}
+	format, err := FormatOf(path)
+	if err != nil {
+		return "", err
+	}
+	b, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return "", err
+	}
+	if format == FormatYAML && len(bytes.TrimSpace(b)) > 0 {
+		var doc yaml.Node
+		if err := yaml.Unmarshal(b, &doc); err != nil {
+			return "", fmt.Errorf("parse config: %w", err)
+		}
+		if len(doc.Content) == 1 && doc.Content[0].Kind == yaml.MappingNode {
+			value := yamlMappingValue(yamlMappingValue(doc.Content[0], "telegram", yaml.MappingNode), "chat_id", yaml.ScalarNode)
+			id := strconv.FormatInt(chatID, 10)
+			if value.Value == id {
+				return "", nil
+			}
+			value.Kind, value.Tag, value.Value, value.Style = yaml.ScalarNode, "!!int", id, 0
+			out, err := yaml.Marshal(&doc)
+			if err != nil {
+				return "", err
+			}
+			return path, writeConfigFile(path, out)
+		}
+	}
 
-	return os.WriteFile(path, b, 0o600)
+	cfg, err := LoadGlobal()
+	if err != nil {
+		return "", err
+	}
+	if cfg.Telegram.ChatID == chatID {
+		return "", nil
+	}
+	cfg.Telegram.ChatID = chatID
+	return path, SaveAs(path, cfg)
+}
+
+func yamlMappingValue(mapping *yaml.Node, key string, kind yaml.Kind) *yaml.Node {
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == key {
+			value := mapping.Content[i+1]
+			if value.Kind != kind {
+				*value = yaml.Node{Kind: kind}
+			}
+			return value
+		}
+	}
+	value := &yaml.Node{Kind: kind}
+	mapping.Content = append(mapping.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: key}, value)
+	return value
 }
 
 func ApplyDefaults(cfg *Config) {
@@ -186,6 +766,9 @@ func ApplyDefaults(cfg *Config) {
 	if cfg.Telegram.PollIntervalSeconds <= 0 {
 		cfg.Telegram.PollIntervalSeconds = 2
 	}
+	if cfg.Telegram.SendRetries < 0 {
+		cfg.Telegram.SendRetries = 3
+	}
 	telegramStorePath := strings.TrimSpace(cfg.Telegram.PendingStorePath)
 	if telegramStorePath == "" {
 		if p, err := DefaultTelegramPendingStorePath(); err == nil {
@@ -227,16 +810,78 @@ func Set(cfg *Config, key string, value string) error {
 	k := strings.ToLower(strings.TrimSpace(key))
 	v := strings.TrimSpace(value)
 
+	if alias, ok := strings.CutPrefix(k, TelegramChatKeyPrefix); ok {
+		return SetTelegramChat(cfg, alias, v)
+	}
+
 	switch k {
 	case "active_provider", "provider", "default-provider":
 		v = strings.ToLower(v)
 		if v == "whatsapp" {
 			return fmt.Errorf("whatsapp is temporarily disabled")
 		}
-		if v != "telegram" {
-			return fmt.Errorf("provider must be telegram")
+		if v != "telegram" && v != "slack" && v != "signal" && v != "email" && v != "ntfy" && v != "webhook" && v != "desktop" && v != "zulip" && v != "pushover" && v != "imessage" {
+			return fmt.Errorf("provider must be telegram, slack, signal, email, ntfy, webhook, desktop, zulip, pushover or imessage")
 		}
 		cfg.ActiveProvider = v
+	case "fallback_providers":
+		providers, err := parseProviderList(k, v)
+		if err != nil {
+			return err
+		}
+		cfg.FallbackProviders = providers
+	case "broadcast_providers":
+		providers, err := parseProviderList(k, v)
+		if err != nil {
+			return err
+		}
+		cfg.BroadcastProviders = providers
+	case "quiet_hours.start", "quiet_hours.end":
+		if v != "" {
+			if _, err := time.Parse(quietHoursClock, v); err != nil {
+				return fmt.Errorf("%s must be a time like 23:00", k)
+			}
+		}
+		if k == "quiet_hours.start" {
+			cfg.QuietHours.Start = v
+		} else {
+			cfg.QuietHours.End = v
+		}
+	case "quiet_hours.timezone":
+		if v != "" {
+			if _, err := time.LoadLocation(v); err != nil {
+				return fmt.Errorf("invalid quiet_hours.timezone: %w", err)
+			}
+		}
+		cfg.QuietHours.Timezone = v
+	case "quiet_hours.behavior":
+		v = strings.ToLower(v)
+		if v != "" && v != QuietHoursSilent && v != QuietHoursDefer {
+			return fmt.Errorf("quiet_hours.behavior must be silent or defer")
+		}
+		cfg.QuietHours.Behavior = v
+	case "escalation.after":
+		if v != "" {
+			d, err := time.ParseDuration(v)
+			if err != nil {
+				return fmt.Errorf("invalid escalation.after: %w", err)
+			}
+			if d <= 0 {
+				return fmt.Errorf("escalation.after must be > 0")
+			}
+		}
+		cfg.Escalation.After = v
+	case "escalation.provider":
+		providers, err := parseProviderList(k, v)
+		if err != nil {
+			return err
+		}
+		if len(providers) > 1 {
+			return fmt.Errorf("escalation.provider takes a single provider")
+		}
+		cfg.Escalation.Provider = strings.Join(providers, "")
+	case "escalation.recipient":
+		cfg.Escalation.Recipient = v
 	case "request_timeout":
 		if _, err := time.ParseDuration(v); err != nil {
 			return fmt.Errorf("invalid duration: %w", err)
@@ -256,6 +901,213 @@ func Set(cfg *Config, key string, value string) error {
 			return fmt.Errorf("telegram.poll_interval_seconds must be a positive integer")
 		}
 		cfg.Telegram.PollIntervalSeconds = n
+	case "telegram.send_retries":
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			return fmt.Errorf("telegram.send_retries must be a non-negative integer")
+		}
+		cfg.Telegram.SendRetries = n
+	case "telegram.max_requests_per_second":
+		n, err := strconv.ParseFloat(v, 64)
+		if err != nil || n < 0 || math.IsInf(n, 0) || math.IsNaN(n) {
+			return fmt.Errorf("telegram.max_requests_per_second must be a non-negative number (0 disables the limit)")
+		}
+		cfg.Telegram.MaxRequestsPerSec = n
+	case "telegram.priority_ping_after":
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			return fmt.Errorf("invalid duration: %w", err)
+		}
+		if d < 0 {
+			return fmt.Errorf("telegram.priority_ping_after must be >= 0")
+		}
+		cfg.Telegram.PriorityPingAfter = v
+	case "telegram.remind_after":
+		if v != "" {
+			d, err := time.ParseDuration(v)
+			if err != nil {
+				return fmt.Errorf("invalid duration: %w", err)
+			}
+			if d < 0 {
+				return fmt.Errorf("telegram.remind_after must be >= 0")
+			}
+		}
+		cfg.Telegram.RemindAfter = v
+	case "telegram.inline_code_blocks":
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			return fmt.Errorf("telegram.inline_code_blocks must be true or false")
+		}
+		cfg.Telegram.InlineCodeBlocks = b
+	case "telegram.code_block_max_lines":
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 {
+			return fmt.Errorf("telegram.code_block_max_lines must be a positive integer")
+		}
+		cfg.Telegram.CodeBlockMaxLines = n
+	case "telegram.silent":
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			return fmt.Errorf("telegram.silent must be true or false")
+		}
+		cfg.Telegram.Silent = b
+	case "telegram.strict_reply":
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			return fmt.Errorf("telegram.strict_reply must be true or false")
+		}
+		cfg.Telegram.StrictReply = b
+	case "telegram.dedupe_window":
+		if v != "" {
+			d, err := time.ParseDuration(v)
+			if err != nil {
+				return fmt.Errorf("invalid duration: %w", err)
+			}
+			if d < 0 {
+				return fmt.Errorf("telegram.dedupe_window must be >= 0")
+			}
+		}
+		cfg.Telegram.DedupeWindow = v
+	case "telegram.unreachable_after":
+		if v != "" {
+			d, err := time.ParseDuration(v)
+			if err != nil {
+				return fmt.Errorf("invalid duration: %w", err)
+			}
+			if d < 0 {
+				return fmt.Errorf("telegram.unreachable_after must be >= 0")
+			}
+		}
+		cfg.Telegram.UnreachableAfter = v
+	case "telegram.mark_answered":
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			return fmt.Errorf("telegram.mark_answered must be true or false")
+		}
+		cfg.Telegram.MarkAnswered = &b
+	case "telegram.auto_persist_chat_id":
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			return fmt.Errorf("telegram.auto_persist_chat_id must be true or false")
+		}
+		cfg.Telegram.AutoPersistChatID = &b
+	case "telegram.notify_timeout":
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			return fmt.Errorf("telegram.notify_timeout must be true or false")
+		}
+		cfg.Telegram.NotifyTimeout = &b
+	case "telegram.reactions":
+		if _, err := ParseTelegramReactions(v); err != nil {
+			return err
+		}
+		cfg.Telegram.Reactions = v
+	case "telegram.reminder.text_template":
+		if v != "" {
+			if _, err := ParseTelegramReminderTemplate(v); err != nil {
+				return err
+			}
+		}
+		cfg.Telegram.Reminder.TextTemplate = v
+	case "telegram.reminder.cooldown":
+		if v != "" {
+			d, err := time.ParseDuration(v)
+			if err != nil {
+				return fmt.Errorf("invalid duration: %w", err)
+			}
+			if d < 0 {
+				return fmt.Errorf("telegram.reminder.cooldown must be >= 0")
+			}
+		}
+		cfg.Telegram.Reminder.Cooldown = v
+	case "telegram.reminder.max_per_request":
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			return fmt.Errorf("telegram.reminder.max_per_request must be a non-negative integer")
+		}
+		cfg.Telegram.Reminder.MaxPerRequest = n
+	case "telegram.group_mode":
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			return fmt.Errorf("telegram.group_mode must be true or false")
+		}
+		cfg.Telegram.GroupMode = b
+	case "telegram.auto_delete_webhook":
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			return fmt.Errorf("telegram.auto_delete_webhook must be true or false")
+		}
+		cfg.Telegram.AutoDeleteWebhook = b
+	case "telegram.typing_indicator":
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			return fmt.Errorf("telegram.typing_indicator must be true or false")
+		}
+		cfg.Telegram.TypingIndicator = &b
+	case "telegram.confirm_replies":
+		v = strings.ToLower(v)
+		if v != "" && v != TelegramConfirmRepliesChoice && v != TelegramConfirmRepliesAll && v != TelegramConfirmRepliesOff {
+			return fmt.Errorf("telegram.confirm_replies must be choice, all, or off")
+		}
+		cfg.Telegram.ConfirmReplies = v
+	case "telegram.allowed_user_ids":
+		var ids []int64
+		for _, raw := range strings.Split(v, ",") {
+			raw = strings.TrimSpace(raw)
+			if raw == "" {
+				continue
+			}
+			id, err := strconv.ParseInt(raw, 10, 64)
+			if err != nil || id <= 0 {
+				return fmt.Errorf("telegram.allowed_user_ids must be a comma-separated list of numeric user IDs")
+			}
+			ids = append(ids, id)
+		}
+		cfg.Telegram.AllowedUserIDs = ids
+	case "telegram.allowed_usernames":
+		var names []string
+		for _, raw := range strings.Split(v, ",") {
+			if name := NormalizeTelegramUsername(raw); name != "" {
+				names = append(names, name)
+			}
+		}
+		cfg.Telegram.AllowedUsernames = names
+	case "telegram.receive_mode":
+		v = strings.ToLower(v)
+		if v != "" && v != TelegramReceiveModePolling && v != TelegramReceiveModeWebhook {
+			return fmt.Errorf("telegram.receive_mode must be polling or webhook")
+		}
+		cfg.Telegram.ReceiveMode = v
+	case "telegram.webhook_secret":
+		if v != "" && !IsValidTelegramWebhookSecret(v) {
+			return fmt.Errorf("telegram.webhook_secret must be 1-256 characters of A-Z, a-z, 0-9, _ and -")
+		}
+		cfg.Telegram.WebhookSecret = v
+	case "telegram.api_base_url":
+		if v != "" {
+			normalized, err := NormalizeTelegramAPIBaseURL(v)
+			if err != nil {
+				return err
+			}
+			v = normalized
+		}
+		cfg.Telegram.APIBaseURL = v
+	case "telegram.transcribe_command":
+		cfg.Telegram.TranscribeCommand = v
+	case "telegram.sender_label":
+		cfg.Telegram.SenderLabel = v
+	case "telegram.parse_mode":
+		v = strings.ToLower(v)
+		if v != "" && v != TelegramParseModePlain && v != TelegramParseModeMarkdown && v != TelegramParseModeHTML {
+			return fmt.Errorf("telegram.parse_mode must be plain, markdown, or html")
+		}
+		cfg.Telegram.ParseMode = v
+	case "telegram.long_message_mode":
+		v = strings.ToLower(v)
+		if v != "" && v != TelegramLongMessageSplit && v != TelegramLongMessageDocument {
+			return fmt.Errorf("telegram.long_message_mode must be split or document")
+		}
+		cfg.Telegram.LongMessageMode = v
 	case "telegram.pending_store_path", "telegram.store_path":
 		expanded, err := ExpandPath(v)
 		if err != nil {
@@ -264,6 +1116,216 @@ func Set(cfg *Config, key string, value string) error {
 		cfg.Telegram.PendingStorePath = expanded
 	case "whatsapp.recipient":
 		cfg.WhatsApp.Recipient = v
+	case "slack.bot_token":
+		if v != "" && !strings.HasPrefix(v, "xoxb-") {
+			return fmt.Errorf("slack.bot_token must be a bot token starting with xoxb-")
+		}
+		cfg.Slack.BotToken = v
+	case "slack.channel":
+		cfg.Slack.Channel = v
+	case "slack.poll_interval_seconds":
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 {
+			return fmt.Errorf("slack.poll_interval_seconds must be a positive integer")
+		}
+		cfg.Slack.PollIntervalSeconds = n
+	case "signal.api_url":
+		if v != "" {
+			u, err := url.Parse(v)
+			if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+				return fmt.Errorf("signal.api_url must be an http or https URL, got %q", v)
+			}
+			v = strings.TrimRight(v, "/")
+		}
+		cfg.Signal.APIURL = v
+	case "signal.number", "signal.recipient":
+		if v != "" && !IsValidSignalNumber(v) {
+			return fmt.Errorf("%s must be a phone number in international format, like +15551234567", k)
+		}
+		if k == "signal.number" {
+			cfg.Signal.Number = v
+		} else {
+			cfg.Signal.Recipient = v
+		}
+	case "signal.poll_interval_seconds":
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 {
+			return fmt.Errorf("signal.poll_interval_seconds must be a positive integer")
+		}
+		cfg.Signal.PollIntervalSeconds = n
+	case "email.smtp_host":
+		cfg.Email.SMTPHost = v
+	case "email.imap_host":
+		cfg.Email.IMAPHost = v
+	case "email.smtp_port", "email.imap_port":
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 || n > 65535 {
+			return fmt.Errorf("%s must be a port number", k)
+		}
+		if k == "email.smtp_port" {
+			cfg.Email.SMTPPort = n
+		} else {
+			cfg.Email.IMAPPort = n
+		}
+	case "email.smtp_username":
+		cfg.Email.SMTPUsername = v
+	case "email.smtp_password":
+		cfg.Email.SMTPPassword = v
+	case "email.imap_username":
+		cfg.Email.IMAPUsername = v
+	case "email.imap_password":
+		cfg.Email.IMAPPassword = v
+	case "email.from", "email.to":
+		if v != "" {
+			addr, err := mail.ParseAddress(v)
+			if err != nil {
+				return fmt.Errorf("%s must be an email address, got %q", k, v)
+			}
+			v = addr.Address
+		}
+		if k == "email.from" {
+			cfg.Email.From = v
+		} else {
+			cfg.Email.To = v
+		}
+	case "email.mailbox":
+		cfg.Email.Mailbox = v
+	case "email.poll_interval_seconds":
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 {
+			return fmt.Errorf("email.poll_interval_seconds must be a positive integer")
+		}
+		cfg.Email.PollIntervalSeconds = n
+	case "ntfy.server":
+		if v != "" {
+			u, err := url.Parse(v)
+			if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+				return fmt.Errorf("ntfy.server must be an http or https URL, got %q", v)
+			}
+			v = strings.TrimRight(v, "/")
+		}
+		cfg.Ntfy.Server = v
+	case "ntfy.topic":
+		if v != "" && !IsValidNtfyTopic(v) {
+			return fmt.Errorf("ntfy.topic must be 1-%d letters, digits, - or _", 64-len(NtfyReplyTopicSuffix))
+		}
+		cfg.Ntfy.Topic = v
+	case "ntfy.token":
+		if v != "" && !strings.HasPrefix(v, "tk_") {
+			return fmt.Errorf("ntfy.token must be an access token starting with tk_")
+		}
+		cfg.Ntfy.Token = v
+	case "webhook.url", "webhook.poll_url":
+		if v != "" {
+			u, err := url.Parse(v)
+			if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+				return fmt.Errorf("%s must be an http or https URL, got %q", k, v)
+			}
+		}
+		if k == "webhook.url" {
+			cfg.Webhook.URL = v
+		} else {
+			cfg.Webhook.PollURL = v
+		}
+	case "webhook.secret":
+		cfg.Webhook.Secret = v
+	case "webhook.callback_listen":
+		if v != "" {
+			if _, port, err := net.SplitHostPort(v); err != nil || port == "" {
+				return fmt.Errorf("webhook.callback_listen must be an address like 127.0.0.1:8787, got %q", v)
+			}
+		}
+		cfg.Webhook.CallbackListen = v
+	case "webhook.tls_cert", "webhook.tls_key":
+		expanded, err := ExpandPath(v)
+		if err != nil {
+			return err
+		}
+		if k == "webhook.tls_cert" {
+			cfg.Webhook.TLSCert = expanded
+		} else {
+			cfg.Webhook.TLSKey = expanded
+		}
+	case "desktop.port":
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 || n > 65535 {
+			return fmt.Errorf("desktop.port must be a port number, or 0 for any free port")
+		}
+		cfg.Desktop.Port = n
+	case "zulip.site":
+		if v != "" {
+			u, err := url.Parse(v)
+			if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+				return fmt.Errorf("zulip.site must be the organization URL, like https://example.zulipchat.com")
+			}
+			v = strings.TrimRight(v, "/")
+		}
+		cfg.Zulip.Site = v
+	case "zulip.email", "zulip.recipient":
+		if v != "" {
+			addr, err := mail.ParseAddress(v)
+			if err != nil {
+				return fmt.Errorf("%s must be an email address, got %q", k, v)
+			}
+			v = addr.Address
+		}
+		if k == "zulip.email" {
+			cfg.Zulip.Email = v
+		} else {
+			cfg.Zulip.Recipient = v
+		}
+	case "zulip.api_key":
+		cfg.Zulip.APIKey = v
+	case "zulip.stream":
+		cfg.Zulip.Stream = strings.TrimPrefix(v, "#")
+	case "zulip.poll_interval_seconds":
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 {
+			return fmt.Errorf("zulip.poll_interval_seconds must be a positive integer")
+		}
+		cfg.Zulip.PollIntervalSeconds = n
+	case "pushover.app_token", "pushover.user_key":
+		if v != "" && !IsValidPushoverKey(v) {
+			return fmt.Errorf("%s must be 30 letters and digits, as shown on pushover.net", k)
+		}
+		if k == "pushover.app_token" {
+			cfg.Pushover.AppToken = v
+		} else {
+			cfg.Pushover.UserKey = v
+		}
+	case "pushover.reply_url":
+		if v != "" {
+			u, err := url.Parse(v)
+			if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" || strings.Trim(u.Path, "/") != "" {
+				return fmt.Errorf("pushover.reply_url must be an http or https URL without a path, like https://reply.example.com")
+			}
+			v = strings.TrimRight(v, "/")
+		}
+		cfg.Pushover.ReplyURL = v
+	case "pushover.listen":
+		if v != "" {
+			if _, port, err := net.SplitHostPort(v); err != nil || port == "" {
+				return fmt.Errorf("pushover.listen must be an address like %s, got %q", DefaultPushoverListen, v)
+			}
+		}
+		cfg.Pushover.Listen = v
+	case "imessage.handle":
+		if v != "" && !IsValidIMessageHandle(v) {
+			return fmt.Errorf("imessage.handle must be a phone number in international format (+15551234567) or an Apple ID email, got %q", v)
+		}
+		cfg.IMessage.Handle = v
+	case "imessage.db_path":
+		expanded, err := ExpandPath(v)
+		if err != nil {
+			return err
+		}
+		cfg.IMessage.DBPath = expanded
+	case "imessage.poll_interval_seconds":
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 {
+			return fmt.Errorf("imessage.poll_interval_seconds must be a positive integer")
+		}
+		cfg.IMessage.PollIntervalSeconds = n
 	case "whatsapp.store_path":
 		expanded, err := ExpandPath(v)
 		if err != nil {
@@ -278,25 +1340,219 @@ func Set(cfg *Config, key string, value string) error {
 	return nil
 }
 
+func parseProviderList(key, v string) ([]string, error) {
+	providers := make([]string, 0)
+	for _, name := range strings.Split(v, ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+		if name != "telegram" && name != "slack" && name != "signal" && name != "email" && name != "ntfy" && name != "webhook" && name != "desktop" && name != "zulip" && name != "pushover" && name != "imessage" && name != "whatsapp" {
+			return nil, fmt.Errorf("%s entries must be telegram, slack, signal, email, ntfy, webhook, desktop, zulip, pushover, imessage or whatsapp", key)
+		}
+		providers = append(providers, name)
+	}
+	return providers, nil
+}
+
 func Marshal(cfg Config) ([]byte, error) {
 	ApplyDefaults(&cfg)
 	return yaml.Marshal(cfg)
 }
 
+// ExpandPath returns URLs and absolute paths unchanged.
 func ExpandPath(raw string) (string, error) {
 	raw = strings.TrimSpace(raw)
 	if raw == "" {
 		return "", nil
 	}
-	if raw == "~" || strings.HasPrefix(raw, "~/") {
-		home, err := os.UserHomeDir()
+	if strings.Contains(raw, "://") || filepath.IsAbs(raw) {
+		return raw, nil
+	}
+
+	var missing []string
+	lookup := func(name string) string {
+		v, ok := os.LookupEnv(name)
+		if !ok {
+			missing = append(missing, name)
+		}
+		return v
+	}
+	path := os.Expand(raw, lookup)
+	if expandPercentVars {
+		path = expandPercentEnv(path, lookup)
+	}
+	if len(missing) > 0 {
+		return "", fmt.Errorf("path %q uses unset environment variable %s", raw, strings.Join(missing, ", "))
+	}
+
+	if strings.HasPrefix(path, "~") {
+		name, rest := path[1:], ""
+		if i := strings.IndexFunc(name, func(r rune) bool { return r == '/' || r == filepath.Separator }); i >= 0 {
+			name, rest = name[:i], name[i+1:]
+		}
+		home, err := homeDir(name)
 		if err != nil {
 			return "", err
 		}
-		if raw == "~" {
-			return home, nil
+		if home != "" {
+			path = filepath.Join(home, rest)
+		}
+	}
+	return filepath.Clean(path), nil
+}
+
+var expandPercentVars = runtime.GOOS == "windows"
+
+var percentEnvPattern = regexp.MustCompile(`%([A-Za-z_][A-Za-z0-9_()]*)%`)
+
+func expandPercentEnv(s string, lookup func(string) string) string {
+	return percentEnvPattern.ReplaceAllStringFunc(s, func(m string) string {
+		return lookup(m[1 : len(m)-1])
+	})
+}
+
+// An unknown user gives "", leaving ~name as it is.
+func homeDir(name string) (string, error) {
+	if name == "" {
+		return os.UserHomeDir()
+	}
+	u, err := user.Lookup(name)
+	if err != nil {
+		return "", nil
+	}
+	return u.HomeDir, nil
+}
+
+func IsValidTelegramWebhookSecret(v string) bool {
+	if len(v) == 0 || len(v) > 256 {
+		return false
+	}
+	for _, r := range v {
+		if !(r >= 'A' && r <= 'Z' || r >= 'a' && r <= 'z' || r >= '0' && r <= '9' || r == '_' || r == '-') {
+			return false
+		}
+	}
+	return true
+}
+
+func NormalizeTelegramUsername(v string) string {
+	return strings.ToLower(strings.TrimPrefix(strings.TrimSpace(v), "@"))
+}
+
+const TelegramChatKeyPrefix = "telegram.chats."
+
+// SetTelegramChat removes the alias when value is empty.
+func SetTelegramChat(cfg *Config, alias, value string) error {
+	alias = strings.ToLower(strings.TrimSpace(alias))
+	if !IsValidTelegramChatAlias(alias) {
+		return fmt.Errorf("telegram chat alias %q must be letters, digits, _ or -, and not only digits", alias)
+	}
+	value = strings.TrimSpace(value)
+	if value == "" {
+		delete(cfg.Telegram.Chats, alias)
+		return nil
+	}
+	id, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || id == 0 {
+		return fmt.Errorf("%s%s must be a numeric chat ID", TelegramChatKeyPrefix, alias)
+	}
+	if cfg.Telegram.Chats == nil {
+		cfg.Telegram.Chats = make(map[string]int64)
+	}
+	cfg.Telegram.Chats[alias] = id
+	return nil
+}
+
+func ResolveTelegramChat(cfg Config, v string) (int64, error) {
+	v = strings.TrimSpace(v)
+	if id, ok := cfg.Telegram.Chats[strings.ToLower(v)]; ok {
+		return id, nil
+	}
+	if id, err := strconv.ParseInt(v, 10, 64); err == nil && id != 0 {
+		return id, nil
+	}
+	if len(cfg.Telegram.Chats) == 0 {
+		return 0, fmt.Errorf("unknown telegram chat %q; name one with `consult-human setup --provider telegram --link-chat --name <alias>`", v)
+	}
+	aliases := make([]string, 0, len(cfg.Telegram.Chats))
+	for alias := range cfg.Telegram.Chats {
+		aliases = append(aliases, alias)
+	}
+	sort.Strings(aliases)
+	return 0, fmt.Errorf("unknown telegram chat %q; known aliases: %s", v, strings.Join(aliases, ", "))
+}
+
+// IsValidTelegramChatAlias refuses digit-only aliases, which would read as chat IDs.
+func IsValidTelegramChatAlias(alias string) bool {
+	if alias == "" {
+		return false
+	}
+	digitsOnly := true
+	for _, r := range alias {
+		switch {
+		case r >= '0' && r <= '9':
+		case r >= 'a' && r <= 'z', r == '_', r == '-':
+			digitsOnly = false
+		default:
+			return false
 		}
-		return filepath.Join(home, strings.TrimPrefix(raw, "~/")), nil
 	}
-	return raw, nil
+	return !digitsOnly
+}
+
+const quietHoursClock = "15:04"
+
+func (q QuietHours) Enabled() bool {
+	return q.Start != "" && q.End != "" && q.Start != q.End
+}
+
+func (q QuietHours) EffectiveBehavior() string {
+	if q.Behavior == "" {
+		return QuietHoursSilent
+	}
+	return q.Behavior
+}
+
+// Active also returns when the window ends, in the configured time zone.
+func (q QuietHours) Active(now time.Time) (bool, time.Time, error) {
+	if !q.Enabled() {
+		return false, time.Time{}, nil
+	}
+	loc := time.UTC
+	if q.Timezone != "" {
+		l, err := time.LoadLocation(q.Timezone)
+		if err != nil {
+			return false, time.Time{}, fmt.Errorf("invalid quiet_hours.timezone: %w", err)
+		}
+		loc = l
+	}
+	start, err := time.Parse(quietHoursClock, q.Start)
+	if err != nil {
+		return false, time.Time{}, fmt.Errorf("invalid quiet_hours.start %q", q.Start)
+	}
+	end, err := time.Parse(quietHoursClock, q.End)
+	if err != nil {
+		return false, time.Time{}, fmt.Errorf("invalid quiet_hours.end %q", q.End)
+	}
+
+	local := now.In(loc)
+	minute := local.Hour()*60 + local.Minute()
+	startMinute := start.Hour()*60 + start.Minute()
+	endMinute := end.Hour()*60 + end.Minute()
+	var inside bool
+	if startMinute < endMinute {
+		inside = minute >= startMinute && minute < endMinute
+	} else {
+		inside = minute >= startMinute || minute < endMinute
+	}
+	if !inside {
+		return false, time.Time{}, nil
+	}
+
+	ends := time.Date(local.Year(), local.Month(), local.Day(), end.Hour(), end.Minute(), 0, 0, loc)
+	if !ends.After(local) {
+		ends = time.Date(local.Year(), local.Month(), local.Day()+1, end.Hour(), end.Minute(), 0, 0, loc)
+	}
+	return true, ends, nil
 }
@@ -0,0 +1,75 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Escalation re-sends a question left unanswered for After through Provider
+// and then takes the first answer from either. Recipient, when set, replaces
+// where Provider delivers: a Telegram chat name or ID, a Slack channel, a
+// phone number, an email address, an iMessage handle, an ntfy topic, or a
+// Pushover user key.
+type Escalation struct {
+	After     string `yaml:"after,omitempty" json:"after,omitempty" toml:"after,omitempty"`
+	Provider  string `yaml:"provider,omitempty" json:"provider,omitempty" toml:"provider,omitempty"`
+	Recipient string `yaml:"recipient,omitempty" json:"recipient,omitempty" toml:"recipient,omitempty"`
+}
+
+// EffectiveEscalationAfter returns how long to wait before escalating, or 0
+// when escalation is not configured.
+func EffectiveEscalationAfter(cfg Config) (time.Duration, error) {
+	raw := strings.TrimSpace(cfg.Escalation.After)
+	if raw == "" || strings.TrimSpace(cfg.Escalation.Provider) == "" {
+		return 0, nil
+	}
+	d, err := time.ParseDuration(raw)
+	if err != nil {
+		return 0, fmt.Errorf("invalid escalation.after %q: %w", raw, err)
+	}
+	if d <= 0 {
+		return 0, fmt.Errorf("escalation.after must be > 0")
+	}
+	return d, nil
+}
+
+// EscalationTarget returns cfg with escalation.recipient applied to the
+// escalation provider's settings, ready to build that provider from.
+func EscalationTarget(cfg Config) (Config, error) {
+	recipient := strings.TrimSpace(cfg.Escalation.Recipient)
+	if recipient == "" {
+		return cfg, nil
+	}
+	var key string
+	switch name := strings.ToLower(strings.TrimSpace(cfg.Escalation.Provider)); name {
+	case "telegram":
+		chatID, err := ResolveTelegramChat(cfg, recipient)
+		if err != nil {
+			return cfg, fmt.Errorf("invalid escalation.recipient: %w", err)
+		}
+		cfg.Telegram.ChatID = chatID
+		return cfg, nil
+	case "slack":
+		key = "slack.channel"
+	case "signal", "whatsapp", "zulip":
+		key = name + ".recipient"
+		if name == "zulip" {
+			cfg.Zulip.Stream = ""
+		}
+	case "email":
+		key = "email.to"
+	case "ntfy":
+		key = "ntfy.topic"
+	case "pushover":
+		key = "pushover.user_key"
+	case "imessage":
+		key = "imessage.handle"
+	default:
+		return cfg, fmt.Errorf("escalation.recipient is not supported for %s", name)
+	}
+	if err := Set(&cfg, key, recipient); err != nil {
+		return cfg, fmt.Errorf("invalid escalation.recipient: %w", err)
+	}
+	return cfg, nil
+}
@@ -0,0 +1,114 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// Config file formats. A config file's format follows its extension.
+const (
+	FormatYAML = "yaml"
+	FormatJSON = "json"
+	FormatTOML = "toml"
+)
+
+// configFileNames are the default config files looked for, in order, when
+// CONSULT_HUMAN_CONFIG is not set.
+var configFileNames = []string{"config.yaml", "config.yml", "config.json", "config.toml"}
+
+// FormatOf returns the format of the config file at path: .yaml and .yml
+// are YAML, .json JSON and .toml TOML.
+func FormatOf(path string) (string, error) {
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".yaml", ".yml":
+		return FormatYAML, nil
+	case ".json":
+		return FormatJSON, nil
+	case ".toml":
+		return FormatTOML, nil
+	default:
+		return "", fmt.Errorf("unsupported config file extension %q in %s; use .yaml, .yml, .json or .toml", ext, path)
+	}
+}
+
+// defaultConfigFile is the first of configFileNames that exists in dir, or
+// config.yaml when none does.
+func defaultConfigFile(dir string) string {
+	for _, name := range configFileNames {
+		path := filepath.Join(dir, name)
+		if _, err := os.Stat(path); err == nil {
+			return path
+		}
+	}
+	return filepath.Join(dir, configFileNames[0])
+}
+
+// ParseAs decodes config file content in format over Default.
+func ParseAs(format string, b []byte) (Config, error) {
+	cfg := Default()
+	if err := unmarshalAs(format, b, &cfg); err != nil {
+		return Config{}, fmt.Errorf("parse config: %w", err)
+	}
+	ApplyDefaults(&cfg)
+	return cfg, nil
+}
+
+func unmarshalAs(format string, b []byte, cfg *Config) error {
+	switch format {
+	case FormatJSON:
+		return json.Unmarshal(b, cfg)
+	case FormatTOML:
+		_, err := toml.Decode(string(b), cfg)
+		return err
+	default:
+		return yaml.Unmarshal(b, cfg)
+	}
+}
+
+func marshalAs(format string, cfg Config) ([]byte, error) {
+	switch format {
+	case FormatJSON:
+		b, err := json.MarshalIndent(cfg, "", "  ")
+		if err != nil {
+			return nil, err
+		}
+		return append(b, '\n'), nil
+	case FormatTOML:
+		var buf bytes.Buffer
+		if err := toml.NewEncoder(&buf).Encode(cfg); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	default:
+		return yaml.Marshal(cfg)
+	}
+}
+
+// decodeTree decodes config file content in format into nested maps.
+func decodeTree(format string, b []byte) (map[string]any, error) {
+	var tree map[string]any
+	switch format {
+	case FormatJSON:
+		dec := json.NewDecoder(bytes.NewReader(b))
+		dec.UseNumber()
+		if err := dec.Decode(&tree); err != nil {
+			return nil, err
+		}
+	case FormatTOML:
+		if _, err := toml.Decode(string(b), &tree); err != nil {
+			return nil, err
+		}
+	default:
+		if err := yaml.Unmarshal(b, &tree); err != nil {
+			return nil, err
+		}
+	}
+	return tree, nil
+}
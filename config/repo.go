@@ -0,0 +1,322 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/AlhasanIQ/consult-human/gitrepo"
+	"gopkg.in/yaml.v3"
+)
+
+// RepoConfigFileName is the per-repository config file, read from the root
+// of the git repository the command runs in and merged over the global one.
+const RepoConfigFileName = ".consult-human.yaml"
+
+// Layers an effective config value can come from, as shown by
+// `config show --origin`.
+const (
+	OriginDefault = "default"
+	OriginGlobal  = "global"
+	OriginRepo    = "repo"
+	OriginEnv     = "env"
+)
+
+// RepoConfigTemplate is what `config init --repo` writes.
+const RepoConfigTemplate = `# consult-human settings for this repository, merged over the global config
+# (see ` + "`consult-human config path`" + `). Only values set here override it.
+# Only settings for where questions go and how they read are accepted here;
+# secrets, server URLs, commands and paths stay in the global config.
+#
+# request_timeout: 30m
+# telegram:
+#   chat_id: -1001234567890
+#   sender_label: "{repo}"
+`
+
+// secretKeys are the credentials in a config. A repository config file must
+// not set them, since it is meant to be committed, and `config export`
+// redacts them.
+var secretKeys = []struct {
+	key   string
+	value func(Config) string
+}{
+	{"telegram.bot_token", func(c Config) string { return c.Telegram.BotToken }},
+	{"telegram.webhook_secret", func(c Config) string { return c.Telegram.WebhookSecret }},
+	{"slack.bot_token", func(c Config) string { return c.Slack.BotToken }},
+	{"email.smtp_password", func(c Config) string { return c.Email.SMTPPassword }},
+	{"email.imap_password", func(c Config) string { return c.Email.IMAPPassword }},
+	{"ntfy.token", func(c Config) string { return c.Ntfy.Token }},
+	{"webhook.secret", func(c Config) string { return c.Webhook.Secret }},
+	{"zulip.api_key", func(c Config) string { return c.Zulip.APIKey }},
+	{"pushover.app_token", func(c Config) string { return c.Pushover.AppToken }},
+	{"pushover.user_key", func(c Config) string { return c.Pushover.UserKey }},
+}
+
+// repoAllowedKeys are the settings a repository config file may set. The
+// file comes with whatever repository was cloned, so it only picks where
+// questions go and how they read: never a server, listener, command or path
+// that would receive the global credentials or run something. A key ending in
+// "." allows everything under it.
+var repoAllowedKeys = []string{
+	"request_timeout",
+	"active_provider",
+	"fallback_providers",
+	"broadcast_providers",
+	"quiet_hours.",
+	"escalation.after",
+	"telegram.chat_id",
+	"telegram.chats.",
+	"telegram.sender_label",
+	"telegram.parse_mode",
+	"telegram.long_message_mode",
+	"telegram.code_block_max_lines",
+	"telegram.inline_code_blocks",
+	"telegram.silent",
+	"telegram.reactions",
+	"telegram.typing_indicator",
+	"telegram.confirm_replies",
+	"telegram.mark_answered",
+	"telegram.strict_reply",
+	"telegram.group_mode",
+	"telegram.remind_after",
+	"telegram.reminder.",
+	"telegram.priority_ping_after",
+	"telegram.unreachable_after",
+	"telegram.notify_timeout",
+	"telegram.dedupe_window",
+	"telegram.send_retries",
+	"telegram.poll_interval_seconds",
+	"slack.channel",
+	"slack.poll_interval_seconds",
+	"signal.recipient",
+	"signal.poll_interval_seconds",
+	"zulip.stream",
+	"zulip.recipient",
+	"zulip.poll_interval_seconds",
+}
+
+func repoKeyAllowed(key string) bool {
+	for _, allowed := range repoAllowedKeys {
+		if key == allowed || (strings.HasSuffix(allowed, ".") && strings.HasPrefix(key, allowed)) {
+			return true
+		}
+	}
+	return false
+}
+
+// Setting is one effective config value and the layer it came from.
+type Setting struct {
+	Key    string
+	Value  string
+	Origin string
+}
+
+// RepoConfigPath returns where the repository config file of the working
+// directory's git repository is, whether or not it exists. found is false
+// outside a git repository.
+func RepoConfigPath() (path string, found bool, err error) {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return "", false, err
+	}
+	root, found, err := gitrepo.FindRoot(cwd)
+	if err != nil || !found {
+		return "", false, err
+	}
+	return filepath.Join(root, RepoConfigFileName), true, nil
+}
+
+// loadRepoLayer reads the repository config file, if there is one, without
+// defaults: only the values it sets are merged.
+func loadRepoLayer() (Config, []byte, bool, error) {
+	path, found, err := RepoConfigPath()
+	if err != nil || !found {
+		return Config{}, nil, false, err
+	}
+	b, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return Config{}, nil, false, nil
+		}
+		return Config{}, nil, false, err
+	}
+	var repo Config
+	if err := yaml.Unmarshal(b, &repo); err != nil {
+		return Config{}, nil, false, fmt.Errorf("parse %s: %w", path, err)
+	}
+	if err := checkUnknownKeys(path, FormatYAML, b, StrictConfig()); err != nil {
+		return Config{}, nil, false, err
+	}
+	for _, secret := range secretKeys {
+		if strings.TrimSpace(secret.value(repo)) != "" {
+			return Config{}, nil, false, fmt.Errorf(
+				"%s sets %s; secrets are not read from repository config files, which get committed. Remove it there and run `consult-human config set %s <value>` instead",
+				path, secret.key, secret.key,
+			)
+		}
+	}
+	keys, err := setKeys(FormatYAML, b)
+	if err != nil {
+		return Config{}, nil, false, fmt.Errorf("parse %s: %w", path, err)
+	}
+	var refused []string
+	for key := range keys {
+		if !repoKeyAllowed(key) {
+			refused = append(refused, key)
+		}
+	}
+	if len(refused) > 0 {
+		sort.Strings(refused)
+		return Config{}, nil, false, fmt.Errorf(
+			"%s sets %s; repository config files, which come with any repository you clone, may not set servers, listeners, commands or paths. Remove it there and use `consult-human config set` instead",
+			path, strings.Join(refused, ", "),
+		)
+	}
+	return repo, b, true, nil
+}
+
+// mergeNonZero copies every non-zero field of src over dst. Maps are merged
+// key by key; slices and pointers replace what dst had.
+func mergeNonZero(dst, src reflect.Value) {
+	switch src.Kind() {
+	case reflect.Struct:
+		for i := range src.NumField() {
+			mergeNonZero(dst.Field(i), src.Field(i))
+		}
+	case reflect.Map:
+		if src.Len() == 0 {
+			return
+		}
+		if dst.IsNil() {
+			dst.Set(reflect.MakeMap(src.Type()))
+		}
+		iter := src.MapRange()
+		for iter.Next() {
+			dst.SetMapIndex(iter.Key(), iter.Value())
+		}
+	default:
+		if !src.IsZero() {
+			dst.Set(src)
+		}
+	}
+}
+
+// Settings lists the effective config as dotted keys, each with the layer
+// that set it: the repository file, the global file, an environment
+// variable, or the built-in default.
+func Settings() ([]Setting, error) {
+	cfg, err := Load()
+	if err != nil {
+		return nil, err
+	}
+	effective, err := flattenYAML(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	globalKeys := map[string]bool{}
+	path, err := ConfigPath()
+	if err != nil {
+		return nil, err
+	}
+	format, err := FormatOf(path)
+	if err != nil {
+		return nil, err
+	}
+	if b, err := os.ReadFile(path); err == nil {
+		if globalKeys, err = setKeys(format, b); err != nil {
+			return nil, fmt.Errorf("parse config: %w", err)
+		}
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+	repoKeys := map[string]bool{}
+	if _, b, found, err := loadRepoLayer(); err != nil {
+		return nil, err
+	} else if found {
+		if repoKeys, err = setKeys(FormatYAML, b); err != nil {
+			return nil, err
+		}
+	}
+	if raw := strings.TrimSpace(os.Getenv(EnvTelegramPendingStorePath)); raw != "" {
+		if p, err := ExpandPath(raw); err == nil {
+			effective["telegram.pending_store_path"] = p
+		}
+	}
+
+	settings := make([]Setting, 0, len(effective))
+	for key, value := range effective {
+		origin := OriginDefault
+		switch {
+		case key == "telegram.pending_store_path" && os.Getenv(EnvTelegramPendingStorePath) != "":
+			origin = OriginEnv
+		case repoKeys[key]:
+			origin = OriginRepo
+		case globalKeys[key]:
+			origin = OriginGlobal
+		}
+		settings = append(settings, Setting{Key: key, Value: value, Origin: origin})
+	}
+	sort.Slice(settings, func(i, j int) bool { return settings[i].Key < settings[j].Key })
+	return settings, nil
+}
+
+// flattenYAML renders v as YAML and returns its scalar values by dotted key.
+func flattenYAML(v any) (map[string]string, error) {
+	b, err := yaml.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var tree map[string]any
+	if err := yaml.Unmarshal(b, &tree); err != nil {
+		return nil, err
+	}
+	out := map[string]string{}
+	flattenInto(out, "", tree)
+	return out, nil
+}
+
+// setKeys returns the dotted keys a config file in format gives a non-zero
+// value.
+func setKeys(format string, b []byte) (map[string]bool, error) {
+	tree, err := decodeTree(format, b)
+	if err != nil {
+		return nil, err
+	}
+	flat := map[string]string{}
+	flattenInto(flat, "", tree)
+	keys := make(map[string]bool, len(flat))
+	for key, value := range flat {
+		if value != "" && value != "0" && value != "false" {
+			keys[key] = true
+		}
+	}
+	return keys, nil
+}
+
+func flattenInto(out map[string]string, prefix string, node any) {
+	switch n := node.(type) {
+	case map[string]any:
+		for key, child := range n {
+			if prefix != "" {
+				key = prefix + "." + key
+			}
+			flattenInto(out, key, child)
+		}
+	case []any:
+		parts := make([]string, 0, len(n))
+		for _, item := range n {
+			parts = append(parts, fmt.Sprint(item))
+		}
+		out[prefix] = strings.Join(parts, ",")
+	case nil:
+		out[prefix] = ""
+	default:
+		out[prefix] = fmt.Sprint(n)
+	}
+}
@@ -0,0 +1,147 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// exportHeader starts every file `config export` writes.
+const exportHeader = `# consult-human config, exported with ` + "`consult-human config export`" + `.
+# Only values that differ from the defaults are listed. Load it with
+# ` + "`consult-human config import <file>`" + `, which asks for every <REDACTED:...> value.
+`
+
+const (
+	redactedPrefix = "<REDACTED:"
+	redactedSuffix = ">"
+)
+
+// RedactedPlaceholder is what Export writes in place of the secret at key.
+func RedactedPlaceholder(key string) string {
+	return redactedPrefix + key + redactedSuffix
+}
+
+// RedactedKey reports whether value is a placeholder Export wrote, and for
+// which key.
+func RedactedKey(value string) (string, bool) {
+	key, ok := strings.CutPrefix(strings.TrimSpace(value), redactedPrefix)
+	if !ok {
+		return "", false
+	}
+	return strings.CutSuffix(key, redactedSuffix)
+}
+
+// Export renders the values of cfg that differ from the defaults as YAML for
+// another machine. Unless includeSecrets is set, every secret is replaced
+// with its RedactedPlaceholder.
+func Export(cfg Config, includeSecrets bool) ([]byte, error) {
+	b, err := yaml.Marshal(cfg)
+	if err != nil {
+		return nil, err
+	}
+	tree, err := decodeTree(FormatYAML, b)
+	if err != nil {
+		return nil, err
+	}
+	defaults := Default()
+	ApplyDefaults(&defaults)
+	defaultValues, err := flattenYAML(defaults)
+	if err != nil {
+		return nil, err
+	}
+	pruneDefaults(tree, "", defaultValues)
+
+	if !includeSecrets {
+		for _, secret := range secretKeys {
+			if strings.TrimSpace(secret.value(cfg)) != "" {
+				replaceTreeValue(tree, secret.key, RedactedPlaceholder(secret.key))
+			}
+		}
+	}
+
+	out := []byte(exportHeader)
+	if len(tree) == 0 {
+		return out, nil
+	}
+	body, err := yaml.Marshal(tree)
+	if err != nil {
+		return nil, err
+	}
+	return append(out, body...), nil
+}
+
+// pruneDefaults drops every value in tree that is empty or equal to its
+// default, and every section left empty.
+func pruneDefaults(tree map[string]any, prefix string, defaults map[string]string) {
+	for name, child := range tree {
+		key := name
+		if prefix != "" {
+			key = prefix + "." + name
+		}
+		if section, ok := child.(map[string]any); ok {
+			pruneDefaults(section, key, defaults)
+			if len(section) == 0 {
+				delete(tree, name)
+			}
+			continue
+		}
+		flat := map[string]string{}
+		flattenInto(flat, key, child)
+		if flat[key] == "" || flat[key] == defaults[key] {
+			delete(tree, name)
+		}
+	}
+}
+
+// replaceTreeValue sets the value at a dotted key that tree already has.
+func replaceTreeValue(tree map[string]any, key string, value any) {
+	parent, name, found := strings.Cut(key, ".")
+	if !found {
+		if _, ok := tree[key]; ok {
+			tree[key] = value
+		}
+		return
+	}
+	if section, ok := tree[parent].(map[string]any); ok {
+		replaceTreeValue(section, name, value)
+	}
+}
+
+// ImportSetting is one value of an exported config, as the dotted key and
+// string value `config set` takes.
+type ImportSetting struct {
+	Key   string
+	Value string
+	// Redacted is set when Value is a RedactedPlaceholder still to be filled in.
+	Redacted bool
+}
+
+// ParseExport reads a file written by Export into the settings it holds,
+// sorted by key. The keys are not checked here; Set rejects the ones it does
+// not know.
+func ParseExport(b []byte) ([]ImportSetting, error) {
+	tree, err := decodeTree(FormatYAML, b)
+	if err != nil {
+		return nil, fmt.Errorf("parse exported config: %w", err)
+	}
+	flat := map[string]string{}
+	flattenInto(flat, "", tree)
+	delete(flat, "")
+
+	settings := make([]ImportSetting, 0, len(flat))
+	for key, value := range flat {
+		setting := ImportSetting{Key: key, Value: value}
+		if redacted, ok := RedactedKey(value); ok {
+			if redacted != key {
+				return nil, fmt.Errorf("%s holds the placeholder for %s", key, redacted)
+			}
+			setting.Redacted = true
+		}
+		settings = append(settings, setting)
+	}
+	sort.Slice(settings, func(i, j int) bool { return settings[i].Key < settings[j].Key })
+	return settings, nil
+}
@@ -0,0 +1,179 @@
+package config
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// EnvStrictConfig, when true, makes unknown keys in a config file an error
+// instead of a warning, like `config validate --strict`.
+const EnvStrictConfig = "CONSULT_HUMAN_STRICT_CONFIG"
+
+// unknownKeyOut is where unknown keys are reported outside strict mode.
+// Tests replace it.
+var unknownKeyOut io.Writer = os.Stderr
+
+// unknownKeyWarned holds the path and key of every warning already printed,
+// so a process that loads the config many times warns once.
+var unknownKeyWarned sync.Map
+
+// UnknownKey is a key in a config file that no setting reads, with the known
+// key it most likely meant.
+type UnknownKey struct {
+	Key        string
+	Suggestion string
+}
+
+func (u UnknownKey) String() string {
+	if u.Suggestion == "" {
+		return u.Key
+	}
+	return fmt.Sprintf("%s (did you mean %s?)", u.Key, u.Suggestion)
+}
+
+// UnknownKeysError is what loading a config file with unknown keys returns
+// in strict mode.
+type UnknownKeysError struct {
+	Path string
+	Keys []UnknownKey
+}
+
+func (e *UnknownKeysError) Error() string {
+	parts := make([]string, 0, len(e.Keys))
+	for _, k := range e.Keys {
+		parts = append(parts, k.String())
+	}
+	return fmt.Sprintf("%s has unknown keys: %s", e.Path, strings.Join(parts, ", "))
+}
+
+// StrictConfig reports whether CONSULT_HUMAN_STRICT_CONFIG asks for unknown
+// keys to be errors.
+func StrictConfig() bool {
+	strict, _ := strconv.ParseBool(strings.TrimSpace(os.Getenv(EnvStrictConfig)))
+	return strict
+}
+
+// UnknownKeys lists, sorted, the keys of config file content in format that
+// no setting reads. Keys under a map such as telegram.chats are all known.
+func UnknownKeys(format string, b []byte) ([]UnknownKey, error) {
+	tree, err := decodeTree(format, b)
+	if err != nil {
+		return nil, err
+	}
+	var unknown []string
+	collectUnknownKeys(tree, reflect.TypeOf(Config{}), "", &unknown)
+	sort.Strings(unknown)
+
+	known := knownKeys(reflect.TypeOf(Config{}), "")
+	keys := make([]UnknownKey, 0, len(unknown))
+	for _, key := range unknown {
+		keys = append(keys, UnknownKey{Key: key, Suggestion: closestKey(key, known)})
+	}
+	return keys, nil
+}
+
+// checkUnknownKeys reports the unknown keys of the config file at path: as
+// an UnknownKeysError in strict mode, otherwise as one warning per key.
+func checkUnknownKeys(path, format string, b []byte, strict bool) error {
+	keys, err := UnknownKeys(format, b)
+	if err != nil || len(keys) == 0 {
+		return err
+	}
+	if strict {
+		return &UnknownKeysError{Path: path, Keys: keys}
+	}
+	for _, k := range keys {
+		if _, warned := unknownKeyWarned.LoadOrStore(path+"\x00"+k.Key, true); warned {
+			continue
+		}
+		fmt.Fprintf(unknownKeyOut, "warning: %s: unknown key %s; it is ignored\n", path, k)
+	}
+	return nil
+}
+
+// configFields maps the yaml names of the fields of struct type t to their
+// types.
+func configFields(t reflect.Type) map[string]reflect.Type {
+	fields := make(map[string]reflect.Type, t.NumField())
+	for i := range t.NumField() {
+		f := t.Field(i)
+		name, _, _ := strings.Cut(f.Tag.Get("yaml"), ",")
+		if name != "" && name != "-" {
+			fields[name] = f.Type
+		}
+	}
+	return fields
+}
+
+func collectUnknownKeys(tree map[string]any, t reflect.Type, prefix string, out *[]string) {
+	fields := configFields(t)
+	for name, child := range tree {
+		key := name
+		if prefix != "" {
+			key = prefix + "." + name
+		}
+		ft, ok := fields[name]
+		if !ok {
+			*out = append(*out, key)
+			continue
+		}
+		if section, ok := child.(map[string]any); ok && ft.Kind() == reflect.Struct {
+			collectUnknownKeys(section, ft, key, out)
+		}
+	}
+}
+
+// knownKeys lists the dotted keys of struct type t, sections included.
+func knownKeys(t reflect.Type, prefix string) []string {
+	var keys []string
+	for name, ft := range configFields(t) {
+		key := name
+		if prefix != "" {
+			key = prefix + "." + name
+		}
+		keys = append(keys, key)
+		if ft.Kind() == reflect.Struct {
+			keys = append(keys, knownKeys(ft, key)...)
+		}
+	}
+	return keys
+}
+
+// closestKey is the known key nearest to key by edit distance, or "" when
+// none is close enough to be what was meant.
+func closestKey(key string, known []string) string {
+	best, bestDistance := "", len(key)/3+1
+	for _, candidate := range known {
+		if d := editDistance(key, candidate); d < bestDistance || (d == bestDistance && best != "" && candidate < best) {
+			best, bestDistance = candidate, d
+		}
+	}
+	return best
+}
+
+// editDistance is the Levenshtein distance between a and b.
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(b)]
+}
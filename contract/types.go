@@ -9,18 +9,59 @@ const (
 	QuestionTypeChoice QuestionType = "choice"
 )
 
+type Priority string
+
+const (
+	PriorityLow    Priority = "low"
+	PriorityNormal Priority = "normal"
+	PriorityHigh   Priority = "high"
+)
+
 type Choice struct {
-	ID   string `json:"id" yaml:"id"`
-	Text string `json:"text" yaml:"text"`
+	ID          string `json:"id" yaml:"id"`
+	Text        string `json:"text" yaml:"text"`
+	Description string `json:"description,omitempty" yaml:"description,omitempty"`
 }
 
 type AskRequest struct {
-	RequestID  string       `json:"request_id"`
-	Question   string       `json:"question"`
-	Type       QuestionType `json:"type"`
-	Choices    []Choice     `json:"choices,omitempty"`
-	AllowOther bool         `json:"allow_other,omitempty"`
-	SentAt     time.Time    `json:"sent_at"`
+	RequestID    string            `json:"request_id"`
+	Question     string            `json:"question"`
+	Type         QuestionType      `json:"type"`
+	Choices      []Choice          `json:"choices,omitempty"`
+	AllowOther   bool              `json:"allow_other,omitempty"`
+	Priority     Priority          `json:"priority,omitempty"`
+	FollowUpTo   string            `json:"follow_up_to,omitempty"`
+	Session      string            `json:"session,omitempty"`
+	Tags         map[string]string `json:"tags,omitempty"`
+	PreFormatted bool              `json:"pre_formatted,omitempty"`
+	SentAt       time.Time         `json:"sent_at"`
+	// Poll asks a choice question as a native poll where the provider has
+	// one. With PollWait set, the most voted option answers once it passes.
+	Poll     bool          `json:"poll,omitempty"`
+	PollWait time.Duration `json:"poll_wait,omitempty"`
+	// Silent delivers the question, and the reminders it causes, without a
+	// notification sound.
+	Silent bool `json:"silent,omitempty"`
+	// ShowDeadline keeps the time left until the ask deadline on the
+	// question message.
+	ShowDeadline bool `json:"show_deadline,omitempty"`
+	// Origin names the machine or repo asking, for humans answering
+	// several agents in one chat.
+	Origin string `json:"origin,omitempty"`
+}
+
+// Notification is a one-way message that expects no reply. Attachments are
+// local file paths.
+type Notification struct {
+	Text        string   `json:"text"`
+	Attachments []string `json:"attachments,omitempty"`
+	Silent      bool     `json:"silent,omitempty"`
+}
+
+// Attachment is a file that came with a reply, saved on this machine.
+type Attachment struct {
+	Path     string `json:"path"`
+	MimeType string `json:"mime_type,omitempty"`
 }
 
 type Reply struct {
@@ -30,15 +71,26 @@ type Reply struct {
 	ProviderMessageID string    `json:"provider_message_id,omitempty"`
 	ReceivedAt        time.Time `json:"received_at"`
 	Raw               string    `json:"raw,omitempty"`
+	// Attachments holds files sent as the answer, such as a voice message.
+	Attachments []Attachment `json:"attachments,omitempty"`
+	// Edited is set when the human edited their message and Text is the
+	// edited version.
+	Edited bool `json:"edited,omitempty"`
 }
 
 type AskResult struct {
 	RequestID    string       `json:"request_id"`
 	Provider     string       `json:"provider"`
 	QuestionType QuestionType `json:"question_type"`
+	Question     string       `json:"question,omitempty"`
+	Choices      []Choice     `json:"choices,omitempty"`
+	SentAt       time.Time    `json:"sent_at,omitzero"`
 	Text         string       `json:"text,omitempty"`
 	SelectedIDs  []string     `json:"selected_ids,omitempty"`
 	OtherText    string       `json:"other_text,omitempty"`
 	RawReply     string       `json:"raw_reply,omitempty"`
+	Attachments  []Attachment `json:"attachments,omitempty"`
+	Edited       bool         `json:"edited,omitempty"`
+	Cancelled    bool         `json:"cancelled,omitempty"`
 	ReceivedAt   time.Time    `json:"received_at"`
 }
@@ -0,0 +1,121 @@
+// Package filelock serializes access to a state file between processes with
+// a lock file that holds the owner's PID.
+package filelock
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
+
+const (
+	// Wait is how long With waits for another process to release the lock.
+	Wait = 3 * time.Second
+	// MaxAge is how old a lock file without a readable PID may get before it
+	// is treated as abandoned.
+	MaxAge = 10 * time.Second
+)
+
+// Lock is the lock file at Path. Name says what it guards in the timeout
+// error, and MaxAge overrides the package MaxAge when set.
+type Lock struct {
+	Path   string
+	Name   string
+	MaxAge time.Duration
+}
+
+// With runs fn while holding the lock, waiting up to Wait for it.
+func (l Lock) With(fn func() error) error {
+	deadline := time.Now().Add(Wait)
+	for {
+		ok, err := l.TryWith(fn)
+		if ok || err != nil {
+			return err
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("timeout waiting for %s lock", l.Name)
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+}
+
+// TryWith runs fn if the lock is free or stale and reports whether it did.
+func (l Lock) TryWith(fn func() error) (bool, error) {
+	if err := os.MkdirAll(filepath.Dir(l.Path), 0o755); err != nil {
+		return false, err
+	}
+	for {
+		f, err := os.OpenFile(l.Path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o600)
+		if err == nil {
+			_, _ = f.WriteString(fmt.Sprintf("%d\n", os.Getpid()))
+			_ = f.Close()
+			defer os.Remove(l.Path)
+			return true, fn()
+		}
+		if !os.IsExist(err) {
+			return false, err
+		}
+		if stale, staleErr := l.Stale(); staleErr == nil && stale {
+			_ = os.Remove(l.Path)
+			continue
+		}
+		return false, nil
+	}
+}
+
+// Stale reports whether the lock file is left over from a process that is
+// gone, or, where that cannot be checked, older than MaxAge.
+func (l Lock) Stale() (bool, error) {
+	st, err := os.Stat(l.Path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	rawPID, _ := os.ReadFile(l.Path)
+	pid, parseErr := strconv.Atoi(strings.TrimSpace(string(rawPID)))
+	if parseErr == nil && pid > 0 && runtime.GOOS != "windows" {
+		return !ProcessExists(pid), nil
+	}
+
+	// If we can't parse the PID, fall back to lock-file age.
+	maxAge := l.MaxAge
+	if maxAge <= 0 {
+		maxAge = MaxAge
+	}
+	return time.Since(st.ModTime()) > maxAge, nil
+}
+
+// ProcessExists reports whether a process with pid is running. It is only
+// meaningful outside Windows.
+func ProcessExists(pid int) bool {
+	if pid <= 0 {
+		return false
+	}
+	proc, err := os.FindProcess(pid)
+	if err != nil {
+		return false
+	}
+	err = proc.Signal(syscall.Signal(0))
+	if err == nil {
+		return true
+	}
+	var errno syscall.Errno
+	if errors.As(err, &errno) {
+		if errno == syscall.EPERM {
+			return true
+		}
+		if errno == syscall.ESRCH {
+			return false
+		}
+	}
+	return false
+}
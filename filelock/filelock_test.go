@@ -0,0 +1,71 @@
+package filelock
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+)
+
+func TestWithRemovesLockAfterRun(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state", "store.json.lock")
+	ran := false
+	err := Lock{Path: path, Name: "test store"}.With(func() error {
+		ran = true
+		if _, err := os.Stat(path); err != nil {
+			t.Fatalf("expected lock file while held: %v", err)
+		}
+		return nil
+	})
+	if err != nil || !ran {
+		t.Fatalf("With: ran=%v err=%v", ran, err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Fatalf("expected lock file removed, got %v", err)
+	}
+}
+
+func TestTryWithReportsLiveHolder(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "store.json.lock")
+	l := Lock{Path: path, Name: "test store"}
+	err := l.With(func() error {
+		ok, err := l.TryWith(func() error {
+			t.Fatalf("expected the held lock to stay held")
+			return nil
+		})
+		if ok || err != nil {
+			t.Fatalf("TryWith on held lock: ok=%v err=%v", ok, err)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("With: %v", err)
+	}
+}
+
+func TestWithRecoversStaleLock(t *testing.T) {
+	dir := t.TempDir()
+	deadPID := filepath.Join(dir, "dead.lock")
+	if err := os.WriteFile(deadPID, []byte("999999\n"), 0o600); err != nil {
+		t.Fatalf("write lock: %v", err)
+	}
+	old := filepath.Join(dir, "old.lock")
+	if err := os.WriteFile(old, nil, 0o600); err != nil {
+		t.Fatalf("write lock: %v", err)
+	}
+	past := time.Now().Add(-time.Minute)
+	if err := os.Chtimes(old, past, past); err != nil {
+		t.Fatalf("chtimes: %v", err)
+	}
+
+	paths := []string{old}
+	if runtime.GOOS != "windows" {
+		paths = append(paths, deadPID)
+	}
+	for _, path := range paths {
+		if err := (Lock{Path: path, Name: "test store"}).With(func() error { return nil }); err != nil {
+			t.Fatalf("With on stale lock %s: %v", filepath.Base(path), err)
+		}
+	}
+}
@@ -0,0 +1,33 @@
+// Package gitrepo finds the git repository a directory belongs to.
+package gitrepo
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// FindRoot walks up from startPath to the nearest directory holding a .git
+// entry, which is a directory in a clone and a file in a worktree.
+func FindRoot(startPath string) (string, bool, error) {
+	if strings.TrimSpace(startPath) == "" {
+		return "", false, fmt.Errorf("empty start path")
+	}
+	current := filepath.Clean(startPath)
+	for {
+		gitPath := filepath.Join(current, ".git")
+		if _, err := os.Stat(gitPath); err == nil {
+			return current, true, nil
+		} else if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return "", false, err
+		}
+
+		parent := filepath.Dir(current)
+		if parent == current {
+			return "", false, nil
+		}
+		current = parent
+	}
+}
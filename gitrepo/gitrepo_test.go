@@ -0,0 +1,30 @@
+package gitrepo
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestFindRoot(t *testing.T) {
+	root := t.TempDir()
+	repo := filepath.Join(root, "repo")
+	sub := filepath.Join(repo, "a", "b")
+	if err := os.MkdirAll(filepath.Join(repo, ".git"), 0o755); err != nil {
+		t.Fatalf("mkdir .git: %v", err)
+	}
+	if err := os.MkdirAll(sub, 0o755); err != nil {
+		t.Fatalf("mkdir sub: %v", err)
+	}
+
+	got, found, err := FindRoot(sub)
+	if err != nil {
+		t.Fatalf("FindRoot: %v", err)
+	}
+	if !found {
+		t.Fatalf("expected repo to be found")
+	}
+	if got != repo {
+		t.Fatalf("expected repo %q got %q", repo, got)
+	}
+}
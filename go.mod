@@ -3,6 +3,7 @@ module github.com/AlhasanIQ/consult-human
 go 1.25.0
 
 require (
+	github.com/BurntSushi/toml v1.6.0
 	golang.org/x/term v0.40.0
 	gopkg.in/yaml.v3 v3.0.1
 )
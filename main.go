@@ -15,6 +15,6 @@ func main() {
 	cmd.SetEmbeddedSkillTemplate(embeddedSkillDoc)
 	if err := cmd.Execute(os.Args[1:], cmd.IO{In: os.Stdin, Out: os.Stdout, ErrOut: os.Stderr}); err != nil {
 		fmt.Fprintln(os.Stderr, err)
-		os.Exit(1)
+		os.Exit(cmd.ExitCode(err))
 	}
 }
@@ -0,0 +1,115 @@
+package provider
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/AlhasanIQ/consult-human/contract"
+)
+
+const consoleReplyFrom = "local-terminal"
+
+// ConsoleProvider asks the question in the local terminal: the prompt goes to
+// out and the answer is read as the next non-empty line of in.
+type ConsoleProvider struct {
+	in  *bufio.Reader
+	out io.Writer
+
+	mu      sync.Mutex
+	pending map[string]contract.AskRequest
+}
+
+func NewConsole(in io.Reader, out io.Writer) *ConsoleProvider {
+	return &ConsoleProvider{
+		in:      bufio.NewReader(in),
+		out:     out,
+		pending: make(map[string]contract.AskRequest),
+	}
+}
+
+func (p *ConsoleProvider) Name() string { return "console" }
+
+func (p *ConsoleProvider) Close() error { return nil }
+
+func (p *ConsoleProvider) Send(_ context.Context, req contract.AskRequest) (string, error) {
+	if _, err := fmt.Fprintf(p.out, "\n%s\n> ", RenderPrompt(req)); err != nil {
+		return "", err
+	}
+
+	p.mu.Lock()
+	p.pending[req.RequestID] = req
+	p.mu.Unlock()
+	return req.RequestID, nil
+}
+
+func (p *ConsoleProvider) Receive(ctx context.Context, requestID string) (contract.Reply, error) {
+	p.mu.Lock()
+	_, ok := p.pending[requestID]
+	delete(p.pending, requestID)
+	p.mu.Unlock()
+	if !ok {
+		return contract.Reply{}, fmt.Errorf("unknown request id %q", requestID)
+	}
+
+	type readResult struct {
+		line string
+		err  error
+	}
+	// The read cannot be interrupted, so it runs in its own goroutine and a
+	// timed-out ask simply stops waiting for it.
+	done := make(chan readResult, 1)
+	go func() {
+		line, err := p.readAnswer()
+		done <- readResult{line: line, err: err}
+	}()
+
+	select {
+	case <-ctx.Done():
+		return contract.Reply{}, ctx.Err()
+	case res := <-done:
+		if res.err != nil {
+			return contract.Reply{}, res.err
+		}
+		return contract.Reply{
+			RequestID:  requestID,
+			Text:       res.line,
+			Raw:        res.line,
+			From:       consoleReplyFrom,
+			ReceivedAt: time.Now().UTC(),
+		}, nil
+	}
+}
+
+func (p *ConsoleProvider) Notify(_ context.Context, n contract.Notification) error {
+	if text := strings.TrimSpace(n.Text); text != "" {
+		if _, err := fmt.Fprintf(p.out, "\n%s\n", text); err != nil {
+			return err
+		}
+	}
+	for _, path := range n.Attachments {
+		if _, err := fmt.Fprintf(p.out, "attachment: %s\n", path); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (p *ConsoleProvider) readAnswer() (string, error) {
+	for {
+		line, err := p.in.ReadString('\n')
+		if text := strings.TrimSpace(line); text != "" {
+			return text, nil
+		}
+		if err == io.EOF {
+			return "", fmt.Errorf("input closed before a reply was entered")
+		}
+		if err != nil {
+			return "", err
+		}
+	}
+}
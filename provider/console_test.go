@@ -0,0 +1,67 @@
+package provider
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/AlhasanIQ/consult-human/contract"
+)
+
+func TestConsoleProviderReadsReplyFromInput(t *testing.T) {
+	var out bytes.Buffer
+	p := NewConsole(strings.NewReader("\n  B  \n"), &out)
+
+	req := contract.AskRequest{
+		RequestID: "req-console",
+		Question:  "Which approach?",
+		Type:      contract.QuestionTypeChoice,
+		Choices:   []contract.Choice{{ID: "A", Text: "Shared"}, {ID: "B", Text: "Inline"}},
+	}
+	if _, err := p.Send(context.Background(), req); err != nil {
+		t.Fatalf("Send returned error: %v", err)
+	}
+	if !strings.Contains(out.String(), "- B) Inline") {
+		t.Fatalf("expected rendered choices in prompt, got %q", out.String())
+	}
+
+	reply, err := p.Receive(context.Background(), req.RequestID)
+	if err != nil {
+		t.Fatalf("Receive returned error: %v", err)
+	}
+	if reply.Text != "B" || reply.From != "local-terminal" || reply.RequestID != req.RequestID {
+		t.Fatalf("unexpected reply: %#v", reply)
+	}
+}
+
+func TestConsoleProviderRespectsContextTimeout(t *testing.T) {
+	r, w := io.Pipe()
+	defer w.Close()
+	p := NewConsole(r, io.Discard)
+
+	req := contract.AskRequest{RequestID: "req-wait", Question: "Ship it?", Type: contract.QuestionTypeOpen}
+	if _, err := p.Send(context.Background(), req); err != nil {
+		t.Fatalf("Send returned error: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Millisecond)
+	defer cancel()
+	if _, err := p.Receive(ctx, req.RequestID); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected deadline exceeded, got %v", err)
+	}
+}
+
+func TestConsoleProviderFailsOnClosedInput(t *testing.T) {
+	p := NewConsole(strings.NewReader(""), io.Discard)
+	req := contract.AskRequest{RequestID: "req-eof", Question: "Ship it?", Type: contract.QuestionTypeOpen}
+	if _, err := p.Send(context.Background(), req); err != nil {
+		t.Fatalf("Send returned error: %v", err)
+	}
+	if _, err := p.Receive(context.Background(), req.RequestID); err == nil {
+		t.Fatalf("expected error when input closes without a reply")
+	}
+}
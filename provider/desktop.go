@@ -0,0 +1,63 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strconv"
+
+	"github.com/AlhasanIQ/consult-human/config"
+	"github.com/AlhasanIQ/consult-human/contract"
+)
+
+// DesktopProvider shows a desktop notification for each question and serves
+// a reply form on 127.0.0.1. The server starts with the first question and
+// stops once the last one is answered or abandoned.
+type DesktopProvider struct {
+	// notify shows the notification; clicking it opens url.
+	notify func(title, body, url string) error
+	form   *replyForm
+}
+
+func NewDesktop(cfg config.Config) (*DesktopProvider, error) {
+	return &DesktopProvider{
+		notify: desktopNotify,
+		form:   newReplyForm(net.JoinHostPort("127.0.0.1", strconv.Itoa(cfg.Desktop.Port)), true),
+	}, nil
+}
+
+func (p *DesktopProvider) Name() string { return "desktop" }
+
+func (p *DesktopProvider) Close() error { return p.form.close() }
+
+func (p *DesktopProvider) Send(ctx context.Context, req contract.AskRequest) (string, error) {
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+	replyURL, err := p.form.open(req)
+	if err != nil {
+		return "", err
+	}
+
+	title := "consult-human"
+	if req.Priority == contract.PriorityHigh {
+		title = "consult-human (urgent)"
+	}
+	body := req.Question
+	if r := []rune(body); len(r) > 200 {
+		body = string(r[:199]) + "…"
+	}
+	if err := p.notify(title, body, replyURL); err != nil {
+		p.form.resolve(req.RequestID)
+		return "", fmt.Errorf("show desktop notification: %w", err)
+	}
+	return req.RequestID, nil
+}
+
+func (p *DesktopProvider) Receive(ctx context.Context, requestID string) (contract.Reply, error) {
+	return p.form.wait(ctx, requestID)
+}
+
+// URL is the address of the index page listing the waiting questions, or
+// "" while no question is waiting.
+func (p *DesktopProvider) URL() string { return p.form.URL() }
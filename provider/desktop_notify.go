@@ -0,0 +1,110 @@
+package provider
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+// desktopDialogTimeoutSeconds is how long the macOS dialog stays up before
+// it gives up; the question stays answerable from the index page.
+const desktopDialogTimeoutSeconds = 600
+
+// desktopNotify shows a notification that opens url when clicked. The
+// notifiers that wait for the click keep running in the background.
+func desktopNotify(title, body, url string) error {
+	switch runtime.GOOS {
+	case "darwin":
+		return notifyDarwin(title, body, url)
+	case "windows":
+		return notifyWindows(title, body, url)
+	default:
+		return notifyLinux(title, body, url)
+	}
+}
+
+// notifyLinux uses notify-send. With libnotify 0.7.10 or later it waits
+// for a click and opens the form; older versions reject --action, so the
+// URL goes in the body instead.
+func notifyLinux(title, body, url string) error {
+	path, err := exec.LookPath("notify-send")
+	if err != nil {
+		return fmt.Errorf("notify-send not found; install libnotify (e.g. the libnotify-bin package), then answer at %s", url)
+	}
+	cmd := exec.Command(path, "--app-name=consult-human", "--action=default=Reply", "--wait", title, body)
+	var out bytes.Buffer
+	cmd.Stdout = &out
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	go func() {
+		if err := cmd.Wait(); err != nil {
+			_ = exec.Command(path, "--app-name=consult-human", title, body+"\n\nAnswer at "+url).Run()
+			return
+		}
+		if strings.TrimSpace(out.String()) == "default" {
+			_ = exec.Command("xdg-open", url).Run()
+		}
+	}()
+	return nil
+}
+
+// notifyDarwin uses an osascript dialog: plain notifications cannot open a
+// URL when clicked.
+func notifyDarwin(title, body, url string) error {
+	script := fmt.Sprintf(
+		`display dialog %s with title %s buttons {"Later", "Reply"} default button "Reply" giving up after %d`,
+		appleScriptString(body), appleScriptString(title), desktopDialogTimeoutSeconds,
+	)
+	cmd := exec.Command("osascript", "-e", script)
+	var out bytes.Buffer
+	cmd.Stdout = &out
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	go func() {
+		if cmd.Wait() == nil && strings.Contains(out.String(), "button returned:Reply") {
+			_ = exec.Command("open", url).Run()
+		}
+	}()
+	return nil
+}
+
+// notifyWindows shows a toast through PowerShell; clicking it opens url in
+// the default browser.
+func notifyWindows(title, body, url string) error {
+	toast := fmt.Sprintf(
+		`<toast activationType="protocol" launch="%s"><visual><binding template="ToastGeneric"><text>%s</text><text>%s</text></binding></visual></toast>`,
+		xmlEscape(url), xmlEscape(title), xmlEscape(body),
+	)
+	// The single-quoted here-string expands nothing, and escaped XML cannot
+	// contain the '@ that would end it.
+	script := strings.Join([]string{
+		"[Windows.UI.Notifications.ToastNotificationManager, Windows.UI.Notifications, ContentType = WindowsRuntime] | Out-Null",
+		"[Windows.Data.Xml.Dom.XmlDocument, Windows.Data.Xml.Dom.XmlDocument, ContentType = WindowsRuntime] | Out-Null",
+		"$doc = New-Object Windows.Data.Xml.Dom.XmlDocument",
+		"$doc.LoadXml(@'",
+		toast,
+		"'@)",
+		`$app = '{1AC14E77-02E7-4E5D-B744-2EB1AE5198B7}\WindowsPowerShell\v1.0\powershell.exe'`,
+		"[Windows.UI.Notifications.ToastNotificationManager]::CreateToastNotifier($app).Show([Windows.UI.Notifications.ToastNotification]::new($doc))",
+	}, "\n")
+	if out, err := exec.Command("powershell", "-NoProfile", "-NonInteractive", "-Command", script).CombinedOutput(); err != nil {
+		return fmt.Errorf("%w: %s", err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+func appleScriptString(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	return `"` + strings.ReplaceAll(s, `"`, `\"`) + `"`
+}
+
+func xmlEscape(s string) string {
+	var b strings.Builder
+	_ = xml.EscapeText(&b, []byte(s))
+	return b.String()
+}
@@ -0,0 +1,108 @@
+package provider
+
+import (
+	"html/template"
+	"net/http"
+
+	"github.com/AlhasanIQ/consult-human/contract"
+)
+
+type desktopIndexView struct {
+	Questions []desktopIndexItem
+}
+
+type desktopIndexItem struct {
+	RequestID string
+	Question  string
+	Origin    string
+	Urgent    bool
+}
+
+type desktopFormView struct {
+	RequestID  string
+	Question   string
+	Origin     string
+	Urgent     bool
+	Choices    []contract.Choice
+	FreeText   bool
+	Token      string
+	ActionPath string
+	// Index links back to the list of waiting questions.
+	Index bool
+}
+
+type desktopMessageView struct {
+	Title   string
+	Message string
+	Index   bool
+}
+
+const desktopPageHead = `<!doctype html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>consult-human</title>
+<style>
+  body { margin: 0; min-height: 100vh; display: flex; align-items: center; justify-content: center; background: #f4f5f7; color: #1f2328; font-family: -apple-system, BlinkMacSystemFont, "Segoe UI", Roboto, sans-serif; }
+  main { background: #fff; border-radius: 12px; box-shadow: 0 2px 12px rgba(0, 0, 0, .08); padding: 28px 32px; width: min(560px, calc(100vw - 48px)); }
+  h1 { font-size: 18px; margin: 0 0 16px; }
+  .meta { color: #656d76; font-size: 13px; margin-bottom: 12px; }
+  .urgent { color: #cf222e; font-weight: 600; }
+  .question { white-space: pre-wrap; font-size: 16px; line-height: 1.5; margin: 0 0 20px; }
+  ul { list-style: none; padding: 0; margin: 0; }
+  li { border-top: 1px solid #d0d7de; padding: 12px 0; }
+  li:first-child { border-top: 0; }
+  a { color: #0969da; text-decoration: none; }
+  button { display: block; width: 100%; text-align: left; font: inherit; padding: 10px 14px; margin-bottom: 8px; border: 1px solid #d0d7de; border-radius: 8px; background: #f6f8fa; cursor: pointer; }
+  button:hover { background: #eef1f4; }
+  button.primary { text-align: center; background: #1f883d; border-color: #1f883d; color: #fff; }
+  .choice-desc { display: block; color: #656d76; font-size: 13px; margin-top: 2px; }
+  textarea { box-sizing: border-box; width: 100%; min-height: 96px; font: inherit; padding: 10px; border: 1px solid #d0d7de; border-radius: 8px; margin-bottom: 8px; }
+</style>
+</head>
+<body>
+<main>
+`
+
+const desktopPageFoot = `</main>
+</body>
+</html>
+`
+
+var desktopIndexTemplate = template.Must(template.New("index").Parse(desktopPageHead + `<h1>Waiting for your answer</h1>
+{{if .Questions}}<ul>
+{{range .Questions}}<li>
+  <div class="meta">{{if .Urgent}}<span class="urgent">Urgent · </span>{{end}}{{.RequestID}}{{if .Origin}} · {{.Origin}}{{end}}</div>
+  <a href="/reply/{{.RequestID}}">{{.Question}}</a>
+</li>
+{{end}}</ul>
+{{else}}<p>No questions are waiting.</p>
+{{end}}` + desktopPageFoot))
+
+var desktopFormTemplate = template.Must(template.New("form").Parse(desktopPageHead + `<h1>consult-human</h1>
+<div class="meta">{{if .Urgent}}<span class="urgent">Urgent · </span>{{end}}{{.RequestID}}{{if .Origin}} · {{.Origin}}{{end}}</div>
+<p class="question">{{.Question}}</p>
+<form method="post" action="{{.ActionPath}}">
+  <input type="hidden" name="token" value="{{.Token}}">
+  {{range .Choices}}<button type="submit" name="choice" value="{{.ID}}">{{.ID}}) {{.Text}}{{if .Description}}<span class="choice-desc">{{.Description}}</span>{{end}}</button>
+  {{end}}{{if .FreeText}}<textarea name="text" placeholder="{{if .Choices}}Or write your own answer{{else}}Your answer{{end}}" autofocus></textarea>
+  <button type="submit" class="primary">Send answer</button>
+  {{end}}
+</form>
+{{if .Index}}<p class="meta"><a href="/">All waiting questions</a></p>
+{{end}}` + desktopPageFoot))
+
+var desktopMessageTemplate = template.Must(template.New("message").Parse(desktopPageHead + `<h1>{{.Title}}</h1>
+<p>{{.Message}}</p>
+{{if .Index}}<p class="meta"><a href="/">All waiting questions</a></p>
+{{end}}` + desktopPageFoot))
+
+func renderDesktopPage(w http.ResponseWriter, status int, tmpl *template.Template, data any) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-store")
+	// The form must not be framed by another page.
+	w.Header().Set("X-Frame-Options", "DENY")
+	w.WriteHeader(status)
+	_ = tmpl.Execute(w, data)
+}
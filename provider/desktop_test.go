@@ -0,0 +1,166 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/AlhasanIQ/consult-human/config"
+	"github.com/AlhasanIQ/consult-human/contract"
+)
+
+var desktopTokenPattern = regexp.MustCompile(`name="token" value="([0-9a-f]+)"`)
+
+func newTestDesktopProvider(t *testing.T) (*DesktopProvider, *[]string) {
+	t.Helper()
+	p, err := NewDesktop(config.Default())
+	if err != nil {
+		t.Fatalf("NewDesktop returned error: %v", err)
+	}
+	var notified []string
+	p.notify = func(title, body, url string) error {
+		notified = append(notified, url)
+		return nil
+	}
+	t.Cleanup(func() { _ = p.Close() })
+	return p, &notified
+}
+
+func getDesktopPage(t *testing.T, pageURL string) (int, string) {
+	t.Helper()
+	resp, err := http.Get(pageURL)
+	if err != nil {
+		t.Fatalf("GET %s: %v", pageURL, err)
+	}
+	defer resp.Body.Close()
+	b, _ := io.ReadAll(resp.Body)
+	return resp.StatusCode, string(b)
+}
+
+func postDesktopForm(t *testing.T, pageURL string, form url.Values) (int, string) {
+	t.Helper()
+	resp, err := http.PostForm(pageURL, form)
+	if err != nil {
+		t.Fatalf("POST %s: %v", pageURL, err)
+	}
+	defer resp.Body.Close()
+	b, _ := io.ReadAll(resp.Body)
+	return resp.StatusCode, string(b)
+}
+
+func TestDesktopFormAnswersEachQuestion(t *testing.T) {
+	p, notified := newTestDesktopProvider(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if _, err := p.Send(ctx, contract.AskRequest{
+		RequestID: "req-1",
+		Question:  "Deploy <now>?",
+		Type:      contract.QuestionTypeChoice,
+		Choices:   []contract.Choice{{ID: "a", Text: "Yes"}, {ID: "b", Text: "No"}},
+	}); err != nil {
+		t.Fatalf("Send returned error: %v", err)
+	}
+	if _, err := p.Send(ctx, contract.AskRequest{RequestID: "req-2", Question: "Which region?", Origin: "api-repo"}); err != nil {
+		t.Fatalf("Send returned error: %v", err)
+	}
+	base := strings.TrimSuffix(p.URL(), "/")
+	if len(*notified) != 2 || (*notified)[0] != base+"/reply/req-1" {
+		t.Fatalf("expected a notification per question opening its form, got %#v", *notified)
+	}
+
+	status, index := getDesktopPage(t, base+"/")
+	if status != http.StatusOK || strings.Index(index, "Deploy &lt;now&gt;?") > strings.Index(index, "Which region?") || !strings.Contains(index, "api-repo") {
+		t.Fatalf("expected both questions listed in order, got %d: %s", status, index)
+	}
+
+	_, form := getDesktopPage(t, base+"/reply/req-1")
+	m := desktopTokenPattern.FindStringSubmatch(form)
+	if m == nil || !strings.Contains(form, `name="choice" value="a"`) || strings.Contains(form, "<textarea") {
+		t.Fatalf("expected choice buttons and no text box, got: %s", form)
+	}
+	token := m[1]
+
+	if status, _ := postDesktopForm(t, base+"/reply/req-1", url.Values{"choice": {"a"}}); status != http.StatusForbidden {
+		t.Fatalf("expected a post without the form token to be refused, got %d", status)
+	}
+	if status, _ := postDesktopForm(t, base+"/reply/req-1", url.Values{"token": {token}, "choice": {"a"}}); status != http.StatusOK {
+		t.Fatalf("expected the answer to be accepted, got %d", status)
+	}
+	reply, err := p.Receive(ctx, "req-1")
+	if err != nil {
+		t.Fatalf("Receive returned error: %v", err)
+	}
+	if reply.Text != "a" || reply.RequestID != "req-1" {
+		t.Fatalf("unexpected reply: %#v", reply)
+	}
+
+	// The server keeps running for the question still waiting.
+	if status, _ := getDesktopPage(t, base+"/reply/req-1"); status != http.StatusNotFound {
+		t.Fatalf("expected the answered question to be gone, got %d", status)
+	}
+	_, form = getDesktopPage(t, base+"/reply/req-2")
+	if !strings.Contains(form, "<textarea") {
+		t.Fatalf("expected a text box for an open question, got: %s", form)
+	}
+	if status, _ := postDesktopForm(t, base+"/reply/req-2", url.Values{"token": {token}, "text": {"  eu-west-1 "}}); status != http.StatusOK {
+		t.Fatalf("expected the answer to be accepted, got %d", status)
+	}
+	reply, err = p.Receive(ctx, "req-2")
+	if err != nil {
+		t.Fatalf("Receive returned error: %v", err)
+	}
+	if reply.Text != "eu-west-1" {
+		t.Fatalf("unexpected reply: %#v", reply)
+	}
+
+	if p.URL() != "" {
+		t.Fatalf("expected the server to stop after the last answer")
+	}
+	if _, err := http.Get(base + "/"); err == nil {
+		t.Fatalf("expected the reply form to be gone")
+	}
+}
+
+func TestDesktopReceiveCanceledStopsServer(t *testing.T) {
+	p, _ := newTestDesktopProvider(t)
+	if _, err := p.Send(context.Background(), contract.AskRequest{RequestID: "req-1", Question: "Deploy?"}); err != nil {
+		t.Fatalf("Send returned error: %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := p.Receive(ctx, "req-1"); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+	if p.URL() != "" {
+		t.Fatalf("expected the server to stop once nothing is waiting")
+	}
+}
+
+func TestDesktopSendNotifyFailure(t *testing.T) {
+	p, _ := newTestDesktopProvider(t)
+	p.notify = func(title, body, url string) error { return errors.New("notify-send not found") }
+	_, err := p.Send(context.Background(), contract.AskRequest{RequestID: "req-1", Question: "Deploy?"})
+	if err == nil || !strings.Contains(err.Error(), "notify-send not found") {
+		t.Fatalf("expected the notification error, got %v", err)
+	}
+	if p.URL() != "" {
+		t.Fatalf("expected no server left running")
+	}
+}
+
+func TestFactoryUsesDesktop(t *testing.T) {
+	p, err := New(config.Default(), "desktop")
+	if err != nil {
+		t.Fatalf("New returned error: %v", err)
+	}
+	if p.Name() != "desktop" {
+		t.Fatalf("unexpected provider %q", p.Name())
+	}
+}
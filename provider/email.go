@@ -0,0 +1,329 @@
+package provider
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/tls"
+	"encoding/hex"
+	"fmt"
+	"mime"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/AlhasanIQ/consult-human/config"
+	"github.com/AlhasanIQ/consult-human/contract"
+)
+
+const (
+	emailDefaultPollInterval = 15 * time.Second
+	emailDialTimeout         = 30 * time.Second
+	emailSubjectQuestionMax  = 60
+)
+
+// EmailProvider mails questions over SMTP and polls an IMAP mailbox for the
+// reply. Replies are matched by In-Reply-To/References, which carry the
+// question's Message-ID, or by the request ID in the subject.
+type EmailProvider struct {
+	smtpHost     string
+	smtpPort     int
+	smtpUsername string
+	smtpPassword string
+	imapHost     string
+	imapPort     int
+	imapUsername string
+	imapPassword string
+	mailbox      string
+	from         string
+	to           string
+	pollInterval time.Duration
+
+	// sendMail and search stand in for the SMTP and IMAP round trips in
+	// tests.
+	sendMail func(ctx context.Context, msg []byte) error
+	search   func(ctx context.Context, requestID string, since time.Time) ([]emailFetched, error)
+
+	mu      sync.Mutex
+	pending map[string]emailThread
+}
+
+// emailThread is the question as sent: its Message-ID and when it left.
+type emailThread struct {
+	MessageID string
+	SentAt    time.Time
+}
+
+// emailFetched is a raw message pulled from the mailbox.
+type emailFetched struct {
+	Raw []byte
+}
+
+func NewEmail(cfg config.Config) (*EmailProvider, error) {
+	e := cfg.Email
+	if strings.TrimSpace(e.SMTPHost) == "" || strings.TrimSpace(e.IMAPHost) == "" || strings.TrimSpace(e.From) == "" || strings.TrimSpace(e.To) == "" {
+		return nil, fmt.Errorf(
+			"email.smtp_host, email.imap_host, email.from and email.to are required.\n" +
+				"First-time email setup:\n" +
+				"1) Get the SMTP and IMAP servers and a login (an app password where the provider requires one)\n" +
+				"2) Run: `consult-human setup --non-interactive --provider email` for the config commands",
+		)
+	}
+	p := &EmailProvider{
+		smtpHost:     strings.TrimSpace(e.SMTPHost),
+		smtpPort:     e.SMTPPort,
+		smtpUsername: e.SMTPUsername,
+		smtpPassword: e.SMTPPassword,
+		imapHost:     strings.TrimSpace(e.IMAPHost),
+		imapPort:     e.IMAPPort,
+		imapUsername: e.IMAPUsername,
+		imapPassword: e.IMAPPassword,
+		mailbox:      strings.TrimSpace(e.Mailbox),
+		from:         strings.TrimSpace(e.From),
+		to:           strings.TrimSpace(e.To),
+		pollInterval: emailDefaultPollInterval,
+		pending:      make(map[string]emailThread),
+	}
+	if p.smtpPort <= 0 {
+		p.smtpPort = config.DefaultEmailSMTPPort
+	}
+	if p.imapPort <= 0 {
+		p.imapPort = config.DefaultEmailIMAPPort
+	}
+	if p.imapUsername == "" {
+		p.imapUsername = p.smtpUsername
+	}
+	if p.imapPassword == "" {
+		p.imapPassword = p.smtpPassword
+	}
+	if p.mailbox == "" {
+		p.mailbox = config.DefaultEmailMailbox
+	}
+	if e.PollIntervalSeconds > 0 {
+		p.pollInterval = time.Duration(e.PollIntervalSeconds) * time.Second
+	}
+	p.sendMail = p.sendSMTP
+	p.search = p.searchIMAP
+	return p, nil
+}
+
+func (p *EmailProvider) Name() string { return "email" }
+
+func (p *EmailProvider) Close() error { return nil }
+
+func (p *EmailProvider) Send(ctx context.Context, req contract.AskRequest) (string, error) {
+	messageID, err := emailMessageID(req.RequestID, p.from)
+	if err != nil {
+		return "", err
+	}
+	sentAt := time.Now()
+	msg, err := renderEmailQuestion(req, p.from, p.to, messageID, sentAt)
+	if err != nil {
+		return "", err
+	}
+	if err := p.sendMail(ctx, msg); err != nil {
+		return "", err
+	}
+
+	p.mu.Lock()
+	p.pending[req.RequestID] = emailThread{MessageID: messageID, SentAt: sentAt}
+	p.mu.Unlock()
+	return messageID, nil
+}
+
+func (p *EmailProvider) Receive(ctx context.Context, requestID string) (contract.Reply, error) {
+	p.mu.Lock()
+	thread, ok := p.pending[requestID]
+	p.mu.Unlock()
+	if !ok {
+		return contract.Reply{}, fmt.Errorf("unknown request id %q", requestID)
+	}
+
+	for {
+		fetched, err := p.search(ctx, requestID, thread.SentAt)
+		if err != nil {
+			if ctx.Err() != nil {
+				return contract.Reply{}, ctx.Err()
+			}
+			return contract.Reply{}, err
+		}
+		for _, f := range fetched {
+			reply, ok, err := emailReplyFor(f.Raw, requestID, thread.MessageID)
+			if err != nil || !ok {
+				continue
+			}
+			p.mu.Lock()
+			delete(p.pending, requestID)
+			p.mu.Unlock()
+			return reply, nil
+		}
+
+		timer := time.NewTimer(p.pollInterval)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return contract.Reply{}, ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// emailReplyFor parses a fetched message and returns its answer when it is
+// a reply to the question. Replies with no text left once the quoted
+// question is removed don't count.
+func emailReplyFor(raw []byte, requestID, messageID string) (contract.Reply, bool, error) {
+	msg, err := mail.ReadMessage(bytes.NewReader(raw))
+	if err != nil {
+		return contract.Reply{}, false, err
+	}
+	if !emailMatchesRequest(msg.Header, requestID, messageID) {
+		return contract.Reply{}, false, nil
+	}
+	full, err := emailBodyText(msg)
+	if err != nil {
+		return contract.Reply{}, false, err
+	}
+	text := stripEmailQuote(full)
+	if text == "" {
+		return contract.Reply{}, false, nil
+	}
+
+	from := msg.Header.Get("From")
+	if addr, err := mail.ParseAddress(from); err == nil {
+		from = addr.Address
+	}
+	receivedAt := time.Now().UTC()
+	if date, err := msg.Header.Date(); err == nil {
+		receivedAt = date.UTC()
+	}
+	return contract.Reply{
+		RequestID:         requestID,
+		Text:              text,
+		Raw:               full,
+		From:              from,
+		ProviderMessageID: strings.TrimSpace(msg.Header.Get("Message-Id")),
+		ReceivedAt:        receivedAt,
+	}, true, nil
+}
+
+// emailMessageID embeds the request ID, so replies name it in In-Reply-To
+// and References.
+func emailMessageID(requestID, from string) (string, error) {
+	b := make([]byte, 6)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	domain := "consult-human.local"
+	if at := strings.LastIndex(from, "@"); at >= 0 && at < len(from)-1 {
+		domain = from[at+1:]
+	}
+	return fmt.Sprintf("<consult-human.%s.%s@%s>", requestID, hex.EncodeToString(b), domain), nil
+}
+
+// emailSubject carries the request ID so replies match even when the mail
+// client drops the threading headers.
+func emailSubject(req contract.AskRequest) string {
+	question := strings.Join(strings.Fields(req.Question), " ")
+	if r := []rune(question); len(r) > emailSubjectQuestionMax {
+		question = string(r[:emailSubjectQuestionMax-1]) + "…"
+	}
+	return fmt.Sprintf("[consult-human %s] %s", req.RequestID, question)
+}
+
+func renderEmailQuestion(req contract.AskRequest, from, to, messageID string, date time.Time) ([]byte, error) {
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "From: %s\r\n", from)
+	fmt.Fprintf(&b, "To: %s\r\n", to)
+	fmt.Fprintf(&b, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", emailSubject(req)))
+	fmt.Fprintf(&b, "Date: %s\r\n", date.Format(time.RFC1123Z))
+	fmt.Fprintf(&b, "Message-ID: %s\r\n", messageID)
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	b.WriteString("Content-Transfer-Encoding: quoted-printable\r\n")
+	b.WriteString("\r\n")
+
+	body := RenderPrompt(req) + "\nWrite your answer at the top of the reply; the quoted question is ignored.\n"
+	qp := quotedprintable.NewWriter(&b)
+	if _, err := qp.Write([]byte(strings.ReplaceAll(body, "\n", "\r\n"))); err != nil {
+		return nil, err
+	}
+	if err := qp.Close(); err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
+
+// sendSMTP delivers msg with STARTTLS, or implicit TLS on port 465, and
+// refuses to log in over a plain connection.
+func (p *EmailProvider) sendSMTP(ctx context.Context, msg []byte) error {
+	addr := net.JoinHostPort(p.smtpHost, strconv.Itoa(p.smtpPort))
+	conn, err := dialEmail(ctx, addr, p.smtpHost, p.smtpPort == 465)
+	if err != nil {
+		return fmt.Errorf("connect to smtp server %s: %w", addr, err)
+	}
+	c, err := smtp.NewClient(conn, p.smtpHost)
+	if err != nil {
+		_ = conn.Close()
+		return fmt.Errorf("smtp %s: %w", addr, err)
+	}
+	defer c.Close()
+
+	if _, isTLS := conn.(*tls.Conn); !isTLS {
+		if ok, _ := c.Extension("STARTTLS"); !ok {
+			return fmt.Errorf("smtp server %s does not offer STARTTLS", addr)
+		}
+		if err := c.StartTLS(&tls.Config{ServerName: p.smtpHost}); err != nil {
+			return fmt.Errorf("smtp starttls: %w", err)
+		}
+	}
+	if p.smtpUsername != "" {
+		if err := c.Auth(smtp.PlainAuth("", p.smtpUsername, p.smtpPassword, p.smtpHost)); err != nil {
+			return fmt.Errorf("smtp login as %s: %w", p.smtpUsername, err)
+		}
+	}
+	if err := c.Mail(p.from); err != nil {
+		return fmt.Errorf("smtp MAIL FROM %s: %w", p.from, err)
+	}
+	if err := c.Rcpt(p.to); err != nil {
+		return fmt.Errorf("smtp RCPT TO %s: %w", p.to, err)
+	}
+	w, err := c.Data()
+	if err != nil {
+		return fmt.Errorf("smtp DATA: %w", err)
+	}
+	if _, err := w.Write(msg); err != nil {
+		return fmt.Errorf("smtp DATA: %w", err)
+	}
+	if err := w.Close(); err != nil {
+		return fmt.Errorf("smtp DATA: %w", err)
+	}
+	return c.Quit()
+}
+
+// dialEmail connects to addr, over TLS when implicitTLS is set. The
+// connection's deadline follows ctx, capped by emailDialTimeout for each
+// step of the exchange.
+func dialEmail(ctx context.Context, addr, host string, implicitTLS bool) (net.Conn, error) {
+	dialer := &net.Dialer{Timeout: emailDialTimeout}
+	var conn net.Conn
+	var err error
+	if implicitTLS {
+		conn, err = (&tls.Dialer{NetDialer: dialer, Config: &tls.Config{ServerName: host}}).DialContext(ctx, "tcp", addr)
+	} else {
+		conn, err = dialer.DialContext(ctx, "tcp", addr)
+	}
+	if err != nil {
+		return nil, err
+	}
+	deadline := time.Now().Add(emailDialTimeout)
+	if d, ok := ctx.Deadline(); ok && d.Before(deadline) {
+		deadline = d
+	}
+	_ = conn.SetDeadline(deadline)
+	return conn, nil
+}
@@ -0,0 +1,182 @@
+package provider
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"net"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const emailMaxMessageSize = 25 << 20
+
+var imapLiteralPattern = regexp.MustCompile(`\{(\d+)\+?\}$`)
+
+// imapConn is just enough of an IMAP4rev1 client to log in, search one
+// mailbox and fetch whole messages.
+type imapConn struct {
+	conn net.Conn
+	r    *bufio.Reader
+	tag  int
+}
+
+// imapLine is one response line with the literals ({n} byte strings) it
+// carried.
+type imapLine struct {
+	Text     string
+	Literals [][]byte
+}
+
+// searchIMAP logs in, finds messages received since the question that name
+// the request ID in a threading header or the subject, and fetches them
+// without marking them read.
+func (p *EmailProvider) searchIMAP(ctx context.Context, requestID string, since time.Time) ([]emailFetched, error) {
+	addr := net.JoinHostPort(p.imapHost, strconv.Itoa(p.imapPort))
+	c, err := dialIMAP(ctx, addr, p.imapHost, p.imapPort != 143)
+	if err != nil {
+		return nil, fmt.Errorf("connect to imap server %s: %w", addr, err)
+	}
+	defer c.close()
+
+	if _, err := c.cmd("LOGIN %s %s", imapQuote(p.imapUsername), imapQuote(p.imapPassword)); err != nil {
+		return nil, err
+	}
+	if _, err := c.cmd("SELECT %s", imapQuote(p.mailbox)); err != nil {
+		return nil, err
+	}
+	// SINCE only has day precision, so look back a day to be safe across
+	// time zones; the headers are checked again after fetching.
+	key := imapQuote(requestID)
+	lines, err := c.cmd("UID SEARCH SINCE %s OR OR HEADER In-Reply-To %s HEADER References %s SUBJECT %s",
+		since.AddDate(0, 0, -1).Format("02-Jan-2006"), key, key, key)
+	if err != nil {
+		return nil, err
+	}
+	var uids []string
+	for _, line := range lines {
+		if rest, ok := strings.CutPrefix(line.Text, "* SEARCH"); ok {
+			uids = append(uids, strings.Fields(rest)...)
+		}
+	}
+	if len(uids) == 0 {
+		return nil, nil
+	}
+
+	lines, err = c.cmd("UID FETCH %s (BODY.PEEK[])", strings.Join(uids, ","))
+	if err != nil {
+		return nil, err
+	}
+	fetched := make([]emailFetched, 0, len(lines))
+	for _, line := range lines {
+		if !strings.HasPrefix(line.Text, "* ") || !strings.Contains(line.Text, "FETCH") || len(line.Literals) == 0 {
+			continue
+		}
+		fetched = append(fetched, emailFetched{Raw: line.Literals[0]})
+	}
+	return fetched, nil
+}
+
+// dialIMAP connects and reads the greeting. Without implicit TLS (port 143)
+// the connection is upgraded with STARTTLS before anything is sent.
+func dialIMAP(ctx context.Context, addr, host string, implicitTLS bool) (*imapConn, error) {
+	conn, err := dialEmail(ctx, addr, host, implicitTLS)
+	if err != nil {
+		return nil, err
+	}
+	c := &imapConn{conn: conn, r: bufio.NewReader(conn)}
+	greeting, err := c.readLine()
+	if err != nil {
+		_ = conn.Close()
+		return nil, err
+	}
+	if !strings.HasPrefix(greeting.Text, "* OK") {
+		_ = conn.Close()
+		return nil, fmt.Errorf("unexpected imap greeting %q", greeting.Text)
+	}
+	if !implicitTLS {
+		if _, err := c.cmd("STARTTLS"); err != nil {
+			_ = conn.Close()
+			return nil, err
+		}
+		tlsConn := tls.Client(conn, &tls.Config{ServerName: host})
+		c.conn = tlsConn
+		c.r = bufio.NewReader(tlsConn)
+	}
+	return c, nil
+}
+
+// cmd sends a tagged command and returns the untagged lines that came back
+// before its OK.
+func (c *imapConn) cmd(format string, args ...any) ([]imapLine, error) {
+	c.tag++
+	tag := "a" + strconv.Itoa(c.tag)
+	command := fmt.Sprintf(format, args...)
+	if _, err := fmt.Fprintf(c.conn, "%s %s\r\n", tag, command); err != nil {
+		return nil, err
+	}
+
+	verb := strings.SplitN(command, " ", 2)[0]
+	if verb == "UID" {
+		verb = strings.Join(strings.SplitN(command, " ", 3)[:2], " ")
+	}
+	var lines []imapLine
+	for {
+		line, err := c.readLine()
+		if err != nil {
+			return nil, err
+		}
+		status, ok := strings.CutPrefix(line.Text, tag+" ")
+		if !ok {
+			lines = append(lines, line)
+			continue
+		}
+		if strings.HasPrefix(status, "OK") {
+			return lines, nil
+		}
+		return nil, fmt.Errorf("imap %s failed: %s", verb, status)
+	}
+}
+
+// readLine reads one response line, pulling in any literals it announces.
+func (c *imapConn) readLine() (imapLine, error) {
+	var line imapLine
+	var text strings.Builder
+	for {
+		part, err := c.r.ReadString('\n')
+		if err != nil {
+			return imapLine{}, err
+		}
+		part = strings.TrimRight(part, "\r\n")
+		text.WriteString(part)
+		m := imapLiteralPattern.FindStringSubmatch(part)
+		if m == nil {
+			line.Text = text.String()
+			return line, nil
+		}
+		n, err := strconv.Atoi(m[1])
+		if err != nil || n > emailMaxMessageSize {
+			return imapLine{}, fmt.Errorf("imap literal of %s bytes is too large", m[1])
+		}
+		literal := make([]byte, n)
+		if _, err := io.ReadFull(c.r, literal); err != nil {
+			return imapLine{}, err
+		}
+		line.Literals = append(line.Literals, literal)
+	}
+}
+
+func (c *imapConn) close() {
+	_, _ = c.cmd("LOGOUT")
+	_ = c.conn.Close()
+}
+
+// imapQuote renders s as an IMAP quoted string.
+func imapQuote(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	return `"` + strings.ReplaceAll(s, `"`, `\"`) + `"`
+}
@@ -0,0 +1,192 @@
+package provider
+
+import (
+	"encoding/base64"
+	"html"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/mail"
+	"regexp"
+	"strings"
+)
+
+var (
+	emailHTMLDropPattern     = regexp.MustCompile(`(?is)<(?:style|script|head)\b.*?</(?:style|script|head)>`)
+	emailHTMLBreakPattern    = regexp.MustCompile(`(?i)<br\s*/?>|</(?:p|div|li|tr|h[1-6])>`)
+	emailHTMLTagPattern      = regexp.MustCompile(`(?s)<[^>]*>`)
+	emailQuoteHeaderPattern  = regexp.MustCompile(`(?i)^(?:on\s.*\swrote:|-+\s*original message\s*-+|_{10,}|sent from my\s.*)$`)
+	emailHTMLQuoteStartMarks = []string{`<blockquote`, `class="gmail_quote`, `id="divrplyfwdmsg"`, `id="appendonsend"`}
+)
+
+// emailMatchesRequest reports whether a message answers the question sent
+// as messageID: In-Reply-To or References name it or its request ID, or the
+// subject carries the request ID. The question itself never matches.
+func emailMatchesRequest(h mail.Header, requestID, messageID string) bool {
+	if strings.TrimSpace(h.Get("Message-Id")) == messageID {
+		return false
+	}
+	for _, key := range []string{"In-Reply-To", "References"} {
+		v := h.Get(key)
+		if strings.Contains(v, messageID) || strings.Contains(v, "consult-human."+requestID+".") {
+			return true
+		}
+	}
+	subject, err := new(mime.WordDecoder).DecodeHeader(h.Get("Subject"))
+	if err != nil {
+		subject = h.Get("Subject")
+	}
+	return strings.Contains(subject, requestID)
+}
+
+// emailBodyText returns the message's text/plain part, or the text of its
+// text/html part when it has no plain one. Attachments are skipped.
+func emailBodyText(msg *mail.Message) (string, error) {
+	plain, htmlText, err := emailParts(msg.Header.Get("Content-Type"), msg.Header.Get("Content-Transfer-Encoding"), msg.Body)
+	if err != nil {
+		return "", err
+	}
+	if strings.TrimSpace(plain) != "" {
+		return normalizeEmailText(plain), nil
+	}
+	return emailHTMLText(htmlText), nil
+}
+
+func emailParts(contentType, encoding string, body io.Reader) (plain, htmlText string, err error) {
+	mediaType, params, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		// A missing or broken Content-Type means plain text.
+		mediaType, params = "text/plain", nil
+	}
+	if strings.HasPrefix(mediaType, "multipart/") {
+		r := multipart.NewReader(body, params["boundary"])
+		for {
+			part, err := r.NextPart()
+			if err == io.EOF {
+				return plain, htmlText, nil
+			}
+			if err != nil {
+				return "", "", err
+			}
+			if disposition, _, _ := mime.ParseMediaType(part.Header.Get("Content-Disposition")); disposition == "attachment" {
+				continue
+			}
+			p, h, err := emailParts(part.Header.Get("Content-Type"), part.Header.Get("Content-Transfer-Encoding"), part)
+			if err != nil {
+				return "", "", err
+			}
+			if plain == "" {
+				plain = p
+			}
+			if htmlText == "" {
+				htmlText = h
+			}
+		}
+	}
+	if mediaType != "text/plain" && mediaType != "text/html" {
+		return "", "", nil
+	}
+
+	switch strings.ToLower(strings.TrimSpace(encoding)) {
+	case "quoted-printable":
+		body = quotedprintable.NewReader(body)
+	case "base64":
+		body = base64.NewDecoder(base64.StdEncoding, body)
+	}
+	b, err := io.ReadAll(body)
+	if err != nil {
+		return "", "", err
+	}
+	text := emailDecodeCharset(b, params["charset"])
+	if mediaType == "text/html" {
+		return "", text, nil
+	}
+	return text, "", nil
+}
+
+// emailDecodeCharset handles the Latin-1 family besides UTF-8; anything else
+// is passed through as is.
+func emailDecodeCharset(b []byte, charset string) string {
+	switch strings.ToLower(strings.TrimSpace(charset)) {
+	case "iso-8859-1", "latin1", "windows-1252", "cp1252":
+		runes := make([]rune, len(b))
+		for i, c := range b {
+			runes[i] = rune(c)
+		}
+		return string(runes)
+	default:
+		return string(b)
+	}
+}
+
+// emailHTMLText is a basic HTML to text conversion for HTML-only replies.
+// The quoted original, which mail clients put in a blockquote or a marked
+// div, is cut off first.
+func emailHTMLText(s string) string {
+	lower := strings.ToLower(s)
+	for _, mark := range emailHTMLQuoteStartMarks {
+		idx := strings.Index(lower, mark)
+		if idx < 0 {
+			continue
+		}
+		if start := strings.LastIndex(lower[:idx+1], "<"); start >= 0 {
+			s, lower = s[:start], lower[:start]
+		}
+	}
+	s = emailHTMLDropPattern.ReplaceAllString(s, "")
+	s = emailHTMLBreakPattern.ReplaceAllString(s, "\n")
+	s = emailHTMLTagPattern.ReplaceAllString(s, "")
+	s = strings.ReplaceAll(html.UnescapeString(s), "\u00a0", " ")
+	return normalizeEmailText(s)
+}
+
+func normalizeEmailText(s string) string {
+	s = strings.ReplaceAll(s, "\r\n", "\n")
+	lines := strings.Split(s, "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimRight(line, " \t\r")
+	}
+	return strings.TrimSpace(strings.Join(lines, "\n"))
+}
+
+// stripEmailQuote keeps the top-posted text of a reply: everything before
+// the quoted original, its "On ... wrote:" header or the signature. A
+// bottom-posted reply keeps its unquoted lines instead.
+func stripEmailQuote(text string) string {
+	lines := strings.Split(normalizeEmailText(text), "\n")
+	var top []string
+	for i, line := range lines {
+		if isEmailQuoteStart(lines, i) {
+			break
+		}
+		top = append(top, line)
+	}
+	if out := strings.TrimSpace(strings.Join(top, "\n")); out != "" {
+		return out
+	}
+
+	var rest []string
+	for i, line := range lines {
+		if line == "--" {
+			break
+		}
+		if isEmailQuoteStart(lines, i) {
+			continue
+		}
+		rest = append(rest, line)
+	}
+	return strings.TrimSpace(strings.Join(rest, "\n"))
+}
+
+func isEmailQuoteStart(lines []string, i int) bool {
+	line := strings.TrimSpace(lines[i])
+	if strings.HasPrefix(line, ">") || lines[i] == "--" || emailQuoteHeaderPattern.MatchString(line) {
+		return true
+	}
+	// Clients wrap long "On <date>, <name> wrote:" lines.
+	if strings.HasPrefix(strings.ToLower(line), "on ") && i+1 < len(lines) {
+		return emailQuoteHeaderPattern.MatchString(line + " " + strings.TrimSpace(lines[i+1]))
+	}
+	return false
+}
@@ -0,0 +1,209 @@
+package provider
+
+import (
+	"bytes"
+	"context"
+	"net/mail"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/AlhasanIQ/consult-human/config"
+	"github.com/AlhasanIQ/consult-human/contract"
+)
+
+const emailTestMessageID = "<consult-human.4f2a9c1e.0a1b2c3d4e5f@example.com>"
+
+// A Gmail reply: multipart/alternative, quoted-printable, with a wrapped
+// "On ... wrote:" line above the quoted question.
+var emailGmailReply = strings.ReplaceAll(`From: Human <human@example.org>
+To: agent@example.com
+Subject: Re: [consult-human 4f2a9c1e] Deploy to production?
+Date: Mon, 12 Oct 2026 10:15:00 +0200
+Message-ID: <CAF=reply1@mail.gmail.com>
+In-Reply-To: <consult-human.4f2a9c1e.0a1b2c3d4e5f@example.com>
+References: <consult-human.4f2a9c1e.0a1b2c3d4e5f@example.com>
+MIME-Version: 1.0
+Content-Type: multipart/alternative; boundary="000000000000abcd"
+
+--000000000000abcd
+Content-Type: text/plain; charset="UTF-8"
+Content-Transfer-Encoding: quoted-printable
+
+Yes, ship it =E2=80=94 but only after the migration.
+
+On Mon, Oct 12, 2026 at 10:02 AM consult-human <agent@example.com>
+wrote:
+
+> consult-human request
+> Request ID: 4f2a9c1e
+>
+> Deploy to production?
+
+--000000000000abcd
+Content-Type: text/html; charset="UTF-8"
+
+<div dir=3D"ltr">Yes, ship it</div>
+--000000000000abcd--
+`, "\n", "\r\n")
+
+// An Outlook reply that dropped the threading headers, sent as base64 HTML
+// only: "<p>Use <b>eu-west-1</b>&nbsp;please</p><div id="divRplyFwdMsg">From: consult-human</div>".
+var emailOutlookReply = strings.ReplaceAll(`From: "Human" <human@example.org>
+To: agent@example.com
+Subject: =?utf-8?Q?RE:_[consult-human_4f2a9c1e]_Which_region=3F?=
+Date: Mon, 12 Oct 2026 08:20:00 +0000
+Message-ID: <AM0PR01@outlook.com>
+MIME-Version: 1.0
+Content-Type: text/html; charset="us-ascii"
+Content-Transfer-Encoding: base64
+
+PHA+VXNlIDxiPmV1LXdlc3QtMTwvYj4mbmJzcDtwbGVhc2U8L3A+PGRpdiBpZD0iZGl2UnBseUZ3
+ZE1zZyI+RnJvbTogY29uc3VsdC1odW1hbjwvZGl2Pg==
+`, "\n", "\r\n")
+
+func TestEmailMatchesRequest(t *testing.T) {
+	header := func(raw string) mail.Header {
+		t.Helper()
+		msg, err := mail.ReadMessage(strings.NewReader(raw + "\r\n"))
+		if err != nil {
+			t.Fatalf("parse header: %v", err)
+		}
+		return msg.Header
+	}
+
+	cases := []struct {
+		name string
+		raw  string
+		want bool
+	}{
+		{"in-reply-to", "In-Reply-To: " + emailTestMessageID + "\r\nSubject: Re: hello\r\n", true},
+		{"references chain", "References: <a@x> <consult-human.4f2a9c1e.ffffffffffff@example.com>\r\nSubject: Re: hello\r\n", true},
+		{"encoded subject", "Subject: =?utf-8?Q?AW:_[consult-human_4f2a9c1e]_Deploy=3F?=\r\n", true},
+		{"other request", "In-Reply-To: <consult-human.77777777.0a1b2c3d4e5f@example.com>\r\nSubject: Re: [consult-human 77777777] Deploy?\r\n", false},
+		{"the question itself", "Message-ID: " + emailTestMessageID + "\r\nSubject: [consult-human 4f2a9c1e] Deploy?\r\n", false},
+	}
+	for _, tc := range cases {
+		if got := emailMatchesRequest(header(tc.raw), "4f2a9c1e", emailTestMessageID); got != tc.want {
+			t.Fatalf("%s: expected %v, got %v", tc.name, tc.want, got)
+		}
+	}
+}
+
+func TestEmailReplyForGmailStripsQuotedQuestion(t *testing.T) {
+	reply, ok, err := emailReplyFor([]byte(emailGmailReply), "4f2a9c1e", emailTestMessageID)
+	if err != nil || !ok {
+		t.Fatalf("expected a match, got ok=%v err=%v", ok, err)
+	}
+	if reply.Text != "Yes, ship it — but only after the migration." {
+		t.Fatalf("unexpected reply text %q", reply.Text)
+	}
+	if reply.From != "human@example.org" || reply.ProviderMessageID != "<CAF=reply1@mail.gmail.com>" {
+		t.Fatalf("unexpected reply metadata: %#v", reply)
+	}
+	if !reply.ReceivedAt.Equal(time.Date(2026, 10, 12, 8, 15, 0, 0, time.UTC)) {
+		t.Fatalf("expected the Date header as ReceivedAt, got %s", reply.ReceivedAt)
+	}
+	if !strings.Contains(reply.Raw, "> Deploy to production?") {
+		t.Fatalf("expected Raw to keep the full body, got %q", reply.Raw)
+	}
+}
+
+func TestEmailReplyForHTMLOnlyReplyMatchedBySubject(t *testing.T) {
+	reply, ok, err := emailReplyFor([]byte(emailOutlookReply), "4f2a9c1e", emailTestMessageID)
+	if err != nil || !ok {
+		t.Fatalf("expected a match, got ok=%v err=%v", ok, err)
+	}
+	if reply.Text != "Use eu-west-1 please" {
+		t.Fatalf("unexpected reply text %q", reply.Text)
+	}
+}
+
+func TestStripEmailQuote(t *testing.T) {
+	cases := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{"outlook separator", "Approved.\n\n________________________________\nFrom: consult-human\nSent: Monday\n\nDeploy?", "Approved."},
+		{"original message", "No.\r\n-----Original Message-----\r\nDeploy?", "No."},
+		{"signature", "Option b\n\n-- \nJane Doe\nCTO", "Option b"},
+		{"mobile footer", "ok\n\nSent from my iPhone", "ok"},
+		{"bottom posted", "On Mon, Oct 12, 2026 at 10:02 AM consult-human wrote:\n> Deploy?\n\nYes, go ahead.\n\n-- \nJane", "Yes, go ahead."},
+		{"quote only", "> Deploy?", ""},
+	}
+	for _, tc := range cases {
+		if got := stripEmailQuote(tc.in); got != tc.want {
+			t.Fatalf("%s: expected %q, got %q", tc.name, tc.want, got)
+		}
+	}
+}
+
+func TestEmailSendAndReceive(t *testing.T) {
+	var sent []byte
+	p := &EmailProvider{
+		from:         "agent@example.com",
+		to:           "human@example.org",
+		pollInterval: 5 * time.Millisecond,
+		pending:      make(map[string]emailThread),
+		sendMail: func(_ context.Context, msg []byte) error {
+			sent = msg
+			return nil
+		},
+	}
+	polls := 0
+	p.search = func(_ context.Context, requestID string, _ time.Time) ([]emailFetched, error) {
+		polls++
+		if polls == 1 {
+			return nil, nil
+		}
+		question := "Message-ID: " + p.pending[requestID].MessageID + "\r\nSubject: [consult-human req-1] Deploy?\r\n\r\nDeploy?\r\n"
+		other := "In-Reply-To: <consult-human.req-2.000000000000@example.com>\r\n\r\nno\r\n"
+		answer := "From: human@example.org\r\nIn-Reply-To: " + p.pending[requestID].MessageID + "\r\n\r\nyes\r\n\r\n> Deploy?\r\n"
+		return []emailFetched{{Raw: []byte(question)}, {Raw: []byte(other)}, {Raw: []byte(answer)}}, nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	messageID, err := p.Send(ctx, contract.AskRequest{RequestID: "req-1", Question: "Deploy?"})
+	if err != nil {
+		t.Fatalf("Send returned error: %v", err)
+	}
+	if !strings.HasPrefix(messageID, "<consult-human.req-1.") || !strings.HasSuffix(messageID, "@example.com>") {
+		t.Fatalf("expected the request ID in the Message-ID, got %q", messageID)
+	}
+	msg, err := mail.ReadMessage(bytes.NewReader(sent))
+	if err != nil {
+		t.Fatalf("parse sent message: %v", err)
+	}
+	if got := msg.Header.Get("Subject"); got != "[consult-human req-1] Deploy?" {
+		t.Fatalf("unexpected subject %q", got)
+	}
+	if msg.Header.Get("Message-Id") != messageID || msg.Header.Get("To") != "human@example.org" {
+		t.Fatalf("unexpected headers: %#v", msg.Header)
+	}
+
+	reply, err := p.Receive(ctx, "req-1")
+	if err != nil {
+		t.Fatalf("Receive returned error: %v", err)
+	}
+	if reply.Text != "yes" || reply.RequestID != "req-1" || polls != 2 {
+		t.Fatalf("unexpected reply after %d polls: %#v", polls, reply)
+	}
+}
+
+func TestFactoryUsesEmail(t *testing.T) {
+	cfg := config.Default()
+	if _, err := New(cfg, "email"); err == nil || !strings.Contains(err.Error(), "email.smtp_host, email.imap_host, email.from and email.to are required") {
+		t.Fatalf("expected a missing config error, got %v", err)
+	}
+	cfg.Email = config.EmailConfig{SMTPHost: "smtp.example.com", IMAPHost: "imap.example.com", From: "agent@example.com", To: "human@example.org", SMTPUsername: "agent", SMTPPassword: "secret"}
+	p, err := New(cfg, "email")
+	if err != nil {
+		t.Fatalf("New returned error: %v", err)
+	}
+	email := p.(*EmailProvider)
+	if email.smtpPort != 587 || email.imapPort != 993 || email.mailbox != "INBOX" || email.imapUsername != "agent" || email.imapPassword != "secret" {
+		t.Fatalf("unexpected defaults: %#v", email)
+	}
+}
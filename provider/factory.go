@@ -1,12 +1,17 @@
 package provider
 
 import (
+	"errors"
 	"fmt"
+	"os"
 	"strings"
 
 	"github.com/AlhasanIQ/consult-human/config"
 )
 
+// ErrProviderDisabled marks a known provider that cannot be used right now.
+var ErrProviderDisabled = errors.New("provider is temporarily disabled")
+
 func New(cfg config.Config, override string) (Provider, error) {
 	config.ApplyDefaults(&cfg)
 	name := strings.ToLower(strings.TrimSpace(override))
@@ -17,8 +22,29 @@ func New(cfg config.Config, override string) (Provider, error) {
 	switch name {
 	case "telegram":
 		return NewTelegram(cfg)
+	case "slack":
+		return NewSlack(cfg)
+	case "signal":
+		return NewSignal(cfg)
+	case "email":
+		return NewEmail(cfg)
+	case "ntfy":
+		return NewNtfy(cfg)
+	case "webhook":
+		return NewWebhook(cfg)
+	case "desktop":
+		return NewDesktop(cfg)
+	case "zulip":
+		return NewZulip(cfg)
+	case "pushover":
+		return NewPushover(cfg)
+	case "imessage":
+		// Only macOS has Messages; elsewhere newIMessage says so.
+		return newIMessage(cfg)
+	case "console":
+		return NewConsole(os.Stdin, os.Stderr), nil
 	case "whatsapp":
-		return nil, fmt.Errorf("whatsapp provider is temporarily disabled")
+		return nil, fmt.Errorf("whatsapp %w", ErrProviderDisabled)
 	default:
 		return nil, fmt.Errorf("unknown provider %q", name)
 	}
@@ -0,0 +1,340 @@
+//go:build darwin
+
+package provider
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/AlhasanIQ/consult-human/config"
+	"github.com/AlhasanIQ/consult-human/contract"
+)
+
+const imessageDefaultPollInterval = 2 * time.Second
+
+// imessageQuestionMarker starts every question RenderPrompt produces. When
+// the handle is the Mac's own number, Messages also records each question as
+// received, so incoming messages starting with it are not answers.
+const imessageQuestionMarker = "consult-human request"
+
+// imessageFullDiskAccessHint is added when the Messages database cannot be
+// opened, which is almost always missing Full Disk Access.
+const imessageFullDiskAccessHint = "grant Full Disk Access to the app running consult-human (System Settings > Privacy & Security > Full Disk Access, e.g. Terminal or iTerm), then restart that app"
+
+// imessageAutomationHint is added when osascript may not control Messages.
+const imessageAutomationHint = "allow the app running consult-human to control Messages (System Settings > Privacy & Security > Automation), and make sure Messages is signed in to iMessage"
+
+// imessageSendScript sends its second argument to the iMessage handle given
+// as the first. Passing both as arguments avoids quoting them in AppleScript.
+const imessageSendScript = `on run {targetHandle, targetMessage}
+	tell application "Messages"
+		set targetService to 1st account whose service type = iMessage
+		set targetBuddy to participant targetHandle of targetService
+		send targetMessage to targetBuddy
+	end tell
+end run`
+
+// imessageEpoch is the zero of the dates in the Messages database.
+var imessageEpoch = time.Date(2001, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// IMessageProvider sends questions through the Messages app and polls its
+// database, read-only, for the answers. Messages records no reply threading
+// that can be read reliably, so an answer names the request ID or is the
+// first message from the handle while its question is the latest one sent.
+type IMessageProvider struct {
+	handle       string
+	dbPath       string
+	pollInterval time.Duration
+	run          func(ctx context.Context, name string, args ...string) ([]byte, error)
+
+	mu      sync.Mutex
+	pending map[string]imessageQuestion
+}
+
+// imessageQuestion is where a question landed in the Messages database:
+// answers have a higher ROWID and a later date.
+type imessageQuestion struct {
+	AfterRowID int64
+	SentAt     time.Time
+}
+
+// imessageRow is one message of the conversation with the handle.
+type imessageRow struct {
+	RowID          int64  `json:"rowid"`
+	Date           int64  `json:"date"`
+	IsFromMe       int    `json:"is_from_me"`
+	Text           string `json:"text"`
+	AttributedBody string `json:"body"`
+}
+
+func NewIMessage(cfg config.Config) (*IMessageProvider, error) {
+	handle := strings.TrimSpace(cfg.IMessage.Handle)
+	if handle == "" {
+		return nil, fmt.Errorf(
+			"imessage.handle is required.\n" +
+				"First-time iMessage setup:\n" +
+				"1) Sign in to iMessage in the Messages app on this Mac\n" +
+				"2) Grant Full Disk Access to your terminal app so replies can be read\n" +
+				"3) Run: `consult-human setup --provider imessage`",
+		)
+	}
+	dbPath, err := config.EffectiveIMessageDBPath(cfg)
+	if err != nil {
+		return nil, err
+	}
+	pollInterval := imessageDefaultPollInterval
+	if cfg.IMessage.PollIntervalSeconds > 0 {
+		pollInterval = time.Duration(cfg.IMessage.PollIntervalSeconds) * time.Second
+	}
+	return &IMessageProvider{
+		handle:       handle,
+		dbPath:       dbPath,
+		pollInterval: pollInterval,
+		run:          runIMessageCommand,
+		pending:      make(map[string]imessageQuestion),
+	}, nil
+}
+
+func newIMessage(cfg config.Config) (Provider, error) {
+	return NewIMessage(cfg)
+}
+
+func (p *IMessageProvider) Name() string { return "imessage" }
+
+func (p *IMessageProvider) Close() error { return nil }
+
+// Send notes where the database ends before sending, which also makes sure
+// the answer can be read before the human is asked anything.
+func (p *IMessageProvider) Send(ctx context.Context, req contract.AskRequest) (string, error) {
+	afterRowID, err := p.lastRowID(ctx)
+	if err != nil {
+		return "", err
+	}
+	sentAt := time.Now().UTC()
+	if err := sendIMessage(ctx, p.run, p.handle, RenderPrompt(req)); err != nil {
+		return "", err
+	}
+	p.mu.Lock()
+	p.pending[req.RequestID] = imessageQuestion{AfterRowID: afterRowID, SentAt: sentAt}
+	p.mu.Unlock()
+	return req.RequestID, nil
+}
+
+func (p *IMessageProvider) Receive(ctx context.Context, requestID string) (contract.Reply, error) {
+	p.mu.Lock()
+	question, ok := p.pending[requestID]
+	p.mu.Unlock()
+	if !ok {
+		return contract.Reply{}, fmt.Errorf("unknown request id %q", requestID)
+	}
+	defer func() {
+		p.mu.Lock()
+		delete(p.pending, requestID)
+		p.mu.Unlock()
+	}()
+
+	anchor := question.AfterRowID
+	laterQuestion := false
+	for {
+		rows, err := p.rowsAfter(ctx, anchor)
+		if err != nil {
+			if ctx.Err() != nil {
+				return contract.Reply{}, ctx.Err()
+			}
+			return contract.Reply{}, err
+		}
+		for _, row := range rows {
+			anchor = row.RowID
+			text := strings.TrimSpace(row.Text)
+			if text == "" {
+				text = strings.TrimSpace(decodeIMessageAttributedBody(row.AttributedBody))
+			}
+			receivedAt := imessageTime(row.Date)
+			if strings.HasPrefix(text, imessageQuestionMarker) {
+				if _, ours := stripRequestIDToken(text, requestID); row.IsFromMe == 1 && !ours {
+					laterQuestion = true
+				}
+				continue
+			}
+			if row.IsFromMe == 1 || text == "" || receivedAt.Before(question.SentAt) {
+				continue
+			}
+			answer, ok := imessageAnswerText(text, requestID, laterQuestion)
+			if !ok {
+				continue
+			}
+			return contract.Reply{
+				RequestID:         requestID,
+				Text:              answer,
+				Raw:               text,
+				From:              p.handle,
+				ProviderMessageID: strconv.FormatInt(row.RowID, 10),
+				ReceivedAt:        receivedAt,
+			}, nil
+		}
+
+		timer := time.NewTimer(p.pollInterval)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return contract.Reply{}, ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// imessageAnswerText decides whether a message from the handle answers the
+// question: it names the request ID, or no other question went out since.
+func imessageAnswerText(text, requestID string, laterQuestion bool) (string, bool) {
+	if stripped, ok := stripRequestIDToken(text, requestID); ok {
+		stripped = strings.TrimSpace(stripped)
+		return stripped, stripped != ""
+	}
+	return text, !laterQuestion
+}
+
+func (p *IMessageProvider) lastRowID(ctx context.Context) (int64, error) {
+	var rows []struct {
+		RowID int64 `json:"rowid"`
+	}
+	if err := p.query(ctx, "SELECT COALESCE(MAX(ROWID), 0) AS rowid FROM message;", &rows); err != nil {
+		return 0, err
+	}
+	if len(rows) == 0 {
+		return 0, nil
+	}
+	return rows[0].RowID, nil
+}
+
+// rowsAfter lists the one-to-one messages with the handle after rowID,
+// oldest first. Group chats the handle is in are left out.
+func (p *IMessageProvider) rowsAfter(ctx context.Context, rowID int64) ([]imessageRow, error) {
+	query := fmt.Sprintf(`SELECT m.ROWID AS rowid, m.date AS date, m.is_from_me AS is_from_me,
+	COALESCE(m.text, '') AS text, COALESCE(hex(m.attributedBody), '') AS body
+FROM message m JOIN handle h ON h.ROWID = m.handle_id
+WHERE m.ROWID > %d AND h.id = %s COLLATE NOCASE AND COALESCE(m.cache_roomnames, '') = ''
+ORDER BY m.ROWID;`, rowID, sqlQuote(p.handle))
+	var rows []imessageRow
+	if err := p.query(ctx, query, &rows); err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
+
+// query runs a read-only query through the sqlite3 tool that ships with
+// macOS and decodes its JSON output.
+func (p *IMessageProvider) query(ctx context.Context, query string, out any) error {
+	raw, err := p.run(ctx, "sqlite3", "-readonly", "-json", p.dbPath, query)
+	if err != nil {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		msg := strings.TrimSpace(string(raw))
+		if strings.Contains(msg, "unable to open") || strings.Contains(msg, "authorization denied") || strings.Contains(msg, "not permitted") {
+			return fmt.Errorf("cannot read the Messages database at %s: %s; %s", p.dbPath, msg, imessageFullDiskAccessHint)
+		}
+		return fmt.Errorf("query the Messages database at %s: %w: %s", p.dbPath, err, msg)
+	}
+	if len(bytes.TrimSpace(raw)) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(raw, out); err != nil {
+		return fmt.Errorf("decode the Messages database output: %w", err)
+	}
+	return nil
+}
+
+// SendIMessage sends text to handle through the Messages app.
+func SendIMessage(ctx context.Context, handle, text string) error {
+	return sendIMessage(ctx, runIMessageCommand, handle, text)
+}
+
+// CheckIMessageAccess reports whether the Messages database at dbPath can be
+// read, with the Full Disk Access guidance when it cannot.
+func CheckIMessageAccess(ctx context.Context, dbPath string) error {
+	p := &IMessageProvider{dbPath: dbPath, run: runIMessageCommand}
+	_, err := p.lastRowID(ctx)
+	return err
+}
+
+func sendIMessage(ctx context.Context, run func(context.Context, string, ...string) ([]byte, error), handle, text string) error {
+	out, err := run(ctx, "osascript", "-e", imessageSendScript, handle, text)
+	if err != nil {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		msg := strings.TrimSpace(string(out))
+		if strings.Contains(msg, "-1743") || strings.Contains(msg, "Not authorized") {
+			return fmt.Errorf("could not send the iMessage: %s; %s", msg, imessageAutomationHint)
+		}
+		return fmt.Errorf("could not send the iMessage to %s: %w: %s", handle, err, msg)
+	}
+	return nil
+}
+
+func runIMessageCommand(ctx context.Context, name string, args ...string) ([]byte, error) {
+	cmd := exec.CommandContext(ctx, name, args...)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			return stderr.Bytes(), err
+		}
+		return nil, err
+	}
+	return stdout.Bytes(), nil
+}
+
+// imessageTime converts a Messages database date: nanoseconds since 2001 on
+// current macOS, seconds on versions before High Sierra.
+func imessageTime(date int64) time.Time {
+	if date > 1e12 || date < -1e12 {
+		return imessageEpoch.Add(time.Duration(date)).UTC()
+	}
+	return imessageEpoch.Add(time.Duration(date) * time.Second).UTC()
+}
+
+// decodeIMessageAttributedBody pulls the plain text out of the hex-encoded
+// typedstream Messages stores when the text column is empty, as it is for
+// most messages since macOS Ventura. The text follows the NSString class
+// name, after a fixed preamble, with a one-byte length or 0x81 and a
+// two-byte little-endian length.
+func decodeIMessageAttributedBody(hexBody string) string {
+	body, err := hex.DecodeString(hexBody)
+	if err != nil {
+		return ""
+	}
+	_, rest, ok := bytes.Cut(body, []byte("NSString"))
+	if !ok || len(rest) < 6 {
+		return ""
+	}
+	rest = rest[5:]
+	n := int(rest[0])
+	rest = rest[1:]
+	if n == 0x81 {
+		if len(rest) < 2 {
+			return ""
+		}
+		n = int(rest[0]) | int(rest[1])<<8
+		rest = rest[2:]
+	}
+	if n > len(rest) {
+		return ""
+	}
+	return string(rest[:n])
+}
+
+func sqlQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
@@ -0,0 +1,140 @@
+//go:build darwin
+
+package provider
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/AlhasanIQ/consult-human/config"
+	"github.com/AlhasanIQ/consult-human/contract"
+)
+
+// fakeMessages stands in for osascript and sqlite3: sends are recorded and
+// queries after the first return rows.
+type fakeMessages struct {
+	sent    []string
+	lastRow int64
+	rows    []imessageRow
+	openErr string
+}
+
+func (f *fakeMessages) run(ctx context.Context, name string, args ...string) ([]byte, error) {
+	switch name {
+	case "osascript":
+		f.sent = append(f.sent, args[len(args)-1])
+		return nil, nil
+	case "sqlite3":
+		if f.openErr != "" {
+			return []byte(f.openErr), errors.New("exit status 1")
+		}
+		query := args[len(args)-1]
+		if strings.Contains(query, "MAX(ROWID)") {
+			return json.Marshal([]map[string]int64{{"rowid": f.lastRow}})
+		}
+		rows := f.rows
+		f.rows = nil
+		return json.Marshal(rows)
+	}
+	return nil, errors.New("unexpected command " + name)
+}
+
+func newTestIMessage(t *testing.T, fake *fakeMessages) *IMessageProvider {
+	t.Helper()
+	cfg := config.Default()
+	cfg.IMessage = config.IMessageConfig{Handle: "+15551234567", DBPath: "/tmp/chat.db"}
+	p, err := NewIMessage(cfg)
+	if err != nil {
+		t.Fatalf("NewIMessage: %v", err)
+	}
+	p.run = fake.run
+	p.pollInterval = 10 * time.Millisecond
+	return p
+}
+
+func imessageDate(t time.Time) int64 {
+	return int64(t.Sub(imessageEpoch))
+}
+
+func TestIMessageSendAndReceiveSinglePending(t *testing.T) {
+	fake := &fakeMessages{lastRow: 41}
+	p := newTestIMessage(t, fake)
+	req := contract.AskRequest{RequestID: "req-1", Question: "Ship it?", Type: contract.QuestionTypeOpen}
+	if _, err := p.Send(context.Background(), req); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+	if len(fake.sent) != 1 || !strings.Contains(fake.sent[0], "Ship it?") {
+		t.Fatalf("unexpected sends: %#v", fake.sent)
+	}
+
+	now := imessageDate(time.Now().Add(time.Second))
+	fake.rows = []imessageRow{
+		{RowID: 42, Date: now, IsFromMe: 1, Text: fake.sent[0]},
+		{RowID: 43, Date: now, Text: fake.sent[0]},
+		{RowID: 44, Date: now, Text: "yes, ship it"},
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	reply, err := p.Receive(ctx, "req-1")
+	if err != nil {
+		t.Fatalf("Receive: %v", err)
+	}
+	if reply.Text != "yes, ship it" || reply.ProviderMessageID != "44" || reply.From != "+15551234567" {
+		t.Fatalf("unexpected reply: %#v", reply)
+	}
+}
+
+func TestIMessageLaterQuestionNeedsRequestID(t *testing.T) {
+	fake := &fakeMessages{lastRow: 10}
+	p := newTestIMessage(t, fake)
+	if _, err := p.Send(context.Background(), contract.AskRequest{RequestID: "req-a", Question: "First?"}); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+
+	now := imessageDate(time.Now().Add(time.Second))
+	other := RenderPrompt(contract.AskRequest{RequestID: "req-b", Question: "Second?"})
+	fake.rows = []imessageRow{
+		{RowID: 11, Date: now, IsFromMe: 1, Text: fake.sent[0]},
+		{RowID: 12, Date: now, IsFromMe: 1, Text: other},
+		{RowID: 13, Date: now, Text: "sure"},
+		{RowID: 14, Date: now, Text: "req-a go ahead"},
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	reply, err := p.Receive(ctx, "req-a")
+	if err != nil {
+		t.Fatalf("Receive: %v", err)
+	}
+	if reply.Text != "go ahead" || reply.ProviderMessageID != "14" {
+		t.Fatalf("unexpected reply: %#v", reply)
+	}
+}
+
+func TestIMessageFullDiskAccessGuidance(t *testing.T) {
+	fake := &fakeMessages{openErr: "Error: unable to open database \"/tmp/chat.db\": authorization denied"}
+	p := newTestIMessage(t, fake)
+	_, err := p.Send(context.Background(), contract.AskRequest{RequestID: "req-1", Question: "Hi?"})
+	if err == nil || !strings.Contains(err.Error(), "Full Disk Access") {
+		t.Fatalf("expected Full Disk Access guidance, got %v", err)
+	}
+	if len(fake.sent) != 0 {
+		t.Fatalf("expected nothing to be sent without database access, got %#v", fake.sent)
+	}
+}
+
+func TestDecodeIMessageAttributedBody(t *testing.T) {
+	body := append([]byte("streamtyped\x81\xe8\x03\x84\x01@\x84\x84\x84\x12NSAttributedString\x00\x84\x84\x08NSObject\x00\x85\x92\x84\x84\x84\x08NSString"), []byte("\x01\x94\x84\x01+\x05hello\x86")...)
+	if got := decodeIMessageAttributedBody(hex.EncodeToString(body)); got != "hello" {
+		t.Fatalf("expected hello, got %q", got)
+	}
+	long := strings.Repeat("a", 300)
+	body = append([]byte("NSString\x01\x94\x84\x01+\x81\x2c\x01"), []byte(long+"\x86")...)
+	if got := decodeIMessageAttributedBody(hex.EncodeToString(body)); got != long {
+		t.Fatalf("expected the long text, got %d bytes", len(got))
+	}
+}
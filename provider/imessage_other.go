@@ -0,0 +1,29 @@
+//go:build !darwin
+
+package provider
+
+import (
+	"context"
+	"errors"
+
+	"github.com/AlhasanIQ/consult-human/config"
+)
+
+// errIMessageUnsupported is returned everywhere but macOS, where the Messages
+// app and its database live.
+var errIMessageUnsupported = errors.New("the imessage provider only runs on macOS")
+
+func newIMessage(cfg config.Config) (Provider, error) {
+	return nil, errIMessageUnsupported
+}
+
+// SendIMessage sends text to handle through the Messages app.
+func SendIMessage(ctx context.Context, handle, text string) error {
+	return errIMessageUnsupported
+}
+
+// CheckIMessageAccess reports whether the Messages database at dbPath can be
+// read, with the Full Disk Access guidance when it cannot.
+func CheckIMessageAccess(ctx context.Context, dbPath string) error {
+	return errIMessageUnsupported
+}
@@ -0,0 +1,343 @@
+package provider
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/AlhasanIQ/consult-human/config"
+	"github.com/AlhasanIQ/consult-human/contract"
+)
+
+const (
+	ntfyPublishTimeout  = 30 * time.Second
+	ntfyReconnectDelay  = 3 * time.Second
+	ntfyMaxActions      = 3
+	ntfyActionLabelMax  = 40
+	ntfyMaxMessageBytes = 1 << 20
+)
+
+// NtfyProvider publishes questions to an ntfy topic and reads answers from
+// the JSON stream of "<topic>-replies". Answers name the request ID in their
+// title or body; with a single question waiting, any answer counts.
+type NtfyProvider struct {
+	server     string
+	topic      string
+	token      string
+	httpClient *http.Client
+	// streamClient has no overall timeout; the subscription stays open
+	// until the context ends.
+	streamClient   *http.Client
+	reconnectDelay time.Duration
+	store          *ntfyStore
+
+	mu     sync.Mutex
+	sentAt map[string]time.Time
+}
+
+// ntfyEvent is one line of GET /<topic>/json.
+type ntfyEvent struct {
+	ID      string `json:"id"`
+	Time    int64  `json:"time"`
+	Event   string `json:"event"`
+	Title   string `json:"title"`
+	Message string `json:"message"`
+}
+
+// ntfyAPIError is a non-200 answer from the ntfy server.
+type ntfyAPIError struct {
+	Action string
+	Status int
+	Msg    string
+}
+
+func (e *ntfyAPIError) Error() string {
+	msg := fmt.Sprintf("ntfy %s failed with status %d: %s", e.Action, e.Status, e.Msg)
+	if e.Status == http.StatusUnauthorized || e.Status == http.StatusForbidden {
+		msg += "; set ntfy.token to an access token allowed to use the topic"
+	}
+	return msg
+}
+
+type ntfyAction struct {
+	Action  string            `json:"action"`
+	Label   string            `json:"label"`
+	URL     string            `json:"url"`
+	Method  string            `json:"method,omitempty"`
+	Headers map[string]string `json:"headers,omitempty"`
+	Body    string            `json:"body,omitempty"`
+	Clear   bool              `json:"clear,omitempty"`
+}
+
+func NewNtfy(cfg config.Config) (*NtfyProvider, error) {
+	topic := strings.TrimSpace(cfg.Ntfy.Topic)
+	if topic == "" {
+		return nil, fmt.Errorf(
+			"ntfy.topic is required.\n" +
+				"First-time ntfy setup:\n" +
+				"1) Install the ntfy app and pick a hard-to-guess topic name\n" +
+				"2) Run: `consult-human setup --provider ntfy`",
+		)
+	}
+	storePath, err := config.DefaultNtfyStorePath()
+	if err != nil {
+		return nil, err
+	}
+	return &NtfyProvider{
+		server:         config.EffectiveNtfyServer(cfg),
+		topic:          topic,
+		token:          strings.TrimSpace(cfg.Ntfy.Token),
+		httpClient:     &http.Client{Timeout: ntfyPublishTimeout},
+		streamClient:   &http.Client{},
+		reconnectDelay: ntfyReconnectDelay,
+		store:          newNtfyStore(storePath),
+		sentAt:         make(map[string]time.Time),
+	}, nil
+}
+
+func (p *NtfyProvider) Name() string { return "ntfy" }
+
+func (p *NtfyProvider) Close() error { return nil }
+
+func (p *NtfyProvider) replyTopic() string { return p.topic + config.NtfyReplyTopicSuffix }
+
+func (p *NtfyProvider) Send(ctx context.Context, req contract.AskRequest) (string, error) {
+	replyURL := p.server + "/" + p.replyTopic()
+	payload := map[string]any{
+		"topic":   p.topic,
+		"title":   "consult-human " + req.RequestID,
+		"message": RenderPrompt(req) + fmt.Sprintf("\nAnswer by publishing to %s with the request ID in the title or message.\n", replyURL),
+		"tags":    []string{"question"},
+		"click":   replyURL,
+	}
+	if req.Priority == contract.PriorityHigh {
+		payload["priority"] = 4
+	}
+	if actions := ntfyChoiceActions(req, replyURL); len(actions) > 0 {
+		payload["actions"] = actions
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return "", err
+	}
+
+	var published struct {
+		ID   string `json:"id"`
+		Time int64  `json:"time"`
+	}
+	if err := p.publish(ctx, body, &published); err != nil {
+		return "", err
+	}
+
+	expiresAt := time.Now().UTC().Add(ntfyPendingTTL)
+	if deadline, ok := ctx.Deadline(); ok {
+		expiresAt = deadline.UTC().Add(time.Minute)
+	}
+	if err := p.store.Register(ntfyPendingRecord{RequestID: req.RequestID, Topic: p.topic, ExpiresAt: expiresAt}); err != nil {
+		return "", err
+	}
+	sentAt := time.Now()
+	if published.Time > 0 {
+		sentAt = time.Unix(published.Time, 0)
+	}
+	p.mu.Lock()
+	p.sentAt[req.RequestID] = sentAt
+	p.mu.Unlock()
+	return published.ID, nil
+}
+
+// ntfyChoiceActions turns the first choices into buttons that publish the
+// choice ID to the reply topic, titled with the request ID.
+func ntfyChoiceActions(req contract.AskRequest, replyURL string) []ntfyAction {
+	if req.Type != contract.QuestionTypeChoice {
+		return nil
+	}
+	actions := make([]ntfyAction, 0, ntfyMaxActions)
+	for _, choice := range req.Choices {
+		if len(actions) == ntfyMaxActions {
+			break
+		}
+		label := choice.ID + ") " + choice.Text
+		if r := []rune(label); len(r) > ntfyActionLabelMax {
+			label = string(r[:ntfyActionLabelMax-1]) + "…"
+		}
+		actions = append(actions, ntfyAction{
+			Action:  "http",
+			Label:   label,
+			URL:     replyURL,
+			Method:  http.MethodPost,
+			Headers: map[string]string{"Title": req.RequestID},
+			Body:    choice.ID,
+			Clear:   true,
+		})
+	}
+	return actions
+}
+
+// Receive subscribes to the reply topic from the moment the question went
+// out and reconnects, resuming after the last event seen, when the stream
+// drops.
+func (p *NtfyProvider) Receive(ctx context.Context, requestID string) (contract.Reply, error) {
+	p.mu.Lock()
+	sentAt, ok := p.sentAt[requestID]
+	p.mu.Unlock()
+	if !ok {
+		return contract.Reply{}, fmt.Errorf("unknown request id %q", requestID)
+	}
+	defer func() {
+		_ = p.store.Remove(requestID)
+		p.mu.Lock()
+		delete(p.sentAt, requestID)
+		p.mu.Unlock()
+	}()
+
+	since := strconv.FormatInt(sentAt.Unix(), 10)
+	for {
+		reply, lastID, err := p.stream(ctx, requestID, since)
+		if err == nil {
+			return reply, nil
+		}
+		if ctx.Err() != nil {
+			return contract.Reply{}, ctx.Err()
+		}
+		// A rejected subscription won't succeed on retry.
+		var apiErr *ntfyAPIError
+		if errors.As(err, &apiErr) && apiErr.Status >= 400 && apiErr.Status < 500 {
+			return contract.Reply{}, err
+		}
+		if lastID != "" {
+			since = lastID
+		}
+
+		timer := time.NewTimer(p.reconnectDelay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return contract.Reply{}, ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// stream reads the reply topic until an answer for requestID arrives. It
+// returns the ID of the last event read so a reconnect can resume there.
+func (p *NtfyProvider) stream(ctx context.Context, requestID, since string) (contract.Reply, string, error) {
+	endpoint := p.server + "/" + url.PathEscape(p.replyTopic()) + "/json?since=" + url.QueryEscape(since)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return contract.Reply{}, "", err
+	}
+	p.authorize(req)
+	resp, err := p.streamClient.Do(req)
+	if err != nil {
+		return contract.Reply{}, "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return contract.Reply{}, "", ntfyStatusError("subscribe to "+p.replyTopic(), resp)
+	}
+
+	lastID := ""
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(make([]byte, 0, 64*1024), ntfyMaxMessageBytes)
+	for scanner.Scan() {
+		var ev ntfyEvent
+		if err := json.Unmarshal(scanner.Bytes(), &ev); err != nil || ev.Event != "message" {
+			continue
+		}
+		lastID = ev.ID
+		text, ok, err := p.answerText(ev, requestID)
+		if err != nil {
+			return contract.Reply{}, lastID, err
+		}
+		if !ok {
+			continue
+		}
+		return contract.Reply{
+			RequestID:         requestID,
+			Text:              text,
+			Raw:               ev.Message,
+			ProviderMessageID: ev.ID,
+			ReceivedAt:        time.Unix(ev.Time, 0).UTC(),
+		}, lastID, nil
+	}
+	if err := scanner.Err(); err != nil {
+		return contract.Reply{}, lastID, err
+	}
+	return contract.Reply{}, lastID, io.ErrUnexpectedEOF
+}
+
+// answerText decides whether ev answers requestID: a title naming the
+// request ID, the request ID in the message, or, while it is the only
+// question waiting on the topic, any message.
+func (p *NtfyProvider) answerText(ev ntfyEvent, requestID string) (string, bool, error) {
+	message := strings.TrimSpace(ev.Message)
+	if _, ok := stripRequestIDToken(ev.Title, requestID); ok {
+		text, _ := stripRequestIDToken(message, requestID)
+		return strings.TrimSpace(text), strings.TrimSpace(text) != "", nil
+	}
+	if text, ok := stripRequestIDToken(message, requestID); ok {
+		return strings.TrimSpace(text), strings.TrimSpace(text) != "", nil
+	}
+	if strings.TrimSpace(ev.Title) != "" || message == "" {
+		return "", false, nil
+	}
+	waiting, err := p.store.Waiting(p.topic)
+	if err != nil {
+		return "", false, err
+	}
+	return message, waiting == 1, nil
+}
+
+func (p *NtfyProvider) publish(ctx context.Context, body []byte, out any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.server+"/", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	p.authorize(req)
+
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		return fmt.Errorf("could not reach ntfy at %s: %w", p.server, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return ntfyStatusError("publish to "+p.topic, resp)
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, ntfyMaxMessageBytes)).Decode(out); err != nil {
+		return fmt.Errorf("decode ntfy publish response: %w", err)
+	}
+	return nil
+}
+
+func (p *NtfyProvider) authorize(req *http.Request) {
+	if p.token != "" {
+		req.Header.Set("Authorization", "Bearer "+p.token)
+	}
+}
+
+func ntfyStatusError(action string, resp *http.Response) error {
+	b, _ := io.ReadAll(io.LimitReader(resp.Body, 4<<10))
+	var decoded struct {
+		Error string `json:"error"`
+	}
+	msg := strings.TrimSpace(string(b))
+	if json.Unmarshal(b, &decoded) == nil && decoded.Error != "" {
+		msg = decoded.Error
+	}
+	return &ntfyAPIError{Action: action, Status: resp.StatusCode, Msg: msg}
+}
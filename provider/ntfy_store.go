@@ -0,0 +1,121 @@
+package provider
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/AlhasanIQ/consult-human/filelock"
+)
+
+const ntfyPendingTTL = 24 * time.Hour
+
+// ntfyPendingRecord is a question some ask process is waiting on.
+type ntfyPendingRecord struct {
+	RequestID string    `json:"request_id"`
+	Topic     string    `json:"topic"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// ntfyStore lists the questions waiting across ask processes. Every
+// subscriber sees every reply, so a reply without a request ID is only taken
+// while exactly one question waits on the topic.
+type ntfyStore struct {
+	path string
+	lock string
+}
+
+func newNtfyStore(path string) *ntfyStore {
+	return &ntfyStore{path: path, lock: path + ".lock"}
+}
+
+func (s *ntfyStore) Register(rec ntfyPendingRecord) error {
+	return s.withLock(func() error {
+		pending, err := s.loadPrunedLocked(time.Now())
+		if err != nil {
+			return err
+		}
+		out := pending[:0]
+		for _, existing := range pending {
+			if existing.RequestID != rec.RequestID {
+				out = append(out, existing)
+			}
+		}
+		return s.saveLocked(append(out, rec))
+	})
+}
+
+func (s *ntfyStore) Remove(requestID string) error {
+	return s.withLock(func() error {
+		pending, err := s.loadPrunedLocked(time.Now())
+		if err != nil {
+			return err
+		}
+		out := pending[:0]
+		for _, rec := range pending {
+			if rec.RequestID != requestID {
+				out = append(out, rec)
+			}
+		}
+		return s.saveLocked(out)
+	})
+}
+
+// Waiting counts the questions waiting on topic.
+func (s *ntfyStore) Waiting(topic string) (int, error) {
+	n := 0
+	err := s.withLock(func() error {
+		pending, err := s.loadPrunedLocked(time.Now())
+		if err != nil {
+			return err
+		}
+		for _, rec := range pending {
+			if rec.Topic == topic {
+				n++
+			}
+		}
+		return nil
+	})
+	return n, err
+}
+
+func (s *ntfyStore) withLock(fn func() error) error {
+	return filelock.Lock{Path: s.lock, Name: "ntfy store"}.With(fn)
+}
+
+func (s *ntfyStore) loadPrunedLocked(now time.Time) ([]ntfyPendingRecord, error) {
+	b, err := os.ReadFile(s.path)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	var pending []ntfyPendingRecord
+	if len(b) > 0 {
+		if err := json.Unmarshal(b, &pending); err != nil {
+			return nil, fmt.Errorf("parse ntfy store: %w", err)
+		}
+	}
+	out := pending[:0]
+	for _, rec := range pending {
+		if rec.ExpiresAt.IsZero() || rec.ExpiresAt.After(now) {
+			out = append(out, rec)
+		}
+	}
+	return out, nil
+}
+
+func (s *ntfyStore) saveLocked(pending []ntfyPendingRecord) error {
+	b, err := json.Marshal(pending)
+	if err != nil {
+		return err
+	}
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, b, 0o600); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, s.path); err != nil {
+		_ = os.Remove(tmp)
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,221 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/AlhasanIQ/consult-human/config"
+	"github.com/AlhasanIQ/consult-human/contract"
+)
+
+// ntfyServerMock publishes to an in-memory topic list and serves each
+// subscription one batch of reply events, then closes the stream.
+type ntfyServerMock struct {
+	mu              sync.Mutex
+	published       []map[string]any
+	auth            []string
+	since           []string
+	replies         [][]ntfyEvent
+	subscribeStatus int
+}
+
+func (m *ntfyServerMock) handler(t *testing.T) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		m.mu.Lock()
+		m.auth = append(m.auth, r.Header.Get("Authorization"))
+		switch {
+		case r.Method == http.MethodPost && r.URL.Path == "/":
+			var body map[string]any
+			if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+				t.Errorf("decode publish body: %v", err)
+			}
+			m.published = append(m.published, body)
+			m.mu.Unlock()
+			_, _ = fmt.Fprintf(w, `{"id":"q%d","time":1700000000,"event":"message"}`, len(m.published))
+		case r.Method == http.MethodGet && r.URL.Path == "/agent-questions-replies/json":
+			m.since = append(m.since, r.URL.Query().Get("since"))
+			if m.subscribeStatus != 0 {
+				m.mu.Unlock()
+				w.WriteHeader(m.subscribeStatus)
+				_, _ = w.Write([]byte(`{"code":40101,"http":401,"error":"unauthorized"}`))
+				return
+			}
+			var batch []ntfyEvent
+			if len(m.replies) > 0 {
+				batch = m.replies[0]
+				m.replies = m.replies[1:]
+			}
+			m.mu.Unlock()
+			enc := json.NewEncoder(w)
+			_ = enc.Encode(ntfyEvent{ID: "open", Event: "open"})
+			w.(http.Flusher).Flush()
+			for _, ev := range batch {
+				_ = enc.Encode(ev)
+				w.(http.Flusher).Flush()
+			}
+		default:
+			m.mu.Unlock()
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}
+}
+
+func newTestNtfyProvider(srvURL, storePath string) *NtfyProvider {
+	return &NtfyProvider{
+		server:         srvURL,
+		topic:          "agent-questions",
+		token:          "tk_test",
+		httpClient:     http.DefaultClient,
+		streamClient:   http.DefaultClient,
+		reconnectDelay: 5 * time.Millisecond,
+		store:          newNtfyStore(storePath),
+		sentAt:         make(map[string]time.Time),
+	}
+}
+
+func TestNtfySendAndReceiveByTitle(t *testing.T) {
+	m := &ntfyServerMock{}
+	srv := httptest.NewServer(m.handler(t))
+	defer srv.Close()
+	p := newTestNtfyProvider(srv.URL, filepath.Join(t.TempDir(), "ntfy-pending.json"))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	id, err := p.Send(ctx, contract.AskRequest{
+		RequestID: "req-1",
+		Question:  "Deploy?",
+		Type:      contract.QuestionTypeChoice,
+		Choices:   []contract.Choice{{ID: "a", Text: "Yes"}, {ID: "b", Text: "No"}, {ID: "c", Text: "Later"}, {ID: "d", Text: "Never"}},
+	})
+	if err != nil {
+		t.Fatalf("Send returned error: %v", err)
+	}
+	if id != "q1" {
+		t.Fatalf("expected the published message ID, got %q", id)
+	}
+	pub := m.published[0]
+	if pub["topic"] != "agent-questions" || pub["title"] != "consult-human req-1" || pub["click"] != srv.URL+"/agent-questions-replies" {
+		t.Fatalf("unexpected publish payload: %#v", pub)
+	}
+	actions, _ := pub["actions"].([]any)
+	if len(actions) != 3 {
+		t.Fatalf("expected three choice buttons, got %#v", pub["actions"])
+	}
+	first := actions[0].(map[string]any)
+	if first["action"] != "http" || first["body"] != "a" || first["url"] != srv.URL+"/agent-questions-replies" || first["headers"].(map[string]any)["Title"] != "req-1" {
+		t.Fatalf("unexpected action: %#v", first)
+	}
+
+	// Another question is waiting too, so the untitled message is not taken.
+	if err := p.store.Register(ntfyPendingRecord{RequestID: "req-9", Topic: "agent-questions"}); err != nil {
+		t.Fatalf("Register: %v", err)
+	}
+	m.replies = [][]ntfyEvent{{
+		{ID: "r1", Time: 1700000005, Event: "message", Message: "untitled"},
+		{ID: "r2", Time: 1700000006, Event: "message", Title: "req-9", Message: "not ours"},
+		{ID: "r3", Time: 1700000007, Event: "message", Title: "req-1", Message: "a"},
+	}}
+	reply, err := p.Receive(ctx, "req-1")
+	if err != nil {
+		t.Fatalf("Receive returned error: %v", err)
+	}
+	if reply.Text != "a" || reply.ProviderMessageID != "r3" || !reply.ReceivedAt.Equal(time.Unix(1700000007, 0)) {
+		t.Fatalf("unexpected reply: %#v", reply)
+	}
+	if m.since[0] != "1700000000" {
+		t.Fatalf("expected the subscription to start at the question, got since=%q", m.since[0])
+	}
+	for _, auth := range m.auth {
+		if auth != "Bearer tk_test" {
+			t.Fatalf("expected the access token on every request, got %q", auth)
+		}
+	}
+	if n, _ := p.store.Waiting("agent-questions"); n != 1 {
+		t.Fatalf("expected req-1 to leave the store, %d waiting", n)
+	}
+}
+
+func TestNtfyReceiveSinglePendingReconnects(t *testing.T) {
+	m := &ntfyServerMock{}
+	srv := httptest.NewServer(m.handler(t))
+	defer srv.Close()
+	p := newTestNtfyProvider(srv.URL, filepath.Join(t.TempDir(), "ntfy-pending.json"))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if _, err := p.Send(ctx, contract.AskRequest{RequestID: "req-1", Question: "Which region?"}); err != nil {
+		t.Fatalf("Send returned error: %v", err)
+	}
+	m.replies = [][]ntfyEvent{
+		{{ID: "r1", Time: 1700000001, Event: "keepalive"}, {ID: "r2", Time: 1700000002, Event: "message", Title: "req-old", Message: "stale"}},
+		{{ID: "r3", Time: 1700000003, Event: "message", Message: "eu-west-1"}},
+	}
+	reply, err := p.Receive(ctx, "req-1")
+	if err != nil {
+		t.Fatalf("Receive returned error: %v", err)
+	}
+	if reply.Text != "eu-west-1" {
+		t.Fatalf("unexpected reply: %#v", reply)
+	}
+	if len(m.since) != 2 || m.since[1] != "r2" {
+		t.Fatalf("expected the reconnect to resume after r2, got %#v", m.since)
+	}
+}
+
+func TestNtfyReceiveRequestIDInMessage(t *testing.T) {
+	p := &NtfyProvider{topic: "t", store: newNtfyStore(filepath.Join(t.TempDir(), "ntfy-pending.json"))}
+	for _, id := range []string{"req-1", "req-2"} {
+		if err := p.store.Register(ntfyPendingRecord{RequestID: id, Topic: "t"}); err != nil {
+			t.Fatalf("Register: %v", err)
+		}
+	}
+	text, ok, err := p.answerText(ntfyEvent{Message: "req-2 ship it"}, "req-2")
+	if err != nil || !ok || text != "ship it" {
+		t.Fatalf("expected the request ID to be stripped, got %q ok=%v err=%v", text, ok, err)
+	}
+	if _, ok, _ := p.answerText(ntfyEvent{Message: "ship it"}, "req-2"); ok {
+		t.Fatalf("expected an untitled message to be ignored with two questions waiting")
+	}
+}
+
+func TestNtfySubscribeUnauthorizedStops(t *testing.T) {
+	m := &ntfyServerMock{subscribeStatus: http.StatusUnauthorized}
+	srv := httptest.NewServer(m.handler(t))
+	defer srv.Close()
+	p := newTestNtfyProvider(srv.URL, filepath.Join(t.TempDir(), "ntfy-pending.json"))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if _, err := p.Send(ctx, contract.AskRequest{RequestID: "req-1", Question: "Deploy?"}); err != nil {
+		t.Fatalf("Send returned error: %v", err)
+	}
+	_, err := p.Receive(ctx, "req-1")
+	if err == nil || !strings.Contains(err.Error(), "status 401: unauthorized") || !strings.Contains(err.Error(), "ntfy.token") {
+		t.Fatalf("expected an unauthorized error with a token hint, got %v", err)
+	}
+}
+
+func TestFactoryUsesNtfy(t *testing.T) {
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+	cfg := config.Default()
+	if _, err := New(cfg, "ntfy"); err == nil || !strings.Contains(err.Error(), "ntfy.topic is required") {
+		t.Fatalf("expected a missing config error, got %v", err)
+	}
+	cfg.Ntfy.Topic = "agent-questions"
+	p, err := New(cfg, "ntfy")
+	if err != nil {
+		t.Fatalf("New returned error: %v", err)
+	}
+	if got := p.(*NtfyProvider).server; got != config.DefaultNtfyServer {
+		t.Fatalf("expected the public server by default, got %q", got)
+	}
+}
@@ -4,13 +4,42 @@ import (
 	"fmt"
 	"strings"
 
+	"github.com/AlhasanIQ/consult-human/config"
 	"github.com/AlhasanIQ/consult-human/contract"
 )
 
+const (
+	telegramHighPriorityMarker = "❗ Urgent"
+	telegramFollowUpMarker     = "(follow-up)"
+)
+
 func RenderTelegramPrompt(req contract.AskRequest) string {
+	return RenderTelegramPromptFor(req, config.TelegramParseModePlain)
+}
+
+// RenderTelegramPromptFor renders the prompt for a telegram.parse_mode,
+// escaping the question and choices unless req.PreFormatted is set, in which
+// case only the question is passed through as-is.
+func RenderTelegramPromptFor(req contract.AskRequest, parseMode string) string {
+	esc := telegramEscaper(parseMode)
 	var b strings.Builder
 
+	if origin := strings.TrimSpace(req.Origin); origin != "" {
+		b.WriteString(telegramDim(parseMode, esc("["+origin+"]")))
+		b.WriteString("\n")
+	}
+	if req.Priority == contract.PriorityHigh {
+		b.WriteString(esc(telegramHighPriorityMarker))
+		b.WriteString("\n\n")
+	}
+
 	question := strings.TrimSpace(req.Question)
+	if !req.PreFormatted {
+		question = esc(question)
+	}
+	if req.FollowUpTo != "" {
+		question = strings.TrimSpace(esc(telegramFollowUpMarker) + " " + question)
+	}
 	if question != "" {
 		b.WriteString(question)
 	}
@@ -20,17 +49,140 @@ func RenderTelegramPrompt(req contract.AskRequest) string {
 			b.WriteString("\n\n")
 		}
 		for _, choice := range req.Choices {
-			b.WriteString(fmt.Sprintf("%s) %s\n", choice.ID, choice.Text))
+			b.WriteString(esc(fmt.Sprintf("%s) %s", choice.ID, choice.Text)))
+			b.WriteString("\n")
+			if desc := strings.TrimSpace(choice.Description); desc != "" {
+				b.WriteString("    " + esc(desc) + "\n")
+			}
+		}
+		if req.AllowOther {
+			b.WriteString(esc("other) write your own answer"))
+			b.WriteString("\n")
+		}
+		b.WriteString("\n" + esc("Reply with option ID or text."))
+	}
+
+	return strings.TrimSpace(b.String())
+}
+
+// RenderSlackPrompt renders the prompt as Slack mrkdwn text. Only &, < and >
+// need escaping there; req.PreFormatted passes the question through as-is.
+func RenderSlackPrompt(req contract.AskRequest) string {
+	esc := slackEscaper.Replace
+	var b strings.Builder
+
+	if origin := strings.TrimSpace(req.Origin); origin != "" {
+		b.WriteString("_" + esc("["+origin+"]") + "_\n")
+	}
+	if req.Priority == contract.PriorityHigh {
+		b.WriteString(esc(telegramHighPriorityMarker) + "\n\n")
+	}
+
+	question := strings.TrimSpace(req.Question)
+	if !req.PreFormatted {
+		question = esc(question)
+	}
+	if req.FollowUpTo != "" {
+		question = strings.TrimSpace(telegramFollowUpMarker + " " + question)
+	}
+	b.WriteString(question)
+
+	if req.Type == contract.QuestionTypeChoice && len(req.Choices) > 0 {
+		b.WriteString("\n\n")
+		for _, choice := range req.Choices {
+			b.WriteString(esc(fmt.Sprintf("%s) %s", choice.ID, choice.Text)) + "\n")
+			if desc := strings.TrimSpace(choice.Description); desc != "" {
+				b.WriteString("    " + esc(desc) + "\n")
+			}
 		}
 		if req.AllowOther {
 			b.WriteString("other) write your own answer\n")
 		}
-		b.WriteString("\nReply with option ID or text.")
+		b.WriteString("\nReply in this thread with the option ID or text.")
+	} else {
+		b.WriteString("\n\nReply in this thread.")
+	}
+
+	return strings.TrimSpace(b.String())
+}
+
+// RenderZulipPrompt formats a question as Zulip markdown. In a stream each
+// question has its own topic; direct messages share one conversation, so
+// they ask for a quote or the request ID.
+func RenderZulipPrompt(req contract.AskRequest, direct bool) string {
+	var b strings.Builder
+
+	if origin := strings.TrimSpace(req.Origin); origin != "" {
+		b.WriteString("*[" + origin + "]*\n")
+	}
+	if req.Priority == contract.PriorityHigh {
+		b.WriteString("**" + telegramHighPriorityMarker + "**\n\n")
+	}
+	question := strings.TrimSpace(req.Question)
+	if req.FollowUpTo != "" {
+		question = telegramFollowUpMarker + " " + question
+	}
+	b.WriteString(question)
+
+	reply := "Reply in this topic"
+	if direct {
+		reply = fmt.Sprintf("Quote this message, or include `%s` in your reply,", req.RequestID)
+	}
+	if req.Type == contract.QuestionTypeChoice && len(req.Choices) > 0 {
+		b.WriteString("\n\n")
+		for _, choice := range req.Choices {
+			b.WriteString(fmt.Sprintf("**%s)** %s\n", choice.ID, choice.Text))
+			if desc := strings.TrimSpace(choice.Description); desc != "" {
+				b.WriteString("    " + desc + "\n")
+			}
+		}
+		if req.AllowOther {
+			b.WriteString("**other)** write your own answer\n")
+		}
+		b.WriteString("\n" + reply + " with the option ID or text.")
+	} else {
+		b.WriteString("\n\n" + strings.TrimSuffix(reply, ",") + ".")
 	}
 
 	return strings.TrimSpace(b.String())
 }
 
+var slackEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+
+// telegramMarkdownV2Escaper escapes every character MarkdownV2 treats as
+// markup; Telegram rejects the message if any of them is left bare.
+var telegramMarkdownV2Escaper = strings.NewReplacer(
+	`\`, `\\`, "_", `\_`, "*", `\*`, "[", `\[`, "]", `\]`, "(", `\(`, ")", `\)`,
+	"~", `\~`, "`", "\\`", ">", `\>`, "#", `\#`, "+", `\+`, "-", `\-`, "=", `\=`,
+	"|", `\|`, "{", `\{`, "}", `\}`, ".", `\.`, "!", `\!`,
+)
+
+var telegramHTMLEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+
+// telegramDim styles already escaped text as secondary, in italics where the
+// parse mode has markup for it.
+func telegramDim(parseMode, text string) string {
+	switch parseMode {
+	case config.TelegramParseModeMarkdown:
+		return "_" + text + "_"
+	case config.TelegramParseModeHTML:
+		return "<i>" + text + "</i>"
+	default:
+		return text
+	}
+}
+
+func telegramEscaper(parseMode string) func(string) string {
+	switch parseMode {
+	case config.TelegramParseModeMarkdown:
+		return telegramMarkdownV2Escaper.Replace
+	case config.TelegramParseModeHTML:
+		return telegramHTMLEscaper.Replace
+	default:
+		return func(s string) string { return s }
+	}
+}
+
 func RenderPrompt(req contract.AskRequest) string {
 	var b strings.Builder
 
@@ -43,6 +195,9 @@ func RenderPrompt(req contract.AskRequest) string {
 		b.WriteString("Options:\n")
 		for _, choice := range req.Choices {
 			b.WriteString(fmt.Sprintf("- %s) %s\n", choice.ID, choice.Text))
+			if desc := strings.TrimSpace(choice.Description); desc != "" {
+				b.WriteString(fmt.Sprintf("    %s\n", desc))
+			}
 		}
 		if req.AllowOther {
 			b.WriteString("- other) reply with your own text\n")
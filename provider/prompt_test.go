@@ -4,6 +4,7 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/AlhasanIQ/consult-human/config"
 	"github.com/AlhasanIQ/consult-human/contract"
 )
 
@@ -49,3 +50,138 @@ func TestRenderTelegramPromptChoice(t *testing.T) {
 		t.Fatalf("prompt should not include request metadata, got: %q", got)
 	}
 }
+
+func TestRenderPromptsIndentChoiceDescriptions(t *testing.T) {
+	req := contract.AskRequest{
+		RequestID: "req-desc",
+		Question:  "Release?",
+		Type:      contract.QuestionTypeChoice,
+		Choices: []contract.Choice{
+			{ID: "A", Text: "Ship now", Description: "Deploys the current build"},
+			{ID: "B", Text: "Wait"},
+		},
+	}
+
+	wantTelegram := "Release?\n\nA) Ship now\n    Deploys the current build\nB) Wait\n\nReply with option ID or text."
+	if got := RenderTelegramPrompt(req); got != wantTelegram {
+		t.Fatalf("unexpected telegram prompt:\n%s", got)
+	}
+	if got := RenderPrompt(req); !strings.Contains(got, "Options:\n- A) Ship now\n    Deploys the current build\n- B) Wait\n") {
+		t.Fatalf("unexpected prompt:\n%s", got)
+	}
+}
+
+func TestTelegramMarkdownV2Escaping(t *testing.T) {
+	cases := map[string]string{
+		"snake_case":         `snake\_case`,
+		"*bold*":             `\*bold\*`,
+		"[link](url)":        `\[link\]\(url\)`,
+		"`code`":             "\\`code\\`",
+		`C:\path`:            `C:\\path`,
+		"v1.2-rc! #3 a>b":    `v1\.2\-rc\! \#3 a\>b`,
+		"{x}=|y|~z+":         `\{x\}\=\|y\|\~z\+`,
+		"plain words, no op": "plain words, no op",
+	}
+	for in, want := range cases {
+		if got := telegramEscaper(config.TelegramParseModeMarkdown)(in); got != want {
+			t.Fatalf("escape %q = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestTelegramHTMLEscaping(t *testing.T) {
+	got := telegramEscaper(config.TelegramParseModeHTML)(`a < b && c > "d" *e*`)
+	if want := `a &lt; b &amp;&amp; c &gt; "d" *e*`; got != want {
+		t.Fatalf("escape = %q, want %q", got, want)
+	}
+}
+
+func TestRenderTelegramPromptForEscapesUnlessPreFormatted(t *testing.T) {
+	req := contract.AskRequest{
+		Question: "Rename `user_id`?",
+		Type:     contract.QuestionTypeChoice,
+		Choices:  []contract.Choice{{ID: "A", Text: "Yes (do it)"}},
+	}
+
+	got := RenderTelegramPromptFor(req, config.TelegramParseModeMarkdown)
+	want := "Rename \\`user\\_id\\`?\n\nA\\) Yes \\(do it\\)\n\nReply with option ID or text\\."
+	if got != want {
+		t.Fatalf("unexpected markdown prompt:\n%s", got)
+	}
+
+	req.Question = "Rename `user_id`?"
+	req.PreFormatted = true
+	got = RenderTelegramPromptFor(req, config.TelegramParseModeMarkdown)
+	if !strings.HasPrefix(got, "Rename `user_id`?\n\nA\\) Yes") {
+		t.Fatalf("pre-formatted question must pass through while choices stay escaped:\n%s", got)
+	}
+}
+
+func TestRenderTelegramPromptShowsOriginFirst(t *testing.T) {
+	req := contract.AskRequest{
+		Question: "Deploy now?",
+		Type:     contract.QuestionTypeOpen,
+		Priority: contract.PriorityHigh,
+		Origin:   "macbook · consult-human",
+	}
+
+	if got, want := RenderTelegramPrompt(req), "[macbook · consult-human]\n❗ Urgent\n\nDeploy now?"; got != want {
+		t.Fatalf("unexpected prompt:\n%s", got)
+	}
+	if got := RenderTelegramPromptFor(req, config.TelegramParseModeHTML); !strings.HasPrefix(got, "<i>[macbook · consult-human]</i>\n") {
+		t.Fatalf("expected the origin in italics, got:\n%s", got)
+	}
+	if got := RenderTelegramPromptFor(req, config.TelegramParseModeMarkdown); !strings.HasPrefix(got, "_\\[macbook · consult\\-human\\]_\n") {
+		t.Fatalf("expected the origin escaped in italics, got:\n%s", got)
+	}
+
+	rec := telegramPendingRecord{Question: "Deploy now?", Origin: req.Origin}
+	if got := telegramFollowUpText(rec); got != "[macbook · consult-human] Still waiting on: Deploy now?" {
+		t.Fatalf("expected the follow-up to name the origin, got %q", got)
+	}
+}
+
+func TestRenderSlackPromptEscapesAndAsksForThreadReply(t *testing.T) {
+	req := contract.AskRequest{
+		RequestID:  "req-1",
+		Question:   "Merge <feature> & deploy?",
+		Type:       contract.QuestionTypeChoice,
+		Choices:    []contract.Choice{{ID: "1", Text: "Yes"}, {ID: "2", Text: "No"}},
+		AllowOther: true,
+		Origin:     "laptop",
+	}
+	got := RenderSlackPrompt(req)
+	want := "_[laptop]_\nMerge &lt;feature&gt; &amp; deploy?\n\n1) Yes\n2) No\nother) write your own answer\n\nReply in this thread with the option ID or text."
+	if got != want {
+		t.Fatalf("unexpected slack prompt:\n%s", got)
+	}
+
+	req.Type = contract.QuestionTypeOpen
+	req.PreFormatted = true
+	req.Origin = ""
+	if got := RenderSlackPrompt(req); got != "Merge <feature> & deploy?\n\nReply in this thread." {
+		t.Fatalf("expected a pre-formatted question to pass through, got %q", got)
+	}
+}
+
+func TestRenderZulipPromptAsksForTopicOrQuotedReply(t *testing.T) {
+	req := contract.AskRequest{
+		RequestID:  "req-1",
+		Question:   "Merge the feature?",
+		Type:       contract.QuestionTypeChoice,
+		Choices:    []contract.Choice{{ID: "1", Text: "Yes"}, {ID: "2", Text: "No"}},
+		AllowOther: true,
+		Origin:     "laptop",
+	}
+	got := RenderZulipPrompt(req, false)
+	want := "*[laptop]*\nMerge the feature?\n\n**1)** Yes\n**2)** No\n**other)** write your own answer\n\nReply in this topic with the option ID or text."
+	if got != want {
+		t.Fatalf("unexpected zulip prompt:\n%s", got)
+	}
+
+	req.Type = contract.QuestionTypeOpen
+	req.Origin = ""
+	if got := RenderZulipPrompt(req, true); got != "Merge the feature?\n\nQuote this message, or include `req-1` in your reply." {
+		t.Fatalf("unexpected direct message prompt: %q", got)
+	}
+}
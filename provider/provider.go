@@ -2,13 +2,80 @@ package provider
 
 import (
 	"context"
+	"errors"
+	"io"
+	"time"
 
 	"github.com/AlhasanIQ/consult-human/contract"
 )
 
+// ErrCancelledByHuman is returned by Receive when the human dismissed the
+// question instead of answering it.
+var ErrCancelledByHuman = errors.New("question cancelled by the human")
+
 type Provider interface {
 	Name() string
 	Send(ctx context.Context, req contract.AskRequest) (string, error)
 	Receive(ctx context.Context, requestID string) (contract.Reply, error)
 	Close() error
 }
+
+// Canceler is implemented by providers that can withdraw a question that was
+// sent but will no longer be waited on.
+type Canceler interface {
+	Cancel(ctx context.Context, requestID string) error
+}
+
+// TimeoutNotifier is implemented by providers that can tell the human the
+// agent stopped waiting, so a late answer is not sent into the void.
+type TimeoutNotifier interface {
+	NotifyTimeout(ctx context.Context, requestID string, waited time.Duration) error
+}
+
+// Deferrer is implemented by providers that can list a question as held back
+// until a time, before it is sent.
+type Deferrer interface {
+	Defer(ctx context.Context, req contract.AskRequest, until time.Time) error
+}
+
+// LocalReplier is implemented by providers that can accept an answer typed on
+// this machine for a question another process is still waiting on.
+type LocalReplier interface {
+	ReplyLocally(ctx context.Context, requestID, text string, notify bool) error
+}
+
+// Notifier is implemented by providers that can deliver a one-way message
+// without registering a pending question.
+type Notifier interface {
+	Notify(ctx context.Context, n contract.Notification) error
+}
+
+// Corrector is implemented by providers that can tell when the human edits
+// a reply shortly after it was received.
+type Corrector interface {
+	AwaitCorrection(ctx context.Context, reply contract.Reply) (contract.Reply, bool, error)
+}
+
+// Acknowledger is implemented by providers that can tell the human how their
+// reply was understood.
+type Acknowledger interface {
+	Acknowledge(ctx context.Context, reply contract.Reply, text string) error
+}
+
+// Linker is implemented by providers that can note on a pending question
+// that it also went out through another provider, as escalation does.
+type Linker interface {
+	Link(ctx context.Context, requestID, other string) error
+}
+
+// ElsewhereNotifier is implemented by providers that can tell the human a
+// broadcast question was already answered through another provider.
+type ElsewhereNotifier interface {
+	NotifyAnsweredElsewhere(ctx context.Context, requestID, winner string) error
+}
+
+// StatusReporter is implemented by providers that print progress notes while
+// a question is out, so the caller can route them to its own status output.
+type StatusReporter interface {
+	SetStatus(w io.Writer)
+}
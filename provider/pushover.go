@@ -0,0 +1,382 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/AlhasanIQ/consult-human/config"
+	"github.com/AlhasanIQ/consult-human/contract"
+)
+
+const (
+	// DefaultPushoverAPIBaseURL is the Pushover message API.
+	DefaultPushoverAPIBaseURL = "https://api.pushover.net/1"
+	// PushoverAcknowledgedText is the answer to a question acknowledged in
+	// confirm-only mode.
+	PushoverAcknowledgedText = "acknowledged"
+
+	pushoverRequestTimeout = 30 * time.Second
+	pushoverProbeTimeout   = 5 * time.Second
+	// Pushover asks receipts to be polled no more than every 5 seconds.
+	pushoverReceiptInterval = 5 * time.Second
+	pushoverMessageLimit    = 1024
+	// Emergency notifications repeat every pushoverRetry until acknowledged
+	// or pushoverMaxExpire has passed.
+	pushoverRetry     = 60
+	pushoverMaxExpire = 3 * time.Hour
+)
+
+// PushoverProvider sends questions as Pushover notifications. With
+// pushover.reply_url set, each notification links to a reply form served on
+// pushover.listen, and submitting the form is the answer. Without it the
+// provider runs confirm-only: questions go out as emergency notifications
+// that repeat until acknowledged, and the acknowledgment is the answer.
+type PushoverProvider struct {
+	appToken     string
+	userKey      string
+	replyURL     string
+	listen       string
+	apiBaseURL   string
+	httpClient   *http.Client
+	pollInterval time.Duration
+	// form is nil in confirm-only mode.
+	form *replyForm
+
+	mu       sync.Mutex
+	receipts map[string]string
+}
+
+// pushoverAPIError is a "status": 0 answer, such as an invalid token or
+// user key.
+type pushoverAPIError struct {
+	Action string
+	Status int
+	Errors []string
+}
+
+func (e *pushoverAPIError) Error() string {
+	msg := fmt.Sprintf("pushover %s failed with status %d", e.Action, e.Status)
+	if len(e.Errors) > 0 {
+		msg += ": " + strings.Join(e.Errors, "; ")
+	}
+	for _, text := range e.Errors {
+		switch {
+		case strings.Contains(text, "application token"):
+			return msg + "; check pushover.app_token"
+		case strings.Contains(text, "user key") || strings.Contains(text, "user identifier"):
+			return msg + "; check pushover.user_key"
+		}
+	}
+	return msg
+}
+
+type pushoverReceipt struct {
+	Acknowledged         int    `json:"acknowledged"`
+	AcknowledgedAt       int64  `json:"acknowledged_at"`
+	AcknowledgedByDevice string `json:"acknowledged_by_device"`
+	Expired              int    `json:"expired"`
+}
+
+func NewPushover(cfg config.Config) (*PushoverProvider, error) {
+	po := cfg.Pushover
+	if strings.TrimSpace(po.AppToken) == "" || strings.TrimSpace(po.UserKey) == "" {
+		return nil, fmt.Errorf(
+			"pushover.app_token and pushover.user_key are required.\n" +
+				"First-time Pushover setup:\n" +
+				"1) Create an application at https://pushover.net/apps/build and copy its API token\n" +
+				"2) Run: `consult-human setup --provider pushover`",
+		)
+	}
+	listen := strings.TrimSpace(po.Listen)
+	if listen == "" {
+		listen = config.DefaultPushoverListen
+	}
+	p := &PushoverProvider{
+		appToken:     strings.TrimSpace(po.AppToken),
+		userKey:      strings.TrimSpace(po.UserKey),
+		replyURL:     strings.TrimRight(strings.TrimSpace(po.ReplyURL), "/"),
+		listen:       listen,
+		apiBaseURL:   DefaultPushoverAPIBaseURL,
+		httpClient:   &http.Client{Timeout: pushoverRequestTimeout},
+		pollInterval: pushoverReceiptInterval,
+		receipts:     make(map[string]string),
+	}
+	if p.replyURL != "" {
+		if isLoopbackURL(p.replyURL) {
+			return nil, fmt.Errorf("pushover.reply_url %s points at this machine, which your phone cannot open; %s", p.replyURL, p.reachHint())
+		}
+		p.form = newReplyForm(listen, false)
+	}
+	return p, nil
+}
+
+func (p *PushoverProvider) Name() string { return "pushover" }
+
+func (p *PushoverProvider) Close() error {
+	if p.form == nil {
+		return nil
+	}
+	return p.form.close()
+}
+
+// confirmOnly reports whether questions are answered by acknowledging them.
+func (p *PushoverProvider) confirmOnly() bool { return p.form == nil }
+
+// tunnelHint explains how to make the reply form reachable from the phone.
+func (p *PushoverProvider) tunnelHint() string {
+	return fmt.Sprintf("expose pushover.listen (%s) through a tunnel such as `cloudflared tunnel --url http://%s` and set pushover.reply_url to the tunnel URL", p.listen, p.listen)
+}
+
+func (p *PushoverProvider) reachHint() string {
+	return p.tunnelHint() + ", or clear pushover.reply_url to use confirm-only mode"
+}
+
+func (p *PushoverProvider) Send(ctx context.Context, req contract.AskRequest) (string, error) {
+	if p.confirmOnly() {
+		return p.sendConfirm(ctx, req)
+	}
+	if _, err := p.form.open(req); err != nil {
+		return "", fmt.Errorf("%w; pushover.listen must be free on this machine", err)
+	}
+	link := p.replyURL + replyFormPath(req.RequestID)
+	if err := p.probe(ctx, link); err != nil {
+		p.form.resolve(req.RequestID)
+		if ctx.Err() != nil {
+			return "", ctx.Err()
+		}
+		return "", fmt.Errorf("the pushover reply form is not reachable at %s (%v); %s", link, err, p.reachHint())
+	}
+
+	params := p.messageParams(req, req.Question+"\n\nTap to answer.")
+	params.Set("url", link)
+	params.Set("url_title", "Answer")
+	switch {
+	case req.Priority == contract.PriorityHigh:
+		params.Set("priority", "1")
+	case req.Silent:
+		params.Set("priority", "-1")
+	}
+	var decoded struct {
+		Request string `json:"request"`
+	}
+	if err := p.call(ctx, http.MethodPost, "messages.json", params, &decoded); err != nil {
+		p.form.resolve(req.RequestID)
+		return "", err
+	}
+	return decoded.Request, nil
+}
+
+// sendConfirm sends req as an emergency notification that repeats until it
+// is acknowledged, at most until ctx's deadline.
+func (p *PushoverProvider) sendConfirm(ctx context.Context, req contract.AskRequest) (string, error) {
+	if req.Type == contract.QuestionTypeChoice && len(req.Choices) > 0 {
+		return "", fmt.Errorf("pushover is in confirm-only mode, where acknowledging is the only answer, so it cannot ask a choice question; to answer it from a reply form, %s", p.tunnelHint())
+	}
+	expire := pushoverMaxExpire
+	if deadline, ok := ctx.Deadline(); ok {
+		expire = min(max(time.Until(deadline), pushoverRetry*time.Second), pushoverMaxExpire)
+	}
+	params := p.messageParams(req, req.Question+"\n\nAcknowledge to confirm.")
+	params.Set("priority", "2")
+	params.Set("retry", strconv.Itoa(pushoverRetry))
+	params.Set("expire", strconv.Itoa(int(expire.Seconds())))
+
+	var decoded struct {
+		Request string `json:"request"`
+		Receipt string `json:"receipt"`
+	}
+	if err := p.call(ctx, http.MethodPost, "messages.json", params, &decoded); err != nil {
+		return "", err
+	}
+	if decoded.Receipt == "" {
+		return "", fmt.Errorf("pushover returned no receipt for an emergency notification")
+	}
+	p.mu.Lock()
+	p.receipts[req.RequestID] = decoded.Receipt
+	p.mu.Unlock()
+	return decoded.Request, nil
+}
+
+func (p *PushoverProvider) messageParams(req contract.AskRequest, message string) url.Values {
+	if r := []rune(message); len(r) > pushoverMessageLimit {
+		message = string(r[:pushoverMessageLimit-1]) + "…"
+	}
+	title := "consult-human"
+	if req.Origin != "" {
+		title += " · " + req.Origin
+	}
+	return url.Values{
+		"token":   {p.appToken},
+		"user":    {p.userKey},
+		"title":   {title},
+		"message": {message},
+	}
+}
+
+// probe checks that link reaches the reply form, so a dead tunnel fails the
+// send instead of leaving the human with a link that does not open.
+func (p *PushoverProvider) probe(ctx context.Context, link string) error {
+	ctx, cancel := context.WithTimeout(ctx, pushoverProbeTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, link, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 1<<20))
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// Receive waits for the reply form, or in confirm-only mode polls the
+// notification's receipt until it is acknowledged.
+func (p *PushoverProvider) Receive(ctx context.Context, requestID string) (contract.Reply, error) {
+	if !p.confirmOnly() {
+		return p.form.wait(ctx, requestID)
+	}
+	p.mu.Lock()
+	receipt, ok := p.receipts[requestID]
+	p.mu.Unlock()
+	if !ok {
+		return contract.Reply{}, fmt.Errorf("unknown request id %q", requestID)
+	}
+
+	for {
+		var status pushoverReceipt
+		err := p.call(ctx, http.MethodGet, "receipts/"+url.PathEscape(receipt)+".json", url.Values{"token": {p.appToken}}, &status)
+		switch {
+		case err != nil:
+			if ctx.Err() != nil {
+				return contract.Reply{}, ctx.Err()
+			}
+			return contract.Reply{}, err
+		case status.Acknowledged == 1:
+			p.forget(requestID)
+			return contract.Reply{
+				RequestID:  requestID,
+				Text:       PushoverAcknowledgedText,
+				Raw:        PushoverAcknowledgedText,
+				From:       status.AcknowledgedByDevice,
+				ReceivedAt: time.Unix(status.AcknowledgedAt, 0).UTC(),
+			}, nil
+		case status.Expired == 1:
+			p.forget(requestID)
+			return contract.Reply{}, fmt.Errorf("pushover stopped alerting for %s before it was acknowledged", requestID)
+		}
+
+		timer := time.NewTimer(p.pollInterval)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return contract.Reply{}, ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// Cancel stops a confirm-only notification from repeating, or takes down
+// the question's reply form.
+func (p *PushoverProvider) Cancel(ctx context.Context, requestID string) error {
+	if !p.confirmOnly() {
+		p.form.resolve(requestID)
+		return nil
+	}
+	p.mu.Lock()
+	receipt, ok := p.receipts[requestID]
+	p.mu.Unlock()
+	if !ok {
+		return nil
+	}
+	p.forget(requestID)
+	return p.call(ctx, http.MethodPost, "receipts/"+url.PathEscape(receipt)+"/cancel.json", url.Values{"token": {p.appToken}}, nil)
+}
+
+func (p *PushoverProvider) forget(requestID string) {
+	p.mu.Lock()
+	delete(p.receipts, requestID)
+	p.mu.Unlock()
+}
+
+// call makes an API request and decodes the answer into out once Pushover
+// reports "status": 1.
+func (p *PushoverProvider) call(ctx context.Context, method, path string, params url.Values, out any) error {
+	endpoint := p.apiBaseURL + "/" + path
+	var body io.Reader
+	if method == http.MethodGet {
+		endpoint += "?" + params.Encode()
+	} else {
+		body = strings.NewReader(params.Encode())
+	}
+	req, err := http.NewRequestWithContext(ctx, method, endpoint, body)
+	if err != nil {
+		return err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	}
+
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		return fmt.Errorf("could not reach pushover: %w", err)
+	}
+	defer resp.Body.Close()
+	raw, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return err
+	}
+
+	action := strings.TrimSuffix(path, ".json")
+	var status struct {
+		Status int      `json:"status"`
+		Errors []string `json:"errors"`
+	}
+	if err := json.Unmarshal(raw, &status); err != nil {
+		if resp.StatusCode != http.StatusOK {
+			return &pushoverAPIError{Action: action, Status: resp.StatusCode, Errors: []string{strings.TrimSpace(string(raw))}}
+		}
+		return fmt.Errorf("decode pushover %s response: %w", action, err)
+	}
+	if resp.StatusCode != http.StatusOK || status.Status != 1 {
+		return &pushoverAPIError{Action: action, Status: resp.StatusCode, Errors: status.Errors}
+	}
+	if out == nil {
+		return nil
+	}
+	if err := json.Unmarshal(raw, out); err != nil {
+		return fmt.Errorf("decode pushover %s response: %w", action, err)
+	}
+	return nil
+}
+
+// isLoopbackURL reports whether raw names this machine, such as localhost
+// or 127.0.0.1.
+func isLoopbackURL(raw string) bool {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return false
+	}
+	host := u.Hostname()
+	if strings.EqualFold(host, "localhost") {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
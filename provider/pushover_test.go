@@ -0,0 +1,239 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/AlhasanIQ/consult-human/config"
+	"github.com/AlhasanIQ/consult-human/contract"
+)
+
+const (
+	testPushoverToken = "azGDORePK8gMaC0QOYAMyEEuzJnyUi"
+	testPushoverUser  = "uQiRzpo4DXghDmr9QzzfQu27cmVRsG"
+)
+
+// pushoverAPIMock records posted messages and answers each receipt poll with
+// the next entry of receipts, repeating the last.
+type pushoverAPIMock struct {
+	mu        sync.Mutex
+	messages  []url.Values
+	receipts  []pushoverReceipt
+	polls     int
+	cancelled []string
+	errors    []string
+}
+
+func (m *pushoverAPIMock) handler(t *testing.T) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		m.mu.Lock()
+		defer m.mu.Unlock()
+		if err := r.ParseForm(); err != nil {
+			t.Errorf("ParseForm: %v", err)
+		}
+		if r.Form.Get("token") != testPushoverToken {
+			t.Errorf("unexpected token %q", r.Form.Get("token"))
+		}
+		if len(m.errors) > 0 {
+			w.WriteHeader(http.StatusBadRequest)
+			_ = json.NewEncoder(w).Encode(map[string]any{"status": 0, "errors": m.errors})
+			return
+		}
+		switch {
+		case r.Method == http.MethodPost && r.URL.Path == "/messages.json":
+			m.messages = append(m.messages, r.PostForm)
+			_ = json.NewEncoder(w).Encode(map[string]any{"status": 1, "request": "req-abc", "receipt": "rcpt1"})
+		case r.Method == http.MethodGet && r.URL.Path == "/receipts/rcpt1.json":
+			i := min(m.polls, len(m.receipts)-1)
+			m.polls++
+			rec := m.receipts[i]
+			_ = json.NewEncoder(w).Encode(map[string]any{
+				"status":                 1,
+				"acknowledged":           rec.Acknowledged,
+				"acknowledged_at":        rec.AcknowledgedAt,
+				"acknowledged_by_device": rec.AcknowledgedByDevice,
+				"expired":                rec.Expired,
+			})
+		case r.Method == http.MethodPost && strings.HasSuffix(r.URL.Path, "/cancel.json"):
+			m.cancelled = append(m.cancelled, r.URL.Path)
+			_, _ = w.Write([]byte(`{"status":1}`))
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}
+}
+
+// freeLocalAddr returns a loopback address nothing is listening on.
+func freeLocalAddr(t *testing.T) string {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	addr := ln.Addr().String()
+	_ = ln.Close()
+	return addr
+}
+
+// newTestPushoverProvider builds a provider against m. A non-empty replyURL
+// turns on the reply form, listening on listen.
+func newTestPushoverProvider(t *testing.T, m *pushoverAPIMock, listen, replyURL string) *PushoverProvider {
+	t.Helper()
+	srv := httptest.NewServer(m.handler(t))
+	t.Cleanup(srv.Close)
+	p := &PushoverProvider{
+		appToken:     testPushoverToken,
+		userKey:      testPushoverUser,
+		replyURL:     replyURL,
+		listen:       listen,
+		apiBaseURL:   srv.URL,
+		httpClient:   &http.Client{Timeout: 5 * time.Second},
+		pollInterval: 5 * time.Millisecond,
+		receipts:     make(map[string]string),
+	}
+	if replyURL != "" {
+		p.form = newReplyForm(listen, false)
+	}
+	t.Cleanup(func() { _ = p.Close() })
+	return p
+}
+
+func TestPushoverReplyFormAnswersQuestion(t *testing.T) {
+	m := &pushoverAPIMock{}
+	listen := freeLocalAddr(t)
+	p := newTestPushoverProvider(t, m, listen, "http://"+listen)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	req := contract.AskRequest{RequestID: "req-1", Question: "Deploy?", Priority: contract.PriorityHigh, Origin: "api-repo"}
+	if _, err := p.Send(ctx, req); err != nil {
+		t.Fatalf("Send returned error: %v", err)
+	}
+	msg := m.messages[0]
+	link := "http://" + listen + "/reply/req-1"
+	if msg.Get("url") != link || msg.Get("priority") != "1" || msg.Get("user") != testPushoverUser ||
+		msg.Get("title") != "consult-human · api-repo" || !strings.HasPrefix(msg.Get("message"), "Deploy?") {
+		t.Fatalf("unexpected message: %#v", msg)
+	}
+
+	_, form := getDesktopPage(t, link)
+	match := desktopTokenPattern.FindStringSubmatch(form)
+	if match == nil || strings.Contains(form, "All waiting questions") {
+		t.Fatalf("expected a form without the index link, got: %s", form)
+	}
+	if status, _ := getDesktopPage(t, "http://"+listen+"/"); status != http.StatusNotFound {
+		t.Fatalf("expected no index page for a form reachable from outside, got %d", status)
+	}
+	if status, _ := postDesktopForm(t, link, url.Values{"token": {match[1]}, "text": {"yes"}}); status != http.StatusOK {
+		t.Fatalf("expected the answer to be accepted, got %d", status)
+	}
+	reply, err := p.Receive(ctx, "req-1")
+	if err != nil {
+		t.Fatalf("Receive returned error: %v", err)
+	}
+	if reply.Text != "yes" {
+		t.Fatalf("unexpected reply: %#v", reply)
+	}
+}
+
+func TestPushoverUnreachableReplyForm(t *testing.T) {
+	m := &pushoverAPIMock{}
+	p := newTestPushoverProvider(t, m, freeLocalAddr(t), "http://"+freeLocalAddr(t))
+
+	_, err := p.Send(context.Background(), contract.AskRequest{RequestID: "req-1", Question: "Deploy?"})
+	if err == nil || !strings.Contains(err.Error(), "not reachable") || !strings.Contains(err.Error(), "cloudflared tunnel") || !strings.Contains(err.Error(), "confirm-only") {
+		t.Fatalf("expected a reachability error with hints, got %v", err)
+	}
+	if len(m.messages) != 0 {
+		t.Fatalf("expected no notification with a dead link, got %#v", m.messages)
+	}
+}
+
+func TestPushoverConfirmOnlyWaitsForAcknowledgment(t *testing.T) {
+	m := &pushoverAPIMock{receipts: []pushoverReceipt{
+		{},
+		{Acknowledged: 1, AcknowledgedAt: 1700000000, AcknowledgedByDevice: "pixel"},
+	}}
+	p := newTestPushoverProvider(t, m, config.DefaultPushoverListen, "")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
+	defer cancel()
+	if _, err := p.Send(ctx, contract.AskRequest{RequestID: "req-1", Question: "Restart the database?"}); err != nil {
+		t.Fatalf("Send returned error: %v", err)
+	}
+	msg := m.messages[0]
+	if msg.Get("priority") != "2" || msg.Get("retry") != "60" || msg.Get("url") != "" {
+		t.Fatalf("expected an emergency notification, got %#v", msg)
+	}
+	if expire := msg.Get("expire"); expire != "599" && expire != "600" {
+		t.Fatalf("expected the alert to expire with the request, got %s", expire)
+	}
+
+	reply, err := p.Receive(ctx, "req-1")
+	if err != nil {
+		t.Fatalf("Receive returned error: %v", err)
+	}
+	if reply.Text != PushoverAcknowledgedText || reply.From != "pixel" || !reply.ReceivedAt.Equal(time.Unix(1700000000, 0)) {
+		t.Fatalf("unexpected reply: %#v", reply)
+	}
+	if m.polls != 2 {
+		t.Fatalf("expected two receipt polls, got %d", m.polls)
+	}
+}
+
+func TestPushoverConfirmOnlyRejectsChoicesAndCancels(t *testing.T) {
+	m := &pushoverAPIMock{receipts: []pushoverReceipt{{}}}
+	p := newTestPushoverProvider(t, m, config.DefaultPushoverListen, "")
+
+	choice := contract.AskRequest{RequestID: "req-1", Question: "Which?", Type: contract.QuestionTypeChoice, Choices: []contract.Choice{{ID: "a", Text: "A"}}}
+	if _, err := p.Send(context.Background(), choice); err == nil || !strings.Contains(err.Error(), "confirm-only") || !strings.Contains(err.Error(), "pushover.reply_url") {
+		t.Fatalf("expected choice questions to be refused, got %v", err)
+	}
+
+	if _, err := p.Send(context.Background(), contract.AskRequest{RequestID: "req-2", Question: "Restart?"}); err != nil {
+		t.Fatalf("Send returned error: %v", err)
+	}
+	if err := p.Cancel(context.Background(), "req-2"); err != nil {
+		t.Fatalf("Cancel returned error: %v", err)
+	}
+	if len(m.cancelled) != 1 || m.cancelled[0] != "/receipts/rcpt1/cancel.json" {
+		t.Fatalf("expected the receipt to be cancelled, got %#v", m.cancelled)
+	}
+}
+
+func TestPushoverAPIErrorHint(t *testing.T) {
+	m := &pushoverAPIMock{errors: []string{"user key is invalid"}}
+	p := newTestPushoverProvider(t, m, config.DefaultPushoverListen, "")
+	_, err := p.Send(context.Background(), contract.AskRequest{RequestID: "req-1", Question: "Restart?"})
+	if err == nil || !strings.Contains(err.Error(), "user key is invalid") || !strings.Contains(err.Error(), "pushover.user_key") {
+		t.Fatalf("expected an API error with a hint, got %v", err)
+	}
+}
+
+func TestFactoryUsesPushover(t *testing.T) {
+	cfg := config.Default()
+	if _, err := New(cfg, "pushover"); err == nil || !strings.Contains(err.Error(), "pushover.user_key are required") {
+		t.Fatalf("expected a missing config error, got %v", err)
+	}
+	cfg.Pushover = config.PushoverConfig{AppToken: testPushoverToken, UserKey: testPushoverUser, ReplyURL: "http://localhost:8788"}
+	if _, err := New(cfg, "pushover"); err == nil || !strings.Contains(err.Error(), "phone cannot open") {
+		t.Fatalf("expected a loopback reply_url error, got %v", err)
+	}
+	cfg.Pushover.ReplyURL = "https://reply.example.com"
+	p, err := New(cfg, "pushover")
+	if err != nil {
+		t.Fatalf("New returned error: %v", err)
+	}
+	if p.Name() != "pushover" {
+		t.Fatalf("unexpected provider %q", p.Name())
+	}
+}
@@ -0,0 +1,256 @@
+package provider
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/AlhasanIQ/consult-human/contract"
+)
+
+const (
+	replyFormMaxBytes        = 64 << 10
+	replyFormShutdownTimeout = 5 * time.Second
+)
+
+// replyForm serves a reply form for each waiting question on a local
+// listener. The server starts with the first question and stops once the
+// last one is answered or abandoned. The desktop and Pushover providers both
+// answer through it.
+type replyForm struct {
+	addr string
+	// index lists the waiting questions at /. It stays off when the form is
+	// reachable from other machines, where request IDs act as capabilities.
+	index bool
+
+	mu       sync.Mutex
+	server   *http.Server
+	listener net.Listener
+	// formToken is embedded in every form and required on submit, so other
+	// sites open in the browser cannot post answers.
+	formToken string
+	pending   map[string]*replyFormPending
+}
+
+type replyFormPending struct {
+	req     contract.AskRequest
+	sentAt  time.Time
+	replies chan contract.Reply
+}
+
+func newReplyForm(addr string, index bool) *replyForm {
+	return &replyForm{addr: addr, index: index, pending: make(map[string]*replyFormPending)}
+}
+
+// replyFormPath is the form's path for requestID, relative to the server
+// root.
+func replyFormPath(requestID string) string {
+	return "/reply/" + url.PathEscape(requestID)
+}
+
+// open registers req, starting the server if needed, and returns the local
+// URL of its form.
+func (f *replyForm) open(req contract.AskRequest) (string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.startServerLocked(); err != nil {
+		return "", err
+	}
+	f.pending[req.RequestID] = &replyFormPending{req: req, sentAt: time.Now(), replies: make(chan contract.Reply, 1)}
+	return f.baseURLLocked() + replyFormPath(req.RequestID), nil
+}
+
+// wait blocks until the form for requestID is submitted or ctx ends. Either
+// way the question is no longer served afterwards.
+func (f *replyForm) wait(ctx context.Context, requestID string) (contract.Reply, error) {
+	f.mu.Lock()
+	pending, ok := f.pending[requestID]
+	f.mu.Unlock()
+	if !ok {
+		return contract.Reply{}, fmt.Errorf("unknown request id %q", requestID)
+	}
+	defer f.resolve(requestID)
+	select {
+	case reply := <-pending.replies:
+		return reply, nil
+	case <-ctx.Done():
+		return contract.Reply{}, ctx.Err()
+	}
+}
+
+// resolve drops requestID and stops the server when nothing is left to
+// answer.
+func (f *replyForm) resolve(requestID string) {
+	f.mu.Lock()
+	delete(f.pending, requestID)
+	var srv *http.Server
+	if len(f.pending) == 0 {
+		srv = f.takeServerLocked()
+	}
+	f.mu.Unlock()
+	_ = shutdownReplyFormServer(srv)
+}
+
+// URL is the local address of the server root, or "" while no question is
+// waiting.
+func (f *replyForm) URL() string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.listener == nil {
+		return ""
+	}
+	return f.baseURLLocked() + "/"
+}
+
+func (f *replyForm) close() error {
+	f.mu.Lock()
+	srv := f.takeServerLocked()
+	f.mu.Unlock()
+	return shutdownReplyFormServer(srv)
+}
+
+func (f *replyForm) baseURLLocked() string {
+	return "http://" + f.listener.Addr().String()
+}
+
+func (f *replyForm) startServerLocked() error {
+	if f.server != nil {
+		return nil
+	}
+	ln, err := net.Listen("tcp", f.addr)
+	if err != nil {
+		return fmt.Errorf("listen for the reply form on %s: %w", f.addr, err)
+	}
+	token := make([]byte, 16)
+	if _, err := rand.Read(token); err != nil {
+		_ = ln.Close()
+		return err
+	}
+	mux := http.NewServeMux()
+	if f.index {
+		mux.HandleFunc("GET /{$}", f.handleIndex)
+	}
+	mux.HandleFunc("GET /reply/{id}", f.handleForm)
+	mux.HandleFunc("POST /reply/{id}", f.handleSubmit)
+	srv := &http.Server{Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+	f.server, f.listener, f.formToken = srv, ln, hex.EncodeToString(token)
+	go func() { _ = srv.Serve(ln) }()
+	return nil
+}
+
+// takeServerLocked detaches the server for shutdown. The listener closes
+// right away so a fixed port is free for the next question.
+func (f *replyForm) takeServerLocked() *http.Server {
+	srv := f.server
+	if f.listener != nil {
+		_ = f.listener.Close()
+	}
+	f.server, f.listener = nil, nil
+	return srv
+}
+
+func shutdownReplyFormServer(srv *http.Server) error {
+	if srv == nil {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), replyFormShutdownTimeout)
+	defer cancel()
+	if err := srv.Shutdown(ctx); err != nil && !errors.Is(err, net.ErrClosed) {
+		return err
+	}
+	return nil
+}
+
+func (f *replyForm) handleIndex(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	items := make([]replyFormPending, 0, len(f.pending))
+	for _, pending := range f.pending {
+		items = append(items, *pending)
+	}
+	f.mu.Unlock()
+	sort.Slice(items, func(i, j int) bool { return items[i].sentAt.Before(items[j].sentAt) })
+
+	view := desktopIndexView{}
+	for _, item := range items {
+		view.Questions = append(view.Questions, desktopIndexItem{
+			RequestID: item.req.RequestID,
+			Question:  item.req.Question,
+			Origin:    item.req.Origin,
+			Urgent:    item.req.Priority == contract.PriorityHigh,
+		})
+	}
+	renderDesktopPage(w, http.StatusOK, desktopIndexTemplate, view)
+}
+
+func (f *replyForm) handleForm(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	pending, ok := f.pending[r.PathValue("id")]
+	token := f.formToken
+	f.mu.Unlock()
+	if !ok {
+		f.renderMessage(w, http.StatusNotFound, "Nothing to answer", "This question was already answered or is no longer waiting.")
+		return
+	}
+	req := pending.req
+	renderDesktopPage(w, http.StatusOK, desktopFormTemplate, desktopFormView{
+		RequestID:  req.RequestID,
+		Question:   req.Question,
+		Origin:     req.Origin,
+		Urgent:     req.Priority == contract.PriorityHigh,
+		Choices:    req.Choices,
+		FreeText:   req.Type != contract.QuestionTypeChoice || len(req.Choices) == 0 || req.AllowOther,
+		Token:      token,
+		ActionPath: replyFormPath(req.RequestID),
+		Index:      f.index,
+	})
+}
+
+func (f *replyForm) handleSubmit(w http.ResponseWriter, r *http.Request) {
+	r.Body = http.MaxBytesReader(w, r.Body, replyFormMaxBytes)
+	if err := r.ParseForm(); err != nil {
+		f.renderMessage(w, http.StatusBadRequest, "Could not read the answer", err.Error())
+		return
+	}
+	requestID := r.PathValue("id")
+	f.mu.Lock()
+	pending, ok := f.pending[requestID]
+	token := f.formToken
+	f.mu.Unlock()
+	if subtle.ConstantTimeCompare([]byte(r.PostForm.Get("token")), []byte(token)) != 1 {
+		f.renderMessage(w, http.StatusForbidden, "Answer not accepted", "Reload the question page and answer again.")
+		return
+	}
+	if !ok {
+		f.renderMessage(w, http.StatusNotFound, "Nothing to answer", "This question was already answered or is no longer waiting.")
+		return
+	}
+	text := strings.TrimSpace(r.PostForm.Get("choice"))
+	if text == "" {
+		text = strings.TrimSpace(r.PostForm.Get("text"))
+	}
+	if text == "" {
+		http.Redirect(w, r, replyFormPath(requestID), http.StatusSeeOther)
+		return
+	}
+
+	select {
+	case pending.replies <- contract.Reply{RequestID: requestID, Text: text, ReceivedAt: time.Now().UTC()}:
+		f.renderMessage(w, http.StatusOK, "Answer sent", "You can close this tab.")
+	default:
+		f.renderMessage(w, http.StatusConflict, "Already answered", "This question already has an answer.")
+	}
+}
+
+func (f *replyForm) renderMessage(w http.ResponseWriter, status int, title, message string) {
+	renderDesktopPage(w, status, desktopMessageTemplate, desktopMessageView{Title: title, Message: message, Index: f.index})
+}
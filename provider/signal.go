@@ -0,0 +1,249 @@
+package provider
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/AlhasanIQ/consult-human/config"
+	"github.com/AlhasanIQ/consult-human/contract"
+)
+
+const (
+	signalDefaultPollInterval = 2 * time.Second
+	signalRequestTimeout      = 30 * time.Second
+)
+
+// signalAPIHint is added to errors reaching signal-cli-rest-api, which has to
+// run next to consult-human.
+const signalAPIHint = "start signal-cli-rest-api with `docker run -d -p 8080:8080 -v $HOME/.local/share/signal-cli:/home/.local/share/signal-cli -e MODE=normal bbernhard/signal-cli-rest-api`, register or link signal.number there, and set signal.api_url if it does not listen on " + config.DefaultSignalAPIURL
+
+// SignalProvider asks through a local signal-cli-rest-api instance. Replies
+// are polled from /v1/receive and matched by the quoted message, a request
+// ID in the text, or, with a single question waiting, any message.
+type SignalProvider struct {
+	apiURL       string
+	number       string
+	recipient    string
+	httpClient   *http.Client
+	pollInterval time.Duration
+	store        *signalStore
+}
+
+// signalEnvelope is one entry of GET /v1/receive/<number>.
+type signalEnvelope struct {
+	Envelope struct {
+		Source       string `json:"source"`
+		SourceNumber string `json:"sourceNumber"`
+		Timestamp    int64  `json:"timestamp"`
+		DataMessage  *struct {
+			Message string `json:"message"`
+			Quote   *struct {
+				ID int64 `json:"id"`
+			} `json:"quote"`
+			GroupInfo *struct {
+				GroupID string `json:"groupId"`
+			} `json:"groupInfo"`
+		} `json:"dataMessage"`
+	} `json:"envelope"`
+}
+
+func NewSignal(cfg config.Config) (*SignalProvider, error) {
+	number := strings.TrimSpace(cfg.Signal.Number)
+	recipient := strings.TrimSpace(cfg.Signal.Recipient)
+	if number == "" || recipient == "" {
+		return nil, fmt.Errorf(
+			"signal.number and signal.recipient are required.\n" +
+				"First-time Signal setup:\n" +
+				"1) Run signal-cli-rest-api: `docker run -d -p 8080:8080 -v $HOME/.local/share/signal-cli:/home/.local/share/signal-cli -e MODE=normal bbernhard/signal-cli-rest-api`\n" +
+				"2) Register a number for consult-human there, or link it to an existing Signal account\n" +
+				"3) Run: `consult-human setup --provider signal`",
+		)
+	}
+	storePath, err := config.DefaultSignalStorePath()
+	if err != nil {
+		return nil, err
+	}
+	pollInterval := signalDefaultPollInterval
+	if cfg.Signal.PollIntervalSeconds > 0 {
+		pollInterval = time.Duration(cfg.Signal.PollIntervalSeconds) * time.Second
+	}
+	return &SignalProvider{
+		apiURL:       config.EffectiveSignalAPIURL(cfg),
+		number:       number,
+		recipient:    recipient,
+		httpClient:   &http.Client{Timeout: signalRequestTimeout},
+		pollInterval: pollInterval,
+		store:        newSignalStore(storePath),
+	}, nil
+}
+
+func (p *SignalProvider) Name() string { return "signal" }
+
+func (p *SignalProvider) Close() error { return nil }
+
+func (p *SignalProvider) Send(ctx context.Context, req contract.AskRequest) (string, error) {
+	body, err := json.Marshal(map[string]any{
+		"message":    RenderPrompt(req),
+		"number":     p.number,
+		"recipients": []string{p.recipient},
+	})
+	if err != nil {
+		return "", err
+	}
+	var decoded struct {
+		Timestamp json.RawMessage `json:"timestamp"`
+	}
+	if err := p.do(ctx, http.MethodPost, "/v2/send", body, &decoded); err != nil {
+		return "", err
+	}
+	// The timestamp comes back as a string from some versions and as a
+	// number from others.
+	ts, err := strconv.ParseInt(strings.Trim(string(decoded.Timestamp), `"`), 10, 64)
+	if err != nil || ts <= 0 {
+		return "", fmt.Errorf("signal send returned no message timestamp")
+	}
+
+	expiresAt := time.Now().UTC().Add(signalPendingTTL)
+	if deadline, ok := ctx.Deadline(); ok {
+		expiresAt = deadline.UTC().Add(time.Minute)
+	}
+	if err := p.store.Register(signalPendingRecord{
+		RequestID: req.RequestID,
+		Recipient: p.recipient,
+		Timestamp: ts,
+		ExpiresAt: expiresAt,
+	}); err != nil {
+		return "", err
+	}
+	return strconv.FormatInt(ts, 10), nil
+}
+
+// Receive polls signal-cli for new messages, parks them in the shared store
+// and claims the one answering requestID. Another ask process may be the one
+// that pulls the answer off signal-cli; it is still claimed from the store.
+func (p *SignalProvider) Receive(ctx context.Context, requestID string) (contract.Reply, error) {
+	for {
+		msg, err := p.store.Claim(requestID)
+		if err != nil {
+			return contract.Reply{}, err
+		}
+		if msg != nil {
+			return contract.Reply{
+				RequestID:         requestID,
+				Text:              msg.Text,
+				Raw:               msg.Text,
+				From:              msg.Source,
+				ProviderMessageID: strconv.FormatInt(msg.Timestamp, 10),
+				ReceivedAt:        time.UnixMilli(msg.Timestamp).UTC(),
+			}, nil
+		}
+
+		msgs, err := p.receive(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				_ = p.store.Remove(requestID)
+				return contract.Reply{}, ctx.Err()
+			}
+			return contract.Reply{}, err
+		}
+		if len(msgs) > 0 {
+			if err := p.store.Append(msgs); err != nil {
+				return contract.Reply{}, err
+			}
+			continue
+		}
+
+		timer := time.NewTimer(p.pollInterval)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			_ = p.store.Remove(requestID)
+			return contract.Reply{}, ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// receive fetches the text messages waiting on signal-cli. Receipts, typing
+// notices and group messages are dropped.
+func (p *SignalProvider) receive(ctx context.Context) ([]signalMessage, error) {
+	var envelopes []signalEnvelope
+	if err := p.do(ctx, http.MethodGet, "/v1/receive/"+url.PathEscape(p.number), nil, &envelopes); err != nil {
+		return nil, err
+	}
+	now := time.Now().UTC()
+	msgs := make([]signalMessage, 0, len(envelopes))
+	for _, env := range envelopes {
+		data := env.Envelope.DataMessage
+		if data == nil || data.GroupInfo != nil || strings.TrimSpace(data.Message) == "" {
+			continue
+		}
+		source := env.Envelope.SourceNumber
+		if source == "" {
+			source = env.Envelope.Source
+		}
+		msg := signalMessage{
+			Source:     source,
+			Timestamp:  env.Envelope.Timestamp,
+			Text:       strings.TrimSpace(data.Message),
+			ReceivedAt: now,
+		}
+		if data.Quote != nil {
+			msg.QuoteID = data.Quote.ID
+		}
+		msgs = append(msgs, msg)
+	}
+	return msgs, nil
+}
+
+func (p *SignalProvider) do(ctx context.Context, method, path string, body []byte, out any) error {
+	var reader io.Reader
+	if body != nil {
+		reader = bytes.NewReader(body)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, p.apiURL+path, reader)
+	if err != nil {
+		return err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		return fmt.Errorf("could not reach signal-cli-rest-api at %s: %w; %s", p.apiURL, err, signalAPIHint)
+	}
+	defer resp.Body.Close()
+	b, err := io.ReadAll(io.LimitReader(resp.Body, 4<<20))
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		var apiErr struct {
+			Error string `json:"error"`
+		}
+		msg := strings.TrimSpace(string(b))
+		if json.Unmarshal(b, &apiErr) == nil && apiErr.Error != "" {
+			msg = apiErr.Error
+		}
+		return fmt.Errorf("signal-cli-rest-api %s %s status %d: %s", method, path, resp.StatusCode, msg)
+	}
+	if out == nil || len(bytes.TrimSpace(b)) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(b, out); err != nil {
+		return fmt.Errorf("decode signal-cli-rest-api %s response: %w", path, err)
+	}
+	return nil
+}
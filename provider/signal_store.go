@@ -0,0 +1,225 @@
+package provider
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/AlhasanIQ/consult-human/filelock"
+)
+
+const (
+	signalMessageTTL = 20 * time.Minute
+	signalPendingTTL = 24 * time.Hour
+)
+
+// signalPendingRecord is a question waiting for an answer. Timestamp is the
+// sent message's timestamp in milliseconds, which is how Signal quotes refer
+// to it.
+type signalPendingRecord struct {
+	RequestID string    `json:"request_id"`
+	Recipient string    `json:"recipient"`
+	Timestamp int64     `json:"timestamp"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// signalMessage is an incoming message that no process has claimed yet.
+// QuoteID is the timestamp of the message it quotes, 0 for none.
+type signalMessage struct {
+	Source     string    `json:"source"`
+	Timestamp  int64     `json:"timestamp"`
+	QuoteID    int64     `json:"quote_id,omitempty"`
+	Text       string    `json:"text"`
+	ReceivedAt time.Time `json:"received_at"`
+}
+
+type signalStoreState struct {
+	Pending  []signalPendingRecord `json:"pending"`
+	Messages []signalMessage       `json:"messages"`
+}
+
+// signalStore shares pending questions and received messages between ask
+// processes: signal-cli hands each message to whichever process polls first,
+// so messages are parked here until the process they answer claims them.
+type signalStore struct {
+	path string
+	lock string
+}
+
+func newSignalStore(path string) *signalStore {
+	return &signalStore{path: path, lock: path + ".lock"}
+}
+
+func (s *signalStore) Register(rec signalPendingRecord) error {
+	return s.withLock(func() error {
+		state, err := s.loadPrunedLocked(time.Now())
+		if err != nil {
+			return err
+		}
+		out := state.Pending[:0]
+		for _, existing := range state.Pending {
+			if existing.RequestID != rec.RequestID {
+				out = append(out, existing)
+			}
+		}
+		state.Pending = append(out, rec)
+		return s.saveLocked(state)
+	})
+}
+
+func (s *signalStore) Remove(requestID string) error {
+	return s.withLock(func() error {
+		state, err := s.loadPrunedLocked(time.Now())
+		if err != nil {
+			return err
+		}
+		out := state.Pending[:0]
+		for _, rec := range state.Pending {
+			if rec.RequestID != requestID {
+				out = append(out, rec)
+			}
+		}
+		state.Pending = out
+		return s.saveLocked(state)
+	})
+}
+
+// Append parks messages received by this process for any process to claim.
+func (s *signalStore) Append(msgs []signalMessage) error {
+	if len(msgs) == 0 {
+		return nil
+	}
+	return s.withLock(func() error {
+		state, err := s.loadPrunedLocked(time.Now())
+		if err != nil {
+			return err
+		}
+		state.Messages = append(state.Messages, msgs...)
+		return s.saveLocked(state)
+	})
+}
+
+// Claim takes the message that answers requestID: one quoting the question
+// first, then one carrying the request ID, then, while it is the only
+// question waiting on that recipient, the earliest unquoted message sent
+// after it. The record is removed with the claimed message.
+func (s *signalStore) Claim(requestID string) (*signalMessage, error) {
+	var claimed *signalMessage
+	err := s.withLock(func() error {
+		state, err := s.loadPrunedLocked(time.Now())
+		if err != nil {
+			return err
+		}
+		var rec *signalPendingRecord
+		waiting := 0
+		for i := range state.Pending {
+			if state.Pending[i].RequestID == requestID {
+				rec = &state.Pending[i]
+			}
+		}
+		if rec == nil {
+			return fmt.Errorf("unknown request id %q", requestID)
+		}
+		for _, other := range state.Pending {
+			if other.Recipient == rec.Recipient {
+				waiting++
+			}
+		}
+
+		idx := -1
+		for i, msg := range state.Messages {
+			if msg.Source == rec.Recipient && msg.QuoteID == rec.Timestamp {
+				idx = i
+				break
+			}
+		}
+		if idx < 0 {
+			for i, msg := range state.Messages {
+				if msg.Source != rec.Recipient {
+					continue
+				}
+				if text, ok := stripRequestIDToken(msg.Text, requestID); ok {
+					state.Messages[i].Text = text
+					idx = i
+					break
+				}
+			}
+		}
+		if idx < 0 && waiting == 1 {
+			for i, msg := range state.Messages {
+				if msg.Source == rec.Recipient && msg.QuoteID == 0 && msg.Timestamp > rec.Timestamp {
+					idx = i
+					break
+				}
+			}
+		}
+		if idx < 0 {
+			return nil
+		}
+
+		msg := state.Messages[idx]
+		claimed = &msg
+		state.Messages = append(state.Messages[:idx], state.Messages[idx+1:]...)
+		out := state.Pending[:0]
+		for _, p := range state.Pending {
+			if p.RequestID != requestID {
+				out = append(out, p)
+			}
+		}
+		state.Pending = out
+		return s.saveLocked(state)
+	})
+	return claimed, err
+}
+
+func (s *signalStore) withLock(fn func() error) error {
+	return filelock.Lock{Path: s.lock, Name: "signal store"}.With(fn)
+}
+
+// loadPrunedLocked drops expired questions and messages nobody claimed in
+// time.
+func (s *signalStore) loadPrunedLocked(now time.Time) (signalStoreState, error) {
+	b, err := os.ReadFile(s.path)
+	if err != nil && !os.IsNotExist(err) {
+		return signalStoreState{}, err
+	}
+	var state signalStoreState
+	if len(b) > 0 {
+		if err := json.Unmarshal(b, &state); err != nil {
+			return signalStoreState{}, fmt.Errorf("parse signal store: %w", err)
+		}
+	}
+
+	pending := state.Pending[:0]
+	for _, rec := range state.Pending {
+		if rec.ExpiresAt.IsZero() || rec.ExpiresAt.After(now) {
+			pending = append(pending, rec)
+		}
+	}
+	state.Pending = pending
+	messages := state.Messages[:0]
+	for _, msg := range state.Messages {
+		if now.Sub(msg.ReceivedAt) < signalMessageTTL {
+			messages = append(messages, msg)
+		}
+	}
+	state.Messages = messages
+	return state, nil
+}
+
+func (s *signalStore) saveLocked(state signalStoreState) error {
+	b, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, b, 0o600); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, s.path); err != nil {
+		_ = os.Remove(tmp)
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,174 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/AlhasanIQ/consult-human/config"
+	"github.com/AlhasanIQ/consult-human/contract"
+)
+
+// signalAPIMock stands in for signal-cli-rest-api: sends get increasing
+// timestamps and /v1/receive hands out queued envelopes once.
+type signalAPIMock struct {
+	mu       sync.Mutex
+	nextTS   int64
+	sent     []map[string]any
+	received [][]string
+}
+
+func (m *signalAPIMock) handler(t *testing.T) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		m.mu.Lock()
+		defer m.mu.Unlock()
+		switch {
+		case r.Method == http.MethodPost && r.URL.Path == "/v2/send":
+			var body map[string]any
+			if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+				t.Errorf("decode send body: %v", err)
+			}
+			m.sent = append(m.sent, body)
+			m.nextTS += 1000
+			w.WriteHeader(http.StatusCreated)
+			_, _ = w.Write([]byte(`{"timestamp":"` + strconv.FormatInt(m.nextTS, 10) + `"}`))
+		case r.Method == http.MethodGet && r.URL.Path == "/v1/receive/+15550001111":
+			batch := "[]"
+			if len(m.received) > 0 {
+				batch = "[" + strings.Join(m.received[0], ",") + "]"
+				m.received = m.received[1:]
+			}
+			_, _ = w.Write([]byte(batch))
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}
+}
+
+func signalEnvelopeJSON(source string, ts, quoteID int64, text string) string {
+	data := map[string]any{"message": text}
+	if quoteID != 0 {
+		data["quote"] = map[string]any{"id": quoteID}
+	}
+	b, _ := json.Marshal(map[string]any{"envelope": map[string]any{"sourceNumber": source, "timestamp": ts, "dataMessage": data}})
+	return string(b)
+}
+
+func newTestSignalProvider(t *testing.T, srvURL, storePath string) *SignalProvider {
+	t.Helper()
+	return &SignalProvider{
+		apiURL:       srvURL,
+		number:       "+15550001111",
+		recipient:    "+15552223333",
+		httpClient:   http.DefaultClient,
+		pollInterval: 5 * time.Millisecond,
+		store:        newSignalStore(storePath),
+	}
+}
+
+func TestSignalReceiveMatchesQuotedReplyAcrossProcesses(t *testing.T) {
+	m := &signalAPIMock{nextTS: 1700000000000}
+	srv := httptest.NewServer(m.handler(t))
+	defer srv.Close()
+	storePath := filepath.Join(t.TempDir(), "signal-pending.json")
+	first := newTestSignalProvider(t, srv.URL, storePath)
+	second := newTestSignalProvider(t, srv.URL, storePath)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	ts1, err := first.Send(ctx, contract.AskRequest{RequestID: "req-1", Question: "Deploy?"})
+	if err != nil {
+		t.Fatalf("Send returned error: %v", err)
+	}
+	if _, err := second.Send(ctx, contract.AskRequest{RequestID: "req-2", Question: "Which region?"}); err != nil {
+		t.Fatalf("Send returned error: %v", err)
+	}
+	if ts1 != "1700000001000" {
+		t.Fatalf("expected the send timestamp, got %q", ts1)
+	}
+	if got := m.sent[0]["recipients"]; len(got.([]any)) != 1 || got.([]any)[0] != "+15552223333" {
+		t.Fatalf("unexpected recipients: %#v", got)
+	}
+
+	// The first process pulls both replies off signal-cli; the one quoting
+	// the second question must still reach the second process.
+	m.received = [][]string{{
+		signalEnvelopeJSON("+15559999999", 1700000002500, 0, "spam"),
+		signalEnvelopeJSON("+15552223333", 1700000003000, 1700000002000, "eu-west"),
+		signalEnvelopeJSON("+15552223333", 1700000004000, 1700000001000, "yes"),
+	}}
+	reply, err := first.Receive(ctx, "req-1")
+	if err != nil {
+		t.Fatalf("Receive returned error: %v", err)
+	}
+	if reply.Text != "yes" || reply.From != "+15552223333" || reply.ProviderMessageID != "1700000004000" {
+		t.Fatalf("unexpected reply for req-1: %#v", reply)
+	}
+	reply, err = second.Receive(ctx, "req-2")
+	if err != nil {
+		t.Fatalf("Receive returned error: %v", err)
+	}
+	if reply.Text != "eu-west" {
+		t.Fatalf("unexpected reply for req-2: %#v", reply)
+	}
+}
+
+func TestSignalReceiveSinglePendingTakesUnquotedReply(t *testing.T) {
+	m := &signalAPIMock{nextTS: 1700000000000}
+	srv := httptest.NewServer(m.handler(t))
+	defer srv.Close()
+	p := newTestSignalProvider(t, srv.URL, filepath.Join(t.TempDir(), "signal-pending.json"))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if _, err := p.Send(ctx, contract.AskRequest{RequestID: "req-1", Question: "Deploy?"}); err != nil {
+		t.Fatalf("Send returned error: %v", err)
+	}
+	group := `{"envelope":{"sourceNumber":"+15552223333","timestamp":1700000001500,"dataMessage":{"message":"group chatter","groupInfo":{"groupId":"abc"}}}}`
+	m.received = [][]string{{}, {group, `{"envelope":{"sourceNumber":"+15552223333","timestamp":1700000001600,"receiptMessage":{}}}`}, {signalEnvelopeJSON("+15552223333", 1700000002000, 0, "ship it")}}
+
+	reply, err := p.Receive(ctx, "req-1")
+	if err != nil {
+		t.Fatalf("Receive returned error: %v", err)
+	}
+	if reply.Text != "ship it" {
+		t.Fatalf("unexpected reply: %#v", reply)
+	}
+}
+
+func TestSignalUnreachableAPIExplainsSetup(t *testing.T) {
+	srv := httptest.NewServer(http.NotFoundHandler())
+	srvURL := srv.URL
+	srv.Close()
+	p := newTestSignalProvider(t, srvURL, filepath.Join(t.TempDir(), "signal-pending.json"))
+
+	_, err := p.Send(context.Background(), contract.AskRequest{RequestID: "req-1", Question: "Deploy?"})
+	if err == nil || !strings.Contains(err.Error(), "could not reach signal-cli-rest-api") || !strings.Contains(err.Error(), "bbernhard/signal-cli-rest-api") {
+		t.Fatalf("expected setup guidance in the error, got %v", err)
+	}
+}
+
+func TestFactoryUsesSignal(t *testing.T) {
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+	cfg := config.Default()
+	if _, err := New(cfg, "signal"); err == nil || !strings.Contains(err.Error(), "signal.number and signal.recipient are required") {
+		t.Fatalf("expected a missing config error, got %v", err)
+	}
+	cfg.Signal.Number = "+15550001111"
+	cfg.Signal.Recipient = "+15552223333"
+	p, err := New(cfg, "signal")
+	if err != nil {
+		t.Fatalf("New returned error: %v", err)
+	}
+	if p.Name() != "signal" {
+		t.Fatalf("expected signal provider, got %q", p.Name())
+	}
+}
@@ -0,0 +1,301 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/AlhasanIQ/consult-human/config"
+	"github.com/AlhasanIQ/consult-human/contract"
+)
+
+const (
+	slackDefaultPollInterval = 2 * time.Second
+	slackRepliesLimit        = 200
+	slackRequestTimeout      = 30 * time.Second
+)
+
+// SlackProvider posts questions to a channel with a bot token and takes the
+// first human message in the question's thread as the answer. Replies are
+// read by polling conversations.replies, so no public URL is needed.
+type SlackProvider struct {
+	token        string
+	channel      string
+	apiBaseURL   string
+	httpClient   *http.Client
+	pollInterval time.Duration
+
+	mu      sync.Mutex
+	pending map[string]slackThread
+}
+
+// slackThread is where a question was posted: the channel ID Slack resolved
+// and the message timestamp that identifies its thread.
+type slackThread struct {
+	Channel string
+	TS      string
+}
+
+// slackAPIError is an "ok": false answer from the Web API, such as
+// not_in_channel or invalid_auth.
+type slackAPIError struct {
+	Method string
+	Code   string
+}
+
+func (e *slackAPIError) Error() string {
+	return fmt.Sprintf("slack %s failed: %s", e.Method, e.Code)
+}
+
+// slackRateLimitedError is a 429 answer carrying Slack's Retry-After.
+type slackRateLimitedError struct {
+	RetryAfter time.Duration
+}
+
+func (e *slackRateLimitedError) Error() string {
+	return fmt.Sprintf("slack rate limited; retry after %s", e.RetryAfter)
+}
+
+type slackMessage struct {
+	Type    string `json:"type"`
+	Subtype string `json:"subtype"`
+	User    string `json:"user"`
+	BotID   string `json:"bot_id"`
+	Text    string `json:"text"`
+	TS      string `json:"ts"`
+}
+
+func NewSlack(cfg config.Config) (*SlackProvider, error) {
+	token := strings.TrimSpace(cfg.Slack.BotToken)
+	if token == "" {
+		return nil, fmt.Errorf(
+			"slack.bot_token is required.\n" +
+				"First-time Slack setup:\n" +
+				"1) Create a Slack app with the chat:write and channels:history (groups:history for private channels) bot scopes\n" +
+				"2) Install it to your workspace and copy the xoxb- bot token\n" +
+				"3) Run: `consult-human setup --provider slack`",
+		)
+	}
+	channel := strings.TrimSpace(cfg.Slack.Channel)
+	if channel == "" {
+		return nil, fmt.Errorf("slack.channel is required; invite the bot to a channel and run `consult-human config set slack.channel <CHANNEL_ID>`")
+	}
+	pollInterval := slackDefaultPollInterval
+	if cfg.Slack.PollIntervalSeconds > 0 {
+		pollInterval = time.Duration(cfg.Slack.PollIntervalSeconds) * time.Second
+	}
+	return &SlackProvider{
+		token:        token,
+		channel:      channel,
+		apiBaseURL:   config.DefaultSlackAPIBaseURL,
+		httpClient:   &http.Client{Timeout: slackRequestTimeout},
+		pollInterval: pollInterval,
+		pending:      make(map[string]slackThread),
+	}, nil
+}
+
+func (p *SlackProvider) Name() string { return "slack" }
+
+func (p *SlackProvider) Close() error { return nil }
+
+func (p *SlackProvider) Send(ctx context.Context, req contract.AskRequest) (string, error) {
+	var decoded struct {
+		Channel string `json:"channel"`
+		TS      string `json:"ts"`
+	}
+	params := url.Values{
+		"channel":      {p.channel},
+		"text":         {RenderSlackPrompt(req)},
+		"unfurl_links": {"false"},
+		"unfurl_media": {"false"},
+	}
+	if err := p.call(ctx, "chat.postMessage", params, &decoded); err != nil {
+		var apiErr *slackAPIError
+		if errors.As(err, &apiErr) && apiErr.Code == "not_in_channel" {
+			return "", fmt.Errorf("%w; invite the bot with /invite in %s", err, p.channel)
+		}
+		return "", err
+	}
+	if decoded.TS == "" {
+		return "", fmt.Errorf("slack chat.postMessage returned no message ts")
+	}
+	thread := slackThread{Channel: decoded.Channel, TS: decoded.TS}
+	if thread.Channel == "" {
+		thread.Channel = p.channel
+	}
+
+	p.mu.Lock()
+	p.pending[req.RequestID] = thread
+	p.mu.Unlock()
+	return decoded.TS, nil
+}
+
+// Receive polls the question's thread until a human posts in it, backing off
+// as Slack asks when rate limited.
+func (p *SlackProvider) Receive(ctx context.Context, requestID string) (contract.Reply, error) {
+	p.mu.Lock()
+	thread, ok := p.pending[requestID]
+	p.mu.Unlock()
+	if !ok {
+		return contract.Reply{}, fmt.Errorf("unknown request id %q", requestID)
+	}
+	defer func() {
+		// A canceled wait keeps the thread for NotifyAnsweredElsewhere.
+		if ctx.Err() != nil {
+			return
+		}
+		p.mu.Lock()
+		delete(p.pending, requestID)
+		p.mu.Unlock()
+	}()
+
+	for {
+		wait := p.pollInterval
+		msg, found, err := p.firstThreadReply(ctx, thread)
+		var rateErr *slackRateLimitedError
+		switch {
+		case errors.As(err, &rateErr):
+			wait = max(rateErr.RetryAfter, p.pollInterval)
+		case err != nil:
+			if ctx.Err() != nil {
+				return contract.Reply{}, ctx.Err()
+			}
+			return contract.Reply{}, err
+		case found:
+			text := slackUnescape(strings.TrimSpace(msg.Text))
+			return contract.Reply{
+				RequestID:         requestID,
+				Text:              text,
+				Raw:               text,
+				From:              msg.User,
+				ProviderMessageID: msg.TS,
+				ReceivedAt:        slackTSTime(msg.TS),
+			}, nil
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return contract.Reply{}, ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// NotifyAnsweredElsewhere posts in the question's thread that a broadcast
+// question was answered through winner.
+func (p *SlackProvider) NotifyAnsweredElsewhere(ctx context.Context, requestID, winner string) error {
+	p.mu.Lock()
+	thread, ok := p.pending[requestID]
+	delete(p.pending, requestID)
+	p.mu.Unlock()
+	if !ok {
+		return fmt.Errorf("unknown request id %q", requestID)
+	}
+	params := url.Values{
+		"channel":   {thread.Channel},
+		"thread_ts": {thread.TS},
+		"text":      {fmt.Sprintf("Answered via %s, no reply needed here.", winner)},
+	}
+	return p.call(ctx, "chat.postMessage", params, nil)
+}
+
+// firstThreadReply returns the earliest message in the thread written by a
+// person, skipping the question itself, bot posts and channel events.
+func (p *SlackProvider) firstThreadReply(ctx context.Context, thread slackThread) (slackMessage, bool, error) {
+	var decoded struct {
+		Messages []slackMessage `json:"messages"`
+	}
+	params := url.Values{
+		"channel": {thread.Channel},
+		"ts":      {thread.TS},
+		"limit":   {strconv.Itoa(slackRepliesLimit)},
+	}
+	if err := p.call(ctx, "conversations.replies", params, &decoded); err != nil {
+		return slackMessage{}, false, err
+	}
+	for _, msg := range decoded.Messages {
+		if msg.TS == thread.TS || msg.BotID != "" || msg.Subtype != "" || msg.User == "" {
+			continue
+		}
+		if strings.TrimSpace(msg.Text) == "" {
+			continue
+		}
+		return msg, true, nil
+	}
+	return slackMessage{}, false, nil
+}
+
+// call posts a form-encoded Web API request and decodes the answer into out
+// once Slack reports "ok": true.
+func (p *SlackProvider) call(ctx context.Context, method string, params url.Values, out any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, strings.TrimRight(p.apiBaseURL, "/")+"/"+method, strings.NewReader(params.Encode()))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Authorization", "Bearer "+p.token)
+
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusTooManyRequests {
+		retryAfter := time.Second
+		if n, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil && n > 0 {
+			retryAfter = time.Duration(n) * time.Second
+		}
+		return &slackRateLimitedError{RetryAfter: retryAfter}
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 4<<20))
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("slack %s status %d: %s", method, resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	var status struct {
+		OK    bool   `json:"ok"`
+		Error string `json:"error"`
+	}
+	if err := json.Unmarshal(body, &status); err != nil {
+		return fmt.Errorf("decode slack %s response: %w", method, err)
+	}
+	if !status.OK {
+		return &slackAPIError{Method: method, Code: status.Error}
+	}
+	if out == nil {
+		return nil
+	}
+	if err := json.Unmarshal(body, out); err != nil {
+		return fmt.Errorf("decode slack %s response: %w", method, err)
+	}
+	return nil
+}
+
+var slackUnescaper = strings.NewReplacer("&lt;", "<", "&gt;", ">", "&amp;", "&")
+
+// slackUnescape undoes the &, < and > escaping Slack applies to message text.
+func slackUnescape(text string) string {
+	return slackUnescaper.Replace(text)
+}
+
+// slackTSTime reads a message timestamp such as "1700000000.123456".
+func slackTSTime(ts string) time.Time {
+	secs, err := strconv.ParseFloat(ts, 64)
+	if err != nil || secs <= 0 {
+		return time.Now().UTC()
+	}
+	return time.UnixMicro(int64(secs * 1e6)).UTC()
+}
@@ -0,0 +1,201 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/AlhasanIQ/consult-human/config"
+	"github.com/AlhasanIQ/consult-human/contract"
+)
+
+// slackAPIMock answers chat.postMessage with a fixed ts and serves each
+// conversations.replies call the next entry of replies, repeating the last.
+type slackAPIMock struct {
+	mu          sync.Mutex
+	posted      []map[string]string
+	replies     []string
+	repliesSeen int
+	postError   string
+	rateLimited int
+}
+
+func (m *slackAPIMock) handler(t *testing.T) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("Authorization"); got != "Bearer xoxb-test" {
+			t.Errorf("unexpected authorization header %q", got)
+		}
+		if err := r.ParseForm(); err != nil {
+			t.Errorf("ParseForm: %v", err)
+		}
+		m.mu.Lock()
+		defer m.mu.Unlock()
+		switch strings.TrimPrefix(r.URL.Path, "/") {
+		case "chat.postMessage":
+			m.posted = append(m.posted, map[string]string{"channel": r.FormValue("channel"), "thread_ts": r.FormValue("thread_ts"), "text": r.FormValue("text")})
+			if m.postError != "" {
+				_ = json.NewEncoder(w).Encode(map[string]any{"ok": false, "error": m.postError})
+				return
+			}
+			_ = json.NewEncoder(w).Encode(map[string]any{"ok": true, "channel": "C123", "ts": "1700000000.000100"})
+		case "conversations.replies":
+			if r.FormValue("ts") != "1700000000.000100" {
+				t.Errorf("unexpected thread ts %q", r.FormValue("ts"))
+			}
+			if m.rateLimited > 0 {
+				m.rateLimited--
+				w.Header().Set("Retry-After", "0")
+				w.WriteHeader(http.StatusTooManyRequests)
+				return
+			}
+			i := min(m.repliesSeen, len(m.replies)-1)
+			m.repliesSeen++
+			_, _ = w.Write([]byte(m.replies[i]))
+		default:
+			t.Errorf("unexpected method %s", r.URL.Path)
+		}
+	}
+}
+
+func newTestSlackProvider(t *testing.T, m *slackAPIMock) *SlackProvider {
+	t.Helper()
+	srv := httptest.NewServer(m.handler(t))
+	t.Cleanup(srv.Close)
+	return &SlackProvider{
+		token:        "xoxb-test",
+		channel:      "C123",
+		apiBaseURL:   srv.URL,
+		httpClient:   srv.Client(),
+		pollInterval: 5 * time.Millisecond,
+		pending:      make(map[string]slackThread),
+	}
+}
+
+const slackQuestionOnly = `{"ok":true,"messages":[{"type":"message","user":"UBOT","bot_id":"B1","text":"question","ts":"1700000000.000100"}]}`
+
+func TestSlackReceiveTakesFirstHumanThreadReply(t *testing.T) {
+	m := &slackAPIMock{replies: []string{
+		slackQuestionOnly,
+		`{"ok":true,"messages":[
+			{"type":"message","user":"UBOT","bot_id":"B1","text":"question","ts":"1700000000.000100"},
+			{"type":"message","subtype":"channel_join","user":"U2","text":"joined","ts":"1700000001.000000"},
+			{"type":"message","user":"UBOT","bot_id":"B1","text":"reminder","ts":"1700000002.000000"},
+			{"type":"message","user":"U1","text":"use &lt;main&gt; &amp; ship","ts":"1700000003.000000"},
+			{"type":"message","user":"U3","text":"later","ts":"1700000004.000000"}
+		]}`,
+	}}
+	p := newTestSlackProvider(t, m)
+
+	req := contract.AskRequest{RequestID: "req-1", Question: "Which branch?", Type: contract.QuestionTypeOpen}
+	ts, err := p.Send(context.Background(), req)
+	if err != nil {
+		t.Fatalf("Send returned error: %v", err)
+	}
+	if ts != "1700000000.000100" {
+		t.Fatalf("expected the message ts, got %q", ts)
+	}
+	if len(m.posted) != 1 || m.posted[0]["channel"] != "C123" || !strings.Contains(m.posted[0]["text"], "Which branch?") {
+		t.Fatalf("unexpected posted messages: %#v", m.posted)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	reply, err := p.Receive(ctx, "req-1")
+	if err != nil {
+		t.Fatalf("Receive returned error: %v", err)
+	}
+	if reply.Text != "use <main> & ship" || reply.From != "U1" || reply.ProviderMessageID != "1700000003.000000" {
+		t.Fatalf("unexpected reply: %#v", reply)
+	}
+	if got := reply.ReceivedAt.Unix(); got != 1700000003 {
+		t.Fatalf("expected the reply time from its ts, got %d", got)
+	}
+}
+
+func TestSlackReceiveWaitsOutRateLimit(t *testing.T) {
+	m := &slackAPIMock{rateLimited: 2, replies: []string{
+		`{"ok":true,"messages":[{"type":"message","user":"U1","text":"yes","ts":"1700000005.000000"}]}`,
+	}}
+	p := newTestSlackProvider(t, m)
+	if _, err := p.Send(context.Background(), contract.AskRequest{RequestID: "req-1", Question: "Ship?"}); err != nil {
+		t.Fatalf("Send returned error: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	reply, err := p.Receive(ctx, "req-1")
+	if err != nil {
+		t.Fatalf("Receive returned error: %v", err)
+	}
+	if reply.Text != "yes" {
+		t.Fatalf("unexpected reply: %#v", reply)
+	}
+}
+
+func TestSlackReceiveStopsAtDeadline(t *testing.T) {
+	p := newTestSlackProvider(t, &slackAPIMock{replies: []string{slackQuestionOnly}})
+	if _, err := p.Send(context.Background(), contract.AskRequest{RequestID: "req-1", Question: "Ship?"}); err != nil {
+		t.Fatalf("Send returned error: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if _, err := p.Receive(ctx, "req-1"); err != context.DeadlineExceeded {
+		t.Fatalf("expected the deadline error, got %v", err)
+	}
+}
+
+func TestSlackNotifyAnsweredElsewhereAfterCanceledReceive(t *testing.T) {
+	m := &slackAPIMock{replies: []string{slackQuestionOnly}}
+	p := newTestSlackProvider(t, m)
+	if _, err := p.Send(context.Background(), contract.AskRequest{RequestID: "req-1", Question: "Ship?"}); err != nil {
+		t.Fatalf("Send returned error: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := p.Receive(ctx, "req-1"); err != context.Canceled {
+		t.Fatalf("expected the cancel error, got %v", err)
+	}
+	if err := p.NotifyAnsweredElsewhere(context.Background(), "req-1", "telegram"); err != nil {
+		t.Fatalf("NotifyAnsweredElsewhere returned error: %v", err)
+	}
+	if len(m.posted) != 2 || m.posted[1]["thread_ts"] != "1700000000.000100" || !strings.Contains(m.posted[1]["text"], "Answered via telegram") {
+		t.Fatalf("expected a note in the question's thread, got %#v", m.posted)
+	}
+	if err := p.NotifyAnsweredElsewhere(context.Background(), "req-1", "telegram"); err == nil {
+		t.Fatalf("expected the thread to be forgotten after the note")
+	}
+}
+
+func TestSlackSendExplainsNotInChannel(t *testing.T) {
+	p := newTestSlackProvider(t, &slackAPIMock{postError: "not_in_channel"})
+	_, err := p.Send(context.Background(), contract.AskRequest{RequestID: "req-1", Question: "Ship?"})
+	if err == nil || !strings.Contains(err.Error(), "not_in_channel") || !strings.Contains(err.Error(), "/invite") {
+		t.Fatalf("expected a not_in_channel error with an /invite hint, got %v", err)
+	}
+}
+
+func TestFactoryUsesSlack(t *testing.T) {
+	cfg := config.Default()
+	if _, err := New(cfg, "slack"); err == nil || !strings.Contains(err.Error(), "slack.bot_token is required") {
+		t.Fatalf("expected a missing token error, got %v", err)
+	}
+	cfg.Slack.BotToken = "xoxb-test"
+	if _, err := New(cfg, "slack"); err == nil || !strings.Contains(err.Error(), "slack.channel is required") {
+		t.Fatalf("expected a missing channel error, got %v", err)
+	}
+	cfg.Slack.Channel = "C123"
+	p, err := New(cfg, "slack")
+	if err != nil {
+		t.Fatalf("New returned error: %v", err)
+	}
+	if p.Name() != "slack" {
+		t.Fatalf("expected slack provider, got %q", p.Name())
+	}
+}
@@ -4,35 +4,142 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"math/rand/v2"
+	"mime/multipart"
+	"net"
 	"net/http"
+	"net/url"
 	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
 	"strings"
 	"sync"
+	"text/template"
 	"time"
+	"unicode/utf8"
 
 	"github.com/AlhasanIQ/consult-human/config"
 	"github.com/AlhasanIQ/consult-human/contract"
 )
 
-const telegramReplyReminderCooldown = 20 * time.Second
+const telegramDefaultReminderCooldown = 20 * time.Second
+const telegramReminderExcerptRunes = 80
 const telegramPendingExpiryGrace = 15 * time.Second
 
+const telegramWithdrawnText = "This question was withdrawn, no reply needed."
+
+const telegramAnsweredLocallyPrefix = "Answered locally: "
+
+const (
+	telegramAnsweredMarker       = "✅ Answered: "
+	telegramAnsweredExcerptRunes = 80
+	telegramAnsweredVoiceSummary = "🎤 voice message"
+	telegramAnsweredPhotoSummary = "🖼 photo"
+	telegramMarkAnsweredTimeout  = 5 * time.Second
+	telegramCancelledMarker      = "❌ Cancelled"
+)
+
+const telegramCancelChoiceText = "Several questions are waiting. Reply /cancel to the one you want to drop:"
+
+var telegramAllowedUpdates = []string{"message", "edited_message", "callback_query", "message_reaction", "poll_answer"}
+
+const (
+	telegramCallbackDataPrefix   = "ch"
+	telegramCallbackDataMaxBytes = 64
+)
+
+const (
+	telegramMaxMessageRunes        = 4096
+	telegramMaxDocumentBytes       = 50 << 20
+	telegramQuestionAttachmentName = "question.txt"
+)
+
+const (
+	telegramDefaultPriorityPingAfter = 5 * time.Minute
+	telegramQuestionExcerptMaxRunes  = 120
+)
+
+const (
+	telegramPollBackoffBase         = time.Second
+	telegramPollBackoffMax          = 30 * time.Second
+	telegramDefaultUnreachableAfter = 5 * time.Minute
+)
+
+const (
+	telegramDefaultSendRetries = 3
+	telegramSendRetryBaseDelay = 500 * time.Millisecond
+	telegramSendRetryMaxDelay  = 8 * time.Second
+	telegramRateLimitRetries   = 3
+)
+
+const (
+	telegramChatActionTyping         = "typing"
+	telegramChatActionUploadDocument = "upload_document"
+	telegramChatActionTimeout        = 3 * time.Second
+)
+
+// Telegram allows about one message per second per chat.
+const telegramChatPacing = time.Second
+
 type TelegramProvider struct {
-	chatID       int64
-	pollInterval time.Duration
-	baseURL      string
-	client       *http.Client
-	pendingStore *telegramPendingStore
-	inboxStore   *telegramInboxStore
-	pollerLock   *telegramPollerLock
+	chatID            int64
+	pollInterval      time.Duration
+	baseURL           string
+	fileBaseURL       string
+	client            *http.Client
+	sendRetries       int
+	retryBaseDelay    time.Duration
+	pollBackoffBase   time.Duration
+	unreachableAfter  time.Duration
+	pingAfter         time.Duration
+	remindAfter       time.Duration
+	strictReply       bool
+	longMessageMode   string
+	parseMode         string
+	markAnswered      bool
+	autoPersistChatID bool
+	notifyTimeout     bool
+	typingIndicator   bool
+	webhookMode       bool
+	autoDeleteWebhook bool
+	groupMode         bool
+	reactions         map[string]string
+	chatPacing        time.Duration
+	allowlist         telegramAllowlist
+	mediaDir          string
+	transcribeCommand string
+	dedupeWindow      time.Duration
+	reminderCooldown  time.Duration
+	reminderMax       int
+	reminderTemplate  *template.Template
+	countdownInterval time.Duration
+	codeBlockMaxLines int
+	pendingStore      *telegramPendingStore
+	answeredStore     *telegramPendingStore
+	inboxStore        *telegramInboxStore
+	recentStore       *telegramRecentStore
+	pollerLock        *telegramPollerLock
+	rateLimiter       *telegramRateLimiter
+	status            io.Writer
 
 	mu             sync.Mutex
 	nextUpdateID   int64
 	pending        map[string]int64
-	lastReminderAt time.Time
+	lastReminderAt map[int64]time.Time
+	remindersSent  map[string]int
+	lastStatusAt   map[int64]time.Time
 	pollingChecked bool
+	nextChatSend   map[int64]time.Time
+	allowNoticed   map[int64]bool
+	bot            telegramUser
+	adminChecked   map[int64]bool
+
+	mentionUsername string
+	mentionRe       *regexp.Regexp
 }
 
 func NewTelegram(cfg config.Config) (*TelegramProvider, error) {
@@ -51,31 +158,144 @@ func NewTelegram(cfg config.Config) (*TelegramProvider, error) {
 	if err != nil {
 		return nil, err
 	}
+	answeredStore, err := newTelegramAnsweredStore(cfg)
+	if err != nil {
+		return nil, err
+	}
 	inboxStore, err := newTelegramInboxStore(cfg)
 	if err != nil {
 		return nil, err
 	}
+	recentStore, err := newTelegramRecentStore(cfg)
+	if err != nil {
+		return nil, err
+	}
 	pollerLock, err := newTelegramPollerLock(cfg)
 	if err != nil {
 		return nil, err
 	}
+	rateLimiter, err := newTelegramRateLimiter(cfg)
+	if err != nil {
+		return nil, err
+	}
+	mediaDir, err := config.EffectiveTelegramMediaDir(cfg)
+	if err != nil {
+		return nil, err
+	}
 
 	pollSeconds := cfg.Telegram.PollIntervalSeconds
 	if pollSeconds <= 0 {
 		pollSeconds = 2
 	}
+	sendRetries := cfg.Telegram.SendRetries
+	if sendRetries < 0 {
+		sendRetries = telegramDefaultSendRetries
+	}
+	pingAfter := telegramDefaultPriorityPingAfter
+	if raw := strings.TrimSpace(cfg.Telegram.PriorityPingAfter); raw != "" {
+		d, err := time.ParseDuration(raw)
+		if err != nil {
+			return nil, fmt.Errorf("invalid telegram.priority_ping_after %q: %w", raw, err)
+		}
+		pingAfter = d
+	}
+	var remindAfter time.Duration
+	if raw := strings.TrimSpace(cfg.Telegram.RemindAfter); raw != "" {
+		d, err := time.ParseDuration(raw)
+		if err != nil {
+			return nil, fmt.Errorf("invalid telegram.remind_after %q: %w", raw, err)
+		}
+		remindAfter = d
+	}
+	apiBaseURL, err := config.EffectiveTelegramAPIBaseURL(cfg)
+	if err != nil {
+		return nil, err
+	}
+	unreachableAfter := telegramDefaultUnreachableAfter
+	if raw := strings.TrimSpace(cfg.Telegram.UnreachableAfter); raw != "" {
+		d, err := time.ParseDuration(raw)
+		if err != nil {
+			return nil, fmt.Errorf("invalid telegram.unreachable_after %q: %w", raw, err)
+		}
+		unreachableAfter = d
+	}
+	reactions, err := config.EffectiveTelegramReactions(cfg)
+	if err != nil {
+		return nil, err
+	}
+	dedupeWindow := telegramDefaultDedupeWindow
+	if raw := strings.TrimSpace(cfg.Telegram.DedupeWindow); raw != "" {
+		d, err := time.ParseDuration(raw)
+		if err != nil {
+			return nil, fmt.Errorf("invalid telegram.dedupe_window %q: %w", raw, err)
+		}
+		dedupeWindow = d
+	}
+	reminderCooldown := telegramDefaultReminderCooldown
+	if raw := strings.TrimSpace(cfg.Telegram.Reminder.Cooldown); raw != "" {
+		d, err := time.ParseDuration(raw)
+		if err != nil {
+			return nil, fmt.Errorf("invalid telegram.reminder.cooldown %q: %w", raw, err)
+		}
+		reminderCooldown = d
+	}
+	codeBlockMaxLines := telegramDefaultCodeBlockMaxLines
+	if cfg.Telegram.CodeBlockMaxLines > 0 {
+		codeBlockMaxLines = cfg.Telegram.CodeBlockMaxLines
+	}
+	if cfg.Telegram.InlineCodeBlocks {
+		codeBlockMaxLines = 0
+	}
+	var reminderTemplate *template.Template
+	if raw := cfg.Telegram.Reminder.TextTemplate; strings.TrimSpace(raw) != "" {
+		reminderTemplate, err = config.ParseTelegramReminderTemplate(raw)
+		if err != nil {
+			return nil, err
+		}
+	}
 
 	return &TelegramProvider{
 		chatID:       cfg.Telegram.ChatID,
 		pollInterval: time.Duration(pollSeconds) * time.Second,
-		baseURL:      fmt.Sprintf("https://api.telegram.org/bot%s", token),
+		baseURL:      fmt.Sprintf("%s/bot%s", apiBaseURL, token),
+		fileBaseURL:  fmt.Sprintf("%s/file/bot%s", apiBaseURL, token),
 		client: &http.Client{
 			Timeout: 45 * time.Second,
 		},
-		pending:      make(map[string]int64),
-		pendingStore: pendingStore,
-		inboxStore:   inboxStore,
-		pollerLock:   pollerLock,
+		sendRetries:       sendRetries,
+		retryBaseDelay:    telegramSendRetryBaseDelay,
+		pollBackoffBase:   telegramPollBackoffBase,
+		unreachableAfter:  unreachableAfter,
+		pingAfter:         pingAfter,
+		remindAfter:       remindAfter,
+		strictReply:       cfg.Telegram.StrictReply,
+		longMessageMode:   cfg.Telegram.LongMessageMode,
+		parseMode:         cfg.Telegram.ParseMode,
+		markAnswered:      cfg.Telegram.MarkAnswered == nil || *cfg.Telegram.MarkAnswered,
+		autoPersistChatID: cfg.Telegram.AutoPersistChatID == nil || *cfg.Telegram.AutoPersistChatID,
+		notifyTimeout:     cfg.Telegram.NotifyTimeout == nil || *cfg.Telegram.NotifyTimeout,
+		typingIndicator:   cfg.Telegram.TypingIndicator == nil || *cfg.Telegram.TypingIndicator,
+		webhookMode:       cfg.Telegram.ReceiveMode == config.TelegramReceiveModeWebhook,
+		autoDeleteWebhook: cfg.Telegram.AutoDeleteWebhook,
+		groupMode:         cfg.Telegram.GroupMode,
+		reactions:         reactions,
+		chatPacing:        telegramChatPacing,
+		allowlist:         newTelegramAllowlist(cfg.Telegram),
+		mediaDir:          mediaDir,
+		transcribeCommand: strings.TrimSpace(cfg.Telegram.TranscribeCommand),
+		dedupeWindow:      dedupeWindow,
+		reminderCooldown:  reminderCooldown,
+		reminderMax:       cfg.Telegram.Reminder.MaxPerRequest,
+		reminderTemplate:  reminderTemplate,
+		countdownInterval: telegramCountdownInterval,
+		codeBlockMaxLines: codeBlockMaxLines,
+		pending:           make(map[string]int64),
+		pendingStore:      pendingStore,
+		answeredStore:     answeredStore,
+		inboxStore:        inboxStore,
+		recentStore:       recentStore,
+		pollerLock:        pollerLock,
+		rateLimiter:       rateLimiter,
 	}, nil
 }
 
@@ -83,85 +303,782 @@ func (p *TelegramProvider) Name() string { return "telegram" }
 
 func (p *TelegramProvider) Close() error { return nil }
 
+// SetStatus sends progress notes to w instead of stderr; warnings still go
+// to stderr.
+func (p *TelegramProvider) SetStatus(w io.Writer) { p.status = w }
+
+func (p *TelegramProvider) statusOut() io.Writer {
+	if p.status == nil {
+		return os.Stderr
+	}
+	return p.status
+}
+
 func (p *TelegramProvider) Send(ctx context.Context, req contract.AskRequest) (string, error) {
-	if err := p.ensureLongPollingReady(ctx); err != nil {
-		return "", err
+	if !p.webhookMode {
+		if err := p.ensureLongPollingReady(ctx); err != nil {
+			return "", err
+		}
 	}
 	if err := p.ensureChatID(ctx); err != nil {
 		return "", err
 	}
 
-	chatID := p.chatIDValue()
-	prompt := RenderTelegramPrompt(req)
-	messageID, err := p.sendTelegramMessage(ctx, chatID, prompt, true)
+	chatID := p.chatIDValue()
+	if req.Poll {
+		if p.pendingStore == nil {
+			return "", fmt.Errorf("telegram polls require the shared pending store")
+		}
+	} else if p.attachToDuplicate(ctx, chatID, req) {
+		return req.RequestID, nil
+	}
+	opts := telegramSendOptions{
+		ForceReply:     true,
+		Silent:         req.Silent,
+		InlineKeyboard: telegramChoiceKeyboard(req),
+	}
+	if req.FollowUpTo != "" {
+		if prior, ok := p.lookupAnswered(req.FollowUpTo); ok && prior.ChatID == chatID {
+			opts.ReplyToMessageID = prior.MessageID
+		} else {
+			fmt.Fprintf(os.Stderr, "warning: follow-up request %q not found; sending as a new question\n", req.FollowUpTo)
+		}
+	} else if req.Session != "" {
+		// An unknown or expired session just starts a new thread.
+		if prev, ok := p.lookupSession(chatID, req.Session); ok {
+			opts.ReplyToMessageID = prev.MessageID
+		}
+	}
+	if req.Poll {
+		if err := p.sendPollQuestion(ctx, chatID, req, opts); err != nil {
+			return "", err
+		}
+		return req.RequestID, nil
+	}
+	p.sendChatAction(ctx, chatID, telegramChatActionTyping)
+	sent, err := p.sendTelegramPrompt(ctx, chatID, req, opts)
+	if err != nil {
+		return "", err
+	}
+
+	now := time.Now().UTC()
+	rec := telegramPendingRecord{
+		RequestID:  req.RequestID,
+		ChatID:     chatID,
+		MessageID:  sent.ID,
+		PromptText: sent.Text,
+		ParseMode:  sent.ParseMode,
+		CreatedAt:  now,
+		ExpiresAt:  now.Add(telegramPendingLegacyTTL),
+		Priority:   string(req.Priority),
+		Question:   questionExcerpt(req.Question, telegramQuestionExcerptMaxRunes),
+		Tags:       req.Tags,
+		Session:    req.Session,
+		Reactions:  p.reactionAnswers(req),
+		Origin:     req.Origin,
+		Silent:     req.Silent,
+	}
+	if req.ShowDeadline {
+		rec.ShowDeadline = true
+		rec.Keyboard = opts.InlineKeyboard
+	}
+	if dl, ok := ctx.Deadline(); ok {
+		rec.ExpiresAt = dl.UTC().Add(telegramPendingExpiryGrace)
+	}
+	if len(rec.Reactions) > 0 {
+		p.checkReactionsDelivered(ctx, chatID)
+	}
+	if d := p.followUpDelay(req.Priority); d > 0 {
+		rec.PingAt = now.Add(d)
+	}
+
+	if err := p.registerPending(rec); err != nil {
+		return "", err
+	}
+	p.rememberQuestion(chatID, req, now)
+
+	return req.RequestID, nil
+}
+
+// Defer records req as held back until the given time, for /status to list.
+func (p *TelegramProvider) Defer(ctx context.Context, req contract.AskRequest, until time.Time) error {
+	chatID := p.chatIDValue()
+	if p.pendingStore == nil || chatID == 0 {
+		return nil
+	}
+	now := time.Now().UTC()
+	rec := telegramPendingRecord{
+		RequestID:     req.RequestID,
+		ChatID:        chatID,
+		CreatedAt:     now,
+		ExpiresAt:     until.UTC().Add(telegramPendingExpiryGrace),
+		OwnerPID:      os.Getpid(),
+		OwnerHost:     telegramLocalHostname,
+		Priority:      string(req.Priority),
+		Question:      questionExcerpt(req.Question, telegramQuestionExcerptMaxRunes),
+		Tags:          req.Tags,
+		Session:       req.Session,
+		Origin:        req.Origin,
+		DeferredUntil: until,
+	}
+	if dl, ok := ctx.Deadline(); ok {
+		rec.ExpiresAt = dl.UTC().Add(telegramPendingExpiryGrace)
+	}
+	return p.pendingStore.Upsert(rec)
+}
+
+// Both waiters share one message and receive the same reply.
+func (p *TelegramProvider) attachToDuplicate(ctx context.Context, chatID int64, req contract.AskRequest) bool {
+	if p.dedupeWindow <= 0 || p.recentStore == nil || p.pendingStore == nil || p.inboxStore == nil {
+		return false
+	}
+	recent, ok, err := p.recentStore.Lookup(telegramQuestionKey(chatID, req), p.dedupeWindow, time.Now().UTC())
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "warning: telegram recent-questions store read failed: %v\n", err)
+		return false
+	}
+	if !ok || recent.ChatID != chatID {
+		return false
+	}
+	orig, ok, err := p.pendingStore.Get(recent.RequestID)
+	if err != nil || !ok || orig.ChatID != chatID || orig.MessageID == 0 {
+		return false
+	}
+
+	now := time.Now().UTC()
+	rec := telegramPendingRecord{
+		RequestID:   req.RequestID,
+		ChatID:      chatID,
+		MessageID:   orig.MessageID,
+		PromptText:  orig.PromptText,
+		ParseMode:   orig.ParseMode,
+		CreatedAt:   now,
+		ExpiresAt:   now.Add(telegramPendingLegacyTTL),
+		Priority:    string(req.Priority),
+		Question:    questionExcerpt(req.Question, telegramQuestionExcerptMaxRunes),
+		Tags:        req.Tags,
+		DuplicateOf: orig.RequestID,
+		Session:     req.Session,
+		Reactions:   p.reactionAnswers(req),
+		Origin:      req.Origin,
+		Silent:      req.Silent,
+	}
+	if dl, ok := ctx.Deadline(); ok {
+		rec.ExpiresAt = dl.UTC().Add(telegramPendingExpiryGrace)
+	}
+	if err := p.registerPending(rec); err != nil {
+		return false
+	}
+	fmt.Fprintf(p.statusOut(), "note: identical question is already pending as %s; waiting on the same message\n", orig.RequestID)
+	return true
+}
+
+func (p *TelegramProvider) rememberQuestion(chatID int64, req contract.AskRequest, askedAt time.Time) {
+	if p.dedupeWindow <= 0 || p.recentStore == nil {
+		return
+	}
+	q := telegramRecentQuestion{RequestID: req.RequestID, ChatID: chatID, AskedAt: askedAt}
+	if err := p.recentStore.Remember(telegramQuestionKey(chatID, req), q, p.dedupeWindow); err != nil {
+		fmt.Fprintf(os.Stderr, "warning: telegram recent-questions store write failed: %v\n", err)
+	}
+}
+
+// Copies are marked shared so they are not passed on again.
+func (p *TelegramProvider) shareReply(rec telegramPendingRecord, claimed telegramInboxEntry) {
+	if claimed.Shared || p.pendingStore == nil || p.inboxStore == nil {
+		return
+	}
+	waiters, err := p.pendingStore.ListByMessage(rec.ChatID, rec.MessageID)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "warning: telegram pending store read failed: %v\n", err)
+		return
+	}
+	others := 0
+	for _, w := range waiters {
+		if w.RequestID != rec.RequestID {
+			others++
+		}
+	}
+	if err := p.inboxStore.ShareReply(claimed, rec.MessageID, others); err != nil {
+		fmt.Fprintf(os.Stderr, "warning: telegram inbox store write failed: %v\n", err)
+	}
+}
+
+func (p *TelegramProvider) Receive(ctx context.Context, requestID string) (contract.Reply, error) {
+	rec, err := p.lookupPending(requestID)
+	if err != nil {
+		return contract.Reply{}, err
+	}
+	defer func() {
+		// A canceled or timed-out wait keeps the record so Cancel or
+		// NotifyTimeout can still find the question.
+		if ctx.Err() == nil {
+			p.clearPending(requestID)
+			p.cleanupThreadingReminders(rec.ChatID)
+		}
+	}()
+
+	if rec.PollID != "" {
+		reply, err := p.receivePoll(ctx, rec)
+		if err != nil {
+			return contract.Reply{}, err
+		}
+		p.recordAnswered(rec)
+		p.stopTelegramPoll(rec)
+		return reply, nil
+	}
+
+	stopCountdown := p.startCountdown(ctx, rec)
+	reply, err := p.receivePending(ctx, rec)
+	stopCountdown()
+	if err != nil {
+		return contract.Reply{}, err
+	}
+	p.recordAnswered(rec)
+	if rec.ShowDeadline && !p.markAnswered {
+		p.markQuestion(rec, telegramCountdownAnsweredText)
+	}
+	p.markQuestionAnswered(rec, reply)
+	return reply, nil
+}
+
+func (p *TelegramProvider) AwaitCorrection(ctx context.Context, reply contract.Reply) (contract.Reply, bool, error) {
+	messageID, err := strconv.ParseInt(reply.ProviderMessageID, 10, 64)
+	if err != nil || p.inboxStore == nil || p.pollerLock == nil {
+		return contract.Reply{}, false, nil
+	}
+	rec, ok := p.lookupAnswered(reply.RequestID)
+	if !ok {
+		return contract.Reply{}, false, nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, telegramEditCorrectionWindow)
+	defer cancel()
+	for {
+		correction, err := p.inboxStore.TakeCorrection(rec.ChatID, messageID)
+		if err != nil {
+			return contract.Reply{}, false, err
+		}
+		if correction != nil {
+			corrected := reply
+			corrected.Text = correction.Text
+			corrected.Raw = correction.Text
+			corrected.Edited = true
+			p.markQuestionAnswered(rec, corrected)
+			return corrected, true, nil
+		}
+
+		if ctx.Err() != nil {
+			return contract.Reply{}, false, nil
+		}
+
+		polled, err := p.pollInboxOnce(ctx)
+		if err != nil && ctx.Err() == nil {
+			return contract.Reply{}, false, err
+		}
+		if !polled {
+			sleepWithContext(ctx, telegramPollerWaitInterval)
+		}
+	}
+}
+
+// Best-effort: Telegram refuses edits after 48 hours or when nothing changed.
+func (p *TelegramProvider) markQuestionAnswered(rec telegramPendingRecord, reply contract.Reply) {
+	if !p.markAnswered || rec.MessageID == 0 {
+		return
+	}
+	summary := reply.Text
+	if summary == "" && len(reply.Attachments) > 0 {
+		summary = telegramAnsweredVoiceSummary
+		if strings.HasPrefix(reply.Attachments[0].MimeType, "image/") {
+			summary = telegramAnsweredPhotoSummary
+		}
+	}
+	p.markQuestion(rec, telegramAnsweredMarker+questionExcerpt(summary, telegramAnsweredExcerptRunes))
+}
+
+func (p *TelegramProvider) markQuestionCancelled(rec telegramPendingRecord) {
+	if !p.markAnswered || rec.MessageID == 0 {
+		return
+	}
+	p.markQuestion(rec, telegramCancelledMarker)
+}
+
+func (p *TelegramProvider) markQuestion(rec telegramPendingRecord, status string) {
+	ctx, cancel := context.WithTimeout(context.Background(), telegramMarkAnsweredTimeout)
+	defer cancel()
+
+	if err := p.editQuestion(ctx, rec, status, nil); err != nil {
+		var statusErr *telegramStatusError
+		if errors.As(err, &statusErr) && statusErr.StatusCode == http.StatusBadRequest {
+			return
+		}
+		fmt.Fprintf(os.Stderr, "warning: telegram could not mark question %s: %v\n", rec.RequestID, err)
+	}
+}
+
+// A nil keyboard removes the inline keyboard.
+func (p *TelegramProvider) editQuestion(ctx context.Context, rec telegramPendingRecord, status string, keyboard [][]telegramInlineButton) error {
+	method := "editMessageText"
+	payload := map[string]any{
+		"chat_id":    rec.ChatID,
+		"message_id": rec.MessageID,
+	}
+	if rec.PromptText != "" {
+		esc := telegramEscaper(rec.ParseMode)
+		payload["text"] = rec.PromptText + "\n\n" + esc(status)
+		switch rec.ParseMode {
+		case config.TelegramParseModeMarkdown:
+			payload["parse_mode"] = "MarkdownV2"
+		case config.TelegramParseModeHTML:
+			payload["parse_mode"] = "HTML"
+		}
+		if len(keyboard) > 0 {
+			payload["reply_markup"] = map[string]any{"inline_keyboard": keyboard}
+		}
+	} else {
+		// Records from older versions lack the text; just drop the buttons.
+		method = "editMessageReplyMarkup"
+		payload["reply_markup"] = map[string]any{"inline_keyboard": [][]telegramInlineButton{}}
+	}
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	_, err = p.postTelegramSend(ctx, method, "application/json", body)
+	return err
+}
+
+// nil when an ID does not fit in callback data.
+func telegramChoiceKeyboard(req contract.AskRequest) [][]telegramInlineButton {
+	if req.Type != contract.QuestionTypeChoice || len(req.Choices) == 0 {
+		return nil
+	}
+	rows := make([][]telegramInlineButton, 0, len(req.Choices))
+	for _, choice := range req.Choices {
+		data := telegramCallbackData(req.RequestID, choice.ID)
+		if len(data) > telegramCallbackDataMaxBytes {
+			return nil
+		}
+		rows = append(rows, []telegramInlineButton{{
+			Text:         fmt.Sprintf("%s) %s", choice.ID, choice.Text),
+			CallbackData: data,
+		}})
+	}
+	return rows
+}
+
+func telegramCallbackData(requestID, choiceID string) string {
+	return telegramCallbackDataPrefix + ":" + requestID + ":" + choiceID
+}
+
+func parseTelegramCallbackData(data string) (requestID, choiceID string, ok bool) {
+	prefix, rest, found := strings.Cut(data, ":")
+	if !found || prefix != telegramCallbackDataPrefix {
+		return "", "", false
+	}
+	requestID, choiceID, found = strings.Cut(rest, ":")
+	if !found || requestID == "" || choiceID == "" {
+		return "", "", false
+	}
+	return requestID, choiceID, true
+}
+
+type telegramPromptMessage struct {
+	ID        int64
+	Text      string
+	ParseMode string
+}
+
+// A formatted question Telegram cannot parse is resent once as plain text.
+func (p *TelegramProvider) sendTelegramPrompt(ctx context.Context, chatID int64, req contract.AskRequest, opts telegramSendOptions) (telegramPromptMessage, error) {
+	req, err := p.sendSpilledSnippets(ctx, chatID, req, opts)
+	if err != nil {
+		return telegramPromptMessage{}, err
+	}
+	if p.parseMode != "" && p.parseMode != config.TelegramParseModePlain {
+		msg, err := p.sendTelegramPromptAs(ctx, chatID, req, opts, p.parseMode)
+		if err == nil || !isTelegramEntityParseError(err) {
+			return msg, err
+		}
+		fmt.Fprintf(os.Stderr, "warning: telegram could not parse the %s question (%v); resending as plain text\n", p.parseMode, err)
+	}
+	return p.sendTelegramPromptAs(ctx, chatID, req, opts, config.TelegramParseModePlain)
+}
+
+func (p *TelegramProvider) renderPrompt(chatID int64, req contract.AskRequest, parseMode string) string {
+	prompt := RenderTelegramPromptFor(req, parseMode)
+	if p.groupMode && chatID < 0 {
+		prompt += "\n\n" + telegramEscaper(parseMode)(telegramGroupPromptHint)
+	}
+	return prompt
+}
+
+func (p *TelegramProvider) sendTelegramPromptAs(ctx context.Context, chatID int64, req contract.AskRequest, opts telegramSendOptions, parseMode string) (telegramPromptMessage, error) {
+	opts.ParseMode = parseMode
+	prompt := p.renderPrompt(chatID, req, parseMode)
+	if utf8.RuneCountInString(prompt) <= telegramMaxMessageRunes {
+		id, err := p.sendTelegramMessage(ctx, chatID, prompt, opts)
+		return telegramPromptMessage{ID: id, Text: prompt, ParseMode: parseMode}, err
+	}
+
+	if p.longMessageMode == config.TelegramLongMessageDocument {
+		summaryReq := req
+		summaryReq.Question = questionExcerpt(req.Question, telegramQuestionExcerptMaxRunes) + "\n\n(Full question attached as " + telegramQuestionAttachmentName + ".)"
+		summaryReq.PreFormatted = false
+		summary := p.renderPrompt(chatID, summaryReq, parseMode)
+		if utf8.RuneCountInString(summary) <= telegramMaxMessageRunes {
+			docOpts := telegramSendOptions{Silent: opts.Silent, ReplyToMessageID: opts.ReplyToMessageID}
+			p.sendChatAction(ctx, chatID, telegramChatActionUploadDocument)
+			if _, err := p.sendTelegramDocument(ctx, chatID, telegramQuestionAttachmentName, []byte(RenderTelegramPrompt(req)), "", docOpts); err != nil {
+				return telegramPromptMessage{}, err
+			}
+			opts.ReplyToMessageID = 0
+			p.sendChatAction(ctx, chatID, telegramChatActionTyping)
+			id, err := p.sendTelegramMessage(ctx, chatID, summary, opts)
+			return telegramPromptMessage{ID: id, Text: summary, ParseMode: parseMode}, err
+		}
+	}
+
+	chunks := splitTelegramText(prompt, telegramMaxMessageRunes)
+	var last telegramPromptMessage
+	for i, chunk := range chunks {
+		chunkOpts := telegramSendOptions{Silent: opts.Silent, ParseMode: parseMode}
+		if i == 0 {
+			chunkOpts.ReplyToMessageID = opts.ReplyToMessageID
+		}
+		if i == len(chunks)-1 {
+			chunkOpts.ForceReply = opts.ForceReply
+			chunkOpts.InlineKeyboard = opts.InlineKeyboard
+		}
+		if i > 0 {
+			p.sendChatAction(ctx, chatID, telegramChatActionTyping)
+		}
+		id, err := p.sendTelegramMessage(ctx, chatID, chunk, chunkOpts)
+		if err != nil {
+			return telegramPromptMessage{}, err
+		}
+		last = telegramPromptMessage{ID: id, Text: chunk, ParseMode: parseMode}
+	}
+	return last, nil
+}
+
+func isTelegramEntityParseError(err error) bool {
+	var statusErr *telegramStatusError
+	return errors.As(err, &statusErr) &&
+		statusErr.StatusCode == http.StatusBadRequest &&
+		strings.Contains(strings.ToLower(statusErr.Body), "can't parse entities")
+}
+
+func splitTelegramText(text string, limit int) []string {
+	var chunks []string
+	for utf8.RuneCountInString(text) > limit {
+		cut := len(string([]rune(text)[:limit]))
+		window := text[:cut]
+		for _, sep := range []string{"\n\n", "\n", " "} {
+			if i := strings.LastIndex(window, sep); i > 0 {
+				cut = i
+				break
+			}
+		}
+		if chunk := strings.TrimRight(text[:cut], " \n"); chunk != "" {
+			chunks = append(chunks, chunk)
+		}
+		text = strings.TrimLeft(text[cut:], " \n")
+	}
+	if text != "" {
+		chunks = append(chunks, text)
+	}
+	return chunks
+}
+
+func (p *TelegramProvider) Cancel(ctx context.Context, requestID string) error {
+	rec, err := p.lookupPending(requestID)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		p.clearPending(requestID)
+		p.cleanupThreadingReminders(rec.ChatID)
+	}()
+
+	if p.pendingStore != nil {
+		// Keep the question open while a deduplicated request still waits on it.
+		if waiters, err := p.pendingStore.ListByMessage(rec.ChatID, rec.MessageID); err == nil && len(waiters) > 1 {
+			return nil
+		}
+	}
+	if rec.PollID != "" {
+		p.stopTelegramPoll(rec)
+	}
+	_, err = p.sendTelegramMessage(ctx, rec.ChatID, telegramWithdrawnText, telegramSendOptions{
+		Silent:           true,
+		ReplyToMessageID: rec.MessageID,
+	})
+	return err
+}
+
+// NotifyTimeout drops the pending record so a later request cannot claim a late reply.
+func (p *TelegramProvider) NotifyTimeout(ctx context.Context, requestID string, waited time.Duration) error {
+	rec, err := p.lookupPending(requestID)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		p.clearPending(requestID)
+		p.cleanupThreadingReminders(rec.ChatID)
+	}()
+
+	if !p.notifyTimeout || rec.MessageID == 0 {
+		return nil
+	}
+	if p.pendingStore != nil {
+		if waiters, err := p.pendingStore.ListByMessage(rec.ChatID, rec.MessageID); err == nil && len(waiters) > 1 {
+			return nil
+		}
+	}
+	text := telegramTimedOutText(waited)
+	if rec.PollID != "" {
+		p.stopTelegramPoll(rec)
+	}
+	if rec.PromptText != "" {
+		p.markQuestion(rec, text)
+		return nil
+	}
+	_, err = p.sendTelegramMessage(ctx, rec.ChatID, text, telegramSendOptions{
+		Silent:           true,
+		ReplyToMessageID: rec.MessageID,
+	})
+	return err
+}
+
+func telegramTimedOutText(waited time.Duration) string {
+	return fmt.Sprintf("⏰ Timed out after %s — the agent proceeded without an answer", telegramAge(waited))
+}
+
+func (p *TelegramProvider) NotifyAnsweredElsewhere(ctx context.Context, requestID, winner string) error {
+	rec, err := p.lookupPending(requestID)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		p.clearPending(requestID)
+		p.cleanupThreadingReminders(rec.ChatID)
+	}()
+
+	if rec.MessageID == 0 {
+		return nil
+	}
+	if p.pendingStore != nil {
+		if waiters, err := p.pendingStore.ListByMessage(rec.ChatID, rec.MessageID); err == nil && len(waiters) > 1 {
+			return nil
+		}
+	}
+	text := telegramAnsweredElsewhereText(winner)
+	if rec.PollID != "" {
+		p.stopTelegramPoll(rec)
+	}
+	if rec.PromptText != "" {
+		p.markQuestion(rec, text)
+		return nil
+	}
+	_, err = p.sendTelegramMessage(ctx, rec.ChatID, text, telegramSendOptions{
+		Silent:           true,
+		ReplyToMessageID: rec.MessageID,
+	})
+	return err
+}
+
+func (p *TelegramProvider) Link(ctx context.Context, requestID, other string) error {
+	if p.pendingStore == nil {
+		return nil
+	}
+	return p.pendingStore.Link(requestID, other)
+}
+
+func telegramAnsweredElsewhereText(winner string) string {
+	return fmt.Sprintf("☑️ Answered via %s — no reply needed here", winner)
+}
+
+// Notify sends a one-way message to an already linked chat.
+func (p *TelegramProvider) Notify(ctx context.Context, n contract.Notification) error {
+	chatID := p.chatIDValue()
+	if chatID == 0 {
+		return fmt.Errorf("telegram chat is not linked yet; run `consult-human setup` or answer one `ask` first")
+	}
+
+	type attachment struct {
+		name    string
+		content []byte
+	}
+	attachments := make([]attachment, 0, len(n.Attachments))
+	for _, path := range n.Attachments {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("read attachment: %w", err)
+		}
+		if len(content) > telegramMaxDocumentBytes {
+			return fmt.Errorf("attachment %s is larger than telegram's %d MB limit", path, telegramMaxDocumentBytes>>20)
+		}
+		attachments = append(attachments, attachment{name: filepath.Base(path), content: content})
+	}
+
+	opts := telegramSendOptions{Silent: n.Silent}
+	if text := strings.TrimSpace(n.Text); text != "" {
+		for _, chunk := range splitTelegramText(text, telegramMaxMessageRunes) {
+			if _, err := p.sendTelegramMessage(ctx, chatID, chunk, opts); err != nil {
+				return err
+			}
+		}
+	}
+	for _, a := range attachments {
+		if _, err := p.sendTelegramDocument(ctx, chatID, a.name, a.content, "", opts); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (p *TelegramProvider) Acknowledge(ctx context.Context, reply contract.Reply, text string) error {
+	chatID := p.chatIDValue()
+	if chatID == 0 {
+		return fmt.Errorf("telegram chat is not linked")
+	}
+	replyTo, _ := strconv.ParseInt(reply.ProviderMessageID, 10, 64)
+	if !p.paceChat(ctx, chatID) {
+		return ctx.Err()
+	}
+	_, err := p.sendTelegramMessage(ctx, chatID, text, telegramSendOptions{
+		Silent:           true,
+		ReplyToMessageID: replyTo,
+	})
+	return err
+}
+
+// ReplyLocally queues a reply in the shared inbox for the waiting Receive to claim.
+func (p *TelegramProvider) ReplyLocally(ctx context.Context, requestID, text string, notify bool) error {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return fmt.Errorf("reply text is required")
+	}
+	if p.pendingStore == nil || p.inboxStore == nil {
+		return fmt.Errorf("telegram local replies require the shared pending and inbox stores")
+	}
+	rec, ok, err := p.pendingStore.Get(requestID)
 	if err != nil {
-		return "", err
+		return err
 	}
-
-	expiresAt := time.Now().UTC().Add(telegramPendingLegacyTTL)
-	if dl, ok := ctx.Deadline(); ok {
-		expiresAt = dl.UTC().Add(telegramPendingExpiryGrace)
+	if !ok || rec.ChatID == 0 || rec.MessageID == 0 {
+		return fmt.Errorf("unknown request id %q: no pending telegram question with that id", requestID)
 	}
-
-	if err := p.registerPending(req.RequestID, chatID, messageID, expiresAt); err != nil {
-		return "", err
+	if err := p.inboxStore.InjectLocalReply(rec.ChatID, rec.MessageID, text); err != nil {
+		return err
 	}
 
-	return req.RequestID, nil
-}
-
-func (p *TelegramProvider) Receive(ctx context.Context, requestID string) (contract.Reply, error) {
-	chatID, targetMessageID, err := p.lookupPending(requestID)
-	if err != nil {
-		return contract.Reply{}, err
+	if notify {
+		_, err := p.sendTelegramMessage(ctx, rec.ChatID, telegramAnsweredLocallyPrefix+text, telegramSendOptions{
+			Silent:           true,
+			ReplyToMessageID: rec.MessageID,
+		})
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "warning: telegram answered-locally notice failed: %v\n", err)
+		}
 	}
-	defer p.clearPending(requestID)
+	return nil
+}
 
+func (p *TelegramProvider) receivePending(ctx context.Context, rec telegramPendingRecord) (contract.Reply, error) {
+	requestID := rec.RequestID
+	chatID, targetMessageID := rec.ChatID, rec.MessageID
+	pingAt := rec.PingAt
 	if p.inboxStore == nil || p.pollerLock == nil {
-		return p.receiveDirect(ctx, requestID, chatID, targetMessageID)
+		return p.receiveDirect(ctx, rec)
 	}
 
+	backoff := p.newPollBackoff()
 	for {
 		select {
 		case <-ctx.Done():
 			return contract.Reply{}, ctx.Err()
 		default:
 		}
+		pingAt = p.maybeSendFollowUpPing(ctx, rec, pingAt)
 
+		strictRequestID := ""
+		if p.strictReply {
+			strictRequestID = requestID
+		}
 		pendingCount := p.pendingCountForChat(chatID)
-		claimed, needsReminder, err := p.inboxStore.ClaimForRequest(chatID, targetMessageID, pendingCount)
+		claimed, hints, err := p.inboxStore.ClaimForRequest(chatID, targetMessageID, pendingCount, strictRequestID, p.allowlist)
 		if err != nil {
 			if ctx.Err() != nil {
 				return contract.Reply{}, ctx.Err()
 			}
 			return contract.Reply{}, err
 		}
+		for _, id := range hints.RejectedCallbacks {
+			p.answerCallbackQuery(ctx, id, p.allowlist.noticeText())
+		}
+		if hints.Unauthorized {
+			p.maybeSendAllowlistNotice(chatID)
+		}
 		if claimed != nil {
+			if claimed.CallbackQueryID != "" {
+				p.answerCallbackQuery(ctx, claimed.CallbackQueryID, "")
+			}
+			if claimed.Kind == telegramInboxKindReaction && rec.Reactions[claimed.Text] == "" {
+				// A reaction that stands for no answer to this question.
+				continue
+			}
+			p.shareReply(rec, *claimed)
+			if claimed.Kind == telegramInboxKindReaction {
+				claimed.Text = rec.Reactions[claimed.Text]
+			}
+			if claimed.Kind == "" && isTelegramCancelCommand(claimed.Text) {
+				p.markQuestionCancelled(rec)
+				return contract.Reply{}, ErrCancelledByHuman
+			}
 			reply := contract.Reply{
 				RequestID:         requestID,
 				Text:              strings.TrimSpace(claimed.Text),
 				Raw:               strings.TrimSpace(claimed.Text),
 				ProviderMessageID: fmt.Sprintf("%d", claimed.MessageID),
 				ReceivedAt:        time.Unix(claimed.Date, 0).UTC(),
+				Edited:            claimed.Edited,
 			}
 			if strings.TrimSpace(claimed.Username) != "" {
 				reply.From = strings.TrimSpace(claimed.Username)
 			} else {
 				reply.From = strings.TrimSpace(strings.Join([]string{claimed.FirstName, claimed.LastName}, " "))
 			}
+			if claimed.PhotoFileID != "" {
+				p.attachPhoto(ctx, &reply, claimed.MessageID, claimed.PhotoFileID)
+			}
+			if claimed.VoiceFileID != "" {
+				p.attachVoice(ctx, &reply, claimed.MessageID, telegramVoice{
+					FileID:   claimed.VoiceFileID,
+					Duration: claimed.VoiceDuration,
+					MimeType: claimed.VoiceMimeType,
+				})
+			}
 			return reply, nil
 		}
-		if needsReminder && pendingCount > 1 {
-			p.maybeSendThreadingReminder(chatID, pendingCount)
+		if hints.NeedsReminder && (pendingCount > 1 || p.strictReply) {
+			p.maybeSendThreadingReminder(requestID, chatID, pendingCount)
+		}
+		if hints.NeedsCancelChoice {
+			p.sendCancelChoice(chatID)
 		}
 
 		polled, err := p.pollInboxOnce(ctx)
 		if err != nil {
-			if ctx.Err() != nil {
-				return contract.Reply{}, ctx.Err()
+			if err := backoff.wait(ctx, err); err != nil {
+				return contract.Reply{}, err
 			}
-			return contract.Reply{}, err
+			continue
 		}
+		backoff.reset()
 		if !polled {
 			select {
 			case <-ctx.Done():
@@ -172,23 +1089,73 @@ func (p *TelegramProvider) Receive(ctx context.Context, requestID string) (contr
 	}
 }
 
-func (p *TelegramProvider) receiveDirect(ctx context.Context, requestID string, chatID, targetMessageID int64) (contract.Reply, error) {
+func (p *TelegramProvider) receiveDirect(ctx context.Context, rec telegramPendingRecord) (contract.Reply, error) {
+	requestID, chatID, targetMessageID := rec.RequestID, rec.ChatID, rec.MessageID
+	pingAt := rec.PingAt
+	backoff := p.newPollBackoff()
 	for {
 		select {
 		case <-ctx.Done():
 			return contract.Reply{}, ctx.Err()
 		default:
 		}
+		pingAt = p.maybeSendFollowUpPing(ctx, rec, pingAt)
 
 		updates, err := p.getUpdates(ctx)
 		if err != nil {
-			if ctx.Err() != nil {
-				return contract.Reply{}, ctx.Err()
+			if err := backoff.wait(ctx, err); err != nil {
+				return contract.Reply{}, err
 			}
-			return contract.Reply{}, err
+			continue
 		}
+		backoff.reset()
 
 		for _, up := range updates {
+			if cq := up.CallbackQuery; cq != nil && cq.Message != nil && cq.Message.Chat.ID == chatID {
+				cbRequestID, choiceID, ok := parseTelegramCallbackData(cq.Data)
+				if !ok || (cbRequestID != requestID && cq.Message.MessageID != targetMessageID) {
+					continue
+				}
+				if from := cq.From; !p.allowlist.empty() && (from == nil || !p.allowlist.allows(from.ID, from.Username)) {
+					p.answerCallbackQuery(ctx, cq.ID, p.allowlist.noticeText())
+					continue
+				}
+				p.answerCallbackQuery(ctx, cq.ID, "")
+				reply := contract.Reply{
+					RequestID:         requestID,
+					Text:              choiceID,
+					Raw:               choiceID,
+					ProviderMessageID: fmt.Sprintf("%d", cq.Message.MessageID),
+					ReceivedAt:        time.Now().UTC(),
+				}
+				if cq.From != nil {
+					reply.From = telegramUserName(*cq.From)
+				}
+				return reply, nil
+			}
+
+			if mr := up.MessageReaction; mr != nil && mr.Chat.ID == chatID && mr.MessageID == targetMessageID {
+				answer := rec.Reactions[telegramReactionEmoji(mr)]
+				if answer == "" {
+					continue
+				}
+				if from := mr.User; !p.allowlist.empty() && (from == nil || !p.allowlist.allows(from.ID, from.Username)) {
+					p.maybeSendAllowlistNotice(chatID)
+					continue
+				}
+				reply := contract.Reply{
+					RequestID:         requestID,
+					Text:              answer,
+					Raw:               answer,
+					ProviderMessageID: fmt.Sprintf("%d", mr.MessageID),
+					ReceivedAt:        time.Now().UTC(),
+				}
+				if mr.User != nil {
+					reply.From = telegramUserName(*mr.User)
+				}
+				return reply, nil
+			}
+
 			msg := up.Message
 			if msg == nil {
 				continue
@@ -196,18 +1163,43 @@ func (p *TelegramProvider) receiveDirect(ctx context.Context, requestID string,
 			if msg.Chat.ID != chatID {
 				continue
 			}
-
-			text := strings.TrimSpace(msg.Text)
-			if text == "" {
+			if isTelegramStatusCommand(msg.Text) {
+				p.answerStatusCommand(up)
 				continue
 			}
 
+			text := telegramMessageText(msg)
 			matchesByReply := msg.ReplyToMessage != nil && msg.ReplyToMessage.MessageID == targetMessageID
-			if !matchesByReply {
+			// Voice messages and bare photos only count as direct replies to
+			// the question.
+			if text == "" && (!telegramMessageHasMedia(msg) || !matchesByReply) {
+				continue
+			}
+			if from := msg.From; !p.allowlist.empty() && (from == nil || !p.allowlist.allows(from.ID, from.Username)) {
+				if matchesByReply {
+					p.maybeSendAllowlistNotice(chatID)
+				}
+				continue
+			}
+			if !matchesByReply && p.strictReply {
+				if msg.MessageID <= targetMessageID {
+					continue
+				}
+				stripped, ok := stripRequestIDToken(text, requestID)
+				if !ok {
+					if msg.ReplyToMessage == nil {
+						p.maybeSendThreadingReminder(requestID, chatID, p.pendingCountForChat(chatID))
+					}
+					continue
+				}
+				text = stripped
+			} else if !matchesByReply {
 				pendingCount := p.pendingCountForChat(chatID)
 				if pendingCount > 1 {
-					if msg.ReplyToMessage == nil {
-						p.maybeSendThreadingReminder(chatID, pendingCount)
+					if msg.ReplyToMessage == nil && isTelegramCancelCommand(text) {
+						p.sendCancelChoice(chatID)
+					} else if msg.ReplyToMessage == nil {
+						p.maybeSendThreadingReminder(requestID, chatID, pendingCount)
 					}
 					continue
 				}
@@ -216,6 +1208,11 @@ func (p *TelegramProvider) receiveDirect(ctx context.Context, requestID string,
 				}
 			}
 
+			if isTelegramCancelCommand(text) {
+				p.markQuestionCancelled(rec)
+				return contract.Reply{}, ErrCancelledByHuman
+			}
+
 			reply := contract.Reply{
 				RequestID:         requestID,
 				Text:              text,
@@ -230,6 +1227,12 @@ func (p *TelegramProvider) receiveDirect(ctx context.Context, requestID string,
 					reply.From = strings.TrimSpace(strings.Join([]string{msg.From.FirstName, msg.From.LastName}, " "))
 				}
 			}
+			if len(msg.Photo) > 0 {
+				p.attachPhoto(ctx, &reply, msg.MessageID, largestTelegramPhoto(msg.Photo))
+			}
+			if text == "" && msg.Voice != nil {
+				p.attachVoice(ctx, &reply, msg.MessageID, *msg.Voice)
+			}
 			return reply, nil
 		}
 	}
@@ -245,6 +1248,12 @@ func (p *TelegramProvider) ensureChatID(ctx context.Context) error {
 	if p.chatIDValue() != 0 {
 		return nil
 	}
+	if p.webhookMode {
+		return p.waitForLinkedChatID(ctx, "while `consult-human serve telegram-webhook` is running")
+	}
+	if p.pollerLock != nil && p.pollerLock.heldByOther() {
+		return p.waitForLinkedChatID(ctx, "while `consult-human poller run` is running")
+	}
 
 	for {
 		select {
@@ -273,200 +1282,764 @@ func (p *TelegramProvider) ensureChatID(ctx context.Context) error {
 			p.mu.Lock()
 			p.chatID = msg.Chat.ID
 			p.mu.Unlock()
-			persistTelegramChatID(msg.Chat.ID)
+			p.persistChatID(msg.Chat.ID)
+			return nil
+		}
+	}
+}
+
+func (p *TelegramProvider) waitForLinkedChatID(ctx context.Context, hint string) error {
+	for {
+		if cfg, err := config.Load(); err == nil && cfg.Telegram.ChatID != 0 {
+			p.mu.Lock()
+			p.chatID = cfg.Telegram.ChatID
+			p.mu.Unlock()
 			return nil
 		}
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("telegram chat is not linked; send /start to the bot %s: %w", hint, ctx.Err())
+		case <-time.After(p.pollInterval):
+		}
+	}
+}
+
+func isTelegramStartCommand(text string) bool {
+	return isTelegramCommand(text, "/start")
+}
+
+func isTelegramCancelCommand(text string) bool {
+	return isTelegramCommand(text, "/cancel")
+}
+
+func isTelegramStatusCommand(text string) bool {
+	return isTelegramCommand(text, "/status")
+}
+
+func isTelegramCommand(text, command string) bool {
+	t := strings.ToLower(strings.TrimSpace(text))
+	if t == "" {
+		return false
+	}
+	token := strings.Fields(t)[0]
+	if token == command {
+		return true
+	}
+	return strings.HasPrefix(token, command+"@") && len(token) > len(command+"@")
+}
+
+func (p *TelegramProvider) persistChatID(chatID int64) {
+	if chatID == 0 || !p.autoPersistChatID {
+		return
+	}
+	path, err := config.SaveTelegramChatID(chatID)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "warning: linked chat %d but could not save it: %v\n", chatID, err)
+		return
+	}
+	if path != "" {
+		fmt.Fprintf(p.statusOut(), "Linked chat %d and saved it to %s\n", chatID, path)
+	}
+}
+
+func (p *TelegramProvider) registerPending(rec telegramPendingRecord) error {
+	if strings.TrimSpace(rec.RequestID) == "" || rec.ChatID == 0 || rec.MessageID == 0 {
+		return fmt.Errorf("invalid telegram pending request")
+	}
+
+	p.mu.Lock()
+	p.pending[rec.RequestID] = rec.MessageID
+	p.mu.Unlock()
+
+	if p.pendingStore == nil {
+		return nil
+	}
+
+	rec.OwnerPID = os.Getpid()
+	rec.OwnerHost = telegramLocalHostname
+	if err := p.pendingStore.Upsert(rec); err != nil {
+		p.mu.Lock()
+		delete(p.pending, rec.RequestID)
+		p.mu.Unlock()
+		return err
+	}
+	return nil
+}
+
+func (p *TelegramProvider) lookupPending(requestID string) (telegramPendingRecord, error) {
+	if p.pendingStore != nil {
+		rec, ok, err := p.pendingStore.Get(requestID)
+		if err == nil && ok && rec.ChatID != 0 && rec.MessageID != 0 {
+			return rec, nil
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "warning: telegram pending store read failed: %v\n", err)
+		}
+	}
+
+	p.mu.Lock()
+	msgID, ok := p.pending[requestID]
+	chatID := p.chatID
+	p.mu.Unlock()
+	if !ok || msgID == 0 || chatID == 0 {
+		return telegramPendingRecord{}, fmt.Errorf("unknown request id %q", requestID)
+	}
+	return telegramPendingRecord{RequestID: requestID, ChatID: chatID, MessageID: msgID}, nil
+}
+
+func (p *TelegramProvider) recordAnswered(rec telegramPendingRecord) {
+	if p.answeredStore == nil {
+		return
+	}
+	now := time.Now().UTC()
+	rec.OwnerPID = 0
+	rec.OwnerHost = ""
+	rec.PingAt = time.Time{}
+	rec.ExpiresAt = now.Add(telegramAnsweredRetention)
+	if err := p.answeredStore.Upsert(rec); err != nil {
+		fmt.Fprintf(os.Stderr, "warning: telegram answered store write failed: %v\n", err)
+	}
+}
+
+func (p *TelegramProvider) lookupAnswered(requestID string) (telegramPendingRecord, bool) {
+	if p.answeredStore != nil {
+		if rec, ok, err := p.answeredStore.Get(requestID); err == nil && ok && rec.MessageID != 0 {
+			return rec, true
+		}
+	}
+	if p.pendingStore != nil {
+		if rec, ok, err := p.pendingStore.Get(requestID); err == nil && ok && rec.MessageID != 0 {
+			return rec, true
+		}
+	}
+	return telegramPendingRecord{}, false
+}
+
+func (p *TelegramProvider) lookupSession(chatID int64, session string) (telegramPendingRecord, bool) {
+	var latest telegramPendingRecord
+	var found bool
+	for _, store := range []*telegramPendingStore{p.pendingStore, p.answeredStore} {
+		if store == nil {
+			continue
+		}
+		rec, ok, err := store.LatestBySession(chatID, session)
+		if err != nil || !ok {
+			continue
+		}
+		if !found || rec.CreatedAt.After(latest.CreatedAt) {
+			latest, found = rec, true
+		}
+	}
+	return latest, found
+}
+
+func (p *TelegramProvider) clearPending(requestID string) {
+	p.mu.Lock()
+	delete(p.pending, requestID)
+	p.mu.Unlock()
+
+	if p.pendingStore != nil {
+		if err := p.pendingStore.Delete(requestID); err != nil {
+			fmt.Fprintf(os.Stderr, "warning: telegram pending store delete failed: %v\n", err)
+		}
+	}
+}
+
+func (p *TelegramProvider) pendingCountForChat(chatID int64) int {
+	if chatID == 0 {
+		return 0
+	}
+	if p.pendingStore != nil {
+		if n, err := p.pendingStore.CountByChat(chatID); err == nil {
+			return n
+		}
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return len(p.pending)
+}
+
+func (p *TelegramProvider) maybeSendThreadingReminder(requestID string, chatID int64, pendingCount int) {
+	if pendingCount < 1 || chatID == 0 || p.reminderCooldown <= 0 {
+		return
+	}
+	p.mu.Lock()
+	exhausted := p.reminderMax > 0 && p.remindersSent[requestID] >= p.reminderMax
+	p.mu.Unlock()
+	if exhausted || !p.reserveReminder(chatID) {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if !p.paceChat(ctx, chatID) {
+		return
+	}
+	// The reminder quotes the waiting questions and replies to the oldest, so
+	// tapping it jumps back up a busy chat.
+	questions := p.waitingQuestions(chatID)
+	opts := telegramSendOptions{}
+	if rec, err := p.lookupPending(requestID); err == nil {
+		opts.Silent = rec.Silent
+	}
+	if len(questions) > 0 {
+		opts.ReplyToMessageID = questions[0].MessageID
+	}
+	messageID, err := p.sendTelegramMessage(ctx, chatID, p.threadingReminderText(pendingCount, questions, time.Now()), opts)
+	if err != nil || messageID == 0 {
+		return
+	}
+	p.mu.Lock()
+	if p.remindersSent == nil {
+		p.remindersSent = make(map[string]int)
+	}
+	p.remindersSent[requestID]++
+	p.mu.Unlock()
+	if p.inboxStore == nil {
+		return
+	}
+	if err := p.inboxStore.RecordReminder(chatID, messageID); err != nil {
+		fmt.Fprintf(os.Stderr, "warning: telegram inbox store reminder update failed: %v\n", err)
+	}
+}
+
+// With an inbox store the cooldown is shared between processes.
+func (p *TelegramProvider) reserveReminder(chatID int64) bool {
+	now := time.Now()
+	if p.inboxStore != nil {
+		reserved, err := p.inboxStore.ReserveReminder(chatID, p.reminderCooldown, now)
+		if err == nil {
+			return reserved
+		}
+		fmt.Fprintf(os.Stderr, "warning: telegram inbox store reminder update failed: %v\n", err)
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if last := p.lastReminderAt[chatID]; !last.IsZero() && now.Sub(last) < p.reminderCooldown {
+		return false
+	}
+	if p.lastReminderAt == nil {
+		p.lastReminderAt = make(map[int64]time.Time)
+	}
+	p.lastReminderAt[chatID] = now
+	return true
+}
+
+func (p *TelegramProvider) cleanupThreadingReminders(chatID int64) {
+	if p.inboxStore == nil || chatID == 0 || p.pendingCountForChat(chatID) > 1 {
+		return
+	}
+	messageIDs, err := p.inboxStore.TakeReminders(chatID)
+	if err != nil || len(messageIDs) == 0 {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	for _, messageID := range messageIDs {
+		p.deleteTelegramMessage(ctx, chatID, messageID)
+	}
+}
+
+// Best-effort: Telegram refuses deletes after 48 hours.
+func (p *TelegramProvider) deleteTelegramMessage(ctx context.Context, chatID, messageID int64) {
+	body, err := json.Marshal(map[string]any{"chat_id": chatID, "message_id": messageID})
+	if err != nil {
+		return
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, p.baseURL+"/deleteMessage", bytes.NewReader(body))
+	if err != nil {
+		return
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	resp, err := p.client.Do(httpReq)
+	if err != nil {
+		return
+	}
+	_ = resp.Body.Close()
+}
+
+func (p *TelegramProvider) sendChatAction(ctx context.Context, chatID int64, action string) {
+	if !p.typingIndicator {
+		return
+	}
+	ctx, cancel := context.WithTimeout(ctx, telegramChatActionTimeout)
+	defer cancel()
+	body, err := json.Marshal(map[string]any{"chat_id": chatID, "action": action})
+	if err != nil {
+		return
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, p.baseURL+"/sendChatAction", bytes.NewReader(body))
+	if err != nil {
+		return
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	resp, err := p.client.Do(httpReq)
+	if err != nil {
+		return
 	}
+	_ = resp.Body.Close()
 }
 
-func isTelegramStartCommand(text string) bool {
-	t := strings.ToLower(strings.TrimSpace(text))
-	if t == "" {
-		return false
-	}
-	token := strings.Fields(t)[0]
-	if token == "/start" {
+func (p *TelegramProvider) paceChat(ctx context.Context, chatID int64) bool {
+	if p.chatPacing <= 0 {
 		return true
 	}
-	return strings.HasPrefix(token, "/start@") && len(token) > len("/start@")
+	p.mu.Lock()
+	if p.nextChatSend == nil {
+		p.nextChatSend = make(map[int64]time.Time)
+	}
+	now := time.Now()
+	slot := p.nextChatSend[chatID]
+	if slot.Before(now) {
+		slot = now
+	}
+	jitter := time.Duration(rand.Int64N(int64(p.chatPacing/4) + 1))
+	p.nextChatSend[chatID] = slot.Add(p.chatPacing + jitter)
+	p.mu.Unlock()
+
+	if wait := time.Until(slot); wait > 0 {
+		return sleepWithContext(ctx, wait)
+	}
+	return true
 }
 
-func persistTelegramChatID(chatID int64) {
-	if chatID == 0 {
+func (p *TelegramProvider) maybeSendAllowlistNotice(chatID int64) {
+	p.mu.Lock()
+	if p.allowNoticed[chatID] {
+		p.mu.Unlock()
 		return
 	}
-	cfg, err := config.Load()
-	if err != nil {
-		return
+	if p.allowNoticed == nil {
+		p.allowNoticed = make(map[int64]bool)
 	}
-	if cfg.Telegram.ChatID == chatID {
+	p.allowNoticed[chatID] = true
+	p.mu.Unlock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if !p.paceChat(ctx, chatID) {
 		return
 	}
-	cfg.Telegram.ChatID = chatID
-	_ = config.Save(cfg)
+	_, _ = p.sendTelegramMessage(ctx, chatID, p.allowlist.noticeText(), telegramSendOptions{Silent: true})
 }
 
-func (p *TelegramProvider) registerPending(requestID string, chatID, messageID int64, expiresAt time.Time) error {
-	if strings.TrimSpace(requestID) == "" || chatID == 0 || messageID == 0 {
-		return fmt.Errorf("invalid telegram pending request")
+func (p *TelegramProvider) sendCancelChoice(chatID int64) {
+	var b strings.Builder
+	b.WriteString(telegramCancelChoiceText)
+	for i, rec := range p.waitingQuestions(chatID) {
+		fmt.Fprintf(&b, "\n%d. %s", i+1, questionExcerpt(rec.Question, telegramQuestionExcerptMaxRunes))
 	}
 
-	p.mu.Lock()
-	p.pending[requestID] = messageID
-	p.mu.Unlock()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if !p.paceChat(ctx, chatID) {
+		return
+	}
+	_, _ = p.sendTelegramMessage(ctx, chatID, b.String(), telegramSendOptions{})
+}
 
+// Requests sharing one message are listed once.
+func (p *TelegramProvider) waitingQuestions(chatID int64) []telegramPendingRecord {
 	if p.pendingStore == nil {
 		return nil
 	}
-
-	err := p.pendingStore.Upsert(telegramPendingRecord{
-		RequestID: requestID,
-		ChatID:    chatID,
-		MessageID: messageID,
-		CreatedAt: time.Now().UTC(),
-		ExpiresAt: expiresAt.UTC(),
-		OwnerPID:  os.Getpid(),
-		OwnerHost: telegramLocalHostname,
-	})
+	records, err := p.pendingStore.ListByChat(chatID)
 	if err != nil {
-		p.mu.Lock()
-		delete(p.pending, requestID)
-		p.mu.Unlock()
-		return err
+		return nil
 	}
-	return nil
+	seen := make(map[int64]struct{}, len(records))
+	out := records[:0]
+	for _, rec := range records {
+		if _, ok := seen[rec.MessageID]; ok {
+			continue
+		}
+		seen[rec.MessageID] = struct{}{}
+		out = append(out, rec)
+	}
+	return out
 }
 
-func (p *TelegramProvider) lookupPending(requestID string) (int64, int64, error) {
-	if p.pendingStore != nil {
-		rec, ok, err := p.pendingStore.Get(requestID)
-		if err == nil && ok && rec.ChatID != 0 && rec.MessageID != 0 {
-			return rec.ChatID, rec.MessageID, nil
-		}
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "warning: telegram pending store read failed: %v\n", err)
-		}
+func (p *TelegramProvider) threadingReminderText(pendingCount int, questions []telegramPendingRecord, now time.Time) string {
+	if p.reminderTemplate == nil {
+		return telegramThreadingReminderText(pendingCount, questions, now)
 	}
+	var b strings.Builder
+	if err := p.reminderTemplate.Execute(&b, config.TelegramReminderData{PendingCount: pendingCount}); err != nil {
+		fmt.Fprintf(os.Stderr, "warning: telegram.reminder.text_template failed: %v\n", err)
+		return telegramThreadingReminderText(pendingCount, questions, now)
+	}
+	writeTelegramReminderQuestions(&b, questions, now)
+	return b.String()
+}
 
-	p.mu.Lock()
-	msgID, ok := p.pending[requestID]
-	chatID := p.chatID
-	p.mu.Unlock()
-	if !ok || msgID == 0 || chatID == 0 {
-		return 0, 0, fmt.Errorf("unknown request id %q", requestID)
+func telegramThreadingReminderText(pendingCount int, questions []telegramPendingRecord, now time.Time) string {
+	var b strings.Builder
+	if pendingCount <= 1 {
+		b.WriteString("Please reply directly to the message you are answering.")
+	} else {
+		fmt.Fprintf(&b, "You have %d unanswered consult-human questions. Please reply directly to the exact message you are answering.", pendingCount)
 	}
-	return chatID, msgID, nil
+	writeTelegramReminderQuestions(&b, questions, now)
+	return b.String()
 }
 
-func (p *TelegramProvider) clearPending(requestID string) {
-	p.mu.Lock()
-	delete(p.pending, requestID)
-	p.mu.Unlock()
+func writeTelegramReminderQuestions(b *strings.Builder, questions []telegramPendingRecord, now time.Time) {
+	for _, rec := range questions {
+		fmt.Fprintf(b, "\n• %s%s (%s ago)", telegramOriginPrefix(rec.Origin), questionExcerpt(rec.Question, telegramReminderExcerptRunes), telegramAge(now.Sub(rec.CreatedAt)))
+	}
+}
 
-	if p.pendingStore != nil {
-		if err := p.pendingStore.Delete(requestID); err != nil {
-			fmt.Fprintf(os.Stderr, "warning: telegram pending store delete failed: %v\n", err)
-		}
+// Zero disables the follow-up.
+func (p *TelegramProvider) followUpDelay(priority contract.Priority) time.Duration {
+	d := p.remindAfter
+	if priority == contract.PriorityHigh && p.pingAfter > 0 && (d <= 0 || p.pingAfter < d) {
+		d = p.pingAfter
 	}
+	return d
 }
 
-func (p *TelegramProvider) pendingCountForChat(chatID int64) int {
-	if chatID == 0 {
-		return 0
+// The pending store makes sure only one waiting process pings.
+func (p *TelegramProvider) maybeSendFollowUpPing(ctx context.Context, rec telegramPendingRecord, pingAt time.Time) time.Time {
+	if pingAt.IsZero() || time.Now().Before(pingAt) {
+		return pingAt
 	}
 	if p.pendingStore != nil {
-		if n, err := p.pendingStore.CountByChat(chatID); err == nil {
-			return n
+		claimed, err := p.pendingStore.ClaimPing(rec.RequestID, time.Now().UTC())
+		if err != nil || !claimed {
+			return time.Time{}
 		}
 	}
-	p.mu.Lock()
-	defer p.mu.Unlock()
-	return len(p.pending)
+	if ctx.Err() != nil {
+		return time.Time{}
+	}
+
+	sendCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+	if !p.paceChat(sendCtx, rec.ChatID) {
+		return time.Time{}
+	}
+	_, _ = p.sendTelegramMessage(sendCtx, rec.ChatID, telegramFollowUpText(rec), telegramSendOptions{
+		Silent:           rec.Silent,
+		ReplyToMessageID: rec.MessageID,
+	})
+	return time.Time{}
 }
 
-func (p *TelegramProvider) maybeSendThreadingReminder(chatID int64, pendingCount int) {
-	p.mu.Lock()
-	now := time.Now()
-	if pendingCount <= 1 || chatID == 0 || (!p.lastReminderAt.IsZero() && now.Sub(p.lastReminderAt) < telegramReplyReminderCooldown) {
-		p.mu.Unlock()
-		return
+func telegramFollowUpText(rec telegramPendingRecord) string {
+	text := "Still waiting on a reply to this question."
+	if q := strings.TrimSpace(rec.Question); q != "" {
+		text = "Still waiting on: " + q
 	}
-	p.lastReminderAt = now
-	p.mu.Unlock()
+	text = telegramOriginPrefix(rec.Origin) + text
+	if rec.Priority == string(contract.PriorityHigh) {
+		text = "❗ " + text
+	}
+	return text
+}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	defer cancel()
-	_, _ = p.sendTelegramMessage(ctx, chatID, telegramThreadingReminderText(pendingCount), false)
+func telegramOriginPrefix(origin string) string {
+	if origin == "" {
+		return ""
+	}
+	return "[" + origin + "] "
 }
 
-func telegramThreadingReminderText(pendingCount int) string {
-	if pendingCount <= 1 {
-		return "Please reply directly to the message you are answering."
+func questionExcerpt(question string, maxRunes int) string {
+	line, _, _ := strings.Cut(strings.TrimSpace(question), "\n")
+	line = strings.TrimSpace(line)
+	runes := []rune(line)
+	if maxRunes > 0 && len(runes) > maxRunes {
+		return strings.TrimSpace(string(runes[:maxRunes-1])) + "…"
 	}
-	return fmt.Sprintf(
-		"You have %d unanswered consult-human questions. Please reply directly to the exact message you are answering.",
-		pendingCount,
-	)
+	return line
+}
+
+type telegramSendOptions struct {
+	ForceReply       bool
+	Silent           bool
+	ReplyToMessageID int64
+	// InlineKeyboard replaces the force-reply markup when set.
+	InlineKeyboard [][]telegramInlineButton
+	ParseMode      string
+}
+
+type telegramInlineButton struct {
+	Text         string `json:"text"`
+	CallbackData string `json:"callback_data"`
 }
 
-func (p *TelegramProvider) sendTelegramMessage(ctx context.Context, chatID int64, text string, forceReply bool) (int64, error) {
+func (p *TelegramProvider) sendTelegramMessage(ctx context.Context, chatID int64, text string, opts telegramSendOptions) (int64, error) {
 	payload := map[string]any{
 		"chat_id": chatID,
 		"text":    text,
 	}
-	if forceReply {
+	if len(opts.InlineKeyboard) > 0 {
+		payload["reply_markup"] = map[string]any{
+			"inline_keyboard": opts.InlineKeyboard,
+		}
+	} else if opts.ForceReply {
 		payload["reply_markup"] = map[string]any{
 			"force_reply": true,
 		}
 	}
+	if opts.Silent {
+		payload["disable_notification"] = true
+	}
+	switch opts.ParseMode {
+	case config.TelegramParseModeMarkdown:
+		payload["parse_mode"] = "MarkdownV2"
+	case config.TelegramParseModeHTML:
+		payload["parse_mode"] = "HTML"
+	}
+	if opts.ReplyToMessageID != 0 {
+		payload["reply_parameters"] = map[string]any{
+			"message_id":                  opts.ReplyToMessageID,
+			"allow_sending_without_reply": true,
+		}
+	}
 
 	body, err := json.Marshal(payload)
 	if err != nil {
 		return 0, err
 	}
+	return p.postTelegramSendWithRetries(ctx, "sendMessage", "application/json", body)
+}
 
-	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, p.baseURL+"/sendMessage", bytes.NewReader(body))
+func (p *TelegramProvider) sendTelegramDocument(ctx context.Context, chatID int64, filename string, content []byte, caption string, opts telegramSendOptions) (int64, error) {
+	var buf bytes.Buffer
+	mw := multipart.NewWriter(&buf)
+	fields := map[string]string{"chat_id": strconv.FormatInt(chatID, 10)}
+	if caption != "" {
+		fields["caption"] = caption
+	}
+	if opts.Silent {
+		fields["disable_notification"] = "true"
+	}
+	if opts.ReplyToMessageID != 0 {
+		fields["reply_parameters"] = fmt.Sprintf(`{"message_id":%d,"allow_sending_without_reply":true}`, opts.ReplyToMessageID)
+	}
+	for k, v := range fields {
+		if err := mw.WriteField(k, v); err != nil {
+			return 0, err
+		}
+	}
+	fw, err := mw.CreateFormFile("document", filename)
 	if err != nil {
 		return 0, err
 	}
-	httpReq.Header.Set("Content-Type", "application/json")
+	if _, err := fw.Write(content); err != nil {
+		return 0, err
+	}
+	if err := mw.Close(); err != nil {
+		return 0, err
+	}
+	return p.postTelegramSendWithRetries(ctx, "sendDocument", mw.FormDataContentType(), buf.Bytes())
+}
+
+func (p *TelegramProvider) postTelegramSendWithRetries(ctx context.Context, method, contentType string, body []byte) (int64, error) {
+	msg, err := p.postTelegramSendMessageWithRetries(ctx, method, contentType, body)
+	return msg.MessageID, err
+}
+
+func (p *TelegramProvider) postTelegramSendMessageWithRetries(ctx context.Context, method, contentType string, body []byte) (telegramMessage, error) {
+	attempt, rateLimited := 0, 0
+	for {
+		msg, err := p.postTelegramSend(ctx, method, contentType, body)
+		if err == nil {
+			return msg, nil
+		}
+		// Rate limiting has its own budget: the wait comes from Telegram and
+		// the send is expected to succeed afterwards.
+		if wait, ok := telegramRetryAfter(err); ok {
+			if rateLimited >= telegramRateLimitRetries || !p.waitRetryAfter(ctx, wait, rateLimited) {
+				return telegramMessage{}, err
+			}
+			rateLimited++
+			continue
+		}
+		if attempt >= p.sendRetries || !isTelegramRetryableError(ctx, err) {
+			return telegramMessage{}, err
+		}
+		if !sleepWithContext(ctx, telegramRetryDelay(p.retryBaseDelay, attempt)) {
+			return telegramMessage{}, err
+		}
+		attempt++
+	}
+}
+
+func (p *TelegramProvider) waitRetryAfter(ctx context.Context, wait time.Duration, attempt int) bool {
+	if wait <= 0 {
+		wait = telegramRetryDelay(p.retryBaseDelay, attempt)
+	}
+	return sleepWithContext(ctx, wait)
+}
+
+func (p *TelegramProvider) awaitRateLimit(ctx context.Context) error {
+	if p.rateLimiter == nil {
+		return nil
+	}
+	return p.rateLimiter.Wait(ctx)
+}
+
+func (p *TelegramProvider) postTelegramSend(ctx context.Context, method, contentType string, body []byte) (telegramMessage, error) {
+	if err := p.awaitRateLimit(ctx); err != nil {
+		return telegramMessage{}, err
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, p.baseURL+"/"+method, bytes.NewReader(body))
+	if err != nil {
+		return telegramMessage{}, err
+	}
+	httpReq.Header.Set("Content-Type", contentType)
 
 	resp, err := p.client.Do(httpReq)
 	if err != nil {
-		return 0, err
+		return telegramMessage{}, err
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
 		b, _ := io.ReadAll(io.LimitReader(resp.Body, 2048))
-		return 0, fmt.Errorf("telegram sendMessage status %d: %s", resp.StatusCode, strings.TrimSpace(string(b)))
+		return telegramMessage{}, newTelegramStatusError(method, resp.StatusCode, b)
 	}
 
 	var tr telegramSendResponse
 	if err := json.NewDecoder(resp.Body).Decode(&tr); err != nil {
-		return 0, err
+		return telegramMessage{}, err
 	}
 	if !tr.OK {
-		return 0, fmt.Errorf("telegram sendMessage failed")
+		return telegramMessage{}, fmt.Errorf("telegram %s failed", method)
+	}
+
+	return tr.Result, nil
+}
+
+type telegramStatusError struct {
+	Method     string
+	StatusCode int
+	Body       string
+	// RetryAfter is the wait Telegram asked for with a 429, if any.
+	RetryAfter time.Duration
+}
+
+func newTelegramStatusError(method string, statusCode int, body []byte) *telegramStatusError {
+	e := &telegramStatusError{Method: method, StatusCode: statusCode, Body: strings.TrimSpace(string(body))}
+	if statusCode == http.StatusTooManyRequests {
+		var parsed struct {
+			Parameters struct {
+				RetryAfter int `json:"retry_after"`
+			} `json:"parameters"`
+		}
+		if json.Unmarshal(body, &parsed) == nil && parsed.Parameters.RetryAfter > 0 {
+			e.RetryAfter = time.Duration(parsed.Parameters.RetryAfter) * time.Second
+		}
+	}
+	return e
+}
+
+func telegramRetryAfter(err error) (time.Duration, bool) {
+	var statusErr *telegramStatusError
+	if !errors.As(err, &statusErr) || statusErr.StatusCode != http.StatusTooManyRequests {
+		return 0, false
+	}
+	return statusErr.RetryAfter, true
+}
+
+func (e *telegramStatusError) Error() string {
+	return fmt.Sprintf("telegram %s status %d: %s", e.Method, e.StatusCode, e.Body)
+}
+
+// 4xx responses are never retried.
+func isTelegramRetryableError(ctx context.Context, err error) bool {
+	if err == nil || ctx.Err() != nil {
+		return false
+	}
+	var statusErr *telegramStatusError
+	if errors.As(err, &statusErr) {
+		return statusErr.StatusCode >= 500
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return true
+	}
+	var urlErr *url.Error
+	return errors.As(err, &urlErr)
+}
+
+func telegramRetryDelay(base time.Duration, attempt int) time.Duration {
+	if base <= 0 {
+		base = telegramSendRetryBaseDelay
+	}
+	d := base << attempt
+	if d <= 0 || d > telegramSendRetryMaxDelay {
+		d = telegramSendRetryMaxDelay
+	}
+	// Up to 50% jitter so concurrent processes don't retry in lockstep.
+	return d/2 + time.Duration(rand.Int64N(int64(d/2)+1))
+}
+
+type telegramPollBackoff struct {
+	base     time.Duration
+	budget   time.Duration
+	failures int
+	since    time.Time
+}
+
+func (p *TelegramProvider) newPollBackoff() *telegramPollBackoff {
+	return &telegramPollBackoff{base: p.pollBackoffBase, budget: p.unreachableAfter}
+}
+
+func (b *telegramPollBackoff) wait(ctx context.Context, err error) error {
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	if !isTelegramRetryableError(ctx, err) || b.budget <= 0 {
+		return err
+	}
+	now := time.Now()
+	if b.failures == 0 {
+		b.since = now
+	}
+	if now.Sub(b.since) >= b.budget {
+		return fmt.Errorf("telegram unreachable for %s: %w", b.budget, err)
+	}
+
+	delay := telegramPollBackoffMax
+	if b.failures < 16 {
+		delay = min(b.base<<b.failures, telegramPollBackoffMax)
 	}
+	b.failures++
+	if !sleepWithContext(ctx, delay) {
+		return ctx.Err()
+	}
+	return nil
+}
+
+func (b *telegramPollBackoff) reset() {
+	b.failures = 0
+}
 
-	return tr.Result.MessageID, nil
+func sleepWithContext(ctx context.Context, d time.Duration) bool {
+	if dl, ok := ctx.Deadline(); ok && time.Until(dl) < d {
+		return false
+	}
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-t.C:
+		return true
+	}
 }
 
 func (p *TelegramProvider) pollInboxOnce(ctx context.Context) (bool, error) {
-	if p.inboxStore == nil || p.pollerLock == nil {
+	// In webhook mode the serve process appends updates to the inbox.
+	if p.webhookMode || p.inboxStore == nil || p.pollerLock == nil {
 		return false, nil
 	}
 	return p.pollerLock.TryWithLock(func() error {
-		offset, err := p.inboxStore.NextOffset()
-		if err != nil {
-			return err
-		}
-		updates, _, err := p.getUpdatesWithOffset(ctx, offset)
-		if err != nil {
-			return err
-		}
-		_, _, err = p.inboxStore.AppendUpdates(updates)
+		_, err := p.pollIntoInbox(ctx)
 		return err
 	})
 }
@@ -483,8 +2056,17 @@ func (p *TelegramProvider) ensureLongPollingReady(ctx context.Context) error {
 	if err != nil {
 		return err
 	}
-	if strings.TrimSpace(webhookURL) != "" {
-		return fmt.Errorf("telegram webhook is configured; disable it before using consult-human long polling")
+	if webhookURL != "" && p.autoDeleteWebhook {
+		if err := p.deleteWebhook(ctx); err != nil {
+			return err
+		}
+		fmt.Fprintf(p.statusOut(), "note: removed telegram webhook %s so consult-human can poll (telegram.auto_delete_webhook)\n", webhookURL)
+		if webhookURL, err = p.getWebhookURL(ctx); err != nil {
+			return err
+		}
+	}
+	if webhookURL != "" {
+		return fmt.Errorf("telegram webhook is configured at %s; remove it with `consult-human config set telegram.auto_delete_webhook true`, or set telegram.receive_mode to webhook and run `consult-human serve telegram-webhook`", webhookURL)
 	}
 
 	p.mu.Lock()
@@ -493,6 +2075,40 @@ func (p *TelegramProvider) ensureLongPollingReady(ctx context.Context) error {
 	return nil
 }
 
+func (p *TelegramProvider) answerCallbackQuery(ctx context.Context, callbackQueryID, text string) {
+	payload := map[string]any{"callback_query_id": callbackQueryID}
+	if text != "" {
+		payload["text"] = text
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, p.baseURL+"/answerCallbackQuery", bytes.NewReader(body))
+	if err != nil {
+		return
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	resp, err := p.client.Do(httpReq)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "warning: telegram answerCallbackQuery failed: %v\n", err)
+		return
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		b, _ := io.ReadAll(io.LimitReader(resp.Body, 2048))
+		fmt.Fprintf(os.Stderr, "warning: telegram answerCallbackQuery status %d: %s\n", resp.StatusCode, strings.TrimSpace(string(b)))
+	}
+}
+
+func telegramUserName(u telegramUser) string {
+	if name := strings.TrimSpace(u.Username); name != "" {
+		return name
+	}
+	return strings.TrimSpace(strings.Join([]string{u.FirstName, u.LastName}, " "))
+}
+
 func (p *TelegramProvider) getWebhookURL(ctx context.Context) (string, error) {
 	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, p.baseURL+"/getWebhookInfo", bytes.NewReader([]byte("{}")))
 	if err != nil {
@@ -521,7 +2137,23 @@ func (p *TelegramProvider) getWebhookURL(ctx context.Context) (string, error) {
 	return strings.TrimSpace(wh.Result.URL), nil
 }
 
+// With an inbox store the batch is also appended to it, so other processes can claim it.
 func (p *TelegramProvider) getUpdates(ctx context.Context) ([]telegramUpdate, error) {
+	if p.inboxStore != nil {
+		offset, err := p.inboxStore.NextOffset()
+		if err != nil {
+			return nil, err
+		}
+		updates, _, err := p.getUpdatesWithOffset(ctx, offset)
+		if err != nil {
+			return nil, err
+		}
+		if _, _, err := p.inboxStore.AppendUpdates(updates); err != nil {
+			return nil, err
+		}
+		return updates, nil
+	}
+
 	p.mu.Lock()
 	offset := p.nextUpdateID
 	p.mu.Unlock()
@@ -541,6 +2173,21 @@ func (p *TelegramProvider) getUpdates(ctx context.Context) ([]telegramUpdate, er
 }
 
 func (p *TelegramProvider) getUpdatesWithOffset(ctx context.Context, offset int64) ([]telegramUpdate, int64, error) {
+	for rateLimited := 0; ; rateLimited++ {
+		updates, nextOffset, err := p.fetchUpdates(ctx, offset)
+		if wait, ok := telegramRetryAfter(err); ok && rateLimited < telegramRateLimitRetries {
+			if p.waitRetryAfter(ctx, wait, rateLimited) {
+				continue
+			}
+		}
+		if err == nil {
+			p.filterGroupUpdates(ctx, updates)
+		}
+		return updates, nextOffset, err
+	}
+}
+
+func (p *TelegramProvider) fetchUpdates(ctx context.Context, offset int64) ([]telegramUpdate, int64, error) {
 	nextOffset := offset
 
 	timeoutSeconds := int(p.pollInterval / time.Second)
@@ -552,7 +2199,7 @@ func (p *TelegramProvider) getUpdatesWithOffset(ctx context.Context, offset int6
 	payload := map[string]any{
 		"timeout":         timeoutSeconds,
 		"limit":           100,
-		"allowed_updates": []string{"message"},
+		"allowed_updates": telegramAllowedUpdates,
 	}
 	if offset > 0 {
 		payload["offset"] = offset
@@ -563,6 +2210,9 @@ func (p *TelegramProvider) getUpdatesWithOffset(ctx context.Context, offset int6
 		return nil, nextOffset, err
 	}
 
+	if err := p.awaitRateLimit(ctx); err != nil {
+		return nil, nextOffset, err
+	}
 	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, p.baseURL+"/getUpdates", bytes.NewReader(b))
 	if err != nil {
 		return nil, nextOffset, err
@@ -577,7 +2227,7 @@ func (p *TelegramProvider) getUpdatesWithOffset(ctx context.Context, offset int6
 
 	if resp.StatusCode != http.StatusOK {
 		body, _ := io.ReadAll(io.LimitReader(resp.Body, 2048))
-		return nil, nextOffset, fmt.Errorf("telegram getUpdates status %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+		return nil, nextOffset, newTelegramStatusError("getUpdates", resp.StatusCode, body)
 	}
 
 	var gr telegramGetUpdatesResponse
@@ -623,17 +2273,66 @@ type telegramWebhookInfo struct {
 }
 
 type telegramUpdate struct {
-	UpdateID int64            `json:"update_id"`
-	Message  *telegramMessage `json:"message"`
+	UpdateID        int64                           `json:"update_id"`
+	Message         *telegramMessage                `json:"message"`
+	EditedMessage   *telegramMessage                `json:"edited_message"`
+	CallbackQuery   *telegramCallbackQuery          `json:"callback_query"`
+	MessageReaction *telegramMessageReactionUpdated `json:"message_reaction"`
+	PollAnswer      *telegramPollAnswer             `json:"poll_answer"`
+}
+
+type telegramMessageReactionUpdated struct {
+	Chat        telegramChat           `json:"chat"`
+	MessageID   int64                  `json:"message_id"`
+	User        *telegramUser          `json:"user"`
+	Date        int64                  `json:"date"`
+	NewReaction []telegramReactionType `json:"new_reaction"`
+}
+
+type telegramReactionType struct {
+	Type  string `json:"type"`
+	Emoji string `json:"emoji"`
+}
+
+type telegramCallbackQuery struct {
+	ID      string           `json:"id"`
+	From    *telegramUser    `json:"from"`
+	Message *telegramMessage `json:"message"`
+	Data    string           `json:"data"`
 }
 
 type telegramMessage struct {
-	MessageID      int64            `json:"message_id"`
-	Date           int64            `json:"date"`
-	Text           string           `json:"text"`
-	Chat           telegramChat     `json:"chat"`
-	From           *telegramUser    `json:"from"`
-	ReplyToMessage *telegramMessage `json:"reply_to_message"`
+	MessageID      int64               `json:"message_id"`
+	Date           int64               `json:"date"`
+	Text           string              `json:"text"`
+	Chat           telegramChat        `json:"chat"`
+	From           *telegramUser       `json:"from"`
+	ReplyToMessage *telegramMessage    `json:"reply_to_message"`
+	Voice          *telegramVoice      `json:"voice"`
+	Photo          []telegramPhotoSize `json:"photo"`
+	Caption        string              `json:"caption"`
+	Poll           *telegramPoll       `json:"poll"`
+}
+
+type telegramPoll struct {
+	ID string `json:"id"`
+}
+
+type telegramPollAnswer struct {
+	PollID    string        `json:"poll_id"`
+	User      *telegramUser `json:"user"`
+	OptionIDs []int         `json:"option_ids"`
+}
+
+func telegramMessageText(msg *telegramMessage) string {
+	if text := strings.TrimSpace(msg.Text); text != "" {
+		return text
+	}
+	return strings.TrimSpace(msg.Caption)
+}
+
+func telegramMessageHasMedia(msg *telegramMessage) bool {
+	return msg.Voice != nil || len(msg.Photo) > 0
 }
 
 type telegramChat struct {
@@ -641,6 +2340,8 @@ type telegramChat struct {
 }
 
 type telegramUser struct {
+	ID        int64  `json:"id"`
+	IsBot     bool   `json:"is_bot"`
 	Username  string `json:"username"`
 	FirstName string `json:"first_name"`
 	LastName  string `json:"last_name"`
@@ -0,0 +1,75 @@
+package provider
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/AlhasanIQ/consult-human/config"
+)
+
+// telegramAllowlist restricts whose replies are accepted. The zero value
+// accepts everyone.
+type telegramAllowlist struct {
+	userIDs   map[int64]struct{}
+	usernames map[string]struct{}
+}
+
+func newTelegramAllowlist(cfg config.TelegramConfig) telegramAllowlist {
+	var a telegramAllowlist
+	for _, id := range cfg.AllowedUserIDs {
+		if a.userIDs == nil {
+			a.userIDs = make(map[int64]struct{})
+		}
+		a.userIDs[id] = struct{}{}
+	}
+	for _, name := range cfg.AllowedUsernames {
+		name = config.NormalizeTelegramUsername(name)
+		if name == "" {
+			continue
+		}
+		if a.usernames == nil {
+			a.usernames = make(map[string]struct{})
+		}
+		a.usernames[name] = struct{}{}
+	}
+	return a
+}
+
+func (a telegramAllowlist) empty() bool {
+	return len(a.userIDs) == 0 && len(a.usernames) == 0
+}
+
+func (a telegramAllowlist) allows(userID int64, username string) bool {
+	if a.empty() {
+		return true
+	}
+	if _, ok := a.userIDs[userID]; ok && userID != 0 {
+		return true
+	}
+	_, ok := a.usernames[config.NormalizeTelegramUsername(username)]
+	return ok && username != ""
+}
+
+// allowsEntry also trusts synthetic entries: local replies, and shared
+// copies of a reply that was already accepted.
+func (a telegramAllowlist) allowsEntry(entry telegramInboxEntry) bool {
+	return entry.UpdateID < 0 || a.allows(entry.FromID, entry.Username)
+}
+
+// noticeText names who may answer, for the notice sent when someone else
+// tries to.
+func (a telegramAllowlist) noticeText() string {
+	var names []string
+	for name := range a.usernames {
+		names = append(names, "@"+name)
+	}
+	sort.Strings(names)
+	var ids []string
+	for id := range a.userIDs {
+		ids = append(ids, fmt.Sprintf("user %d", id))
+	}
+	sort.Strings(ids)
+	names = append(names, ids...)
+	return fmt.Sprintf("Only %s can answer consult-human questions in this chat.", strings.Join(names, ", "))
+}
@@ -0,0 +1,72 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// telegramCountdownInterval is how often a question shown with its deadline
+// is edited, well under Telegram's edit limits.
+const telegramCountdownInterval = time.Minute
+
+const (
+	telegramCountdownPrefix       = "⏳ expires in "
+	telegramCountdownExpiredText  = "⏰ expired"
+	telegramCountdownAnsweredText = "✅ answered"
+)
+
+// startCountdown edits the question message right away and then every
+// countdownInterval to show the time left until ctx's deadline. Edits are
+// best-effort and their errors are ignored. The returned func stops the
+// countdown, aborting an edit in flight, and returns once it has stopped.
+// When the deadline passes and NotifyTimeout will not mark the question, the
+// countdown is replaced with telegramCountdownExpiredText.
+func (p *TelegramProvider) startCountdown(ctx context.Context, rec telegramPendingRecord) func() {
+	deadline, ok := ctx.Deadline()
+	if !rec.ShowDeadline || !ok || rec.PromptText == "" || rec.DuplicateOf != "" {
+		return func() {}
+	}
+	interval := p.countdownInterval
+	if interval <= 0 {
+		interval = telegramCountdownInterval
+	}
+
+	editCtx, cancel := context.WithCancel(ctx)
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			_ = p.editQuestion(editCtx, rec, telegramCountdownText(time.Until(deadline)), rec.Keyboard)
+			select {
+			case <-editCtx.Done():
+				if errors.Is(ctx.Err(), context.DeadlineExceeded) && !p.notifyTimeout {
+					p.markQuestion(rec, telegramCountdownExpiredText)
+				}
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			cancel()
+			wg.Wait()
+		})
+	}
+}
+
+// telegramCountdownText formats the time left, rounded to whole minutes once
+// it is a minute or more.
+func telegramCountdownText(left time.Duration) string {
+	if left < time.Minute {
+		return telegramCountdownPrefix + telegramAge(left.Truncate(time.Second))
+	}
+	return telegramCountdownPrefix + telegramAge(left.Round(time.Minute))
+}
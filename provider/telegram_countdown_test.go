@@ -0,0 +1,100 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/AlhasanIQ/consult-human/contract"
+)
+
+var telegramCountdownTestRequest = contract.AskRequest{
+	RequestID:    "req-countdown",
+	Question:     "Ship it?",
+	Type:         contract.QuestionTypeChoice,
+	Choices:      []contract.Choice{{ID: "A", Text: "Yes"}, {ID: "B", Text: "No"}},
+	ShowDeadline: true,
+}
+
+func TestTelegramCountdownRefreshesUntilExpired(t *testing.T) {
+	mock := newTelegramAPIMock()
+	srv := httptest.NewServer(mock)
+	defer srv.Close()
+
+	p := newTestTelegramProvider(t, srv, "", func(p *TelegramProvider) { p.countdownInterval = 30 * time.Millisecond })
+	if _, err := p.Send(context.Background(), telegramCountdownTestRequest); err != nil {
+		t.Fatalf("Send returned error: %v", err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+	if _, err := p.Receive(ctx, telegramCountdownTestRequest.RequestID); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected the wait to time out, got %v", err)
+	}
+
+	edits := mock.sentEdits()
+	if len(edits) < 3 {
+		t.Fatalf("expected several countdown edits, got %#v", edits)
+	}
+	first := edits[0].Payload
+	if text, _ := first["text"].(string); !strings.HasPrefix(text, "Ship it?") || !strings.Contains(text, "\n\n"+telegramCountdownPrefix) {
+		t.Fatalf("unexpected countdown edit: %#v", first)
+	}
+	if markup, _ := first["reply_markup"].(map[string]any); markup == nil || markup["inline_keyboard"] == nil {
+		t.Fatalf("expected the countdown to keep the buttons: %#v", first)
+	}
+	last := edits[len(edits)-1].Payload
+	if !strings.HasSuffix(last["text"].(string), telegramCountdownExpiredText) || last["reply_markup"] != nil {
+		t.Fatalf("expected the countdown replaced by the expired marker, got %#v", last)
+	}
+}
+
+func TestTelegramCountdownStopsWhenAnswered(t *testing.T) {
+	mock := newTelegramAPIMock()
+	mock.batches = [][]telegramUpdate{
+		{},
+		{{UpdateID: 1, Message: &telegramMessage{
+			MessageID:      2001,
+			Date:           time.Now().Unix(),
+			Text:           "A",
+			Chat:           telegramChat{ID: 777},
+			ReplyToMessage: &telegramMessage{MessageID: 1001},
+		}}},
+	}
+	srv := httptest.NewServer(mock)
+	defer srv.Close()
+
+	p := newTestTelegramProvider(t, srv, "", func(p *TelegramProvider) { p.countdownInterval = 30 * time.Millisecond })
+	if _, err := p.Send(context.Background(), telegramCountdownTestRequest); err != nil {
+		t.Fatalf("Send returned error: %v", err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	if _, err := p.Receive(ctx, telegramCountdownTestRequest.RequestID); err != nil {
+		t.Fatalf("Receive returned error: %v", err)
+	}
+
+	edits := mock.sentEdits()
+	if len(edits) < 2 || !strings.HasSuffix(edits[len(edits)-1].Payload["text"].(string), telegramCountdownAnsweredText) {
+		t.Fatalf("expected the countdown replaced by the answered marker, got %#v", edits)
+	}
+	time.Sleep(100 * time.Millisecond)
+	if after := mock.sentEdits(); len(after) != len(edits) {
+		t.Fatalf("expected no edits once answered, got %d more", len(after)-len(edits))
+	}
+}
+
+func TestTelegramCountdownText(t *testing.T) {
+	cases := map[time.Duration]string{
+		12*time.Minute + 20*time.Second:   "⏳ expires in 12m",
+		90 * time.Minute:                  "⏳ expires in 1h30m",
+		42*time.Second + time.Millisecond: "⏳ expires in 42s",
+	}
+	for left, want := range cases {
+		if got := telegramCountdownText(left); got != want {
+			t.Fatalf("telegramCountdownText(%s) = %q, want %q", left, got, want)
+		}
+	}
+}
@@ -0,0 +1,169 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"regexp"
+	"strings"
+)
+
+const telegramGroupPromptHint = "Reply to this message to answer."
+
+// filterGroupUpdates applies telegram.group_mode: in group chats only
+// messages that reply to a pending question or reminder, mention the bot, or
+// are commands are kept, with the mention stripped. Other messages are
+// blanked rather than removed so the update still advances the offset.
+func (p *TelegramProvider) filterGroupUpdates(ctx context.Context, updates []telegramUpdate) {
+	if !p.groupMode {
+		return
+	}
+	var mention *regexp.Regexp
+	looked := false
+	for i := range updates {
+		up := &updates[i]
+		for _, msg := range []**telegramMessage{&up.Message, &up.EditedMessage} {
+			if *msg == nil || (*msg).Chat.ID >= 0 {
+				continue
+			}
+			if !looked {
+				mention = p.mentionRegexp(p.botUsernameValue(ctx))
+				looked = true
+			}
+			repliesToBot := false
+			if reply := (*msg).ReplyToMessage; reply != nil {
+				repliesToBot = p.isBotPromptMessage((*msg).Chat.ID, reply.MessageID)
+			}
+			if !addressTelegramGroupMessage(*msg, repliesToBot, mention) {
+				*msg = nil
+			}
+		}
+	}
+}
+
+// addressTelegramGroupMessage reports whether msg is meant for the bot, and
+// strips the @mention from its text if so. mention is nil while the bot's
+// username is unknown.
+func addressTelegramGroupMessage(msg *telegramMessage, repliesToBot bool, mention *regexp.Regexp) bool {
+	if strings.HasPrefix(telegramMessageText(msg), "/") {
+		return true
+	}
+	addressed := repliesToBot
+	if mention != nil && (mention.MatchString(msg.Text) || mention.MatchString(msg.Caption)) {
+		msg.Text = strings.TrimSpace(mention.ReplaceAllString(msg.Text, ""))
+		msg.Caption = strings.TrimSpace(mention.ReplaceAllString(msg.Caption, ""))
+		addressed = true
+	}
+	return addressed
+}
+
+// isBotPromptMessage reports whether messageID in chatID is a question still
+// waiting for an answer or a threading reminder, the only bot messages a
+// group reply may answer.
+func (p *TelegramProvider) isBotPromptMessage(chatID, messageID int64) bool {
+	if messageID == 0 {
+		return false
+	}
+	if chatID == p.chatID {
+		p.mu.Lock()
+		for _, id := range p.pending {
+			if id == messageID {
+				p.mu.Unlock()
+				return true
+			}
+		}
+		p.mu.Unlock()
+	}
+	if p.pendingStore != nil {
+		if recs, err := p.pendingStore.ListByMessage(chatID, messageID); err == nil && len(recs) > 0 {
+			return true
+		}
+	}
+	if p.inboxStore != nil {
+		if ok, err := p.inboxStore.HasReminder(chatID, messageID); err == nil && ok {
+			return true
+		}
+	}
+	return false
+}
+
+// mentionRegexp returns the pattern matching an @mention of username,
+// compiled once and reused until the username changes.
+func (p *TelegramProvider) mentionRegexp(username string) *regexp.Regexp {
+	if username == "" {
+		return nil
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.mentionRe == nil || p.mentionUsername != username {
+		p.mentionRe = regexp.MustCompile(`(?i)@` + regexp.QuoteMeta(username) + `\b[,:]?\s*`)
+		p.mentionUsername = username
+	}
+	return p.mentionRe
+}
+
+// botUsernameValue returns the bot's username, looking it up with getMe the
+// first time. It returns "" when the lookup fails, so only replies to the
+// bot's questions are recognized until a later lookup succeeds.
+func (p *TelegramProvider) botUsernameValue(ctx context.Context) string {
+	bot, err := p.botUser(ctx)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "warning: telegram could not look up the bot username: %v\n", err)
+		return ""
+	}
+	return bot.Username
+}
+
+// botUser returns the bot's own user, cached after the first getMe.
+func (p *TelegramProvider) botUser(ctx context.Context) (telegramUser, error) {
+	p.mu.Lock()
+	bot := p.bot
+	p.mu.Unlock()
+	if bot.ID != 0 {
+		return bot, nil
+	}
+
+	bot, err := p.getMe(ctx)
+	if err != nil {
+		return telegramUser{}, err
+	}
+	p.mu.Lock()
+	p.bot = bot
+	p.mu.Unlock()
+	return bot, nil
+}
+
+func (p *TelegramProvider) getMe(ctx context.Context) (telegramUser, error) {
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, p.baseURL+"/getMe", strings.NewReader("{}"))
+	if err != nil {
+		return telegramUser{}, err
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	resp, err := p.client.Do(httpReq)
+	if err != nil {
+		return telegramUser{}, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		b, _ := io.ReadAll(io.LimitReader(resp.Body, 2048))
+		return telegramUser{}, newTelegramStatusError("getMe", resp.StatusCode, b)
+	}
+
+	var decoded struct {
+		OK     bool         `json:"ok"`
+		Result telegramUser `json:"result"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&decoded); err != nil {
+		return telegramUser{}, err
+	}
+	if !decoded.OK {
+		return telegramUser{}, fmt.Errorf("telegram getMe failed")
+	}
+	decoded.Result.Username = strings.TrimSpace(decoded.Result.Username)
+	return decoded.Result, nil
+}
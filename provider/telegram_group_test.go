@@ -0,0 +1,151 @@
+package provider
+
+import (
+	"context"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/AlhasanIQ/consult-human/contract"
+)
+
+func TestTelegramGroupModeOnlyAcceptsRepliesAndMentions(t *testing.T) {
+	mock := newTelegramAPIMock()
+	mock.batches = [][]telegramUpdate{
+		{
+			{
+				UpdateID: 1,
+				Message: &telegramMessage{
+					MessageID: 2001,
+					Date:      time.Now().Unix(),
+					Text:      "lunch anyone?",
+					Chat:      telegramChat{ID: -100123},
+				},
+			},
+			{
+				UpdateID: 2,
+				Message: &telegramMessage{
+					MessageID: 2002,
+					Date:      time.Now().Unix(),
+					Text:      "@Consult_Bot: ship it",
+					Chat:      telegramChat{ID: -100123},
+				},
+			},
+		},
+	}
+	srv := httptest.NewServer(mock)
+	defer srv.Close()
+
+	p := &TelegramProvider{
+		chatID:       -100123,
+		pollInterval: 10 * time.Millisecond,
+		baseURL:      srv.URL,
+		client:       srv.Client(),
+		groupMode:    true,
+		pending: map[string]int64{
+			"req-group": 1111,
+		},
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	reply, err := p.Receive(ctx, "req-group")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if reply.Text != "ship it" || reply.ProviderMessageID != "2002" {
+		t.Fatalf("expected the mention with the @mention stripped, got %#v", reply)
+	}
+}
+
+func TestFilterGroupUpdatesKeepsMessagesForTheBot(t *testing.T) {
+	mock := newTelegramAPIMock()
+	srv := httptest.NewServer(mock)
+	defer srv.Close()
+
+	inboxPath := filepath.Join(t.TempDir(), "inbox.json")
+	p := &TelegramProvider{
+		chatID:     -1,
+		baseURL:    srv.URL,
+		client:     srv.Client(),
+		groupMode:  true,
+		pending:    map[string]int64{"req-group": 1001},
+		inboxStore: &telegramInboxStore{path: inboxPath, lock: inboxPath + ".lock"},
+	}
+	if err := p.inboxStore.RecordReminder(-1, 1002); err != nil {
+		t.Fatalf("RecordReminder returned error: %v", err)
+	}
+	bot := &telegramUser{ID: 42, IsBot: true, Username: "consult_bot"}
+	human := &telegramUser{ID: 7, Username: "alice"}
+	updates := []telegramUpdate{
+		{UpdateID: 1, Message: &telegramMessage{Text: "yes", Chat: telegramChat{ID: -1}, ReplyToMessage: &telegramMessage{MessageID: 1001, From: bot}}},
+		{UpdateID: 2, Message: &telegramMessage{Text: "agreed", Chat: telegramChat{ID: -1}, ReplyToMessage: &telegramMessage{MessageID: 5, From: human}}},
+		{UpdateID: 3, Message: &telegramMessage{Text: "/cancel@consult_bot", Chat: telegramChat{ID: -1}}},
+		{UpdateID: 4, Message: &telegramMessage{Text: "hello", Chat: telegramChat{ID: 777}}},
+		{UpdateID: 5, EditedMessage: &telegramMessage{Text: "off topic", Chat: telegramChat{ID: -1}}},
+		{UpdateID: 6, Message: &telegramMessage{Text: "no", Chat: telegramChat{ID: -1}, ReplyToMessage: &telegramMessage{MessageID: 1002, From: bot}}},
+		{UpdateID: 7, Message: &telegramMessage{Text: "thanks", Chat: telegramChat{ID: -1}, ReplyToMessage: &telegramMessage{MessageID: 1003, From: bot}}},
+	}
+	p.filterGroupUpdates(context.Background(), updates)
+
+	kept := map[int64]bool{}
+	for _, up := range updates {
+		kept[up.UpdateID] = up.Message != nil || up.EditedMessage != nil
+	}
+	want := map[int64]bool{1: true, 2: false, 3: true, 4: true, 5: false, 6: true, 7: false}
+	for id, w := range want {
+		if kept[id] != w {
+			t.Fatalf("update %d kept=%v, want %v", id, kept[id], w)
+		}
+	}
+}
+
+func TestTelegramGroupModeAddsReplyHintToPrompt(t *testing.T) {
+	mock := newTelegramAPIMock()
+	srv := httptest.NewServer(mock)
+	defer srv.Close()
+
+	p := &TelegramProvider{
+		chatID:       -100123,
+		pollInterval: 10 * time.Millisecond,
+		baseURL:      srv.URL,
+		client:       srv.Client(),
+		groupMode:    true,
+		pending:      make(map[string]int64),
+	}
+	req := contract.AskRequest{RequestID: "req-hint", Question: "Ship it?", Type: contract.QuestionTypeOpen}
+	if _, err := p.Send(context.Background(), req); err != nil {
+		t.Fatalf("Send returned error: %v", err)
+	}
+	texts := mock.sentTexts()
+	if len(texts) != 1 || !strings.HasSuffix(texts[0], "\n\n"+telegramGroupPromptHint) {
+		t.Fatalf("expected reply hint in group prompt, got %#v", texts)
+	}
+}
+
+func TestAddressTelegramGroupMessageWithoutUsername(t *testing.T) {
+	msg := &telegramMessage{Text: "@consult_bot ship it", Chat: telegramChat{ID: -1}}
+	if addressTelegramGroupMessage(msg, false, nil) {
+		t.Fatalf("expected a mention to be ignored while the username is unknown")
+	}
+	if !addressTelegramGroupMessage(msg, true, nil) {
+		t.Fatalf("expected a reply to a question to be kept while the username is unknown")
+	}
+}
+
+func TestMentionRegexpIsCachedPerUsername(t *testing.T) {
+	p := &TelegramProvider{}
+	first := p.mentionRegexp("consult_bot")
+	if p.mentionRegexp("consult_bot") != first {
+		t.Fatalf("expected the mention pattern to be reused")
+	}
+	if p.mentionRegexp("other_bot") == first {
+		t.Fatalf("expected a new pattern after the username changed")
+	}
+	if p.mentionRegexp("") != nil {
+		t.Fatalf("expected no pattern for an unknown username")
+	}
+}
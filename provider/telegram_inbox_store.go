@@ -5,42 +5,97 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
-	"runtime"
 	"sort"
-	"strconv"
 	"strings"
 	"time"
 
 	"github.com/AlhasanIQ/consult-human/config"
+	"github.com/AlhasanIQ/consult-human/filelock"
 )
 
 const (
 	telegramInboxReplyTTL      = 20 * time.Minute
 	telegramInboxLooseTTL      = 5 * time.Minute
-	telegramInboxLockWait      = 3 * time.Second
-	telegramInboxLockMaxAge    = 10 * time.Second
 	telegramInboxMaxEntries    = 4096
 	telegramPollerLockMaxAge   = 2 * time.Minute
 	telegramPollerWaitInterval = 150 * time.Millisecond
+
+	telegramLocalReplyFrom = "local"
+
+	// Telegram only lets bots delete their messages for 48 hours.
+	telegramReminderMaxAge = 48 * time.Hour
+
+	// An edit to a reply that was already claimed counts as a correction
+	// only this soon after the claim.
+	telegramEditCorrectionWindow = 60 * time.Second
 )
 
+// telegramInboxKindCallback marks an entry ingested from an inline keyboard
+// tap. Text messages leave Kind empty, as stores written before callback
+// queries were ingested do.
+const telegramInboxKindCallback = "callback"
+
+// telegramInboxKindReaction marks a reaction on a question message; its
+// text is the emoji, mapped to an answer by whoever claims it.
+const telegramInboxKindReaction = "reaction"
+
+// telegramInboxKindPollAnswer marks a vote in a question poll. Poll answers
+// carry no chat, so they are only taken by TakePollAnswers.
+const telegramInboxKindPollAnswer = "poll_answer"
+
+// telegramInboxKindCorrection marks the edited text of a reply that was
+// already claimed. It is only taken by TakeCorrection, never claimed.
+const telegramInboxKindCorrection = "correction"
+
 type telegramInboxEntry struct {
 	UpdateID         int64     `json:"update_id"`
+	Kind             string    `json:"kind,omitempty"`
+	CallbackQueryID  string    `json:"callback_query_id,omitempty"`
 	ChatID           int64     `json:"chat_id"`
 	MessageID        int64     `json:"message_id"`
 	ReplyToMessageID int64     `json:"reply_to_message_id,omitempty"`
 	Text             string    `json:"text"`
 	Date             int64     `json:"date"`
+	FromID           int64     `json:"from_id,omitempty"`
 	Username         string    `json:"username,omitempty"`
 	FirstName        string    `json:"first_name,omitempty"`
 	LastName         string    `json:"last_name,omitempty"`
+	Shared           bool      `json:"shared,omitempty"`
+	Edited           bool      `json:"edited,omitempty"`
+	VoiceFileID      string    `json:"voice_file_id,omitempty"`
+	VoiceMimeType    string    `json:"voice_mime_type,omitempty"`
+	VoiceDuration    int       `json:"voice_duration,omitempty"`
+	PhotoFileID      string    `json:"photo_file_id,omitempty"`
+	PollID           string    `json:"poll_id,omitempty"`
+	PollOptions      []int     `json:"poll_options,omitempty"`
 	IngestedAt       time.Time `json:"ingested_at"`
 	ExpiresAt        time.Time `json:"expires_at"`
 }
 
+// telegramReminderMessage is a threading reminder sent to a chat, kept so it
+// can be deleted once the chat no longer has several questions waiting.
+type telegramReminderMessage struct {
+	ChatID    int64     `json:"chat_id"`
+	MessageID int64     `json:"message_id"`
+	SentAt    time.Time `json:"sent_at"`
+}
+
+// telegramClaimedMessage remembers a claimed text reply for
+// telegramEditCorrectionWindow, so a quick edit to it can be matched.
+type telegramClaimedMessage struct {
+	ChatID    int64     `json:"chat_id"`
+	MessageID int64     `json:"message_id"`
+	ClaimedAt time.Time `json:"claimed_at"`
+}
+
 type telegramInboxState struct {
-	NextUpdateID int64                `json:"next_update_id"`
-	Entries      []telegramInboxEntry `json:"entries"`
+	NextUpdateID int64                     `json:"next_update_id"`
+	Entries      []telegramInboxEntry      `json:"entries"`
+	Reminders    []telegramReminderMessage `json:"reminders,omitempty"`
+	Claimed      []telegramClaimedMessage  `json:"claimed,omitempty"`
+	// LastReminderAt is when each chat last got a threading reminder, so
+	// concurrent processes share one reminder cooldown.
+	LastReminderAt map[int64]time.Time `json:"last_reminder_at,omitempty"`
 }
 
 type telegramInboxStore struct {
@@ -125,12 +180,62 @@ func (s *telegramInboxStore) AppendUpdates(updates []telegramUpdate) (int, int64
 			if _, ok := existing[up.UpdateID]; ok {
 				continue
 			}
+			if cq := up.CallbackQuery; cq != nil {
+				if entry, ok := telegramCallbackInboxEntry(up.UpdateID, cq, now); ok {
+					state.Entries = append(state.Entries, entry)
+					existing[up.UpdateID] = struct{}{}
+					added++
+				}
+				continue
+			}
+			if pa := up.PollAnswer; pa != nil {
+				entry := telegramInboxEntry{
+					UpdateID:    up.UpdateID,
+					Kind:        telegramInboxKindPollAnswer,
+					PollID:      pa.PollID,
+					PollOptions: pa.OptionIDs,
+					Date:        now.Unix(),
+					IngestedAt:  now,
+					ExpiresAt:   now.Add(telegramInboxReplyTTL),
+				}
+				if pa.User != nil {
+					entry.FromID = pa.User.ID
+					entry.Username = strings.TrimSpace(pa.User.Username)
+					entry.FirstName = strings.TrimSpace(pa.User.FirstName)
+					entry.LastName = strings.TrimSpace(pa.User.LastName)
+				}
+				state.Entries = append(state.Entries, entry)
+				existing[up.UpdateID] = struct{}{}
+				added++
+				continue
+			}
+			if mr := up.MessageReaction; mr != nil {
+				if entry, ok := applyTelegramReaction(&state, up.UpdateID, mr, now); ok {
+					state.Entries = append(state.Entries, entry)
+					existing[up.UpdateID] = struct{}{}
+					added++
+				}
+				continue
+			}
+			if msg := up.EditedMessage; msg != nil {
+				if entry, ok := applyTelegramEdit(&state, up.UpdateID, msg, now); ok {
+					state.Entries = append(state.Entries, entry)
+					existing[up.UpdateID] = struct{}{}
+					added++
+				}
+				continue
+			}
 			msg := up.Message
 			if msg == nil {
 				continue
 			}
-			text := strings.TrimSpace(msg.Text)
-			if text == "" {
+			text := telegramMessageText(msg)
+			// Voice messages and bare photos only count as threaded replies.
+			if text == "" && (!telegramMessageHasMedia(msg) || msg.ReplyToMessage == nil) {
+				continue
+			}
+			// /status is answered by whoever ingests it and never claimed.
+			if isTelegramStatusCommand(text) {
 				continue
 			}
 
@@ -153,7 +258,16 @@ func (s *telegramInboxStore) AppendUpdates(updates []telegramUpdate) (int, int64
 				IngestedAt:       now,
 				ExpiresAt:        expiresAt,
 			}
+			if msg.Voice != nil && text == "" {
+				entry.VoiceFileID = msg.Voice.FileID
+				entry.VoiceMimeType = msg.Voice.MimeType
+				entry.VoiceDuration = msg.Voice.Duration
+			}
+			// Keep the file ID, not the file, so whichever process claims
+			// the reply can download it.
+			entry.PhotoFileID = largestTelegramPhoto(msg.Photo)
 			if msg.From != nil {
+				entry.FromID = msg.From.ID
 				entry.Username = strings.TrimSpace(msg.From.Username)
 				entry.FirstName = strings.TrimSpace(msg.From.FirstName)
 				entry.LastName = strings.TrimSpace(msg.From.LastName)
@@ -182,23 +296,376 @@ func (s *telegramInboxStore) AppendUpdates(updates []telegramUpdate) (int, int64
 	return added, nextOffset, nil
 }
 
-func (s *telegramInboxStore) ClaimForRequest(chatID, targetMessageID int64, pendingCount int) (*telegramInboxEntry, bool, error) {
-	var claimed *telegramInboxEntry
-	var needsReminder bool
+// applyTelegramEdit replaces the text of an edited message still waiting in
+// the inbox. If the message was claimed within telegramEditCorrectionWindow,
+// it instead returns a correction entry for TakeCorrection. Edits to
+// anything else are dropped.
+func applyTelegramEdit(state *telegramInboxState, updateID int64, msg *telegramMessage, now time.Time) (telegramInboxEntry, bool) {
+	text := telegramMessageText(msg)
+	if text == "" {
+		return telegramInboxEntry{}, false
+	}
+	replaced := false
+	for i := range state.Entries {
+		e := &state.Entries[i]
+		if e.Kind == "" && e.ChatID == msg.Chat.ID && e.MessageID == msg.MessageID && e.Username != telegramLocalReplyFrom {
+			e.Text = text
+			e.Edited = true
+			replaced = true
+		}
+	}
+	if replaced {
+		return telegramInboxEntry{}, false
+	}
+	for _, c := range state.Claimed {
+		if c.ChatID != msg.Chat.ID || c.MessageID != msg.MessageID {
+			continue
+		}
+		return telegramInboxEntry{
+			UpdateID:   updateID,
+			Kind:       telegramInboxKindCorrection,
+			ChatID:     msg.Chat.ID,
+			MessageID:  msg.MessageID,
+			Text:       text,
+			Date:       msg.Date,
+			Edited:     true,
+			IngestedAt: now,
+			ExpiresAt:  c.ClaimedAt.Add(telegramEditCorrectionWindow),
+		}, true
+	}
+	return telegramInboxEntry{}, false
+}
+
+// applyTelegramReaction drops the sender's earlier unclaimed reaction to the
+// same message, so a changed reaction replaces it, and returns an entry for
+// the new one. A removed reaction just drops the earlier one.
+func applyTelegramReaction(state *telegramInboxState, updateID int64, mr *telegramMessageReactionUpdated, now time.Time) (telegramInboxEntry, bool) {
+	var fromID int64
+	if mr.User != nil {
+		fromID = mr.User.ID
+	}
+	kept := state.Entries[:0]
+	for _, e := range state.Entries {
+		if e.Kind == telegramInboxKindReaction && e.ChatID == mr.Chat.ID && e.MessageID == mr.MessageID && e.FromID == fromID {
+			continue
+		}
+		kept = append(kept, e)
+	}
+	state.Entries = kept
+
+	emoji := telegramReactionEmoji(mr)
+	if emoji == "" {
+		return telegramInboxEntry{}, false
+	}
+	entry := telegramInboxEntry{
+		UpdateID:         updateID,
+		Kind:             telegramInboxKindReaction,
+		ChatID:           mr.Chat.ID,
+		MessageID:        mr.MessageID,
+		ReplyToMessageID: mr.MessageID,
+		Text:             emoji,
+		Date:             mr.Date,
+		IngestedAt:       now,
+		ExpiresAt:        now.Add(telegramInboxReplyTTL),
+	}
+	if mr.User != nil {
+		entry.FromID = mr.User.ID
+		entry.Username = strings.TrimSpace(mr.User.Username)
+		entry.FirstName = strings.TrimSpace(mr.User.FirstName)
+		entry.LastName = strings.TrimSpace(mr.User.LastName)
+	}
+	return entry, true
+}
 
+// TakePollAnswers removes and returns the votes in pollID, oldest first.
+func (s *telegramInboxStore) TakePollAnswers(pollID string) ([]telegramInboxEntry, error) {
+	var out []telegramInboxEntry
+	err := s.withLock(func() error {
+		state, changed, err := s.loadPrunedLocked(time.Now().UTC())
+		if err != nil {
+			return err
+		}
+		kept := state.Entries[:0]
+		for _, e := range state.Entries {
+			if e.Kind == telegramInboxKindPollAnswer && e.PollID == pollID {
+				out = append(out, e)
+				continue
+			}
+			kept = append(kept, e)
+		}
+		state.Entries = kept
+		if len(out) == 0 && !changed {
+			return nil
+		}
+		return s.saveLocked(state)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// TakeCorrection removes and returns the latest correction to the claimed
+// message, or nil if it has not been edited.
+func (s *telegramInboxStore) TakeCorrection(chatID, messageID int64) (*telegramInboxEntry, error) {
+	var latest *telegramInboxEntry
+	err := s.withLock(func() error {
+		state, changed, err := s.loadPrunedLocked(time.Now().UTC())
+		if err != nil {
+			return err
+		}
+		kept := state.Entries[:0]
+		for _, e := range state.Entries {
+			if e.Kind == telegramInboxKindCorrection && e.ChatID == chatID && e.MessageID == messageID {
+				c := e
+				latest = &c
+				continue
+			}
+			kept = append(kept, e)
+		}
+		state.Entries = kept
+		if latest == nil && !changed {
+			return nil
+		}
+		return s.saveLocked(state)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return latest, nil
+}
+
+// telegramCallbackInboxEntry stores a button tap as a reply to the message
+// carrying the keyboard, with the chosen option ID as its text, so claiming
+// works the same as for threaded text replies.
+func telegramCallbackInboxEntry(updateID int64, cq *telegramCallbackQuery, now time.Time) (telegramInboxEntry, bool) {
+	if cq.Message == nil {
+		return telegramInboxEntry{}, false
+	}
+	_, choiceID, ok := parseTelegramCallbackData(cq.Data)
+	if !ok {
+		return telegramInboxEntry{}, false
+	}
+	entry := telegramInboxEntry{
+		UpdateID:         updateID,
+		Kind:             telegramInboxKindCallback,
+		CallbackQueryID:  cq.ID,
+		ChatID:           cq.Message.Chat.ID,
+		MessageID:        cq.Message.MessageID,
+		ReplyToMessageID: cq.Message.MessageID,
+		Text:             choiceID,
+		Date:             now.Unix(),
+		IngestedAt:       now,
+		ExpiresAt:        now.Add(telegramInboxReplyTTL),
+	}
+	if cq.From != nil {
+		entry.FromID = cq.From.ID
+		entry.Username = strings.TrimSpace(cq.From.Username)
+		entry.FirstName = strings.TrimSpace(cq.From.FirstName)
+		entry.LastName = strings.TrimSpace(cq.From.LastName)
+	}
+	return entry, true
+}
+
+// InjectLocalReply queues text as a reply to targetMessageID, as if it had
+// arrived from Telegram.
+func (s *telegramInboxStore) InjectLocalReply(chatID, targetMessageID int64, text string) error {
+	now := time.Now().UTC()
+	return s.inject(telegramInboxEntry{
+		ChatID:           chatID,
+		ReplyToMessageID: targetMessageID,
+		Text:             strings.TrimSpace(text),
+		Date:             now.Unix(),
+		Username:         telegramLocalReplyFrom,
+	})
+}
+
+// ShareReply queues copies of a claimed reply for the other requests that
+// wait on targetMessageID, so deduplicated waiters all get the same answer.
+func (s *telegramInboxStore) ShareReply(entry telegramInboxEntry, targetMessageID int64, copies int) error {
+	entry.ReplyToMessageID = targetMessageID
+	entry.Shared = true
+	entry.CallbackQueryID = ""
+	entries := make([]telegramInboxEntry, copies)
+	for i := range entries {
+		entries[i] = entry
+	}
+	return s.inject(entries...)
+}
+
+// inject appends synthetic entries. They use negative update IDs so they
+// never collide with real updates or move the getUpdates offset.
+func (s *telegramInboxStore) inject(entries ...telegramInboxEntry) error {
+	if len(entries) == 0 {
+		return nil
+	}
+	return s.withLock(func() error {
+		now := time.Now().UTC()
+		state, _, err := s.loadPrunedLocked(now)
+		if err != nil {
+			return err
+		}
+
+		updateID := int64(0)
+		for _, rec := range state.Entries {
+			if rec.UpdateID < updateID {
+				updateID = rec.UpdateID
+			}
+		}
+		for _, entry := range entries {
+			updateID--
+			entry.UpdateID = updateID
+			entry.IngestedAt = now
+			entry.ExpiresAt = now.Add(telegramInboxReplyTTL)
+			state.Entries = append(state.Entries, entry)
+		}
+		sort.Slice(state.Entries, func(i, j int) bool {
+			return state.Entries[i].UpdateID < state.Entries[j].UpdateID
+		})
+		return s.saveLocked(state)
+	})
+}
+
+// ReserveReminder starts the threading reminder cooldown for chatID at now,
+// reporting false while a reminder sent less than cooldown ago still holds it.
+func (s *telegramInboxStore) ReserveReminder(chatID int64, cooldown time.Duration, now time.Time) (bool, error) {
+	reserved := false
+	err := s.withLock(func() error {
+		state, _, err := s.loadPrunedLocked(now)
+		if err != nil {
+			return err
+		}
+		if last, ok := state.LastReminderAt[chatID]; ok && now.Sub(last) < cooldown {
+			return nil
+		}
+		if state.LastReminderAt == nil {
+			state.LastReminderAt = make(map[int64]time.Time)
+		}
+		state.LastReminderAt[chatID] = now.UTC()
+		reserved = true
+		return s.saveLocked(state)
+	})
+	return reserved, err
+}
+
+// RecordReminder remembers a threading reminder so it can be deleted later.
+func (s *telegramInboxStore) RecordReminder(chatID, messageID int64) error {
+	return s.withLock(func() error {
+		now := time.Now().UTC()
+		state, _, err := s.loadPrunedLocked(now)
+		if err != nil {
+			return err
+		}
+		state.Reminders = append(state.Reminders, telegramReminderMessage{
+			ChatID:    chatID,
+			MessageID: messageID,
+			SentAt:    now,
+		})
+		return s.saveLocked(state)
+	})
+}
+
+// HasReminder reports whether messageID is a threading reminder recorded
+// for chatID.
+func (s *telegramInboxStore) HasReminder(chatID, messageID int64) (bool, error) {
+	found := false
 	err := s.withLock(func() error {
 		state, _, err := s.loadPrunedLocked(time.Now().UTC())
 		if err != nil {
 			return err
 		}
+		for _, r := range state.Reminders {
+			if r.ChatID == chatID && r.MessageID == messageID {
+				found = true
+				break
+			}
+		}
+		return nil
+	})
+	return found, err
+}
+
+// TakeReminders removes and returns the reminder message IDs recorded for chatID.
+func (s *telegramInboxStore) TakeReminders(chatID int64) ([]int64, error) {
+	var out []int64
+	err := s.withLock(func() error {
+		state, changed, err := s.loadPrunedLocked(time.Now().UTC())
+		if err != nil {
+			return err
+		}
+		kept := state.Reminders[:0]
+		for _, r := range state.Reminders {
+			if r.ChatID == chatID {
+				out = append(out, r.MessageID)
+				continue
+			}
+			kept = append(kept, r)
+		}
+		state.Reminders = kept
+		if len(out) == 0 && !changed {
+			return nil
+		}
+		return s.saveLocked(state)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// telegramClaimHints reports what ClaimForRequest dropped, so the claimer
+// can tell the chat why.
+type telegramClaimHints struct {
+	// NeedsReminder is set when an ambiguous unthreaded message was dropped.
+	NeedsReminder bool
+	// NeedsCancelChoice is set when an unthreaded /cancel was dropped because
+	// several questions are waiting.
+	NeedsCancelChoice bool
+	// Unauthorized is set when a reply to the question from a sender outside
+	// the allowlist was dropped.
+	Unauthorized bool
+	// RejectedCallbacks are the IDs of dropped button taps, which still need
+	// an answer to stop their loading spinner.
+	RejectedCallbacks []string
+}
+
+// ClaimForRequest removes and returns the inbox entry answering the target
+// message. A non-empty strictRequestID disables the single-pending fallback:
+// only explicit replies or messages carrying the request ID are accepted.
+func (s *telegramInboxStore) ClaimForRequest(chatID, targetMessageID int64, pendingCount int, strictRequestID string, allow telegramAllowlist) (*telegramInboxEntry, telegramClaimHints, error) {
+	var claimed *telegramInboxEntry
+	var hints telegramClaimHints
+
+	err := s.withLock(func() error {
+		now := time.Now().UTC()
+		state, _, err := s.loadPrunedLocked(now)
+		if err != nil {
+			return err
+		}
 
 		changed := false
 		for i := 0; i < len(state.Entries); {
 			entry := state.Entries[i]
-			if entry.ChatID != chatID {
+			if entry.ChatID != chatID || entry.Kind == telegramInboxKindCorrection {
 				i++
 				continue
 			}
+			if entry.Kind == telegramInboxKindReaction && now.Sub(entry.IngestedAt) < telegramReactionSettleWindow {
+				i++
+				continue
+			}
+
+			if !allow.allowsEntry(entry) {
+				if targetMessageID > 0 && entry.ReplyToMessageID == targetMessageID {
+					hints.Unauthorized = true
+				}
+				if entry.CallbackQueryID != "" {
+					hints.RejectedCallbacks = append(hints.RejectedCallbacks, entry.CallbackQueryID)
+				}
+				state.Entries = append(state.Entries[:i], state.Entries[i+1:]...)
+				changed = true
+				continue
+			}
 
 			matchesByReply := targetMessageID > 0 && entry.ReplyToMessageID == targetMessageID
 			if matchesByReply {
@@ -209,10 +676,26 @@ func (s *telegramInboxStore) ClaimForRequest(chatID, targetMessageID int64, pend
 				break
 			}
 
-			if pendingCount > 1 {
+			if strictRequestID != "" && entry.MessageID > targetMessageID {
+				if text, ok := stripRequestIDToken(entry.Text, strictRequestID); ok {
+					c := entry
+					c.Text = text
+					claimed = &c
+					state.Entries = append(state.Entries[:i], state.Entries[i+1:]...)
+					changed = true
+					break
+				}
+			}
+
+			if pendingCount > 1 || strictRequestID != "" {
 				if entry.ReplyToMessageID == 0 && entry.MessageID > targetMessageID {
-					// Ambiguous free-text message while multiple requests are pending.
-					needsReminder = true
+					// Ambiguous free-text message while multiple requests are pending
+					// or strict reply matching is on.
+					if pendingCount > 1 && isTelegramCancelCommand(entry.Text) {
+						hints.NeedsCancelChoice = true
+					} else {
+						hints.NeedsReminder = true
+					}
 					state.Entries = append(state.Entries[:i], state.Entries[i+1:]...)
 					changed = true
 					continue
@@ -251,64 +734,45 @@ func (s *telegramInboxStore) ClaimForRequest(chatID, targetMessageID int64, pend
 			i++
 		}
 
+		if claimed != nil && claimed.Kind == "" && claimed.UpdateID > 0 {
+			state.Claimed = append(state.Claimed, telegramClaimedMessage{
+				ChatID:    claimed.ChatID,
+				MessageID: claimed.MessageID,
+				ClaimedAt: time.Now().UTC(),
+			})
+		}
 		if changed {
 			return s.saveLocked(state)
 		}
 		return nil
 	})
 	if err != nil {
-		return nil, false, err
+		return nil, telegramClaimHints{}, err
 	}
-	return claimed, needsReminder, nil
+	return claimed, hints, nil
 }
 
-func (s *telegramInboxStore) withLock(fn func() error) error {
-	if err := os.MkdirAll(filepath.Dir(s.path), 0o755); err != nil {
-		return err
-	}
-
-	deadline := time.Now().Add(telegramInboxLockWait)
-	for {
-		lockFile, err := os.OpenFile(s.lock, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o600)
-		if err == nil {
-			_, _ = lockFile.WriteString(fmt.Sprintf("%d\n", os.Getpid()))
-			_ = lockFile.Close()
-			defer os.Remove(s.lock)
-			return fn()
-		}
-		if !os.IsExist(err) {
-			return err
-		}
-		stale, staleErr := s.isStaleLock()
-		if staleErr == nil && stale {
-			_ = os.Remove(s.lock)
+// stripRequestIDToken reports whether text names requestID as a standalone
+// token and returns the text with that token removed.
+func stripRequestIDToken(text, requestID string) (string, bool) {
+	fields := strings.Fields(text)
+	kept := make([]string, 0, len(fields))
+	found := false
+	for _, f := range fields {
+		if !found && strings.EqualFold(strings.Trim(f, "()[]{}<>.,:;#"), requestID) {
+			found = true
 			continue
 		}
-		if time.Now().After(deadline) {
-			return fmt.Errorf("timeout waiting for telegram inbox store lock")
-		}
-		time.Sleep(20 * time.Millisecond)
+		kept = append(kept, f)
 	}
-}
-
-func (s *telegramInboxStore) isStaleLock() (bool, error) {
-	st, err := os.Stat(s.lock)
-	if err != nil {
-		if os.IsNotExist(err) {
-			return false, nil
-		}
-		return false, err
+	if !found {
+		return text, false
 	}
+	return strings.Join(kept, " "), true
+}
 
-	rawPID, _ := os.ReadFile(s.lock)
-	pid, parseErr := strconv.Atoi(strings.TrimSpace(string(rawPID)))
-	if parseErr == nil && pid > 0 && runtime.GOOS != "windows" {
-		if !processExists(pid) {
-			return true, nil
-		}
-		return false, nil
-	}
-	return time.Since(st.ModTime()) > telegramInboxLockMaxAge, nil
+func (s *telegramInboxStore) withLock(fn func() error) error {
+	return filelock.Lock{Path: s.lock, Name: "telegram inbox store"}.With(fn)
 }
 
 func (s *telegramInboxStore) loadPrunedLocked(now time.Time) (telegramInboxState, bool, error) {
@@ -335,6 +799,33 @@ func pruneExpiredInboxEntries(state *telegramInboxState, now time.Time) bool {
 		out = append(out, rec)
 	}
 	state.Entries = out
+
+	reminders := state.Reminders[:0]
+	for _, r := range state.Reminders {
+		if now.Sub(r.SentAt) > telegramReminderMaxAge {
+			changed = true
+			continue
+		}
+		reminders = append(reminders, r)
+	}
+	state.Reminders = reminders
+
+	claimed := state.Claimed[:0]
+	for _, c := range state.Claimed {
+		if now.Sub(c.ClaimedAt) > telegramEditCorrectionWindow {
+			changed = true
+			continue
+		}
+		claimed = append(claimed, c)
+	}
+	state.Claimed = claimed
+
+	for chatID, at := range state.LastReminderAt {
+		if now.Sub(at) > telegramReminderMaxAge {
+			delete(state.LastReminderAt, chatID)
+			changed = true
+		}
+	}
 	return changed
 }
 
@@ -376,46 +867,9 @@ func (l *telegramPollerLock) TryWithLock(fn func() error) (bool, error) {
 	if l == nil {
 		return false, fmt.Errorf("nil telegram poller lock")
 	}
-	if err := os.MkdirAll(filepath.Dir(l.path), 0o755); err != nil {
-		return false, err
-	}
-
-	for {
-		f, err := os.OpenFile(l.path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o600)
-		if err == nil {
-			_, _ = f.WriteString(fmt.Sprintf("%d\n", os.Getpid()))
-			_ = f.Close()
-			defer os.Remove(l.path)
-			return true, fn()
-		}
-		if !os.IsExist(err) {
-			return false, err
-		}
-		stale, staleErr := l.isStale()
-		if staleErr == nil && stale {
-			_ = os.Remove(l.path)
-			continue
-		}
-		return false, nil
-	}
+	return l.fileLock().TryWith(fn)
 }
 
-func (l *telegramPollerLock) isStale() (bool, error) {
-	st, err := os.Stat(l.path)
-	if err != nil {
-		if os.IsNotExist(err) {
-			return false, nil
-		}
-		return false, err
-	}
-
-	rawPID, _ := os.ReadFile(l.path)
-	pid, parseErr := strconv.Atoi(strings.TrimSpace(string(rawPID)))
-	if parseErr == nil && pid > 0 && runtime.GOOS != "windows" {
-		if !processExists(pid) {
-			return true, nil
-		}
-		return false, nil
-	}
-	return time.Since(st.ModTime()) > telegramPollerLockMaxAge, nil
+func (l *telegramPollerLock) fileLock() filelock.Lock {
+	return filelock.Lock{Path: l.path, MaxAge: telegramPollerLockMaxAge}
 }
@@ -4,6 +4,8 @@ import (
 	"path/filepath"
 	"testing"
 	"time"
+
+	"github.com/AlhasanIQ/consult-human/config"
 )
 
 func TestTelegramInboxStoreAppendAndClaimReply(t *testing.T) {
@@ -33,11 +35,11 @@ func TestTelegramInboxStoreAppendAndClaimReply(t *testing.T) {
 		t.Fatalf("AppendUpdates: %v", err)
 	}
 
-	got, needsReminder, err := store.ClaimForRequest(7001, 5001, 2)
+	got, hints, err := store.ClaimForRequest(7001, 5001, 2, "", telegramAllowlist{})
 	if err != nil {
 		t.Fatalf("ClaimForRequest: %v", err)
 	}
-	if needsReminder {
+	if hints.NeedsReminder {
 		t.Fatalf("did not expect reminder")
 	}
 	if got == nil {
@@ -72,24 +74,55 @@ func TestTelegramInboxStoreDropsAmbiguousMessageWhenMultiplePending(t *testing.T
 		t.Fatalf("AppendUpdates: %v", err)
 	}
 
-	got, needsReminder, err := store.ClaimForRequest(7002, 5002, 3)
+	got, hints, err := store.ClaimForRequest(7002, 5002, 3, "", telegramAllowlist{})
 	if err != nil {
 		t.Fatalf("ClaimForRequest: %v", err)
 	}
 	if got != nil {
 		t.Fatalf("did not expect claimed entry: %#v", got)
 	}
-	if !needsReminder {
+	if !hints.NeedsReminder {
 		t.Fatalf("expected reminder flag")
 	}
 
 	// The ambiguous message should be removed once observed in multi-pending mode.
-	got, needsReminder, err = store.ClaimForRequest(7002, 5002, 3)
+	got, hints, err = store.ClaimForRequest(7002, 5002, 3, "", telegramAllowlist{})
 	if err != nil {
 		t.Fatalf("ClaimForRequest second call: %v", err)
 	}
-	if got != nil || needsReminder {
-		t.Fatalf("expected no leftover ambiguous message, got entry=%#v reminder=%v", got, needsReminder)
+	if got != nil || hints.NeedsReminder {
+		t.Fatalf("expected no leftover ambiguous message, got entry=%#v reminder=%v", got, hints.NeedsReminder)
+	}
+}
+
+func TestTelegramInboxStoreBareCancelWhenMultiplePending(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "telegram-inbox.json")
+	store := &telegramInboxStore{
+		path: path,
+		lock: path + ".lock",
+	}
+
+	updates := []telegramUpdate{
+		{
+			UpdateID: 13,
+			Message: &telegramMessage{
+				MessageID: 9003,
+				Date:      time.Now().Unix(),
+				Text:      "/cancel",
+				Chat:      telegramChat{ID: 7003},
+			},
+		},
+	}
+	if _, _, err := store.AppendUpdates(updates); err != nil {
+		t.Fatalf("AppendUpdates: %v", err)
+	}
+
+	got, hints, err := store.ClaimForRequest(7003, 5003, 2, "", telegramAllowlist{})
+	if err != nil {
+		t.Fatalf("ClaimForRequest: %v", err)
+	}
+	if got != nil || hints.NeedsReminder || !hints.NeedsCancelChoice {
+		t.Fatalf("expected only a cancel choice hint, got entry=%#v hints=%#v", got, hints)
 	}
 }
 
@@ -166,14 +199,14 @@ func TestTelegramInboxStoreDropsIrrelevantEntriesForSinglePending(t *testing.T)
 		t.Fatalf("AppendUpdates: %v", err)
 	}
 
-	got, needsReminder, err := store.ClaimForRequest(7004, 5001, 1)
+	got, hints, err := store.ClaimForRequest(7004, 5001, 1, "", telegramAllowlist{})
 	if err != nil {
 		t.Fatalf("ClaimForRequest: %v", err)
 	}
 	if got != nil {
 		t.Fatalf("did not expect a claim, got %#v", got)
 	}
-	if needsReminder {
+	if hints.NeedsReminder {
 		t.Fatalf("did not expect reminder for single pending")
 	}
 
@@ -185,3 +218,226 @@ func TestTelegramInboxStoreDropsIrrelevantEntriesForSinglePending(t *testing.T)
 		t.Fatalf("expected irrelevant entries to be dropped, got %#v", state.Entries)
 	}
 }
+
+func TestTelegramInboxStoreStrictModeRejectsSinglePendingFreeText(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "telegram-inbox.json")
+	store := &telegramInboxStore{
+		path: path,
+		lock: path + ".lock",
+	}
+
+	now := time.Now().Unix()
+	updates := []telegramUpdate{
+		{
+			UpdateID: 31,
+			Message: &telegramMessage{
+				MessageID: 5002,
+				Date:      now,
+				Text:      "unrelated chatter",
+				Chat:      telegramChat{ID: 7005},
+			},
+		},
+	}
+	if _, _, err := store.AppendUpdates(updates); err != nil {
+		t.Fatalf("AppendUpdates: %v", err)
+	}
+
+	got, hints, err := store.ClaimForRequest(7005, 5001, 1, "req-strict", telegramAllowlist{})
+	if err != nil {
+		t.Fatalf("ClaimForRequest: %v", err)
+	}
+	if got != nil {
+		t.Fatalf("did not expect a claim in strict mode, got %#v", got)
+	}
+	if !hints.NeedsReminder {
+		t.Fatalf("expected reminder for free text in strict mode")
+	}
+}
+
+func TestTelegramInboxStoreStrictModeAcceptsRequestIDToken(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "telegram-inbox.json")
+	store := &telegramInboxStore{
+		path: path,
+		lock: path + ".lock",
+	}
+
+	now := time.Now().Unix()
+	updates := []telegramUpdate{
+		{
+			UpdateID: 41,
+			Message: &telegramMessage{
+				MessageID: 5002,
+				Date:      now,
+				Text:      "req-strict: go with B",
+				Chat:      telegramChat{ID: 7006},
+			},
+		},
+	}
+	if _, _, err := store.AppendUpdates(updates); err != nil {
+		t.Fatalf("AppendUpdates: %v", err)
+	}
+
+	got, hints, err := store.ClaimForRequest(7006, 5001, 1, "req-strict", telegramAllowlist{})
+	if err != nil {
+		t.Fatalf("ClaimForRequest: %v", err)
+	}
+	if hints.NeedsReminder {
+		t.Fatalf("did not expect reminder")
+	}
+	if got == nil || got.Text != "go with B" {
+		t.Fatalf("unexpected claimed entry: %#v", got)
+	}
+}
+
+func TestTelegramInboxStoreClaimSkipsSendersOutsideAllowlist(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "telegram-inbox.json")
+	store := &telegramInboxStore{
+		path: path,
+		lock: path + ".lock",
+	}
+
+	now := time.Now().Unix()
+	updates := []telegramUpdate{
+		{
+			UpdateID: 31,
+			Message: &telegramMessage{
+				MessageID:      9001,
+				Date:           now,
+				Text:           "ship it",
+				Chat:           telegramChat{ID: 7010},
+				From:           &telegramUser{ID: 66, Username: "mallory"},
+				ReplyToMessage: &telegramMessage{MessageID: 5001},
+			},
+		},
+		{
+			UpdateID: 32,
+			CallbackQuery: &telegramCallbackQuery{
+				ID:      "cb-mallory",
+				Data:    telegramCallbackData("req-allow", "A"),
+				From:    &telegramUser{ID: 66, Username: "mallory"},
+				Message: &telegramMessage{MessageID: 5001, Chat: telegramChat{ID: 7010}},
+			},
+		},
+		{
+			UpdateID: 33,
+			Message: &telegramMessage{
+				MessageID:      9003,
+				Date:           now,
+				Text:           "hold off",
+				Chat:           telegramChat{ID: 7010},
+				From:           &telegramUser{ID: 42, Username: "Alice"},
+				ReplyToMessage: &telegramMessage{MessageID: 5001},
+			},
+		},
+	}
+	if _, _, err := store.AppendUpdates(updates); err != nil {
+		t.Fatalf("AppendUpdates: %v", err)
+	}
+
+	allow := newTelegramAllowlist(config.TelegramConfig{AllowedUsernames: []string{"@alice"}})
+	got, hints, err := store.ClaimForRequest(7010, 5001, 1, "", allow)
+	if err != nil {
+		t.Fatalf("ClaimForRequest: %v", err)
+	}
+	if got == nil || got.Text != "hold off" || got.FromID != 42 {
+		t.Fatalf("expected the allowed reply to be claimed, got %#v", got)
+	}
+	if !hints.Unauthorized {
+		t.Fatalf("expected the rejected reply to be reported")
+	}
+	if len(hints.RejectedCallbacks) != 1 || hints.RejectedCallbacks[0] != "cb-mallory" {
+		t.Fatalf("unexpected rejected callbacks: %#v", hints.RejectedCallbacks)
+	}
+
+	if err := store.InjectLocalReply(7010, 5001, "answered at the keyboard"); err != nil {
+		t.Fatalf("InjectLocalReply: %v", err)
+	}
+	got, _, err = store.ClaimForRequest(7010, 5001, 1, "", allow)
+	if err != nil {
+		t.Fatalf("ClaimForRequest: %v", err)
+	}
+	if got == nil || got.Text != "answered at the keyboard" {
+		t.Fatalf("expected local replies to bypass the allowlist, got %#v", got)
+	}
+}
+
+func TestTelegramInboxStoreAppliesEdits(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "telegram-inbox.json")
+	store := &telegramInboxStore{path: path, lock: path + ".lock"}
+
+	reply := func(updateID int64, text string, edited bool) telegramUpdate {
+		msg := &telegramMessage{
+			MessageID:      9020,
+			Date:           time.Now().Unix(),
+			Text:           text,
+			Chat:           telegramChat{ID: 7020},
+			ReplyToMessage: &telegramMessage{MessageID: 5001},
+		}
+		if edited {
+			return telegramUpdate{UpdateID: updateID, EditedMessage: msg}
+		}
+		return telegramUpdate{UpdateID: updateID, Message: msg}
+	}
+
+	// Edited before the claim: the stored text is replaced.
+	if _, _, err := store.AppendUpdates([]telegramUpdate{reply(1, "shp it", false), reply(2, "ship it", true)}); err != nil {
+		t.Fatalf("AppendUpdates: %v", err)
+	}
+	got, _, err := store.ClaimForRequest(7020, 5001, 1, "", telegramAllowlist{})
+	if err != nil {
+		t.Fatalf("ClaimForRequest: %v", err)
+	}
+	if got == nil || got.Text != "ship it" || !got.Edited {
+		t.Fatalf("expected edited entry, got %#v", got)
+	}
+
+	// Edited after the claim: a correction is queued, never claimed as a new reply.
+	if _, _, err := store.AppendUpdates([]telegramUpdate{reply(3, "ship it today", true)}); err != nil {
+		t.Fatalf("AppendUpdates: %v", err)
+	}
+	if got, _, err := store.ClaimForRequest(7020, 5001, 1, "", telegramAllowlist{}); err != nil || got != nil {
+		t.Fatalf("expected correction not to be claimable, got %#v, %v", got, err)
+	}
+	correction, err := store.TakeCorrection(7020, 9020)
+	if err != nil {
+		t.Fatalf("TakeCorrection: %v", err)
+	}
+	if correction == nil || correction.Text != "ship it today" {
+		t.Fatalf("expected correction, got %#v", correction)
+	}
+	if correction, err := store.TakeCorrection(7020, 9020); err != nil || correction != nil {
+		t.Fatalf("expected correction to be taken once, got %#v, %v", correction, err)
+	}
+
+	// Edits to messages that were never seen are dropped.
+	unrelated := reply(4, "hello", true)
+	unrelated.EditedMessage.MessageID = 9999
+	if added, _, err := store.AppendUpdates([]telegramUpdate{unrelated}); err != nil || added != 0 {
+		t.Fatalf("expected unrelated edit to be dropped, added %d, err %v", added, err)
+	}
+}
+
+func TestTelegramInboxStoreKeepsOnlyThreadedVoiceMessages(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "telegram-inbox.json")
+	store := &telegramInboxStore{path: path, lock: path + ".lock"}
+
+	voice := &telegramVoice{FileID: "voice-1", Duration: 2}
+	updates := []telegramUpdate{
+		{UpdateID: 1, Message: &telegramMessage{MessageID: 9030, Chat: telegramChat{ID: 7030}, Voice: voice}},
+		{UpdateID: 2, Message: &telegramMessage{MessageID: 9031, Chat: telegramChat{ID: 7030}, Voice: voice, ReplyToMessage: &telegramMessage{MessageID: 5001}}},
+	}
+	added, _, err := store.AppendUpdates(updates)
+	if err != nil {
+		t.Fatalf("AppendUpdates: %v", err)
+	}
+	if added != 1 {
+		t.Fatalf("expected only the threaded voice message to be kept, added %d", added)
+	}
+	got, _, err := store.ClaimForRequest(7030, 5001, 1, "", telegramAllowlist{})
+	if err != nil {
+		t.Fatalf("ClaimForRequest: %v", err)
+	}
+	if got == nil || got.MessageID != 9031 || got.VoiceFileID != "voice-1" || got.VoiceDuration != 2 {
+		t.Fatalf("unexpected claimed entry: %#v", got)
+	}
+}
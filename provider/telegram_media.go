@@ -0,0 +1,200 @@
+package provider
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/AlhasanIQ/consult-human/contract"
+)
+
+const (
+	// Bots can only download files up to 20 MB.
+	telegramMaxDownloadBytes = 20 << 20
+
+	telegramVoiceDefaultMimeType = "audio/ogg"
+	// Telegram re-encodes photos as JPEG.
+	telegramPhotoMimeType = "image/jpeg"
+)
+
+type telegramVoice struct {
+	FileID   string `json:"file_id"`
+	Duration int    `json:"duration"`
+	MimeType string `json:"mime_type"`
+}
+
+type telegramPhotoSize struct {
+	FileID   string `json:"file_id"`
+	Width    int    `json:"width"`
+	Height   int    `json:"height"`
+	FileSize int64  `json:"file_size"`
+}
+
+type telegramFileResponse struct {
+	OK     bool `json:"ok"`
+	Result struct {
+		FilePath string `json:"file_path"`
+		FileSize int64  `json:"file_size"`
+	} `json:"result"`
+}
+
+// largestTelegramPhoto returns the file ID of the biggest size Telegram
+// offers for a photo.
+func largestTelegramPhoto(sizes []telegramPhotoSize) string {
+	best := -1
+	for i, s := range sizes {
+		if best < 0 || s.Width*s.Height > sizes[best].Width*sizes[best].Height {
+			best = i
+		}
+	}
+	if best < 0 {
+		return ""
+	}
+	return sizes[best].FileID
+}
+
+// attachPhoto downloads a photo reply into the request's media dir and
+// attaches it to reply. The caption, if any, stays the reply text.
+func (p *TelegramProvider) attachPhoto(ctx context.Context, reply *contract.Reply, messageID int64, fileID string) {
+	localPath, err := p.downloadTelegramFile(ctx, fileID, reply.RequestID, fmt.Sprintf("photo-%d", messageID))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "warning: telegram could not download photo reply to %s: %v\n", reply.RequestID, err)
+		if reply.Raw == "" {
+			reply.Raw = fmt.Sprintf("[photo, not downloaded: %v]", err)
+		}
+		return
+	}
+	reply.Attachments = append(reply.Attachments, contract.Attachment{Path: localPath, MimeType: telegramPhotoMimeType})
+	if reply.Raw == "" {
+		reply.Raw = fmt.Sprintf("[photo: %s]", localPath)
+	}
+}
+
+// attachVoice downloads a voice reply into the request's media dir and
+// attaches it to reply. With telegram.transcribe_command set, the transcript becomes the
+// reply text; if that fails the audio is still attached for the caller.
+func (p *TelegramProvider) attachVoice(ctx context.Context, reply *contract.Reply, messageID int64, voice telegramVoice) {
+	mimeType := voice.MimeType
+	if mimeType == "" {
+		mimeType = telegramVoiceDefaultMimeType
+	}
+	localPath, err := p.downloadTelegramFile(ctx, voice.FileID, reply.RequestID, fmt.Sprintf("voice-%d", messageID))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "warning: telegram could not download voice reply to %s: %v\n", reply.RequestID, err)
+		reply.Raw = fmt.Sprintf("[voice message, %ds, not downloaded: %v]", voice.Duration, err)
+		return
+	}
+	reply.Attachments = append(reply.Attachments, contract.Attachment{Path: localPath, MimeType: mimeType})
+	reply.Raw = fmt.Sprintf("[voice message, %ds: %s]", voice.Duration, localPath)
+
+	if p.transcribeCommand == "" {
+		return
+	}
+	text, err := runTranscribeCommand(ctx, p.transcribeCommand, localPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "warning: telegram voice transcription failed: %v; the audio is at %s\n", err, localPath)
+		return
+	}
+	reply.Text = text
+}
+
+// runTranscribeCommand runs command through the shell with the audio path as
+// its last argument and returns its trimmed stdout.
+func runTranscribeCommand(ctx context.Context, command, audioPath string) (string, error) {
+	cmd := exec.CommandContext(ctx, "sh", "-c", command+` "$1"`, "consult-human", audioPath)
+	cmd.Stderr = os.Stderr
+	cmd.WaitDelay = time.Second
+	out, err := cmd.Output()
+	if err != nil {
+		return "", err
+	}
+	text := strings.TrimSpace(string(out))
+	if text == "" {
+		return "", fmt.Errorf("transcribe command printed nothing")
+	}
+	return text, nil
+}
+
+// downloadTelegramFile saves the file to the request's media dir as name
+// plus the extension Telegram reports, and returns the local path.
+func (p *TelegramProvider) downloadTelegramFile(ctx context.Context, fileID, requestID, name string) (string, error) {
+	body, err := json.Marshal(map[string]any{"file_id": fileID})
+	if err != nil {
+		return "", err
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, p.baseURL+"/getFile", bytes.NewReader(body))
+	if err != nil {
+		return "", err
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	resp, err := p.client.Do(httpReq)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		b, _ := io.ReadAll(io.LimitReader(resp.Body, 2048))
+		return "", newTelegramStatusError("getFile", resp.StatusCode, b)
+	}
+	var file telegramFileResponse
+	if err := json.NewDecoder(resp.Body).Decode(&file); err != nil {
+		return "", err
+	}
+	if !file.OK || file.Result.FilePath == "" {
+		return "", fmt.Errorf("telegram getFile returned no file path")
+	}
+	if file.Result.FileSize > telegramMaxDownloadBytes {
+		return "", fmt.Errorf("file is larger than %d bytes", telegramMaxDownloadBytes)
+	}
+
+	fileURL := p.fileBaseURL + "/" + (&url.URL{Path: file.Result.FilePath}).EscapedPath()
+	fileReq, err := http.NewRequestWithContext(ctx, http.MethodGet, fileURL, nil)
+	if err != nil {
+		return "", err
+	}
+	fileResp, err := p.client.Do(fileReq)
+	if err != nil {
+		return "", err
+	}
+	defer fileResp.Body.Close()
+	if fileResp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("telegram file download status %d", fileResp.StatusCode)
+	}
+
+	dir := filepath.Join(p.mediaDir, filepath.Base(requestID))
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return "", err
+	}
+	dest := filepath.Join(dir, name+path.Ext(file.Result.FilePath))
+	tmp := dest + ".tmp"
+	f, err := os.OpenFile(tmp, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o600)
+	if err != nil {
+		return "", err
+	}
+	n, err := io.Copy(f, io.LimitReader(fileResp.Body, telegramMaxDownloadBytes+1))
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil && n > telegramMaxDownloadBytes {
+		err = fmt.Errorf("file is larger than %d bytes", telegramMaxDownloadBytes)
+	}
+	if err != nil {
+		_ = os.Remove(tmp)
+		return "", err
+	}
+	if err := os.Rename(tmp, dest); err != nil {
+		_ = os.Remove(tmp)
+		return "", err
+	}
+	return dest, nil
+}
@@ -2,33 +2,71 @@ package provider
 
 import (
 	"encoding/json"
-	"errors"
 	"fmt"
 	"os"
-	"path/filepath"
 	"runtime"
-	"strconv"
+	"sort"
 	"strings"
-	"syscall"
 	"time"
 
 	"github.com/AlhasanIQ/consult-human/config"
+	"github.com/AlhasanIQ/consult-human/filelock"
 )
 
 const (
 	telegramPendingLegacyTTL  = 24 * time.Hour
-	telegramPendingLockWait   = 3 * time.Second
-	telegramPendingLockMaxAge = 10 * time.Second
+	telegramAnsweredRetention = 24 * time.Hour
 )
 
 type telegramPendingRecord struct {
-	RequestID string    `json:"request_id"`
-	ChatID    int64     `json:"chat_id"`
-	MessageID int64     `json:"message_id"`
-	CreatedAt time.Time `json:"created_at"`
-	ExpiresAt time.Time `json:"expires_at,omitempty"`
-	OwnerPID  int       `json:"owner_pid,omitempty"`
-	OwnerHost string    `json:"owner_host,omitempty"`
+	RequestID string            `json:"request_id"`
+	ChatID    int64             `json:"chat_id"`
+	MessageID int64             `json:"message_id"`
+	CreatedAt time.Time         `json:"created_at"`
+	ExpiresAt time.Time         `json:"expires_at,omitempty"`
+	OwnerPID  int               `json:"owner_pid,omitempty"`
+	OwnerHost string            `json:"owner_host,omitempty"`
+	Priority  string            `json:"priority,omitempty"`
+	Question  string            `json:"question,omitempty"`
+	PingAt    time.Time         `json:"ping_at,omitempty"`
+	PingedAt  time.Time         `json:"pinged_at,omitempty"`
+	Tags      map[string]string `json:"tags,omitempty"`
+	// PromptText is the text of the question message, kept so it can be
+	// edited to show the answer.
+	PromptText string `json:"prompt_text,omitempty"`
+	ParseMode  string `json:"parse_mode,omitempty"`
+	// DuplicateOf names the request whose message this one waits on.
+	DuplicateOf string `json:"duplicate_of,omitempty"`
+	// Session is the ask --session name the question was sent under.
+	Session string `json:"session,omitempty"`
+	// Reactions maps the reactions that answer the question to the choice
+	// ID each stands for.
+	Reactions map[string]string `json:"reactions,omitempty"`
+	// PollID is set when the question was sent as a native poll;
+	// PollOptions are the choice IDs in option order.
+	PollID      string        `json:"poll_id,omitempty"`
+	PollOptions []string      `json:"poll_options,omitempty"`
+	PollWait    time.Duration `json:"poll_wait,omitempty"`
+	// Silent is set when the question was delivered without a notification
+	// sound; its follow-ups and reminders are silent too.
+	Silent bool `json:"silent,omitempty"`
+	// Origin is the sender label the question was asked under.
+	Origin string `json:"origin,omitempty"`
+	// ShowDeadline is set when the question message counts down to the ask
+	// deadline; Keyboard is its inline keyboard, kept across those edits.
+	ShowDeadline bool                     `json:"show_deadline,omitempty"`
+	Keyboard     [][]telegramInlineButton `json:"keyboard,omitempty"`
+	// DeferredUntil is set, with no message yet, while quiet hours hold the
+	// question back.
+	DeferredUntil time.Time `json:"deferred_until,omitempty"`
+	// LinkedProvider names the provider the question was also sent through
+	// when it was escalated, from either side.
+	LinkedProvider string `json:"linked_provider,omitempty"`
+}
+
+// deferred reports whether the question is held back and not sent yet.
+func (r telegramPendingRecord) deferred() bool {
+	return r.MessageID == 0 && !r.DeferredUntil.IsZero()
 }
 
 type telegramPendingStore struct {
@@ -52,6 +90,22 @@ func newTelegramPendingStore(cfg config.Config) (*telegramPendingStore, error) {
 	}, nil
 }
 
+// newTelegramAnsweredStore returns a store with the pending-store layout that
+// keeps answered requests around so follow-up questions can thread under them.
+func newTelegramAnsweredStore(cfg config.Config) (*telegramPendingStore, error) {
+	raw, err := config.EffectiveTelegramAnsweredStorePath(cfg)
+	if err != nil {
+		return nil, err
+	}
+	if strings.TrimSpace(raw) == "" {
+		return nil, fmt.Errorf("invalid telegram answered store path")
+	}
+	return &telegramPendingStore{
+		path: raw,
+		lock: raw + ".lock",
+	}, nil
+}
+
 func (s *telegramPendingStore) Upsert(rec telegramPendingRecord) error {
 	return s.withLock(func() error {
 		now := time.Now().UTC()
@@ -104,104 +158,159 @@ func (s *telegramPendingStore) Delete(requestID string) error {
 	})
 }
 
-func (s *telegramPendingStore) CountByChat(chatID int64) (int, error) {
-	var count int
+// ClaimPing marks the follow-up reminder for requestID as sent and reports
+// whether the caller should send it. Only the first caller after PingAt wins.
+func (s *telegramPendingStore) ClaimPing(requestID string, now time.Time) (bool, error) {
+	var claimed bool
 	err := s.withLock(func() error {
-		state, changed, err := s.loadPrunedLocked(time.Now().UTC())
+		state, _, err := s.loadPrunedLocked(now)
 		if err != nil {
 			return err
 		}
-		if changed {
-			if err := s.saveLocked(state); err != nil {
-				return err
-			}
+		rec, ok := state[requestID]
+		if !ok || rec.PingAt.IsZero() || !rec.PingedAt.IsZero() || now.Before(rec.PingAt) {
+			return nil
+		}
+		rec.PingedAt = now.UTC()
+		state[requestID] = rec
+		claimed = true
+		return s.saveLocked(state)
+	})
+	if err != nil {
+		return false, err
+	}
+	return claimed, nil
+}
+
+// Link records on requestID that the question also went out through other.
+// A request that is no longer pending is left alone.
+func (s *telegramPendingStore) Link(requestID, other string) error {
+	return s.withLock(func() error {
+		state, _, err := s.loadPrunedLocked(time.Now().UTC())
+		if err != nil {
+			return err
+		}
+		rec, ok := state[requestID]
+		if !ok {
+			return nil
+		}
+		rec.LinkedProvider = other
+		state[requestID] = rec
+		return s.saveLocked(state)
+	})
+}
+
+// ListByMessage returns every pending request waiting on the given message.
+func (s *telegramPendingStore) ListByMessage(chatID, messageID int64) ([]telegramPendingRecord, error) {
+	var out []telegramPendingRecord
+	err := s.withLock(func() error {
+		state, _, err := s.loadPrunedLocked(time.Now().UTC())
+		if err != nil {
+			return err
 		}
 		for _, rec := range state {
-			if rec.ChatID == chatID {
-				count++
+			if rec.ChatID == chatID && rec.MessageID == messageID && !rec.deferred() {
+				out = append(out, rec)
 			}
 		}
 		return nil
 	})
 	if err != nil {
-		return 0, err
+		return nil, err
 	}
-	return count, nil
+	return out, nil
 }
 
-func (s *telegramPendingStore) withLock(fn func() error) error {
-	if err := os.MkdirAll(filepath.Dir(s.path), 0o755); err != nil {
-		return err
-	}
+// ListByChat returns the chat's pending requests, oldest first. Deferred
+// questions are left out; see ListDeferredByChat.
+func (s *telegramPendingStore) ListByChat(chatID int64) ([]telegramPendingRecord, error) {
+	return s.listByChat(chatID, false)
+}
 
-	deadline := time.Now().Add(telegramPendingLockWait)
-	for {
-		lockFile, err := os.OpenFile(s.lock, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o600)
-		if err == nil {
-			_, _ = lockFile.WriteString(fmt.Sprintf("%d\n", os.Getpid()))
-			_ = lockFile.Close()
-			defer os.Remove(s.lock)
-			return fn()
-		}
-		if !os.IsExist(err) {
+// ListDeferredByChat returns the chat's questions held back by quiet hours,
+// oldest first.
+func (s *telegramPendingStore) ListDeferredByChat(chatID int64) ([]telegramPendingRecord, error) {
+	return s.listByChat(chatID, true)
+}
+
+func (s *telegramPendingStore) listByChat(chatID int64, deferred bool) ([]telegramPendingRecord, error) {
+	var out []telegramPendingRecord
+	err := s.withLock(func() error {
+		state, _, err := s.loadPrunedLocked(time.Now().UTC())
+		if err != nil {
 			return err
 		}
-		stale, staleErr := s.isStaleLock()
-		if staleErr == nil && stale {
-			_ = os.Remove(s.lock)
-			continue
-		}
-		if time.Now().After(deadline) {
-			return fmt.Errorf("timeout waiting for telegram pending store lock")
+		for _, rec := range state {
+			if rec.ChatID == chatID && rec.deferred() == deferred {
+				out = append(out, rec)
+			}
 		}
-		time.Sleep(20 * time.Millisecond)
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
+	sort.Slice(out, func(i, j int) bool { return out[i].CreatedAt.Before(out[j].CreatedAt) })
+	return out, nil
 }
 
-func (s *telegramPendingStore) isStaleLock() (bool, error) {
-	st, err := os.Stat(s.lock)
-	if err != nil {
-		if os.IsNotExist(err) {
-			return false, nil
+// LatestBySession returns the most recently created record sent under
+// session in the chat.
+func (s *telegramPendingStore) LatestBySession(chatID int64, session string) (telegramPendingRecord, bool, error) {
+	var latest telegramPendingRecord
+	var found bool
+	err := s.withLock(func() error {
+		state, _, err := s.loadPrunedLocked(time.Now().UTC())
+		if err != nil {
+			return err
 		}
-		return false, err
-	}
-
-	rawPID, _ := os.ReadFile(s.lock)
-	pid, parseErr := strconv.Atoi(strings.TrimSpace(string(rawPID)))
-	if parseErr == nil && pid > 0 && runtime.GOOS != "windows" {
-		if !processExists(pid) {
-			return true, nil
+		for _, rec := range state {
+			if rec.ChatID != chatID || rec.Session != session || rec.MessageID == 0 {
+				continue
+			}
+			if !found || rec.CreatedAt.After(latest.CreatedAt) {
+				latest, found = rec, true
+			}
 		}
-		return false, nil
+		return nil
+	})
+	if err != nil {
+		return telegramPendingRecord{}, false, err
 	}
-
-	// If we can't parse the PID, fall back to lock-file age.
-	return time.Since(st.ModTime()) > telegramPendingLockMaxAge, nil
+	return latest, found, nil
 }
 
-func processExists(pid int) bool {
-	if pid <= 0 {
-		return false
-	}
-	proc, err := os.FindProcess(pid)
-	if err != nil {
-		return false
-	}
-	err = proc.Signal(syscall.Signal(0))
-	if err == nil {
-		return true
-	}
-	var errno syscall.Errno
-	if errors.As(err, &errno) {
-		if errno == syscall.EPERM {
-			return true
+// CountByChat counts pending questions in a chat. Deduplicated requests that
+// share one message count once.
+func (s *telegramPendingStore) CountByChat(chatID int64) (int, error) {
+	var count int
+	err := s.withLock(func() error {
+		state, changed, err := s.loadPrunedLocked(time.Now().UTC())
+		if err != nil {
+			return err
 		}
-		if errno == syscall.ESRCH {
-			return false
+		if changed {
+			if err := s.saveLocked(state); err != nil {
+				return err
+			}
+		}
+		messages := make(map[int64]struct{})
+		for _, rec := range state {
+			if rec.ChatID == chatID && !rec.deferred() {
+				messages[rec.MessageID] = struct{}{}
+			}
 		}
+		count = len(messages)
+		return nil
+	})
+	if err != nil {
+		return 0, err
 	}
-	return false
+	return count, nil
+}
+
+func (s *telegramPendingStore) withLock(fn func() error) error {
+	return filelock.Lock{Path: s.lock, Name: "telegram pending store"}.With(fn)
 }
 
 func (s *telegramPendingStore) loadPrunedLocked(now time.Time) (map[string]telegramPendingRecord, bool, error) {
@@ -251,7 +360,7 @@ func isTelegramPendingOrphaned(rec telegramPendingRecord) bool {
 			return false
 		}
 	}
-	return !processExists(rec.OwnerPID)
+	return !filelock.ProcessExists(rec.OwnerPID)
 }
 
 func loadTelegramLocalHostname() string {
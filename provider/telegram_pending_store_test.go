@@ -3,9 +3,12 @@ package provider
 import (
 	"os"
 	"path/filepath"
+	"reflect"
 	"runtime"
 	"testing"
 	"time"
+
+	"github.com/AlhasanIQ/consult-human/filelock"
 )
 
 func TestTelegramPendingStoreCRUD(t *testing.T) {
@@ -287,9 +290,78 @@ func TestTelegramPendingStoreKeepsForeignHostOwner(t *testing.T) {
 func findDeadPIDForTest() int {
 	candidates := []int{999999, 4194304, 2147483000}
 	for _, pid := range candidates {
-		if pid > 0 && !processExists(pid) {
+		if pid > 0 && !filelock.ProcessExists(pid) {
 			return pid
 		}
 	}
 	return 0
 }
+
+func TestTelegramPendingStoreClaimPingOnce(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "telegram-pending.json")
+	store := &telegramPendingStore{path: path, lock: path + ".lock"}
+
+	now := time.Now().UTC()
+	if err := store.Upsert(telegramPendingRecord{
+		RequestID: "req-ping",
+		ChatID:    1,
+		MessageID: 2,
+		CreatedAt: now,
+		ExpiresAt: now.Add(time.Hour),
+		PingAt:    now.Add(time.Minute),
+	}); err != nil {
+		t.Fatalf("Upsert: %v", err)
+	}
+
+	if claimed, err := store.ClaimPing("req-ping", now); err != nil || claimed {
+		t.Fatalf("expected no claim before ping time, got %v %v", claimed, err)
+	}
+	later := now.Add(2 * time.Minute)
+	if claimed, err := store.ClaimPing("req-ping", later); err != nil || !claimed {
+		t.Fatalf("expected first claim to win, got %v %v", claimed, err)
+	}
+	if claimed, err := store.ClaimPing("req-ping", later); err != nil || claimed {
+		t.Fatalf("expected second claim to lose, got %v %v", claimed, err)
+	}
+}
+
+func TestTelegramPendingStoreRoundTripsTags(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "telegram-pending.json")
+	store := &telegramPendingStore{path: path, lock: path + ".lock"}
+
+	tags := map[string]string{"repo": "consult-human", "agent": "codex"}
+	if err := store.Upsert(telegramPendingRecord{RequestID: "req-tags", ChatID: 1, MessageID: 2, Tags: tags}); err != nil {
+		t.Fatalf("Upsert: %v", err)
+	}
+
+	reopened := &telegramPendingStore{path: path, lock: path + ".lock"}
+	got, ok, err := reopened.Get("req-tags")
+	if err != nil || !ok {
+		t.Fatalf("Get: ok=%v err=%v", ok, err)
+	}
+	if !reflect.DeepEqual(got.Tags, tags) {
+		t.Fatalf("tags did not round-trip: %#v", got.Tags)
+	}
+}
+
+func TestTelegramPendingStoreLink(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "telegram-pending.json")
+	store := &telegramPendingStore{path: path, lock: path + ".lock"}
+
+	if err := store.Upsert(telegramPendingRecord{RequestID: "req-link", ChatID: 1, MessageID: 2}); err != nil {
+		t.Fatalf("Upsert: %v", err)
+	}
+	if err := store.Link("req-link", "pushover"); err != nil {
+		t.Fatalf("Link: %v", err)
+	}
+	if err := store.Link("req-gone", "pushover"); err != nil {
+		t.Fatalf("Link on a missing request: %v", err)
+	}
+	got, ok, err := store.Get("req-link")
+	if err != nil || !ok || got.LinkedProvider != "pushover" || got.MessageID != 2 {
+		t.Fatalf("unexpected record: %#v ok=%v err=%v", got, ok, err)
+	}
+	if _, ok, _ := store.Get("req-gone"); ok {
+		t.Fatal("expected Link not to create a record")
+	}
+}
@@ -0,0 +1,292 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/AlhasanIQ/consult-human/contract"
+)
+
+// Telegram limits on sendPoll.
+const (
+	telegramPollMaxOptions       = 10
+	telegramPollQuestionMaxRunes = 300
+	telegramPollOptionMaxRunes   = 100
+)
+
+// sendPollQuestion asks req as a poll and registers it as pending. Poll
+// questions are never deduplicated and take no reactions.
+func (p *TelegramProvider) sendPollQuestion(ctx context.Context, chatID int64, req contract.AskRequest, opts telegramSendOptions) error {
+	msg, err := p.sendTelegramPoll(ctx, chatID, req, opts)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now().UTC()
+	rec := telegramPendingRecord{
+		RequestID: req.RequestID,
+		ChatID:    chatID,
+		MessageID: msg.MessageID,
+		CreatedAt: now,
+		ExpiresAt: now.Add(telegramPendingLegacyTTL),
+		Priority:  string(req.Priority),
+		Question:  questionExcerpt(req.Question, telegramQuestionExcerptMaxRunes),
+		Tags:      req.Tags,
+		Session:   req.Session,
+		PollID:    msg.Poll.ID,
+		PollWait:  req.PollWait,
+		Origin:    req.Origin,
+		Silent:    req.Silent,
+	}
+	for _, c := range req.Choices {
+		rec.PollOptions = append(rec.PollOptions, c.ID)
+	}
+	if dl, ok := ctx.Deadline(); ok {
+		rec.ExpiresAt = dl.UTC().Add(telegramPendingExpiryGrace)
+	}
+	if d := p.followUpDelay(req.Priority); d > 0 {
+		rec.PingAt = now.Add(d)
+	}
+	return p.registerPending(rec)
+}
+
+// sendTelegramPoll sends the choices of req as a non-anonymous poll, so each
+// vote arrives as a poll_answer naming the voter.
+func (p *TelegramProvider) sendTelegramPoll(ctx context.Context, chatID int64, req contract.AskRequest, opts telegramSendOptions) (telegramMessage, error) {
+	if req.Type != contract.QuestionTypeChoice || len(req.Choices) < 2 {
+		return telegramMessage{}, fmt.Errorf("telegram polls need at least two choices")
+	}
+	if len(req.Choices) > telegramPollMaxOptions {
+		return telegramMessage{}, fmt.Errorf("telegram polls allow at most %d choices, got %d", telegramPollMaxOptions, len(req.Choices))
+	}
+	question := strings.TrimSpace(req.Question)
+	if utf8.RuneCountInString(question) > telegramPollQuestionMaxRunes {
+		return telegramMessage{}, fmt.Errorf("telegram poll questions are limited to %d characters; shorten the question or ask without --poll", telegramPollQuestionMaxRunes)
+	}
+
+	options := make([]map[string]string, 0, len(req.Choices))
+	for _, c := range req.Choices {
+		text := fmt.Sprintf("%s) %s", c.ID, c.Text)
+		options = append(options, map[string]string{"text": questionExcerpt(text, telegramPollOptionMaxRunes)})
+	}
+	payload := map[string]any{
+		"chat_id":                 chatID,
+		"question":                question,
+		"options":                 options,
+		"is_anonymous":            false,
+		"allows_multiple_answers": false,
+	}
+	if opts.Silent {
+		payload["disable_notification"] = true
+	}
+	if opts.ReplyToMessageID != 0 {
+		payload["reply_parameters"] = map[string]any{
+			"message_id":                  opts.ReplyToMessageID,
+			"allow_sending_without_reply": true,
+		}
+	}
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return telegramMessage{}, err
+	}
+	msg, err := p.postTelegramSendMessageWithRetries(ctx, "sendPoll", "application/json", body)
+	if err != nil {
+		var statusErr *telegramStatusError
+		if errors.As(err, &statusErr) && statusErr.StatusCode == http.StatusBadRequest &&
+			strings.Contains(strings.ToLower(statusErr.Body), "non-anonymous") {
+			return telegramMessage{}, fmt.Errorf("telegram chat %d does not allow non-anonymous polls, so votes cannot be attributed; ask without --poll", chatID)
+		}
+		return telegramMessage{}, err
+	}
+	if msg.Poll == nil || msg.Poll.ID == "" {
+		return telegramMessage{}, fmt.Errorf("telegram sendPoll returned no poll")
+	}
+	return msg, nil
+}
+
+// stopTelegramPoll closes the question poll. It is best-effort: a poll that
+// is already closed is refused with a 400, which is skipped quietly.
+func (p *TelegramProvider) stopTelegramPoll(rec telegramPendingRecord) {
+	ctx, cancel := context.WithTimeout(context.Background(), telegramMarkAnsweredTimeout)
+	defer cancel()
+
+	body, err := json.Marshal(map[string]any{"chat_id": rec.ChatID, "message_id": rec.MessageID})
+	if err != nil {
+		return
+	}
+	if _, err := p.postTelegramSend(ctx, "stopPoll", "application/json", body); err != nil {
+		var statusErr *telegramStatusError
+		if errors.As(err, &statusErr) && statusErr.StatusCode == http.StatusBadRequest {
+			return
+		}
+		fmt.Fprintf(os.Stderr, "warning: telegram could not close poll for %s: %v\n", rec.RequestID, err)
+	}
+}
+
+// receivePoll waits for a vote that answers the poll question. A vote from an
+// allowed voter answers at once, unless the allowlist is empty and PollWait
+// is set: then every vote is tallied and the most voted option answers once
+// PollWait has passed.
+func (p *TelegramProvider) receivePoll(ctx context.Context, rec telegramPendingRecord) (contract.Reply, error) {
+	pingAt := rec.PingAt
+	var tallyAfter time.Time
+	if rec.PollWait > 0 {
+		tallyAfter = rec.CreatedAt.Add(rec.PollWait)
+	}
+	tally := newTelegramPollTally()
+	backoff := p.newPollBackoff()
+	for {
+		select {
+		case <-ctx.Done():
+			return contract.Reply{}, ctx.Err()
+		default:
+		}
+		pingAt = p.maybeSendFollowUpPing(ctx, rec, pingAt)
+
+		answers, err := p.takePollAnswers(ctx, rec.PollID)
+		if err != nil {
+			if ctx.Err() != nil {
+				return contract.Reply{}, ctx.Err()
+			}
+			if err := backoff.wait(ctx, err); err != nil {
+				return contract.Reply{}, err
+			}
+			continue
+		}
+		backoff.reset()
+
+		for _, a := range answers {
+			if a.User == nil {
+				continue
+			}
+			tally.vote(a.User.ID, a.OptionIDs)
+			decisive := rec.PollWait == 0 || !p.allowlist.empty()
+			if !decisive || len(a.OptionIDs) == 0 || !p.allowlist.allows(a.User.ID, a.User.Username) {
+				continue
+			}
+			if choiceID, ok := rec.pollChoice(a.OptionIDs[0]); ok {
+				return contract.Reply{
+					RequestID:         rec.RequestID,
+					Text:              choiceID,
+					Raw:               choiceID,
+					From:              telegramUserName(*a.User),
+					ProviderMessageID: fmt.Sprintf("%d", rec.MessageID),
+					ReceivedAt:        time.Now().UTC(),
+				}, nil
+			}
+		}
+
+		if !tallyAfter.IsZero() && !time.Now().Before(tallyAfter) {
+			if option, votes, ok := tally.plurality(); ok {
+				if choiceID, ok := rec.pollChoice(option); ok {
+					return contract.Reply{
+						RequestID:         rec.RequestID,
+						Text:              choiceID,
+						Raw:               fmt.Sprintf("%s (%d of %d votes)", choiceID, votes, tally.total()),
+						ProviderMessageID: fmt.Sprintf("%d", rec.MessageID),
+						ReceivedAt:        time.Now().UTC(),
+					}, nil
+				}
+			}
+		}
+	}
+}
+
+// takePollAnswers returns the votes in pollID received since the last call,
+// polling Telegram for more when there are none yet.
+func (p *TelegramProvider) takePollAnswers(ctx context.Context, pollID string) ([]telegramPollAnswer, error) {
+	if p.inboxStore == nil || p.pollerLock == nil {
+		updates, err := p.getUpdates(ctx)
+		if err != nil {
+			return nil, err
+		}
+		var out []telegramPollAnswer
+		for _, up := range updates {
+			if pa := up.PollAnswer; pa != nil && pa.PollID == pollID {
+				out = append(out, *pa)
+			}
+		}
+		return out, nil
+	}
+
+	entries, err := p.inboxStore.TakePollAnswers(pollID)
+	if err != nil {
+		return nil, err
+	}
+	if len(entries) > 0 {
+		out := make([]telegramPollAnswer, 0, len(entries))
+		for _, e := range entries {
+			out = append(out, telegramPollAnswer{
+				PollID: e.PollID,
+				User: &telegramUser{
+					ID:        e.FromID,
+					Username:  e.Username,
+					FirstName: e.FirstName,
+					LastName:  e.LastName,
+				},
+				OptionIDs: e.PollOptions,
+			})
+		}
+		return out, nil
+	}
+	polled, err := p.pollInboxOnce(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if !polled {
+		sleepWithContext(ctx, telegramPollerWaitInterval)
+	}
+	return nil, nil
+}
+
+// pollChoice maps a poll option index back to its choice ID.
+func (r telegramPendingRecord) pollChoice(option int) (string, bool) {
+	if option < 0 || option >= len(r.PollOptions) {
+		return "", false
+	}
+	return r.PollOptions[option], true
+}
+
+// telegramPollTally keeps each voter's current vote. A retracted vote
+// arrives with no options and removes the voter.
+type telegramPollTally struct {
+	votes map[int64]int
+}
+
+func newTelegramPollTally() *telegramPollTally {
+	return &telegramPollTally{votes: make(map[int64]int)}
+}
+
+func (t *telegramPollTally) vote(userID int64, options []int) {
+	if len(options) == 0 {
+		delete(t.votes, userID)
+		return
+	}
+	t.votes[userID] = options[0]
+}
+
+func (t *telegramPollTally) total() int { return len(t.votes) }
+
+// plurality returns the most voted option and its votes. Ties go to the
+// earlier option.
+func (t *telegramPollTally) plurality() (int, int, bool) {
+	counts := make(map[int]int)
+	for _, option := range t.votes {
+		counts[option]++
+	}
+	best, bestVotes := -1, 0
+	for option, n := range counts {
+		if n > bestVotes || (n == bestVotes && option < best) {
+			best, bestVotes = option, n
+		}
+	}
+	return best, bestVotes, bestVotes > 0
+}
@@ -0,0 +1,141 @@
+package provider
+
+import (
+	"context"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/AlhasanIQ/consult-human/contract"
+)
+
+func telegramPollAnswerUpdate(updateID int64, pollID string, userID int64, username string, options ...int) telegramUpdate {
+	return telegramUpdate{UpdateID: updateID, PollAnswer: &telegramPollAnswer{
+		PollID:    pollID,
+		User:      &telegramUser{ID: userID, Username: username},
+		OptionIDs: options,
+	}}
+}
+
+var telegramPollTestRequest = contract.AskRequest{
+	RequestID: "req-poll",
+	Question:  "Which region first?",
+	Type:      contract.QuestionTypeChoice,
+	Choices:   []contract.Choice{{ID: "A", Text: "eu-west"}, {ID: "B", Text: "us-east"}, {ID: "C", Text: "ap-south"}},
+	Poll:      true,
+}
+
+func TestTelegramPollAnsweredByAllowedVoter(t *testing.T) {
+	mock := newTelegramAPIMock()
+	mock.batches = [][]telegramUpdate{
+		{},
+		{
+			telegramPollAnswerUpdate(1, "poll-1001", 8, "mallory", 0),
+			telegramPollAnswerUpdate(2, "poll-other", 7, "alice", 2),
+			telegramPollAnswerUpdate(3, "poll-1001", 7, "alice", 1),
+		},
+	}
+	srv := httptest.NewServer(mock)
+	defer srv.Close()
+
+	p := newTestTelegramProvider(t, srv, "", func(p *TelegramProvider) { p.chatID = -777 })
+	p.allowlist = telegramAllowlist{usernames: map[string]struct{}{"alice": {}}}
+	if _, err := p.Send(context.Background(), telegramPollTestRequest); err != nil {
+		t.Fatalf("Send returned error: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	reply, err := p.Receive(ctx, telegramPollTestRequest.RequestID)
+	if err != nil {
+		t.Fatalf("Receive returned error: %v", err)
+	}
+	if reply.Text != "B" || reply.From != "alice" {
+		t.Fatalf("expected alice's vote for B to answer, got %#v", reply)
+	}
+
+	mock.mu.Lock()
+	defer mock.mu.Unlock()
+	if len(mock.sendTexts) != 0 {
+		t.Fatalf("expected no text message for a poll question, got %#v", mock.sendTexts)
+	}
+	if len(mock.pollPayloads) != 1 {
+		t.Fatalf("expected one sendPoll call, got %d", len(mock.pollPayloads))
+	}
+	payload := mock.pollPayloads[0]
+	if payload["is_anonymous"] != false || payload["question"] != "Which region first?" {
+		t.Fatalf("unexpected sendPoll payload: %#v", payload)
+	}
+	options, _ := payload["options"].([]any)
+	if len(options) != 3 || options[1].(map[string]any)["text"] != "B) us-east" {
+		t.Fatalf("unexpected poll options: %#v", payload["options"])
+	}
+	if len(mock.stoppedPolls) != 1 || mock.stoppedPolls[0] != 1001 {
+		t.Fatalf("expected the poll to be stopped once answered, got %#v", mock.stoppedPolls)
+	}
+}
+
+func TestTelegramPollWaitPicksPluralityOption(t *testing.T) {
+	mock := newTelegramAPIMock()
+	mock.batches = [][]telegramUpdate{
+		{},
+		{
+			telegramPollAnswerUpdate(1, "poll-1001", 7, "alice", 0),
+			telegramPollAnswerUpdate(2, "poll-1001", 8, "bob", 2),
+			telegramPollAnswerUpdate(3, "poll-1001", 9, "carol", 2),
+			telegramPollAnswerUpdate(4, "poll-1001", 10, "dave", 0),
+			// dave retracts his vote.
+			telegramPollAnswerUpdate(5, "poll-1001", 10, "dave"),
+		},
+	}
+	srv := httptest.NewServer(mock)
+	defer srv.Close()
+
+	p := newTestTelegramProvider(t, srv, "", func(p *TelegramProvider) { p.chatID = -777 })
+	req := telegramPollTestRequest
+	req.PollWait = 50 * time.Millisecond
+	if _, err := p.Send(context.Background(), req); err != nil {
+		t.Fatalf("Send returned error: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	reply, err := p.Receive(ctx, req.RequestID)
+	if err != nil {
+		t.Fatalf("Receive returned error: %v", err)
+	}
+	if reply.Text != "C" || reply.Raw != "C (2 of 3 votes)" {
+		t.Fatalf("expected C to win 2 of 3 votes, got %#v", reply)
+	}
+}
+
+func TestTelegramPollRejectedByChatSurfacesError(t *testing.T) {
+	mock := newTelegramAPIMock()
+	mock.pollFailureBody = `{"ok":false,"error_code":400,"description":"Bad Request: non-anonymous polls can't be sent to channel chats"}`
+	srv := httptest.NewServer(mock)
+	defer srv.Close()
+
+	p := newTestTelegramProvider(t, srv, "", func(p *TelegramProvider) { p.chatID = -777 })
+	_, err := p.Send(context.Background(), telegramPollTestRequest)
+	if err == nil || !strings.Contains(err.Error(), "does not allow non-anonymous polls") {
+		t.Fatalf("expected a non-anonymous poll error, got %v", err)
+	}
+}
+
+func TestTelegramPollTallyBreaksTiesByOption(t *testing.T) {
+	tally := newTelegramPollTally()
+	if _, _, ok := tally.plurality(); ok {
+		t.Fatalf("expected no plurality without votes")
+	}
+	tally.vote(1, []int{2})
+	tally.vote(2, []int{1})
+	option, votes, ok := tally.plurality()
+	if !ok || option != 1 || votes != 1 {
+		t.Fatalf("expected a tie to go to option 1, got %d (%d votes)", option, votes)
+	}
+	tally.vote(2, nil)
+	if option, _, _ := tally.plurality(); option != 2 || tally.total() != 1 {
+		t.Fatalf("expected the retracted vote to be dropped, got option %d of %d votes", option, tally.total())
+	}
+}
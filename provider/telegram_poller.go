@@ -0,0 +1,120 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/AlhasanIQ/consult-human/config"
+)
+
+// ErrTelegramPollerRunning is returned by RunTelegramPoller when another live
+// process already holds the poller lock.
+var ErrTelegramPollerRunning = errors.New("another consult-human telegram poller is already running")
+
+// RunTelegramPoller holds the poller lock and long-polls getUpdates into the
+// shared inbox until ctx is done, so concurrent ask processes only claim from
+// the inbox and never race each other for updates. If it dies, the lock goes
+// stale and the next waiting ask takes over polling inline.
+func RunTelegramPoller(ctx context.Context, cfg config.Config, status io.Writer) error {
+	if cfg.Telegram.ReceiveMode == config.TelegramReceiveModeWebhook {
+		return fmt.Errorf("telegram.receive_mode is webhook; updates arrive through `consult-human serve telegram-webhook` instead")
+	}
+	p, err := NewTelegram(cfg)
+	if err != nil {
+		return err
+	}
+	if err := p.ensureLongPollingReady(ctx); err != nil {
+		return err
+	}
+	return p.runPoller(ctx, status)
+}
+
+func (p *TelegramProvider) runPoller(ctx context.Context, status io.Writer) error {
+	acquired, err := p.pollerLock.TryWithLock(func() error {
+		fmt.Fprintf(status, "polling telegram updates into %s\n", p.inboxStore.path)
+		attempt := 0
+		for ctx.Err() == nil {
+			p.pollerLock.touch()
+			updates, err := p.pollIntoInbox(ctx)
+			if err != nil {
+				if ctx.Err() != nil {
+					break
+				}
+				fmt.Fprintf(os.Stderr, "warning: telegram poll failed: %v\n", err)
+				if !sleepWithContext(ctx, telegramRetryDelay(p.retryBaseDelay, attempt)) {
+					break
+				}
+				if attempt < 4 {
+					attempt++
+				}
+				continue
+			}
+			attempt = 0
+			for _, up := range updates {
+				p.linkChatFromStart(up)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if !acquired {
+		return fmt.Errorf("%w (lock %s)", ErrTelegramPollerRunning, p.pollerLock.path)
+	}
+	return nil
+}
+
+// pollIntoInbox fetches one batch of updates and appends it to the inbox.
+// The caller must hold the poller lock.
+func (p *TelegramProvider) pollIntoInbox(ctx context.Context) ([]telegramUpdate, error) {
+	offset, err := p.inboxStore.NextOffset()
+	if err != nil {
+		return nil, err
+	}
+	updates, _, err := p.getUpdatesWithOffset(ctx, offset)
+	if err != nil {
+		return nil, err
+	}
+	if _, _, err := p.inboxStore.AppendUpdates(updates); err != nil {
+		return nil, err
+	}
+	// Only the lock holder sees each update here, so /status is answered once.
+	for _, up := range updates {
+		p.answerStatusCommand(up)
+	}
+	return updates, nil
+}
+
+// linkChatFromStart saves the chat of a /start message when none is linked
+// yet, standing in for the link step Send performs when it polls itself.
+func (p *TelegramProvider) linkChatFromStart(up telegramUpdate) {
+	msg := up.Message
+	if msg == nil || p.chatIDValue() != 0 || !isTelegramStartCommand(msg.Text) {
+		return
+	}
+	p.mu.Lock()
+	p.chatID = msg.Chat.ID
+	p.mu.Unlock()
+	p.persistChatID(msg.Chat.ID)
+}
+
+// heldByOther reports whether a live process currently holds the lock.
+func (l *telegramPollerLock) heldByOther() bool {
+	if _, err := os.Stat(l.path); err != nil {
+		return false
+	}
+	stale, err := l.fileLock().Stale()
+	return err == nil && !stale
+}
+
+// touch refreshes the lock's modification time so a long-lived holder is
+// not treated as stale where the PID check is unavailable.
+func (l *telegramPollerLock) touch() {
+	now := time.Now()
+	_ = os.Chtimes(l.path, now, now)
+}
@@ -0,0 +1,182 @@
+package provider
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/AlhasanIQ/consult-human/config"
+	"github.com/AlhasanIQ/consult-human/contract"
+)
+
+func newPollerTestProvider(t *testing.T, srv *httptest.Server) *TelegramProvider {
+	t.Helper()
+	return newTestTelegramProvider(t, srv, "", func(p *TelegramProvider) {
+		p.chatID = 0
+		p.retryBaseDelay = time.Millisecond
+		p.autoPersistChatID = true
+	})
+}
+
+func TestTelegramPollerFillsInboxAndLinksChat(t *testing.T) {
+	t.Setenv(config.EnvConfigPath, filepath.Join(t.TempDir(), "config.yaml"))
+	mock := newTelegramAPIMock()
+	mock.batches = [][]telegramUpdate{
+		{{UpdateID: 7, Message: &telegramMessage{MessageID: 1, Text: "/start", Chat: telegramChat{ID: 555}}}},
+		{{UpdateID: 8, Message: &telegramMessage{MessageID: 2, Text: "hello", Chat: telegramChat{ID: 555}}}},
+	}
+	srv := httptest.NewServer(mock)
+	defer srv.Close()
+
+	p := newPollerTestProvider(t, srv)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	var status bytes.Buffer
+	go func() { done <- p.runPoller(ctx, &status) }()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		offset, err := p.inboxStore.NextOffset()
+		if err == nil && offset == 9 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("poller did not store updates, offset %d (%v)", offset, err)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if !p.pollerLock.heldByOther() {
+		t.Fatalf("expected the poller to hold the lock while running")
+	}
+	cancel()
+	if err := <-done; err != nil {
+		t.Fatalf("runPoller returned error: %v", err)
+	}
+
+	if _, err := os.Stat(p.pollerLock.path); !os.IsNotExist(err) {
+		t.Fatalf("expected poller lock to be released, stat err: %v", err)
+	}
+	if p.chatIDValue() != 555 {
+		t.Fatalf("expected /start to link chat 555, got %d", p.chatIDValue())
+	}
+	cfg, err := config.Load()
+	if err != nil || cfg.Telegram.ChatID != 555 {
+		t.Fatalf("expected linked chat to be saved, got %d (%v)", cfg.Telegram.ChatID, err)
+	}
+}
+
+func TestTelegramPollerRefusesWhenLockHeld(t *testing.T) {
+	p := newPollerTestProvider(t, nil)
+	if err := os.WriteFile(p.pollerLock.path, []byte(fmt.Sprintf("%d\n", os.Getpid())), 0o600); err != nil {
+		t.Fatalf("write lock: %v", err)
+	}
+
+	err := p.runPoller(context.Background(), &bytes.Buffer{})
+	if !errors.Is(err, ErrTelegramPollerRunning) {
+		t.Fatalf("expected ErrTelegramPollerRunning, got %v", err)
+	}
+}
+
+func TestTelegramPollInboxOnceTakesOverStaleDaemonLock(t *testing.T) {
+	mock := newTelegramAPIMock()
+	srv := httptest.NewServer(mock)
+	defer srv.Close()
+
+	p := newPollerTestProvider(t, srv)
+	// A PID that cannot belong to a live process stands in for a dead daemon.
+	if err := os.WriteFile(p.pollerLock.path, []byte("999999999\n"), 0o600); err != nil {
+		t.Fatalf("write lock: %v", err)
+	}
+
+	polled, err := p.pollInboxOnce(context.Background())
+	if err != nil {
+		t.Fatalf("pollInboxOnce returned error: %v", err)
+	}
+	if !polled {
+		t.Fatalf("expected a waiting ask to take over polling from a dead daemon")
+	}
+}
+
+func TestTelegramPollerAnswersStatusCommand(t *testing.T) {
+	mock := newTelegramAPIMock()
+	mock.batches = [][]telegramUpdate{
+		{{UpdateID: 1, Message: &telegramMessage{MessageID: 10, Text: "/status", Chat: telegramChat{ID: 555}}}},
+		{{UpdateID: 2, Message: &telegramMessage{MessageID: 11, Text: "/status", Chat: telegramChat{ID: 555}}}},
+	}
+	srv := httptest.NewServer(mock)
+	defer srv.Close()
+
+	p := newPollerTestProvider(t, srv)
+	pendingPath := filepath.Join(t.TempDir(), "telegram-pending.json")
+	p.pendingStore = &telegramPendingStore{path: pendingPath, lock: pendingPath + ".lock"}
+	if err := p.pendingStore.Upsert(telegramPendingRecord{
+		RequestID: "req-abcdef123456",
+		ChatID:    555,
+		MessageID: 5,
+		CreatedAt: time.Now().Add(-12 * time.Minute),
+		Question:  "Ship it?\nDetails follow.",
+	}); err != nil {
+		t.Fatalf("Upsert: %v", err)
+	}
+
+	for range 2 {
+		if _, err := p.pollInboxOnce(context.Background()); err != nil {
+			t.Fatalf("pollInboxOnce returned error: %v", err)
+		}
+	}
+
+	texts := mock.sentTexts()
+	if want := telegramStatusHeader + "\n1. Ship it? (12m ago, …123456)"; len(texts) != 1 || texts[0] != want {
+		t.Fatalf("expected one rate-limited status answer %q, got %#v", want, texts)
+	}
+	claimed, _, err := p.inboxStore.ClaimForRequest(555, 5, 1, "", telegramAllowlist{})
+	if err != nil || claimed != nil {
+		t.Fatalf("/status must never be claimed as a reply, got %#v (%v)", claimed, err)
+	}
+}
+
+func TestTelegramStatusTextWhenNothingWaits(t *testing.T) {
+	if got := telegramStatusText(nil, nil, time.Now()); got != telegramStatusEmptyText {
+		t.Fatalf("unexpected status text: %q", got)
+	}
+}
+
+func TestTelegramStatusTextNamesOrigin(t *testing.T) {
+	now := time.Now()
+	records := []telegramPendingRecord{{RequestID: "req-1", Question: "Ship it?", CreatedAt: now.Add(-time.Minute), Origin: "buildbox"}}
+	if got, want := telegramStatusText(records, nil, now), telegramStatusHeader+"\n1. [buildbox] Ship it? (1m ago, req-1)"; got != want {
+		t.Fatalf("status text = %q, want %q", got, want)
+	}
+}
+
+func TestTelegramDeferredQuestionsOnlyShowInStatus(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "telegram-pending.json")
+	p := &TelegramProvider{
+		chatID:       777,
+		pending:      make(map[string]int64),
+		pendingStore: &telegramPendingStore{path: path, lock: path + ".lock"},
+	}
+	until := time.Now().Add(time.Hour)
+	if err := p.Defer(context.Background(), contract.AskRequest{RequestID: "req-later", Question: "Ship it?"}, until); err != nil {
+		t.Fatalf("Defer returned error: %v", err)
+	}
+
+	if n, err := p.pendingStore.CountByChat(777); err != nil || n != 0 {
+		t.Fatalf("expected a deferred question not to count as waiting, got %d (%v)", n, err)
+	}
+	deferred, err := p.pendingStore.ListDeferredByChat(777)
+	if err != nil || len(deferred) != 1 {
+		t.Fatalf("expected one deferred question, got %#v (%v)", deferred, err)
+	}
+	want := telegramStatusHeader + "\n1. Ship it? (deferred until " + until.Format("15:04") + ", …-later)"
+	if got := telegramStatusText(p.waitingQuestions(777), deferred, time.Now()); got != want {
+		t.Fatalf("status text = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,167 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/AlhasanIQ/consult-human/config"
+	"github.com/AlhasanIQ/consult-human/filelock"
+)
+
+// telegramRateLimiter is a token bucket kept in the state directory, so
+// every process using the same bot token draws from one budget of
+// telegram.max_requests_per_second. A caller that finds the bucket empty
+// takes a token anyway and waits until it would have been refilled, which
+// keeps callers in the order they arrived.
+type telegramRateLimiter struct {
+	path  string
+	lock  string
+	rate  float64
+	burst float64
+}
+
+// telegramRateLimitState is the bucket as stored, with counters of how
+// often it made callers wait.
+type telegramRateLimitState struct {
+	Tokens    float64   `json:"tokens"`
+	UpdatedAt time.Time `json:"updated_at"`
+	Requests  int64     `json:"requests"`
+	Delayed   int64     `json:"delayed"`
+	WaitedMS  int64     `json:"waited_ms"`
+}
+
+// telegramRateLimitStats counts the requests that went through the bucket,
+// how many of them had to wait, and for how long in total.
+type telegramRateLimitStats struct {
+	Requests int64
+	Delayed  int64
+	Waited   time.Duration
+}
+
+// newTelegramRateLimiter returns nil when telegram.max_requests_per_second
+// is 0, which turns the limit off.
+func newTelegramRateLimiter(cfg config.Config) (*telegramRateLimiter, error) {
+	rate := cfg.Telegram.MaxRequestsPerSec
+	if rate <= 0 {
+		return nil, nil
+	}
+	raw, err := config.EffectiveTelegramRateLimitPath(cfg)
+	if err != nil {
+		return nil, err
+	}
+	if strings.TrimSpace(raw) == "" {
+		return nil, fmt.Errorf("invalid telegram rate limit store path")
+	}
+	return &telegramRateLimiter{
+		path:  raw,
+		lock:  raw + ".lock",
+		rate:  rate,
+		burst: max(rate, 1),
+	}, nil
+}
+
+// Wait takes a token from the bucket, sleeping until it is due. It returns
+// ctx's error, without sleeping in vain, when ctx ends before then. A bucket
+// that cannot be read or written is reported and does not hold the request
+// back.
+func (l *telegramRateLimiter) Wait(ctx context.Context) error {
+	wait, err := l.reserve(time.Now())
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "warning: telegram rate limit store failed: %v\n", err)
+		return nil
+	}
+	if wait > 0 && !sleepWithContext(ctx, wait) {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		return context.DeadlineExceeded
+	}
+	return nil
+}
+
+// reserve takes a token at now and returns how long the caller has to wait
+// for it.
+func (l *telegramRateLimiter) reserve(now time.Time) (time.Duration, error) {
+	var wait time.Duration
+	err := l.withLock(func() error {
+		state, err := l.loadLocked()
+		if err != nil {
+			return err
+		}
+		if state.UpdatedAt.IsZero() {
+			state.Tokens = l.burst
+		} else if elapsed := now.Sub(state.UpdatedAt).Seconds(); elapsed > 0 {
+			state.Tokens = min(l.burst, state.Tokens+elapsed*l.rate)
+		}
+		state.UpdatedAt = now
+		state.Tokens--
+		state.Requests++
+		if state.Tokens < 0 {
+			wait = time.Duration(-state.Tokens / l.rate * float64(time.Second))
+			state.Delayed++
+			state.WaitedMS += wait.Milliseconds()
+		}
+		return l.saveLocked(state)
+	})
+	return wait, err
+}
+
+// Stats returns the bucket's counters.
+func (l *telegramRateLimiter) Stats() (telegramRateLimitStats, error) {
+	var stats telegramRateLimitStats
+	err := l.withLock(func() error {
+		state, err := l.loadLocked()
+		if err != nil {
+			return err
+		}
+		stats = telegramRateLimitStats{
+			Requests: state.Requests,
+			Delayed:  state.Delayed,
+			Waited:   time.Duration(state.WaitedMS) * time.Millisecond,
+		}
+		return nil
+	})
+	return stats, err
+}
+
+func (l *telegramRateLimiter) withLock(fn func() error) error {
+	return filelock.Lock{Path: l.lock, Name: "telegram rate limit store"}.With(fn)
+}
+
+func (l *telegramRateLimiter) loadLocked() (telegramRateLimitState, error) {
+	var state telegramRateLimitState
+	b, err := os.ReadFile(l.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return state, nil
+		}
+		return state, err
+	}
+	if len(b) == 0 {
+		return state, nil
+	}
+	if err := json.Unmarshal(b, &state); err != nil {
+		return telegramRateLimitState{}, fmt.Errorf("parse telegram rate limit store: %w", err)
+	}
+	return state, nil
+}
+
+func (l *telegramRateLimiter) saveLocked(state telegramRateLimitState) error {
+	b, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+	tmp := l.path + ".tmp"
+	if err := os.WriteFile(tmp, b, 0o600); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, l.path); err != nil {
+		_ = os.Remove(tmp)
+		return err
+	}
+	return nil
+}
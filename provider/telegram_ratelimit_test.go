@@ -0,0 +1,88 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/AlhasanIQ/consult-human/config"
+)
+
+func newTestRateLimiter(t *testing.T, path string, rate float64) *telegramRateLimiter {
+	t.Helper()
+	return &telegramRateLimiter{path: path, lock: path + ".lock", rate: rate, burst: max(rate, 1)}
+}
+
+func TestTelegramRateLimiterSharesBudgetAcrossInstances(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "telegram-ratelimit.json")
+	// Two limiters on one file stand in for two processes with the same token.
+	a, b := newTestRateLimiter(t, path, 2), newTestRateLimiter(t, path, 2)
+	now := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	var waits []time.Duration
+	for _, l := range []*telegramRateLimiter{a, b, a, b} {
+		wait, err := l.reserve(now)
+		if err != nil {
+			t.Fatalf("reserve: %v", err)
+		}
+		waits = append(waits, wait)
+	}
+	want := []time.Duration{0, 0, 500 * time.Millisecond, time.Second}
+	for i := range want {
+		if waits[i] != want[i] {
+			t.Fatalf("want waits %v, got %v", want, waits)
+		}
+	}
+
+	// Two seconds later the debt is paid and the bucket is full again.
+	if wait, err := b.reserve(now.Add(3 * time.Second)); err != nil || wait != 0 {
+		t.Fatalf("expected a refilled bucket, got wait %s (%v)", wait, err)
+	}
+
+	stats, err := a.Stats()
+	if err != nil {
+		t.Fatalf("Stats: %v", err)
+	}
+	if stats != (telegramRateLimitStats{Requests: 5, Delayed: 2, Waited: 1500 * time.Millisecond}) {
+		t.Fatalf("unexpected stats %+v", stats)
+	}
+}
+
+func TestTelegramRateLimiterWaitHonorsContext(t *testing.T) {
+	l := newTestRateLimiter(t, filepath.Join(t.TempDir(), "telegram-ratelimit.json"), 0.1)
+	if err := l.Wait(context.Background()); err != nil {
+		t.Fatalf("expected the first request to go through, got %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	if err := l.Wait(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected the wait to end with the context, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("expected Wait to return when the context ended, took %s", elapsed)
+	}
+}
+
+func TestNewTelegramRateLimiterPerToken(t *testing.T) {
+	cfg := config.Default()
+	cfg.Telegram.PendingStorePath = filepath.Join(t.TempDir(), "pending.json")
+	cfg.Telegram.BotToken = "111:AAA"
+	first, err := newTelegramRateLimiter(cfg)
+	if err != nil || first == nil {
+		t.Fatalf("expected a limiter by default, got %v (%v)", first, err)
+	}
+	cfg.Telegram.BotToken = "222:BBB"
+	second, err := newTelegramRateLimiter(cfg)
+	if err != nil || second == nil || second.path == first.path {
+		t.Fatalf("expected each bot token to get its own budget, got %v and %v (%v)", first, second, err)
+	}
+
+	cfg.Telegram.MaxRequestsPerSec = 0
+	if off, err := newTelegramRateLimiter(cfg); err != nil || off != nil {
+		t.Fatalf("expected 0 to turn the limit off, got %v (%v)", off, err)
+	}
+}
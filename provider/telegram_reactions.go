@@ -0,0 +1,119 @@
+package provider
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/AlhasanIQ/consult-human/contract"
+)
+
+// telegramReactionSettleWindow is how long a reaction waits in the inbox
+// before it can be claimed, so a quickly changed reaction replaces it.
+var telegramReactionSettleWindow = 2 * time.Second
+
+// reactionAnswers maps the configured reactions to the choices of req they
+// stand for. Only choice questions with a matching choice take reactions.
+func (p *TelegramProvider) reactionAnswers(req contract.AskRequest) map[string]string {
+	if req.Type != contract.QuestionTypeChoice {
+		return nil
+	}
+	var out map[string]string
+	for emoji, answer := range p.reactions {
+		for _, c := range req.Choices {
+			if strings.EqualFold(c.ID, answer) || strings.EqualFold(strings.TrimSpace(c.Text), answer) {
+				if out == nil {
+					out = map[string]string{}
+				}
+				out[emoji] = c.ID
+				break
+			}
+		}
+	}
+	return out
+}
+
+// telegramReactionEmoji is the emoji the user now reacts with, or "" when
+// they removed their reaction or used a custom emoji.
+func telegramReactionEmoji(mr *telegramMessageReactionUpdated) string {
+	for _, r := range mr.NewReaction {
+		if r.Type == "emoji" && r.Emoji != "" {
+			return r.Emoji
+		}
+	}
+	return ""
+}
+
+// checkReactionsDelivered warns once per group chat when the bot is not an
+// admin there: Telegram only sends bots reaction updates from groups they
+// administer.
+func (p *TelegramProvider) checkReactionsDelivered(ctx context.Context, chatID int64) {
+	if chatID >= 0 {
+		return
+	}
+	p.mu.Lock()
+	if p.adminChecked == nil {
+		p.adminChecked = make(map[int64]bool)
+	}
+	checked := p.adminChecked[chatID]
+	p.adminChecked[chatID] = true
+	p.mu.Unlock()
+	if checked {
+		return
+	}
+
+	bot, err := p.botUser(ctx)
+	if err != nil {
+		return
+	}
+	status, err := p.getChatMemberStatus(ctx, chatID, bot.ID)
+	if err != nil {
+		return
+	}
+	if status != "administrator" && status != "creator" {
+		fmt.Fprintf(os.Stderr, "warning: the bot is not an admin in telegram chat %d, so reactions there are not delivered; make it an admin or answer by replying\n", chatID)
+	}
+}
+
+func (p *TelegramProvider) getChatMemberStatus(ctx context.Context, chatID, userID int64) (string, error) {
+	body, err := json.Marshal(map[string]any{"chat_id": chatID, "user_id": userID})
+	if err != nil {
+		return "", err
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, p.baseURL+"/getChatMember", bytes.NewReader(body))
+	if err != nil {
+		return "", err
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	resp, err := p.client.Do(httpReq)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		b, _ := io.ReadAll(io.LimitReader(resp.Body, 2048))
+		return "", newTelegramStatusError("getChatMember", resp.StatusCode, b)
+	}
+
+	var decoded struct {
+		OK     bool `json:"ok"`
+		Result struct {
+			Status string `json:"status"`
+		} `json:"result"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&decoded); err != nil {
+		return "", err
+	}
+	if !decoded.OK {
+		return "", fmt.Errorf("telegram getChatMember failed")
+	}
+	return decoded.Result.Status, nil
+}
@@ -0,0 +1,122 @@
+package provider
+
+import (
+	"context"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/AlhasanIQ/consult-human/config"
+	"github.com/AlhasanIQ/consult-human/contract"
+)
+
+func stubTelegramReactionSettleWindow(t *testing.T, d time.Duration) {
+	t.Helper()
+	orig := telegramReactionSettleWindow
+	telegramReactionSettleWindow = d
+	t.Cleanup(func() { telegramReactionSettleWindow = orig })
+}
+
+func telegramReactionUpdate(updateID, chatID, messageID, userID int64, emoji string) telegramUpdate {
+	mr := &telegramMessageReactionUpdated{
+		Chat:      telegramChat{ID: chatID},
+		MessageID: messageID,
+		User:      &telegramUser{ID: userID, Username: "alice"},
+		Date:      time.Now().Unix(),
+	}
+	if emoji != "" {
+		mr.NewReaction = []telegramReactionType{{Type: "emoji", Emoji: emoji}}
+	}
+	return telegramUpdate{UpdateID: updateID, MessageReaction: mr}
+}
+
+func TestTelegramReceiveAcceptsChangedReaction(t *testing.T) {
+	stubTelegramReactionSettleWindow(t, 0)
+	mock := newTelegramAPIMock()
+	mock.batches = [][]telegramUpdate{
+		{},
+		{
+			telegramReactionUpdate(1, 777, 1001, 7, "👍"),
+			telegramReactionUpdate(2, 777, 1001, 7, "👎"),
+		},
+	}
+	srv := httptest.NewServer(mock)
+	defer srv.Close()
+
+	reactions, err := config.ParseTelegramReactions(config.DefaultTelegramReactions)
+	if err != nil {
+		t.Fatalf("ParseTelegramReactions returned error: %v", err)
+	}
+	dir := t.TempDir()
+	pendingPath := filepath.Join(dir, "telegram-pending.json")
+	inboxPath := filepath.Join(dir, "telegram-inbox.json")
+	p := &TelegramProvider{
+		chatID:       777,
+		pollInterval: 10 * time.Millisecond,
+		baseURL:      srv.URL,
+		client:       srv.Client(),
+		reactions:    reactions,
+		pending:      make(map[string]int64),
+		pendingStore: &telegramPendingStore{path: pendingPath, lock: pendingPath + ".lock"},
+		inboxStore:   &telegramInboxStore{path: inboxPath, lock: inboxPath + ".lock"},
+		pollerLock:   &telegramPollerLock{path: filepath.Join(dir, "telegram-poller.lock")},
+	}
+	req := contract.AskRequest{
+		RequestID: "req-react",
+		Question:  "Deploy now?",
+		Type:      contract.QuestionTypeChoice,
+		Choices:   []contract.Choice{{ID: "1", Text: "Yes"}, {ID: "2", Text: "No"}},
+	}
+	if _, err := p.Send(context.Background(), req); err != nil {
+		t.Fatalf("Send returned error: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	reply, err := p.Receive(ctx, req.RequestID)
+	if err != nil {
+		t.Fatalf("Receive returned error: %v", err)
+	}
+	if reply.Text != "2" || reply.From != "alice" {
+		t.Fatalf("expected the changed reaction to answer no, got %#v", reply)
+	}
+}
+
+func TestTelegramInboxDropsRemovedAndStrayReactions(t *testing.T) {
+	stubTelegramReactionSettleWindow(t, 0)
+	path := filepath.Join(t.TempDir(), "telegram-inbox.json")
+	store := &telegramInboxStore{path: path, lock: path + ".lock"}
+
+	if _, _, err := store.AppendUpdates([]telegramUpdate{
+		telegramReactionUpdate(1, 777, 1001, 7, "👍"),
+		telegramReactionUpdate(2, 777, 1001, 7, ""),
+		telegramReactionUpdate(3, 777, 555, 7, "👍"),
+	}); err != nil {
+		t.Fatalf("AppendUpdates returned error: %v", err)
+	}
+	claimed, _, err := store.ClaimForRequest(777, 1001, 1, "", telegramAllowlist{})
+	if err != nil {
+		t.Fatalf("ClaimForRequest returned error: %v", err)
+	}
+	if claimed != nil {
+		t.Fatalf("expected removed and stray reactions to be ignored, claimed %#v", claimed)
+	}
+}
+
+func TestTelegramReactionAnswersOnlyForMatchingChoices(t *testing.T) {
+	p := &TelegramProvider{reactions: map[string]string{"👍": "yes", "👎": "no"}}
+
+	open := contract.AskRequest{Question: "Ship it?", Type: contract.QuestionTypeOpen}
+	if got := p.reactionAnswers(open); got != nil {
+		t.Fatalf("open questions should not take reactions, got %#v", got)
+	}
+	choice := contract.AskRequest{
+		Type:    contract.QuestionTypeChoice,
+		Choices: []contract.Choice{{ID: "yes", Text: "Ship it"}, {ID: "B", Text: "Wait"}},
+	}
+	got := p.reactionAnswers(choice)
+	if len(got) != 1 || got["👍"] != "yes" {
+		t.Fatalf("expected only 👍 mapped to yes, got %#v", got)
+	}
+}
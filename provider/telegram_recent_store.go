@@ -0,0 +1,139 @@
+package provider
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/AlhasanIQ/consult-human/config"
+	"github.com/AlhasanIQ/consult-human/contract"
+	"github.com/AlhasanIQ/consult-human/filelock"
+)
+
+const telegramDefaultDedupeWindow = 2 * time.Minute
+
+// telegramRecentQuestion remembers which request first asked a question, so
+// an identical re-ask shortly after can wait on the same Telegram message.
+type telegramRecentQuestion struct {
+	RequestID string    `json:"request_id"`
+	ChatID    int64     `json:"chat_id"`
+	AskedAt   time.Time `json:"asked_at"`
+}
+
+type telegramRecentStore struct {
+	path string
+	lock string
+}
+
+func newTelegramRecentStore(cfg config.Config) (*telegramRecentStore, error) {
+	raw, err := config.EffectiveTelegramRecentStorePath(cfg)
+	if err != nil {
+		return nil, err
+	}
+	if strings.TrimSpace(raw) == "" {
+		return nil, fmt.Errorf("invalid telegram recent-questions store path")
+	}
+	return &telegramRecentStore{
+		path: raw,
+		lock: raw + ".lock",
+	}, nil
+}
+
+// telegramQuestionKey identifies a question by chat, text and choices.
+func telegramQuestionKey(chatID int64, req contract.AskRequest) string {
+	h := sha256.New()
+	fmt.Fprintf(h, "%d\x00%s", chatID, strings.TrimSpace(req.Question))
+	for _, c := range req.Choices {
+		fmt.Fprintf(h, "\x00%s\x00%s", c.ID, c.Text)
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// Lookup returns the question recorded under key if it was asked within window.
+func (s *telegramRecentStore) Lookup(key string, window time.Duration, now time.Time) (telegramRecentQuestion, bool, error) {
+	var out telegramRecentQuestion
+	var ok bool
+	err := s.withLock(func() error {
+		state, err := s.loadLocked()
+		if err != nil {
+			return err
+		}
+		if pruneRecentQuestions(state, window, now) {
+			if err := s.saveLocked(state); err != nil {
+				return err
+			}
+		}
+		out, ok = state[key]
+		return nil
+	})
+	if err != nil {
+		return telegramRecentQuestion{}, false, err
+	}
+	return out, ok, nil
+}
+
+func (s *telegramRecentStore) Remember(key string, q telegramRecentQuestion, window time.Duration) error {
+	return s.withLock(func() error {
+		state, err := s.loadLocked()
+		if err != nil {
+			return err
+		}
+		pruneRecentQuestions(state, window, q.AskedAt)
+		state[key] = q
+		return s.saveLocked(state)
+	})
+}
+
+func pruneRecentQuestions(state map[string]telegramRecentQuestion, window time.Duration, now time.Time) bool {
+	changed := false
+	for key, q := range state {
+		if now.Sub(q.AskedAt) > window {
+			delete(state, key)
+			changed = true
+		}
+	}
+	return changed
+}
+
+func (s *telegramRecentStore) withLock(fn func() error) error {
+	return filelock.Lock{Path: s.lock, Name: "telegram recent-questions store"}.With(fn)
+}
+
+func (s *telegramRecentStore) loadLocked() (map[string]telegramRecentQuestion, error) {
+	b, err := os.ReadFile(s.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return make(map[string]telegramRecentQuestion), nil
+		}
+		return nil, err
+	}
+	if len(b) == 0 {
+		return make(map[string]telegramRecentQuestion), nil
+	}
+
+	state := make(map[string]telegramRecentQuestion)
+	if err := json.Unmarshal(b, &state); err != nil {
+		return nil, fmt.Errorf("parse telegram recent-questions store: %w", err)
+	}
+	return state, nil
+}
+
+func (s *telegramRecentStore) saveLocked(state map[string]telegramRecentQuestion) error {
+	b, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, b, 0o600); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, s.path); err != nil {
+		_ = os.Remove(tmp)
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,88 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/AlhasanIQ/consult-human/contract"
+)
+
+// telegramDefaultCodeBlockMaxLines is how many lines a fenced code block may
+// have before it is sent as a file instead of inline.
+const telegramDefaultCodeBlockMaxLines = 40
+
+const telegramCodeFence = "```"
+
+// telegramSnippet is a fenced code block taken out of a question.
+type telegramSnippet struct {
+	Name    string
+	Content string
+}
+
+// sendSpilledSnippets uploads the fenced code blocks of req's question that
+// are longer than codeBlockMaxLines and returns req with each replaced by a
+// pointer to its file. The question message sent afterwards stays the reply
+// target.
+func (p *TelegramProvider) sendSpilledSnippets(ctx context.Context, chatID int64, req contract.AskRequest, opts telegramSendOptions) (contract.AskRequest, error) {
+	if p.codeBlockMaxLines <= 0 {
+		return req, nil
+	}
+	question, snippets := spillTelegramCodeBlocks(req.Question, p.codeBlockMaxLines)
+	if len(snippets) == 0 {
+		return req, nil
+	}
+	docOpts := telegramSendOptions{Silent: opts.Silent}
+	for _, s := range snippets {
+		p.sendChatAction(ctx, chatID, telegramChatActionUploadDocument)
+		if _, err := p.sendTelegramDocument(ctx, chatID, s.Name, []byte(s.Content), "", docOpts); err != nil {
+			return req, fmt.Errorf("telegram could not attach %s: %w", s.Name, err)
+		}
+	}
+	p.sendChatAction(ctx, chatID, telegramChatActionTyping)
+	req.Question = question
+	return req, nil
+}
+
+// spillTelegramCodeBlocks replaces every fenced code block of more than
+// maxLines lines with "(see attached snippet-<n>.<ext>)" and returns the
+// blocks. Blocks fenced as diff or patch become .diff files, the rest .txt.
+// An unclosed fence is left as it is.
+func spillTelegramCodeBlocks(text string, maxLines int) (string, []telegramSnippet) {
+	lines := strings.Split(text, "\n")
+	var out []string
+	var snippets []telegramSnippet
+	for i := 0; i < len(lines); i++ {
+		lang, ok := strings.CutPrefix(strings.TrimSpace(lines[i]), telegramCodeFence)
+		if !ok {
+			out = append(out, lines[i])
+			continue
+		}
+		end := -1
+		for j := i + 1; j < len(lines); j++ {
+			if strings.TrimSpace(lines[j]) == telegramCodeFence {
+				end = j
+				break
+			}
+		}
+		if end < 0 {
+			out = append(out, lines[i:]...)
+			break
+		}
+		body := lines[i+1 : end]
+		if len(body) <= maxLines {
+			out = append(out, lines[i:end+1]...)
+		} else {
+			ext := "txt"
+			switch strings.ToLower(strings.TrimSpace(lang)) {
+			case "diff", "patch":
+				ext = "diff"
+			}
+			name := fmt.Sprintf("snippet-%d.%s", len(snippets)+1, ext)
+			snippets = append(snippets, telegramSnippet{Name: name, Content: strings.Join(body, "\n") + "\n"})
+			out = append(out, "(see attached "+name+")")
+		}
+		i = end
+	}
+	return strings.Join(out, "\n"), snippets
+}
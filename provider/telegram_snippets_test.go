@@ -0,0 +1,82 @@
+package provider
+
+import (
+	"context"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/AlhasanIQ/consult-human/contract"
+)
+
+func telegramTestCodeBlock(lang string, lines int) string {
+	body := make([]string, lines)
+	for i := range body {
+		body[i] = "+line"
+	}
+	return "```" + lang + "\n" + strings.Join(body, "\n") + "\n```"
+}
+
+func TestSpillTelegramCodeBlocks(t *testing.T) {
+	text := "Apply this?\n" + telegramTestCodeBlock("diff", 5) + "\nor this:\n" + telegramTestCodeBlock("go", 2) + "\nand\n" + telegramTestCodeBlock("", 4)
+	got, snippets := spillTelegramCodeBlocks(text, 3)
+
+	want := "Apply this?\n(see attached snippet-1.diff)\nor this:\n" + telegramTestCodeBlock("go", 2) + "\nand\n(see attached snippet-2.txt)"
+	if got != want {
+		t.Fatalf("unexpected text:\n%s", got)
+	}
+	if len(snippets) != 2 || snippets[0].Name != "snippet-1.diff" || snippets[1].Name != "snippet-2.txt" {
+		t.Fatalf("unexpected snippets: %#v", snippets)
+	}
+	if snippets[0].Content != strings.Repeat("+line\n", 5) {
+		t.Fatalf("unexpected snippet content: %q", snippets[0].Content)
+	}
+
+	unclosed := "Look:\n```\n" + strings.Repeat("x\n", 10)
+	if got, snippets := spillTelegramCodeBlocks(unclosed, 3); got != unclosed || len(snippets) != 0 {
+		t.Fatalf("expected an unclosed fence to stay inline, got %q and %#v", got, snippets)
+	}
+}
+
+func TestTelegramSendAttachesLongCodeBlocks(t *testing.T) {
+	mock := newTelegramAPIMock()
+	srv := httptest.NewServer(mock)
+	defer srv.Close()
+
+	p := &TelegramProvider{
+		chatID:            777,
+		baseURL:           srv.URL,
+		client:            srv.Client(),
+		codeBlockMaxLines: telegramDefaultCodeBlockMaxLines,
+		pending:           make(map[string]int64),
+	}
+	req := contract.AskRequest{
+		RequestID: "req-diff",
+		Question:  "Apply this patch?\n" + telegramTestCodeBlock("diff", 300),
+		Type:      contract.QuestionTypeOpen,
+	}
+	if _, err := p.Send(context.Background(), req); err != nil {
+		t.Fatalf("Send returned error: %v", err)
+	}
+
+	docs := mock.sentDocuments()
+	if len(docs) != 1 || docs[0].Filename != "snippet-1.diff" || strings.Count(docs[0].Content, "\n") != 300 {
+		t.Fatalf("expected the diff attached as snippet-1.diff, got %#v", docs)
+	}
+	texts := mock.sentTexts()
+	if len(texts) != 1 || texts[0] != "Apply this patch?\n(see attached snippet-1.diff)" {
+		t.Fatalf("unexpected question text: %#v", texts)
+	}
+	if p.pending["req-diff"] != 1002 {
+		t.Fatalf("expected the question message to be the reply target, got %d", p.pending["req-diff"])
+	}
+
+	p.codeBlockMaxLines = 0
+	req.RequestID = "req-inline"
+	if _, err := p.Send(context.Background(), req); err != nil {
+		t.Fatalf("Send returned error: %v", err)
+	}
+	if docs := mock.sentDocuments(); len(docs) != 1 {
+		t.Fatalf("expected inline code blocks to stay in the message, got %d documents", len(docs))
+	}
+}
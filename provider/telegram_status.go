@@ -0,0 +1,99 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// telegramStatusCooldown limits /status answers per chat so repeated
+// requests do not use up the chat's send quota.
+const telegramStatusCooldown = 10 * time.Second
+
+const (
+	telegramStatusHeader       = "Waiting on:"
+	telegramStatusEmptyText    = "No questions are waiting."
+	telegramStatusRequestIDLen = 6
+)
+
+// answerStatusCommand replies to a /status message with the chat's waiting
+// questions. Other updates, senders outside the allowlist, and requests
+// within telegramStatusCooldown of the last answer are ignored.
+func (p *TelegramProvider) answerStatusCommand(up telegramUpdate) {
+	msg := up.Message
+	if msg == nil || !isTelegramStatusCommand(msg.Text) {
+		return
+	}
+	if from := msg.From; !p.allowlist.empty() && (from == nil || !p.allowlist.allows(from.ID, from.Username)) {
+		return
+	}
+	chatID := msg.Chat.ID
+
+	p.mu.Lock()
+	now := time.Now()
+	if last := p.lastStatusAt[chatID]; !last.IsZero() && now.Sub(last) < telegramStatusCooldown {
+		p.mu.Unlock()
+		return
+	}
+	if p.lastStatusAt == nil {
+		p.lastStatusAt = make(map[int64]time.Time)
+	}
+	p.lastStatusAt[chatID] = now
+	p.mu.Unlock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if !p.paceChat(ctx, chatID) {
+		return
+	}
+	var deferred []telegramPendingRecord
+	if p.pendingStore != nil {
+		deferred, _ = p.pendingStore.ListDeferredByChat(chatID)
+	}
+	_, _ = p.sendTelegramMessage(ctx, chatID, telegramStatusText(p.waitingQuestions(chatID), deferred, now), telegramSendOptions{
+		Silent:           true,
+		ReplyToMessageID: msg.MessageID,
+	})
+}
+
+// telegramStatusText lists the waiting questions, with the provider each was
+// escalated to if any, then those deferred by quiet hours with the local time
+// they will be sent.
+func telegramStatusText(records, deferred []telegramPendingRecord, now time.Time) string {
+	if len(records) == 0 && len(deferred) == 0 {
+		return telegramStatusEmptyText
+	}
+	var b strings.Builder
+	b.WriteString(telegramStatusHeader)
+	for i, rec := range records {
+		linked := ""
+		if rec.LinkedProvider != "" {
+			linked = ", also via " + rec.LinkedProvider
+		}
+		fmt.Fprintf(&b, "\n%d. %s%s (%s ago%s, %s)", i+1, telegramOriginPrefix(rec.Origin), questionExcerpt(rec.Question, telegramQuestionExcerptMaxRunes), telegramAge(now.Sub(rec.CreatedAt)), linked, telegramStatusRequestID(rec.RequestID))
+	}
+	for i, rec := range deferred {
+		fmt.Fprintf(&b, "\n%d. %s%s (deferred until %s, %s)", len(records)+i+1, telegramOriginPrefix(rec.Origin), questionExcerpt(rec.Question, telegramQuestionExcerptMaxRunes), rec.DeferredUntil.Format("15:04"), telegramStatusRequestID(rec.RequestID))
+	}
+	return b.String()
+}
+
+func telegramStatusRequestID(id string) string {
+	if len(id) > telegramStatusRequestIDLen {
+		return "…" + id[len(id)-telegramStatusRequestIDLen:]
+	}
+	return id
+}
+
+// telegramAge formats d coarsely, such as "45s", "12m" or "3h5m".
+func telegramAge(d time.Duration) string {
+	switch {
+	case d < time.Minute:
+		return fmt.Sprintf("%ds", max(int(d/time.Second), 0))
+	case d < time.Hour:
+		return fmt.Sprintf("%dm", int(d/time.Minute))
+	default:
+		return fmt.Sprintf("%dh%dm", int(d/time.Hour), int(d%time.Hour/time.Minute))
+	}
+}
@@ -1,15 +1,22 @@
 package provider
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
+	"io"
 	"net/http"
 	"net/http/httptest"
+	"os"
 	"path/filepath"
+	"reflect"
+	"strconv"
 	"strings"
 	"sync"
 	"testing"
 	"time"
+	"unicode/utf8"
 
 	"github.com/AlhasanIQ/consult-human/config"
 	"github.com/AlhasanIQ/consult-human/contract"
@@ -21,12 +28,64 @@ type telegramAPIMock struct {
 	getIndex   int
 	sendCount  int
 	sendTexts  []string
+	sendBodies []map[string]any
 	nextMsgID  int64
 	statusCode int
 	webhookURL string
 
-	webhookInfoCalls   int
-	getUpdatesPayloads []map[string]any
+	sendFailures      int
+	sendFailureStatus int
+	sendFailureBody   string
+	sendAttempts      int
+
+	documents []telegramMockDocument
+
+	answeredCallbacks []string
+
+	edits      []telegramMockEdit
+	editStatus int
+
+	deletedMessages []int64
+
+	getUpdatesFailures    int
+	getUpdatesFailureBody string
+	// getUpdatesStatuses scripts one response status per getUpdates call;
+	// 0 serves the next batch. Each call's time goes to getUpdatesTimes.
+	getUpdatesStatuses []int
+	getUpdatesTimes    []time.Time
+
+	webhookInfoCalls      int
+	setWebhookPayloads    []map[string]any
+	deleteWebhookPayloads []map[string]any
+	getUpdatesPayloads    []map[string]any
+
+	chatActions      []telegramMockChatAction
+	chatActionStatus int
+
+	pollPayloads    []map[string]any
+	pollFailureBody string
+	stoppedPolls    []int64
+
+	// files maps file IDs to the content served under /file/files/<id>.
+	files map[string]string
+}
+
+// telegramMockChatAction records a sendChatAction call and how many
+// messages had been sent before it.
+type telegramMockChatAction struct {
+	Action     string
+	SentBefore int
+}
+
+type telegramMockEdit struct {
+	Method  string
+	Payload map[string]any
+}
+
+type telegramMockDocument struct {
+	Filename string
+	Content  string
+	Fields   map[string]string
 }
 
 func newTelegramAPIMock() *telegramAPIMock {
@@ -36,6 +95,35 @@ func newTelegramAPIMock() *telegramAPIMock {
 	}
 }
 
+// newTestTelegramProvider returns a provider linked to chat 777 that talks
+// to srv, or to nothing when srv is nil, and keeps its stores in dir, or in a
+// fresh temp dir when dir is empty. Each opt then adjusts it.
+func newTestTelegramProvider(t *testing.T, srv *httptest.Server, dir string, opts ...func(*TelegramProvider)) *TelegramProvider {
+	t.Helper()
+	if dir == "" {
+		dir = t.TempDir()
+	}
+	pendingPath := filepath.Join(dir, "telegram-pending.json")
+	inboxPath := filepath.Join(dir, "telegram-inbox.json")
+	recentPath := filepath.Join(dir, "telegram-recent.json")
+	p := &TelegramProvider{
+		chatID:       777,
+		pollInterval: 10 * time.Millisecond,
+		pending:      make(map[string]int64),
+		pendingStore: &telegramPendingStore{path: pendingPath, lock: pendingPath + ".lock"},
+		inboxStore:   &telegramInboxStore{path: inboxPath, lock: inboxPath + ".lock"},
+		recentStore:  &telegramRecentStore{path: recentPath, lock: recentPath + ".lock"},
+		pollerLock:   &telegramPollerLock{path: filepath.Join(dir, "telegram-poller.lock")},
+	}
+	if srv != nil {
+		p.baseURL, p.client = srv.URL, srv.Client()
+	}
+	for _, opt := range opts {
+		opt(p)
+	}
+	return p
+}
+
 func (m *telegramAPIMock) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	switch r.URL.Path {
 	case "/sendMessage":
@@ -43,7 +131,21 @@ func (m *telegramAPIMock) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		_ = json.NewDecoder(r.Body).Decode(&payload)
 
 		m.mu.Lock()
+		m.sendAttempts++
+		if m.sendFailures > 0 {
+			m.sendFailures--
+			status := m.sendFailureStatus
+			body := m.sendFailureBody
+			m.mu.Unlock()
+			if body == "" {
+				body = `{"ok":false,"description":"simulated failure"}`
+			}
+			w.WriteHeader(status)
+			_, _ = w.Write([]byte(body))
+			return
+		}
 		m.sendCount++
+		m.sendBodies = append(m.sendBodies, payload)
 		if text, ok := payload["text"].(string); ok {
 			m.sendTexts = append(m.sendTexts, text)
 		}
@@ -60,6 +162,33 @@ func (m *telegramAPIMock) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 				MessageID: msgID,
 			},
 		})
+	case "/sendDocument":
+		doc := telegramMockDocument{Fields: map[string]string{}}
+		if err := r.ParseMultipartForm(1 << 20); err == nil {
+			for k, v := range r.MultipartForm.Value {
+				doc.Fields[k] = v[0]
+			}
+			if fh := r.MultipartForm.File["document"]; len(fh) > 0 {
+				doc.Filename = fh[0].Filename
+				if f, err := fh[0].Open(); err == nil {
+					b, _ := io.ReadAll(f)
+					_ = f.Close()
+					doc.Content = string(b)
+				}
+			}
+		}
+
+		m.mu.Lock()
+		m.documents = append(m.documents, doc)
+		m.nextMsgID++
+		msgID := m.nextMsgID
+		m.mu.Unlock()
+
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(telegramSendResponse{
+			OK:     true,
+			Result: telegramMessage{MessageID: msgID},
+		})
 	case "/getUpdates":
 		var payload map[string]any
 		_ = json.NewDecoder(r.Body).Decode(&payload)
@@ -68,6 +197,25 @@ func (m *telegramAPIMock) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		if payload != nil {
 			m.getUpdatesPayloads = append(m.getUpdatesPayloads, payload)
 		}
+		m.getUpdatesTimes = append(m.getUpdatesTimes, time.Now())
+		if len(m.getUpdatesStatuses) > 0 {
+			status := m.getUpdatesStatuses[0]
+			m.getUpdatesStatuses = m.getUpdatesStatuses[1:]
+			if status != 0 {
+				m.mu.Unlock()
+				w.WriteHeader(status)
+				_, _ = w.Write([]byte(`{"ok":false,"description":"Bad Gateway"}`))
+				return
+			}
+		}
+		if m.getUpdatesFailures > 0 {
+			m.getUpdatesFailures--
+			body := m.getUpdatesFailureBody
+			m.mu.Unlock()
+			w.WriteHeader(http.StatusTooManyRequests)
+			_, _ = w.Write([]byte(body))
+			return
+		}
 		var batch []telegramUpdate
 		if m.getIndex < len(m.batches) {
 			batch = m.batches[m.getIndex]
@@ -80,6 +228,66 @@ func (m *telegramAPIMock) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 			OK:     true,
 			Result: batch,
 		})
+	case "/editMessageText", "/editMessageReplyMarkup":
+		var payload map[string]any
+		_ = json.NewDecoder(r.Body).Decode(&payload)
+
+		m.mu.Lock()
+		m.edits = append(m.edits, telegramMockEdit{Method: strings.TrimPrefix(r.URL.Path, "/"), Payload: payload})
+		status := m.editStatus
+		m.mu.Unlock()
+
+		w.Header().Set("Content-Type", "application/json")
+		if status != 0 && status != http.StatusOK {
+			w.WriteHeader(status)
+			_, _ = w.Write([]byte(`{"ok":false,"description":"Bad Request: message can't be edited"}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"ok":true,"result":{"message_id":1}}`))
+	case "/deleteMessage":
+		var payload map[string]any
+		_ = json.NewDecoder(r.Body).Decode(&payload)
+
+		m.mu.Lock()
+		if id, ok := payload["message_id"].(float64); ok {
+			m.deletedMessages = append(m.deletedMessages, int64(id))
+		}
+		m.mu.Unlock()
+
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"ok":true,"result":true}`))
+	case "/answerCallbackQuery":
+		var payload map[string]any
+		_ = json.NewDecoder(r.Body).Decode(&payload)
+
+		m.mu.Lock()
+		if id, ok := payload["callback_query_id"].(string); ok {
+			m.answeredCallbacks = append(m.answeredCallbacks, id)
+		}
+		m.mu.Unlock()
+
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"ok":true,"result":true}`))
+	case "/setWebhook":
+		var payload map[string]any
+		_ = json.NewDecoder(r.Body).Decode(&payload)
+
+		m.mu.Lock()
+		m.setWebhookPayloads = append(m.setWebhookPayloads, payload)
+		m.mu.Unlock()
+
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"ok":true,"result":true}`))
+	case "/deleteWebhook":
+		var payload map[string]any
+		_ = json.NewDecoder(r.Body).Decode(&payload)
+		m.mu.Lock()
+		m.deleteWebhookPayloads = append(m.deleteWebhookPayloads, payload)
+		m.webhookURL = ""
+		m.mu.Unlock()
+
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"ok":true,"result":true}`))
 	case "/getWebhookInfo":
 		m.mu.Lock()
 		m.webhookInfoCalls++
@@ -93,17 +301,124 @@ func (m *telegramAPIMock) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 				URL: webhookURL,
 			},
 		})
+	case "/sendChatAction":
+		var payload map[string]any
+		_ = json.NewDecoder(r.Body).Decode(&payload)
+		action, _ := payload["action"].(string)
+		m.mu.Lock()
+		m.chatActions = append(m.chatActions, telegramMockChatAction{Action: action, SentBefore: m.sendCount})
+		status := m.chatActionStatus
+		m.mu.Unlock()
+		if status != 0 && status != http.StatusOK {
+			w.WriteHeader(status)
+			_, _ = w.Write([]byte(`{"ok":false,"description":"simulated failure"}`))
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"ok":true,"result":true}`))
+	case "/sendPoll":
+		var payload map[string]any
+		_ = json.NewDecoder(r.Body).Decode(&payload)
+
+		m.mu.Lock()
+		m.pollPayloads = append(m.pollPayloads, payload)
+		failure := m.pollFailureBody
+		m.nextMsgID++
+		msgID := m.nextMsgID
+		m.mu.Unlock()
+
+		if failure != "" {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(failure))
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(telegramSendResponse{
+			OK:     true,
+			Result: telegramMessage{MessageID: msgID, Poll: &telegramPoll{ID: "poll-" + strconv.FormatInt(msgID, 10)}},
+		})
+	case "/stopPoll":
+		var payload map[string]any
+		_ = json.NewDecoder(r.Body).Decode(&payload)
+
+		m.mu.Lock()
+		if id, ok := payload["message_id"].(float64); ok {
+			m.stoppedPolls = append(m.stoppedPolls, int64(id))
+		}
+		m.mu.Unlock()
+
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"ok":true,"result":{"id":"poll"}}`))
+	case "/getMe":
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"ok":true,"result":{"id":42,"is_bot":true,"username":"consult_bot"}}`))
+	case "/getFile":
+		var payload map[string]any
+		_ = json.NewDecoder(r.Body).Decode(&payload)
+		fileID, _ := payload["file_id"].(string)
+		m.mu.Lock()
+		_, ok := m.files[fileID]
+		m.mu.Unlock()
+		if !ok {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"ok":false,"description":"Bad Request: invalid file_id"}`))
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"ok":     true,
+			"result": map[string]any{"file_id": fileID, "file_path": "files/" + fileID},
+		})
 	default:
+		if fileID, ok := strings.CutPrefix(r.URL.Path, "/file/files/"); ok {
+			m.mu.Lock()
+			content, found := m.files[fileID]
+			m.mu.Unlock()
+			if found {
+				_, _ = w.Write([]byte(content))
+				return
+			}
+		}
 		http.NotFound(w, r)
 	}
 }
 
+func (m *telegramAPIMock) sentDocuments() []telegramMockDocument {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]telegramMockDocument(nil), m.documents...)
+}
+
+func (m *telegramAPIMock) sentEdits() []telegramMockEdit {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]telegramMockEdit(nil), m.edits...)
+}
+
+func (m *telegramAPIMock) deletedMessageIDs() []int64 {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]int64(nil), m.deletedMessages...)
+}
+
+func (m *telegramAPIMock) answeredCallbackIDs() []string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]string(nil), m.answeredCallbacks...)
+}
+
 func (m *telegramAPIMock) sendMessageCount() int {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 	return m.sendCount
 }
 
+func (m *telegramAPIMock) sendAttemptCount() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.sendAttempts
+}
+
 func (m *telegramAPIMock) sentTexts() []string {
 	m.mu.Lock()
 	defer m.mu.Unlock()
@@ -112,6 +427,14 @@ func (m *telegramAPIMock) sentTexts() []string {
 	return out
 }
 
+func (m *telegramAPIMock) sentPayloads() []map[string]any {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	out := make([]map[string]any, len(m.sendBodies))
+	copy(out, m.sendBodies)
+	return out
+}
+
 func (m *telegramAPIMock) lastGetUpdatesPayload() map[string]any {
 	m.mu.Lock()
 	defer m.mu.Unlock()
@@ -136,7 +459,7 @@ func TestTelegramReceiveUnknownRequestID(t *testing.T) {
 	}
 }
 
-func TestTelegramGetUpdatesUsesMessageAllowedUpdates(t *testing.T) {
+func TestTelegramGetUpdatesRequestsMessagesEditsCallbacksReactionsAndPollAnswers(t *testing.T) {
 	mock := newTelegramAPIMock()
 	mock.batches = [][]telegramUpdate{{}}
 	srv := httptest.NewServer(mock)
@@ -163,7 +486,7 @@ func TestTelegramGetUpdatesUsesMessageAllowedUpdates(t *testing.T) {
 		t.Fatalf("expected allowed_updates in payload: %#v", payload)
 	}
 	allowed, ok := rawAllowed.([]any)
-	if !ok || len(allowed) != 1 || allowed[0] != "message" {
+	if !ok || len(allowed) != 5 || allowed[0] != "message" || allowed[1] != "edited_message" || allowed[2] != "callback_query" || allowed[3] != "message_reaction" || allowed[4] != "poll_answer" {
 		t.Fatalf("unexpected allowed_updates payload: %#v", rawAllowed)
 	}
 }
@@ -230,6 +553,37 @@ func TestTelegramSendFailsWhenWebhookConfigured(t *testing.T) {
 	}
 }
 
+func TestTelegramSendDeletesWebhookWhenConfigured(t *testing.T) {
+	mock := newTelegramAPIMock()
+	mock.webhookURL = "https://example.com/telegram-webhook"
+	srv := httptest.NewServer(mock)
+	defer srv.Close()
+
+	p := &TelegramProvider{
+		chatID:            777,
+		pollInterval:      10 * time.Millisecond,
+		baseURL:           srv.URL,
+		client:            srv.Client(),
+		autoDeleteWebhook: true,
+		pending:           make(map[string]int64),
+	}
+
+	req := contract.AskRequest{RequestID: "req-webhook-delete", Question: "test", Type: contract.QuestionTypeOpen}
+	if _, err := p.Send(context.Background(), req); err != nil {
+		t.Fatalf("Send returned error: %v", err)
+	}
+
+	mock.mu.Lock()
+	payloads := mock.deleteWebhookPayloads
+	mock.mu.Unlock()
+	if len(payloads) != 1 || payloads[0]["drop_pending_updates"] != false {
+		t.Fatalf("expected one deleteWebhook call keeping pending updates, got %#v", payloads)
+	}
+	if got := len(mock.sentPayloads()); got != 1 {
+		t.Fatalf("expected the question to be sent after removing the webhook, got %d sends", got)
+	}
+}
+
 func TestTelegramSendStoresPendingExpiryFromContextDeadline(t *testing.T) {
 	mock := newTelegramAPIMock()
 	mock.batches = [][]telegramUpdate{{}}
@@ -303,6 +657,64 @@ func TestNewTelegramMissingTokenShowsSetupInstructions(t *testing.T) {
 	}
 }
 
+func TestTelegramPersistChatIDKeepsConcurrentChanges(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	t.Setenv(config.EnvConfigPath, path)
+	if err := os.WriteFile(path, []byte("# my bot\ntelegram:\n    bot_token: test-token\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := config.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg.Telegram.PendingStorePath = filepath.Join(t.TempDir(), "pending.json")
+	p, err := NewTelegram(cfg)
+	if err != nil {
+		t.Fatalf("NewTelegram: %v", err)
+	}
+
+	// A `config set` from another process lands while this one waits for /start.
+	if err := os.WriteFile(path, []byte("# my bot\nrequest_timeout: 30m\ntelegram:\n    bot_token: test-token\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	p.persistChatID(555)
+
+	b, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := config.Parse(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Telegram.ChatID != 555 || got.RequestTimeout != "30m" {
+		t.Fatalf("expected the chat ID saved next to the concurrent change, got chat %d timeout %q", got.Telegram.ChatID, got.RequestTimeout)
+	}
+	if !strings.HasPrefix(string(b), "# my bot\n") {
+		t.Fatalf("expected the comment to survive, got:\n%s", b)
+	}
+}
+
+func TestTelegramPersistChatIDDisabled(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	t.Setenv(config.EnvConfigPath, path)
+	cfg := config.Default()
+	cfg.Telegram.BotToken = "test-token"
+	cfg.Telegram.PendingStorePath = filepath.Join(t.TempDir(), "pending.json")
+	if err := config.Set(&cfg, "telegram.auto_persist_chat_id", "false"); err != nil {
+		t.Fatal(err)
+	}
+	p, err := NewTelegram(cfg)
+	if err != nil {
+		t.Fatalf("NewTelegram: %v", err)
+	}
+
+	p.persistChatID(555)
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Fatalf("expected no config to be written, stat err %v", err)
+	}
+}
+
 func TestIsTelegramStartCommand(t *testing.T) {
 	cases := []struct {
 		input string
@@ -325,6 +737,20 @@ func TestIsTelegramStartCommand(t *testing.T) {
 	}
 }
 
+func TestIsTelegramCancelCommand(t *testing.T) {
+	for input, want := range map[string]bool{
+		"/cancel":            true,
+		"/cancel not needed": true,
+		"/CANCEL@my_bot":     true,
+		"/cancelled":         false,
+		"cancel":             false,
+	} {
+		if got := isTelegramCancelCommand(input); got != want {
+			t.Fatalf("input %q: want %v got %v", input, want, got)
+		}
+	}
+}
+
 func TestTelegramReceiveAllowsFallbackWhenSinglePending(t *testing.T) {
 	mock := newTelegramAPIMock()
 	mock.batches = [][]telegramUpdate{
@@ -387,10 +813,11 @@ func TestTelegramReceiveSendsReminderWhenMultiplePendingAndNotReply(t *testing.T
 	defer srv.Close()
 
 	p := &TelegramProvider{
-		chatID:       888,
-		pollInterval: 10 * time.Millisecond,
-		baseURL:      srv.URL,
-		client:       srv.Client(),
+		chatID:           888,
+		pollInterval:     10 * time.Millisecond,
+		baseURL:          srv.URL,
+		client:           srv.Client(),
+		reminderCooldown: telegramDefaultReminderCooldown,
 		pending: map[string]int64{
 			"req-a": 9001,
 			"req-b": 9002,
@@ -498,3 +925,2019 @@ func TestTelegramReceiveIgnoresOlderNonReplyMessages(t *testing.T) {
 		t.Fatalf("expected deadline exceeded, got %v", err)
 	}
 }
+
+func TestTelegramSendRetriesTransientFailures(t *testing.T) {
+	mock := newTelegramAPIMock()
+	mock.sendFailures = 2
+	mock.sendFailureStatus = http.StatusBadGateway
+	srv := httptest.NewServer(mock)
+	defer srv.Close()
+
+	p := &TelegramProvider{
+		chatID:         777,
+		pollInterval:   10 * time.Millisecond,
+		baseURL:        srv.URL,
+		client:         srv.Client(),
+		sendRetries:    3,
+		retryBaseDelay: time.Millisecond,
+		pending:        make(map[string]int64),
+	}
+
+	req := contract.AskRequest{
+		RequestID: "req-retry",
+		Question:  "retry me",
+		Type:      contract.QuestionTypeOpen,
+	}
+	if _, err := p.Send(context.Background(), req); err != nil {
+		t.Fatalf("Send returned error: %v", err)
+	}
+	if got := mock.sendAttemptCount(); got != 3 {
+		t.Fatalf("expected 3 send attempts, got %d", got)
+	}
+	if got := mock.sendMessageCount(); got != 1 {
+		t.Fatalf("expected one delivered message, got %d", got)
+	}
+}
+
+func TestTelegramSendGivesUpAfterRetryBudget(t *testing.T) {
+	mock := newTelegramAPIMock()
+	mock.sendFailures = 10
+	mock.sendFailureStatus = http.StatusServiceUnavailable
+	srv := httptest.NewServer(mock)
+	defer srv.Close()
+
+	p := &TelegramProvider{
+		chatID:         777,
+		baseURL:        srv.URL,
+		client:         srv.Client(),
+		sendRetries:    2,
+		retryBaseDelay: time.Millisecond,
+		pending:        make(map[string]int64),
+	}
+
+	_, err := p.sendTelegramMessage(context.Background(), 777, "hello", telegramSendOptions{})
+	if err == nil || !strings.Contains(err.Error(), "status 503") {
+		t.Fatalf("expected 503 error after retries, got %v", err)
+	}
+	if got := mock.sendAttemptCount(); got != 3 {
+		t.Fatalf("expected 3 send attempts, got %d", got)
+	}
+}
+
+func TestTelegramSendDoesNotRetryClientErrors(t *testing.T) {
+	mock := newTelegramAPIMock()
+	mock.sendFailures = 1
+	mock.sendFailureStatus = http.StatusUnauthorized
+	srv := httptest.NewServer(mock)
+	defer srv.Close()
+
+	p := &TelegramProvider{
+		chatID:         777,
+		baseURL:        srv.URL,
+		client:         srv.Client(),
+		sendRetries:    3,
+		retryBaseDelay: time.Millisecond,
+		pending:        make(map[string]int64),
+	}
+
+	_, err := p.sendTelegramMessage(context.Background(), 777, "hello", telegramSendOptions{})
+	if err == nil || !strings.Contains(err.Error(), "status 401") {
+		t.Fatalf("expected 401 error, got %v", err)
+	}
+	if got := mock.sendAttemptCount(); got != 1 {
+		t.Fatalf("expected a single attempt for 4xx, got %d", got)
+	}
+}
+
+func TestTelegramSendRetryRespectsContextDeadline(t *testing.T) {
+	mock := newTelegramAPIMock()
+	mock.sendFailures = 10
+	mock.sendFailureStatus = http.StatusBadGateway
+	srv := httptest.NewServer(mock)
+	defer srv.Close()
+
+	p := &TelegramProvider{
+		chatID:         777,
+		baseURL:        srv.URL,
+		client:         srv.Client(),
+		sendRetries:    5,
+		retryBaseDelay: time.Second,
+		pending:        make(map[string]int64),
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	if _, err := p.sendTelegramMessage(ctx, 777, "hello", telegramSendOptions{}); err == nil {
+		t.Fatalf("expected error")
+	}
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Fatalf("retry loop exceeded context budget: %s", elapsed)
+	}
+}
+
+const telegramRateLimitedBody = `{"ok":false,"error_code":429,"description":"Too Many Requests: retry after 1","parameters":{"retry_after":1}}`
+
+func TestTelegramSendWaitsForRetryAfterOn429(t *testing.T) {
+	mock := newTelegramAPIMock()
+	mock.sendFailures = 1
+	mock.sendFailureStatus = http.StatusTooManyRequests
+	mock.sendFailureBody = telegramRateLimitedBody
+	srv := httptest.NewServer(mock)
+	defer srv.Close()
+
+	// Rate limiting is retried even with the transient-error budget disabled.
+	p := &TelegramProvider{
+		chatID:         777,
+		baseURL:        srv.URL,
+		client:         srv.Client(),
+		retryBaseDelay: time.Millisecond,
+		pending:        make(map[string]int64),
+	}
+
+	start := time.Now()
+	if _, err := p.sendTelegramMessage(context.Background(), 777, "hello", telegramSendOptions{}); err != nil {
+		t.Fatalf("sendTelegramMessage returned error: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed < time.Second {
+		t.Fatalf("expected to wait retry_after before resending, waited %s", elapsed)
+	}
+	if got := mock.sendAttemptCount(); got != 2 {
+		t.Fatalf("expected 2 send attempts, got %d", got)
+	}
+}
+
+func TestTelegramSendRetryAfterRespectsContextDeadline(t *testing.T) {
+	mock := newTelegramAPIMock()
+	mock.sendFailures = 1
+	mock.sendFailureStatus = http.StatusTooManyRequests
+	mock.sendFailureBody = telegramRateLimitedBody
+	srv := httptest.NewServer(mock)
+	defer srv.Close()
+
+	p := &TelegramProvider{chatID: 777, baseURL: srv.URL, client: srv.Client(), pending: make(map[string]int64)}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+	_, err := p.sendTelegramMessage(ctx, 777, "hello", telegramSendOptions{})
+	if err == nil || !strings.Contains(err.Error(), "status 429") {
+		t.Fatalf("expected 429 error when retry_after exceeds the deadline, got %v", err)
+	}
+	if got := mock.sendAttemptCount(); got != 1 {
+		t.Fatalf("expected a single attempt, got %d", got)
+	}
+}
+
+func TestTelegramGetUpdatesWaitsForRetryAfterOn429(t *testing.T) {
+	mock := newTelegramAPIMock()
+	mock.getUpdatesFailures = 1
+	mock.getUpdatesFailureBody = telegramRateLimitedBody
+	mock.batches = [][]telegramUpdate{{{UpdateID: 5, Message: &telegramMessage{MessageID: 1, Text: "hi", Chat: telegramChat{ID: 777}}}}}
+	srv := httptest.NewServer(mock)
+	defer srv.Close()
+
+	p := &TelegramProvider{chatID: 777, pollInterval: 10 * time.Millisecond, baseURL: srv.URL, client: srv.Client(), pending: make(map[string]int64)}
+
+	updates, err := p.getUpdates(context.Background())
+	if err != nil {
+		t.Fatalf("getUpdates returned error: %v", err)
+	}
+	if len(updates) != 1 || updates[0].UpdateID != 5 {
+		t.Fatalf("unexpected updates after retry: %#v", updates)
+	}
+}
+
+func TestTelegramReceiveBacksOffWhileGetUpdatesFails(t *testing.T) {
+	mock := newTelegramAPIMock()
+	bad := http.StatusBadGateway
+	mock.getUpdatesStatuses = []int{bad, bad, bad, 0, bad, 0}
+	mock.batches = [][]telegramUpdate{
+		{},
+		{{UpdateID: 1, Message: &telegramMessage{MessageID: 2001, Date: time.Now().Unix(), Text: "yes", Chat: telegramChat{ID: 777}}}},
+	}
+	srv := httptest.NewServer(mock)
+	defer srv.Close()
+
+	const base = 20 * time.Millisecond
+	p := &TelegramProvider{
+		chatID:           777,
+		pollInterval:     10 * time.Millisecond,
+		baseURL:          srv.URL,
+		client:           srv.Client(),
+		pollBackoffBase:  base,
+		unreachableAfter: time.Minute,
+		pending:          map[string]int64{"req-1": 1111},
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	reply, err := p.Receive(ctx, "req-1")
+	if err != nil {
+		t.Fatalf("Receive returned error: %v", err)
+	}
+	if reply.Text != "yes" {
+		t.Fatalf("unexpected reply: %#v", reply)
+	}
+
+	mock.mu.Lock()
+	times := mock.getUpdatesTimes
+	mock.mu.Unlock()
+	if len(times) != 6 {
+		t.Fatalf("expected 6 getUpdates calls, got %d", len(times))
+	}
+	// Waits double after each failure and start over after the success.
+	for i, want := range []time.Duration{base, 2 * base, 4 * base, 0, base} {
+		gap := times[i+1].Sub(times[i])
+		if gap < want || (want > 0 && gap > want+15*base) {
+			t.Fatalf("gap %d: want about %s, got %s", i, want, gap)
+		}
+	}
+}
+
+func TestTelegramReceiveGivesUpWhenUnreachable(t *testing.T) {
+	mock := newTelegramAPIMock()
+	for range 100 {
+		mock.getUpdatesStatuses = append(mock.getUpdatesStatuses, http.StatusBadGateway)
+	}
+	srv := httptest.NewServer(mock)
+	defer srv.Close()
+
+	p := &TelegramProvider{
+		chatID:           777,
+		pollInterval:     10 * time.Millisecond,
+		baseURL:          srv.URL,
+		client:           srv.Client(),
+		pollBackoffBase:  5 * time.Millisecond,
+		unreachableAfter: 50 * time.Millisecond,
+		pending:          map[string]int64{"req-1": 1111},
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	_, err := p.Receive(ctx, "req-1")
+	if err == nil || !strings.Contains(err.Error(), "telegram unreachable for 50ms") {
+		t.Fatalf("expected an unreachable error, got %v", err)
+	}
+}
+
+func TestTelegramPacesAcknowledgmentsPerChat(t *testing.T) {
+	mock := newTelegramAPIMock()
+	srv := httptest.NewServer(mock)
+	defer srv.Close()
+
+	p := &TelegramProvider{
+		chatID:     777,
+		baseURL:    srv.URL,
+		client:     srv.Client(),
+		chatPacing: 50 * time.Millisecond,
+		pending:    make(map[string]int64),
+	}
+
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		if err := p.Acknowledge(context.Background(), contract.Reply{ProviderMessageID: "3001"}, "Got it."); err != nil {
+			t.Fatalf("Acknowledge returned error: %v", err)
+		}
+	}
+	if elapsed := time.Since(start); elapsed < 100*time.Millisecond {
+		t.Fatalf("expected acknowledgments to be spaced out, took %s", elapsed)
+	}
+	if got := mock.sendMessageCount(); got != 3 {
+		t.Fatalf("expected 3 acknowledgments, got %d", got)
+	}
+}
+
+func TestTelegramSendSilentDisablesNotification(t *testing.T) {
+	mock := newTelegramAPIMock()
+	srv := httptest.NewServer(mock)
+	defer srv.Close()
+
+	p := &TelegramProvider{
+		chatID:  777,
+		baseURL: srv.URL,
+		client:  srv.Client(),
+		pending: make(map[string]int64),
+	}
+
+	for _, silent := range []bool{true, false} {
+		req := contract.AskRequest{
+			RequestID: "req-" + strconv.FormatBool(silent),
+			Question:  "quiet please",
+			Type:      contract.QuestionTypeOpen,
+			Silent:    silent,
+		}
+		if _, err := p.Send(context.Background(), req); err != nil {
+			t.Fatalf("Send returned error: %v", err)
+		}
+	}
+
+	payloads := mock.sentPayloads()
+	if len(payloads) != 2 {
+		t.Fatalf("expected two sent messages, got %d", len(payloads))
+	}
+	if payloads[0]["disable_notification"] != true {
+		t.Fatalf("expected a silent question to disable notification: %#v", payloads[0])
+	}
+	if _, ok := payloads[1]["disable_notification"]; ok {
+		t.Fatalf("expected other questions to notify: %#v", payloads[1])
+	}
+
+	p.maybeSendFollowUpPing(context.Background(), telegramPendingRecord{RequestID: "req-true", ChatID: 777, MessageID: 1001, Silent: true}, time.Now())
+	if payloads = mock.sentPayloads(); len(payloads) != 3 || payloads[2]["disable_notification"] != true {
+		t.Fatalf("expected the follow-up of a silent question to be silent: %#v", payloads)
+	}
+}
+
+func TestTelegramReceiveSendsSinglePriorityPing(t *testing.T) {
+	mock := newTelegramAPIMock()
+	srv := httptest.NewServer(mock)
+	defer srv.Close()
+
+	path := filepath.Join(t.TempDir(), "telegram-pending.json")
+	store := &telegramPendingStore{path: path, lock: path + ".lock"}
+
+	p := &TelegramProvider{
+		chatID:       777,
+		pollInterval: 10 * time.Millisecond,
+		baseURL:      srv.URL,
+		client:       srv.Client(),
+		pingAfter:    20 * time.Millisecond,
+		pending:      make(map[string]int64),
+		pendingStore: store,
+	}
+
+	req := contract.AskRequest{
+		RequestID: "req-urgent",
+		Question:  "Prod is down, roll back?",
+		Type:      contract.QuestionTypeOpen,
+		Priority:  contract.PriorityHigh,
+	}
+	if _, err := p.Send(context.Background(), req); err != nil {
+		t.Fatalf("Send returned error: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+	if _, err := p.Receive(ctx, req.RequestID); err == nil {
+		t.Fatalf("expected timeout without a reply")
+	}
+
+	texts := mock.sentTexts()
+	if len(texts) != 2 {
+		t.Fatalf("expected question plus one ping, got %#v", texts)
+	}
+	if !strings.HasPrefix(texts[0], telegramHighPriorityMarker) {
+		t.Fatalf("expected urgent marker on question, got %q", texts[0])
+	}
+	if texts[1] != "❗ Still waiting on: Prod is down, roll back?" {
+		t.Fatalf("unexpected ping text: %q", texts[1])
+	}
+	replyTo, _ := mock.sentPayloads()[1]["reply_parameters"].(map[string]any)
+	if replyTo == nil || replyTo["message_id"] != float64(1001) {
+		t.Fatalf("expected ping to reply to the question message, got %#v", mock.sentPayloads()[1])
+	}
+}
+
+func TestTelegramReceiveRemindAfterSendsOneReminderPerRequest(t *testing.T) {
+	mock := newTelegramAPIMock()
+	srv := httptest.NewServer(mock)
+	defer srv.Close()
+
+	path := filepath.Join(t.TempDir(), "telegram-pending.json")
+	newProvider := func() *TelegramProvider {
+		return &TelegramProvider{
+			chatID:       777,
+			pollInterval: 10 * time.Millisecond,
+			baseURL:      srv.URL,
+			client:       srv.Client(),
+			remindAfter:  20 * time.Millisecond,
+			pending:      make(map[string]int64),
+			pendingStore: &telegramPendingStore{path: path, lock: path + ".lock"},
+		}
+	}
+	sender := newProvider()
+
+	req := contract.AskRequest{
+		RequestID: "req-remind",
+		Question:  "Which region?\nDetails follow.",
+		Type:      contract.QuestionTypeOpen,
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+	if _, err := sender.Send(ctx, req); err != nil {
+		t.Fatalf("Send returned error: %v", err)
+	}
+
+	// A second process awaiting the same request must not double-remind.
+	var wg sync.WaitGroup
+	for _, p := range []*TelegramProvider{sender, newProvider()} {
+		wg.Add(1)
+		go func(p *TelegramProvider) {
+			defer wg.Done()
+			rec, err := p.lookupPending(req.RequestID)
+			if err != nil {
+				return
+			}
+			_, _ = p.receiveDirect(ctx, rec)
+		}(p)
+	}
+	wg.Wait()
+
+	texts := mock.sentTexts()
+	if len(texts) != 2 {
+		t.Fatalf("expected question plus exactly one reminder, got %#v", texts)
+	}
+	if texts[1] != "Still waiting on: Which region?" {
+		t.Fatalf("unexpected reminder text: %q", texts[1])
+	}
+}
+
+func TestTelegramReceiveRemindAfterSkippedWhenAnswered(t *testing.T) {
+	mock := newTelegramAPIMock()
+	mock.batches = [][]telegramUpdate{
+		{
+			{
+				UpdateID: 1,
+				Message: &telegramMessage{
+					MessageID: 2001,
+					Date:      time.Now().Unix(),
+					Text:      "eu-west",
+					Chat:      telegramChat{ID: 777},
+					ReplyToMessage: &telegramMessage{
+						MessageID: 1001,
+					},
+				},
+			},
+		},
+	}
+	srv := httptest.NewServer(mock)
+	defer srv.Close()
+
+	path := filepath.Join(t.TempDir(), "telegram-pending.json")
+	p := &TelegramProvider{
+		chatID:       777,
+		pollInterval: 10 * time.Millisecond,
+		baseURL:      srv.URL,
+		client:       srv.Client(),
+		remindAfter:  50 * time.Millisecond,
+		pending:      make(map[string]int64),
+		pendingStore: &telegramPendingStore{path: path, lock: path + ".lock"},
+	}
+
+	req := contract.AskRequest{RequestID: "req-answered", Question: "Which region?", Type: contract.QuestionTypeOpen}
+	if _, err := p.Send(context.Background(), req); err != nil {
+		t.Fatalf("Send returned error: %v", err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if _, err := p.Receive(ctx, req.RequestID); err != nil {
+		t.Fatalf("Receive returned error: %v", err)
+	}
+	time.Sleep(80 * time.Millisecond)
+
+	if got := mock.sendMessageCount(); got != 1 {
+		t.Fatalf("expected no reminder after the reply arrived, got %d messages", got)
+	}
+}
+
+func TestQuestionExcerpt(t *testing.T) {
+	if got := questionExcerpt("  First line  \nsecond", 80); got != "First line" {
+		t.Fatalf("unexpected excerpt: %q", got)
+	}
+	if got := questionExcerpt("abcdefghij", 5); got != "abcd…" {
+		t.Fatalf("unexpected truncated excerpt: %q", got)
+	}
+}
+
+func TestTelegramSendFollowUpThreadsUnderAnsweredRequest(t *testing.T) {
+	mock := newTelegramAPIMock()
+	srv := httptest.NewServer(mock)
+	defer srv.Close()
+
+	dir := t.TempDir()
+	p := &TelegramProvider{
+		chatID:        777,
+		pollInterval:  10 * time.Millisecond,
+		baseURL:       srv.URL,
+		client:        srv.Client(),
+		pending:       make(map[string]int64),
+		answeredStore: &telegramPendingStore{path: filepath.Join(dir, "answered.json"), lock: filepath.Join(dir, "answered.json.lock")},
+	}
+	p.recordAnswered(telegramPendingRecord{RequestID: "req-prior", ChatID: 777, MessageID: 900, OwnerPID: 1})
+
+	req := contract.AskRequest{
+		RequestID:  "req-next",
+		Question:   "Which branch?",
+		Type:       contract.QuestionTypeOpen,
+		FollowUpTo: "req-prior",
+	}
+	if _, err := p.Send(context.Background(), req); err != nil {
+		t.Fatalf("Send returned error: %v", err)
+	}
+
+	if got := mock.sentTexts()[0]; got != "(follow-up) Which branch?" {
+		t.Fatalf("unexpected follow-up prompt: %q", got)
+	}
+	replyTo, _ := mock.sentPayloads()[0]["reply_parameters"].(map[string]any)
+	if replyTo == nil || replyTo["message_id"] != float64(900) {
+		t.Fatalf("expected follow-up to reply to the prior message, got %#v", mock.sentPayloads()[0])
+	}
+}
+
+func TestTelegramSendFollowUpFallsBackWhenPriorUnknown(t *testing.T) {
+	mock := newTelegramAPIMock()
+	srv := httptest.NewServer(mock)
+	defer srv.Close()
+
+	dir := t.TempDir()
+	p := &TelegramProvider{
+		chatID:        777,
+		pollInterval:  10 * time.Millisecond,
+		baseURL:       srv.URL,
+		client:        srv.Client(),
+		pending:       make(map[string]int64),
+		answeredStore: &telegramPendingStore{path: filepath.Join(dir, "answered.json"), lock: filepath.Join(dir, "answered.json.lock")},
+	}
+
+	req := contract.AskRequest{
+		RequestID:  "req-next",
+		Question:   "Which branch?",
+		Type:       contract.QuestionTypeOpen,
+		FollowUpTo: "req-missing",
+	}
+	if _, err := p.Send(context.Background(), req); err != nil {
+		t.Fatalf("expected fallback send, got error: %v", err)
+	}
+	if _, ok := mock.sentPayloads()[0]["reply_parameters"]; ok {
+		t.Fatalf("did not expect reply_parameters for unknown prior request: %#v", mock.sentPayloads()[0])
+	}
+}
+
+func TestTelegramSendThreadsQuestionsInSession(t *testing.T) {
+	mock := newTelegramAPIMock()
+	srv := httptest.NewServer(mock)
+	defer srv.Close()
+
+	dir := t.TempDir()
+	pendingPath := filepath.Join(dir, "telegram-pending.json")
+	answeredPath := filepath.Join(dir, "telegram-answered.json")
+	p := &TelegramProvider{
+		chatID:        777,
+		pollInterval:  10 * time.Millisecond,
+		baseURL:       srv.URL,
+		client:        srv.Client(),
+		pending:       make(map[string]int64),
+		pendingStore:  &telegramPendingStore{path: pendingPath, lock: pendingPath + ".lock"},
+		answeredStore: &telegramPendingStore{path: answeredPath, lock: answeredPath + ".lock"},
+	}
+	send := func(requestID, session string) {
+		t.Helper()
+		req := contract.AskRequest{RequestID: requestID, Question: "Question " + requestID, Type: contract.QuestionTypeOpen, Session: session}
+		if _, err := p.Send(context.Background(), req); err != nil {
+			t.Fatalf("Send returned error: %v", err)
+		}
+	}
+	replyTo := func(i int) any {
+		t.Helper()
+		params, _ := mock.sentPayloads()[i]["reply_parameters"].(map[string]any)
+		if params == nil {
+			return nil
+		}
+		return params["message_id"]
+	}
+
+	send("req-1", "deploy")
+	// Answered questions keep the thread going.
+	rec, err := p.lookupPending("req-1")
+	if err != nil {
+		t.Fatalf("lookupPending: %v", err)
+	}
+	p.recordAnswered(rec)
+	p.clearPending("req-1")
+	send("req-2", "deploy")
+	send("req-3", "deploy")
+	send("req-4", "other")
+
+	if got := replyTo(0); got != nil {
+		t.Fatalf("first question in a session should start a thread, replied to %v", got)
+	}
+	if got := replyTo(1); got != float64(1001) {
+		t.Fatalf("second question should reply to 1001, got %v", got)
+	}
+	if got := replyTo(2); got != float64(1002) {
+		t.Fatalf("third question should reply to 1002, got %v", got)
+	}
+	if got := replyTo(3); got != nil {
+		t.Fatalf("a new session should start its own thread, replied to %v", got)
+	}
+}
+
+func TestTelegramCancelWithdrawsPendingQuestion(t *testing.T) {
+	mock := newTelegramAPIMock()
+	srv := httptest.NewServer(mock)
+	defer srv.Close()
+
+	path := filepath.Join(t.TempDir(), "telegram-pending.json")
+	store := &telegramPendingStore{path: path, lock: path + ".lock"}
+	p := &TelegramProvider{
+		chatID:       777,
+		pollInterval: 10 * time.Millisecond,
+		baseURL:      srv.URL,
+		client:       srv.Client(),
+		pending:      make(map[string]int64),
+		pendingStore: store,
+	}
+
+	req := contract.AskRequest{RequestID: "req-withdraw", Question: "Ship it?", Type: contract.QuestionTypeOpen}
+	if _, err := p.Send(context.Background(), req); err != nil {
+		t.Fatalf("Send returned error: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := p.Receive(ctx, req.RequestID); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected canceled receive, got %v", err)
+	}
+	if err := p.Cancel(context.Background(), req.RequestID); err != nil {
+		t.Fatalf("Cancel returned error: %v", err)
+	}
+
+	texts := mock.sentTexts()
+	if len(texts) != 2 || texts[1] != telegramWithdrawnText {
+		t.Fatalf("expected withdrawal notice, got %#v", texts)
+	}
+	replyTo, _ := mock.sentPayloads()[1]["reply_parameters"].(map[string]any)
+	if replyTo == nil || replyTo["message_id"] != float64(1001) {
+		t.Fatalf("expected notice to reply to the question, got %#v", mock.sentPayloads()[1])
+	}
+	if _, ok, err := store.Get(req.RequestID); err != nil || ok {
+		t.Fatalf("expected pending record removed, ok=%v err=%v", ok, err)
+	}
+}
+
+func TestTelegramNotifyTimeoutMarksQuestionAndClearsPending(t *testing.T) {
+	mock := newTelegramAPIMock()
+	srv := httptest.NewServer(mock)
+	defer srv.Close()
+
+	path := filepath.Join(t.TempDir(), "telegram-pending.json")
+	store := &telegramPendingStore{path: path, lock: path + ".lock"}
+	p := &TelegramProvider{
+		chatID:        777,
+		pollInterval:  10 * time.Millisecond,
+		baseURL:       srv.URL,
+		client:        srv.Client(),
+		notifyTimeout: true,
+		pending:       make(map[string]int64),
+		pendingStore:  store,
+	}
+
+	req := contract.AskRequest{RequestID: "req-timeout", Question: "Ship it?", Type: contract.QuestionTypeOpen}
+	if _, err := p.Send(context.Background(), req); err != nil {
+		t.Fatalf("Send returned error: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Millisecond)
+	defer cancel()
+	if _, err := p.Receive(ctx, req.RequestID); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected timed out receive, got %v", err)
+	}
+	if err := p.NotifyTimeout(context.Background(), req.RequestID, 15*time.Minute); err != nil {
+		t.Fatalf("NotifyTimeout returned error: %v", err)
+	}
+
+	edits := mock.sentEdits()
+	want := "Ship it?\n\n⏰ Timed out after 15m — the agent proceeded without an answer"
+	if len(edits) != 1 || edits[0].Payload["text"] != want || edits[0].Payload["message_id"] != float64(1001) {
+		t.Fatalf("expected question edited with timeout notice, got %#v", edits)
+	}
+	if _, ok, err := store.Get(req.RequestID); err != nil || ok {
+		t.Fatalf("expected pending record removed, ok=%v err=%v", ok, err)
+	}
+}
+
+func TestTelegramNotifyAnsweredElsewhereMarksQuestionAndClearsPending(t *testing.T) {
+	mock := newTelegramAPIMock()
+	srv := httptest.NewServer(mock)
+	defer srv.Close()
+
+	path := filepath.Join(t.TempDir(), "telegram-pending.json")
+	store := &telegramPendingStore{path: path, lock: path + ".lock"}
+	p := &TelegramProvider{
+		chatID:       777,
+		pollInterval: 10 * time.Millisecond,
+		baseURL:      srv.URL,
+		client:       srv.Client(),
+		pending:      make(map[string]int64),
+		pendingStore: store,
+	}
+
+	req := contract.AskRequest{RequestID: "req-broadcast", Question: "Ship it?", Type: contract.QuestionTypeOpen}
+	if _, err := p.Send(context.Background(), req); err != nil {
+		t.Fatalf("Send returned error: %v", err)
+	}
+	if err := p.NotifyAnsweredElsewhere(context.Background(), req.RequestID, "email"); err != nil {
+		t.Fatalf("NotifyAnsweredElsewhere returned error: %v", err)
+	}
+
+	edits := mock.sentEdits()
+	want := "Ship it?\n\n☑️ Answered via email — no reply needed here"
+	if len(edits) != 1 || edits[0].Payload["text"] != want {
+		t.Fatalf("expected question edited with the winning provider, got %#v", edits)
+	}
+	if _, ok, err := store.Get(req.RequestID); err != nil || ok {
+		t.Fatalf("expected pending record removed, ok=%v err=%v", ok, err)
+	}
+}
+
+func TestTelegramConcurrentReceiversClaimTheirOwnReplies(t *testing.T) {
+	mock := newTelegramAPIMock()
+	mock.batches = [][]telegramUpdate{
+		{
+			{
+				UpdateID: 1,
+				Message: &telegramMessage{
+					MessageID:      3001,
+					Date:           time.Now().Unix(),
+					Text:           "answer two",
+					Chat:           telegramChat{ID: 777},
+					ReplyToMessage: &telegramMessage{MessageID: 1002},
+				},
+			},
+			{
+				UpdateID: 2,
+				Message: &telegramMessage{
+					MessageID:      3002,
+					Date:           time.Now().Unix(),
+					Text:           "answer one",
+					Chat:           telegramChat{ID: 777},
+					ReplyToMessage: &telegramMessage{MessageID: 1001},
+				},
+			},
+		},
+	}
+	srv := httptest.NewServer(mock)
+	defer srv.Close()
+
+	dir := t.TempDir()
+	pendingPath := filepath.Join(dir, "telegram-pending.json")
+	inboxPath := filepath.Join(dir, "telegram-inbox.json")
+	p := &TelegramProvider{
+		chatID:       777,
+		pollInterval: 10 * time.Millisecond,
+		baseURL:      srv.URL,
+		client:       srv.Client(),
+		pending:      make(map[string]int64),
+		pendingStore: &telegramPendingStore{path: pendingPath, lock: pendingPath + ".lock"},
+		inboxStore:   &telegramInboxStore{path: inboxPath, lock: inboxPath + ".lock"},
+		pollerLock:   &telegramPollerLock{path: filepath.Join(dir, "telegram-poller.lock")},
+	}
+
+	reqs := []contract.AskRequest{
+		{RequestID: "req-one", Question: "First?", Type: contract.QuestionTypeOpen},
+		{RequestID: "req-two", Question: "Second?", Type: contract.QuestionTypeOpen},
+	}
+	for _, req := range reqs {
+		if _, err := p.Send(context.Background(), req); err != nil {
+			t.Fatalf("Send returned error: %v", err)
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	var wg sync.WaitGroup
+	got := make([]string, len(reqs))
+	errs := make([]error, len(reqs))
+	for i, req := range reqs {
+		wg.Add(1)
+		go func(i int, requestID string) {
+			defer wg.Done()
+			reply, err := p.Receive(ctx, requestID)
+			got[i], errs[i] = reply.Text, err
+		}(i, req.RequestID)
+	}
+	wg.Wait()
+
+	for i, err := range errs {
+		if err != nil {
+			t.Fatalf("Receive %s returned error: %v", reqs[i].RequestID, err)
+		}
+	}
+	if got[0] != "answer one" || got[1] != "answer two" {
+		t.Fatalf("replies were mismatched: %#v", got)
+	}
+}
+
+func TestTelegramThreadingReminderCooldownIsSharedAcrossProcesses(t *testing.T) {
+	mock := newTelegramAPIMock()
+	srv := httptest.NewServer(mock)
+	defer srv.Close()
+
+	dir := t.TempDir()
+	first := newTestTelegramProvider(t, srv, dir)
+	second := newTestTelegramProvider(t, srv, dir)
+	first.reminderCooldown = telegramDefaultReminderCooldown
+	second.reminderCooldown = telegramDefaultReminderCooldown
+	first.maybeSendThreadingReminder("req-a", 777, 2)
+	second.maybeSendThreadingReminder("req-b", 777, 2)
+
+	texts := mock.sentTexts()
+	if len(texts) != 1 {
+		t.Fatalf("expected one reminder between both processes, got %#v", texts)
+	}
+	ids, err := second.inboxStore.TakeReminders(777)
+	if err != nil {
+		t.Fatalf("TakeReminders returned error: %v", err)
+	}
+	if len(ids) != 1 || ids[0] != 1001 {
+		t.Fatalf("expected reminder 1001 to be recorded for cleanup, got %#v", ids)
+	}
+}
+
+func TestTelegramThreadingReminderFollowsReminderConfig(t *testing.T) {
+	mock := newTelegramAPIMock()
+	srv := httptest.NewServer(mock)
+	defer srv.Close()
+
+	tmpl, err := config.ParseTelegramReminderTemplate("Bitte direkt antworten ({{.PendingCount}} offen).")
+	if err != nil {
+		t.Fatalf("ParseTelegramReminderTemplate returned error: %v", err)
+	}
+	p := &TelegramProvider{
+		chatID:           777,
+		baseURL:          srv.URL,
+		client:           srv.Client(),
+		reminderCooldown: time.Nanosecond,
+		reminderMax:      1,
+		reminderTemplate: tmpl,
+		pending:          make(map[string]int64),
+	}
+	p.maybeSendThreadingReminder("req-a", 777, 2)
+	time.Sleep(time.Millisecond)
+	p.maybeSendThreadingReminder("req-a", 777, 2)
+	p.maybeSendThreadingReminder("req-b", 777, 2)
+
+	texts := mock.sentTexts()
+	if len(texts) != 2 || texts[0] != "Bitte direkt antworten (2 offen)." {
+		t.Fatalf("expected one templated reminder per request, got %#v", texts)
+	}
+
+	p.reminderCooldown = 0
+	p.maybeSendThreadingReminder("req-c", 777, 2)
+	if got := mock.sentTexts(); len(got) != 2 {
+		t.Fatalf("expected a zero cooldown to disable reminders, got %#v", got)
+	}
+}
+
+func TestTelegramReceiveDeletesThreadingReminderOnceAnswered(t *testing.T) {
+	mock := newTelegramAPIMock()
+	mock.batches = [][]telegramUpdate{
+		{
+			{
+				UpdateID: 1,
+				Message: &telegramMessage{
+					MessageID: 3001,
+					Date:      time.Now().Unix(),
+					Text:      "which one?",
+					Chat:      telegramChat{ID: 777},
+				},
+			},
+		},
+		{
+			{
+				UpdateID: 2,
+				Message: &telegramMessage{
+					MessageID:      3002,
+					Date:           time.Now().Unix(),
+					Text:           "answer one",
+					Chat:           telegramChat{ID: 777},
+					ReplyToMessage: &telegramMessage{MessageID: 1001},
+				},
+			},
+		},
+	}
+	srv := httptest.NewServer(mock)
+	defer srv.Close()
+
+	dir := t.TempDir()
+	pendingPath := filepath.Join(dir, "telegram-pending.json")
+	inboxPath := filepath.Join(dir, "telegram-inbox.json")
+	p := &TelegramProvider{
+		chatID:           777,
+		pollInterval:     10 * time.Millisecond,
+		baseURL:          srv.URL,
+		client:           srv.Client(),
+		reminderCooldown: telegramDefaultReminderCooldown,
+		pending:          make(map[string]int64),
+		pendingStore:     &telegramPendingStore{path: pendingPath, lock: pendingPath + ".lock"},
+		inboxStore:       &telegramInboxStore{path: inboxPath, lock: inboxPath + ".lock"},
+		pollerLock:       &telegramPollerLock{path: filepath.Join(dir, "telegram-poller.lock")},
+	}
+
+	for _, req := range []contract.AskRequest{
+		{RequestID: "req-one", Question: "First?", Type: contract.QuestionTypeOpen},
+		{RequestID: "req-two", Question: "Second?", Type: contract.QuestionTypeOpen},
+	} {
+		if _, err := p.Send(context.Background(), req); err != nil {
+			t.Fatalf("Send returned error: %v", err)
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	reply, err := p.Receive(ctx, "req-one")
+	if err != nil {
+		t.Fatalf("Receive returned error: %v", err)
+	}
+	if reply.Text != "answer one" {
+		t.Fatalf("unexpected reply: %q", reply.Text)
+	}
+
+	texts := mock.sentTexts()
+	if len(texts) != 3 || !strings.Contains(texts[2], "2 unanswered consult-human questions") {
+		t.Fatalf("expected a threading reminder after the questions, got %#v", texts)
+	}
+	if !strings.Contains(texts[2], "\n• First? (") || !strings.Contains(texts[2], "\n• Second? (") {
+		t.Fatalf("expected the reminder to quote both questions, got %q", texts[2])
+	}
+	reminder := mock.sentPayloads()[2]
+	if replyTo, _ := reminder["reply_parameters"].(map[string]any); replyTo == nil || replyTo["message_id"] != float64(1001) {
+		t.Fatalf("expected the reminder to reply to the oldest question, got %#v", reminder)
+	}
+	if got := mock.deletedMessageIDs(); len(got) != 1 || got[0] != 1003 {
+		t.Fatalf("expected reminder 1003 to be deleted, got %#v", got)
+	}
+	if ids, err := p.inboxStore.TakeReminders(777); err != nil || len(ids) != 0 {
+		t.Fatalf("expected no reminders left in the store, got %#v (%v)", ids, err)
+	}
+}
+
+func TestTelegramReceiveIgnoresRepliesOutsideAllowlist(t *testing.T) {
+	mock := newTelegramAPIMock()
+	mock.batches = [][]telegramUpdate{
+		{
+			{
+				UpdateID: 1,
+				Message: &telegramMessage{
+					MessageID:      3001,
+					Date:           time.Now().Unix(),
+					Text:           "yes",
+					Chat:           telegramChat{ID: -100777},
+					From:           &telegramUser{ID: 66, Username: "mallory"},
+					ReplyToMessage: &telegramMessage{MessageID: 1001},
+				},
+			},
+			{
+				UpdateID: 2,
+				Message: &telegramMessage{
+					MessageID:      3002,
+					Date:           time.Now().Unix(),
+					Text:           "definitely yes",
+					Chat:           telegramChat{ID: -100777},
+					From:           &telegramUser{ID: 66, Username: "mallory"},
+					ReplyToMessage: &telegramMessage{MessageID: 1001},
+				},
+			},
+		},
+		{
+			{
+				UpdateID: 3,
+				Message: &telegramMessage{
+					MessageID:      3003,
+					Date:           time.Now().Unix(),
+					Text:           "no",
+					Chat:           telegramChat{ID: -100777},
+					From:           &telegramUser{ID: 42, Username: "alice"},
+					ReplyToMessage: &telegramMessage{MessageID: 1001},
+				},
+			},
+		},
+	}
+	srv := httptest.NewServer(mock)
+	defer srv.Close()
+
+	dir := t.TempDir()
+	pendingPath := filepath.Join(dir, "telegram-pending.json")
+	inboxPath := filepath.Join(dir, "telegram-inbox.json")
+	p := &TelegramProvider{
+		chatID:       -100777,
+		pollInterval: 10 * time.Millisecond,
+		baseURL:      srv.URL,
+		client:       srv.Client(),
+		allowlist:    newTelegramAllowlist(config.TelegramConfig{AllowedUserIDs: []int64{42}}),
+		pending:      make(map[string]int64),
+		pendingStore: &telegramPendingStore{path: pendingPath, lock: pendingPath + ".lock"},
+		inboxStore:   &telegramInboxStore{path: inboxPath, lock: inboxPath + ".lock"},
+		pollerLock:   &telegramPollerLock{path: filepath.Join(dir, "telegram-poller.lock")},
+	}
+
+	req := contract.AskRequest{RequestID: "req-allow", Question: "Ship it?", Type: contract.QuestionTypeOpen}
+	if _, err := p.Send(context.Background(), req); err != nil {
+		t.Fatalf("Send returned error: %v", err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	reply, err := p.Receive(ctx, req.RequestID)
+	if err != nil {
+		t.Fatalf("Receive returned error: %v", err)
+	}
+	if reply.Text != "no" || reply.From != "alice" {
+		t.Fatalf("expected alice's reply, got %#v", reply)
+	}
+
+	var notices int
+	for _, text := range mock.sentTexts() {
+		if text == "Only user 42 can answer consult-human questions in this chat." {
+			notices++
+		}
+	}
+	if notices != 1 {
+		t.Fatalf("expected exactly one allowlist notice, got %d in %#v", notices, mock.sentTexts())
+	}
+}
+
+func TestTelegramReplyLocallyAnswersWaitingReceive(t *testing.T) {
+	mock := newTelegramAPIMock()
+	srv := httptest.NewServer(mock)
+	defer srv.Close()
+
+	dir := t.TempDir()
+	pendingPath := filepath.Join(dir, "telegram-pending.json")
+	inboxPath := filepath.Join(dir, "telegram-inbox.json")
+	newProvider := func() *TelegramProvider {
+		return &TelegramProvider{
+			chatID:       777,
+			pollInterval: 10 * time.Millisecond,
+			baseURL:      srv.URL,
+			client:       srv.Client(),
+			pending:      make(map[string]int64),
+			pendingStore: &telegramPendingStore{path: pendingPath, lock: pendingPath + ".lock"},
+			inboxStore:   &telegramInboxStore{path: inboxPath, lock: inboxPath + ".lock"},
+			pollerLock:   &telegramPollerLock{path: filepath.Join(dir, "telegram-poller.lock")},
+		}
+	}
+	asker, replier := newProvider(), newProvider()
+
+	req := contract.AskRequest{RequestID: "req-local", Question: "Ship it?", Type: contract.QuestionTypeOpen}
+	if _, err := asker.Send(context.Background(), req); err != nil {
+		t.Fatalf("Send returned error: %v", err)
+	}
+	if err := replier.ReplyLocally(context.Background(), "req-missing", "yes", false); err == nil || !strings.Contains(err.Error(), "unknown request id") {
+		t.Fatalf("expected unknown request id error, got %v", err)
+	}
+	if err := replier.ReplyLocally(context.Background(), req.RequestID, "  yes, ship it  ", true); err != nil {
+		t.Fatalf("ReplyLocally returned error: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	reply, err := asker.Receive(ctx, req.RequestID)
+	if err != nil {
+		t.Fatalf("Receive returned error: %v", err)
+	}
+	if reply.Text != "yes, ship it" || reply.From != "local" {
+		t.Fatalf("unexpected reply: %#v", reply)
+	}
+
+	texts := mock.sentTexts()
+	if len(texts) != 2 || texts[1] != telegramAnsweredLocallyPrefix+"yes, ship it" {
+		t.Fatalf("expected answered-locally notice, got %#v", texts)
+	}
+	if mock.sentPayloads()[1]["disable_notification"] != true {
+		t.Fatalf("expected silent notice, got %#v", mock.sentPayloads()[1])
+	}
+}
+
+func TestTelegramNotifySendsWithoutPendingRecord(t *testing.T) {
+	mock := newTelegramAPIMock()
+	srv := httptest.NewServer(mock)
+	defer srv.Close()
+
+	dir := t.TempDir()
+	pendingPath := filepath.Join(dir, "telegram-pending.json")
+	store := &telegramPendingStore{path: pendingPath, lock: pendingPath + ".lock"}
+	p := &TelegramProvider{
+		chatID:       777,
+		pollInterval: 10 * time.Millisecond,
+		baseURL:      srv.URL,
+		client:       srv.Client(),
+		pending:      make(map[string]int64),
+		pendingStore: store,
+	}
+
+	attachPath := filepath.Join(dir, "report.txt")
+	if err := os.WriteFile(attachPath, []byte("all green"), 0o600); err != nil {
+		t.Fatalf("write attachment: %v", err)
+	}
+	err := p.Notify(context.Background(), contract.Notification{
+		Text:        "Deploy finished",
+		Attachments: []string{attachPath},
+		Silent:      true,
+	})
+	if err != nil {
+		t.Fatalf("Notify returned error: %v", err)
+	}
+
+	if texts := mock.sentTexts(); len(texts) != 1 || texts[0] != "Deploy finished" {
+		t.Fatalf("unexpected sent texts: %#v", texts)
+	}
+	if mock.sentPayloads()[0]["disable_notification"] != true {
+		t.Fatalf("expected silent message, got %#v", mock.sentPayloads()[0])
+	}
+	if _, hasMarkup := mock.sentPayloads()[0]["reply_markup"]; hasMarkup {
+		t.Fatalf("notification must not request a reply: %#v", mock.sentPayloads()[0])
+	}
+	docs := mock.sentDocuments()
+	if len(docs) != 1 || docs[0].Filename != "report.txt" || docs[0].Content != "all green" || docs[0].Fields["disable_notification"] != "true" {
+		t.Fatalf("unexpected documents: %#v", docs)
+	}
+	if n, err := store.CountByChat(777); err != nil || n != 0 {
+		t.Fatalf("expected no pending records, got %d (err=%v)", n, err)
+	}
+	if got := mock.lastGetUpdatesPayload(); got != nil {
+		t.Fatalf("notify must not poll for updates, got %#v", got)
+	}
+}
+
+func TestTelegramNotifyRequiresLinkedChat(t *testing.T) {
+	p := &TelegramProvider{pending: make(map[string]int64)}
+	err := p.Notify(context.Background(), contract.Notification{Text: "hi"})
+	if err == nil || !strings.Contains(err.Error(), "not linked") {
+		t.Fatalf("expected unlinked chat error, got %v", err)
+	}
+}
+
+func TestTelegramDuplicateQuestionSharesMessageAndReply(t *testing.T) {
+	mock := newTelegramAPIMock()
+	mock.batches = [][]telegramUpdate{
+		{},
+		{
+			{
+				UpdateID: 1,
+				Message: &telegramMessage{
+					MessageID:      3001,
+					Date:           time.Now().Unix(),
+					Text:           "yes",
+					Chat:           telegramChat{ID: 777},
+					ReplyToMessage: &telegramMessage{MessageID: 1001},
+				},
+			},
+		},
+	}
+	srv := httptest.NewServer(mock)
+	defer srv.Close()
+
+	dir := t.TempDir()
+	dedupe := func(p *TelegramProvider) { p.dedupeWindow = time.Minute }
+	first := newTestTelegramProvider(t, srv, dir, dedupe)
+	second := newTestTelegramProvider(t, srv, dir, dedupe)
+
+	if _, err := first.Send(context.Background(), contract.AskRequest{RequestID: "req-first", Question: "Ship it?", Type: contract.QuestionTypeOpen}); err != nil {
+		t.Fatalf("first Send returned error: %v", err)
+	}
+	var status bytes.Buffer
+	second.SetStatus(&status)
+	if _, err := second.Send(context.Background(), contract.AskRequest{RequestID: "req-retry", Question: "Ship it?", Type: contract.QuestionTypeOpen}); err != nil {
+		t.Fatalf("second Send returned error: %v", err)
+	}
+	if n := mock.sendMessageCount(); n != 1 {
+		t.Fatalf("expected the duplicate to reuse the first message, got %d sends", n)
+	}
+	if !strings.Contains(status.String(), "already pending as req-first") {
+		t.Fatalf("expected the duplicate note on the status writer, got %q", status.String())
+	}
+	rec, ok, err := second.pendingStore.Get("req-retry")
+	if err != nil || !ok || rec.MessageID != 1001 || rec.DuplicateOf != "req-first" {
+		t.Fatalf("unexpected duplicate record: %#v ok=%v err=%v", rec, ok, err)
+	}
+	if n := first.pendingCountForChat(777); n != 1 {
+		t.Fatalf("duplicates should count as one pending question, got %d", n)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	var wg sync.WaitGroup
+	replies := make([]contract.Reply, 2)
+	errs := make([]error, 2)
+	for i, p := range []*TelegramProvider{first, second} {
+		wg.Add(1)
+		go func(i int, p *TelegramProvider, requestID string) {
+			defer wg.Done()
+			replies[i], errs[i] = p.Receive(ctx, requestID)
+		}(i, p, []string{"req-first", "req-retry"}[i])
+	}
+	wg.Wait()
+
+	for i, err := range errs {
+		if err != nil {
+			t.Fatalf("Receive %d returned error: %v", i, err)
+		}
+		if replies[i].Text != "yes" {
+			t.Fatalf("Receive %d got %q, want the shared reply", i, replies[i].Text)
+		}
+	}
+}
+
+func TestTelegramDuplicateQuestionSentWhenDedupeDisabled(t *testing.T) {
+	mock := newTelegramAPIMock()
+	srv := httptest.NewServer(mock)
+	defer srv.Close()
+
+	p := newTestTelegramProvider(t, srv, "")
+	for _, id := range []string{"req-a", "req-b"} {
+		if _, err := p.Send(context.Background(), contract.AskRequest{RequestID: id, Question: "Ship it?", Type: contract.QuestionTypeOpen}); err != nil {
+			t.Fatalf("Send %s returned error: %v", id, err)
+		}
+	}
+	if n := mock.sendMessageCount(); n != 2 {
+		t.Fatalf("expected both questions to be sent, got %d sends", n)
+	}
+}
+
+func TestTelegramAcknowledgeRepliesToHumanMessage(t *testing.T) {
+	mock := newTelegramAPIMock()
+	srv := httptest.NewServer(mock)
+	defer srv.Close()
+
+	p := &TelegramProvider{
+		chatID:  777,
+		baseURL: srv.URL,
+		client:  srv.Client(),
+		pending: make(map[string]int64),
+	}
+	reply := contract.Reply{RequestID: "req-ack", Text: "2", ProviderMessageID: "3001"}
+	if err := p.Acknowledge(context.Background(), reply, "Got it: B) Wait"); err != nil {
+		t.Fatalf("Acknowledge returned error: %v", err)
+	}
+
+	payloads := mock.sentPayloads()
+	if len(payloads) != 1 || payloads[0]["text"] != "Got it: B) Wait" || payloads[0]["disable_notification"] != true {
+		t.Fatalf("unexpected confirmation payloads: %#v", payloads)
+	}
+	replyTo, _ := payloads[0]["reply_parameters"].(map[string]any)
+	if replyTo == nil || replyTo["message_id"] != float64(3001) {
+		t.Fatalf("expected confirmation to reply to the human's message, got %#v", payloads[0])
+	}
+}
+
+func TestTelegramChoiceQuestionAttachesInlineKeyboard(t *testing.T) {
+	mock := newTelegramAPIMock()
+	srv := httptest.NewServer(mock)
+	defer srv.Close()
+
+	p := &TelegramProvider{
+		chatID:       777,
+		pollInterval: 10 * time.Millisecond,
+		baseURL:      srv.URL,
+		client:       srv.Client(),
+		pending:      make(map[string]int64),
+	}
+	req := contract.AskRequest{
+		RequestID: "req-kb",
+		Question:  "Release?",
+		Type:      contract.QuestionTypeChoice,
+		Choices:   []contract.Choice{{ID: "A", Text: "Ship now"}, {ID: "B", Text: "Wait"}},
+	}
+	if _, err := p.Send(context.Background(), req); err != nil {
+		t.Fatalf("Send returned error: %v", err)
+	}
+
+	markup, _ := mock.sentPayloads()[0]["reply_markup"].(map[string]any)
+	rows, _ := markup["inline_keyboard"].([]any)
+	if len(rows) != 2 {
+		t.Fatalf("expected one keyboard row per choice, got %#v", markup)
+	}
+	button, _ := rows[1].([]any)[0].(map[string]any)
+	if button["text"] != "B) Wait" || button["callback_data"] != "ch:req-kb:B" {
+		t.Fatalf("unexpected button: %#v", button)
+	}
+	if _, ok := markup["force_reply"]; ok {
+		t.Fatalf("inline keyboard must replace force_reply, got %#v", markup)
+	}
+}
+
+func TestTelegramReceiveAcceptsInlineKeyboardTap(t *testing.T) {
+	mock := newTelegramAPIMock()
+	mock.batches = [][]telegramUpdate{
+		{},
+		{
+			{
+				UpdateID: 1,
+				CallbackQuery: &telegramCallbackQuery{
+					ID:      "cb-1",
+					From:    &telegramUser{Username: "alice"},
+					Message: &telegramMessage{MessageID: 1001, Chat: telegramChat{ID: 777}},
+					Data:    "ch:req-tap:B",
+				},
+			},
+		},
+	}
+	srv := httptest.NewServer(mock)
+	defer srv.Close()
+
+	dir := t.TempDir()
+	pendingPath := filepath.Join(dir, "telegram-pending.json")
+	inboxPath := filepath.Join(dir, "telegram-inbox.json")
+	p := &TelegramProvider{
+		chatID:       777,
+		pollInterval: 10 * time.Millisecond,
+		baseURL:      srv.URL,
+		client:       srv.Client(),
+		pending:      make(map[string]int64),
+		pendingStore: &telegramPendingStore{path: pendingPath, lock: pendingPath + ".lock"},
+		inboxStore:   &telegramInboxStore{path: inboxPath, lock: inboxPath + ".lock"},
+		pollerLock:   &telegramPollerLock{path: filepath.Join(dir, "telegram-poller.lock")},
+	}
+	req := contract.AskRequest{
+		RequestID: "req-tap",
+		Question:  "Release?",
+		Type:      contract.QuestionTypeChoice,
+		Choices:   []contract.Choice{{ID: "A", Text: "Ship now"}, {ID: "B", Text: "Wait"}},
+	}
+	if _, err := p.Send(context.Background(), req); err != nil {
+		t.Fatalf("Send returned error: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	reply, err := p.Receive(ctx, req.RequestID)
+	if err != nil {
+		t.Fatalf("Receive returned error: %v", err)
+	}
+	if reply.Text != "B" || reply.From != "alice" {
+		t.Fatalf("unexpected reply: %#v", reply)
+	}
+	if ids := mock.answeredCallbackIDs(); len(ids) != 1 || ids[0] != "cb-1" {
+		t.Fatalf("expected the callback query to be answered, got %#v", ids)
+	}
+}
+
+func TestParseTelegramCallbackData(t *testing.T) {
+	if id, choice, ok := parseTelegramCallbackData(telegramCallbackData("req-1", "OTHER")); !ok || id != "req-1" || choice != "OTHER" {
+		t.Fatalf("round trip failed: %q %q %v", id, choice, ok)
+	}
+	for _, data := range []string{"", "ch", "ch:req-1", "ch::A", "xx:req-1:A"} {
+		if _, _, ok := parseTelegramCallbackData(data); ok {
+			t.Fatalf("expected %q to be rejected", data)
+		}
+	}
+}
+
+func TestTelegramSendUsesParseMode(t *testing.T) {
+	mock := newTelegramAPIMock()
+	srv := httptest.NewServer(mock)
+	defer srv.Close()
+
+	p := &TelegramProvider{
+		chatID:       777,
+		pollInterval: 10 * time.Millisecond,
+		baseURL:      srv.URL,
+		client:       srv.Client(),
+		parseMode:    config.TelegramParseModeMarkdown,
+		pending:      make(map[string]int64),
+	}
+	req := contract.AskRequest{RequestID: "req-md", Question: "Rename user_id?", Type: contract.QuestionTypeOpen}
+	if _, err := p.Send(context.Background(), req); err != nil {
+		t.Fatalf("Send returned error: %v", err)
+	}
+
+	payload := mock.sentPayloads()[0]
+	if payload["parse_mode"] != "MarkdownV2" || payload["text"] != `Rename user\_id?` {
+		t.Fatalf("unexpected payload: %#v", payload)
+	}
+}
+
+func TestTelegramSendFallsBackToPlainOnEntityParseError(t *testing.T) {
+	mock := newTelegramAPIMock()
+	mock.sendFailures = 1
+	mock.sendFailureStatus = http.StatusBadRequest
+	mock.sendFailureBody = `{"ok":false,"error_code":400,"description":"Bad Request: can't parse entities: Can't find end of Bold entity at byte offset 6"}`
+	srv := httptest.NewServer(mock)
+	defer srv.Close()
+
+	p := &TelegramProvider{
+		chatID:       777,
+		pollInterval: 10 * time.Millisecond,
+		baseURL:      srv.URL,
+		client:       srv.Client(),
+		parseMode:    config.TelegramParseModeMarkdown,
+		pending:      make(map[string]int64),
+	}
+	req := contract.AskRequest{RequestID: "req-bad", Question: "Ship *now?", Type: contract.QuestionTypeOpen, PreFormatted: true}
+	if _, err := p.Send(context.Background(), req); err != nil {
+		t.Fatalf("Send returned error: %v", err)
+	}
+
+	if n := mock.sendAttemptCount(); n != 2 {
+		t.Fatalf("expected one plain retry, got %d attempts", n)
+	}
+	payload := mock.sentPayloads()[0]
+	if _, ok := payload["parse_mode"]; ok || payload["text"] != "Ship *now?" {
+		t.Fatalf("expected plain resend, got %#v", payload)
+	}
+}
+
+func TestTelegramReceiveMarksQuestionAnswered(t *testing.T) {
+	for _, tc := range []struct {
+		name       string
+		editStatus int
+	}{
+		{"edited", http.StatusOK},
+		{"edit window expired", http.StatusBadRequest},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			mock := newTelegramAPIMock()
+			mock.editStatus = tc.editStatus
+			mock.batches = [][]telegramUpdate{
+				{},
+				{
+					{
+						UpdateID: 1,
+						Message: &telegramMessage{
+							MessageID:      3001,
+							Date:           time.Now().Unix(),
+							Text:           "yes, ship it",
+							Chat:           telegramChat{ID: 777},
+							ReplyToMessage: &telegramMessage{MessageID: 1001},
+						},
+					},
+				},
+			}
+			srv := httptest.NewServer(mock)
+			defer srv.Close()
+
+			path := filepath.Join(t.TempDir(), "telegram-pending.json")
+			p := &TelegramProvider{
+				chatID:       777,
+				pollInterval: 10 * time.Millisecond,
+				baseURL:      srv.URL,
+				client:       srv.Client(),
+				markAnswered: true,
+				pending:      make(map[string]int64),
+				pendingStore: &telegramPendingStore{path: path, lock: path + ".lock"},
+			}
+			req := contract.AskRequest{RequestID: "req-mark", Question: "Ship it?", Type: contract.QuestionTypeOpen}
+			if _, err := p.Send(context.Background(), req); err != nil {
+				t.Fatalf("Send returned error: %v", err)
+			}
+			ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+			defer cancel()
+			if _, err := p.Receive(ctx, req.RequestID); err != nil {
+				t.Fatalf("Receive returned error: %v", err)
+			}
+
+			edits := mock.sentEdits()
+			if len(edits) != 1 || edits[0].Method != "editMessageText" {
+				t.Fatalf("expected one editMessageText call, got %#v", edits)
+			}
+			if edits[0].Payload["message_id"] != float64(1001) || edits[0].Payload["text"] != "Ship it?\n\n"+telegramAnsweredMarker+"yes, ship it" {
+				t.Fatalf("unexpected edit payload: %#v", edits[0].Payload)
+			}
+		})
+	}
+}
+
+func TestTelegramReceiveCancelCommandDismissesQuestion(t *testing.T) {
+	mock := newTelegramAPIMock()
+	mock.batches = [][]telegramUpdate{
+		{},
+		{
+			{
+				UpdateID: 1,
+				Message: &telegramMessage{
+					MessageID:      3001,
+					Date:           time.Now().Unix(),
+					Text:           "/cancel",
+					Chat:           telegramChat{ID: 777},
+					ReplyToMessage: &telegramMessage{MessageID: 1001},
+				},
+			},
+		},
+	}
+	srv := httptest.NewServer(mock)
+	defer srv.Close()
+
+	path := filepath.Join(t.TempDir(), "telegram-pending.json")
+	p := &TelegramProvider{
+		chatID:       777,
+		pollInterval: 10 * time.Millisecond,
+		baseURL:      srv.URL,
+		client:       srv.Client(),
+		markAnswered: true,
+		pending:      make(map[string]int64),
+		pendingStore: &telegramPendingStore{path: path, lock: path + ".lock"},
+	}
+	req := contract.AskRequest{RequestID: "req-moot", Question: "Ship it?", Type: contract.QuestionTypeOpen}
+	if _, err := p.Send(context.Background(), req); err != nil {
+		t.Fatalf("Send returned error: %v", err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	if _, err := p.Receive(ctx, req.RequestID); !errors.Is(err, ErrCancelledByHuman) {
+		t.Fatalf("expected ErrCancelledByHuman, got %v", err)
+	}
+
+	if _, ok, err := p.pendingStore.Get(req.RequestID); err != nil || ok {
+		t.Fatalf("expected pending record to be deleted, ok=%v err=%v", ok, err)
+	}
+	edits := mock.sentEdits()
+	if len(edits) != 1 || edits[0].Payload["text"] != "Ship it?\n\n"+telegramCancelledMarker {
+		t.Fatalf("expected question marked cancelled, got %#v", edits)
+	}
+}
+
+func TestTelegramReceiveBareCancelListsPendingQuestions(t *testing.T) {
+	mock := newTelegramAPIMock()
+	mock.batches = [][]telegramUpdate{
+		{},
+		{},
+		{
+			{
+				UpdateID: 1,
+				Message: &telegramMessage{
+					MessageID: 3001,
+					Date:      time.Now().Unix(),
+					Text:      "/cancel",
+					Chat:      telegramChat{ID: 777},
+				},
+			},
+		},
+	}
+	srv := httptest.NewServer(mock)
+	defer srv.Close()
+
+	path := filepath.Join(t.TempDir(), "telegram-pending.json")
+	p := &TelegramProvider{
+		chatID:       777,
+		pollInterval: 10 * time.Millisecond,
+		baseURL:      srv.URL,
+		client:       srv.Client(),
+		pending:      make(map[string]int64),
+		pendingStore: &telegramPendingStore{path: path, lock: path + ".lock"},
+	}
+	for _, req := range []contract.AskRequest{
+		{RequestID: "req-a", Question: "Ship it?", Type: contract.QuestionTypeOpen},
+		{RequestID: "req-b", Question: "Run migrations?", Type: contract.QuestionTypeOpen},
+	} {
+		if _, err := p.Send(context.Background(), req); err != nil {
+			t.Fatalf("Send returned error: %v", err)
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 150*time.Millisecond)
+	defer cancel()
+	if _, err := p.Receive(ctx, "req-a"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected the bare /cancel to be ignored, got %v", err)
+	}
+
+	texts := mock.sentTexts()
+	want := telegramCancelChoiceText + "\n1. Ship it?\n2. Run migrations?"
+	if len(texts) != 3 || texts[2] != want {
+		t.Fatalf("expected a list of pending questions, got %#v", texts)
+	}
+}
+
+func TestTelegramReceiveUsesEditedReply(t *testing.T) {
+	answer := func(updateID int64, text string, edited bool) telegramUpdate {
+		msg := &telegramMessage{
+			MessageID:      3001,
+			Date:           time.Now().Unix(),
+			Text:           text,
+			Chat:           telegramChat{ID: 777},
+			ReplyToMessage: &telegramMessage{MessageID: 1001},
+		}
+		if edited {
+			return telegramUpdate{UpdateID: updateID, EditedMessage: msg}
+		}
+		return telegramUpdate{UpdateID: updateID, Message: msg}
+	}
+
+	for _, tc := range []struct {
+		name         string
+		batches      [][]telegramUpdate
+		wantReceived string
+		wantEdited   bool
+	}{
+		{
+			name:         "edit before claim",
+			batches:      [][]telegramUpdate{{}, {answer(1, "shp it", false), answer(2, "ship it", true)}},
+			wantReceived: "ship it",
+			wantEdited:   true,
+		},
+		{
+			name:         "edit after claim",
+			batches:      [][]telegramUpdate{{}, {answer(1, "shp it", false)}, {answer(2, "ship it", true)}},
+			wantReceived: "shp it",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			mock := newTelegramAPIMock()
+			mock.batches = tc.batches
+			srv := httptest.NewServer(mock)
+			defer srv.Close()
+
+			dir := t.TempDir()
+			pendingPath := filepath.Join(dir, "telegram-pending.json")
+			answeredPath := filepath.Join(dir, "answered.json")
+			inboxPath := filepath.Join(dir, "telegram-inbox.json")
+			p := &TelegramProvider{
+				chatID:        777,
+				pollInterval:  10 * time.Millisecond,
+				baseURL:       srv.URL,
+				client:        srv.Client(),
+				pending:       make(map[string]int64),
+				pendingStore:  &telegramPendingStore{path: pendingPath, lock: pendingPath + ".lock"},
+				answeredStore: &telegramPendingStore{path: answeredPath, lock: answeredPath + ".lock"},
+				inboxStore:    &telegramInboxStore{path: inboxPath, lock: inboxPath + ".lock"},
+				pollerLock:    &telegramPollerLock{path: filepath.Join(dir, "telegram-poller.lock")},
+			}
+			req := contract.AskRequest{RequestID: "req-edit", Question: "Ship it?", Type: contract.QuestionTypeOpen}
+			if _, err := p.Send(context.Background(), req); err != nil {
+				t.Fatalf("Send returned error: %v", err)
+			}
+
+			ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+			defer cancel()
+			reply, err := p.Receive(ctx, req.RequestID)
+			if err != nil {
+				t.Fatalf("Receive returned error: %v", err)
+			}
+			if reply.Text != tc.wantReceived || reply.Edited != tc.wantEdited {
+				t.Fatalf("unexpected reply: text %q edited %v", reply.Text, reply.Edited)
+			}
+
+			awaitCtx, awaitCancel := context.WithTimeout(ctx, 200*time.Millisecond)
+			defer awaitCancel()
+			corrected, edited, err := p.AwaitCorrection(awaitCtx, reply)
+			if err != nil {
+				t.Fatalf("AwaitCorrection returned error: %v", err)
+			}
+			if tc.wantEdited {
+				if edited {
+					t.Fatalf("did not expect a second correction, got %#v", corrected)
+				}
+				return
+			}
+			if !edited || corrected.Text != "ship it" || !corrected.Edited || corrected.RequestID != req.RequestID {
+				t.Fatalf("expected corrected reply, got %#v (edited %v)", corrected, edited)
+			}
+		})
+	}
+}
+
+func TestTelegramReceiveVoiceReply(t *testing.T) {
+	for _, tc := range []struct {
+		name       string
+		transcribe string
+		wantText   string
+	}{
+		{name: "no transcriber"},
+		{name: "transcribed", transcribe: "cat", wantText: "ship it"},
+		{name: "transcriber fails", transcribe: "false"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			mock := newTelegramAPIMock()
+			mock.files = map[string]string{"voice-1.oga": "ship it"}
+			mock.batches = [][]telegramUpdate{
+				{},
+				{
+					{
+						UpdateID: 1,
+						Message: &telegramMessage{
+							MessageID:      3001,
+							Date:           time.Now().Unix(),
+							Chat:           telegramChat{ID: 777},
+							ReplyToMessage: &telegramMessage{MessageID: 1001},
+							Voice:          &telegramVoice{FileID: "voice-1.oga", Duration: 3, MimeType: "audio/ogg"},
+						},
+					},
+				},
+			}
+			srv := httptest.NewServer(mock)
+			defer srv.Close()
+
+			dir := t.TempDir()
+			pendingPath := filepath.Join(dir, "telegram-pending.json")
+			inboxPath := filepath.Join(dir, "telegram-inbox.json")
+			p := &TelegramProvider{
+				chatID:            777,
+				pollInterval:      10 * time.Millisecond,
+				baseURL:           srv.URL,
+				fileBaseURL:       srv.URL + "/file",
+				client:            srv.Client(),
+				mediaDir:          filepath.Join(dir, "media"),
+				transcribeCommand: tc.transcribe,
+				pending:           make(map[string]int64),
+				pendingStore:      &telegramPendingStore{path: pendingPath, lock: pendingPath + ".lock"},
+				inboxStore:        &telegramInboxStore{path: inboxPath, lock: inboxPath + ".lock"},
+				pollerLock:        &telegramPollerLock{path: filepath.Join(dir, "telegram-poller.lock")},
+			}
+			req := contract.AskRequest{RequestID: "req-voice", Question: "Ship it?", Type: contract.QuestionTypeOpen}
+			if _, err := p.Send(context.Background(), req); err != nil {
+				t.Fatalf("Send returned error: %v", err)
+			}
+			ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+			defer cancel()
+			reply, err := p.Receive(ctx, req.RequestID)
+			if err != nil {
+				t.Fatalf("Receive returned error: %v", err)
+			}
+
+			if reply.Text != tc.wantText {
+				t.Fatalf("want text %q got %q", tc.wantText, reply.Text)
+			}
+			if len(reply.Attachments) != 1 || reply.Attachments[0].MimeType != "audio/ogg" {
+				t.Fatalf("expected one audio attachment, got %#v", reply.Attachments)
+			}
+			audio := reply.Attachments[0].Path
+			if audio != filepath.Join(p.mediaDir, req.RequestID, "voice-3001.oga") {
+				t.Fatalf("expected audio in media dir, got %q", audio)
+			}
+			if b, err := os.ReadFile(audio); err != nil || string(b) != "ship it" {
+				t.Fatalf("unexpected downloaded audio: %q, %v", b, err)
+			}
+			if !strings.Contains(reply.Raw, "voice message") || !strings.Contains(reply.Raw, audio) {
+				t.Fatalf("expected raw note with audio path, got %q", reply.Raw)
+			}
+		})
+	}
+}
+
+func TestTelegramReceivePhotoReplyClaimedByAnotherProcess(t *testing.T) {
+	mock := newTelegramAPIMock()
+	mock.files = map[string]string{"photo-small.jpg": "small", "photo-large.jpg": "large"}
+	srv := httptest.NewServer(mock)
+	defer srv.Close()
+
+	dir := t.TempDir()
+	pendingPath := filepath.Join(dir, "telegram-pending.json")
+	inboxPath := filepath.Join(dir, "telegram-inbox.json")
+	newProvider := func() *TelegramProvider {
+		return &TelegramProvider{
+			chatID:       777,
+			pollInterval: 10 * time.Millisecond,
+			baseURL:      srv.URL,
+			fileBaseURL:  srv.URL + "/file",
+			client:       srv.Client(),
+			mediaDir:     filepath.Join(dir, "media"),
+			pending:      make(map[string]int64),
+			pendingStore: &telegramPendingStore{path: pendingPath, lock: pendingPath + ".lock"},
+			inboxStore:   &telegramInboxStore{path: inboxPath, lock: inboxPath + ".lock"},
+			pollerLock:   &telegramPollerLock{path: filepath.Join(dir, "telegram-poller.lock")},
+		}
+	}
+	asker := newProvider()
+	req := contract.AskRequest{RequestID: "req-photo", Question: "Which design?", Type: contract.QuestionTypeOpen}
+	if _, err := asker.Send(context.Background(), req); err != nil {
+		t.Fatalf("Send returned error: %v", err)
+	}
+
+	// Another process polled the photo into the shared inbox.
+	poller := newProvider()
+	if _, _, err := poller.inboxStore.AppendUpdates([]telegramUpdate{{
+		UpdateID: 1,
+		Message: &telegramMessage{
+			MessageID:      3001,
+			Date:           time.Now().Unix(),
+			Caption:        "this one, with the bigger header",
+			Chat:           telegramChat{ID: 777},
+			ReplyToMessage: &telegramMessage{MessageID: 1001},
+			Photo: []telegramPhotoSize{
+				{FileID: "photo-small.jpg", Width: 90, Height: 60},
+				{FileID: "photo-large.jpg", Width: 1280, Height: 960},
+			},
+		},
+	}}); err != nil {
+		t.Fatalf("AppendUpdates: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	reply, err := asker.Receive(ctx, req.RequestID)
+	if err != nil {
+		t.Fatalf("Receive returned error: %v", err)
+	}
+	if reply.Text != "this one, with the bigger header" {
+		t.Fatalf("expected caption as text, got %q", reply.Text)
+	}
+	if len(reply.Attachments) != 1 || reply.Attachments[0].MimeType != "image/jpeg" {
+		t.Fatalf("expected one photo attachment, got %#v", reply.Attachments)
+	}
+	photo := reply.Attachments[0].Path
+	if photo != filepath.Join(dir, "media", req.RequestID, "photo-3001.jpg") {
+		t.Fatalf("unexpected photo path %q", photo)
+	}
+	if b, err := os.ReadFile(photo); err != nil || string(b) != "large" {
+		t.Fatalf("expected the largest photo size, got %q, %v", b, err)
+	}
+}
+
+func TestSplitTelegramText(t *testing.T) {
+	exact := strings.Repeat("a", telegramMaxMessageRunes)
+	if chunks := splitTelegramText(exact, telegramMaxMessageRunes); len(chunks) != 1 || chunks[0] != exact {
+		t.Fatalf("text at exactly the limit must not be split, got %d chunks", len(chunks))
+	}
+	if chunks := splitTelegramText(exact+"b", telegramMaxMessageRunes); len(chunks) != 2 || chunks[1] != "b" {
+		t.Fatalf("expected one overflow rune in a second chunk, got %d chunks", len(chunks))
+	}
+
+	paragraphs := strings.Repeat("x", 3000) + "\n\n" + strings.Repeat("y", 3000)
+	chunks := splitTelegramText(paragraphs, telegramMaxMessageRunes)
+	if len(chunks) != 2 || chunks[0] != strings.Repeat("x", 3000) || chunks[1] != strings.Repeat("y", 3000) {
+		t.Fatalf("expected split on the paragraph boundary, got %d chunks", len(chunks))
+	}
+
+	multiByte := strings.Repeat("é日🙂", 3000)
+	chunks = splitTelegramText(multiByte, telegramMaxMessageRunes)
+	if strings.Join(chunks, "") != multiByte {
+		t.Fatalf("multi-byte chunks do not reassemble to the original text")
+	}
+	for i, chunk := range chunks {
+		if !utf8.ValidString(chunk) {
+			t.Fatalf("chunk %d is not valid UTF-8", i)
+		}
+		if n := utf8.RuneCountInString(chunk); n > telegramMaxMessageRunes {
+			t.Fatalf("chunk %d has %d runes", i, n)
+		}
+	}
+}
+
+func TestTelegramSendSplitsLongQuestion(t *testing.T) {
+	mock := newTelegramAPIMock()
+	srv := httptest.NewServer(mock)
+	defer srv.Close()
+
+	p := &TelegramProvider{
+		chatID:       777,
+		pollInterval: 10 * time.Millisecond,
+		baseURL:      srv.URL,
+		client:       srv.Client(),
+		pending:      make(map[string]int64),
+	}
+
+	req := contract.AskRequest{
+		RequestID: "req-long",
+		Question:  strings.Repeat("log line\n", 600) + "\nShould I roll back?",
+		Type:      contract.QuestionTypeOpen,
+	}
+	if _, err := p.Send(context.Background(), req); err != nil {
+		t.Fatalf("Send returned error: %v", err)
+	}
+
+	payloads := mock.sentPayloads()
+	if len(payloads) != 2 {
+		t.Fatalf("expected the question to be split into 2 messages, got %d", len(payloads))
+	}
+	if _, ok := payloads[0]["reply_markup"]; ok {
+		t.Fatalf("only the last chunk should request a reply")
+	}
+	if _, ok := payloads[1]["reply_markup"]; !ok {
+		t.Fatalf("expected the last chunk to request a reply")
+	}
+	if rec, err := p.lookupPending(req.RequestID); err != nil || rec.MessageID != 1002 {
+		t.Fatalf("expected the last chunk to be registered, got %#v (err %v)", rec, err)
+	}
+}
+
+func TestTelegramSendShowsTypingBetweenChunks(t *testing.T) {
+	for _, tc := range []struct {
+		name        string
+		enabled     bool
+		status      int
+		wantActions []telegramMockChatAction
+	}{
+		{
+			name:        "enabled",
+			enabled:     true,
+			wantActions: []telegramMockChatAction{{Action: "typing", SentBefore: 0}, {Action: "typing", SentBefore: 1}},
+		},
+		{
+			name:        "failing action does not fail the send",
+			enabled:     true,
+			status:      http.StatusInternalServerError,
+			wantActions: []telegramMockChatAction{{Action: "typing", SentBefore: 0}, {Action: "typing", SentBefore: 1}},
+		},
+		{name: "disabled"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			mock := newTelegramAPIMock()
+			mock.chatActionStatus = tc.status
+			srv := httptest.NewServer(mock)
+			defer srv.Close()
+
+			p := &TelegramProvider{
+				chatID:          777,
+				pollInterval:    10 * time.Millisecond,
+				baseURL:         srv.URL,
+				client:          srv.Client(),
+				typingIndicator: tc.enabled,
+				pending:         make(map[string]int64),
+			}
+			req := contract.AskRequest{
+				RequestID: "req-typing",
+				Question:  strings.Repeat("log line\n", 600) + "\nShould I roll back?",
+				Type:      contract.QuestionTypeOpen,
+			}
+			if _, err := p.Send(context.Background(), req); err != nil {
+				t.Fatalf("Send returned error: %v", err)
+			}
+			if got := len(mock.sentPayloads()); got != 2 {
+				t.Fatalf("expected 2 chunks, got %d", got)
+			}
+
+			mock.mu.Lock()
+			actions := append([]telegramMockChatAction(nil), mock.chatActions...)
+			mock.mu.Unlock()
+			if !reflect.DeepEqual(actions, tc.wantActions) {
+				t.Fatalf("want chat actions %#v got %#v", tc.wantActions, actions)
+			}
+		})
+	}
+}
+
+func TestTelegramSendAttachesLongQuestionInDocumentMode(t *testing.T) {
+	mock := newTelegramAPIMock()
+	srv := httptest.NewServer(mock)
+	defer srv.Close()
+
+	p := &TelegramProvider{
+		chatID:          777,
+		pollInterval:    10 * time.Millisecond,
+		baseURL:         srv.URL,
+		client:          srv.Client(),
+		longMessageMode: config.TelegramLongMessageDocument,
+		pending:         make(map[string]int64),
+	}
+
+	req := contract.AskRequest{
+		RequestID: "req-doc",
+		Question:  "Deploy failed, roll back?\n" + strings.Repeat("stack frame\n", 500),
+		Type:      contract.QuestionTypeOpen,
+	}
+	if _, err := p.Send(context.Background(), req); err != nil {
+		t.Fatalf("Send returned error: %v", err)
+	}
+
+	docs := mock.sentDocuments()
+	if len(docs) != 1 || docs[0].Filename != telegramQuestionAttachmentName || !strings.Contains(docs[0].Content, "stack frame") {
+		t.Fatalf("expected full question attached, got %#v", docs)
+	}
+	texts := mock.sentTexts()
+	if len(texts) != 1 || !strings.HasPrefix(texts[0], "Deploy failed, roll back?") || utf8.RuneCountInString(texts[0]) > telegramMaxMessageRunes {
+		t.Fatalf("unexpected summary message: %#v", texts)
+	}
+	if rec, err := p.lookupPending(req.RequestID); err != nil || rec.MessageID != 1002 {
+		t.Fatalf("expected the summary message to be registered, got %#v (err %v)", rec, err)
+	}
+}
+
+func TestTelegramUpdatesReadWhileLinkingStayClaimable(t *testing.T) {
+	t.Setenv(config.EnvConfigPath, filepath.Join(t.TempDir(), "config.yaml"))
+	mock := newTelegramAPIMock()
+	mock.batches = [][]telegramUpdate{
+		{
+			{UpdateID: 1, Message: &telegramMessage{MessageID: 1, Text: "/start", Chat: telegramChat{ID: 777}}},
+			{UpdateID: 2, Message: &telegramMessage{
+				MessageID:      1002,
+				Date:           time.Now().Unix(),
+				Text:           "yes",
+				Chat:           telegramChat{ID: 777},
+				ReplyToMessage: &telegramMessage{MessageID: 1001},
+			}},
+		},
+	}
+	srv := httptest.NewServer(mock)
+	defer srv.Close()
+
+	dir := t.TempDir()
+	linker := newTestTelegramProvider(t, srv, dir)
+	linker.chatID = 0
+	waiter := newTestTelegramProvider(t, srv, dir)
+	if err := waiter.registerPending(telegramPendingRecord{RequestID: "req-b", ChatID: 777, MessageID: 1001, CreatedAt: time.Now().UTC()}); err != nil {
+		t.Fatalf("registerPending returned error: %v", err)
+	}
+
+	// The linking process reads the /start and, in the same batch, the reply
+	// meant for the other process.
+	if err := linker.ensureChatID(context.Background()); err != nil {
+		t.Fatalf("ensureChatID returned error: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	reply, err := waiter.Receive(ctx, "req-b")
+	if err != nil {
+		t.Fatalf("Receive returned error: %v", err)
+	}
+	if reply.Text != "yes" {
+		t.Fatalf("expected the reply read by the other process, got %#v", reply)
+	}
+	if _, err := waiter.getUpdates(ctx); err != nil {
+		t.Fatalf("getUpdates returned error: %v", err)
+	}
+	payload := mock.lastGetUpdatesPayload()
+	if offset, _ := payload["offset"].(float64); offset != 3 {
+		t.Fatalf("expected later polls to resume from the shared offset 3, got %#v", payload["offset"])
+	}
+}
@@ -0,0 +1,222 @@
+package provider
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/AlhasanIQ/consult-human/config"
+)
+
+const (
+	telegramWebhookSecretHeader    = "X-Telegram-Bot-Api-Secret-Token"
+	telegramWebhookMaxBodyBytes    = 1 << 20
+	telegramWebhookShutdownTimeout = 5 * time.Second
+
+	TelegramWebhookHealthPath = "/healthz"
+)
+
+type TelegramWebhookOptions struct {
+	// Listen is the local address to serve on, e.g. ":8443".
+	Listen string
+	// URL is the public HTTPS address Telegram posts updates to. Its path is
+	// the path served locally.
+	URL string
+	// Secret is checked against the secret token header on every update.
+	// A random one is generated when empty.
+	Secret string
+}
+
+// TelegramWebhookServer receives updates pushed by Telegram and appends them
+// to the shared inbox, where waiting Receive calls claim them exactly as
+// they claim polled updates.
+type TelegramWebhookServer struct {
+	provider *TelegramProvider
+	listen   string
+	url      string
+	path     string
+	secret   string
+}
+
+func NewTelegramWebhookServer(cfg config.Config, opts TelegramWebhookOptions) (*TelegramWebhookServer, error) {
+	hookURL, err := url.Parse(strings.TrimSpace(opts.URL))
+	if err != nil || hookURL.Scheme != "https" || hookURL.Host == "" {
+		return nil, fmt.Errorf("webhook url must be an https:// URL, got %q", opts.URL)
+	}
+	path := hookURL.Path
+	if path == "" {
+		path = "/"
+	}
+	if path == TelegramWebhookHealthPath {
+		return nil, fmt.Errorf("webhook url path %s is reserved for the health check", TelegramWebhookHealthPath)
+	}
+
+	secret := strings.TrimSpace(opts.Secret)
+	if secret == "" {
+		b := make([]byte, 24)
+		if _, err := rand.Read(b); err != nil {
+			return nil, err
+		}
+		secret = hex.EncodeToString(b)
+	} else if !config.IsValidTelegramWebhookSecret(secret) {
+		return nil, fmt.Errorf("webhook secret must be 1-256 characters of A-Z, a-z, 0-9, _ and -")
+	}
+
+	p, err := NewTelegram(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return &TelegramWebhookServer{
+		provider: p,
+		listen:   opts.Listen,
+		url:      hookURL.String(),
+		path:     path,
+		secret:   secret,
+	}, nil
+}
+
+// Handler serves webhook updates on the URL path and a health check on
+// TelegramWebhookHealthPath.
+func (s *TelegramWebhookServer) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc(TelegramWebhookHealthPath, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		_, _ = io.WriteString(w, "ok\n")
+	})
+	mux.HandleFunc(s.path, s.handleUpdate)
+	return mux
+}
+
+func (s *TelegramWebhookServer) handleUpdate(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if subtle.ConstantTimeCompare([]byte(r.Header.Get(telegramWebhookSecretHeader)), []byte(s.secret)) != 1 {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	var up telegramUpdate
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, telegramWebhookMaxBodyBytes)).Decode(&up); err != nil {
+		http.Error(w, "invalid update", http.StatusBadRequest)
+		return
+	}
+	updates := []telegramUpdate{up}
+	s.provider.filterGroupUpdates(r.Context(), updates)
+	up = updates[0]
+	// A non-2xx response makes Telegram redeliver the update later.
+	if _, _, err := s.provider.inboxStore.AppendUpdates(updates); err != nil {
+		fmt.Fprintf(os.Stderr, "warning: telegram webhook could not store update %d: %v\n", up.UpdateID, err)
+		http.Error(w, "could not store update", http.StatusInternalServerError)
+		return
+	}
+	s.provider.linkChatFromStart(up)
+	s.provider.answerStatusCommand(up)
+	w.WriteHeader(http.StatusOK)
+}
+
+// Serve registers the webhook with Telegram and serves updates until ctx is
+// done, then shuts down gracefully. The webhook stays registered so Telegram
+// queues updates while the server is down.
+func (s *TelegramWebhookServer) Serve(ctx context.Context) error {
+	ln, err := net.Listen("tcp", s.listen)
+	if err != nil {
+		return err
+	}
+	return s.serveListener(ctx, ln)
+}
+
+func (s *TelegramWebhookServer) serveListener(ctx context.Context, ln net.Listener) error {
+	srv := &http.Server{
+		Handler:           s.Handler(),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	errCh := make(chan error, 1)
+	go func() { errCh <- srv.Serve(ln) }()
+
+	if err := s.provider.setWebhook(ctx, s.url, s.secret); err != nil {
+		_ = srv.Close()
+		// Stopped while registering: that is a shutdown, not a failure.
+		if ctx.Err() != nil {
+			return nil
+		}
+		return err
+	}
+
+	select {
+	case err := <-errCh:
+		return err
+	case <-ctx.Done():
+	}
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), telegramWebhookShutdownTimeout)
+	defer cancel()
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		return err
+	}
+	if err := <-errCh; !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+// deleteWebhook removes the bot's webhook so getUpdates works again. Updates
+// Telegram queued for the webhook are kept and delivered to polling.
+func (p *TelegramProvider) deleteWebhook(ctx context.Context) error {
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, p.baseURL+"/deleteWebhook", strings.NewReader(`{"drop_pending_updates":false}`))
+	if err != nil {
+		return err
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	resp, err := p.client.Do(httpReq)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		b, _ := io.ReadAll(io.LimitReader(resp.Body, 2048))
+		return fmt.Errorf("telegram deleteWebhook status %d: %s", resp.StatusCode, strings.TrimSpace(string(b)))
+	}
+	return nil
+}
+
+func (p *TelegramProvider) setWebhook(ctx context.Context, hookURL, secret string) error {
+	body, err := json.Marshal(map[string]any{
+		"url":             hookURL,
+		"secret_token":    secret,
+		"allowed_updates": telegramAllowedUpdates,
+	})
+	if err != nil {
+		return err
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, p.baseURL+"/setWebhook", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	resp, err := p.client.Do(httpReq)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		b, _ := io.ReadAll(io.LimitReader(resp.Body, 2048))
+		return fmt.Errorf("telegram setWebhook status %d: %s", resp.StatusCode, strings.TrimSpace(string(b)))
+	}
+	return nil
+}
@@ -0,0 +1,165 @@
+package provider
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/AlhasanIQ/consult-human/config"
+	"github.com/AlhasanIQ/consult-human/contract"
+)
+
+func newWebhookTestProvider(t *testing.T, srv *httptest.Server) *TelegramProvider {
+	t.Helper()
+	return newTestTelegramProvider(t, srv, "", func(p *TelegramProvider) { p.webhookMode = true })
+}
+
+func postWebhookUpdate(t *testing.T, h http.Handler, secret string, up telegramUpdate) int {
+	t.Helper()
+	body, err := json.Marshal(up)
+	if err != nil {
+		t.Fatalf("marshal update: %v", err)
+	}
+	req := httptest.NewRequest(http.MethodPost, "/hook", bytes.NewReader(body))
+	if secret != "" {
+		req.Header.Set(telegramWebhookSecretHeader, secret)
+	}
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	return rec.Code
+}
+
+func TestTelegramWebhookHandlerFeedsReceive(t *testing.T) {
+	mock := newTelegramAPIMock()
+	api := httptest.NewServer(mock)
+	defer api.Close()
+
+	p := newWebhookTestProvider(t, api)
+	s := &TelegramWebhookServer{provider: p, path: "/hook", secret: "s3cret"}
+	h := s.Handler()
+
+	req := contract.AskRequest{RequestID: "req-hook", Question: "Deploy?", Type: contract.QuestionTypeOpen}
+	if _, err := p.Send(context.Background(), req); err != nil {
+		t.Fatalf("Send returned error: %v", err)
+	}
+	if mock.webhookInfoCalls != 0 {
+		t.Fatalf("webhook mode should not check getWebhookInfo, got %d calls", mock.webhookInfoCalls)
+	}
+
+	up := telegramUpdate{
+		UpdateID: 41,
+		Message: &telegramMessage{
+			MessageID:      3001,
+			Date:           time.Now().Unix(),
+			Text:           "go ahead",
+			Chat:           telegramChat{ID: 777},
+			ReplyToMessage: &telegramMessage{MessageID: 1001},
+		},
+	}
+	if code := postWebhookUpdate(t, h, "wrong", up); code != http.StatusUnauthorized {
+		t.Fatalf("expected 401 for a bad secret, got %d", code)
+	}
+	if code := postWebhookUpdate(t, h, "s3cret", up); code != http.StatusOK {
+		t.Fatalf("expected 200 for a valid update, got %d", code)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	reply, err := p.Receive(ctx, req.RequestID)
+	if err != nil {
+		t.Fatalf("Receive returned error: %v", err)
+	}
+	if reply.Text != "go ahead" {
+		t.Fatalf("unexpected reply: %q", reply.Text)
+	}
+	if len(mock.getUpdatesPayloads) != 0 {
+		t.Fatalf("webhook mode should not call getUpdates, got %d calls", len(mock.getUpdatesPayloads))
+	}
+}
+
+func TestTelegramWebhookHandlerHealthAndMethod(t *testing.T) {
+	s := &TelegramWebhookServer{provider: newWebhookTestProvider(t, nil), path: "/hook", secret: "s3cret"}
+	h := s.Handler()
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, TelegramWebhookHealthPath, nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected healthy status, got %d", rec.Code)
+	}
+
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/hook", nil))
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Fatalf("expected 405 for GET on the hook, got %d", rec.Code)
+	}
+}
+
+func TestTelegramWebhookServeRegistersAndShutsDown(t *testing.T) {
+	mock := newTelegramAPIMock()
+	api := httptest.NewServer(mock)
+	defer api.Close()
+
+	s := &TelegramWebhookServer{
+		provider: newWebhookTestProvider(t, api),
+		url:      "https://example.com/hook",
+		path:     "/hook",
+		secret:   "s3cret",
+	}
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- s.serveListener(ctx, ln) }()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		resp, err := http.Get("http://" + ln.Addr().String() + TelegramWebhookHealthPath)
+		if err == nil {
+			resp.Body.Close()
+			mock.mu.Lock()
+			registered := len(mock.setWebhookPayloads) > 0
+			mock.mu.Unlock()
+			if registered {
+				break
+			}
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("server never became healthy: %v", err)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	cancel()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("serve returned error: %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatalf("server did not shut down")
+	}
+
+	mock.mu.Lock()
+	defer mock.mu.Unlock()
+	if len(mock.setWebhookPayloads) != 1 {
+		t.Fatalf("expected one setWebhook call, got %d", len(mock.setWebhookPayloads))
+	}
+	got := mock.setWebhookPayloads[0]
+	if got["url"] != "https://example.com/hook" || got["secret_token"] != "s3cret" {
+		t.Fatalf("unexpected setWebhook payload: %#v", got)
+	}
+}
+
+func TestNewTelegramWebhookServerRejectsPlainHTTP(t *testing.T) {
+	_, err := NewTelegramWebhookServer(config.Config{}, TelegramWebhookOptions{URL: "http://example.com/hook"})
+	if err == nil {
+		t.Fatalf("expected error for a non-https url")
+	}
+}
@@ -0,0 +1,345 @@
+package provider
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/tls"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/AlhasanIQ/consult-human/config"
+	"github.com/AlhasanIQ/consult-human/contract"
+)
+
+const (
+	// WebhookSignatureHeader carries "sha256=<hex HMAC of the body>" when
+	// webhook.secret is set, on questions sent and on answers received.
+	WebhookSignatureHeader = "X-Consult-Human-Signature"
+	// WebhookRequestIDHeader names the question on every request to the
+	// bridge.
+	WebhookRequestIDHeader = "X-Consult-Human-Request-Id"
+
+	webhookRequestTimeout  = 30 * time.Second
+	webhookPollInterval    = 2 * time.Second
+	webhookMaxBodyBytes    = 1 << 20
+	webhookShutdownTimeout = 5 * time.Second
+)
+
+// WebhookProvider bridges to a custom chat system. Send POSTs the
+// contract.AskRequest JSON to webhook.url; the answer is a contract.Reply
+// JSON, either POSTed to a listener on webhook.callback_listen or returned
+// by webhook.poll_url.
+type WebhookProvider struct {
+	url            string
+	secret         string
+	callbackListen string
+	pollURL        string
+	tlsCert        string
+	tlsKey         string
+	httpClient     *http.Client
+	pollInterval   time.Duration
+
+	mu       sync.Mutex
+	server   *http.Server
+	listener net.Listener
+	waiting  map[string]chan contract.Reply
+}
+
+func NewWebhook(cfg config.Config) (*WebhookProvider, error) {
+	w := cfg.Webhook
+	if strings.TrimSpace(w.URL) == "" {
+		return nil, fmt.Errorf(
+			"webhook.url is required.\n" +
+				"First-time webhook setup:\n" +
+				"1) Run a bridge that accepts questions as JSON and posts answers back\n" +
+				"2) Run: `consult-human setup --non-interactive --provider webhook` for the config commands",
+		)
+	}
+	callbackListen := strings.TrimSpace(w.CallbackListen)
+	pollURL := strings.TrimSpace(w.PollURL)
+	if (callbackListen == "") == (pollURL == "") {
+		return nil, fmt.Errorf("set exactly one of webhook.callback_listen and webhook.poll_url")
+	}
+	if (strings.TrimSpace(w.TLSCert) == "") != (strings.TrimSpace(w.TLSKey) == "") {
+		return nil, fmt.Errorf("webhook.tls_cert and webhook.tls_key must be set together")
+	}
+	return &WebhookProvider{
+		url:            strings.TrimSpace(w.URL),
+		secret:         w.Secret,
+		callbackListen: callbackListen,
+		pollURL:        pollURL,
+		tlsCert:        strings.TrimSpace(w.TLSCert),
+		tlsKey:         strings.TrimSpace(w.TLSKey),
+		httpClient:     &http.Client{Timeout: webhookRequestTimeout},
+		pollInterval:   webhookPollInterval,
+		waiting:        make(map[string]chan contract.Reply),
+	}, nil
+}
+
+func (p *WebhookProvider) Name() string { return "webhook" }
+
+// Close stops the callback listener, if one was started.
+func (p *WebhookProvider) Close() error {
+	p.mu.Lock()
+	srv := p.server
+	p.server = nil
+	p.mu.Unlock()
+	if srv == nil {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), webhookShutdownTimeout)
+	defer cancel()
+	return srv.Shutdown(ctx)
+}
+
+// Send starts the callback listener before posting, so an answer that comes
+// back right away is not missed.
+func (p *WebhookProvider) Send(ctx context.Context, req contract.AskRequest) (string, error) {
+	if p.callbackListen != "" {
+		if err := p.startListener(); err != nil {
+			return "", err
+		}
+		p.mu.Lock()
+		p.waiting[req.RequestID] = make(chan contract.Reply, 1)
+		p.mu.Unlock()
+	}
+
+	body, err := json.Marshal(req)
+	if err != nil {
+		return "", err
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, p.url, bytes.NewReader(body))
+	if err != nil {
+		return "", err
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	httpReq.Header.Set(WebhookRequestIDHeader, req.RequestID)
+	if p.secret != "" {
+		httpReq.Header.Set(WebhookSignatureHeader, webhookSignature(p.secret, body))
+	}
+
+	resp, err := p.httpClient.Do(httpReq)
+	if err != nil {
+		p.forget(req.RequestID)
+		if ctx.Err() != nil {
+			return "", ctx.Err()
+		}
+		return "", fmt.Errorf("post question to webhook.url: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		p.forget(req.RequestID)
+		b, _ := io.ReadAll(io.LimitReader(resp.Body, 2048))
+		return "", fmt.Errorf("webhook.url answered status %d: %s", resp.StatusCode, strings.TrimSpace(string(b)))
+	}
+	return req.RequestID, nil
+}
+
+func (p *WebhookProvider) Receive(ctx context.Context, requestID string) (contract.Reply, error) {
+	if p.pollURL != "" {
+		return p.poll(ctx, requestID)
+	}
+
+	p.mu.Lock()
+	ch, ok := p.waiting[requestID]
+	p.mu.Unlock()
+	if !ok {
+		return contract.Reply{}, fmt.Errorf("unknown request id %q", requestID)
+	}
+	defer p.forget(requestID)
+	select {
+	case reply := <-ch:
+		return reply, nil
+	case <-ctx.Done():
+		return contract.Reply{}, ctx.Err()
+	}
+}
+
+// poll asks webhook.poll_url for the answer until it has one: 200 with a
+// contract.Reply body is the answer, 204 or 404 means not yet.
+func (p *WebhookProvider) poll(ctx context.Context, requestID string) (contract.Reply, error) {
+	u, err := url.Parse(p.pollURL)
+	if err != nil {
+		return contract.Reply{}, err
+	}
+	q := u.Query()
+	q.Set("request_id", requestID)
+	u.RawQuery = q.Encode()
+
+	for {
+		reply, ok, err := p.pollOnce(ctx, u.String(), requestID)
+		if err != nil {
+			if ctx.Err() != nil {
+				return contract.Reply{}, ctx.Err()
+			}
+			return contract.Reply{}, err
+		}
+		if ok {
+			return reply, nil
+		}
+
+		timer := time.NewTimer(p.pollInterval)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return contract.Reply{}, ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+func (p *WebhookProvider) pollOnce(ctx context.Context, pollURL, requestID string) (contract.Reply, bool, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, pollURL, nil)
+	if err != nil {
+		return contract.Reply{}, false, err
+	}
+	req.Header.Set(WebhookRequestIDHeader, requestID)
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return contract.Reply{}, false, fmt.Errorf("poll webhook.poll_url: %w", err)
+	}
+	defer resp.Body.Close()
+	switch resp.StatusCode {
+	case http.StatusNoContent, http.StatusNotFound:
+		return contract.Reply{}, false, nil
+	case http.StatusOK:
+	default:
+		b, _ := io.ReadAll(io.LimitReader(resp.Body, 2048))
+		return contract.Reply{}, false, fmt.Errorf("webhook.poll_url answered status %d: %s", resp.StatusCode, strings.TrimSpace(string(b)))
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, webhookMaxBodyBytes))
+	if err != nil {
+		return contract.Reply{}, false, err
+	}
+	reply, err := p.decodeReply(body, resp.Header.Get(WebhookSignatureHeader))
+	if err != nil {
+		return contract.Reply{}, false, fmt.Errorf("webhook.poll_url: %w", err)
+	}
+	if reply.RequestID != requestID {
+		return contract.Reply{}, false, fmt.Errorf("webhook.poll_url answered request_id %q, want %q", reply.RequestID, requestID)
+	}
+	return reply, true, nil
+}
+
+func (p *WebhookProvider) startListener() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.server != nil {
+		return nil
+	}
+	ln, err := net.Listen("tcp", p.callbackListen)
+	if err != nil {
+		return fmt.Errorf("listen on webhook.callback_listen %s: %w", p.callbackListen, err)
+	}
+	if p.tlsCert != "" {
+		cert, err := tls.LoadX509KeyPair(p.tlsCert, p.tlsKey)
+		if err != nil {
+			_ = ln.Close()
+			return fmt.Errorf("load webhook.tls_cert and webhook.tls_key: %w", err)
+		}
+		ln = tls.NewListener(ln, &tls.Config{Certificates: []tls.Certificate{cert}, MinVersion: tls.VersionTLS12})
+	}
+	srv := &http.Server{
+		Handler:           p.CallbackHandler(),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	p.server, p.listener = srv, ln
+	go func() { _ = srv.Serve(ln) }()
+	return nil
+}
+
+// callbackAddr is the address the callback listener is bound to, once
+// started.
+func (p *WebhookProvider) callbackAddr() string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.listener == nil {
+		return ""
+	}
+	return p.listener.Addr().String()
+}
+
+// CallbackHandler accepts answers POSTed as contract.Reply JSON. It answers
+// 401 for a bad signature, 400 for a malformed reply, 404 for a request ID
+// nobody waits on and 409 when the question already has its answer.
+func (p *WebhookProvider) CallbackHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.Header().Set("Allow", http.MethodPost)
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, webhookMaxBodyBytes))
+		if err != nil {
+			http.Error(w, "body too large", http.StatusRequestEntityTooLarge)
+			return
+		}
+		reply, err := p.decodeReply(body, r.Header.Get(WebhookSignatureHeader))
+		if errors.Is(err, errWebhookBadSignature) {
+			http.Error(w, err.Error(), http.StatusUnauthorized)
+			return
+		}
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		p.mu.Lock()
+		ch, ok := p.waiting[reply.RequestID]
+		p.mu.Unlock()
+		if !ok {
+			http.Error(w, "unknown request_id", http.StatusNotFound)
+			return
+		}
+		select {
+		case ch <- reply:
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			http.Error(w, "request_id already answered", http.StatusConflict)
+		}
+	})
+}
+
+var errWebhookBadSignature = errors.New("missing or invalid " + WebhookSignatureHeader)
+
+// decodeReply checks the signature, when a secret is set, and parses a
+// contract.Reply.
+func (p *WebhookProvider) decodeReply(body []byte, signature string) (contract.Reply, error) {
+	if p.secret != "" && !hmac.Equal([]byte(signature), []byte(webhookSignature(p.secret, body))) {
+		return contract.Reply{}, errWebhookBadSignature
+	}
+	var reply contract.Reply
+	if err := json.Unmarshal(body, &reply); err != nil {
+		return contract.Reply{}, fmt.Errorf("invalid reply JSON: %w", err)
+	}
+	if strings.TrimSpace(reply.RequestID) == "" {
+		return contract.Reply{}, fmt.Errorf("reply has no request_id")
+	}
+	if reply.ReceivedAt.IsZero() {
+		reply.ReceivedAt = time.Now().UTC()
+	}
+	return reply, nil
+}
+
+func (p *WebhookProvider) forget(requestID string) {
+	p.mu.Lock()
+	delete(p.waiting, requestID)
+	p.mu.Unlock()
+}
+
+func webhookSignature(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
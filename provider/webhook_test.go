@@ -0,0 +1,322 @@
+package provider
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"encoding/pem"
+	"io"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/AlhasanIQ/consult-human/config"
+	"github.com/AlhasanIQ/consult-human/contract"
+)
+
+// webhookBridgeMock stands in for a team's chat bridge: it records each
+// question and, when answer is set, posts the reply to the callback URL the
+// test gives it.
+type webhookBridgeMock struct {
+	mu         sync.Mutex
+	questions  []contract.AskRequest
+	signatures []string
+	answer     func(req contract.AskRequest)
+}
+
+func (m *webhookBridgeMock) handler(t *testing.T) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+		var req contract.AskRequest
+		if err := json.Unmarshal(body, &req); err != nil {
+			t.Errorf("decode question: %v", err)
+		}
+		m.mu.Lock()
+		m.questions = append(m.questions, req)
+		m.signatures = append(m.signatures, r.Header.Get(WebhookSignatureHeader))
+		answer := m.answer
+		m.mu.Unlock()
+		w.WriteHeader(http.StatusAccepted)
+		if answer != nil {
+			go answer(req)
+		}
+	}
+}
+
+func newTestWebhookProvider(t *testing.T, cfg config.WebhookConfig) *WebhookProvider {
+	t.Helper()
+	c := config.Default()
+	c.Webhook = cfg
+	p, err := NewWebhook(c)
+	if err != nil {
+		t.Fatalf("NewWebhook returned error: %v", err)
+	}
+	p.pollInterval = 5 * time.Millisecond
+	t.Cleanup(func() { _ = p.Close() })
+	return p
+}
+
+func postWebhookReply(client *http.Client, url, secret string, reply contract.Reply) (int, error) {
+	body, _ := json.Marshal(reply)
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	if secret != "" {
+		req.Header.Set(WebhookSignatureHeader, webhookSignature(secret, body))
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	resp.Body.Close()
+	return resp.StatusCode, nil
+}
+
+func TestWebhookCallbackRoundTrip(t *testing.T) {
+	bridge := &webhookBridgeMock{}
+	srv := httptest.NewServer(bridge.handler(t))
+	defer srv.Close()
+	p := newTestWebhookProvider(t, config.WebhookConfig{URL: srv.URL, Secret: "s3cret", CallbackListen: "127.0.0.1:0"})
+
+	statuses := make(chan int, 4)
+	bridge.answer = func(req contract.AskRequest) {
+		url := "http://" + p.callbackAddr()
+		for _, attempt := range []struct {
+			secret string
+			reply  contract.Reply
+		}{
+			{"wrong", contract.Reply{RequestID: req.RequestID, Text: "forged"}},
+			{"s3cret", contract.Reply{RequestID: "req-other", Text: "not ours"}},
+			{"s3cret", contract.Reply{RequestID: req.RequestID, Text: "ship it", From: "alice"}},
+		} {
+			status, err := postWebhookReply(http.DefaultClient, url, attempt.secret, attempt.reply)
+			if err != nil {
+				t.Errorf("post reply: %v", err)
+			}
+			statuses <- status
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	req := contract.AskRequest{RequestID: "req-1", Question: "Deploy?", Type: contract.QuestionTypeOpen}
+	id, err := p.Send(ctx, req)
+	if err != nil {
+		t.Fatalf("Send returned error: %v", err)
+	}
+	if id != "req-1" {
+		t.Fatalf("expected the request ID back, got %q", id)
+	}
+	reply, err := p.Receive(ctx, "req-1")
+	if err != nil {
+		t.Fatalf("Receive returned error: %v", err)
+	}
+	if reply.Text != "ship it" || reply.From != "alice" || reply.ReceivedAt.IsZero() {
+		t.Fatalf("unexpected reply: %#v", reply)
+	}
+	for _, want := range []int{http.StatusUnauthorized, http.StatusNotFound, http.StatusNoContent} {
+		if got := <-statuses; got != want {
+			t.Fatalf("expected callback status %d, got %d", want, got)
+		}
+	}
+
+	bridge.mu.Lock()
+	defer bridge.mu.Unlock()
+	if len(bridge.questions) != 1 || bridge.questions[0].Question != "Deploy?" {
+		t.Fatalf("unexpected questions at the bridge: %#v", bridge.questions)
+	}
+	body, _ := json.Marshal(req)
+	if bridge.signatures[0] != webhookSignature("s3cret", body) {
+		t.Fatalf("unexpected question signature %q", bridge.signatures[0])
+	}
+
+	// The answered question no longer accepts callbacks.
+	status, err := postWebhookReply(http.DefaultClient, "http://"+p.callbackAddr(), "s3cret", contract.Reply{RequestID: "req-1", Text: "again"})
+	if err != nil || status != http.StatusNotFound {
+		t.Fatalf("expected 404 after the answer, got %d err=%v", status, err)
+	}
+}
+
+func TestWebhookCallbackTLS(t *testing.T) {
+	certPath, keyPath, pool := writeWebhookTestCert(t)
+	bridge := &webhookBridgeMock{}
+	srv := httptest.NewServer(bridge.handler(t))
+	defer srv.Close()
+	p := newTestWebhookProvider(t, config.WebhookConfig{URL: srv.URL, CallbackListen: "127.0.0.1:0", TLSCert: certPath, TLSKey: keyPath})
+
+	client := &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{RootCAs: pool}}}
+	bridge.answer = func(req contract.AskRequest) {
+		if _, err := postWebhookReply(client, "https://"+p.callbackAddr(), "", contract.Reply{RequestID: req.RequestID, Text: "b"}); err != nil {
+			t.Errorf("post reply over TLS: %v", err)
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if _, err := p.Send(ctx, contract.AskRequest{RequestID: "req-1", Question: "Which?"}); err != nil {
+		t.Fatalf("Send returned error: %v", err)
+	}
+	reply, err := p.Receive(ctx, "req-1")
+	if err != nil {
+		t.Fatalf("Receive returned error: %v", err)
+	}
+	if reply.Text != "b" {
+		t.Fatalf("unexpected reply: %#v", reply)
+	}
+}
+
+func TestWebhookPollRoundTrip(t *testing.T) {
+	var mu sync.Mutex
+	polls := 0
+	mux := http.NewServeMux()
+	mux.HandleFunc("/questions", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	mux.HandleFunc("/answers", func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		polls++
+		n := polls
+		mu.Unlock()
+		if r.URL.Query().Get("request_id") != "req-1" {
+			t.Errorf("unexpected poll query %q", r.URL.RawQuery)
+		}
+		if n < 3 {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		body, _ := json.Marshal(contract.Reply{RequestID: "req-1", Text: "eu-west-1"})
+		w.Header().Set(WebhookSignatureHeader, webhookSignature("s3cret", body))
+		_, _ = w.Write(body)
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+	p := newTestWebhookProvider(t, config.WebhookConfig{URL: srv.URL + "/questions", Secret: "s3cret", PollURL: srv.URL + "/answers?team=ops"})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if _, err := p.Send(ctx, contract.AskRequest{RequestID: "req-1", Question: "Which region?"}); err != nil {
+		t.Fatalf("Send returned error: %v", err)
+	}
+	reply, err := p.Receive(ctx, "req-1")
+	if err != nil {
+		t.Fatalf("Receive returned error: %v", err)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if reply.Text != "eu-west-1" || polls != 3 {
+		t.Fatalf("unexpected reply %#v after %d polls", reply, polls)
+	}
+}
+
+func TestWebhookPollRejectsUnsignedReply(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			_, _ = w.Write([]byte(`{"request_id":"req-1","text":"forged"}`))
+		}
+	}))
+	defer srv.Close()
+	p := newTestWebhookProvider(t, config.WebhookConfig{URL: srv.URL, Secret: "s3cret", PollURL: srv.URL})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	_, err := p.Receive(ctx, "req-1")
+	if err == nil || !strings.Contains(err.Error(), WebhookSignatureHeader) {
+		t.Fatalf("expected a signature error, got %v", err)
+	}
+}
+
+func TestWebhookSendRejectedStopsListening(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "no such channel", http.StatusBadRequest)
+	}))
+	defer srv.Close()
+	p := newTestWebhookProvider(t, config.WebhookConfig{URL: srv.URL, CallbackListen: "127.0.0.1:0"})
+
+	_, err := p.Send(context.Background(), contract.AskRequest{RequestID: "req-1", Question: "Deploy?"})
+	if err == nil || !strings.Contains(err.Error(), "status 400: no such channel") {
+		t.Fatalf("expected the bridge error, got %v", err)
+	}
+	if _, err := p.Receive(context.Background(), "req-1"); err == nil || !strings.Contains(err.Error(), "unknown request id") {
+		t.Fatalf("expected the failed question to be forgotten, got %v", err)
+	}
+}
+
+func TestFactoryUsesWebhook(t *testing.T) {
+	cfg := config.Default()
+	if _, err := New(cfg, "webhook"); err == nil || !strings.Contains(err.Error(), "webhook.url is required") {
+		t.Fatalf("expected a missing config error, got %v", err)
+	}
+	cfg.Webhook.URL = "https://bridge.example/questions"
+	if _, err := New(cfg, "webhook"); err == nil || !strings.Contains(err.Error(), "exactly one of") {
+		t.Fatalf("expected a receive mode error, got %v", err)
+	}
+	cfg.Webhook.PollURL = "https://bridge.example/answers"
+	p, err := New(cfg, "webhook")
+	if err != nil {
+		t.Fatalf("New returned error: %v", err)
+	}
+	if p.Name() != "webhook" {
+		t.Fatalf("unexpected provider %q", p.Name())
+	}
+}
+
+// writeWebhookTestCert writes a self-signed certificate for 127.0.0.1 and
+// returns its paths and a pool that trusts it.
+func writeWebhookTestCert(t *testing.T) (string, string, *x509.CertPool) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "consult-human test"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("create certificate: %v", err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatalf("marshal key: %v", err)
+	}
+	dir := t.TempDir()
+	certPath, keyPath := filepath.Join(dir, "cert.pem"), filepath.Join(dir, "key.pem")
+	if err := os.WriteFile(certPath, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(keyPath, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pool := x509.NewCertPool()
+	pool.AddCert(cert)
+	return certPath, keyPath, pool
+}
@@ -0,0 +1,378 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/AlhasanIQ/consult-human/config"
+	"github.com/AlhasanIQ/consult-human/contract"
+)
+
+const (
+	zulipDefaultPollInterval = 2 * time.Second
+	zulipRequestTimeout      = 30 * time.Second
+	zulipFetchLimit          = 100
+	// ZulipTopicPrefix starts the topic of every question sent to a stream.
+	ZulipTopicPrefix   = "consult-human/"
+	zulipTopicIDLength = 8
+)
+
+// ZulipProvider sends questions as a Zulip bot. In stream mode each question
+// gets its own topic and the first message there from someone else is the
+// answer. In direct-message mode questions go to zulip.recipient, and a reply
+// quotes the question, names its request ID, or comes before the next
+// question.
+type ZulipProvider struct {
+	site         string
+	email        string
+	apiKey       string
+	stream       string
+	recipient    string
+	httpClient   *http.Client
+	pollInterval time.Duration
+
+	mu      sync.Mutex
+	pending map[string]zulipQuestion
+}
+
+// zulipQuestion is where a question was sent.
+type zulipQuestion struct {
+	MessageID int64
+	Topic     string
+}
+
+type zulipMessage struct {
+	ID             int64  `json:"id"`
+	SenderEmail    string `json:"sender_email"`
+	SenderFullName string `json:"sender_full_name"`
+	Content        string `json:"content"`
+	Timestamp      int64  `json:"timestamp"`
+}
+
+// zulipAPIError is a "result": "error" answer, such as a wrong API key or
+// a stream the bot is not subscribed to.
+type zulipAPIError struct {
+	Action string
+	Status int
+	Code   string
+	Msg    string
+}
+
+func (e *zulipAPIError) Error() string {
+	msg := fmt.Sprintf("zulip %s failed with status %d: %s", e.Action, e.Status, e.Msg)
+	if e.Status == http.StatusUnauthorized {
+		msg += "; check zulip.email and zulip.api_key"
+	}
+	return msg
+}
+
+// zulipRateLimitedError is a 429 answer carrying Zulip's Retry-After.
+type zulipRateLimitedError struct {
+	RetryAfter time.Duration
+}
+
+func (e *zulipRateLimitedError) Error() string {
+	return fmt.Sprintf("zulip rate limited; retry after %s", e.RetryAfter)
+}
+
+func NewZulip(cfg config.Config) (*ZulipProvider, error) {
+	z := cfg.Zulip
+	if strings.TrimSpace(z.Site) == "" || strings.TrimSpace(z.Email) == "" || strings.TrimSpace(z.APIKey) == "" {
+		return nil, fmt.Errorf(
+			"zulip.site, zulip.email and zulip.api_key are required.\n" +
+				"First-time Zulip setup:\n" +
+				"1) Create a generic bot under Personal settings > Bots and download its zuliprc\n" +
+				"2) Run: `consult-human setup --provider zulip`",
+		)
+	}
+	stream := strings.TrimSpace(z.Stream)
+	recipient := strings.TrimSpace(z.Recipient)
+	if (stream == "") == (recipient == "") {
+		return nil, fmt.Errorf("set exactly one of zulip.stream and zulip.recipient")
+	}
+	pollInterval := zulipDefaultPollInterval
+	if z.PollIntervalSeconds > 0 {
+		pollInterval = time.Duration(z.PollIntervalSeconds) * time.Second
+	}
+	return &ZulipProvider{
+		site:         strings.TrimRight(strings.TrimSpace(z.Site), "/"),
+		email:        strings.TrimSpace(z.Email),
+		apiKey:       strings.TrimSpace(z.APIKey),
+		stream:       stream,
+		recipient:    recipient,
+		httpClient:   &http.Client{Timeout: zulipRequestTimeout},
+		pollInterval: pollInterval,
+		pending:      make(map[string]zulipQuestion),
+	}, nil
+}
+
+func (p *ZulipProvider) Name() string { return "zulip" }
+
+func (p *ZulipProvider) Close() error { return nil }
+
+func (p *ZulipProvider) direct() bool { return p.stream == "" }
+
+// ZulipTopic is the topic a question is sent to in stream mode.
+func ZulipTopic(requestID string) string {
+	id := requestID
+	if r := []rune(id); len(r) > zulipTopicIDLength {
+		id = string(r[:zulipTopicIDLength])
+	}
+	return ZulipTopicPrefix + id
+}
+
+func (p *ZulipProvider) Send(ctx context.Context, req contract.AskRequest) (string, error) {
+	params := url.Values{"content": {RenderZulipPrompt(req, p.direct())}}
+	question := zulipQuestion{}
+	if p.direct() {
+		to, _ := json.Marshal([]string{p.recipient})
+		params.Set("type", "private")
+		params.Set("to", string(to))
+	} else {
+		question.Topic = ZulipTopic(req.RequestID)
+		params.Set("type", "stream")
+		params.Set("to", p.stream)
+		params.Set("topic", question.Topic)
+	}
+
+	var decoded struct {
+		ID int64 `json:"id"`
+	}
+	if err := p.call(ctx, http.MethodPost, "messages", params, &decoded); err != nil {
+		return "", err
+	}
+	if decoded.ID == 0 {
+		return "", fmt.Errorf("zulip send message returned no message id")
+	}
+	question.MessageID = decoded.ID
+
+	p.mu.Lock()
+	p.pending[req.RequestID] = question
+	p.mu.Unlock()
+	return strconv.FormatInt(decoded.ID, 10), nil
+}
+
+// Receive polls the messages sent after the question, backing off as Zulip
+// asks when rate limited.
+func (p *ZulipProvider) Receive(ctx context.Context, requestID string) (contract.Reply, error) {
+	p.mu.Lock()
+	question, ok := p.pending[requestID]
+	p.mu.Unlock()
+	if !ok {
+		return contract.Reply{}, fmt.Errorf("unknown request id %q", requestID)
+	}
+	defer func() {
+		p.mu.Lock()
+		delete(p.pending, requestID)
+		p.mu.Unlock()
+	}()
+
+	anchor := question.MessageID
+	laterQuestion := false
+	for {
+		wait := p.pollInterval
+		msgs, err := p.messagesAfter(ctx, question, anchor)
+		var rateErr *zulipRateLimitedError
+		switch {
+		case errors.As(err, &rateErr):
+			wait = max(rateErr.RetryAfter, p.pollInterval)
+		case err != nil:
+			if ctx.Err() != nil {
+				return contract.Reply{}, ctx.Err()
+			}
+			return contract.Reply{}, err
+		}
+		for _, msg := range msgs {
+			if msg.ID <= anchor {
+				continue
+			}
+			anchor = msg.ID
+			if strings.EqualFold(msg.SenderEmail, p.email) {
+				laterQuestion = true
+				continue
+			}
+			text, ok := p.answerText(msg, question, requestID, laterQuestion)
+			if !ok {
+				continue
+			}
+			from := msg.SenderFullName
+			if from == "" {
+				from = msg.SenderEmail
+			}
+			return contract.Reply{
+				RequestID:         requestID,
+				Text:              text,
+				Raw:               msg.Content,
+				From:              from,
+				ProviderMessageID: strconv.FormatInt(msg.ID, 10),
+				ReceivedAt:        time.Unix(msg.Timestamp, 0).UTC(),
+			}, nil
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return contract.Reply{}, ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// answerText decides whether msg answers the question. Every message in a
+// question's topic does; a direct message must quote the question, name the
+// request ID, or come before any later question.
+func (p *ZulipProvider) answerText(msg zulipMessage, question zulipQuestion, requestID string, laterQuestion bool) (string, bool) {
+	content := strings.TrimSpace(msg.Content)
+	if !p.direct() {
+		return content, content != ""
+	}
+	if !strings.EqualFold(msg.SenderEmail, p.recipient) {
+		return "", false
+	}
+	if text, quoted, quotesOther := stripZulipQuote(content, question.MessageID); quoted {
+		text, _ = stripRequestIDToken(text, requestID)
+		return strings.TrimSpace(text), strings.TrimSpace(text) != ""
+	} else if quotesOther {
+		return "", false
+	}
+	if text, ok := stripRequestIDToken(content, requestID); ok {
+		return strings.TrimSpace(text), strings.TrimSpace(text) != ""
+	}
+	return content, !laterQuestion && content != ""
+}
+
+// stripZulipQuote removes a quote of message id from content, as inserted
+// by Zulip's "Quote and reply": a line ending in "[said](<link>/near/<id>):"
+// followed by a ```quote block. quotesOther reports a quote of another
+// message.
+func stripZulipQuote(content string, id int64) (text string, quoted, quotesOther bool) {
+	lines := strings.Split(content, "\n")
+	near := "/near/" + strconv.FormatInt(id, 10) + ")"
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if !strings.Contains(trimmed, "/near/") || !strings.HasSuffix(trimmed, "):") {
+			continue
+		}
+		if !strings.Contains(trimmed, near) {
+			return content, false, true
+		}
+		end := i + 1
+		if end < len(lines) {
+			fence := strings.TrimSpace(lines[end])
+			if strings.HasPrefix(fence, "```quote") || strings.HasPrefix(fence, "~~~quote") {
+				closing := fence[:3]
+				end++
+				for end < len(lines) && strings.TrimSpace(lines[end]) != closing {
+					end++
+				}
+				end++
+			}
+		}
+		kept := append(append([]string{}, lines[:i]...), lines[min(end, len(lines)):]...)
+		return strings.TrimSpace(strings.Join(kept, "\n")), true, false
+	}
+	return content, false, false
+}
+
+// messagesAfter lists the messages after anchor in the question's topic or
+// direct-message conversation, oldest first.
+func (p *ZulipProvider) messagesAfter(ctx context.Context, question zulipQuestion, anchor int64) ([]zulipMessage, error) {
+	narrow := []map[string]string{{"operator": "pm-with", "operand": p.recipient}}
+	if !p.direct() {
+		narrow = []map[string]string{
+			{"operator": "stream", "operand": p.stream},
+			{"operator": "topic", "operand": question.Topic},
+		}
+	}
+	narrowJSON, err := json.Marshal(narrow)
+	if err != nil {
+		return nil, err
+	}
+	params := url.Values{
+		"anchor":         {strconv.FormatInt(anchor, 10)},
+		"num_before":     {"0"},
+		"num_after":      {strconv.Itoa(zulipFetchLimit)},
+		"narrow":         {string(narrowJSON)},
+		"apply_markdown": {"false"},
+	}
+	var decoded struct {
+		Messages []zulipMessage `json:"messages"`
+	}
+	if err := p.call(ctx, http.MethodGet, "messages", params, &decoded); err != nil {
+		return nil, err
+	}
+	return decoded.Messages, nil
+}
+
+// call makes a REST API request with the bot's login and decodes the answer
+// into out once Zulip reports "result": "success".
+func (p *ZulipProvider) call(ctx context.Context, method, path string, params url.Values, out any) error {
+	endpoint := p.site + "/api/v1/" + path
+	var body io.Reader
+	if method == http.MethodGet {
+		endpoint += "?" + params.Encode()
+	} else {
+		body = strings.NewReader(params.Encode())
+	}
+	req, err := http.NewRequestWithContext(ctx, method, endpoint, body)
+	if err != nil {
+		return err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	}
+	req.SetBasicAuth(p.email, p.apiKey)
+
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		return fmt.Errorf("could not reach zulip at %s: %w", p.site, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusTooManyRequests {
+		retryAfter := time.Second
+		if secs, err := strconv.ParseFloat(resp.Header.Get("Retry-After"), 64); err == nil && secs > 0 {
+			retryAfter = time.Duration(secs * float64(time.Second))
+		}
+		return &zulipRateLimitedError{RetryAfter: retryAfter}
+	}
+	raw, err := io.ReadAll(io.LimitReader(resp.Body, 4<<20))
+	if err != nil {
+		return err
+	}
+
+	action := strings.ToLower(method) + " " + path
+	var status struct {
+		Result string `json:"result"`
+		Msg    string `json:"msg"`
+		Code   string `json:"code"`
+	}
+	if err := json.Unmarshal(raw, &status); err != nil {
+		if resp.StatusCode != http.StatusOK {
+			return &zulipAPIError{Action: action, Status: resp.StatusCode, Msg: strings.TrimSpace(string(raw))}
+		}
+		return fmt.Errorf("decode zulip %s response: %w", action, err)
+	}
+	if resp.StatusCode != http.StatusOK || status.Result != "success" {
+		return &zulipAPIError{Action: action, Status: resp.StatusCode, Code: status.Code, Msg: status.Msg}
+	}
+	if out == nil {
+		return nil
+	}
+	if err := json.Unmarshal(raw, out); err != nil {
+		return fmt.Errorf("decode zulip %s response: %w", action, err)
+	}
+	return nil
+}
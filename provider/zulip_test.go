@@ -0,0 +1,206 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/AlhasanIQ/consult-human/config"
+	"github.com/AlhasanIQ/consult-human/contract"
+)
+
+// zulipServerMock records sent messages and serves each GET /messages one
+// batch of messages.
+type zulipServerMock struct {
+	mu      sync.Mutex
+	sent    []map[string]string
+	queries []map[string]string
+	batches [][]zulipMessage
+	status  int
+}
+
+func (m *zulipServerMock) handler(t *testing.T) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		m.mu.Lock()
+		defer m.mu.Unlock()
+		if user, key, ok := r.BasicAuth(); !ok || user != "bot@example.zulipchat.com" || key != "key123" {
+			t.Errorf("unexpected auth %q %q", user, key)
+		}
+		if m.status != 0 {
+			w.WriteHeader(m.status)
+			_, _ = w.Write([]byte(`{"result":"error","msg":"Invalid API key","code":"UNAUTHORIZED"}`))
+			return
+		}
+		if r.URL.Path != "/api/v1/messages" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		switch r.Method {
+		case http.MethodPost:
+			if err := r.ParseForm(); err != nil {
+				t.Errorf("parse form: %v", err)
+			}
+			fields := map[string]string{}
+			for k := range r.PostForm {
+				fields[k] = r.PostForm.Get(k)
+			}
+			m.sent = append(m.sent, fields)
+			_, _ = fmt.Fprintf(w, `{"result":"success","msg":"","id":%d}`, 100*len(m.sent))
+		case http.MethodGet:
+			q := map[string]string{}
+			for k := range r.URL.Query() {
+				q[k] = r.URL.Query().Get(k)
+			}
+			m.queries = append(m.queries, q)
+			var batch []zulipMessage
+			if len(m.batches) > 0 {
+				batch = m.batches[0]
+				m.batches = m.batches[1:]
+			}
+			_ = json.NewEncoder(w).Encode(map[string]any{"result": "success", "msg": "", "messages": batch})
+		}
+	}
+}
+
+func newTestZulipProvider(t *testing.T, site string, stream, recipient string) *ZulipProvider {
+	t.Helper()
+	cfg := config.Default()
+	cfg.Zulip = config.ZulipConfig{Site: site, Email: "bot@example.zulipchat.com", APIKey: "key123", Stream: stream, Recipient: recipient}
+	p, err := NewZulip(cfg)
+	if err != nil {
+		t.Fatalf("NewZulip returned error: %v", err)
+	}
+	p.pollInterval = 5 * time.Millisecond
+	return p
+}
+
+func TestZulipStreamSendAndReceiveFromTopic(t *testing.T) {
+	m := &zulipServerMock{}
+	srv := httptest.NewServer(m.handler(t))
+	defer srv.Close()
+	p := newTestZulipProvider(t, srv.URL, "agents", "")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	id, err := p.Send(ctx, contract.AskRequest{RequestID: "0123456789abcdef", Question: "Deploy?", Type: contract.QuestionTypeOpen})
+	if err != nil {
+		t.Fatalf("Send returned error: %v", err)
+	}
+	if id != "100" {
+		t.Fatalf("expected the message ID, got %q", id)
+	}
+	sent := m.sent[0]
+	if sent["type"] != "stream" || sent["to"] != "agents" || sent["topic"] != "consult-human/01234567" || !strings.HasPrefix(sent["content"], "Deploy?") {
+		t.Fatalf("unexpected message: %#v", sent)
+	}
+
+	m.batches = [][]zulipMessage{
+		{{ID: 100, SenderEmail: "bot@example.zulipchat.com", Content: "Deploy?"}},
+		{
+			{ID: 101, SenderEmail: "bot@example.zulipchat.com", Content: "reminder"},
+			{ID: 102, SenderEmail: "alice@example.com", SenderFullName: "Alice", Content: " ship it ", Timestamp: 1700000000},
+		},
+	}
+	reply, err := p.Receive(ctx, "0123456789abcdef")
+	if err != nil {
+		t.Fatalf("Receive returned error: %v", err)
+	}
+	if reply.Text != "ship it" || reply.From != "Alice" || reply.ProviderMessageID != "102" || !reply.ReceivedAt.Equal(time.Unix(1700000000, 0)) {
+		t.Fatalf("unexpected reply: %#v", reply)
+	}
+	q := m.queries[0]
+	if q["anchor"] != "100" || q["num_before"] != "0" || q["apply_markdown"] != "false" ||
+		q["narrow"] != `[{"operand":"agents","operator":"stream"},{"operand":"consult-human/01234567","operator":"topic"}]` {
+		t.Fatalf("unexpected messages query: %#v", q)
+	}
+	if m.queries[1]["anchor"] != "100" {
+		t.Fatalf("expected the second poll to start after the last message seen, got %#v", m.queries[1])
+	}
+}
+
+func TestZulipDirectReplyMatching(t *testing.T) {
+	m := &zulipServerMock{}
+	srv := httptest.NewServer(m.handler(t))
+	defer srv.Close()
+	p := newTestZulipProvider(t, srv.URL, "", "human@example.com")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if _, err := p.Send(ctx, contract.AskRequest{RequestID: "req-1", Question: "Which region?"}); err != nil {
+		t.Fatalf("Send returned error: %v", err)
+	}
+	if sent := m.sent[0]; sent["type"] != "private" || sent["to"] != `["human@example.com"]` || !strings.Contains(sent["content"], "include `req-1`") {
+		t.Fatalf("unexpected message: %#v", sent)
+	}
+
+	m.batches = [][]zulipMessage{{
+		{ID: 101, SenderEmail: "bot@example.zulipchat.com", Content: "Another question"},
+		{ID: 102, SenderEmail: "human@example.com", Content: "answers the later question"},
+		{ID: 103, SenderEmail: "human@example.com", Content: "@_**Bot|9** [said](https://x.zulipchat.com/#narrow/dm/9/near/101):\n```quote\nAnother question\n```\nnot this one"},
+		{ID: 104, SenderEmail: "human@example.com", Content: "req-1 eu-west-1"},
+	}}
+	reply, err := p.Receive(ctx, "req-1")
+	if err != nil {
+		t.Fatalf("Receive returned error: %v", err)
+	}
+	if reply.Text != "eu-west-1" || reply.ProviderMessageID != "104" {
+		t.Fatalf("unexpected reply: %#v", reply)
+	}
+	if got := m.queries[0]["narrow"]; got != `[{"operand":"human@example.com","operator":"pm-with"}]` {
+		t.Fatalf("unexpected narrow %s", got)
+	}
+}
+
+func TestZulipDirectQuoteAnswersQuestion(t *testing.T) {
+	p := &ZulipProvider{recipient: "human@example.com"}
+	quoted := zulipMessage{SenderEmail: "human@example.com", Content: "@_**Bot|9** [said](https://x.zulipchat.com/#narrow/dm/9/near/100):\n```quote\nWhich region?\n```\neu-west-1"}
+	if text, ok := p.answerText(quoted, zulipQuestion{MessageID: 100}, "req-1", true); !ok || text != "eu-west-1" {
+		t.Fatalf("expected the quote to be stripped, got %q ok=%v", text, ok)
+	}
+	plain := zulipMessage{SenderEmail: "human@example.com", Content: "eu-west-1"}
+	if _, ok := p.answerText(plain, zulipQuestion{MessageID: 100}, "req-1", false); !ok {
+		t.Fatalf("expected a plain reply before any later question to count")
+	}
+	other := zulipMessage{SenderEmail: "someone@example.com", Content: "req-1 yes"}
+	if _, ok := p.answerText(other, zulipQuestion{MessageID: 100}, "req-1", false); ok {
+		t.Fatalf("expected messages from others to be ignored")
+	}
+}
+
+func TestZulipUnauthorized(t *testing.T) {
+	m := &zulipServerMock{status: http.StatusUnauthorized}
+	srv := httptest.NewServer(m.handler(t))
+	defer srv.Close()
+	p := newTestZulipProvider(t, srv.URL, "agents", "")
+
+	_, err := p.Send(context.Background(), contract.AskRequest{RequestID: "req-1", Question: "Deploy?"})
+	if err == nil || !strings.Contains(err.Error(), "Invalid API key") || !strings.Contains(err.Error(), "zulip.api_key") {
+		t.Fatalf("expected an auth error with a hint, got %v", err)
+	}
+}
+
+func TestFactoryUsesZulip(t *testing.T) {
+	cfg := config.Default()
+	if _, err := New(cfg, "zulip"); err == nil || !strings.Contains(err.Error(), "zulip.api_key are required") {
+		t.Fatalf("expected a missing config error, got %v", err)
+	}
+	cfg.Zulip = config.ZulipConfig{Site: "https://example.zulipchat.com", Email: "bot@example.zulipchat.com", APIKey: "key123"}
+	if _, err := New(cfg, "zulip"); err == nil || !strings.Contains(err.Error(), "exactly one of") {
+		t.Fatalf("expected a destination error, got %v", err)
+	}
+	cfg.Zulip.Stream = "agents"
+	p, err := New(cfg, "zulip")
+	if err != nil {
+		t.Fatalf("New returned error: %v", err)
+	}
+	if p.Name() != "zulip" {
+		t.Fatalf("unexpected provider %q", p.Name())
+	}
+}